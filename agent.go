@@ -0,0 +1,141 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// --- SSH Agent ---
+
+// ssh finds its agent through SSH_AUTH_SOCK. When that is unset, assho looks
+// for an agent that is running anyway and exports its socket at startup, so
+// ssh, ssh-add, and ssh-copy-id all use it: gpg-agent's ssh socket, for keys
+// on a GPG smartcard (gpg-agent needs enable-ssh-support), and on Windows
+// PuTTY's Pageant. The Windows OpenSSH agent, which gpg4win can also stand in
+// for, listens on a pipe ssh tries by itself, so it is only reported. An
+// SSH_AUTH_SOCK that is already set is always left alone. `assho doctor`
+// shows which agent is in use and how it was found.
+
+const (
+	pipeDir          = `\\.\pipe\`
+	windowsAgentPipe = pipeDir + "openssh-ssh-agent"
+)
+
+type sshAgent struct {
+	kind   string // ssh-agent, gpg-agent, Pageant, or Windows OpenSSH agent
+	socket string
+	source string // how it was found
+}
+
+// gpgAgentSocket is where gpg-agent serves ssh clients, or "" without gpg.
+var gpgAgentSocket = func() string {
+	if out, err := exec.Command("gpgconf", "--list-dirs", "agent-ssh-socket").Output(); err == nil {
+		return strings.TrimSpace(string(out))
+	}
+	home := os.Getenv("GNUPGHOME")
+	if home == "" {
+		userHome, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		home = filepath.Join(userHome, ".gnupg")
+	}
+	return filepath.Join(home, "S.gpg-agent.ssh")
+}
+
+// agentPipes lists the named pipes an agent may listen on. It is empty
+// outside Windows.
+var agentPipes = func() []string {
+	if runtime.GOOS != "windows" {
+		return nil
+	}
+	entries, err := os.ReadDir(pipeDir)
+	if err != nil {
+		return nil
+	}
+	var pipes []string
+	for _, entry := range entries {
+		pipes = append(pipes, entry.Name())
+	}
+	return pipes
+}
+
+// exportedAgent is the agent assho found and exported at startup, if any.
+var exportedAgent sshAgent
+
+// agentKind guesses which agent serves socket from its name.
+func agentKind(socket string) string {
+	name := strings.ToLower(filepath.Base(socket))
+	switch {
+	case strings.Contains(name, "gpg-agent"):
+		return "gpg-agent"
+	case strings.Contains(name, "pageant"):
+		return "Pageant"
+	case socket == windowsAgentPipe:
+		return "Windows OpenSSH agent"
+	}
+	return "ssh-agent"
+}
+
+// agentReachable reports whether socket is a Unix socket or, on Windows, an
+// existing named pipe.
+func agentReachable(socket string) bool {
+	info, err := os.Stat(socket)
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeSocket != 0 || strings.HasPrefix(socket, pipeDir)
+}
+
+// detectSSHAgent finds the agent ssh would use, or one it could use if
+// SSH_AUTH_SOCK pointed at it. ok is false when there is none.
+func detectSSHAgent() (agent sshAgent, ok bool) {
+	if socket := strings.TrimSpace(os.Getenv("SSH_AUTH_SOCK")); socket != "" {
+		if socket == exportedAgent.socket {
+			return exportedAgent, agentReachable(socket)
+		}
+		agent = sshAgent{kind: agentKind(socket), socket: socket, source: "SSH_AUTH_SOCK"}
+		return agent, agentReachable(socket)
+	}
+	pipes := agentPipes()
+	for _, pipe := range pipes {
+		if pipeDir+pipe == windowsAgentPipe {
+			return sshAgent{kind: agentKind(windowsAgentPipe), socket: windowsAgentPipe, source: "ssh's default pipe"}, true
+		}
+	}
+	for _, pipe := range pipes {
+		if strings.HasPrefix(strings.ToLower(pipe), "pageant.") {
+			return sshAgent{kind: "Pageant", socket: pipeDir + pipe, source: "its named pipe"}, true
+		}
+	}
+	if runtime.GOOS != "windows" {
+		if socket := gpgAgentSocket(); socket != "" && agentReachable(socket) {
+			return sshAgent{kind: "gpg-agent", socket: socket, source: "GnuPG"}, true
+		}
+	}
+	return sshAgent{}, false
+}
+
+// useDetectedSSHAgent points SSH_AUTH_SOCK at an agent found without it, so
+// every ssh assho runs can use the agent. main calls it only on the paths
+// that run ssh or report on the agent, so help, version and completion never
+// wait on gpgconf.
+func useDetectedSSHAgent() {
+	if os.Getenv("SSH_AUTH_SOCK") != "" {
+		return
+	}
+	agent, ok := detectSSHAgent()
+	if !ok || agent.socket == windowsAgentPipe {
+		return
+	}
+	os.Setenv("SSH_AUTH_SOCK", agent.socket)
+	exportedAgent = agent
+}
+
+func sshAgentAvailable() bool {
+	_, ok := detectSSHAgent()
+	return ok
+}
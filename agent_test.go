@@ -0,0 +1,100 @@
+package main
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// listenAgentSocket serves a Unix socket named name for the test's duration.
+func listenAgentSocket(t *testing.T, name string) string {
+	t.Helper()
+	dir, err := os.MkdirTemp("", "agent")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	socket := filepath.Join(dir, name)
+	l, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Skipf("unix sockets unavailable: %v", err)
+	}
+	t.Cleanup(func() { l.Close() })
+	return socket
+}
+
+// stubAgentProbes replaces the gpg and pipe lookups for the test.
+func stubAgentProbes(t *testing.T, gpgSocket string, pipes []string) {
+	t.Helper()
+	oldGPG, oldPipes, oldExported := gpgAgentSocket, agentPipes, exportedAgent
+	gpgAgentSocket = func() string { return gpgSocket }
+	agentPipes = func() []string { return pipes }
+	t.Cleanup(func() { gpgAgentSocket, agentPipes, exportedAgent = oldGPG, oldPipes, oldExported })
+}
+
+func TestDetectSSHAgentKeepsSSHAuthSock(t *testing.T) {
+	socket := listenAgentSocket(t, "S.gpg-agent.ssh")
+	stubAgentProbes(t, "", nil)
+	t.Setenv("SSH_AUTH_SOCK", socket)
+	agent, ok := detectSSHAgent()
+	if !ok || agent.kind != "gpg-agent" || agent.source != "SSH_AUTH_SOCK" {
+		t.Fatalf("unexpected agent %+v ok=%v", agent, ok)
+	}
+
+	t.Setenv("SSH_AUTH_SOCK", filepath.Join(t.TempDir(), "gone"))
+	if agent, ok := detectSSHAgent(); ok || agent.socket == "" {
+		t.Fatalf("a stale SSH_AUTH_SOCK should be reported as unreachable, got %+v ok=%v", agent, ok)
+	}
+	if c := doctorAgentCheck(); c.level != doctorWarn {
+		t.Fatalf("expected a warning for a stale socket, got %+v", c)
+	}
+}
+
+func TestUseDetectedSSHAgentExportsGPGSocket(t *testing.T) {
+	socket := listenAgentSocket(t, "S.gpg-agent.ssh")
+	stubAgentProbes(t, socket, nil)
+	t.Setenv("SSH_AUTH_SOCK", "")
+	useDetectedSSHAgent()
+	if got := os.Getenv("SSH_AUTH_SOCK"); got != socket {
+		t.Fatalf("expected SSH_AUTH_SOCK=%s, got %q", socket, got)
+	}
+	if !sshAgentAvailable() {
+		t.Fatal("expected the exported agent to be available")
+	}
+	c := doctorAgentCheck()
+	if c.level != doctorOK || !strings.Contains(c.detail, "gpg-agent") || !strings.Contains(c.detail, "SSH_AUTH_SOCK is unset") {
+		t.Fatalf("expected doctor to name gpg-agent and how it was found, got %+v", c)
+	}
+}
+
+func TestDetectSSHAgentIgnoresMissingGPGSocket(t *testing.T) {
+	stubAgentProbes(t, filepath.Join(t.TempDir(), "S.gpg-agent.ssh"), nil)
+	t.Setenv("SSH_AUTH_SOCK", "")
+	useDetectedSSHAgent()
+	if got := os.Getenv("SSH_AUTH_SOCK"); got != "" {
+		t.Fatalf("expected SSH_AUTH_SOCK to stay unset, got %q", got)
+	}
+	if c := doctorAgentCheck(); c.level != doctorInfo {
+		t.Fatalf("expected the no-agent hint, got %+v", c)
+	}
+}
+
+func TestDetectSSHAgentWindowsPipes(t *testing.T) {
+	stubAgentProbes(t, "", []string{"pageant.alex.0123abcd", "openssh-ssh-agent"})
+	t.Setenv("SSH_AUTH_SOCK", "")
+	if agent, ok := detectSSHAgent(); !ok || agent.socket != windowsAgentPipe {
+		t.Fatalf("expected ssh's default pipe to win, got %+v ok=%v", agent, ok)
+	}
+	useDetectedSSHAgent()
+	if got := os.Getenv("SSH_AUTH_SOCK"); got != "" {
+		t.Fatalf("ssh finds its default pipe itself; SSH_AUTH_SOCK should stay unset, got %q", got)
+	}
+
+	stubAgentProbes(t, "", []string{"pageant.alex.0123abcd"})
+	useDetectedSSHAgent()
+	if got := os.Getenv("SSH_AUTH_SOCK"); got != `\\.\pipe\pageant.alex.0123abcd` || exportedAgent.kind != "Pageant" {
+		t.Fatalf("expected Pageant's pipe to be exported, got %q (%+v)", got, exportedAgent)
+	}
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// --- Ansible Inventory ---
+
+// `assho export --ansible [ini|yaml]` prints the hosts as an Ansible
+// inventory, so the list curated here can drive playbooks. Each group
+// becomes an Ansible group, smart groups included with the hosts their query
+// matches, and hosts outside a group land in ungrouped. Hosts carry
+// ansible_host, ansible_user, ansible_port, and
+// ansible_ssh_private_key_file after their group defaults are applied, and a
+// ProxyJump or ProxyCommand is passed on in ansible_ssh_common_args. Hosts
+// that connect through ~/.ssh/config are listed by alias alone, PS remoting
+// hosts use the winrm connection, and local hosts the local one. Archived
+// hosts and containers are left out.
+
+const ansibleHeader = "# Generated by assho export --ansible; edits are overwritten on the next export.\n"
+
+var ansibleGroupInvalid = regexp.MustCompile(`[^A-Za-z0-9_]+`)
+
+type ansibleGroup struct {
+	name  string
+	hosts []string // aliases
+}
+
+type ansibleVar struct {
+	key, value string
+}
+
+// ansibleGroupName turns a group name into a valid Ansible group name.
+func ansibleGroupName(name string) string {
+	name = strings.Trim(ansibleGroupInvalid.ReplaceAllString(strings.TrimSpace(name), "_"), "_")
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		name = "group_" + name
+	}
+	return name
+}
+
+// ansibleHostVars are the inventory variables for h, in a fixed order.
+func ansibleHostVars(h Host) []ansibleVar {
+	var vars []ansibleVar
+	add := func(key, value string) {
+		if value != "" {
+			vars = append(vars, ansibleVar{key, value})
+		}
+	}
+	switch {
+	case h.isLocal():
+		add("ansible_connection", "local")
+		return vars
+	case h.UseSSHConfig:
+		return nil
+	case h.Transport == transportPSRemoting:
+		add("ansible_connection", "winrm")
+	case h.Transport == transportPowerShell:
+		add("ansible_shell_type", "powershell")
+	}
+	if bare := bareHostname(h.Hostname); bare != h.Alias {
+		add("ansible_host", bare)
+	}
+	add("ansible_user", h.User)
+	if h.Port != "22" {
+		add("ansible_port", h.Port)
+	}
+	if h.Transport != transportPSRemoting {
+		add("ansible_ssh_private_key_file", h.IdentityFile)
+		switch {
+		case h.ProxyCommand != "":
+			add("ansible_ssh_common_args", "-o ProxyCommand="+shellQuote(h.ProxyCommand))
+		case h.ProxyJump != "":
+			add("ansible_ssh_common_args", "-o ProxyJump="+h.ProxyJump)
+		}
+	}
+	return vars
+}
+
+// ansibleInventory sorts hosts into Ansible groups, in config order with
+// ungrouped last, and returns the hosts to export by alias.
+func ansibleInventory(groups []Group, hosts []Host) ([]ansibleGroup, map[string]Host) {
+	exported := map[string]Host{}
+	var active []Host
+	for _, h := range hosts {
+		if h.Archived || h.IsContainer || h.Alias == "" {
+			continue
+		}
+		exported[h.Alias] = h
+		active = append(active, h)
+	}
+	used := map[string]bool{"all": true, "ungrouped": true}
+	grouped := map[string]bool{}
+	var out []ansibleGroup
+	for _, g := range groups {
+		members := groupMembers(groupItem{Group: g}, groups, active)
+		if len(members) == 0 {
+			continue
+		}
+		name := ansibleGroupName(g.Name)
+		for base, n := name, 2; used[name]; n++ {
+			name = fmt.Sprintf("%s_%d", base, n)
+		}
+		used[name] = true
+		ag := ansibleGroup{name: name}
+		for _, h := range members {
+			ag.hosts = append(ag.hosts, h.Alias)
+			if !g.Smart() {
+				grouped[h.Alias] = true
+			}
+		}
+		out = append(out, ag)
+	}
+	ungrouped := ansibleGroup{name: "ungrouped"}
+	for _, h := range active {
+		if !grouped[h.Alias] {
+			ungrouped.hosts = append(ungrouped.hosts, h.Alias)
+		}
+	}
+	if len(ungrouped.hosts) > 0 {
+		out = append(out, ungrouped)
+	}
+	return out, exported
+}
+
+// fprintAnsibleINI writes the inventory in Ansible's INI format. A host in
+// several groups carries its variables on its first line only.
+func fprintAnsibleINI(w io.Writer, groups []Group, hosts []Host) {
+	inventory, exported := ansibleInventory(groups, hosts)
+	fmt.Fprint(w, ansibleHeader)
+	written := map[string]bool{}
+	for _, g := range inventory {
+		fmt.Fprintf(w, "\n[%s]\n", g.name)
+		for _, alias := range g.hosts {
+			line := alias
+			if !written[alias] {
+				for _, v := range ansibleHostVars(exported[alias]) {
+					line += " " + v.key + "=" + shellQuote(v.value)
+				}
+				written[alias] = true
+			}
+			fmt.Fprintln(w, line)
+		}
+	}
+}
+
+// fprintAnsibleYAML writes the inventory in Ansible's YAML format.
+func fprintAnsibleYAML(w io.Writer, groups []Group, hosts []Host) {
+	inventory, exported := ansibleInventory(groups, hosts)
+	fmt.Fprint(w, ansibleHeader)
+	if len(inventory) == 0 {
+		fmt.Fprintln(w, "all: {}")
+		return
+	}
+	fmt.Fprintln(w, "all:\n  children:")
+	written := map[string]bool{}
+	for _, g := range inventory {
+		fmt.Fprintf(w, "    %s:\n      hosts:\n", g.name)
+		for _, alias := range g.hosts {
+			vars := ansibleHostVars(exported[alias])
+			if written[alias] || len(vars) == 0 {
+				fmt.Fprintf(w, "        %s:\n", yamlScalar(alias))
+				continue
+			}
+			written[alias] = true
+			fmt.Fprintf(w, "        %s:\n", yamlScalar(alias))
+			for _, v := range vars {
+				value := yamlScalar(v.value)
+				if v.key == "ansible_port" {
+					if _, err := strconv.Atoi(v.value); err == nil {
+						value = v.value
+					}
+				}
+				fmt.Fprintf(w, "          %s: %s\n", v.key, value)
+			}
+		}
+	}
+}
+
+var yamlPlain = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_./@~-]*$`)
+
+// yamlScalar writes s plain when YAML reads it back as the same string, and
+// double-quoted otherwise.
+func yamlScalar(s string) string {
+	switch strings.ToLower(s) {
+	case "y", "n", "yes", "no", "true", "false", "on", "off", "null", "~":
+	default:
+		if _, err := strconv.ParseFloat(s, 64); err != nil && yamlPlain.MatchString(s) {
+			return s
+		}
+	}
+	b, _ := json.Marshal(s)
+	return string(b)
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func ansibleTestConfig() ([]Group, []Host) {
+	groups := []Group{
+		{ID: "g1", Name: "Web Servers", DefaultUser: "deploy"},
+		{ID: "g2", Name: "behind bastion", Query: "jump=bastion"},
+		{ID: "g3", Name: "empty"},
+	}
+	hosts := []Host{
+		{ID: "a", Alias: "web1", Hostname: "10.0.0.1", Port: "22", GroupID: "g1", IdentityFile: "~/.ssh/id_web"},
+		{ID: "b", Alias: "web2", Hostname: "10.0.0.2", Port: "2222", GroupID: "g1", ProxyJump: "bastion"},
+		{ID: "c", Alias: "box", Hostname: "box", User: "root", Port: "22", ProxyCommand: "nc -X 5 -x 127.0.0.1:1080 %h %p"},
+		{ID: "d", Alias: "win", Hostname: "192.168.1.5", Port: "5985", Transport: transportPSRemoting, User: "admin"},
+		{ID: "e", Alias: "old", Hostname: "10.0.0.9", Archived: true},
+		{ID: "f", Alias: "cfg", Hostname: "cfg.example.com", UseSSHConfig: true},
+	}
+	return groups, withGroupDefaults(hosts, groups)
+}
+
+func TestFprintAnsibleINI(t *testing.T) {
+	groups, hosts := ansibleTestConfig()
+	var buf bytes.Buffer
+	fprintAnsibleINI(&buf, groups, hosts)
+	out := buf.String()
+	for _, want := range []string{
+		"\n[Web_Servers]\nweb1 ansible_host=10.0.0.1 ansible_user=deploy ansible_ssh_private_key_file='~/.ssh/id_web'\n" +
+			"web2 ansible_host=10.0.0.2 ansible_user=deploy ansible_port=2222 ansible_ssh_common_args='-o ProxyJump=bastion'\n",
+		"\n[behind_bastion]\nweb2\n",
+		`box ansible_user=root ansible_ssh_common_args='-o ProxyCommand='\''nc -X 5 -x 127.0.0.1:1080 %h %p'\'''` + "\n",
+		"win ansible_connection=winrm ansible_host=192.168.1.5 ansible_user=admin ansible_port=5985\n",
+		"cfg\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "old") || strings.Contains(out, "[empty]") {
+		t.Errorf("expected archived hosts and empty groups left out\n%s", out)
+	}
+}
+
+func TestFprintAnsibleYAML(t *testing.T) {
+	groups, hosts := ansibleTestConfig()
+	var buf bytes.Buffer
+	fprintAnsibleYAML(&buf, groups, hosts)
+	out := buf.String()
+	for _, want := range []string{
+		"all:\n  children:\n    Web_Servers:\n      hosts:\n        web1:\n          ansible_host: 10.0.0.1\n          ansible_user: deploy\n",
+		"        web2:\n          ansible_host: 10.0.0.2\n          ansible_user: deploy\n          ansible_port: 2222\n          ansible_ssh_common_args: \"-o ProxyJump=bastion\"\n",
+		"    behind_bastion:\n      hosts:\n        web2:\n    ungrouped:\n",
+		"        cfg:\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in\n%s", want, out)
+		}
+	}
+
+	buf.Reset()
+	fprintAnsibleYAML(&buf, nil, nil)
+	if !strings.HasSuffix(buf.String(), "all: {}\n") {
+		t.Fatalf("expected an empty inventory, got %q", buf.String())
+	}
+}
+
+func TestAnsibleNames(t *testing.T) {
+	for name, want := range map[string]string{"Web Servers": "Web_Servers", "db-prod": "db_prod", "2024": "group_2024", "--": "group_"} {
+		if got := ansibleGroupName(name); got != want {
+			t.Errorf("ansibleGroupName(%q) = %q, want %q", name, got, want)
+		}
+	}
+	for s, want := range map[string]string{"web-1.lan": "web-1.lan", "yes": `"yes"`, "1.5": `"1.5"`, "a b": `"a b"`} {
+		if got := yamlScalar(s); got != want {
+			t.Errorf("yamlScalar(%q) = %q, want %q", s, got, want)
+		}
+	}
+}
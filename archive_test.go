@@ -0,0 +1,62 @@
+package main
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestFlattenHostsHidesArchived(t *testing.T) {
+	hosts := []Host{
+		{ID: "a", Alias: "web"},
+		{ID: "b", Alias: "old", Archived: true, Pinned: true},
+	}
+	items := flattenHosts(nil, hosts)
+	if len(items) != 1 {
+		t.Fatalf("expected only the active host, got %d items", len(items))
+	}
+
+	items = flattenHostsImpl(nil, hosts, true, true)
+	if len(items) != 3 {
+		t.Fatalf("expected active host plus archived section, got %d items", len(items))
+	}
+	if g, ok := items[1].(groupItem); !ok || g.ID != "__archived__" || g.HostCount != 1 {
+		t.Fatalf("expected archived section header, got %#v", items[1])
+	}
+	if h, ok := items[2].(Host); !ok || h.ID != "b" {
+		t.Fatalf("expected archived host under its section, got %#v", items[2])
+	}
+}
+
+func TestArchiveToggleKeepsHostAndHistory(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("ASSHO_STORE_PASSWORD", "0")
+	m := model{
+		rawHosts:    []Host{{ID: "a", Alias: "web", Hostname: "10.0.0.1"}},
+		history:     []HistoryEntry{{HostID: "a", Alias: "web", Timestamp: 1}},
+		historyList: newTestHistoryListModel(),
+	}
+	m.list = newTestListModel(nil, m.rawHosts)
+
+	updated, _ := m.updateList(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("A")})
+	m = updated.(model)
+	if !m.rawHosts[0].Archived || len(m.list.Items()) != 0 || len(m.history) != 1 {
+		t.Fatalf("expected host archived and hidden with history kept: %+v, %d items", m.rawHosts[0], len(m.list.Items()))
+	}
+	_, hosts, history, err := loadConfig()
+	if err != nil || !hosts[0].Archived || len(history) != 1 {
+		t.Fatalf("expected archived flag and history persisted, got %+v %v %v", hosts, history, err)
+	}
+
+	updated, _ = m.updateList(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(".")})
+	m = updated.(model)
+	if len(m.list.Items()) != 2 {
+		t.Fatalf("expected archived section when showing archived, got %d items", len(m.list.Items()))
+	}
+	m.list.Select(1)
+	updated, _ = m.updateList(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("A")})
+	m = updated.(model)
+	if m.rawHosts[0].Archived {
+		t.Fatal("expected A on an archived host to restore it")
+	}
+}
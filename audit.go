@@ -0,0 +1,119 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// --- Connection Audit Log ---
+
+// auditMaxBytes is the size at which the active audit log is rotated. It is a
+// variable so tests can exercise rotation without writing megabytes.
+var auditMaxBytes int64 = 1 << 20
+
+const auditKeepFiles = 5
+
+type auditEntry struct {
+	Time   time.Time `json:"time"`
+	Action string    `json:"action"` // connect|test|scan
+	Alias  string    `json:"alias"`
+	User   string    `json:"user,omitempty"`
+	Target string    `json:"target"`
+	Result string    `json:"result"` // ok|error
+	Error  string    `json:"error,omitempty"`
+}
+
+// auditLogPath returns the audit log destination, or "" when auditing is off.
+// ASSHO_AUDIT_LOG accepts 1/true/yes for the default location or a file path.
+func auditLogPath() string {
+	value := strings.TrimSpace(os.Getenv("ASSHO_AUDIT_LOG"))
+	switch strings.ToLower(value) {
+	case "", "0", "false", "no":
+		return ""
+	case "1", "true", "yes":
+		return filepath.Join(filepath.Dir(getConfigPath()), "audit.log")
+	}
+	return expandPath(value)
+}
+
+// recordAudit appends one JSON line describing an SSH action. target is the
+// host the SSH connection is made to (the parent host for containers). Audit
+// failures never block the action being audited.
+func recordAudit(action, alias string, target Host, err error) {
+	path := auditLogPath()
+	if path == "" {
+		return
+	}
+	entry := auditEntry{
+		Time:   time.Now().UTC(),
+		Action: action,
+		Alias:  alias,
+		User:   target.User,
+		Target: auditTarget(target),
+		Result: "ok",
+	}
+	if err != nil {
+		entry.Result = "error"
+		entry.Error = err.Error()
+	}
+	_ = appendAuditEntry(path, entry)
+}
+
+func auditTarget(h Host) string {
+	port := h.Port
+	if port == "" {
+		port = "22"
+	}
+	return hostPort(h.Hostname, port)
+}
+
+func appendAuditEntry(path string, entry auditEntry) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	if err := rotateAuditLog(path); err != nil {
+		return err
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// rotateAuditLog shifts audit.log → audit.log.1 → … once the active file
+// reaches auditMaxBytes, discarding the oldest beyond auditKeepFiles.
+func rotateAuditLog(path string) error {
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if info.Size() < auditMaxBytes {
+		return nil
+	}
+	_ = os.Remove(fmt.Sprintf("%s.%d", path, auditKeepFiles))
+	for i := auditKeepFiles - 1; i >= 1; i-- {
+		older := fmt.Sprintf("%s.%d", path, i)
+		if _, err := os.Stat(older); err == nil {
+			if err := os.Rename(older, fmt.Sprintf("%s.%d", path, i+1)); err != nil {
+				return err
+			}
+		}
+	}
+	return os.Rename(path, path+".1")
+}
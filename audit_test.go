@@ -0,0 +1,85 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func readAuditEntries(t *testing.T, path string) []auditEntry {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read audit log: %v", err)
+	}
+	var entries []auditEntry
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var entry auditEntry
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("invalid audit line %q: %v", line, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestAuditLogDisabledByDefault(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("ASSHO_AUDIT_LOG", "")
+
+	recordAudit("test", "web", Host{Hostname: "10.0.0.1"}, nil)
+	if _, err := os.Stat(filepath.Join(home, ".config", "assho", "audit.log")); !os.IsNotExist(err) {
+		t.Fatalf("expected no audit log when disabled, got err=%v", err)
+	}
+}
+
+func TestAuditLogAppendsEntries(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("ASSHO_AUDIT_LOG", "1")
+
+	recordAudit("connect", "web", Host{Hostname: "10.0.0.1", User: "deploy"}, nil)
+	recordAudit("test", "db", Host{Hostname: "db.internal", Port: "2222", User: "root"}, errors.New("Permission denied"))
+
+	path := filepath.Join(home, ".config", "assho", "audit.log")
+	entries := readAuditEntries(t, path)
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 audit entries, got %d", len(entries))
+	}
+	if entries[0].Action != "connect" || entries[0].Target != "10.0.0.1:22" || entries[0].Result != "ok" {
+		t.Fatalf("unexpected first entry: %+v", entries[0])
+	}
+	if entries[1].Result != "error" || entries[1].Error != "Permission denied" || entries[1].Target != "db.internal:2222" {
+		t.Fatalf("unexpected second entry: %+v", entries[1])
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Fatalf("expected audit log mode 0600, got %04o", info.Mode().Perm())
+	}
+}
+
+func TestAuditLogCustomPathAndRotation(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "ssh-audit.log")
+	t.Setenv("ASSHO_AUDIT_LOG", path)
+	original := auditMaxBytes
+	auditMaxBytes = 64
+	t.Cleanup(func() { auditMaxBytes = original })
+
+	for i := 0; i < 10; i++ {
+		recordAudit("test", "web", Host{Hostname: "10.0.0.1"}, nil)
+	}
+	if _, err := os.Stat(path + ".1"); err != nil {
+		t.Fatalf("expected rotated audit log: %v", err)
+	}
+	if _, err := os.Stat(path + "." + "6"); !os.IsNotExist(err) {
+		t.Fatalf("expected at most %d rotated files", auditKeepFiles)
+	}
+}
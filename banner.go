@@ -0,0 +1,158 @@
+package main
+
+import (
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// --- Banner & MOTD ---
+
+// A connection test also captures what the server says on the way in: the
+// pre-auth banner, which ssh prints on stderr, and the message of the day,
+// which a non-interactive session skips, so the test reads it itself. The
+// last one seen is kept on the host for the detail pane, and the test result
+// calls out a banner that differs from last time, since an unexpected banner
+// is often the first clue that this is the wrong or a tampered-with box. The
+// MOTD is not compared: distributions regenerate it with load and dates.
+
+// HostBanner is what the server showed at the last successful test.
+type HostBanner struct {
+	Banner string `json:"banner,omitempty"` // sshd Banner, before authentication
+	MOTD   string `json:"motd,omitempty"`
+	SeenAt int64  `json:"seen_at,omitempty"`
+}
+
+const (
+	motdCommand    = "cat /run/motd.dynamic /etc/motd 2>/dev/null; exit 0"
+	maxBannerLines = 20
+	maxBannerWidth = 160
+)
+
+// sshNoise are the stderr lines ssh writes itself rather than the server.
+var sshNoise = []string{
+	"Warning: ", "** ", "Pseudo-terminal will not be allocated", "Connection to ",
+	"Authenticated to ", "Transferred: ", "Bytes per second: ", "Authentication succeeded",
+}
+
+// testCommand is what a connection test runs on h: the MOTD where there is
+// one to read, otherwise just exit.
+func testCommand(h Host) string {
+	if h.isWindows() || h.isLocal() {
+		return "exit"
+	}
+	return motdCommand
+}
+
+// parseBanner reads the banner from ssh's stderr and the MOTD from the
+// output of motdCommand. It returns nil when the server showed neither.
+func parseBanner(stdout, stderr string, now time.Time) *HostBanner {
+	var banner []string
+	for _, line := range strings.Split(stripSSHDebug(stderr), "\n") {
+		if !isSSHNoise(line) {
+			banner = append(banner, line)
+		}
+	}
+	b := HostBanner{Banner: cleanBanner(strings.Join(banner, "\n")), MOTD: cleanBanner(stdout)}
+	if b.Banner == "" && b.MOTD == "" {
+		return nil
+	}
+	b.SeenAt = now.Unix()
+	return &b
+}
+
+func isSSHNoise(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	for _, prefix := range sshNoise {
+		if strings.HasPrefix(trimmed, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// cleanBanner makes server text safe to draw: escape sequences and control
+// characters are dropped, blank runs collapse, and long text is cut off.
+func cleanBanner(text string) string {
+	text = ansi.Strip(strings.ReplaceAll(text, "\r", ""))
+	var lines []string
+	blank := true // skips leading blank lines
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimRightFunc(strings.Map(func(r rune) rune {
+			switch {
+			case r == '\t':
+				return ' '
+			case unicode.IsControl(r):
+				return -1
+			}
+			return r
+		}, line), unicode.IsSpace)
+		if line == "" {
+			if !blank {
+				lines = append(lines, "")
+			}
+			blank = true
+			continue
+		}
+		blank = false
+		if len(lines) == maxBannerLines {
+			lines = append(lines, "…")
+			break
+		}
+		lines = append(lines, ansi.Truncate(line, maxBannerWidth, "…"))
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// summary is the first line of the banner, or of the MOTD, for the
+// test result.
+func (b *HostBanner) summary() string {
+	if b == nil {
+		return ""
+	}
+	text := b.Banner
+	if text == "" {
+		text = b.MOTD
+	}
+	first, _, _ := strings.Cut(text, "\n")
+	return ansi.Truncate(strings.TrimSpace(first), 60, "…")
+}
+
+// setHostBanner caches b on the host and reports whether its pre-auth banner
+// differs from the one seen before. Saving is left to the caller, which
+// records test stats right after.
+func (m *model) setHostBanner(hostID string, b *HostBanner) bool {
+	idx := findHostIndexByID(m.rawHosts, hostID)
+	if idx == -1 {
+		return false
+	}
+	prev := m.rawHosts[idx].Banner
+	m.rawHosts[idx].Banner = b
+	if prev == nil {
+		return false
+	}
+	current := ""
+	if b != nil {
+		current = b.Banner
+	}
+	return prev.Banner != current
+}
+
+// bannerPreview indents at most limit lines of text for the detail pane.
+func bannerPreview(text string, limit int) string {
+	lines := strings.Split(text, "\n")
+	more := len(lines) - limit
+	if more > 0 {
+		lines = lines[:limit]
+	}
+	var b strings.Builder
+	for _, line := range lines {
+		b.WriteString("  " + line + "\n")
+	}
+	if more > 0 {
+		b.WriteString(formHintStyle.Render("  …") + "\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseBanner(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	stderr := "debug1: Connecting to db\n\x1b[31mAuthorized use only\x1b[0m\r\nAll activity is logged.\n" +
+		"Warning: Permanently added 'db' (ED25519) to the list of known hosts.\n" +
+		"Authenticated to db ([10.0.0.5]:22) using \"publickey\".\n"
+	stdout := "\n\nWelcome to Ubuntu 22.04\n\n\n\n * Documentation: https://help.ubuntu.com\x07\n"
+	got := parseBanner(stdout, stderr, now)
+	if got == nil {
+		t.Fatal("expected a banner")
+	}
+	if got.Banner != "Authorized use only\nAll activity is logged." {
+		t.Fatalf("unexpected banner %q", got.Banner)
+	}
+	if got.MOTD != "Welcome to Ubuntu 22.04\n\n * Documentation: https://help.ubuntu.com" {
+		t.Fatalf("unexpected MOTD %q", got.MOTD)
+	}
+	if got.SeenAt != now.Unix() || got.summary() != "Authorized use only" {
+		t.Fatalf("unexpected banner %+v", got)
+	}
+	if parseBanner("", "Warning: Permanently added 'db'\n", now) != nil {
+		t.Fatal("ssh's own warnings are not a banner")
+	}
+}
+
+func TestCleanBannerCutsLongText(t *testing.T) {
+	got := cleanBanner(strings.Repeat("line\n", maxBannerLines+5))
+	lines := strings.Split(got, "\n")
+	if len(lines) != maxBannerLines+1 || lines[maxBannerLines] != "…" {
+		t.Fatalf("expected %d lines and a marker, got %d", maxBannerLines, len(lines))
+	}
+}
+
+func TestTestCommandReadsMOTDOnlyOverSSH(t *testing.T) {
+	if testCommand(Host{Hostname: "db"}) != motdCommand {
+		t.Fatal("ssh hosts should read the MOTD")
+	}
+	for _, h := range []Host{{Transport: transportPowerShell}, {Transport: transportLocal}} {
+		if testCommand(h) != "exit" {
+			t.Fatalf("%s hosts should just exit", h.Transport)
+		}
+	}
+}
+
+func TestTestResultFlagsChangedBanner(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("ASSHO_STORE_PASSWORD", "0")
+	hosts := []Host{{ID: "h1", Alias: "web", Hostname: "10.0.0.1"}}
+	m := model{rawHosts: hosts, list: newTestListModel(nil, hosts), historyList: newTestHistoryListModel()}
+
+	updated, _ := m.Update(testConnectionMsg{hostID: "h1", banner: &HostBanner{Banner: "Authorized use only", MOTD: "load 0.1"}})
+	m = updated.(model)
+	if m.rawHosts[0].Banner == nil || !strings.Contains(m.form.testStatus, "Authorized use only") {
+		t.Fatalf("expected the banner to be cached and shown, got %q", m.form.testStatus)
+	}
+	if strings.Contains(m.form.testStatus, "changed") {
+		t.Fatal("a first banner is not a change")
+	}
+
+	updated, _ = m.Update(testConnectionMsg{hostID: "h1", banner: &HostBanner{Banner: "Authorized use only", MOTD: "load 0.7"}})
+	m = updated.(model)
+	if strings.Contains(m.form.testStatus, "changed") {
+		t.Fatal("a different MOTD alone should not be flagged")
+	}
+
+	updated, _ = m.Update(testConnectionMsg{hostID: "h1", banner: &HostBanner{Banner: "Welcome to honeypot"}})
+	m = updated.(model)
+	if !strings.Contains(m.form.testStatus, "banner changed since the last test") {
+		t.Fatalf("expected a changed banner to be flagged, got %q", m.form.testStatus)
+	}
+}
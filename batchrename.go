@@ -0,0 +1,306 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// --- Batch Rename ---
+
+// R renames many aliases, or group names, with one find/replace. ctrl+r
+// switches to a regular expression whose groups expand as $1, $2, ... in the
+// replacement. Only checked rows change, and every rename is previewed before
+// enter applies it. Opening it on a group header checks just that group.
+
+type batchRenameTarget int
+
+const (
+	batchRenameAliases batchRenameTarget = iota
+	batchRenameGroups
+)
+
+const (
+	batchFocusFind = iota
+	batchFocusReplace
+	batchFocusList
+)
+
+type batchRenameItem struct {
+	id       string
+	name     string
+	selected bool
+}
+
+type batchRenameChange struct {
+	id   string
+	from string
+	to   string
+}
+
+type batchRenameState struct {
+	target  batchRenameTarget
+	items   []batchRenameItem
+	find    textinput.Model
+	replace textinput.Model
+	regex   bool
+	focus   int
+	cursor  int
+	err     string
+}
+
+func (t batchRenameTarget) label() string {
+	if t == batchRenameGroups {
+		return "groups"
+	}
+	return "aliases"
+}
+
+// planBatchRename applies the pattern to the checked items. Unchecked items
+// keep their names but still count when looking for duplicates.
+func planBatchRename(items []batchRenameItem, find, replace string, regex bool) ([]batchRenameChange, error) {
+	if find == "" {
+		return nil, nil
+	}
+	rename := func(name string) string { return strings.ReplaceAll(name, find, replace) }
+	if regex {
+		re, err := regexp.Compile(find)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern: %v", err)
+		}
+		rename = func(name string) string { return re.ReplaceAllString(name, replace) }
+	}
+	var changes []batchRenameChange
+	seen := map[string]bool{}
+	for _, item := range items {
+		name := item.name
+		if item.selected {
+			if renamed := strings.TrimSpace(rename(item.name)); renamed != item.name {
+				if renamed == "" {
+					return nil, fmt.Errorf("%s would be left without a name", item.name)
+				}
+				changes = append(changes, batchRenameChange{id: item.id, from: item.name, to: renamed})
+				name = renamed
+			}
+		}
+		key := strings.ToLower(strings.TrimSpace(name))
+		if key == "" {
+			continue
+		}
+		if seen[key] {
+			return nil, fmt.Errorf("%s would be used twice", name)
+		}
+		seen[key] = true
+	}
+	return changes, nil
+}
+
+func (m model) batchRenameItems(target batchRenameTarget, checked map[string]bool) []batchRenameItem {
+	var items []batchRenameItem
+	if target == batchRenameGroups {
+		for _, g := range m.rawGroups {
+			items = append(items, batchRenameItem{id: g.ID, name: g.Name, selected: checked == nil || checked[g.ID]})
+		}
+		return items
+	}
+	for _, h := range m.rawHosts {
+		items = append(items, batchRenameItem{id: h.ID, name: h.Alias, selected: checked == nil || checked[h.ID]})
+	}
+	return items
+}
+
+func (m model) openBatchRename() (tea.Model, tea.Cmd) {
+	m.clearListDeleteConfirm()
+	var checked map[string]bool
+	if g, ok := m.list.SelectedItem().(groupItem); ok {
+		checked = map[string]bool{}
+		for _, h := range groupMembers(g, m.rawGroups, m.rawHosts) {
+			checked[h.ID] = true
+		}
+	}
+	m.batchRename = batchRenameState{
+		target:  batchRenameAliases,
+		items:   m.batchRenameItems(batchRenameAliases, checked),
+		find:    newPromptInput("  Find     ", "-dc1"),
+		replace: newPromptInput("  Replace  ", "(empty removes the match)"),
+	}
+	m.state = stateBatchRename
+	return m, m.batchRename.find.Focus()
+}
+
+func (m *model) focusBatchRename(focus int) tea.Cmd {
+	m.batchRename.focus = focus
+	m.batchRename.find.Blur()
+	m.batchRename.replace.Blur()
+	switch focus {
+	case batchFocusFind:
+		return m.batchRename.find.Focus()
+	case batchFocusReplace:
+		return m.batchRename.replace.Focus()
+	}
+	return nil
+}
+
+func (m model) updateBatchRename(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	s := &m.batchRename
+	switch msg.String() {
+	case "ctrl+c":
+		m.quitting = true
+		return m, tea.Quit
+	case "esc":
+		m.state = stateList
+		return m, nil
+	case "tab":
+		return m, m.focusBatchRename((s.focus + 1) % 3)
+	case "shift+tab":
+		return m, m.focusBatchRename((s.focus + 2) % 3)
+	case "ctrl+r":
+		s.regex = !s.regex
+		s.err = ""
+		return m, nil
+	case "ctrl+g":
+		if s.target == batchRenameAliases {
+			s.target = batchRenameGroups
+		} else {
+			s.target = batchRenameAliases
+		}
+		s.items = m.batchRenameItems(s.target, nil)
+		s.cursor = 0
+		s.err = ""
+		return m, nil
+	case "enter":
+		return m.applyBatchRename()
+	}
+	if s.focus == batchFocusList {
+		switch msg.String() {
+		case "up", "k":
+			if s.cursor > 0 {
+				s.cursor--
+			}
+		case "down", "j":
+			if s.cursor < len(s.items)-1 {
+				s.cursor++
+			}
+		case " ", "space":
+			if s.cursor < len(s.items) {
+				s.items[s.cursor].selected = !s.items[s.cursor].selected
+			}
+		case "a":
+			all := false
+			for _, item := range s.items {
+				if !item.selected {
+					all = true
+				}
+			}
+			for i := range s.items {
+				s.items[i].selected = all
+			}
+		}
+		return m, nil
+	}
+	var cmd tea.Cmd
+	if s.focus == batchFocusFind {
+		s.find, cmd = s.find.Update(msg)
+	} else {
+		s.replace, cmd = s.replace.Update(msg)
+	}
+	s.err = ""
+	return m, cmd
+}
+
+func (m model) applyBatchRename() (tea.Model, tea.Cmd) {
+	s := &m.batchRename
+	changes, err := planBatchRename(s.items, s.find.Value(), s.replace.Value(), s.regex)
+	if err != nil {
+		s.err = err.Error()
+		return m, nil
+	}
+	if len(changes) == 0 {
+		s.err = "nothing to rename"
+		return m, nil
+	}
+	snapshot := m.snapshot()
+	for _, c := range changes {
+		if s.target == batchRenameGroups {
+			for i := range m.rawGroups {
+				if m.rawGroups[i].ID == c.id {
+					m.rawGroups[i].Name = c.to
+				}
+			}
+		} else if idx := findHostIndexByID(m.rawHosts, c.id); idx != -1 {
+			m.rawHosts[idx].Alias = c.to
+		}
+	}
+	m.refreshList()
+	if err := m.save(); err != nil {
+		m.restoreSnapshot(snapshot)
+		s.err = fmt.Sprintf("failed to save renames: %v", err)
+		return m, nil
+	}
+	m.rebuildHistoryList()
+	m.state = stateList
+	m.status.message = fmt.Sprintf("Renamed %d %s", len(changes), s.target.label())
+	m.status.isError = false
+	m.status.version++
+	return m, statusClearCmd(m.status.version)
+}
+
+func (m model) renderBatchRenameView() string {
+	width, height := normalizedSize(m.width, m.height)
+	inner := max(min(width-6, 100)-6, 24)
+	s := m.batchRename
+	mode := "text"
+	if s.regex {
+		mode = "regex"
+	}
+	var b strings.Builder
+	b.WriteString(lipgloss.NewStyle().Bold(true).Foreground(colorText).Render("BATCH RENAME · "+s.target.label()) + "\n")
+	b.WriteString(formHintStyle.Render(ansi.Truncate("match as "+mode, inner, "…")) + "\n\n")
+	for _, input := range []textinput.Model{s.find, s.replace} {
+		input.Width = max(inner-lipgloss.Width(input.Prompt)-1, 1)
+		b.WriteString(ansi.Truncate(input.View(), inner, "…") + "\n")
+	}
+	b.WriteString("\n")
+
+	changes, err := planBatchRename(s.items, s.find.Value(), s.replace.Value(), s.regex)
+	renamed := map[string]string{}
+	for _, c := range changes {
+		renamed[c.id] = c.to
+	}
+	maxRows := max(height-14, 2)
+	start := 0
+	if s.cursor >= maxRows {
+		start = s.cursor - maxRows + 1
+	}
+	end := min(start+maxRows, len(s.items))
+	for idx := start; idx < end; idx++ {
+		item := s.items[idx]
+		mark := "[ ]"
+		if item.selected {
+			mark = "[x]"
+		}
+		label := mark + " " + item.name
+		if to, ok := renamed[item.id]; ok {
+			label += " → " + to
+		}
+		b.WriteString(selectionLine(s.focus == batchFocusList && idx == s.cursor, ansi.Truncate(label, inner-2, "…")) + "\n")
+	}
+	if end < len(s.items) {
+		b.WriteString(formHintStyle.Render(fmt.Sprintf("… %d more", len(s.items)-end)) + "\n")
+	}
+	switch {
+	case s.err != "":
+		b.WriteString(testFailStyle.Render(ansi.Truncate("✘ "+s.err, inner, "…")) + "\n")
+	case err != nil:
+		b.WriteString(testFailStyle.Render(ansi.Truncate("✘ "+err.Error(), inner, "…")) + "\n")
+	case len(changes) > 0:
+		b.WriteString(testSuccessStyle.Render(fmt.Sprintf("%d to rename", len(changes))) + "\n")
+	}
+	b.WriteString("\n" + helpEntry("tab", "focus") + "  " + helpEntry("space", "check") + "  " + helpEntry("ctrl+r", "regex") + "  " + helpEntry("ctrl+g", "groups") + "  " + helpEntry("enter", "apply") + "  " + helpEntry("esc", "cancel"))
+	return centeredWorkspace(b.String(), width, height)
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestPlanBatchRename(t *testing.T) {
+	items := []batchRenameItem{
+		{id: "h1", name: "web-01-dc1", selected: true},
+		{id: "h2", name: "web-02-dc1", selected: true},
+		{id: "h3", name: "db-01-dc1"},
+	}
+	changes, err := planBatchRename(items, "-dc1", "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 2 || changes[0].to != "web-01" || changes[1].to != "web-02" {
+		t.Fatalf("expected the checked aliases stripped, got %+v", changes)
+	}
+
+	changes, err = planBatchRename(items, `^(\w+)-(\d+)-dc1$`, "$1$2.ams", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 2 || changes[0].to != "web01.ams" {
+		t.Fatalf("expected regex groups expanded, got %+v", changes)
+	}
+
+	if _, err := planBatchRename(items, "[", "", true); err == nil || !strings.Contains(err.Error(), "invalid pattern") {
+		t.Fatalf("expected an invalid pattern error, got %v", err)
+	}
+	if _, err := planBatchRename(items, `-\d+`, "", true); err == nil || !strings.Contains(err.Error(), "used twice") {
+		t.Fatalf("expected a duplicate alias error, got %v", err)
+	}
+	if _, err := planBatchRename(items, "web-01-dc1", "", false); err == nil || !strings.Contains(err.Error(), "without a name") {
+		t.Fatalf("expected an empty alias error, got %v", err)
+	}
+}
+
+func TestPlanBatchRenameChecksUncheckedNames(t *testing.T) {
+	items := []batchRenameItem{
+		{id: "h1", name: "web-dc1", selected: true},
+		{id: "h2", name: "WEB"},
+	}
+	if _, err := planBatchRename(items, "-dc1", "", false); err == nil {
+		t.Fatal("expected a clash with an unchecked alias")
+	}
+}
+
+func TestBatchRenameAppliesGroupScope(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("ASSHO_STORE_PASSWORD", "0")
+	groups := []Group{{ID: "g1", Name: "ams", Expanded: true}}
+	hosts := []Host{
+		{ID: "h1", Alias: "web-dc1", Hostname: "10.0.0.1", GroupID: "g1"},
+		{ID: "h2", Alias: "db-dc1", Hostname: "10.0.0.2"},
+	}
+	m := model{rawGroups: groups, rawHosts: hosts, list: newTestListModel(groups, hosts), historyList: newTestHistoryListModel()}
+	m.list.Select(1) // ungrouped hosts are listed before the group
+	next, _ := m.openBatchRename()
+	m = next.(model)
+	if m.state != stateBatchRename || !m.batchRename.items[0].selected || m.batchRename.items[1].selected {
+		t.Fatalf("expected only the group's hosts checked, got %+v", m.batchRename.items)
+	}
+	m.batchRename.find.SetValue("-dc1")
+	next, _ = m.updateBatchRename(tea.KeyMsg{Type: tea.KeyEnter})
+	m = next.(model)
+	if m.state != stateList || m.rawHosts[0].Alias != "web" || m.rawHosts[1].Alias != "db-dc1" {
+		t.Fatalf("expected only web renamed, got %+v", m.rawHosts)
+	}
+	_, saved, _, err := loadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved[0].Alias != "web" {
+		t.Fatalf("expected the rename saved, got %+v", saved)
+	}
+}
+
+func TestBatchRenameGroups(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("ASSHO_STORE_PASSWORD", "0")
+	groups := []Group{{ID: "g1", Name: "dc1-web"}, {ID: "g2", Name: "dc1-db"}}
+	m := model{rawGroups: groups, list: newTestListModel(groups, nil), historyList: newTestHistoryListModel()}
+	next, _ := m.openBatchRename()
+	m = next.(model)
+	next, _ = m.updateBatchRename(tea.KeyMsg{Type: tea.KeyCtrlG})
+	m = next.(model)
+	m.batchRename.find.SetValue("dc1-")
+	next, _ = m.updateBatchRename(tea.KeyMsg{Type: tea.KeyEnter})
+	m = next.(model)
+	if m.rawGroups[0].Name != "web" || m.rawGroups[1].Name != "db" {
+		t.Fatalf("expected both groups renamed, got %+v", m.rawGroups)
+	}
+}
+
+func TestBatchRenameViewFitsTerminal(t *testing.T) {
+	var items []batchRenameItem
+	for i := range 30 {
+		items = append(items, batchRenameItem{id: fmt.Sprint(i), name: fmt.Sprintf("very-long-production-host-alias-%02d-dc1", i), selected: true})
+	}
+	for _, size := range []struct{ width, height int }{{40, 16}, {80, 24}, {140, 40}} {
+		s := batchRenameState{items: items, cursor: 20, focus: batchFocusList, find: newPromptInput("  Find     ", ""), replace: newPromptInput("  Replace  ", "")}
+		s.find.SetValue("-dc1")
+		m := model{width: size.width, height: size.height, batchRename: s}
+		out := m.renderBatchRenameView()
+		lines := strings.Split(out, "\n")
+		if len(lines) > size.height {
+			t.Fatalf("%dx%d: got %d lines", size.width, size.height, len(lines))
+		}
+		for i, line := range lines {
+			if ansi.StringWidth(line) > size.width {
+				t.Fatalf("%dx%d line %d has width %d", size.width, size.height, i, ansi.StringWidth(line))
+			}
+		}
+		if !strings.Contains(out, "› ") {
+			t.Fatalf("%dx%d: expected the cursor row to stay visible", size.width, size.height)
+		}
+	}
+}
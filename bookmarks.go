@@ -0,0 +1,247 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// --- Web UI Bookmarks ---
+
+// forwardedPortPlaceholder in a bookmark is replaced with the local port of
+// the host's LocalForward, so http://localhost:{forwarded_port} follows it.
+const forwardedPortPlaceholder = "{forwarded_port}"
+
+type webOpenedMsg struct {
+	url    string
+	tunnel bool
+	err    error
+}
+
+// openURL launches the desktop browser; tests replace it.
+var openURL = func(target string) error {
+	name := "xdg-open"
+	if runtime.GOOS == "darwin" {
+		name = "open"
+	}
+	return exec.Command(name, target).Start()
+}
+
+// parseWebURLs splits the form value on commas and whitespace.
+func parseWebURLs(value string) []string {
+	fields := strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' })
+	if len(fields) == 0 {
+		return nil
+	}
+	return fields
+}
+
+func validateWebURLs(urls []string) error {
+	for _, raw := range urls {
+		u, err := url.Parse(strings.ReplaceAll(raw, forwardedPortPlaceholder, "1"))
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("web UI %q must be an http(s) URL", raw)
+		}
+	}
+	return nil
+}
+
+// localForwardPort returns the listening port of a LocalForward spec such
+// as 8080:localhost:80 or [::1]:8080:localhost:80.
+func localForwardPort(spec string) string {
+	parts := splitForwardSpec(spec)
+	switch len(parts) {
+	case 3:
+		return parts[0]
+	case 4:
+		return parts[1]
+	default:
+		return ""
+	}
+}
+
+// resolveWebURL expands the placeholder and reports whether the URL points at
+// the host's local tunnel and therefore needs it running.
+func resolveWebURL(h Host, raw string) (string, bool) {
+	port := localForwardPort(h.LocalForward)
+	if port != "" {
+		raw = strings.ReplaceAll(raw, forwardedPortPlaceholder, port)
+	}
+	u, err := url.Parse(raw)
+	if err != nil || port == "" {
+		return raw, false
+	}
+	switch u.Hostname() {
+	case "localhost", "127.0.0.1", "::1":
+		return raw, u.Port() == port
+	}
+	return raw, false
+}
+
+func tunnelListening(port string) bool {
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort("127.0.0.1", port), 300*time.Millisecond)
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
+
+// buildTunnelArgs starts a forward-only session that backgrounds itself once
+// the listener is bound, so the tunnel outlives assho.
+func buildTunnelArgs(h Host) []string {
+	return tunnelArgs(h, "-L", h.LocalForward)
+}
+
+// tunnelArgs is buildTunnelArgs with the forwards, and any other options,
+// given as extra arguments.
+func tunnelArgs(h Host, extra ...string) []string {
+	args := []string{"-f", "-N", "-o", "ExitOnForwardFailure=yes", "-o", connectTimeoutOption(max(10, h.connectTimeout())), "-o", "StrictHostKeyChecking=yes"}
+	args = append(args, pinnedKnownHostsArgs(h)...)
+	if h.Password == "" {
+		args = append(args, "-o", "BatchMode=yes")
+	}
+	if h.User != "" {
+		args = append(args, "-l", h.User)
+	}
+	if h.Port != "" {
+		args = append(args, "-p", h.Port)
+	}
+	if h.IdentityFile != "" {
+		args = append(args, "-i", expandPath(h.IdentityFile))
+	}
+	args = append(args, identityAgentArgs(h)...)
+	args = append(args, proxyArgs(h)...)
+	args = append(args, extra...)
+	return append(args, bareHostname(h.Hostname))
+}
+
+func openWebURLTrusted(h Host, raw string) tea.Cmd {
+	return func() tea.Msg {
+		h = withPassword(h)
+		target, needsTunnel := resolveWebURL(h, raw)
+		started := false
+		if needsTunnel && !tunnelListening(localForwardPort(h.LocalForward)) {
+			binary, args, extraEnv, _ := buildSSHCommand(h.Password, buildTunnelArgs(h))
+			cmd := exec.Command(binary, args...)
+			cmd.Env = append(cmd.Environ(), extraEnv...)
+			output, err := cmd.CombinedOutput()
+			recordAudit("tunnel", h.Alias, h, err)
+			if err != nil {
+				if out := strings.TrimSpace(string(output)); out != "" {
+					err = errors.New(out)
+				}
+				return webOpenedMsg{url: target, err: fmt.Errorf("tunnel failed: %w", err)}
+			}
+			started = true
+		}
+		return webOpenedMsg{url: target, tunnel: started, err: openURL(target)}
+	}
+}
+
+func (m model) openWebURL(h Host, raw string) (tea.Model, tea.Cmd) {
+	_, needsTunnel := resolveWebURL(h, raw)
+	if !needsTunnel {
+		return m, openWebURLTrusted(h, raw)
+	}
+	return m, checkHostTrustCmd(pendingSSHAction{kind: sshActionOpenWeb, host: h, trustHost: h, inv: m.inventory(), webURL: raw})
+}
+
+func (m model) openBookmarks(h Host) (tea.Model, tea.Cmd) {
+	m.clearListDeleteConfirm()
+	switch len(h.WebURLs) {
+	case 0:
+		m.status.message = "No web UI bookmarks for " + h.Alias + " — add one in the host form"
+		m.status.isError = true
+		m.status.version++
+		return m, statusClearCmd(m.status.version)
+	case 1:
+		return m.openWebURL(h, h.WebURLs[0])
+	}
+	m.bookmarks = bookmarkPickerState{hostID: h.ID}
+	m.state = stateBookmarks
+	return m, nil
+}
+
+func (m model) handleWebOpened(msg webOpenedMsg) (tea.Model, tea.Cmd) {
+	if msg.err != nil {
+		m.status.message = "Open web UI failed: " + msg.err.Error()
+		m.status.isError = true
+	} else if msg.tunnel {
+		m.status.message = "Tunnel started · opened " + msg.url
+		m.status.isError = false
+	} else {
+		m.status.message = "Opened " + msg.url
+		m.status.isError = false
+	}
+	m.status.version++
+	return m, statusClearCmd(m.status.version)
+}
+
+// --- Bookmark Picker ---
+
+type bookmarkPickerState struct {
+	hostID string
+	cursor int
+}
+
+func (m model) updateBookmarks(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	idx := findHostIndexByID(m.rawHosts, m.bookmarks.hostID)
+	if idx == -1 {
+		m.state = stateList
+		return m, nil
+	}
+	h := m.rawHosts[idx]
+	switch msg.String() {
+	case "ctrl+c":
+		m.quitting = true
+		return m, tea.Quit
+	case "esc", "q", "u":
+		m.state = stateList
+	case "up", "k":
+		if m.bookmarks.cursor > 0 {
+			m.bookmarks.cursor--
+		}
+	case "down", "j":
+		if m.bookmarks.cursor < len(h.WebURLs)-1 {
+			m.bookmarks.cursor++
+		}
+	case "enter":
+		if m.bookmarks.cursor < len(h.WebURLs) {
+			m.state = stateList
+			return m.openWebURL(h, h.WebURLs[m.bookmarks.cursor])
+		}
+	}
+	return m, nil
+}
+
+func (m model) renderBookmarksView() string {
+	width, height := normalizedSize(m.width, m.height)
+	inner := max(min(width-6, 100)-6, 24)
+	idx := findHostIndexByID(m.rawHosts, m.bookmarks.hostID)
+	if idx == -1 {
+		return centeredWorkspace(testFailStyle.Render("✘ Host no longer exists")+"\n\n"+helpEntry("esc", "back"), width, height)
+	}
+	h := m.rawHosts[idx]
+	var b strings.Builder
+	b.WriteString(lipgloss.NewStyle().Bold(true).Foreground(colorText).Render("OPEN WEB UI") + "\n")
+	b.WriteString(formHintStyle.Render(ansi.Truncate(h.Alias+" · "+sshTarget(h), inner, "…")) + "\n\n")
+	for i, raw := range h.WebURLs {
+		target, needsTunnel := resolveWebURL(h, raw)
+		if needsTunnel {
+			target += " (via tunnel)"
+		}
+		b.WriteString(selectionLine(i == m.bookmarks.cursor, ansi.Truncate(target, inner-2, "…")) + "\n")
+	}
+	b.WriteString("\n" + helpEntry("enter", "open") + "  " + helpEntry("esc", "back"))
+	return centeredWorkspace(b.String(), width, height)
+}
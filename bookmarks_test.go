@@ -0,0 +1,96 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestResolveWebURLExpandsForwardedPort(t *testing.T) {
+	h := Host{LocalForward: "127.0.0.1:3000:grafana.internal:3000"}
+	target, tunnel := resolveWebURL(h, "http://localhost:{forwarded_port}/d/home")
+	if target != "http://localhost:3000/d/home" || !tunnel {
+		t.Fatalf("expected tunnelled localhost URL, got %q tunnel=%v", target, tunnel)
+	}
+	if _, tunnel := resolveWebURL(h, "https://pve.example.com:8006"); tunnel {
+		t.Fatal("remote URLs must not require the tunnel")
+	}
+	if _, tunnel := resolveWebURL(Host{}, "http://localhost:8080"); tunnel {
+		t.Fatal("hosts without LocalForward cannot need a tunnel")
+	}
+}
+
+func TestValidateWebURLs(t *testing.T) {
+	if err := validateWebURLs(parseWebURLs("http://localhost:{forwarded_port}, https://router.lan")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := validateWebURLs([]string{"router.lan"}); err == nil {
+		t.Fatal("expected error for URL without scheme")
+	}
+}
+
+func TestBuildTunnelArgs(t *testing.T) {
+	args := strings.Join(buildTunnelArgs(Host{Hostname: "10.0.0.1", User: "root", ProxyJump: "bastion", LocalForward: "8006:localhost:8006"}), " ")
+	for _, want := range []string{"-f -N", "ExitOnForwardFailure=yes", "BatchMode=yes", "-J bastion", "-L 8006:localhost:8006 10.0.0.1"} {
+		if !strings.Contains(args, want) {
+			t.Errorf("tunnel args %q missing %q", args, want)
+		}
+	}
+}
+
+func TestOpenBookmarkWithoutTunnel(t *testing.T) {
+	var opened string
+	original := openURL
+	openURL = func(target string) error { opened = target; return nil }
+	t.Cleanup(func() { openURL = original })
+
+	h := Host{ID: "h1", Alias: "router", Hostname: "192.168.1.1", WebURLs: []string{"https://192.168.1.1"}}
+	m := model{rawHosts: []Host{h}}
+	m.list = newTestListModel(nil, m.rawHosts)
+
+	updated, cmd := m.updateList(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("u")})
+	if cmd == nil {
+		t.Fatal("expected open command")
+	}
+	msg := cmd().(webOpenedMsg)
+	if opened != "https://192.168.1.1" || msg.err != nil || msg.tunnel {
+		t.Fatalf("unexpected open result %q %+v", opened, msg)
+	}
+	updated, _ = updated.(model).handleWebOpened(msg)
+	if got := updated.(model).status.message; !strings.Contains(got, "Opened https://192.168.1.1") {
+		t.Fatalf("unexpected status %q", got)
+	}
+}
+
+func TestBookmarkPickerForMultipleURLs(t *testing.T) {
+	h := Host{ID: "h1", Alias: "pve", Hostname: "10.0.0.2", LocalForward: "8006:localhost:8006", WebURLs: []string{"https://localhost:{forwarded_port}", "https://10.0.0.2:9090"}}
+	m := model{rawHosts: []Host{h}, width: 80, height: 24}
+	updated, _ := m.openBookmarks(h)
+	m = updated.(model)
+	if m.state != stateBookmarks {
+		t.Fatalf("expected bookmark picker, got state %v", m.state)
+	}
+	view := m.renderBookmarksView()
+	if !strings.Contains(view, "https://localhost:8006 (via tunnel)") {
+		t.Fatalf("expected expanded tunnelled URL in picker:\n%s", view)
+	}
+}
+
+func TestWebURLsSavedFromForm(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("ASSHO_STORE_PASSWORD", "0")
+	m := model{form: formState{inputs: newFormInputs()}, historyList: newTestHistoryListModel()}
+	m.list = newTestListModel(nil, nil)
+	m.form.inputs[fieldAlias].SetValue("grafana")
+	m.form.inputs[fieldHostname].SetValue("10.0.0.3")
+	m.form.inputs[fieldWebURLs].SetValue("http://localhost:{forwarded_port}, https://grafana.lan")
+	m.buildGroupOptions("")
+
+	if err := m.saveFromForm(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := m.rawHosts[0].WebURLs; len(got) != 2 || got[1] != "https://grafana.lan" {
+		t.Fatalf("unexpected saved web URLs %v", got)
+	}
+}
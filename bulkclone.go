@@ -0,0 +1,240 @@
+package main
+
+import (
+	"fmt"
+	"net/netip"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// --- Bulk Clone ---
+
+// C on a host asks for a numeric range (3-10, 03..10) or a list of hostnames
+// and stamps out one copy per entry. Aliases continue the source's trailing
+// number, so web-01 becomes web-02, web-03, and so on.
+
+const maxBulkClones = 100
+
+var (
+	bulkRangePattern = regexp.MustCompile(`^(\d+)\s*(?:-|\.\.)\s*(\d+)$`)
+	trailingDigits   = regexp.MustCompile(`\d+$`)
+)
+
+type bulkCloneState struct {
+	hostID string
+	input  textinput.Model
+}
+
+// splitAliasNumber splits web-01 into "web-", 1, and width 2. Aliases without
+// a trailing number get a "-" separator and width 0.
+func splitAliasNumber(alias string) (stem string, n, width int) {
+	digits := trailingDigits.FindString(alias)
+	if digits == "" {
+		return alias + "-", 0, 0
+	}
+	n, _ = strconv.Atoi(digits)
+	return strings.TrimSuffix(alias, digits), n, len(digits)
+}
+
+func padNumber(n, width int) string {
+	return fmt.Sprintf("%0*d", width, n)
+}
+
+// cloneHostname swaps the source's number into the first DNS label of the
+// hostname, so web-01.example.com follows its alias. IP literals and
+// hostnames without that number are kept as they are.
+func cloneHostname(hostname, number, replacement string) string {
+	if number == "" {
+		return hostname
+	}
+	if _, err := netip.ParseAddr(bareHostname(hostname)); err == nil {
+		return hostname
+	}
+	label, rest, _ := strings.Cut(hostname, ".")
+	idx := strings.LastIndex(label, number)
+	if idx == -1 {
+		return hostname
+	}
+	label = label[:idx] + replacement + label[idx+len(number):]
+	if rest == "" {
+		return label
+	}
+	return label + "." + rest
+}
+
+// planBulkClone builds the clones of src described by spec without touching
+// hosts. Aliases that are already taken are reported as an error; hostname
+// lists skip over them instead.
+func planBulkClone(src Host, spec string, hosts []Host) ([]Host, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return nil, fmt.Errorf("enter a range like 2-10 or a list of hostnames")
+	}
+	stem, srcNum, width := splitAliasNumber(src.Alias)
+	srcDigits := ""
+	if width > 0 {
+		srcDigits = trailingDigits.FindString(src.Alias)
+	}
+
+	type entry struct {
+		n        int
+		hostname string
+	}
+	var entries []entry
+	if match := bulkRangePattern.FindStringSubmatch(spec); match != nil {
+		from, _ := strconv.Atoi(match[1])
+		to, _ := strconv.Atoi(match[2])
+		if to < from {
+			return nil, fmt.Errorf("range end must not be below its start")
+		}
+		if to-from+1 > maxBulkClones {
+			return nil, fmt.Errorf("at most %d clones at a time", maxBulkClones)
+		}
+		if width == 0 && strings.HasPrefix(match[1], "0") {
+			width = len(match[1])
+		}
+		for n := from; n <= to; n++ {
+			// A range such as 1-10 from web-01 includes the source itself.
+			if !strings.EqualFold(stem+padNumber(n, width), src.Alias) {
+				entries = append(entries, entry{n: n})
+			}
+		}
+	} else {
+		names := parseWebURLs(spec)
+		if len(names) > maxBulkClones {
+			return nil, fmt.Errorf("at most %d clones at a time", maxBulkClones)
+		}
+		n := srcNum
+		for _, name := range names {
+			if err := validateHostname(name); err != nil {
+				return nil, err
+			}
+			n++
+			for aliasTaken(hosts, stem+padNumber(n, width), "") {
+				n++
+			}
+			entries = append(entries, entry{n: n, hostname: bareHostname(name)})
+		}
+	}
+
+	clones := make([]Host, 0, len(entries))
+	seen := map[string]bool{}
+	for _, e := range entries {
+		number := padNumber(e.n, width)
+		alias := stem + number
+		if aliasTaken(hosts, alias, "") || seen[strings.ToLower(alias)] {
+			return nil, fmt.Errorf("alias already exists: %s", alias)
+		}
+		seen[strings.ToLower(alias)] = true
+		clone := src
+		clone.ID = newHostID()
+		clone.Alias = alias
+		clone.Hostname = e.hostname
+		if clone.Hostname == "" {
+			clone.Hostname = cloneHostname(src.Hostname, srcDigits, number)
+		}
+		clone.Containers = nil
+		clone.Expanded = false
+		clone.Pinned = false
+		clone.Stats = nil
+		clone.FirstContact = nil
+		clone.OS = nil
+		clone.Banner = nil
+		clone.HostKeyPin = nil
+		clone.LastIPs = nil
+		clone.SourceID = ""
+		clone.WebURLs = append([]string(nil), src.WebURLs...)
+		clone.InternalSubnets = append([]string(nil), src.InternalSubnets...)
+		clones = append(clones, clone)
+	}
+	return clones, nil
+}
+
+func (m model) openBulkClone(h Host) (tea.Model, tea.Cmd) {
+	m.clearListDeleteConfirm()
+	input := textinput.New()
+	input.Prompt = "  Range or hostnames  "
+	input.Placeholder = "2-10 or db1.example.com, db2.example.com"
+	input.PromptStyle = lipgloss.NewStyle().Foreground(colorHighlight).Bold(true)
+	input.TextStyle = lipgloss.NewStyle().Foreground(colorText)
+	input.PlaceholderStyle = lipgloss.NewStyle().Foreground(colorSubtle)
+	input.Cursor.Style = lipgloss.NewStyle().Foreground(colorSecondary)
+	m.bulkClone = bulkCloneState{hostID: h.ID, input: input}
+	m.form.formError = ""
+	m.state = stateBulkClone
+	return m, m.bulkClone.input.Focus()
+}
+
+func (m model) updateBulkClone(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.state = stateList
+		m.form.formError = ""
+		return m, nil
+	case "enter":
+		idx := findHostIndexByID(m.rawHosts, m.bulkClone.hostID)
+		if idx == -1 {
+			m.form.formError = "host no longer exists"
+			return m, nil
+		}
+		clones, err := planBulkClone(m.rawHosts[idx], m.bulkClone.input.Value(), m.rawHosts)
+		if err != nil {
+			m.form.formError = err.Error()
+			return m, nil
+		}
+		snapshot := m.snapshot()
+		// Insert right after the source so the copies sit next to it.
+		rest := append(clones, m.rawHosts[idx+1:]...)
+		m.rawHosts = append(m.rawHosts[:idx+1:idx+1], rest...)
+		m.refreshList()
+		if err := m.save(); err != nil {
+			m.restoreSnapshot(snapshot)
+			m.form.formError = fmt.Sprintf("failed to save clones: %v", err)
+			return m, nil
+		}
+		m.reselectItem(clones[0].ID, false)
+		m.state = stateList
+		m.form.formError = ""
+		m.status.message = fmt.Sprintf("Created %d copies of %s (%s … %s)", len(clones), m.rawHosts[idx].Alias, clones[0].Alias, clones[len(clones)-1].Alias)
+		m.status.isError = false
+		m.status.version++
+		return m, statusClearCmd(m.status.version)
+	}
+	var cmd tea.Cmd
+	m.bulkClone.input, cmd = m.bulkClone.input.Update(msg)
+	return m, cmd
+}
+
+func (m model) renderBulkCloneView() string {
+	title := "Bulk Clone"
+	content := m.bulkClone.input.View()
+	if idx := findHostIndexByID(m.rawHosts, m.bulkClone.hostID); idx != -1 {
+		src := m.rawHosts[idx]
+		title += " · " + src.Alias
+		if strings.TrimSpace(m.bulkClone.input.Value()) != "" {
+			if clones, err := planBulkClone(src, m.bulkClone.input.Value(), m.rawHosts); err == nil {
+				const previewRows = 4
+				content += "\n\n" + formHintStyle.Render(fmt.Sprintf("%d hosts:", len(clones)))
+				for i, c := range clones {
+					if i == previewRows && len(clones) > previewRows+1 {
+						content += "\n" + formHintStyle.Render(fmt.Sprintf("  … %d more", len(clones)-previewRows))
+						break
+					}
+					content += "\n  " + ansi.Truncate(c.Alias+"  "+hostPort(c.Hostname, ""), 60, "…")
+				}
+			}
+		}
+	}
+	if m.form.formError != "" {
+		content += "\n\n" + testFailStyle.Render("✘ "+m.form.formError)
+	}
+	box := formBoxStyle.Render(formTitleStyle.Render(title) + "\n\n" + content)
+	help := "\n" + helpBarStyle.Render(helpEntry("enter", "create")+" | "+helpEntry("esc", "cancel"))
+	return appStyle.Render(box + help)
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestPlanBulkCloneRange(t *testing.T) {
+	src := Host{ID: "h1", Alias: "web-01", Hostname: "web-01.example.com", User: "deploy", Pinned: true, WebURLs: []string{"http://x"}}
+	clones, err := planBulkClone(src, "1-3", []Host{src})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(clones) != 2 || clones[0].Alias != "web-02" || clones[1].Hostname != "web-03.example.com" {
+		t.Fatalf("unexpected clones %+v", clones)
+	}
+	if c := clones[0]; c.ID == "" || c.ID == src.ID || c.User != "deploy" || c.Pinned {
+		t.Fatalf("expected a fresh unpinned copy, got %+v", c)
+	}
+
+	ip := Host{Alias: "db", Hostname: "10.0.0.1"}
+	clones, err = planBulkClone(ip, "08..10", nil)
+	if err != nil || len(clones) != 3 || clones[0].Alias != "db-08" || clones[0].Hostname != "10.0.0.1" {
+		t.Fatalf("expected padded aliases and unchanged IP, got %+v %v", clones, err)
+	}
+
+	if _, err := planBulkClone(src, "2-4", []Host{src, {ID: "h2", Alias: "WEB-03"}}); err == nil || !strings.Contains(err.Error(), "web-03") {
+		t.Fatalf("expected taken alias to be rejected, got %v", err)
+	}
+	if _, err := planBulkClone(src, "1-500", nil); err == nil {
+		t.Fatal("expected oversized range to be rejected")
+	}
+}
+
+func TestPlanBulkCloneHostnameList(t *testing.T) {
+	src := Host{ID: "n7", Alias: "node7", Hostname: "node7.lab"}
+	clones, err := planBulkClone(src, "a.lab, b.lab", []Host{src, {ID: "n8", Alias: "node8"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(clones) != 2 || clones[0].Alias != "node9" || clones[0].Hostname != "a.lab" || clones[1].Alias != "node10" {
+		t.Fatalf("expected aliases to skip taken numbers, got %+v", clones)
+	}
+	if _, err := planBulkClone(src, "bad host;", nil); err == nil {
+		t.Fatal("expected invalid hostname to be rejected")
+	}
+}
+
+func TestBulkCloneInsertsAfterSource(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("ASSHO_STORE_PASSWORD", "0")
+	hosts := []Host{{ID: "h1", Alias: "web-1", Hostname: "10.0.0.1"}, {ID: "h2", Alias: "db", Hostname: "10.0.0.2"}}
+	m := model{rawHosts: hosts, list: newTestListModel(nil, hosts), historyList: newTestHistoryListModel()}
+	m.list.Select(0)
+	updated, _ := m.updateList(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("C")})
+	m = updated.(model)
+	if m.state != stateBulkClone {
+		t.Fatalf("expected bulk clone prompt, got state %v", m.state)
+	}
+	m.bulkClone.input.SetValue("2-3")
+	if view := m.renderBulkCloneView(); !strings.Contains(view, "web-3") {
+		t.Fatalf("expected preview of the clones, got:\n%s", view)
+	}
+	updated, _ = m.updateBulkClone(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(model)
+	var aliases []string
+	for _, h := range m.rawHosts {
+		aliases = append(aliases, h.Alias)
+	}
+	if got := strings.Join(aliases, ","); got != "web-1,web-2,web-3,db" || m.state != stateList {
+		t.Fatalf("expected clones after the source, got %s", got)
+	}
+}
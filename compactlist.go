@@ -0,0 +1,91 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// --- Compact List ---
+
+// z switches the dashboard between the two-line rows and a compact layout
+// of one line per host: the alias with its badges, then user@host. Groups
+// keep their count and down summary on the same line. The choice is
+// remembered in ui-state.json next to hosts.json, so it survives restarts
+// without rewriting the host config.
+
+// uiState is what the dashboard remembers between runs.
+type uiState struct {
+	Compact bool `json:"compact,omitempty"`
+}
+
+func uiStatePath() string {
+	return filepath.Join(filepath.Dir(getConfigPath()), "ui-state.json")
+}
+
+// loadUIState returns the remembered state; a missing or unreadable file
+// gives the defaults.
+func loadUIState() uiState {
+	var state uiState
+	if data, err := os.ReadFile(uiStatePath()); err == nil {
+		_ = json.Unmarshal(data, &state)
+	}
+	return state
+}
+
+func saveUIState(state uiState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := uiStatePath()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func (m model) toggleCompactList() (tea.Model, tea.Cmd) {
+	m.clearListDeleteConfirm()
+	m.compact = !m.compact
+	m.refreshDelegate()
+	m.status.message = "Two-line rows · press z for one line per host"
+	if m.compact {
+		m.status.message = "One line per host · press z for two-line rows"
+	}
+	m.status.isError = false
+	if err := saveUIState(uiState{Compact: m.compact}); err != nil {
+		m.status.message = fmt.Sprintf("Layout changed but not remembered: %v", err)
+		m.status.isError = true
+	}
+	m.status.version++
+	return m, statusClearCmd(m.status.version)
+}
+
+// renderCompactRow draws a row on one line: the title, then the short
+// description dimmed, cut to the list width.
+func renderCompactRow(w io.Writer, width int, selected bool, titleStyle lipgloss.Style, title, desc string) {
+	if selected {
+		line := title
+		if desc != "" {
+			line += "  " + desc
+		}
+		fmt.Fprint(w, itemSelectedTitle.Render(ansi.Truncate(line, max(width-3, 1), "…")))
+		return
+	}
+	line := titleStyle.Render(title)
+	if desc != "" {
+		line += itemNormalDesc.PaddingLeft(0).Render("  " + desc)
+	}
+	fmt.Fprint(w, ansi.Truncate(line, max(width, 1), "…"))
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestCompactListIsRemembered(t *testing.T) {
+	hosts := []Host{{ID: "h1", Alias: "web", Hostname: "web.example.com", User: "deploy"}}
+	writeTempConfig(t, hosts)
+	m := newModel(nil, hosts, nil, nil)
+	if m.compact {
+		t.Fatal("expected two-line rows by default")
+	}
+	result, _ := m.updateList(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("z")})
+	got := result.(model)
+	if !got.compact || !loadUIState().Compact {
+		t.Fatalf("expected compact rows saved, got %v (%s)", got.compact, got.status.message)
+	}
+	if reopened := newModel(nil, hosts, nil, nil); !reopened.compact {
+		t.Fatal("expected compact rows after a restart")
+	}
+}
+
+func TestCompactListOneLinePerHost(t *testing.T) {
+	hosts := []Host{
+		{ID: "h1", Alias: "web", Hostname: "web.example.com", User: "deploy", Notes: "front door"},
+		{ID: "h2", Alias: "db", Hostname: "db.example.com", User: "postgres"},
+		{ID: "h3", Alias: "cache", Hostname: "cache.example.com", User: "root"},
+	}
+	m := model{rawHosts: hosts, list: newTestListModel(nil, hosts), compact: true}
+	m.refreshDelegate()
+	var rows []string
+	for _, line := range strings.Split(m.list.View(), "\n") {
+		if strings.TrimSpace(line) != "" {
+			rows = append(rows, line)
+		}
+	}
+	if len(rows) != 3 {
+		t.Fatalf("expected three rows\n%s", m.list.View())
+	}
+	if !strings.Contains(rows[1], "db") || !strings.Contains(rows[1], "postgres@db.example.com") {
+		t.Fatalf("expected alias and user@host on one line, got %q", rows[1])
+	}
+	if strings.Contains(rows[0], "front door") {
+		t.Fatalf("expected notes left out of compact rows, got %q", rows[0])
+	}
+}
@@ -28,8 +28,24 @@ _assho_completions() {
         completion)
             COMPREPLY=($(compgen -W "bash zsh fish" -- "$cur"))
             ;;
+        export)
+            if [[ "${COMP_WORDS[COMP_CWORD-1]}" == "--ansible" ]]; then
+                COMPREPLY=($(compgen -W "ini yaml" -- "$cur"))
+            else
+                COMPREPLY=($(compgen -W "--write --grouped --ansible" -- "$cur"))
+            fi
+            ;;
+        daemon)
+            COMPREPLY=($(compgen -W "--once --listen" -- "$cur"))
+            ;;
+        secrets)
+            COMPREPLY=($(compgen -W "migrate --dry-run" -- "$cur"))
+            ;;
+        plugins)
+            COMPREPLY=($(compgen -W "list discover import" -- "$cur"))
+            ;;
         *)
-            COMPREPLY=($(compgen -W "connect test list export completion --version" -- "$cur"))
+            COMPREPLY=($(compgen -W "connect last test list export metrics daemon network secrets plugins sync update doctor completion --version --profile-startup" -- "$cur"))
             ;;
     esac
 }
@@ -41,11 +57,21 @@ _assho() {
     local -a subcmds
     subcmds=(
         'connect:connect to a host by alias'
+        'last:reconnect to the most recently used host'
         'test:test SSH connectivity for an alias'
         'list:list all configured hosts'
         'export:print hosts as SSH config stanzas'
+        'metrics:print Prometheus metrics'
+        'daemon:run the scheduled health checks'
+        'network:show the detected network and active profile'
+        'secrets:migrate stored passwords between backends'
+        'plugins:list plugins or import hosts through one'
+        'sync:pull hosts from configured inventories'
+        'update:install the latest release'
+        'doctor:check tools, secrets, and hosts.json'
         'completion:generate shell completion scripts'
         '--version:print version and exit'
+        '--profile-startup:time each startup step'
     )
 
     if (( CURRENT == 2 )); then
@@ -64,6 +90,23 @@ _assho() {
             shells=(bash zsh fish)
             _describe 'shell' shells
             ;;
+        export)
+            _arguments '(--ansible)--write[update the assho block in ~/.ssh/config]:path:_files' \
+                '(--ansible)--grouped[share group settings in one block per group]' \
+                '(--write --grouped)--ansible[print an Ansible inventory]:format:(ini yaml)'
+            ;;
+        secrets)
+            _arguments '1:action:(migrate)' '--dry-run[print the plan without moving anything]'
+            ;;
+        plugins)
+            _arguments '1:action:(list discover import)'
+            ;;
+        update)
+            _arguments '--check[only report whether a newer release exists]'
+            ;;
+        daemon)
+            _arguments '(--listen)--once[run every check now and exit]' '(--once)--listen[serve Prometheus metrics at /metrics]:address'
+            ;;
     esac
 }
 compdef _assho assho`
@@ -71,15 +114,34 @@ compdef _assho assho`
 const fishCompletion = `# fish completion for assho
 # Install: assho completion fish > ~/.config/fish/completions/assho.fish
 function __assho_no_subcommand
-    not __fish_seen_subcommand_from connect test list completion --version
+    not __fish_seen_subcommand_from connect last test list export metrics daemon network secrets plugins sync update doctor completion --version --profile-startup
 end
 
 complete -c assho -f
 complete -c assho -n '__assho_no_subcommand' -a connect    -d 'Connect to a host'
+complete -c assho -n '__assho_no_subcommand' -a last       -d 'Reconnect to the most recent host'
 complete -c assho -n '__assho_no_subcommand' -a test       -d 'Test SSH connectivity'
 complete -c assho -n '__assho_no_subcommand' -a list       -d 'List all hosts'
 complete -c assho -n '__assho_no_subcommand' -a export     -d 'Print hosts as SSH config stanzas'
+complete -c assho -n '__assho_no_subcommand' -a metrics    -d 'Print Prometheus metrics'
+complete -c assho -n '__assho_no_subcommand' -a daemon     -d 'Run the scheduled health checks'
+complete -c assho -n '__assho_no_subcommand' -a network    -d 'Show the detected network and active profile'
+complete -c assho -n '__assho_no_subcommand' -a secrets    -d 'Migrate stored passwords between backends'
+complete -c assho -n '__assho_no_subcommand' -a plugins    -d 'List plugins or import hosts through one'
+complete -c assho -n '__assho_no_subcommand' -a sync       -d 'Pull hosts from configured inventories'
+complete -c assho -n '__assho_no_subcommand' -a update     -d 'Install the latest release'
+complete -c assho -n '__assho_no_subcommand' -a doctor     -d 'Check tools, secrets, and hosts.json'
 complete -c assho -n '__assho_no_subcommand' -a completion -d 'Generate shell completions'
 complete -c assho -n '__assho_no_subcommand' -a --version  -d 'Print version'
+complete -c assho -n '__assho_no_subcommand' -a --profile-startup -d 'Time each startup step'
+complete -c assho -n '__fish_seen_subcommand_from export' -l write -d 'Update the assho block in ~/.ssh/config'
+complete -c assho -n '__fish_seen_subcommand_from export' -l grouped -d 'Share group settings in one block per group'
+complete -c assho -n '__fish_seen_subcommand_from export' -l ansible -xa 'ini yaml' -d 'Print an Ansible inventory'
+complete -c assho -n '__fish_seen_subcommand_from secrets' -a migrate -d 'Move passwords to ASSHO_SECRET_BACKEND'
+complete -c assho -n '__fish_seen_subcommand_from secrets' -l dry-run -d 'Print the plan without moving anything'
+complete -c assho -n '__fish_seen_subcommand_from plugins' -a 'list discover import'
+complete -c assho -n '__fish_seen_subcommand_from update' -l check -d 'Only report whether a newer release exists'
+complete -c assho -n '__fish_seen_subcommand_from daemon' -l once -d 'Run every check now and exit'
+complete -c assho -n '__fish_seen_subcommand_from daemon' -l listen -r -d 'Serve Prometheus metrics at /metrics'
 complete -c assho -n '__fish_seen_subcommand_from connect test' \
     -a '(assho _aliases 2>/dev/null)'`
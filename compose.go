@@ -0,0 +1,431 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// --- Docker Compose Projects ---
+
+// P on a host lists its compose projects (`docker compose ls`) and runs
+// project-level up, down, restart, pull, and logs against them, streaming the
+// output into the screen. Projects are addressed by name and the config files
+// compose reported, so no shell or working directory is needed.
+
+const composeOutputLines = 1000
+
+type composePhase int
+
+const (
+	composeLoading composePhase = iota
+	composeList
+	composeRunning
+	composeDone
+)
+
+type composeProject struct {
+	Name        string `json:"Name"`
+	Status      string `json:"Status"`
+	ConfigFiles string `json:"ConfigFiles"`
+}
+
+type composeState struct {
+	host        Host
+	phase       composePhase
+	projects    []composeProject
+	cursor      int
+	err         string
+	confirmDown bool
+	action      string
+	project     composeProject
+	output      []string
+	started     time.Time
+	cancel      context.CancelFunc
+	cancelled   bool
+}
+
+type composeProjectsMsg struct {
+	hostID   string
+	projects []composeProject
+	err      error
+}
+
+type composeStartedMsg struct {
+	updates <-chan tea.Msg
+	cancel  context.CancelFunc
+}
+
+type composeOutputMsg struct {
+	updates <-chan tea.Msg
+	line    string
+}
+
+type composeDoneMsg struct{ err error }
+
+// composeActions maps the screen's keys to compose subcommands. Logs follow
+// until esc.
+var composeActions = map[string]string{
+	"u": "up -d",
+	"d": "down",
+	"r": "restart",
+	"p": "pull",
+	"l": "logs --follow --tail 200",
+}
+
+func parseComposeProjects(out string) ([]composeProject, error) {
+	out = strings.TrimSpace(out)
+	if out == "" {
+		return nil, nil
+	}
+	var projects []composeProject
+	if err := json.Unmarshal([]byte(out), &projects); err != nil {
+		return nil, fmt.Errorf("unexpected docker compose ls output: %v", err)
+	}
+	return projects, nil
+}
+
+func (p composeProject) configFiles() []string {
+	var files []string
+	for _, file := range strings.Split(p.ConfigFiles, ",") {
+		if file = strings.TrimSpace(file); file != "" {
+			files = append(files, file)
+		}
+	}
+	return files
+}
+
+// composeCommand is the remote command line for action on p.
+func composeCommand(p composeProject, action string) string {
+	parts := []string{"docker", "compose", "--ansi", "never", "-p", shellQuote(p.Name)}
+	for _, file := range p.configFiles() {
+		parts = append(parts, "-f", shellQuote(file))
+	}
+	return strings.Join(append(parts, action), " ")
+}
+
+// remoteExecCommand runs command on h over ssh, or with sh for the local
+// host, without a terminal.
+func remoteExecCommand(ctx context.Context, h Host, command string) *exec.Cmd {
+	if h.isLocal() {
+		return exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	h = withPassword(h)
+	args := []string{"-o", connectTimeoutOption(h.connectTimeout()), "-o", "StrictHostKeyChecking=yes"}
+	args = append(args, pinnedKnownHostsArgs(h)...)
+	if h.Password == "" {
+		args = append(args, "-o", "BatchMode=yes")
+	}
+	if h.UseSSHConfig {
+		args = append(args, h.Alias, command)
+	} else {
+		if h.User != "" {
+			args = append(args, "-l", h.User)
+		}
+		if h.Port != "" {
+			args = append(args, "-p", h.Port)
+		}
+		if h.IdentityFile != "" {
+			args = append(args, "-i", expandPath(h.IdentityFile))
+		}
+		args = append(args, identityAgentArgs(h)...)
+		args = append(args, proxyArgs(h)...)
+		args = append(args, bareHostname(h.Hostname), command)
+	}
+	binary, cmdArgs, env, _ := buildSSHCommand(h.Password, args)
+	cmd := exec.CommandContext(ctx, binary, cmdArgs...)
+	if len(env) > 0 {
+		cmd.Env = append(cmd.Environ(), env...)
+	}
+	return cmd
+}
+
+func listComposeProjectsTrusted(h Host) tea.Cmd {
+	return func() tea.Msg {
+		out, err := runSSHCommand(h, "docker compose ls -a --format json")
+		if err != nil {
+			return composeProjectsMsg{hostID: h.ID, err: err}
+		}
+		projects, err := parseComposeProjects(out)
+		return composeProjectsMsg{hostID: h.ID, projects: projects, err: err}
+	}
+}
+
+func (m model) openCompose() (tea.Model, tea.Cmd) {
+	h, ok := m.list.SelectedItem().(Host)
+	if !ok || h.IsContainer {
+		return m, nil
+	}
+	if h.isWindows() {
+		m.status.message = "Compose projects are listed on Linux and macOS hosts"
+		m.status.isError = true
+		m.status.version++
+		return m, statusClearCmd(m.status.version)
+	}
+	m.clearListDeleteConfirm()
+	m.compose = composeState{host: h, phase: composeLoading}
+	m.state = stateCompose
+	return m, checkHostTrustCmd(pendingSSHAction{kind: sshActionCompose, host: h, trustHost: h, inv: m.inventory()})
+}
+
+func (m model) finishComposeProjects(msg composeProjectsMsg) (tea.Model, tea.Cmd) {
+	if msg.hostID != m.compose.host.ID || m.compose.phase != composeLoading {
+		return m, nil
+	}
+	m.compose.phase = composeList
+	if msg.err != nil {
+		m.compose.err, _ = formatTestStatus(msg.err)
+		return m, nil
+	}
+	m.compose.err = ""
+	m.compose.projects = msg.projects
+	m.compose.cursor = min(m.compose.cursor, max(len(msg.projects)-1, 0))
+	return m, nil
+}
+
+// startComposeActionTrusted runs the chosen action against h, the compose
+// host as resolved by the trust check.
+func (m model) startComposeActionTrusted(h Host) (model, tea.Cmd) {
+	c := m.compose
+	m.compose.phase = composeRunning
+	m.compose.output = nil
+	m.compose.cancelled = false
+	m.compose.err = ""
+	m.compose.started = time.Now()
+	host, alias := h, c.host.Alias+"/"+c.project.Name
+	command := composeCommand(c.project, composeActions[c.action])
+	m.tasks.start(taskCompose, c.host.ID, strings.Fields(composeActions[c.action])[0]+" "+alias, func(m model) (model, tea.Cmd) {
+		m.compose = composeState{host: c.host, phase: composeList, action: c.action, project: c.project}
+		m.state = stateCompose
+		return m, checkHostTrustCmd(pendingSSHAction{kind: sshActionComposeRun, host: c.host, trustHost: c.host, inv: m.inventory()})
+	})
+	return m, func() tea.Msg {
+		ctx, cancel := context.WithCancel(context.Background())
+		cmd := remoteExecCommand(ctx, host, command)
+		pr, pw := io.Pipe()
+		cmd.Stdout, cmd.Stderr = pw, pw
+		if err := cmd.Start(); err != nil {
+			cancel()
+			recordAudit("compose", alias, host, err)
+			return composeDoneMsg{err: err}
+		}
+		waitErr := make(chan error, 1)
+		go func() {
+			err := cmd.Wait()
+			pw.Close()
+			waitErr <- err
+		}()
+		updates := make(chan tea.Msg, 64)
+		go func() {
+			var last string
+			scanner := bufio.NewScanner(pr)
+			scanner.Split(scanProgressLines)
+			for scanner.Scan() {
+				line := strings.TrimRight(scanner.Text(), " ")
+				if strings.TrimSpace(line) == "" {
+					continue
+				}
+				last = line
+				updates <- composeOutputMsg{updates: updates, line: line}
+			}
+			err := <-waitErr
+			if err != nil && ctx.Err() == nil && last != "" {
+				err = errors.New(strings.TrimSpace(last))
+			}
+			recordAudit("compose", alias, host, err)
+			updates <- composeDoneMsg{err: err}
+		}()
+		return composeStartedMsg{updates: updates, cancel: cancel}
+	}
+}
+
+func waitComposeUpdate(updates <-chan tea.Msg) tea.Cmd {
+	return func() tea.Msg { return <-updates }
+}
+
+func (m model) handleComposeStarted(msg composeStartedMsg) (tea.Model, tea.Cmd) {
+	m.compose.cancel = msg.cancel
+	m.tasks.setCancel(taskCompose, m.compose.host.ID, msg.cancel)
+	if m.compose.cancelled {
+		msg.cancel()
+	}
+	return m, waitComposeUpdate(msg.updates)
+}
+
+func (m model) handleComposeOutput(msg composeOutputMsg) (tea.Model, tea.Cmd) {
+	m.compose.output = append(m.compose.output, msg.line)
+	m.tasks.setProgress(taskCompose, m.compose.host.ID, msg.line)
+	if extra := len(m.compose.output) - composeOutputLines; extra > 0 {
+		m.compose.output = m.compose.output[extra:]
+	}
+	return m, waitComposeUpdate(msg.updates)
+}
+
+func (m model) finishComposeAction(msg composeDoneMsg) (tea.Model, tea.Cmd) {
+	if m.compose.cancel != nil {
+		m.compose.cancel()
+		m.compose.cancel = nil
+	}
+	m.compose.phase = composeDone
+	if m.compose.cancelled {
+		m.tasks.markCancelled(taskCompose, m.compose.host.ID)
+	} else {
+		m.tasks.finish(taskCompose, m.compose.host.ID, msg.err)
+	}
+	switch {
+	case m.compose.cancelled:
+		m.compose.err = ""
+	case msg.err != nil:
+		m.compose.err = msg.err.Error()
+	default:
+		m.compose.err = ""
+	}
+	return m, nil
+}
+
+func (m model) updateCompose(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	key := msg.String()
+	if key == "J" {
+		return m.openTasks()
+	}
+	if key == "ctrl+c" {
+		if m.compose.cancel != nil {
+			m.compose.cancel()
+		}
+		m.quitting = true
+		return m, tea.Quit
+	}
+	switch m.compose.phase {
+	case composeLoading:
+		if key == "esc" || key == "q" {
+			m.state = stateList
+		}
+		return m, nil
+	case composeRunning:
+		if key == "esc" {
+			m.compose.cancelled = true
+			if m.compose.cancel != nil {
+				m.compose.cancel()
+			}
+		}
+		return m, nil
+	case composeDone:
+		switch key {
+		case "enter", "esc", "q":
+			m.compose.phase = composeLoading
+			return m, listComposeProjectsTrusted(m.compose.host)
+		}
+		return m, nil
+	}
+
+	confirmDown := m.compose.confirmDown
+	m.compose.confirmDown = false
+	switch key {
+	case "esc", "q", "P":
+		m.state = stateList
+	case "up", "k":
+		if m.compose.cursor > 0 {
+			m.compose.cursor--
+		}
+	case "down", "j":
+		if m.compose.cursor < len(m.compose.projects)-1 {
+			m.compose.cursor++
+		}
+	case "ctrl+r":
+		m.compose.phase = composeLoading
+		m.compose.err = ""
+		return m, listComposeProjectsTrusted(m.compose.host)
+	case "u", "d", "r", "p", "l":
+		if m.compose.cursor >= len(m.compose.projects) {
+			return m, nil
+		}
+		if key == "d" && !confirmDown {
+			m.compose.confirmDown = true
+			return m, nil
+		}
+		m.compose.action = key
+		m.compose.project = m.compose.projects[m.compose.cursor]
+		return m, checkHostTrustCmd(pendingSSHAction{kind: sshActionComposeRun, host: m.compose.host, trustHost: m.compose.host, inv: m.inventory()})
+	}
+	return m, nil
+}
+
+func (m model) renderComposeView() string {
+	width, height := normalizedSize(m.width, m.height)
+	inner := max(min(width-6, 100)-6, 24)
+	c := m.compose
+	var b strings.Builder
+	b.WriteString(lipgloss.NewStyle().Bold(true).Foreground(colorText).Render(ansi.Truncate("COMPOSE · "+c.host.Alias, inner, "…")) + "\n")
+
+	switch c.phase {
+	case composeLoading:
+		b.WriteString(formHintStyle.Render("Listing compose projects…") + "\n")
+		b.WriteString("\n" + helpEntry("esc", "back"))
+		return centeredWorkspace(b.String(), width, height)
+	case composeList:
+		switch {
+		case c.err != "":
+			b.WriteString(testFailStyle.Render(ansi.Truncate("✘ "+c.err, inner, "…")) + "\n\n")
+		case len(c.projects) == 0:
+			b.WriteString(formHintStyle.Render("No compose projects on this host") + "\n\n")
+		default:
+			b.WriteString(formHintStyle.Render(fmt.Sprintf("%d projects", len(c.projects))) + "\n\n")
+		}
+		maxRows := max(height-14, 2)
+		start := 0
+		if c.cursor >= maxRows {
+			start = c.cursor - maxRows + 1
+		}
+		end := min(start+maxRows, len(c.projects))
+		for idx := start; idx < end; idx++ {
+			p := c.projects[idx]
+			label := fmt.Sprintf("%-20s %-14s %s", p.Name, p.Status, strings.Join(p.configFiles(), ", "))
+			b.WriteString(selectionLine(idx == c.cursor, ansi.Truncate(label, inner-2, "…")) + "\n")
+		}
+		if c.confirmDown {
+			b.WriteString("\n" + testFailStyle.Render("Press d again to take "+c.projects[c.cursor].Name+" down") + "\n")
+		}
+		b.WriteString("\n" + helpEntry("u", "up") + "  " + helpEntry("d", "down") + "  " + helpEntry("r", "restart") + "  " +
+			helpEntry("p", "pull") + "  " + helpEntry("l", "logs") + "  " + helpEntry("ctrl+r", "refresh") + "  " + helpEntry("esc", "back"))
+		return centeredWorkspace(b.String(), width, height)
+	}
+
+	b.WriteString(formHintStyle.Render(ansi.Truncate("docker compose "+composeActions[c.action]+" · "+c.project.Name, inner, "…")) + "\n\n")
+	maxRows := max(height-14, 3)
+	for _, line := range c.output[max(len(c.output)-maxRows, 0):] {
+		b.WriteString(ansi.Truncate(line, inner, "…") + "\n")
+	}
+	if len(c.output) > 0 {
+		b.WriteString("\n")
+	}
+	if c.phase == composeRunning {
+		elapsed := time.Since(c.started).Truncate(time.Second)
+		b.WriteString(m.spinner.View() + fmt.Sprintf(" Running · %s", elapsed) + "\n")
+		stop := "cancel"
+		if c.action == "l" {
+			stop = "stop following"
+		}
+		b.WriteString("\n" + helpEntry("esc", stop) + "  " + helpEntry("J", "tasks"))
+		return centeredWorkspace(b.String(), width, height)
+	}
+	switch {
+	case c.cancelled:
+		b.WriteString(formHintStyle.Render("Stopped.") + "\n")
+	case c.err != "":
+		b.WriteString(testFailStyle.Render(ansi.Wrap("✘ "+c.err, inner, " ")) + "\n")
+	default:
+		b.WriteString(testSuccessStyle.Render("✔ Done") + "\n")
+	}
+	b.WriteString("\n" + helpEntry("enter", "back to projects"))
+	return centeredWorkspace(b.String(), width, height)
+}
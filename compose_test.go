@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestParseComposeProjects(t *testing.T) {
+	projects, err := parseComposeProjects(`[{"Name":"site","Status":"running(2)","ConfigFiles":"/srv/site/compose.yaml,/srv/site/compose.prod.yaml"},{"Name":"old","Status":"exited(1)","ConfigFiles":""}]`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(projects) != 2 || projects[0].Name != "site" || projects[1].Status != "exited(1)" {
+		t.Fatalf("unexpected projects %+v", projects)
+	}
+	if files := projects[0].configFiles(); !slices.Equal(files, []string{"/srv/site/compose.yaml", "/srv/site/compose.prod.yaml"}) {
+		t.Fatalf("unexpected config files %v", files)
+	}
+	if projects, err := parseComposeProjects("\n"); err != nil || len(projects) != 0 {
+		t.Fatalf("empty output should list nothing, got %+v %v", projects, err)
+	}
+	if _, err := parseComposeProjects("docker: 'compose' is not a docker command."); err == nil {
+		t.Fatal("expected an error for non-JSON output")
+	}
+}
+
+func TestComposeCommand(t *testing.T) {
+	p := composeProject{Name: "site", ConfigFiles: "/srv/my site/compose.yaml"}
+	want := "docker compose --ansi never -p site -f '/srv/my site/compose.yaml' up -d"
+	if got := composeCommand(p, composeActions["u"]); got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestRemoteExecCommand(t *testing.T) {
+	h := Host{Alias: "docker01", Hostname: "10.0.0.5", User: "deploy", Port: "2222", ProxyJump: "bastion"}
+	cmd := remoteExecCommand(context.Background(), h, "docker compose ls")
+	want := []string{"ssh", "-o", "ConnectTimeout=5", "-o", "StrictHostKeyChecking=yes", "-o", "BatchMode=yes", "-l", "deploy", "-p", "2222", "-J", "bastion", "10.0.0.5", "docker compose ls"}
+	if !slices.Equal(cmd.Args, want) {
+		t.Fatalf("got %v, want %v", cmd.Args, want)
+	}
+
+	h.Transport = transportLocal
+	cmd = remoteExecCommand(context.Background(), h, "docker compose ls")
+	if !slices.Equal(cmd.Args, []string{"sh", "-c", "docker compose ls"}) {
+		t.Fatalf("local host should run without ssh, got %v", cmd.Args)
+	}
+}
+
+func TestComposeDownNeedsConfirmation(t *testing.T) {
+	m := model{state: stateCompose, compose: composeState{
+		host:     Host{ID: "h1", Alias: "docker01", Hostname: "10.0.0.5"},
+		phase:    composeList,
+		projects: []composeProject{{Name: "site"}},
+	}}
+	press := func(key string) tea.Cmd {
+		result, cmd := m.updateCompose(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+		m = result.(model)
+		return cmd
+	}
+
+	if cmd := press("d"); cmd != nil || !m.compose.confirmDown {
+		t.Fatal("first d should only ask for confirmation")
+	}
+	if cmd := press("j"); cmd != nil || m.compose.confirmDown {
+		t.Fatal("any other key should cancel the confirmation")
+	}
+	press("d")
+	if cmd := press("d"); cmd == nil || m.compose.action != "d" || m.compose.project.Name != "site" {
+		t.Fatalf("second d should start compose down, got action %q", m.compose.action)
+	}
+}
+
+func TestComposeActionUsesResolvedHost(t *testing.T) {
+	bin := t.TempDir()
+	argsFile := filepath.Join(bin, "args")
+	script := "#!/bin/sh\nprintf '%s\\n' \"$@\" > " + argsFile + "\n"
+	if err := os.WriteFile(filepath.Join(bin, "ssh"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin)
+	t.Setenv("ASSHO_AUDIT_LOG", "")
+
+	groups := []Group{{ID: "g1", Name: "prod", DefaultUser: "deploy"}}
+	raw := Host{ID: "h1", Alias: "docker01", Hostname: "10.0.0.5", GroupID: "g1"}
+	m := model{rawGroups: groups, rawHosts: []Host{raw}, state: stateCompose}
+	m.compose = composeState{host: raw, phase: composeList, action: "r", project: composeProject{Name: "site"}}
+
+	action := pendingSSHAction{kind: sshActionComposeRun, host: resolveEndpoint(raw, m.inventory()), trustHost: raw}
+	_, cmd := m.resumePendingSSHActionModel(action)
+	started, ok := cmd().(composeStartedMsg)
+	if !ok {
+		t.Fatal("expected the compose action to start")
+	}
+	for msg := range started.updates {
+		if _, done := msg.(composeDoneMsg); done {
+			break
+		}
+	}
+	args, err := os.ReadFile(argsFile)
+	if err != nil || !strings.Contains(string(args), "-l\ndeploy\n") {
+		t.Fatalf("expected the group's default user in the ssh call, got %q (%v)", args, err)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -18,38 +19,93 @@ import (
 // --- Data Models ---
 
 type Host struct {
-	ID           string `json:"id"`
-	Alias        string `json:"alias"`
-	Hostname     string `json:"hostname"`
-	User         string `json:"user"`
-	Port         string `json:"port"`
-	IdentityFile string `json:"identity_file,omitempty"`
-	Password     string `json:"password,omitempty"`
-	PasswordRef  string `json:"password_ref,omitempty"`
-	ProxyJump    string `json:"proxy_jump,omitempty"`
-	LocalForward string `json:"local_forward,omitempty"`
-	ForwardAgent bool   `json:"forward_agent,omitempty"`
-	Notes        string `json:"notes,omitempty"`
-	Pinned       bool   `json:"pinned,omitempty"`
-	GroupID      string `json:"group_id,omitempty"`
-
-	// Docker Support
+	ID            string        `json:"id"`
+	Alias         string        `json:"alias"`
+	Hostname      string        `json:"hostname"`
+	User          string        `json:"user"`
+	Port          string        `json:"port"`
+	IdentityFile  string        `json:"identity_file,omitempty"`
+	Password      string        `json:"password,omitempty"`
+	PasswordRef   string        `json:"password_ref,omitempty"`
+	ProxyJump     string        `json:"proxy_jump,omitempty"`
+	ProxyCommand  string        `json:"proxy_command,omitempty"`  // see proxycommand.go
+	IdentityAgent string        `json:"identity_agent,omitempty"` // see identityagent.go
+	LocalForward  string        `json:"local_forward,omitempty"`
+	RemoteCommand string        `json:"remote_command,omitempty"`
+	TmuxSession   string        `json:"tmux_session,omitempty"`
+	ForwardAgent  bool          `json:"forward_agent,omitempty"`
+	UseSSHConfig  bool          `json:"use_ssh_config,omitempty"` // connect as `ssh <alias>`
+	Transport     string        `json:"transport,omitempty"`      // powershell or psremoting, see windows.go
+	Term          string        `json:"term,omitempty"`           // TERM for sessions, see sessionenv.go
+	NoLocale      bool          `json:"no_locale,omitempty"`      // keep LANG and LC_* from being sent
+	Terminal      string        `json:"terminal,omitempty"`       // open sessions in a new window, see terminal.go
+	Hotkey        int           `json:"hotkey,omitempty"`         // digit that connects from the dashboard, see quickconnect.go
+	Notes         string        `json:"notes,omitempty"`
+	WebURLs       []string      `json:"web_urls,omitempty"`
+	Pinned        bool          `json:"pinned,omitempty"`
+	Archived      bool          `json:"archived,omitempty"`
+	ExpiresAt     string        `json:"expires_at,omitempty"`
+	Maintenance   *Maintenance  `json:"maintenance,omitempty"` // planned downtime, see maintenance.go
+	Owner         string        `json:"owner,omitempty"`
+	Team          string        `json:"team,omitempty"`
+	Contact       string        `json:"contact,omitempty"`
+	Role          string        `json:"role,omitempty"` // overrides the inferred role, see role.go
+	GroupID       string        `json:"group_id,omitempty"`
+	Stats         *HostStats    `json:"stats,omitempty"`
+	FirstContact  *FirstContact `json:"first_contact,omitempty"`
+	OS            *HostOS       `json:"os,omitempty"`
+	Banner        *HostBanner   `json:"banner,omitempty"`    // last banner and MOTD, see banner.go
+	LastIPs       []string      `json:"last_ips,omitempty"`  // last DNS answer, see dnspreview.go
+	SourceID      string        `json:"source_id,omitempty"` // inventory record, see inventory.go
+
+	// Server key fingerprints checked before every connection (see hostkeypin.go)
+	HostKeyPin []string `json:"host_key_pin,omitempty"`
+
+	// Seconds ssh waits to connect; 0 inherits (see timeouts.go)
+	ConnectTimeout int `json:"connect_timeout,omitempty"`
+
+	// Alternate address preferred on the internal network (see network.go)
+	InternalHostname string   `json:"internal_hostname,omitempty"`
+	InternalSubnets  []string `json:"internal_subnets,omitempty"`
+
+	// Docker Support. Other guests are nested the same way, told apart by
+	// Kind: "vm" (libvirt), "lxd" or "incus" containers, "jail" (FreeBSD),
+	// or "zone" (illumos); empty is a Docker container.
 	Containers  []Host `json:"containers,omitempty"` // Nested hosts (containers)
 	IsContainer bool   `json:"is_container,omitempty"`
+	Kind        string `json:"kind,omitempty"`
 	Expanded    bool   `json:"-"` // UI State
 	ParentID    string `json:"-"` // Reference to parent (SSH host)
 	ListIndent  int    `json:"-"` // UI indent level for tree rendering
+	ListColor   string `json:"-"` // UI tint inherited from the host's group
+	// HealthFailing is set when the health daemon last found the host failing.
+	HealthFailing bool `json:"-"`
+
+	// Engine API details for Docker containers, see dockerapi.go
+	Docker *DockerInfo `json:"docker,omitempty"`
 }
 
 type Group struct {
-	ID       string `json:"id"`
-	Name     string `json:"name"`
-	Expanded bool   `json:"expanded,omitempty"`
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Color       string `json:"color,omitempty"` // palette name or #rrggbb
+	Expanded    bool   `json:"expanded,omitempty"`
+	Query       string `json:"query,omitempty"` // non-empty for smart groups
+
+	// Defaults for member hosts that leave the field blank.
+	DefaultUser         string `json:"default_user,omitempty"`
+	DefaultIdentityFile string `json:"default_identity_file,omitempty"`
+	DefaultProxyJump    string `json:"default_proxy_jump,omitempty"`
 }
 
+// Smart reports whether the group is populated by a query instead of members.
+func (g Group) Smart() bool { return g.Query != "" }
+
 type groupItem struct {
 	Group
 	HostCount int
+	Down      []string // aliases of members that are down, see grouphealth.go
 }
 
 func (g groupItem) FilterValue() string { return g.Name }
@@ -59,6 +115,9 @@ func (g groupItem) Description() string { return "group" }
 // FilterValue implements list.Item
 func (h Host) FilterValue() string { return h.Alias + " " + h.Hostname }
 func (h Host) Title() string {
+	if h.isGuest() {
+		return "  🖥 " + h.Alias
+	}
 	if h.IsContainer {
 		return "  🐳 " + h.Alias
 	}
@@ -69,14 +128,17 @@ func (h Host) Title() string {
 	return prefix + h.Alias
 }
 func (h Host) Description() string {
+	if h.isGuest() {
+		return fmt.Sprintf("VM: %s", guestAddressLabel(h))
+	}
 	if h.IsContainer {
 		return fmt.Sprintf("Container: %s", h.Hostname)
 	}
-	desc := fmt.Sprintf("%s@%s", h.User, h.Hostname)
-	if h.Port != "" && h.Port != "22" {
-		desc += fmt.Sprintf(":%s", h.Port)
+	port := h.Port
+	if port == "22" {
+		port = ""
 	}
-	return desc
+	return h.User + "@" + hostPort(h.Hostname, port)
 }
 
 // --- Config Management ---
@@ -89,17 +151,24 @@ func getConfigPath() string {
 	return filepath.Join(home, ".config", "assho", "hosts.json")
 }
 
-func shouldPersistPassword() bool {
-	value := strings.ToLower(strings.TrimSpace(os.Getenv("ASSHO_STORE_PASSWORD")))
-	if value == "" {
+// envBool reads a 1/true/yes or 0/false/no switch from the environment,
+// returning def when name is unset or holds anything else.
+func envBool(name string, def bool) bool {
+	switch strings.ToLower(strings.TrimSpace(os.Getenv(name))) {
+	case "1", "true", "yes":
 		return true
+	case "0", "false", "no":
+		return false
 	}
-	return value != "0" && value != "false" && value != "no"
+	return def
+}
+
+func shouldPersistPassword() bool {
+	return envBool("ASSHO_STORE_PASSWORD", true)
 }
 
 func allowInsecureTest() bool {
-	value := strings.ToLower(strings.TrimSpace(os.Getenv("ASSHO_INSECURE_TEST")))
-	return value == "1" || value == "true" || value == "yes"
+	return envBool("ASSHO_INSECURE_TEST", false)
 }
 
 const (
@@ -112,6 +181,7 @@ type HistoryEntry struct {
 	HostID    string `json:"host_id"`
 	Alias     string `json:"alias"`
 	Timestamp int64  `json:"timestamp"`
+	EndedAt   int64  `json:"ended_at,omitempty"` // when the session closed, see sessiontime.go
 }
 
 func recordHistory(hostID, alias string, history []HistoryEntry) []HistoryEntry {
@@ -171,6 +241,20 @@ func storePasswordSecret(ref, password string) error {
 	if ref == "" || password == "" {
 		return nil
 	}
+	if secretsOffline() {
+		return errSecretsOffline
+	}
+	err := storeSecret(ref, password)
+	if err == nil {
+		cachePasswordSecret(ref, password)
+	}
+	return err
+}
+
+func storeSecret(ref, password string) error {
+	if _, ok, err := pluginSecretCall(pluginRequest{Action: "set_secret", Ref: ref, Secret: password}); ok {
+		return err
+	}
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
@@ -183,7 +267,7 @@ func storePasswordSecret(ref, password string) error {
 		return nil
 	case "linux":
 		if !commandExists("secret-tool") {
-			return fmt.Errorf("secret-tool not installed")
+			return errMissingTool("secret-tool")
 		}
 		cmd := exec.CommandContext(ctx, "secret-tool", "store", "--label=assho password", "service", secretServiceName, "account", ref)
 		cmd.Stdin = strings.NewReader(password)
@@ -200,6 +284,12 @@ func lookupPasswordSecret(ref string) (string, error) {
 	if ref == "" {
 		return "", nil
 	}
+	if secretsOffline() {
+		return "", errSecretsOffline
+	}
+	if resp, ok, err := pluginSecretCall(pluginRequest{Action: "get_secret", Ref: ref}); ok {
+		return resp.Secret, err
+	}
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
@@ -213,7 +303,7 @@ func lookupPasswordSecret(ref string) (string, error) {
 		return strings.TrimSpace(string(output)), nil
 	case "linux":
 		if !commandExists("secret-tool") {
-			return "", fmt.Errorf("secret-tool not installed")
+			return "", errMissingTool("secret-tool")
 		}
 		cmd := exec.CommandContext(ctx, "secret-tool", "lookup", "service", secretServiceName, "account", ref)
 		output, err := cmd.Output()
@@ -226,6 +316,41 @@ func lookupPasswordSecret(ref string) (string, error) {
 	}
 }
 
+func deletePasswordSecret(ref string) error {
+	if ref == "" {
+		return nil
+	}
+	if secretsOffline() {
+		return errSecretsOffline
+	}
+	cachePasswordSecret(ref, "")
+	if _, ok, err := pluginSecretCall(pluginRequest{Action: "delete_secret", Ref: ref}); ok {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	switch runtime.GOOS {
+	case "darwin":
+		cmd := exec.CommandContext(ctx, "security", "delete-generic-password", "-a", ref, "-s", secretServiceName)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("security delete failed: %v (%s)", err, strings.TrimSpace(string(output)))
+		}
+		return nil
+	case "linux":
+		if !commandExists("secret-tool") {
+			return errMissingTool("secret-tool")
+		}
+		cmd := exec.CommandContext(ctx, "secret-tool", "clear", "service", secretServiceName, "account", ref)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("secret-tool clear failed: %v (%s)", err, strings.TrimSpace(string(output)))
+		}
+		return nil
+	default:
+		return fmt.Errorf("keychain backend unsupported on %s", runtime.GOOS)
+	}
+}
+
 // --- Host/Group Helpers ---
 
 func sanitizeHostsForSave(hosts []Host) []Host {
@@ -235,11 +360,16 @@ func sanitizeHostsForSave(hosts []Host) []Host {
 		if !shouldPersistPassword() {
 			sanitized[i].Password = ""
 			sanitized[i].PasswordRef = ""
+		} else if sanitized[i].Password != "" && secretBackend() == secretBackendConfig {
+			sanitized[i].PasswordRef = ""
 		} else if sanitized[i].Password != "" {
-			// Prefer keychain storage; fall back to plaintext if unavailable.
+			// Prefer keychain storage; fall back to plaintext if unavailable,
+			// except offline, where the password is kept for this run only.
 			if err := storePasswordSecret(sanitized[i].ID, sanitized[i].Password); err == nil {
 				sanitized[i].PasswordRef = sanitized[i].ID
 				sanitized[i].Password = ""
+			} else if errors.Is(err, errSecretsOffline) {
+				sanitized[i].Password = ""
 			}
 		}
 		if len(h.Containers) > 0 {
@@ -280,26 +410,6 @@ func ensureGroupIDs(groups []Group) ([]Group, bool) {
 	return groups, changed
 }
 
-func hydrateHostPasswords(hosts []Host) ([]Host, []string) {
-	var warnings []string
-	for i := range hosts {
-		if hosts[i].Password == "" && hosts[i].PasswordRef != "" {
-			secret, err := lookupPasswordSecret(hosts[i].PasswordRef)
-			if err != nil {
-				warnings = append(warnings, fmt.Sprintf("%q: %v", hosts[i].Alias, err))
-			} else {
-				hosts[i].Password = secret
-			}
-		}
-		if len(hosts[i].Containers) > 0 {
-			var sub []string
-			hosts[i].Containers, sub = hydrateHostPasswords(hosts[i].Containers)
-			warnings = append(warnings, sub...)
-		}
-	}
-	return hosts, warnings
-}
-
 // --- Config I/O ---
 
 type configFile struct {
@@ -307,11 +417,43 @@ type configFile struct {
 	Groups  []Group        `json:"groups,omitempty"`
 	Hosts   []Host         `json:"hosts,omitempty"`
 	History []HistoryEntry `json:"history,omitempty"`
+
+	// Networks is hand-edited; saveConfig carries it over unchanged.
+	Networks []NetworkProfile `json:"networks,omitempty"`
+	// Inventories is hand-edited too; see inventory.go.
+	Inventories []InventorySource `json:"inventories,omitempty"`
+	// Tunnels is hand-edited too; see tunnels.go.
+	Tunnels []TunnelProfile `json:"tunnels,omitempty"`
+	// HealthChecks is hand-edited too; see healthcheck.go.
+	HealthChecks []HealthCheck `json:"health_checks,omitempty"`
+	// Webhooks is hand-edited too; see webhooks.go.
+	Webhooks []Webhook `json:"webhooks,omitempty"`
+	// Roles is hand-edited too; see role.go.
+	Roles map[string]RoleStyle `json:"roles,omitempty"`
+}
+
+// loadConfigFile reads and decodes the config without touching the keychain.
+// A missing file is reported as an error satisfying os.IsNotExist.
+func loadConfigFile() (configFile, error) {
+	f, err := os.Open(getConfigPath())
+	if err != nil {
+		return configFile{}, err
+	}
+	defer f.Close()
+
+	bytes, err := io.ReadAll(f)
+	if err != nil {
+		return configFile{}, err
+	}
+	var cfg configFile
+	if err := json.Unmarshal(bytes, &cfg); err != nil {
+		return configFile{}, fmt.Errorf("invalid config format: %w", err)
+	}
+	return cfg, nil
 }
 
 func loadConfig() ([]Group, []Host, []HistoryEntry, error) {
-	path := getConfigPath()
-	f, err := os.Open(path)
+	cfg, err := loadConfigFile()
 	if err != nil {
 		if os.IsNotExist(err) {
 			// Return default/example data if no config exists.
@@ -321,34 +463,31 @@ func loadConfig() ([]Group, []Host, []HistoryEntry, error) {
 		}
 		return []Group{}, []Host{}, nil, err
 	}
-	defer f.Close()
-
-	bytes, readErr := io.ReadAll(f)
-	if readErr != nil {
-		return []Group{}, []Host{}, nil, readErr
-	}
-
-	var cfg configFile
-	if err := json.Unmarshal(bytes, &cfg); err != nil {
-		return []Group{}, []Host{}, nil, fmt.Errorf("invalid config format: %w", err)
-	}
-	hydratedHosts, hydrateWarnings := hydrateHostPasswords(cfg.Hosts)
-	var hydrateErr error
-	if len(hydrateWarnings) > 0 {
-		hydrateErr = fmt.Errorf("keychain lookup failed: %s", strings.Join(hydrateWarnings, "; "))
-	}
-	return cfg.Groups, hydratedHosts, cfg.History, hydrateErr
+	// Keychain passwords are read on demand; see hostPassword.
+	return cfg.Groups, cfg.Hosts, cfg.History, nil
 }
 
 func saveConfig(groups []Group, hosts []Host, history []HistoryEntry) error {
+	_, err := writeConfig(groups, hosts, history)
+	return err
+}
+
+// writeConfig saves under the config lock and returns the history it wrote,
+// which includes entries merged in from another instance.
+func writeConfig(groups []Group, hosts []Host, history []HistoryEntry) ([]HistoryEntry, error) {
 	path := getConfigPath()
 	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
-		return err
+		return history, err
 	}
+	unlock, err := lockConfig()
+	if err != nil {
+		return history, err
+	}
+	defer unlock()
 	tmpPath := path + ".tmp"
 	f, err := os.Create(tmpPath)
 	if err != nil {
-		return err
+		return history, err
 	}
 	defer func() { _ = f.Close() }()
 	sanitizedHosts := sanitizeHostsForSave(hosts)
@@ -358,18 +497,27 @@ func saveConfig(groups []Group, hosts []Host, history []HistoryEntry) error {
 		Hosts:   sanitizedHosts,
 		History: history,
 	}
+	if existing, err := loadConfigFile(); err == nil {
+		cfg.Networks = existing.Networks
+		cfg.Inventories = existing.Inventories
+		cfg.Tunnels = existing.Tunnels
+		cfg.HealthChecks = existing.HealthChecks
+		cfg.Webhooks = existing.Webhooks
+		cfg.Roles = existing.Roles
+		cfg.History = mergeHistory(history, existing.History, hosts)
+	}
 	bytes, err := json.MarshalIndent(cfg, "", "  ")
 	if err != nil {
-		return err
+		return history, err
 	}
 	if _, err := f.Write(bytes); err != nil {
-		return err
+		return history, err
 	}
 	if err := f.Close(); err != nil {
-		return err
+		return history, err
 	}
 	if err := os.Rename(tmpPath, path); err != nil {
-		return err
+		return history, err
 	}
-	return os.Chmod(path, 0600)
+	return cfg.History, os.Chmod(path, 0600)
 }
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"cmp"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// --- Config Locking ---
+
+// Several assho processes can share hosts.json: two terminals, or the TUI
+// next to `assho metrics`. Saves hold an exclusive advisory lock on a sidecar
+// file while they read, merge, and replace the config, so two saves never
+// interleave. Readers need no lock since the config is replaced by rename.
+// History is merged with what is on disk, so connections recorded by another
+// instance survive this one's save; hosts and groups are last-writer-wins.
+
+// configLockTimeout bounds how long a save waits for another instance.
+const configLockTimeout = 5 * time.Second
+
+var errConfigLocked = errors.New("config is locked by another assho instance")
+
+func getConfigLockPath() string {
+	return getConfigPath() + ".lock"
+}
+
+// lockConfig takes the exclusive config lock and returns its release func.
+func lockConfig() (func(), error) {
+	path := getConfigLockPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, err
+	}
+	deadline := time.Now().Add(configLockTimeout)
+	for {
+		locked, err := tryLockFile(f)
+		if err != nil {
+			_ = f.Close()
+			return nil, err
+		}
+		if locked {
+			return func() {
+				unlockFile(f)
+				_ = f.Close()
+			}, nil
+		}
+		if time.Now().After(deadline) {
+			_ = f.Close()
+			return nil, errConfigLocked
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+// mergeHistory adds entries another instance saved to ours. Per host the
+// newest entry wins, or for the same connect the one that saw the session
+// end, and entries for hosts that are gone are dropped, which keeps
+// deletions made here from being undone. Ours is returned unchanged when the
+// file holds nothing new.
+func mergeHistory(ours, onDisk []HistoryEntry, hosts []Host) []HistoryEntry {
+	known := map[string]bool{}
+	for _, h := range hosts {
+		known[h.ID] = true
+		for _, c := range h.Containers {
+			known[c.ID] = true
+		}
+	}
+	merged := slices.Clone(ours)
+	index := map[string]int{}
+	for i := len(merged) - 1; i >= 0; i-- {
+		index[merged[i].HostID] = i
+	}
+	changed := false
+	for _, e := range onDisk {
+		if !known[e.HostID] {
+			continue
+		}
+		i, ok := index[e.HostID]
+		switch {
+		case !ok:
+			index[e.HostID] = len(merged)
+			merged = append(merged, e)
+			changed = true
+		case e.Timestamp > merged[i].Timestamp,
+			e.Timestamp == merged[i].Timestamp && e.EndedAt > merged[i].EndedAt:
+			merged[i] = e
+			changed = true
+		}
+	}
+	if !changed {
+		return ours
+	}
+	slices.SortStableFunc(merged, func(a, b HistoryEntry) int { return cmp.Compare(b.Timestamp, a.Timestamp) })
+	if len(merged) > maxHistoryEntries {
+		merged = merged[:maxHistoryEntries]
+	}
+	return merged
+}
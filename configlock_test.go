@@ -0,0 +1,87 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMergeHistory(t *testing.T) {
+	hosts := []Host{{ID: "a"}, {ID: "b"}, {ID: "p", Containers: []Host{{ID: "c"}}}}
+	ours := []HistoryEntry{{HostID: "a", Timestamp: 30}, {HostID: "b", Timestamp: 10}}
+
+	if got := mergeHistory(ours, ours, hosts); len(got) != 2 || got[0].HostID != "a" {
+		t.Fatalf("expected our history unchanged, got %+v", got)
+	}
+
+	onDisk := []HistoryEntry{
+		{HostID: "b", Timestamp: 40},
+		{HostID: "c", Timestamp: 20},
+		{HostID: "gone", Timestamp: 50},
+		{HostID: "a", Timestamp: 5},
+	}
+	got := mergeHistory(ours, onDisk, hosts)
+	want := []HistoryEntry{{HostID: "b", Timestamp: 40}, {HostID: "a", Timestamp: 30}, {HostID: "c", Timestamp: 20}}
+	if len(got) != len(want) {
+		t.Fatalf("expected %+v, got %+v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("entry %d: expected %+v, got %+v", i, want[i], got[i])
+		}
+	}
+}
+
+func TestSaveKeepsHistoryFromAnotherInstance(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("ASSHO_STORE_PASSWORD", "0")
+	hosts := []Host{{ID: "h1", Alias: "web"}, {ID: "h2", Alias: "db"}}
+	if err := saveConfig(nil, hosts, []HistoryEntry{{HostID: "h2", Alias: "db", Timestamp: 200}}); err != nil {
+		t.Fatal(err)
+	}
+
+	// This instance loaded before the other one connected to db.
+	m := model{rawHosts: hosts, history: []HistoryEntry{{HostID: "h1", Alias: "web", Timestamp: 100}}, list: newTestListModel(nil, hosts), historyList: newTestHistoryListModel()}
+	if err := m.save(); err != nil {
+		t.Fatal(err)
+	}
+	if len(m.history) != 2 || m.history[0].HostID != "h2" {
+		t.Fatalf("expected the other instance's entry merged in, got %+v", m.history)
+	}
+	_, _, saved, err := loadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(saved) != 2 {
+		t.Fatalf("expected both entries saved, got %+v", saved)
+	}
+}
+
+func TestLockConfigWaitsForOtherHolder(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	unlock, err := lockConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	acquired := make(chan error, 1)
+	go func() {
+		release, err := lockConfig()
+		if err == nil {
+			release()
+		}
+		acquired <- err
+	}()
+	select {
+	case <-acquired:
+		t.Fatal("expected the second lock to wait")
+	case <-time.After(150 * time.Millisecond):
+	}
+	unlock()
+	select {
+	case err := <-acquired:
+		if err != nil {
+			t.Fatalf("expected the lock after release, got %v", err)
+		}
+	case <-time.After(configLockTimeout):
+		t.Fatal("lock was never acquired")
+	}
+}
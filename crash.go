@@ -0,0 +1,185 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// --- Crash Reports ---
+
+// crashGuard wraps the TUI model so a panic in Update, View, or a command
+// they return ends the program through tea.Quit: Bubble Tea restores the
+// terminal as on a normal exit, and main writes a crash report next to
+// hosts.json before printing where it went. The report carries the stack and
+// a summary of the model's state; hostnames, users, notes, and secrets are
+// left out so it can be attached to a bug report as is.
+
+type crashReport struct {
+	value any    // what was passed to panic; nil until something crashes
+	where string // update, view, command, or startup
+	msg   string // type of the message being handled
+	state string
+	stack []byte
+}
+
+func (r *crashReport) crashed() bool {
+	return r != nil && r.value != nil
+}
+
+// crashMsg carries a panic out of a command into Update.
+type crashMsg struct {
+	report crashReport
+}
+
+type crashGuard struct {
+	inner  tea.Model
+	report *crashReport // shared by every copy of the guard
+}
+
+func newCrashGuard(inner tea.Model) crashGuard {
+	return crashGuard{inner: inner, report: &crashReport{}}
+}
+
+func (g crashGuard) record(where string, value any, msg tea.Msg) {
+	*g.report = crashReport{value: value, where: where, state: crashState(g.inner), stack: debug.Stack()}
+	if msg != nil {
+		g.report.msg = fmt.Sprintf("%T", msg)
+	}
+}
+
+func (g crashGuard) Init() tea.Cmd {
+	return guardCmd(g.inner.Init())
+}
+
+func (g crashGuard) Update(msg tea.Msg) (result tea.Model, cmd tea.Cmd) {
+	if g.report.crashed() {
+		return g, tea.Quit
+	}
+	if crash, ok := msg.(crashMsg); ok {
+		*g.report = crash.report
+		g.report.state = crashState(g.inner)
+		return g, tea.Quit
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			g.record("update", r, msg)
+			result, cmd = g, tea.Quit
+		}
+	}()
+	inner, cmd := g.inner.Update(msg)
+	g.inner = inner
+	return g, guardCmd(cmd)
+}
+
+// View draws nothing once something has crashed; the next message, at the
+// latest the spinner's tick, quits.
+func (g crashGuard) View() (view string) {
+	if g.report.crashed() {
+		return ""
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			g.record("view", r, nil)
+			view = ""
+		}
+	}()
+	return g.inner.View()
+}
+
+// guardCmd turns a panic in cmd, or in the commands of a batch it returns,
+// into a crashMsg.
+func guardCmd(cmd tea.Cmd) tea.Cmd {
+	if cmd == nil {
+		return nil
+	}
+	return func() (msg tea.Msg) {
+		defer func() {
+			if r := recover(); r != nil {
+				msg = crashMsg{crashReport{value: r, where: "command", stack: debug.Stack()}}
+			}
+		}()
+		msg = cmd()
+		if batch, ok := msg.(tea.BatchMsg); ok {
+			guarded := make(tea.BatchMsg, len(batch))
+			for i, c := range batch {
+				guarded[i] = guardCmd(c)
+			}
+			return guarded
+		}
+		return msg
+	}
+}
+
+// crashState summarizes the model without any host details.
+func crashState(m tea.Model) string {
+	mm, ok := m.(model)
+	if !ok {
+		return fmt.Sprintf("%T", m)
+	}
+	containers := 0
+	for _, h := range mm.rawHosts {
+		containers += len(h.Containers)
+	}
+	lines := []string{
+		fmt.Sprintf("screen: %d", mm.state),
+		fmt.Sprintf("terminal: %dx%d", mm.width, mm.height),
+		fmt.Sprintf("hosts: %d (%d nested), groups: %d, history: %d", len(mm.rawHosts), containers, len(mm.rawGroups), len(mm.history)),
+		fmt.Sprintf("list: item %d of %d, filter %s", mm.list.Index(), len(mm.list.Items()), mm.list.FilterState()),
+	}
+	if mm.state == stateForm {
+		lines = append(lines, "form: "+formControlLabel(mm.form.focus))
+	}
+	running := 0
+	for _, t := range mm.tasks.tasks {
+		if t.status == taskRunning {
+			running++
+		}
+	}
+	lines = append(lines, fmt.Sprintf("tasks: %d running, %d total", running, len(mm.tasks.tasks)))
+	return strings.Join(lines, "\n")
+}
+
+// writeCrashReport saves r and returns its path.
+func writeCrashReport(r crashReport, now time.Time) (string, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "assho %s crashed at %s\n", version, now.Format(time.RFC3339))
+	fmt.Fprintf(&b, "%s %s/%s\n\n", runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	fmt.Fprintf(&b, "panic in %s", r.where)
+	if r.msg != "" {
+		fmt.Fprintf(&b, " while handling %s", r.msg)
+	}
+	fmt.Fprintf(&b, ": %v\n\n", r.value)
+	if r.state != "" {
+		b.WriteString(r.state + "\n\n")
+	}
+	b.Write(r.stack)
+
+	dir := filepath.Dir(getConfigPath())
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, "crash-"+now.Format("20060102-150405")+".log")
+	if err := os.WriteFile(path, []byte(b.String()), 0o600); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// reportCrash writes r and tells the user where it went.
+func reportCrash(r crashReport) {
+	fmt.Fprintln(os.Stderr, "assho ran into a bug and closed.")
+	path, err := writeCrashReport(r, time.Now())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "The crash report could not be saved (%v):\n\npanic: %v\n\n%s", err, r.value, r.stack)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "A crash report was saved to %s\n", path)
+	fmt.Fprintln(os.Stderr, "It leaves out hostnames and passwords; please attach it to an issue at https://github.com/allisonhere/assho/issues")
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// panicModel panics in whichever method is named by where.
+type panicModel struct {
+	where string
+	cmd   tea.Cmd
+}
+
+func (p panicModel) Init() tea.Cmd { return nil }
+
+func (p panicModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if p.where == "update" {
+		panic("boom in update")
+	}
+	return p, p.cmd
+}
+
+func (p panicModel) View() string {
+	if p.where == "view" {
+		panic("boom in view")
+	}
+	return "ok"
+}
+
+func isQuit(cmd tea.Cmd) bool {
+	if cmd == nil {
+		return false
+	}
+	_, ok := cmd().(tea.QuitMsg)
+	return ok
+}
+
+func TestCrashGuardRecoversUpdatePanic(t *testing.T) {
+	g := newCrashGuard(panicModel{where: "update"})
+	_, cmd := g.Update(tea.KeyMsg{})
+	if !isQuit(cmd) {
+		t.Fatal("a panic in Update should quit")
+	}
+	if !g.report.crashed() || g.report.where != "update" || g.report.msg != "tea.KeyMsg" {
+		t.Fatalf("unexpected report %+v", g.report)
+	}
+	if !strings.Contains(string(g.report.stack), "panicModel.Update") {
+		t.Fatal("the stack should point at the panic")
+	}
+}
+
+func TestCrashGuardQuitsAfterViewPanic(t *testing.T) {
+	g := newCrashGuard(panicModel{where: "view"})
+	if view := g.View(); view != "" {
+		t.Fatalf("expected a blank view, got %q", view)
+	}
+	if g.report.where != "view" {
+		t.Fatalf("unexpected report %+v", g.report)
+	}
+	if _, cmd := g.Update(tea.WindowSizeMsg{}); !isQuit(cmd) {
+		t.Fatal("the next message should quit")
+	}
+}
+
+func TestCrashGuardCatchesCommandPanics(t *testing.T) {
+	bad := func() tea.Msg { panic("boom in command") }
+	g := newCrashGuard(panicModel{cmd: tea.Batch(bad, func() tea.Msg { return nil })})
+	_, cmd := g.Update(nil)
+	batch, ok := cmd().(tea.BatchMsg)
+	if !ok || len(batch) != 2 {
+		t.Fatalf("expected the batch to pass through, got %T", cmd())
+	}
+	msg := batch[0]()
+	if _, ok := msg.(crashMsg); !ok {
+		t.Fatalf("expected a crashMsg, got %T", msg)
+	}
+	if _, cmd := g.Update(msg); !isQuit(cmd) || g.report.where != "command" {
+		t.Fatalf("a crashed command should quit, got report %+v", g.report)
+	}
+}
+
+func TestCrashStateLeavesOutHostDetails(t *testing.T) {
+	hosts := []Host{{Alias: "db-prod", Hostname: "10.9.8.7", User: "admin", Password: "hunter2", Notes: "payroll"}}
+	m := model{rawHosts: hosts, list: newTestListModel(nil, hosts)}
+	state := crashState(m)
+	for _, secret := range []string{"db-prod", "10.9.8.7", "admin", "hunter2", "payroll"} {
+		if strings.Contains(state, secret) {
+			t.Fatalf("crash state leaks %q:\n%s", secret, state)
+		}
+	}
+	if !strings.Contains(state, "hosts: 1") {
+		t.Fatalf("expected a host count, got:\n%s", state)
+	}
+}
+
+func TestWriteCrashReport(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	now := time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC)
+	path, err := writeCrashReport(crashReport{value: "boom", where: "update", msg: "tea.KeyMsg", state: "screen: 0", stack: []byte("goroutine 1")}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(path, "crash-20260304-050607.log") {
+		t.Fatalf("unexpected path %s", path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"panic in update while handling tea.KeyMsg: boom", "screen: 0", "goroutine 1"} {
+		if !strings.Contains(string(data), want) {
+			t.Fatalf("report missing %q:\n%s", want, data)
+		}
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0o600 {
+		t.Fatalf("report should be private, got %v", info.Mode().Perm())
+	}
+}
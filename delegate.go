@@ -14,10 +14,30 @@ import (
 
 type hostDelegate struct {
 	lastConnected map[string]int64
+	lastSession   map[string]time.Duration // history view only
+	quickConnect  bool                     // number the 1–9 hotkey rows
+	lookups       map[string]dnsLookup
+	marked        map[string]bool
+	busy          map[string]string // what busy hosts' rows show after the spinner
+	spinner       string            // frame drawn on busy rows
+	roles         roleSet
+	compact       bool // one line per row, see compactlist.go
+}
+
+func (d hostDelegate) Height() int {
+	if d.compact {
+		return 1
+	}
+	return 2
+}
+
+func (d hostDelegate) Spacing() int {
+	if d.compact {
+		return 0
+	}
+	return 1
 }
 
-func (d hostDelegate) Height() int                             { return 2 }
-func (d hostDelegate) Spacing() int                            { return 1 }
 func (d hostDelegate) Update(_ tea.Msg, _ *list.Model) tea.Cmd { return nil }
 
 func relativeTime(ts int64) string {
@@ -44,17 +64,39 @@ func (d hostDelegate) Render(w io.Writer, m list.Model, index int, listItem list
 		if g.Expanded {
 			icon = " ▼ "
 		}
-		title := "📁 " + g.Name
-		hostWord := "hosts"
-		if g.HostCount == 1 {
-			hostWord = "host"
+		title := "📁 " + g.Name + " " + groupCountLabel(g)
+		var details []string
+		if len(g.Down) > 0 {
+			details = append(details, downSummary(g.Down))
+		}
+		if g.Smart() {
+			title = "🔎 " + g.Name + " " + groupCountLabel(g)
+			details = append(details, g.Query)
+		}
+		if g.Group.Description != "" {
+			details = append(details, g.Group.Description)
+		}
+		if label := groupDefaultsLabel(g.Group); label != "" {
+			details = append(details, label)
+		}
+		desc := strings.Join(details, " · ")
+		if d.compact {
+			titleStyle := itemNormalTitle
+			if c, ok := groupColor(g.Color); ok {
+				titleStyle = titleStyle.Foreground(c).Bold(true)
+			}
+			renderCompactRow(w, m.Width(), isSelected, titleStyle, strings.TrimLeft(icon+title, " "), desc)
+			return
 		}
-		desc := fmt.Sprintf("%d %s", g.HostCount, hostWord)
 		if isSelected {
 			fmt.Fprintf(w, "%s", itemSelectedTitle.Render(strings.TrimLeft(icon+title, " ")))
 			fmt.Fprintf(w, "\n%s", itemSelectedDesc.Render("  "+desc))
 		} else {
-			fmt.Fprintf(w, "%s", itemNormalTitle.Render(strings.TrimLeft(icon+title, " ")))
+			titleStyle := itemNormalTitle
+			if c, ok := groupColor(g.Color); ok {
+				titleStyle = titleStyle.Foreground(c).Bold(true)
+			}
+			fmt.Fprintf(w, "%s", titleStyle.Render(strings.TrimLeft(icon+title, " ")))
 			fmt.Fprintf(w, "\n%s", itemNormalDesc.Render("  "+desc))
 		}
 		return
@@ -66,13 +108,20 @@ func (d hostDelegate) Render(w io.Writer, m list.Model, index int, listItem list
 	}
 
 	// Build the icon and title
-	var icon, title, desc string
+	var icon, title, desc, short string
 	indent := strings.Repeat("  ", h.ListIndent)
 
-	if h.IsContainer {
+	if h.isGuest() {
+		icon = "🖥 "
+		title = h.Alias
+		desc = "vm " + guestAddressLabel(h)
+	} else if h.IsContainer {
 		icon = "📦 "
 		title = h.Alias
-		desc = fmt.Sprintf("container %s", h.Hostname)
+		desc = fmt.Sprintf("%s %s", h.containerLabel(), h.Hostname)
+		if h.Docker != nil {
+			desc += " · " + h.Docker.summary()
+		}
 	} else {
 		if h.Expanded {
 			icon = "▼ "
@@ -84,24 +133,42 @@ func (d hostDelegate) Render(w io.Writer, m list.Model, index int, listItem list
 		authIcon := "🌐 " // globe - no specific auth
 		if h.IdentityFile != "" {
 			authIcon = "🔑 " // key
-		} else if h.Password != "" {
+		} else if h.Password != "" || h.PasswordRef != "" {
 			authIcon = "🔒 " // lock
 		}
 
 		title = authIcon + h.Alias
+		if icon, _, _ := d.roles.style(h); icon != "" {
+			title += " " + icon
+		}
+		if h.OS != nil {
+			if icon := h.OS.Icon(); icon != "" {
+				title += " " + icon
+			}
+		}
+		if h.Expired(time.Now()) {
+			title += " ⌛"
+		}
+		if h.inMaintenance(time.Now()) {
+			title += " 🔧"
+		}
 
-		connStr := fmt.Sprintf("%s@%s", h.User, h.Hostname)
-		if h.Port != "" && h.Port != "22" {
-			connStr += fmt.Sprintf(":%s", h.Port)
+		port := h.Port
+		if port == "22" {
+			port = ""
 		}
-		desc = connStr
+		desc = h.User + "@" + hostPort(h.Hostname, port)
 
-		if h.ProxyJump != "" {
-			desc += " via " + h.ProxyJump
+		if h.behindProxy() {
+			desc += " via " + h.proxyVia()
 		}
 		if len(h.Containers) > 0 {
 			desc += fmt.Sprintf(" [%d containers]", len(h.Containers))
 		}
+		if label, ok := d.busy[h.ID]; ok {
+			desc += " " + d.spinner + " " + label
+		}
+		short = desc
 		if h.Notes != "" {
 			note := h.Notes
 			if len(note) > 28 {
@@ -109,16 +176,56 @@ func (d hostDelegate) Render(w io.Writer, m list.Model, index int, listItem list
 			}
 			desc += " · " + note
 		}
+		if label := expiryLabel(h, time.Now()); label != "" {
+			desc += " · " + label
+		}
+		if label := maintenanceLabel(h, time.Now()); label != "" {
+			desc += " · " + label
+		}
 		if ts, ok := d.lastConnected[h.ID]; ok {
 			desc += " · " + relativeTime(ts)
 		}
+		if d, ok := d.lastSession[h.ID]; ok {
+			desc += " · " + formatSessionDuration(d) + " session"
+		}
+		if l, ok := d.lookups[h.ID]; ok && l.hostname == bareHostname(h.Hostname) {
+			desc += " · " + dnsLookupLabel(l)
+		}
 	}
 
+	if d.marked[h.ID] {
+		icon = "● " + icon
+	}
+	if d.quickConnect {
+		if digit := quickConnectDigit(m.VisibleItems(), h); digit != 0 {
+			title += fmt.Sprintf("  [%d]", digit)
+		}
+	}
+
+	if short == "" {
+		short = desc
+	}
+
+	titleStyle, descStyle := itemNormalTitle, itemNormalDesc
+	if c, ok := groupColor(h.ListColor); ok && !h.IsContainer {
+		titleStyle = titleStyle.Foreground(c)
+	} else if _, c, ok := d.roles.style(h); ok && !h.IsContainer {
+		titleStyle = titleStyle.Foreground(c)
+	}
+	// Stopped containers stay listed but recede.
+	if h.IsContainer && !h.Docker.running() {
+		titleStyle = titleStyle.Foreground(colorMuted)
+		descStyle = descStyle.Foreground(colorMuted)
+	}
+	if d.compact {
+		renderCompactRow(w, m.Width(), isSelected, titleStyle, indent+icon+title, short)
+		return
+	}
 	if isSelected {
 		fmt.Fprintf(w, "%s", itemSelectedTitle.Render(indent+icon+title))
 		fmt.Fprintf(w, "\n%s", itemSelectedDesc.Render(indent+"  "+desc))
 	} else {
-		fmt.Fprintf(w, "%s", itemNormalTitle.Render(indent+icon+title))
-		fmt.Fprintf(w, "\n%s", itemNormalDesc.Render(indent+"  "+desc))
+		fmt.Fprintf(w, "%s", titleStyle.Render(indent+icon+title))
+		fmt.Fprintf(w, "\n%s", descStyle.Render(indent+"  "+desc))
 	}
 }
@@ -0,0 +1,261 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// --- Host Detail Pane ---
+
+func (m model) openDetail(h Host) (tea.Model, tea.Cmd) {
+	m.clearListDeleteConfirm()
+	m.detailHostID = h.ID
+	m.detailSSHConfig = loadSSHConfigBlocks()
+	m.state = stateDetail
+	return m, nil
+}
+
+// detailHost returns the live copy of the host shown in the detail pane.
+func (m model) detailHost() (Host, bool) {
+	idx := findHostIndexByID(m.rawHosts, m.detailHostID)
+	if idx == -1 {
+		return Host{}, false
+	}
+	return m.rawHosts[idx], true
+}
+
+func (m model) updateDetail(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	h, ok := m.detailHost()
+	switch msg.String() {
+	case "ctrl+c":
+		m.quitting = true
+		return m, tea.Quit
+	case "v", "esc", "q":
+		m.state = stateList
+		return m, nil
+	case "enter":
+		if ok {
+			return m.connectToHost(h)
+		}
+	case "f":
+		if ok {
+			return m.openFirstContact(h)
+		}
+	case "s":
+		if ok {
+			return m.startQuickStats(h)
+		}
+	case "g":
+		if ok && !h.IsContainer {
+			return m.openDiagnostics(h)
+		}
+	case "y":
+		if ok {
+			return m.copyPublicKey(h)
+		}
+	case "ctrl+f":
+		return m.fixKeyPermissions()
+	case "e":
+		if ok {
+			m.state = stateForm
+			m.form.selectedHost = &h
+			m.form.inputs = newFormInputs()
+			m.populateForm(h)
+			return m, m.focusInputs()
+		}
+	}
+	return m, nil
+}
+
+func detailRow(label, value string) string {
+	if strings.TrimSpace(value) == "" {
+		value = "—"
+	}
+	return lipgloss.NewStyle().Foreground(colorMuted).Width(14).Render(label) + value + "\n"
+}
+
+func (m model) renderDetailView() string {
+	width, height := normalizedSize(m.width, m.height)
+	h, ok := m.detailHost()
+	if !ok {
+		return centeredWorkspace(testFailStyle.Render("✘ Host no longer exists")+"\n\n"+helpEntry("esc", "back"), width, height)
+	}
+	var b strings.Builder
+	b.WriteString(lipgloss.NewStyle().Bold(true).Foreground(colorText).Render(h.Alias) + "\n")
+	b.WriteString(formHintStyle.Render(sshTarget(h)) + "\n\n")
+
+	port := h.Port
+	if port == "" {
+		port = "22"
+	}
+	b.WriteString(formSectionStyle.Render("Connection") + "\n")
+	b.WriteString(detailRow("Hostname", h.Hostname))
+	if h.UseSSHConfig {
+		b.WriteString(detailRow("Connects as", "ssh "+h.Alias+" (~/.ssh/config)"))
+	}
+	if h.Transport != "" {
+		b.WriteString(detailRow("Connection", transportLabel(h.Transport)))
+	}
+	if role := m.roles.roleOf(h); role != "" {
+		if h.Role == "" {
+			role += " (from the name)"
+		}
+		b.WriteString(detailRow("Role", role))
+	}
+	if l, ok := m.dnsLookups[h.ID]; ok && l.hostname == bareHostname(h.Hostname) {
+		b.WriteString(detailRow("Resolves to", dnsLookupLabel(l)))
+	} else if len(h.LastIPs) > 0 {
+		b.WriteString(detailRow("Resolves to", strings.Join(h.LastIPs, ", ")+" (cached)"))
+	}
+	if h.InternalHostname != "" {
+		b.WriteString(detailRow("Internal", internalRuleLabel(h)))
+	}
+	b.WriteString(detailRow("User", h.User))
+	b.WriteString(detailRow("Port", port))
+	if h.ConnectTimeout > 0 {
+		b.WriteString(detailRow("Timeout", fmt.Sprintf("%ds", h.ConnectTimeout)))
+	}
+	keyFile := h.IdentityFile
+	if h.usesSecurityKey() {
+		keyFile += " · security key, touch required"
+	}
+	b.WriteString(detailRow("Key file", keyFile))
+	if len(h.HostKeyPin) > 0 {
+		b.WriteString(detailRow("Pinned key", strings.Join(h.HostKeyPin, ", ")))
+	}
+	b.WriteString(detailRow("ProxyJump", h.ProxyJump))
+	if hops := jumpChain(applyGroupDefaults(h, m.rawGroups), m.rawHosts, m.detailSSHConfig); len(hops) > 0 {
+		b.WriteString(detailRow("Route", renderJumpRoute(hops, h.Alias)))
+		for _, problem := range jumpProblems(hops) {
+			b.WriteString(detailRow("", testFailStyle.Render("✘ "+problem)))
+		}
+	}
+	if h.ProxyCommand != "" {
+		b.WriteString(detailRow("ProxyCommand", h.ProxyCommand))
+	}
+	if h.IdentityAgent != "" {
+		b.WriteString(detailRow("IdentityAgent", h.IdentityAgent))
+	}
+	b.WriteString(detailRow("LocalForward", h.LocalForward))
+	if h.RemoteCommand != "" {
+		b.WriteString(detailRow("Remote cmd", h.RemoteCommand))
+	}
+	if h.TmuxSession != "" {
+		b.WriteString(detailRow("Tmux", h.TmuxSession))
+	}
+	if h.Term != "" {
+		b.WriteString(detailRow("TERM", h.Term))
+	}
+	if h.NoLocale {
+		b.WriteString(detailRow("Locale", "not sent"))
+	}
+	if h.Terminal != "" {
+		b.WriteString(detailRow("Terminal", h.Terminal))
+	}
+	if h.Hotkey != 0 {
+		b.WriteString(detailRow("Hotkey", fmt.Sprintf("%d", h.Hotkey)))
+	}
+	if h.Notes != "" {
+		b.WriteString(detailRow("Notes", h.Notes))
+	}
+	if len(h.WebURLs) > 0 {
+		b.WriteString(detailRow("Web UIs", strings.Join(h.WebURLs, ", ")))
+	}
+	if h.SourceID != "" {
+		b.WriteString(detailRow("Synced from", h.SourceID))
+	}
+	if label := expiryLabel(h, time.Now()); label != "" {
+		if h.Expired(time.Now()) {
+			label = testFailStyle.Render(label)
+		}
+		b.WriteString(detailRow("Expires", label))
+	}
+	if label := maintenanceLabel(h, time.Now()); label != "" {
+		if note := strings.TrimSpace(h.Maintenance.Note); note != "" {
+			label += " · " + note
+		}
+		b.WriteString(detailRow("Maintenance", label))
+	}
+
+	if h.OS != nil {
+		b.WriteString("\n" + formSectionStyle.Render("System") + "\n")
+		b.WriteString(detailRow("OS", strings.TrimSpace(h.OS.Icon()+" "+h.OS.Label())))
+		b.WriteString(detailRow("Kernel", strings.TrimSpace(h.OS.Kernel+" "+h.OS.Release)))
+		b.WriteString(detailRow("Uptime", h.OS.Uptime))
+		b.WriteString(detailRow("Probed", time.Unix(h.OS.ProbedAt, 0).Format("2006-01-02 15:04")))
+	}
+
+	if banner := h.Banner; banner != nil {
+		b.WriteString("\n" + formSectionStyle.Render("Banner") + "\n")
+		if banner.Banner != "" {
+			b.WriteString(bannerPreview(banner.Banner, 6))
+		}
+		if banner.MOTD != "" {
+			b.WriteString(formHintStyle.Render("MOTD") + "\n")
+			b.WriteString(bannerPreview(banner.MOTD, 6))
+		}
+		b.WriteString(formHintStyle.Render("seen "+time.Unix(banner.SeenAt, 0).Format("2006-01-02 15:04")) + "\n")
+	}
+
+	if h.Owner != "" || h.Team != "" || h.Contact != "" {
+		b.WriteString("\n" + formSectionStyle.Render("Ownership") + "\n")
+		b.WriteString(detailRow("Owner", h.Owner))
+		b.WriteString(detailRow("Team", h.Team))
+		b.WriteString(detailRow("Contact", h.Contact))
+	}
+
+	var s HostStats
+	if h.Stats != nil {
+		s = *h.Stats
+	}
+	b.WriteString("\n" + formSectionStyle.Render("Statistics") + "\n")
+	b.WriteString(detailRow("Connections", fmt.Sprintf("%d", s.Connections)))
+	b.WriteString(detailRow("Tests", fmt.Sprintf("%d (%d failed)", s.Tests, s.Failures)))
+	b.WriteString(detailRow("Avg latency", formatLatency(s.AverageLatency())))
+	if s.Sessions > 0 {
+		b.WriteString(detailRow("Session time", fmt.Sprintf("%s over %d sessions", s.totalSessionTime(), s.Sessions)))
+	}
+	if s.LastFailure != "" {
+		b.WriteString(detailRow("Last failure", s.LastFailure+" · "+time.Unix(s.LastFailureAt, 0).Format("2006-01-02 15:04")))
+	}
+
+	if qs := m.quickStats; qs.hostID == h.ID {
+		b.WriteString("\n" + formSectionStyle.Render("Quick stats") + "\n")
+		switch {
+		case qs.loading:
+			b.WriteString(m.spinner.View() + " Collecting df, free, uptime, who…\n")
+		case qs.err != "":
+			b.WriteString(testFailStyle.Render("✘ "+qs.err) + "\n")
+		case qs.stats != nil:
+			for _, row := range qs.stats.rows() {
+				b.WriteString(detailRow(row[0], row[1]))
+			}
+			b.WriteString(formHintStyle.Render("taken "+qs.stats.takenAt.Format("15:04:05")) + "\n")
+		}
+	}
+
+	if fc := h.FirstContact; fc != nil {
+		b.WriteString("\n" + formSectionStyle.Render("First contact") + "\n")
+		b.WriteString(detailRow("Checked", time.Unix(fc.CheckedAt, 0).Format("2006-01-02 15:04")))
+		b.WriteString(detailRow("Host key", strings.Join(fc.Fingerprints, ", ")))
+		b.WriteString(detailRow("Offered", strings.Join(fc.Offered, ", ")))
+		b.WriteString(detailRow("Working", strings.Join(fc.Working, ", ")))
+		if fc.KeyInstalled {
+			b.WriteString(detailRow("Key", "installed with ssh-copy-id"))
+		}
+	}
+
+	if m.status.message != "" {
+		if m.status.isError {
+			b.WriteString("\n" + testFailStyle.Render("✘ "+m.status.message) + "\n")
+		} else {
+			b.WriteString("\n" + testSuccessStyle.Render("✔ "+m.status.message) + "\n")
+		}
+	}
+	b.WriteString("\n" + helpEntry("enter", "connect") + "  " + helpEntry("e", "edit") + "  " + helpEntry("f", "first contact") + "  " + helpEntry("s", "quick stats") + "  " + helpEntry("g", "diagnose") + "  " + helpEntry("y", "copy public key") + "  " + helpEntry("esc", "back"))
+	return centeredWorkspace(b.String(), width, height)
+}
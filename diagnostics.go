@@ -0,0 +1,371 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// --- Network Diagnostics ---
+
+// After a failed test, ctrl+g in the form (or g in the detail pane) runs DNS
+// resolution, a TCP dial of the SSH port, ping, and traceroute/mtr against the
+// host in parallel. The verdict line says whether the failure looks like a
+// network problem or one for ssh itself (auth, host key).
+
+type diagKind int
+
+const (
+	diagDNS diagKind = iota
+	diagPort
+	diagPing
+	diagTrace
+)
+
+var diagNames = map[diagKind]string{diagDNS: "DNS", diagPort: "SSH port", diagPing: "Ping", diagTrace: "Route"}
+
+// maxDiagDetail caps the detail lines (trace hops) kept per check.
+const maxDiagDetail = 8
+
+var (
+	pingLossPattern = regexp.MustCompile(`([\d.]+)% packet loss`)
+	pingRTTPattern  = regexp.MustCompile(`= [\d.]+/([\d.]+)/`)
+)
+
+type diagCheck struct {
+	kind    diagKind
+	done    bool
+	ok      bool
+	skipped bool
+	summary string
+	detail  []string
+}
+
+type diagnosticsState struct {
+	id       int // bumped per run so late results from an earlier run are dropped
+	host     Host
+	target   string // resolved endpoint, filled in once the run starts
+	returnTo state
+	checks   []diagCheck
+}
+
+type diagResolvedMsg struct {
+	run  int
+	host Host
+}
+
+type diagResultMsg struct {
+	run   int
+	check diagCheck
+}
+
+func (m model) openDiagnostics(h Host) (tea.Model, tea.Cmd) {
+	if strings.TrimSpace(h.Hostname) == "" {
+		return m, nil
+	}
+	returnTo := m.state
+	if m.state == stateDiagnostics {
+		returnTo = m.diagnostics.returnTo
+	}
+	run := m.diagnostics.id + 1
+	m.diagnostics = diagnosticsState{id: run, host: h, returnTo: returnTo}
+	for _, kind := range []diagKind{diagDNS, diagPort, diagPing, diagTrace} {
+		m.diagnostics.checks = append(m.diagnostics.checks, diagCheck{kind: kind})
+	}
+	m.state = stateDiagnostics
+	inv := m.inventory()
+	return m, func() tea.Msg { return diagResolvedMsg{run: run, host: resolveEndpoint(h, inv)} }
+}
+
+func (m model) startDiagnostics(msg diagResolvedMsg) (tea.Model, tea.Cmd) {
+	if msg.run != m.diagnostics.id {
+		return m, nil
+	}
+	h := msg.host
+	port := h.Port
+	if port == "" {
+		port = "22"
+	}
+	m.diagnostics.target = hostPort(h.Hostname, port)
+	host := bareHostname(h.Hostname)
+	run := msg.run
+	check := func(fn func() diagCheck) tea.Cmd {
+		return func() tea.Msg { return diagResultMsg{run: run, check: fn()} }
+	}
+	return m, tea.Batch(
+		check(func() diagCheck { return diagnoseDNS(host) }),
+		check(func() diagCheck { return diagnosePort(host, port, h.proxyVia()) }),
+		check(func() diagCheck { return diagnosePing(host) }),
+		check(func() diagCheck { return diagnoseTrace(host) }),
+	)
+}
+
+func (m model) finishDiagnostic(msg diagResultMsg) (tea.Model, tea.Cmd) {
+	if msg.run != m.diagnostics.id {
+		return m, nil
+	}
+	for i := range m.diagnostics.checks {
+		if m.diagnostics.checks[i].kind == msg.check.kind {
+			msg.check.done = true
+			m.diagnostics.checks[i] = msg.check
+		}
+	}
+	return m, nil
+}
+
+func diagnoseDNS(host string) diagCheck {
+	c := diagCheck{kind: diagDNS}
+	if _, err := netip.ParseAddr(host); err == nil {
+		c.ok, c.skipped, c.summary = true, true, "IP address, no lookup needed"
+		return c
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	addrs, err := net.DefaultResolver.LookupHost(ctx, host)
+	if err != nil {
+		c.summary = "does not resolve: " + dnsErrorText(err)
+		return c
+	}
+	c.ok, c.summary = true, strings.Join(addrs, ", ")
+	return c
+}
+
+func dnsErrorText(err error) string {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		switch {
+		case dnsErr.IsNotFound:
+			return "no such host"
+		case dnsErr.IsTimeout:
+			return "lookup timed out"
+		}
+		return dnsErr.Err
+	}
+	return err.Error()
+}
+
+func diagnosePort(host, port, via string) diagCheck {
+	c := diagCheck{kind: diagPort}
+	if via != "" {
+		c.ok, c.skipped, c.summary = true, true, "reached through "+via+"; not dialed directly"
+		return c
+	}
+	start := time.Now()
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort(host, port), 5*time.Second)
+	if err != nil {
+		c.summary = "port " + port + ": " + dialErrorText(err)
+		return c
+	}
+	conn.Close()
+	c.ok, c.summary = true, fmt.Sprintf("port %s open (%s)", port, formatLatency(time.Since(start)))
+	return c
+}
+
+func dialErrorText(err error) string {
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "refused"):
+		return "connection refused"
+	case strings.Contains(msg, "timeout"), strings.Contains(msg, "timed out"):
+		return "timed out (filtered or host down)"
+	case strings.Contains(msg, "no route"), strings.Contains(msg, "unreachable"):
+		return "no route to host"
+	}
+	return msg
+}
+
+// parsePingSummary pulls packet loss and average round-trip time out of
+// Linux or BSD ping output.
+func parsePingSummary(out string) (loss, avg string) {
+	if match := pingLossPattern.FindStringSubmatch(out); match != nil {
+		loss = match[1] + "%"
+	}
+	if match := pingRTTPattern.FindStringSubmatch(out); match != nil {
+		avg = match[1] + "ms"
+	}
+	return loss, avg
+}
+
+func diagnosePing(host string) diagCheck {
+	c := diagCheck{kind: diagPing}
+	if !commandExists("ping") {
+		c.ok, c.skipped, c.summary = true, true, "ping is not installed"
+		return c
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	out, _ := exec.CommandContext(ctx, "ping", "-c", "3", host).CombinedOutput()
+	loss, avg := parsePingSummary(string(out))
+	switch {
+	case loss == "":
+		c.summary = "no reply"
+		if line := lastLine(string(out)); line != "" {
+			c.summary = line
+		}
+	case loss == "100%":
+		c.summary = "100% packet loss (ICMP may be blocked)"
+	default:
+		c.ok = true
+		c.summary = loss + " packet loss"
+		if avg != "" {
+			c.summary += " · avg " + avg
+		}
+	}
+	return c
+}
+
+// traceCommand picks mtr, traceroute, or tracepath, in that order.
+func traceCommand(host string, exists func(string) bool) (string, []string) {
+	switch {
+	case exists("mtr"):
+		return "mtr", []string{"-r", "-n", "-c", "2", host}
+	case exists("traceroute"):
+		return "traceroute", []string{"-n", "-w", "2", "-q", "1", "-m", "20", host}
+	case exists("tracepath"):
+		return "tracepath", []string{"-n", "-m", "20", host}
+	}
+	return "", nil
+}
+
+// traceHops keeps the numbered hop lines of mtr, traceroute, or tracepath
+// output and drops their headers.
+func traceHops(out string) []string {
+	var hops []string
+	for _, line := range strings.Split(out, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && line[0] >= '0' && line[0] <= '9' {
+			hops = append(hops, line)
+		}
+	}
+	return hops
+}
+
+func diagnoseTrace(host string) diagCheck {
+	c := diagCheck{kind: diagTrace}
+	name, args := traceCommand(host, commandExists)
+	if name == "" {
+		c.ok, c.skipped, c.summary = true, true, "install mtr or traceroute for a route trace"
+		return c
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, name, args...).CombinedOutput()
+	hops := traceHops(string(out))
+	// The last hops say where traffic stops.
+	if len(hops) > maxDiagDetail {
+		hops = hops[len(hops)-maxDiagDetail:]
+	}
+	c.detail = hops
+	c.ok = err == nil
+	c.summary = fmt.Sprintf("%s · %d hops shown", name, len(hops))
+	if ctx.Err() == context.DeadlineExceeded {
+		c.summary = name + " timed out"
+	} else if err != nil && len(hops) == 0 {
+		c.summary = name + ": " + err.Error()
+	}
+	return c
+}
+
+func lastLine(s string) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
+
+// diagnosisVerdict sums up finished checks. It returns "" until DNS and the
+// port dial are both done, since those two decide the answer.
+func diagnosisVerdict(checks []diagCheck) (string, bool) {
+	byKind := map[diagKind]diagCheck{}
+	for _, c := range checks {
+		byKind[c.kind] = c
+	}
+	dns, port := byKind[diagDNS], byKind[diagPort]
+	if !dns.done || !port.done {
+		return "", false
+	}
+	switch {
+	case !dns.ok:
+		return "Network problem: the hostname does not resolve.", false
+	case !port.ok:
+		return "Network problem: the SSH port cannot be reached.", false
+	case port.skipped:
+		return "The jump host carries the connection; check it first, then auth on this host.", true
+	default:
+		return "The network looks fine; the failure is likely authentication or the host key.", true
+	}
+}
+
+func (m model) updateDiagnostics(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		m.quitting = true
+		return m, tea.Quit
+	case "esc", "q":
+		m.state = m.diagnostics.returnTo
+		if m.state == stateForm {
+			return m, m.focusInputs()
+		}
+	case "r":
+		return m.openDiagnostics(m.diagnostics.host)
+	}
+	return m, nil
+}
+
+func (m model) renderDiagnosticsView() string {
+	width, height := normalizedSize(m.width, m.height)
+	inner := max(min(width-6, 100)-6, 24)
+	d := m.diagnostics
+	var b strings.Builder
+	title := "DIAGNOSTICS · " + d.host.Alias
+	if d.host.Alias == "" {
+		title = "DIAGNOSTICS"
+	}
+	b.WriteString(lipgloss.NewStyle().Bold(true).Foreground(colorText).Render(ansi.Truncate(title, inner, "…")) + "\n")
+	target := d.target
+	if target == "" {
+		target = d.host.Hostname
+	}
+	b.WriteString(formHintStyle.Render(ansi.Truncate(target, inner, "…")) + "\n\n")
+
+	// Trace hops take whatever room is left after the fixed lines.
+	hopRoom := max(height-18, 0)
+	for _, c := range d.checks {
+		mark, style := m.spinner.View(), testPendingStyle
+		summary := "running…"
+		switch {
+		case c.done && c.skipped:
+			mark, style, summary = "·", formHintStyle, c.summary
+		case c.done && c.ok:
+			mark, style, summary = "✔", testSuccessStyle, c.summary
+		case c.done:
+			mark, style, summary = "✘", testFailStyle, c.summary
+		}
+		line := fmt.Sprintf("%-9s %s", diagNames[c.kind], summary)
+		b.WriteString(mark + " " + style.Render(ansi.Truncate(line, inner-2, "…")) + "\n")
+		detail := c.detail
+		if len(detail) > hopRoom {
+			detail = detail[len(detail)-hopRoom:]
+		}
+		for _, hop := range detail {
+			b.WriteString(formHintStyle.Render(ansi.Truncate("    "+hop, inner, "…")) + "\n")
+		}
+	}
+	if verdict, ok := diagnosisVerdict(d.checks); verdict != "" {
+		style := testFailStyle
+		if ok {
+			style = testSuccessStyle
+		}
+		b.WriteString("\n" + style.Render(ansi.Truncate(verdict, inner, "…")) + "\n")
+	}
+	b.WriteString("\n" + helpEntry("r", "run again") + "  " + helpEntry("esc", "back"))
+	return centeredWorkspace(b.String(), width, height)
+}
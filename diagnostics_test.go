@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestParsePingSummary(t *testing.T) {
+	linux := "3 packets transmitted, 3 received, 0% packet loss, time 2003ms\nrtt min/avg/max/mdev = 0.412/0.530/0.701/0.120 ms\n"
+	if loss, avg := parsePingSummary(linux); loss != "0%" || avg != "0.530ms" {
+		t.Fatalf("unexpected linux summary %q %q", loss, avg)
+	}
+	bsd := "3 packets transmitted, 0 packets received, 100.0% packet loss\n"
+	if loss, avg := parsePingSummary(bsd); loss != "100.0%" || avg != "" {
+		t.Fatalf("unexpected bsd summary %q %q", loss, avg)
+	}
+}
+
+func TestTraceCommandAndHops(t *testing.T) {
+	only := func(names ...string) func(string) bool {
+		return func(name string) bool { return strings.Contains(strings.Join(names, ","), name) }
+	}
+	if name, _ := traceCommand("h", only("traceroute", "mtr")); name != "mtr" {
+		t.Fatalf("expected mtr to be preferred, got %q", name)
+	}
+	if name, args := traceCommand("h", only("tracepath")); name != "tracepath" || args[len(args)-1] != "h" {
+		t.Fatalf("expected tracepath fallback, got %q %v", name, args)
+	}
+	if name, _ := traceCommand("h", only()); name != "" {
+		t.Fatal("expected no trace tool")
+	}
+	out := "traceroute to h (10.0.0.9), 20 hops max\n 1  10.0.0.1  0.3 ms\n 2  * \n"
+	if hops := traceHops(out); len(hops) != 2 || hops[0] != "1  10.0.0.1  0.3 ms" {
+		t.Fatalf("unexpected hops %q", hops)
+	}
+}
+
+func TestDiagnosisVerdict(t *testing.T) {
+	dns := diagCheck{kind: diagDNS, done: true, ok: true}
+	port := diagCheck{kind: diagPort, done: true, ok: true}
+	cases := []struct {
+		checks []diagCheck
+		want   string
+		ok     bool
+	}{
+		{[]diagCheck{dns, {kind: diagPort}}, "", false},
+		{[]diagCheck{{kind: diagDNS, done: true}, port}, "does not resolve", false},
+		{[]diagCheck{dns, {kind: diagPort, done: true}}, "SSH port cannot be reached", false},
+		{[]diagCheck{dns, port}, "authentication", true},
+	}
+	for _, tc := range cases {
+		got, ok := diagnosisVerdict(tc.checks)
+		if (tc.want == "" && got != "") || !strings.Contains(got, tc.want) || ok != tc.ok {
+			t.Errorf("expected %q/%v, got %q/%v", tc.want, tc.ok, got, ok)
+		}
+	}
+}
+
+func TestFormDiagnosticsRoundTrip(t *testing.T) {
+	m := model{state: stateForm, form: newFormState(newFormInputs())}
+	m.form.inputs[fieldAlias].SetValue("edge")
+	m.form.inputs[fieldHostname].SetValue("10.0.0.8")
+	updated, cmd := m.updateForm(tea.KeyMsg{Type: tea.KeyCtrlG})
+	m = updated.(model)
+	if cmd == nil || m.state != stateDiagnostics || len(m.diagnostics.checks) != 4 {
+		t.Fatalf("expected diagnostics to open, got state %v", m.state)
+	}
+	updated, _ = m.startDiagnostics(diagResolvedMsg{run: m.diagnostics.id, host: m.diagnostics.host})
+	m = updated.(model)
+	updated, _ = m.finishDiagnostic(diagResultMsg{run: m.diagnostics.id - 1, check: diagCheck{kind: diagDNS, ok: true}})
+	if m = updated.(model); m.diagnostics.checks[0].done {
+		t.Fatal("expected results from an earlier run to be dropped")
+	}
+	updated, _ = m.finishDiagnostic(diagResultMsg{run: m.diagnostics.id, check: diagCheck{kind: diagPort, summary: "port 22: connection refused"}})
+	m = updated.(model)
+	if view := m.renderDiagnosticsView(); !strings.Contains(view, "connection refused") || !strings.Contains(view, "10.0.0.8:22") {
+		t.Fatalf("expected port result in the view, got:\n%s", view)
+	}
+	updated, _ = m.updateDiagnostics(tea.KeyMsg{Type: tea.KeyEsc})
+	if m = updated.(model); m.state != stateForm {
+		t.Fatalf("expected esc to return to the form, got %v", m.state)
+	}
+}
+
+func TestDiagnosticsViewFitsTerminal(t *testing.T) {
+	var hops []string
+	for i := range maxDiagDetail {
+		hops = append(hops, fmt.Sprintf("%d  very-long-router-name-%d.backbone.example.net (10.0.%d.1)  12.345 ms", i+1, i, i))
+	}
+	d := diagnosticsState{
+		host:   Host{Alias: "a-very-long-host-alias-for-layout", Hostname: "very-long-hostname.internal.example.com"},
+		target: "very-long-hostname.internal.example.com:2222",
+		checks: []diagCheck{
+			{kind: diagDNS, done: true, ok: true, summary: "10.0.0.1, 10.0.0.2, 2001:db8::1, 2001:db8::2"},
+			{kind: diagPort, done: true, summary: "port 2222: timed out (filtered or host down)"},
+			{kind: diagPing, done: true, ok: true, summary: "0% packet loss · avg 12.3ms"},
+			{kind: diagTrace, done: true, ok: true, summary: "traceroute · 8 hops shown", detail: hops},
+		},
+	}
+	for _, size := range []struct{ width, height int }{{40, 16}, {80, 24}, {140, 40}} {
+		m := model{width: size.width, height: size.height, diagnostics: d}
+		lines := strings.Split(m.renderDiagnosticsView(), "\n")
+		if len(lines) > size.height {
+			t.Fatalf("%dx%d: got %d lines", size.width, size.height, len(lines))
+		}
+		for i, line := range lines {
+			if ansi.StringWidth(line) > size.width {
+				t.Fatalf("%dx%d line %d has width %d", size.width, size.height, i, ansi.StringWidth(line))
+			}
+		}
+	}
+}
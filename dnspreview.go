@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/netip"
+	"slices"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// --- DNS Preview ---
+
+// Selecting a host resolves its hostname in the background and shows the
+// addresses on its row. The last answer is cached on the host (LastIPs), so a
+// dynamic-DNS host whose address moved is flagged with the old address, which
+// often explains a sudden connection failure.
+
+const (
+	// dnsLookupDelay debounces lookups while the cursor is moving.
+	dnsLookupDelay = 300 * time.Millisecond
+	// dnsLookupTTL is how long a lookup is reused before resolving again.
+	dnsLookupTTL = time.Minute
+)
+
+type dnsLookup struct {
+	hostname string
+	ips      []string
+	previous []string // cached addresses that no longer match; empty when unchanged
+	err      string
+	at       time.Time
+}
+
+// changed reports whether the host's cached addresses moved.
+func (l dnsLookup) changed() bool { return len(l.previous) > 0 }
+
+type dnsLookupTickMsg struct {
+	hostID string
+	seq    int
+}
+
+type dnsLookupMsg struct {
+	hostID   string
+	hostname string
+	ips      []string
+	err      error
+}
+
+// queueSelectedLookup schedules a lookup for the selected host unless a
+// fresh one is already cached.
+func (m model) queueSelectedLookup(cmd tea.Cmd) (tea.Model, tea.Cmd) {
+	h, ok := m.list.SelectedItem().(Host)
+	if !ok || h.IsContainer || h.ID == "" {
+		return m, cmd
+	}
+	hostname := bareHostname(h.Hostname)
+	if _, err := netip.ParseAddr(hostname); err == nil || hostname == "" {
+		return m, cmd
+	}
+	if l, ok := m.dnsLookups[h.ID]; ok && l.hostname == hostname && time.Since(l.at) < dnsLookupTTL {
+		return m, cmd
+	}
+	m.dnsSeq++
+	seq, id := m.dnsSeq, h.ID
+	tick := tea.Tick(dnsLookupDelay, func(time.Time) tea.Msg { return dnsLookupTickMsg{hostID: id, seq: seq} })
+	return m, tea.Batch(cmd, tick)
+}
+
+func (m model) startDNSLookup(msg dnsLookupTickMsg) (tea.Model, tea.Cmd) {
+	if msg.seq != m.dnsSeq {
+		return m, nil
+	}
+	idx := findHostIndexByID(m.rawHosts, msg.hostID)
+	if idx == -1 {
+		return m, nil
+	}
+	hostname := bareHostname(m.rawHosts[idx].Hostname)
+	return m, func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+		ips, err := net.DefaultResolver.LookupHost(ctx, hostname)
+		slices.Sort(ips)
+		return dnsLookupMsg{hostID: msg.hostID, hostname: hostname, ips: ips, err: err}
+	}
+}
+
+func (m model) finishDNSLookup(msg dnsLookupMsg) (tea.Model, tea.Cmd) {
+	idx := findHostIndexByID(m.rawHosts, msg.hostID)
+	if idx == -1 || bareHostname(m.rawHosts[idx].Hostname) != msg.hostname {
+		return m, nil
+	}
+	if m.dnsLookups == nil {
+		m.dnsLookups = make(map[string]dnsLookup)
+	}
+	lookup := dnsLookup{hostname: msg.hostname, at: time.Now()}
+	if msg.err != nil {
+		lookup.err = dnsErrorText(msg.err)
+		m.dnsLookups[msg.hostID] = lookup
+		m.refreshDelegate()
+		return m, nil
+	}
+	lookup.ips = msg.ips
+	cached := m.rawHosts[idx].LastIPs
+	switch {
+	case len(cached) > 0 && !slices.Equal(cached, msg.ips):
+		lookup.previous = cached
+	case slices.Equal(cached, msg.ips):
+		// Keep the warning for the session once it has been raised.
+		lookup.previous = m.dnsLookups[msg.hostID].previous
+	}
+	m.dnsLookups[msg.hostID] = lookup
+	if !slices.Equal(cached, msg.ips) {
+		m.rawHosts[idx].LastIPs = msg.ips
+		_ = m.save()
+	}
+	m.refreshDelegate()
+	return m, nil
+}
+
+// dnsLookupLabel is the row and detail-pane text for a lookup.
+func dnsLookupLabel(l dnsLookup) string {
+	if l.err != "" {
+		return "⚠ DNS: " + l.err
+	}
+	ips := l.ips
+	extra := ""
+	if len(ips) > 2 {
+		ips, extra = ips[:2], fmt.Sprintf(" +%d", len(l.ips)-2)
+	}
+	label := "→ " + strings.Join(ips, ", ") + extra
+	if l.changed() {
+		label = "⚠ IP changed (was " + strings.Join(l.previous, ", ") + ") " + label
+	}
+	return label
+}
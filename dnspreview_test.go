@@ -0,0 +1,77 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestDNSLookupFlagsChangedAddresses(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("ASSHO_STORE_PASSWORD", "0")
+	hosts := []Host{{ID: "h1", Alias: "home", Hostname: "home.dyn.example"}}
+	m := model{rawHosts: hosts, list: newTestListModel(nil, hosts), historyList: newTestHistoryListModel()}
+
+	updated, _ := m.finishDNSLookup(dnsLookupMsg{hostID: "h1", hostname: "home.dyn.example", ips: []string{"203.0.113.4"}})
+	m = updated.(model)
+	if got := m.rawHosts[0].LastIPs; len(got) != 1 || m.dnsLookups["h1"].changed() {
+		t.Fatalf("expected first answer cached without a warning, got %v", got)
+	}
+
+	updated, _ = m.finishDNSLookup(dnsLookupMsg{hostID: "h1", hostname: "home.dyn.example", ips: []string{"203.0.113.9"}})
+	m = updated.(model)
+	label := dnsLookupLabel(m.dnsLookups["h1"])
+	if !strings.Contains(label, "IP changed (was 203.0.113.4)") || !strings.Contains(label, "203.0.113.9") {
+		t.Fatalf("expected a changed-address warning, got %q", label)
+	}
+	updated, _ = m.finishDNSLookup(dnsLookupMsg{hostID: "h1", hostname: "home.dyn.example", ips: []string{"203.0.113.9"}})
+	if m = updated.(model); !m.dnsLookups["h1"].changed() {
+		t.Fatal("expected the warning to stay for the session")
+	}
+	_, saved, _, err := loadConfig()
+	if err != nil || strings.Join(saved[0].LastIPs, ",") != "203.0.113.9" {
+		t.Fatalf("expected the new answer to be saved, got %+v %v", saved, err)
+	}
+
+	updated, _ = m.finishDNSLookup(dnsLookupMsg{hostID: "h1", hostname: "old.example", ips: []string{"198.51.100.1"}})
+	if m = updated.(model); m.dnsLookups["h1"].hostname != "home.dyn.example" {
+		t.Fatal("expected a lookup for a stale hostname to be dropped")
+	}
+	updated, _ = m.finishDNSLookup(dnsLookupMsg{hostID: "h1", hostname: "home.dyn.example", err: errors.New("boom")})
+	if m = updated.(model); !strings.HasPrefix(dnsLookupLabel(m.dnsLookups["h1"]), "⚠ DNS") || len(m.rawHosts[0].LastIPs) != 1 {
+		t.Fatal("expected a failed lookup to keep the cached answer")
+	}
+}
+
+func TestDNSLookupLabel(t *testing.T) {
+	l := dnsLookup{ips: []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"}}
+	if got := dnsLookupLabel(l); got != "→ 10.0.0.1, 10.0.0.2 +1" {
+		t.Fatalf("unexpected label %q", got)
+	}
+}
+
+func TestSelectingHostQueuesLookup(t *testing.T) {
+	hosts := []Host{{ID: "h1", Alias: "ip", Hostname: "10.0.0.1"}, {ID: "h2", Alias: "named", Hostname: "named.example"}}
+	m := model{state: stateList, rawHosts: hosts, list: newTestListModel(nil, hosts), historyList: newTestHistoryListModel()}
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyUp})
+	if m = updated.(model); m.dnsSeq != 0 {
+		t.Fatal("expected IP literals not to be looked up")
+	}
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	m = updated.(model)
+	if m.dnsSeq != 1 {
+		t.Fatalf("expected a lookup to be queued for the named host, got seq %d", m.dnsSeq)
+	}
+	if _, cmd := m.startDNSLookup(dnsLookupTickMsg{hostID: "h2", seq: 0}); cmd != nil {
+		t.Fatal("expected a superseded tick to be ignored")
+	}
+	m.dnsLookups = map[string]dnsLookup{"h2": {hostname: "named.example", at: time.Now()}}
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	if m = updated.(model); m.dnsSeq != 1 {
+		t.Fatal("expected a fresh lookup to be reused")
+	}
+}
@@ -0,0 +1,273 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os/exec"
+	"slices"
+	"strings"
+	"time"
+)
+
+// --- Docker Engine API ---
+
+// With ASSHO_DOCKER_API=1 container scans ask the Docker Engine API instead of
+// parsing `docker ps`. The API is reached the way DOCKER_HOST=ssh:// reaches
+// it: ssh runs `docker system dial-stdio` and HTTP flows over its stdin and
+// stdout. The local host dials the socket directly. The API adds labels and
+// reports ports without screen-scraping; when it fails, the CLI result stands.
+
+// dockerSocket is where the local host's Engine API listens.
+const dockerSocket = "/var/run/docker.sock"
+
+// DockerInfo is what a scan learned about a container: the image, state, and
+// published ports from either `docker ps` or the Engine API, plus health and
+// labels where the API reports them.
+type DockerInfo struct {
+	Image  string            `json:"image,omitempty"`
+	State  string            `json:"state,omitempty"`
+	Health string            `json:"health,omitempty"` // healthy, unhealthy, starting
+	Ports  []string          `json:"ports,omitempty"`
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+type dockerAPIContainer struct {
+	ID     string            `json:"Id"`
+	Names  []string          `json:"Names"`
+	Image  string            `json:"Image"`
+	State  string            `json:"State"`
+	Status string            `json:"Status"`
+	Labels map[string]string `json:"Labels"`
+	Ports  []struct {
+		IP          string `json:"IP"`
+		PrivatePort int    `json:"PrivatePort"`
+		PublicPort  int    `json:"PublicPort"`
+		Type        string `json:"Type"`
+	} `json:"Ports"`
+}
+
+func dockerAPIEnabled() bool {
+	return envBool("ASSHO_DOCKER_API", false)
+}
+
+// dockerHealth pulls the health check result out of a status like
+// "Up 2 hours (healthy)".
+func dockerHealth(status string) string {
+	switch {
+	case strings.Contains(status, "(healthy)"):
+		return "healthy"
+	case strings.Contains(status, "(unhealthy)"):
+		return "unhealthy"
+	case strings.Contains(status, "(health: starting)"):
+		return "starting"
+	}
+	return ""
+}
+
+// dockerState maps a `docker ps` status like "Up 2 hours (Paused)" or
+// "Exited (0) 3 days ago" to the Engine API's state name.
+func dockerState(status string) string {
+	switch {
+	case strings.HasPrefix(status, "Up") && strings.HasSuffix(status, "(Paused)"):
+		return "paused"
+	case strings.HasPrefix(status, "Up"):
+		return "running"
+	case strings.HasPrefix(status, "Exited"):
+		return "exited"
+	case strings.HasPrefix(status, "Restarting"):
+		return "restarting"
+	case strings.HasPrefix(status, "Removal"):
+		return "removing"
+	}
+	return strings.ToLower(strings.TrimSpace(status))
+}
+
+// dockerPort renders one port mapping as "8080→80/tcp", keeping the host
+// address only when it is not a wildcard.
+func dockerPort(ip, public, private string) string {
+	if public == "" {
+		return private
+	}
+	if ip = strings.Trim(ip, "[]"); ip != "" && ip != "0.0.0.0" && ip != "::" {
+		public = hostPort(ip, public)
+	}
+	return public + "→" + private
+}
+
+// parseDockerCLIPorts reads the `docker ps` Ports column, e.g.
+// "0.0.0.0:8080->80/tcp, :::8080->80/tcp, 443/tcp".
+func parseDockerCLIPorts(column string) []string {
+	var ports []string
+	for _, field := range strings.Split(column, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		published, private, mapped := strings.Cut(field, "->")
+		if !mapped {
+			ports = append(ports, field)
+			continue
+		}
+		ip, public := "", published
+		if i := strings.LastIndex(published, ":"); i >= 0 {
+			ip, public = published[:i], published[i+1:]
+		}
+		ports = append(ports, dockerPort(ip, public, private))
+	}
+	return tidyPorts(ports)
+}
+
+// tidyPorts sorts ports and drops repeats; Docker lists a wildcard binding
+// once per address family.
+func tidyPorts(ports []string) []string {
+	slices.Sort(ports)
+	return slices.Compact(ports)
+}
+
+// running reports whether the container is up; a scan that learned nothing
+// about its state counts as running.
+func (d *DockerInfo) running() bool {
+	return d == nil || d.State == "" || d.State == "running"
+}
+
+func parseDockerAPIContainers(body []byte, parentID string) ([]Host, error) {
+	var listed []dockerAPIContainer
+	if err := json.Unmarshal(body, &listed); err != nil {
+		return nil, fmt.Errorf("unexpected Docker API response: %v", err)
+	}
+	var hosts []Host
+	for _, c := range listed {
+		if len(c.Names) == 0 {
+			continue
+		}
+		name := strings.TrimPrefix(c.Names[0], "/")
+		info := &DockerInfo{Image: c.Image, State: c.State, Health: dockerHealth(c.Status), Labels: c.Labels}
+		for _, p := range c.Ports {
+			public := ""
+			if p.PublicPort != 0 {
+				public = fmt.Sprint(p.PublicPort)
+			}
+			info.Ports = append(info.Ports, dockerPort(p.IP, public, fmt.Sprintf("%d/%s", p.PrivatePort, p.Type)))
+		}
+		info.Ports = tidyPorts(info.Ports)
+		hosts = append(hosts, Host{
+			ID:          newHostID(),
+			Alias:       name,
+			Hostname:    name,
+			User:        "root",
+			IsContainer: true,
+			ParentID:    parentID,
+			Docker:      info,
+		})
+	}
+	return hosts, nil
+}
+
+// stdioConn is a net.Conn over a child process's stdin and stdout.
+type stdioConn struct {
+	io.Reader
+	io.WriteCloser
+	cmd *exec.Cmd
+}
+
+func (c *stdioConn) Close() error {
+	_ = c.WriteCloser.Close()
+	if c.cmd.Process != nil {
+		_ = c.cmd.Process.Kill()
+	}
+	_ = c.cmd.Wait()
+	return nil
+}
+
+func (c *stdioConn) LocalAddr() net.Addr                { return stdioAddr{} }
+func (c *stdioConn) RemoteAddr() net.Addr               { return stdioAddr{} }
+func (c *stdioConn) SetDeadline(t time.Time) error      { return nil }
+func (c *stdioConn) SetReadDeadline(t time.Time) error  { return nil }
+func (c *stdioConn) SetWriteDeadline(t time.Time) error { return nil }
+
+type stdioAddr struct{}
+
+func (stdioAddr) Network() string { return "stdio" }
+func (stdioAddr) String() string  { return "docker system dial-stdio" }
+
+// dialDockerStdio starts `docker system dial-stdio` on h over ssh.
+func dialDockerStdio(ctx context.Context, h Host) (net.Conn, error) {
+	cmd := remoteExecCommand(ctx, h, "docker system dial-stdio")
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	return &stdioConn{Reader: stdout, WriteCloser: stdin, cmd: cmd}, nil
+}
+
+// scanDockerAPI lists h's containers, stopped ones included, through the
+// Engine API.
+func scanDockerAPI(parent context.Context, h Host) ([]Host, error) {
+	ctx, cancel := context.WithTimeout(parent, h.commandTimeout())
+	defer cancel()
+	dial := func(ctx context.Context, _, _ string) (net.Conn, error) {
+		if h.isLocal() {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", dockerSocket)
+		}
+		return dialDockerStdio(ctx, h)
+	}
+	client := &http.Client{Transport: &http.Transport{DialContext: dial, DisableKeepAlives: true}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://docker/containers/json?all=1", nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 16<<20))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Docker API returned %s", resp.Status)
+	}
+	return parseDockerAPIContainers(body, h.ID)
+}
+
+// mergeDockerAPIScan swaps the CLI's docker rows for the API's richer ones
+// and keeps everything else the scan found.
+func mergeDockerAPIScan(scanned, api []Host) []Host {
+	merged := append([]Host(nil), api...)
+	for _, h := range scanned {
+		if h.Kind != "" {
+			merged = append(merged, h)
+		}
+	}
+	return merged
+}
+
+// summary is the list description for a container a scan described.
+func (d *DockerInfo) summary() string {
+	var parts []string
+	if d.Image != "" {
+		parts = append(parts, d.Image)
+	}
+	if d.Health != "" {
+		parts = append(parts, d.Health)
+	} else if d.State != "" && d.State != "running" {
+		parts = append(parts, d.State)
+	}
+	if len(d.Ports) > 0 {
+		parts = append(parts, strings.Join(d.Ports, ", "))
+	}
+	return strings.Join(parts, " · ")
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseDockerAPIContainers(t *testing.T) {
+	body := []byte(`[
+		{"Id": "abc", "Names": ["/web"], "Image": "nginx:1.27", "State": "running", "Status": "Up 2 hours (healthy)",
+		 "Labels": {"com.docker.compose.project": "site"},
+		 "Ports": [
+			{"IP": "0.0.0.0", "PrivatePort": 80, "PublicPort": 8080, "Type": "tcp"},
+			{"IP": "::", "PrivatePort": 80, "PublicPort": 8080, "Type": "tcp"},
+			{"IP": "127.0.0.1", "PrivatePort": 9000, "PublicPort": 9000, "Type": "tcp"},
+			{"PrivatePort": 443, "Type": "tcp"}
+		 ]},
+		{"Id": "def", "Names": [], "Image": "busybox"}
+	]`)
+	hosts, err := parseDockerAPIContainers(body, "p1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hosts) != 1 || hosts[0].Alias != "web" || hosts[0].ParentID != "p1" || hosts[0].Docker == nil {
+		t.Fatalf("unexpected hosts %+v", hosts)
+	}
+	info := hosts[0].Docker
+	if info.Health != "healthy" || info.Labels["com.docker.compose.project"] != "site" {
+		t.Fatalf("unexpected info %+v", info)
+	}
+	wantPorts := []string{"127.0.0.1:9000→9000/tcp", "443/tcp", "8080→80/tcp"}
+	if !slices.Equal(info.Ports, wantPorts) {
+		t.Fatalf("got ports %v, want %v", info.Ports, wantPorts)
+	}
+	if got := info.summary(); got != "nginx:1.27 · healthy · 127.0.0.1:9000→9000/tcp, 443/tcp, 8080→80/tcp" {
+		t.Fatalf("unexpected summary %q", got)
+	}
+
+	if _, err := parseDockerAPIContainers([]byte("not json"), "p1"); err == nil {
+		t.Fatal("expected an error for a non-JSON body")
+	}
+}
+
+func TestMergeDockerAPIScanKeepsOtherKinds(t *testing.T) {
+	scanned := []Host{{Alias: "web"}, {Alias: "ubuntu-lab", Kind: hostKindVM}, {Alias: "db", Kind: hostKindLXD}}
+	api := []Host{{Alias: "web", Docker: &DockerInfo{State: "running"}}}
+	merged := mergeDockerAPIScan(scanned, api)
+	if len(merged) != 3 || merged[0].Docker == nil || merged[1].Alias != "ubuntu-lab" || merged[2].Alias != "db" {
+		t.Fatalf("unexpected merge %+v", merged)
+	}
+}
+
+func TestParseDockerCLIPorts(t *testing.T) {
+	got := parseDockerCLIPorts("0.0.0.0:8080->80/tcp, :::8080->80/tcp, [::]:8080->80/tcp, 127.0.0.1:9000->9000/tcp, 443/tcp")
+	want := []string{"127.0.0.1:9000→9000/tcp", "443/tcp", "8080→80/tcp"}
+	if !slices.Equal(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	if got := parseDockerCLIPorts(""); len(got) != 0 {
+		t.Fatalf("expected no ports, got %v", got)
+	}
+}
+
+func TestDockerState(t *testing.T) {
+	for status, want := range map[string]string{
+		"Up 2 hours (healthy)":  "running",
+		"Up 5 minutes (Paused)": "paused",
+		"Exited (0) 3 days ago": "exited",
+		"Restarting (1) 1s ago": "restarting",
+		"Created":               "created",
+		"Removal In Progress":   "removing",
+	} {
+		if got := dockerState(status); got != want {
+			t.Errorf("dockerState(%q) = %q, want %q", status, got, want)
+		}
+	}
+}
+
+func TestWithoutStoppedContainers(t *testing.T) {
+	hosts := []Host{{ID: "p1", Containers: []Host{
+		{Alias: "web", IsContainer: true, Docker: &DockerInfo{State: "running"}},
+		{Alias: "job", IsContainer: true, Docker: &DockerInfo{State: "exited"}},
+		{Alias: "lab", IsContainer: true, Kind: hostKindVM},
+	}}}
+	filtered := withoutStoppedContainers(hosts)
+	if len(filtered[0].Containers) != 2 || filtered[0].Containers[0].Alias != "web" || filtered[0].Containers[1].Alias != "lab" {
+		t.Fatalf("unexpected containers %+v", filtered[0].Containers)
+	}
+	if len(hosts[0].Containers) != 3 {
+		t.Fatal("filtering must not modify the stored hosts")
+	}
+}
@@ -0,0 +1,280 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+)
+
+// --- Doctor ---
+
+// `assho doctor` checks what assho leans on and prints a fix for each
+// problem: ssh itself, the optional tools the saved hosts actually need
+// (sshpass for stored passwords, pwsh for WinRM hosts, docker for a local
+// host, the program of each ProxyCommand), the secret backend, which ssh
+// agent is in use, and hosts.json. It exits 1 when something assho cannot
+// work without is missing or broken. Features in the TUI report a missing
+// tool through errMissingTool, so they name the same fix.
+
+type doctorLevel int
+
+const (
+	doctorOK doctorLevel = iota
+	doctorInfo
+	doctorWarn
+	doctorFail
+)
+
+func (l doctorLevel) icon() string {
+	switch l {
+	case doctorInfo:
+		return "·"
+	case doctorWarn:
+		return "⚠"
+	case doctorFail:
+		return "✘"
+	}
+	return "✔"
+}
+
+type doctorCheck struct {
+	level  doctorLevel
+	name   string
+	detail string
+	fix    string
+}
+
+// toolPackage is where a tool comes from on Homebrew and on Debian-style
+// systems.
+type toolPackage struct {
+	brew, apt string
+}
+
+var toolPackages = map[string]toolPackage{
+	"ssh":         {"openssh", "openssh-client"},
+	"ssh-keygen":  {"openssh", "openssh-client"},
+	"ssh-keyscan": {"openssh", "openssh-client"},
+	"ssh-add":     {"openssh", "openssh-client"},
+	"ssh-copy-id": {"ssh-copy-id", "openssh-client"},
+	"sshpass":     {"hudochenkov/sshpass/sshpass", "sshpass"},
+	"rsync":       {"rsync", "rsync"},
+	"docker":      {"docker", "docker.io"},
+	"pwsh":        {"--cask powershell", "powershell"},
+	"secret-tool": {"", "libsecret-tools"},
+	"mtr":         {"mtr", "mtr-tiny"},
+	"ping":        {"", "iputils-ping"},
+	"corkscrew":   {"corkscrew", "corkscrew"},
+	"cloudflared": {"cloudflared", "cloudflared"},
+	"nc":          {"netcat", "netcat-openbsd"},
+	"websocat":    {"websocat", "websocat"},
+	"notify-send": {"", "libnotify-bin"},
+}
+
+// installHint is the command that installs tool on this platform.
+func installHint(tool string) string {
+	pkg, ok := toolPackages[tool]
+	if !ok {
+		pkg = toolPackage{tool, tool}
+	}
+	if runtime.GOOS == "darwin" && pkg.brew != "" {
+		return "brew install " + pkg.brew
+	}
+	return "sudo apt install " + pkg.apt + " (or your distribution's package)"
+}
+
+// errMissingTool reports a missing tool along with how to install it.
+func errMissingTool(tool string) error {
+	return fmt.Errorf("%s is not installed; %s", tool, installHint(tool))
+}
+
+// toolCheck reports tool as missing at level, or fine when it is on PATH.
+func toolCheck(tool, purpose string, level doctorLevel, lookPath func(string) (string, error)) doctorCheck {
+	path, err := lookPath(tool)
+	if err != nil {
+		return doctorCheck{level: level, name: tool, detail: "not found · " + purpose, fix: installHint(tool)}
+	}
+	return doctorCheck{name: tool, detail: path}
+}
+
+func doctorToolChecks(hosts []Host, lookPath func(string) (string, error)) []doctorCheck {
+	var passwords, winrm, local int
+	proxies := map[string]int{} // ProxyCommand program → hosts using it
+	walkHosts(hosts, func(h Host) {
+		if h.Password != "" || h.PasswordRef != "" {
+			passwords++
+		}
+		if program := proxyProgram(h.ProxyCommand); program != "" {
+			proxies[program]++
+		}
+		switch h.Transport {
+		case transportPSRemoting:
+			winrm++
+		case transportLocal:
+			local++
+		}
+	})
+	needed := func(count int, what string) (doctorLevel, string) {
+		if count == 0 {
+			return doctorInfo, "not needed by any saved host"
+		}
+		return doctorWarn, fmt.Sprintf("needed by %d %s", count, what)
+	}
+	checks := []doctorCheck{
+		toolCheck("ssh", "every connection", doctorFail, lookPath),
+		toolCheck("ssh-keygen", "host key review and key generation", doctorWarn, lookPath),
+		toolCheck("ssh-keyscan", "first-contact fingerprints", doctorWarn, lookPath),
+	}
+	level, purpose := needed(passwords, "host(s) with a stored password")
+	checks = append(checks, toolCheck("sshpass", purpose, level, lookPath))
+	level, purpose = needed(winrm, "PS remoting host(s)")
+	checks = append(checks, toolCheck("pwsh", purpose, level, lookPath))
+	level, purpose = needed(local, "local host(s) for container scans")
+	checks = append(checks,
+		toolCheck("docker", purpose, level, lookPath),
+		toolCheck("rsync", "faster transfers; scp is used without it", doctorInfo, lookPath),
+		toolCheck("ssh-copy-id", "installing public keys (Ctrl+K)", doctorInfo, lookPath),
+		toolCheck("mtr", "route traces in network diagnostics", doctorInfo, lookPath),
+	)
+	for _, program := range slices.Sorted(maps.Keys(proxies)) {
+		level, purpose = needed(proxies[program], "host(s) as their ProxyCommand")
+		checks = append(checks, toolCheck(program, purpose, level, lookPath))
+	}
+	return checks
+}
+
+// walkHosts calls fn for every host and nested container.
+func walkHosts(hosts []Host, fn func(Host)) {
+	for _, h := range hosts {
+		fn(h)
+		walkHosts(h.Containers, fn)
+	}
+}
+
+func doctorSecretCheck(lookPath func(string) (string, error)) doctorCheck {
+	if p, ok, err := secretPlugin(); ok {
+		if err != nil {
+			return doctorCheck{level: doctorFail, name: "secrets", detail: err.Error(), fix: "install the plugin or change ASSHO_SECRET_BACKEND"}
+		}
+		return doctorCheck{name: "secrets", detail: "plugin " + p.name}
+	}
+	if secretsOffline() {
+		return doctorCheck{level: doctorInfo, name: "secrets", detail: "offline (ASSHO_SECRETS_OFFLINE); stored passwords are not read"}
+	}
+	if !shouldPersistPassword() {
+		return doctorCheck{level: doctorInfo, name: "secrets", detail: "passwords are not saved (ASSHO_STORE_PASSWORD=0)"}
+	}
+	if secretBackend() == secretBackendConfig {
+		return doctorCheck{level: doctorWarn, name: "secrets", detail: "passwords are stored in plaintext in hosts.json",
+			fix: "unset ASSHO_SECRET_BACKEND and run assho secrets migrate to move them to the keychain"}
+	}
+	switch runtime.GOOS {
+	case "darwin":
+		return doctorCheck{name: "secrets", detail: "macOS keychain"}
+	case "linux":
+		if _, err := lookPath("secret-tool"); err != nil {
+			return doctorCheck{level: doctorWarn, name: "secrets", detail: "secret-tool not found, so passwords cannot be saved", fix: installHint("secret-tool")}
+		}
+		return doctorCheck{name: "secrets", detail: "Secret Service keyring (secret-tool)"}
+	}
+	return doctorCheck{level: doctorWarn, name: "secrets", detail: "no keychain on " + runtime.GOOS, fix: "set ASSHO_SECRET_BACKEND=config or use a secrets plugin"}
+}
+
+func doctorAgentCheck() doctorCheck {
+	agent, ok := detectSSHAgent()
+	switch {
+	case ok && agent.source == "SSH_AUTH_SOCK":
+		return doctorCheck{name: "ssh-agent", detail: agent.kind + " · " + agent.socket}
+	case ok:
+		return doctorCheck{name: "ssh-agent", detail: fmt.Sprintf("%s · %s (found through %s; SSH_AUTH_SOCK is unset)", agent.kind, agent.socket, agent.source)}
+	case agent.socket != "":
+		return doctorCheck{level: doctorWarn, name: "ssh-agent", detail: "SSH_AUTH_SOCK points at " + agent.socket + ", which is not reachable",
+			fix: "restart the agent or unset SSH_AUTH_SOCK so assho can look for gpg-agent or Pageant"}
+	}
+	return doctorCheck{level: doctorInfo, name: "ssh-agent", detail: "no agent; passphrase-protected keys prompt on every connection",
+		fix: `eval "$(ssh-agent)" && ssh-add, or enable-ssh-support in gpg-agent.conf`}
+}
+
+// doctorConfigChecks looks at hosts.json: whether it parses, who can read
+// it, records that need repair, and key files that are missing.
+func doctorConfigChecks() ([]doctorCheck, []Host) {
+	path := getConfigPath()
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return []doctorCheck{{level: doctorInfo, name: "config", detail: "no " + path + " yet; it is created on the first save"}}, nil
+	}
+	if err != nil {
+		return []doctorCheck{{level: doctorFail, name: "config", detail: err.Error()}}, nil
+	}
+	cfg, err := loadConfigFile()
+	if err != nil {
+		return []doctorCheck{{level: doctorFail, name: "config", detail: err.Error(), fix: "fix the JSON in " + path + " or restore it from a backup"}}, nil
+	}
+	checks := []doctorCheck{{name: "config", detail: fmt.Sprintf("%s · %d hosts, %d groups", path, len(cfg.Hosts), len(cfg.Groups))}}
+	if perm := info.Mode().Perm(); perm&0o077 != 0 {
+		checks = append(checks, doctorCheck{level: doctorWarn, name: "config", detail: fmt.Sprintf("%s is readable by others (mode %04o)", filepath.Base(path), perm), fix: "chmod 600 " + path})
+	}
+	if issues := findIntegrityIssues(cfg.Groups, cfg.Hosts, cfg.History); len(issues) > 0 {
+		checks = append(checks, doctorCheck{level: doctorWarn, name: "config", detail: fmt.Sprintf("%d record(s) need repair", len(issues)), fix: "start assho to review and apply the repairs"})
+	}
+	if err := validateWebhooks(cfg.Webhooks); err != nil {
+		checks = append(checks, doctorCheck{level: doctorWarn, name: "webhooks", detail: err.Error(), fix: "fix the webhooks section of " + path})
+	}
+	blocks := loadSSHConfigBlocks()
+	var badJumps []string
+	for _, h := range withGroupDefaults(cfg.Hosts, cfg.Groups) {
+		if len(jumpProblems(jumpChain(h, cfg.Hosts, blocks))) > 0 {
+			badJumps = append(badJumps, h.Alias)
+		}
+	}
+	if len(badJumps) > 0 {
+		checks = append(checks, doctorCheck{level: doctorWarn, name: "jumps", detail: "ssh cannot resolve the ProxyJump of " + strings.Join(badJumps, ", "), fix: "open the host's detail pane (v) to see which hop, then add it to ~/.ssh/config or use its address"})
+	}
+	var missingKeys, openKeys []string
+	for _, h := range cfg.Hosts {
+		if identityFileWarning(h.IdentityFile) != "" {
+			missingKeys = append(missingKeys, h.Alias)
+		} else if keyFileTooOpen(h.IdentityFile) && !slices.Contains(openKeys, h.IdentityFile) {
+			openKeys = append(openKeys, h.IdentityFile)
+		}
+	}
+	for _, key := range openKeys {
+		checks = append(checks, doctorCheck{level: doctorWarn, name: "keys", detail: key + " is readable by others, so ssh ignores it", fix: permFixHint(expandPath(key))})
+	}
+	if len(missingKeys) > 0 {
+		checks = append(checks, doctorCheck{level: doctorWarn, name: "keys", detail: "key file missing for " + strings.Join(missingKeys, ", "), fix: "edit the host and pick an existing key, or mount the drive it lives on"})
+	}
+	return checks, cfg.Hosts
+}
+
+func runDoctor(lookPath func(string) (string, error)) []doctorCheck {
+	checks, hosts := doctorConfigChecks()
+	checks = append(doctorToolChecks(hosts, lookPath), checks...)
+	return append(checks, doctorSecretCheck(lookPath), doctorAgentCheck())
+}
+
+// fprintDoctor prints checks and reports whether any failed.
+func fprintDoctor(w io.Writer, checks []doctorCheck) bool {
+	failed := false
+	for _, c := range checks {
+		fmt.Fprintf(w, "%s %-12s %s\n", c.level.icon(), c.name, c.detail)
+		if c.fix != "" && c.level != doctorOK {
+			fmt.Fprintf(w, "  %-12s fix: %s\n", "", c.fix)
+		}
+		failed = failed || c.level == doctorFail
+	}
+	return failed
+}
+
+func cliDoctor() {
+	exe, _ := os.Executable()
+	fmt.Printf("assho %s · %s/%s · %s\n\n", version, runtime.GOOS, runtime.GOARCH, exe)
+	if fprintDoctor(os.Stdout, runDoctor(exec.LookPath)) {
+		os.Exit(1)
+	}
+}
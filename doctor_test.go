@@ -0,0 +1,106 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeLookPath finds only the tools listed.
+func fakeLookPath(tools ...string) func(string) (string, error) {
+	return func(name string) (string, error) {
+		for _, t := range tools {
+			if t == name {
+				return "/usr/bin/" + name, nil
+			}
+		}
+		return "", errors.New("not found")
+	}
+}
+
+func findCheck(checks []doctorCheck, name string) doctorCheck {
+	for _, c := range checks {
+		if c.name == name {
+			return c
+		}
+	}
+	return doctorCheck{}
+}
+
+func TestDoctorToolChecksFailWithoutSSH(t *testing.T) {
+	checks := doctorToolChecks(nil, fakeLookPath())
+	if c := findCheck(checks, "ssh"); c.level != doctorFail || c.fix == "" {
+		t.Fatalf("missing ssh should fail with a fix, got %+v", c)
+	}
+	if c := findCheck(checks, "sshpass"); c.level != doctorInfo {
+		t.Fatalf("sshpass is optional without stored passwords, got %+v", c)
+	}
+}
+
+func TestDoctorToolChecksWarnForToolsHostsNeed(t *testing.T) {
+	hosts := []Host{
+		{Alias: "db", Password: "secret"},
+		{Alias: "win", Transport: transportPSRemoting},
+		{Alias: "box", Containers: []Host{{Alias: "nested", PasswordRef: "keychain"}}},
+	}
+	checks := doctorToolChecks(hosts, fakeLookPath("ssh", "ssh-keygen", "ssh-keyscan"))
+	if c := findCheck(checks, "sshpass"); c.level != doctorWarn || !strings.Contains(c.detail, "needed by 2") {
+		t.Fatalf("expected sshpass to be needed by 2 hosts, got %+v", c)
+	}
+	if c := findCheck(checks, "pwsh"); c.level != doctorWarn {
+		t.Fatalf("expected pwsh to be needed, got %+v", c)
+	}
+	if c := findCheck(checks, "ssh"); c.level != doctorOK || c.detail != "/usr/bin/ssh" {
+		t.Fatalf("expected ssh to be found, got %+v", c)
+	}
+}
+
+func TestDoctorConfigChecksFlagsPermissionsAndMissingKeys(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	path := getConfigPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	config := `{"hosts":[{"id":"1","alias":"db","hostname":"db.internal","identity_file":"/nonexistent/id_ed25519"}]}`
+	if err := os.WriteFile(path, []byte(config), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	checks, hosts := doctorConfigChecks()
+	if len(hosts) != 1 {
+		t.Fatalf("expected the saved host, got %d", len(hosts))
+	}
+	var perms, keys bool
+	for _, c := range checks {
+		perms = perms || (c.level == doctorWarn && c.fix == "chmod 600 "+path)
+		keys = keys || (c.name == "keys" && strings.Contains(c.detail, "db"))
+	}
+	if !perms || !keys {
+		t.Fatalf("expected permission and key warnings, got %+v", checks)
+	}
+}
+
+func TestFprintDoctorReportsFailures(t *testing.T) {
+	var out strings.Builder
+	failed := fprintDoctor(&out, []doctorCheck{
+		{name: "ssh", detail: "/usr/bin/ssh"},
+		{level: doctorWarn, name: "sshpass", detail: "not found", fix: "sudo apt install sshpass"},
+	})
+	if failed {
+		t.Fatal("warnings alone should not fail")
+	}
+	if !strings.Contains(out.String(), "fix: sudo apt install sshpass") {
+		t.Fatalf("expected the fix to be printed:\n%s", out.String())
+	}
+	if !fprintDoctor(&out, []doctorCheck{{level: doctorFail, name: "ssh"}}) {
+		t.Fatal("a failed check should be reported")
+	}
+}
+
+func TestErrMissingToolNamesFix(t *testing.T) {
+	err := errMissingTool("sshpass")
+	if !strings.Contains(err.Error(), "sshpass is not installed") || !strings.Contains(err.Error(), "install") {
+		t.Fatalf("unexpected error %q", err)
+	}
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"strings"
+
+	"github.com/atotto/clipboard"
+	"github.com/aymanbagabas/go-osc52/v2"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// --- Command Preview (dry run) ---
+
+// The command is built once when the preview opens, off the UI thread:
+// building it reads ssh_config, may probe the network, and may wait on the
+// keychain.
+type commandPreviewState struct {
+	hostID  string
+	ready   bool
+	host    Host
+	cmd     connectCommand
+	failure error
+	notice  string
+	err     bool
+}
+
+type commandPreviewMsg struct {
+	hostID string
+	host   Host
+	cmd    connectCommand
+	err    error
+}
+
+// copyToClipboard prefers the system clipboard and falls back to an OSC 52
+// escape so copying still works inside a remote or headless terminal.
+var copyToClipboard = func(text string) error {
+	if err := clipboard.WriteAll(text); err == nil {
+		return nil
+	}
+	_, err := osc52.New(text).WriteTo(os.Stderr)
+	return err
+}
+
+func (m model) openCommandPreview(h Host) (tea.Model, tea.Cmd) {
+	m.clearListDeleteConfirm()
+	m.preview = commandPreviewState{hostID: h.ID}
+	m.state = stateCommandPreview
+	return m, previewCommandCmd(h, m.inventory())
+}
+
+func previewCommandCmd(h Host, inv hostInventory) tea.Cmd {
+	return func() tea.Msg {
+		cmd, err := buildConnectCommand(h, inv, true)
+		return commandPreviewMsg{hostID: h.ID, host: h, cmd: cmd, err: err}
+	}
+}
+
+// finishCommandPreview stores the built command if its preview is still
+// open.
+func (m model) finishCommandPreview(msg commandPreviewMsg) (tea.Model, tea.Cmd) {
+	if m.state != stateCommandPreview || m.preview.hostID != msg.hostID {
+		return m, nil
+	}
+	m.preview.ready = true
+	m.preview.host, m.preview.cmd, m.preview.failure = msg.host, msg.cmd, msg.err
+	return m, nil
+}
+
+func (m model) updateCommandPreview(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	h, cmd, err := m.preview.host, m.preview.cmd, m.preview.failure
+	if !m.preview.ready {
+		err = errors.New("command not built yet")
+	}
+	switch msg.String() {
+	case "ctrl+c":
+		m.quitting = true
+		return m, tea.Quit
+	case "s", "esc", "q":
+		m.state = stateList
+		return m, nil
+	case "enter":
+		if err == nil {
+			return m.connectToHost(h)
+		}
+	case "y", "c":
+		if err != nil {
+			return m, nil
+		}
+		if copyErr := copyToClipboard(cmd.String()); copyErr != nil {
+			m.preview.notice, m.preview.err = "Copy failed: "+copyErr.Error(), true
+		} else {
+			m.preview.notice, m.preview.err = "Copied to clipboard", false
+		}
+	}
+	return m, nil
+}
+
+func (m model) renderCommandPreviewView() string {
+	width, height := normalizedSize(m.width, m.height)
+	inner := max(min(width-6, 100)-6, 24)
+	if !m.preview.ready {
+		return centeredWorkspace(formHintStyle.Render("Building the ssh command…")+"\n\n"+helpEntry("esc", "back"), width, height)
+	}
+	h, cmd, err := m.preview.host, m.preview.cmd, m.preview.failure
+	if err != nil {
+		return centeredWorkspace(testFailStyle.Render("✘ "+err.Error())+"\n\n"+helpEntry("esc", "back"), width, height)
+	}
+
+	var b strings.Builder
+	b.WriteString(lipgloss.NewStyle().Bold(true).Foreground(colorText).Render("COMMAND PREVIEW") + "\n")
+	b.WriteString(formHintStyle.Render(ansi.Truncate("What assho will run to connect to "+h.Alias, inner, "…")) + "\n\n")
+	b.WriteString(lipgloss.NewStyle().Foreground(colorPrimary).Render(ansi.Wrap(cmd.String(), inner, " ")) + "\n")
+	if len(cmd.extraEnv) > 0 {
+		b.WriteString("\n" + formHintStyle.Render(ansi.Wrap("The stored password is passed to sshpass through the environment and is redacted here.", inner, " ")) + "\n")
+	}
+	if cmd.sshHost.NoLocale {
+		b.WriteString("\n" + formHintStyle.Render(ansi.Wrap("LANG and LC_* are left out of ssh's environment, so the local locale is not sent.", inner, " ")) + "\n")
+	}
+	if launcher := terminalLauncher(h); launcher != nil {
+		b.WriteString("\n" + formHintStyle.Render(ansi.Wrap("Opens in a new window: "+strings.Join(launcher, " ")+" <command>", inner, " ")) + "\n")
+	}
+	if cmd.missingSSHPass {
+		b.WriteString("\n" + testFailStyle.Render(ansi.Wrap("A password is stored but sshpass is not installed, so ssh will prompt for it. Install it with: "+installHint("sshpass"), inner, " ")) + "\n")
+	}
+	if m.preview.notice != "" {
+		style := testSuccessStyle
+		if m.preview.err {
+			style = testFailStyle
+		}
+		b.WriteString("\n" + style.Render(ansi.Truncate(m.preview.notice, inner, "…")) + "\n")
+	}
+	b.WriteString("\n" + helpEntry("y", "copy") + "  " + helpEntry("enter", "connect") + "  " + helpEntry("esc", "back"))
+	return centeredWorkspace(b.String(), width, height)
+}
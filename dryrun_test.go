@@ -0,0 +1,102 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestConnectCommandStringRedactsPassword(t *testing.T) {
+	bin := t.TempDir()
+	if err := os.WriteFile(filepath.Join(bin, "sshpass"), []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin)
+	h := Host{ID: "h1", Alias: "web", Hostname: "10.0.0.1", User: "deploy", Port: "2222", Password: "hunter2", ProxyJump: "bastion", LocalForward: "8080:localhost:80"}
+
+	cmd, err := buildConnectCommand(h, hostInventory{hosts: []Host{h}}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	line := cmd.String()
+	if strings.Contains(line, "hunter2") {
+		t.Fatalf("password leaked into preview: %s", line)
+	}
+	for _, want := range []string{"SSHPASS=<redacted>", "-e ssh", "-J bastion", "-L 8080:localhost:80", "-p 2222", "-l deploy", "10.0.0.1"} {
+		if !strings.Contains(line, want) {
+			t.Errorf("preview %q missing %q", line, want)
+		}
+	}
+}
+
+func TestBuildConnectCommandUsesContainerParent(t *testing.T) {
+	parent := Host{ID: "p1", Alias: "docker-host", Hostname: "10.0.0.5", User: "root"}
+	container := Host{ID: "c1", Alias: "app", IsContainer: true, ParentID: "p1"}
+
+	cmd, err := buildConnectCommand(container, hostInventory{hosts: []Host{parent, container}}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cmd.binary != "ssh" || cmd.sshHost.ID != "p1" {
+		t.Fatalf("expected plain ssh to parent, got %+v", cmd)
+	}
+	if !strings.Contains(cmd.String(), "'docker exec -it app sh -c '\\''command -v bash") {
+		t.Fatalf("remote command not shell-quoted: %s", cmd.String())
+	}
+
+	if _, err := buildConnectCommand(Host{ID: "c2", Alias: "orphan", IsContainer: true}, hostInventory{}, false); err == nil {
+		t.Fatal("expected error for container without parent")
+	}
+}
+
+func TestCommandPreviewCopiesCommand(t *testing.T) {
+	var copied string
+	original := copyToClipboard
+	copyToClipboard = func(text string) error { copied = text; return nil }
+	t.Cleanup(func() { copyToClipboard = original })
+
+	h := Host{ID: "h1", Alias: "web", Hostname: "10.0.0.1", User: "root"}
+	m := model{rawHosts: []Host{h}, width: 80, height: 24}
+	m.list = newTestListModel(nil, m.rawHosts)
+
+	updated, build := m.updateList(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s")})
+	m = updated.(model)
+	if m.state != stateCommandPreview || build == nil {
+		t.Fatalf("expected command preview state building the command, got %v", m.state)
+	}
+	if !strings.Contains(m.renderCommandPreviewView(), "Building") {
+		t.Fatal("expected the preview to wait for the command")
+	}
+	updated, _ = m.Update(build())
+	m = updated.(model)
+	updated, _ = m.updateCommandPreview(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	m = updated.(model)
+	if !strings.HasPrefix(copied, "ssh ") || !strings.HasSuffix(copied, "10.0.0.1") {
+		t.Fatalf("unexpected copied command %q", copied)
+	}
+	if !strings.Contains(m.renderCommandPreviewView(), "Copied") {
+		t.Fatal("expected copy confirmation in preview")
+	}
+}
+
+func TestCommandPreviewViewFitsTerminal(t *testing.T) {
+	h := Host{ID: "h1", Alias: "web", Hostname: "very-long-hostname.internal.example.com", User: "deploy", ProxyJump: "jump1.example.com,jump2.example.com", LocalForward: "8080:localhost:80", IdentityFile: "~/.ssh/id_ed25519_work"}
+	for _, size := range []struct{ width, height int }{{40, 16}, {80, 24}, {140, 40}} {
+		m := model{width: size.width, height: size.height, rawHosts: []Host{h}, state: stateCommandPreview, preview: commandPreviewState{hostID: "h1"}}
+		updated, _ := m.finishCommandPreview(previewCommandCmd(h, m.inventory())().(commandPreviewMsg))
+		m = updated.(model)
+		lines := strings.Split(m.renderCommandPreviewView(), "\n")
+		if len(lines) > size.height {
+			t.Fatalf("%dx%d: got %d lines", size.width, size.height, len(lines))
+		}
+		for i, line := range lines {
+			if ansi.StringWidth(line) > size.width {
+				t.Fatalf("%dx%d line %d has width %d", size.width, size.height, i, ansi.StringWidth(line))
+			}
+		}
+	}
+}
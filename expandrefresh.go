@@ -0,0 +1,40 @@
+package main
+
+import (
+	"context"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// --- Refresh On Expand ---
+
+// Expanding a host shows the containers and VMs from its last scan straight
+// away and rescans it in the background, so the [n containers] count does
+// not go stale until the next manual scan. While the rescan runs a spinner
+// turns on that host's row alone; the "Scanning…" banner and the task list
+// stay for scans asked for with Ctrl+D.
+
+// expandHost opens the host at idx and refreshes its containers.
+func (m model) expandHost(idx int) (model, tea.Cmd) {
+	m.rawHosts[idx].Expanded = true
+	m.refreshList()
+	h := m.rawHosts[idx]
+	if m.expandRefresh[h.ID] || m.tasks.running(taskScan, h.ID) != nil {
+		return m, nil
+	}
+	if m.expandRefresh == nil {
+		m.expandRefresh = map[string]bool{}
+	}
+	m.expandRefresh[h.ID] = true
+	m.refreshDelegate()
+	return m, scanDockerContainers(context.Background(), h, m.inventory(), true)
+}
+
+// finishExpandRefresh stops the row spinner of the host a background scan
+// was for, see rowprogress.go.
+func (m *model) finishExpandRefresh(msg scanDockerMsg) {
+	if msg.background && m.expandRefresh[msg.hostID] {
+		delete(m.expandRefresh, msg.hostID)
+		m.refreshDelegate()
+	}
+}
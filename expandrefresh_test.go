@@ -0,0 +1,49 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestExpandShowsCachedContainersAndRefreshes(t *testing.T) {
+	hosts := []Host{{ID: "h1", Alias: "docker", Hostname: "10.0.0.1", User: "root", Containers: []Host{
+		{ID: "c1", Alias: "web", IsContainer: true},
+	}}}
+	m := model{state: stateList, rawHosts: hosts, list: newTestListModel(nil, hosts), historyList: newTestHistoryListModel()}
+	m.spinner.Spinner.Frames = []string{"*"}
+
+	result, cmd := m.updateList(tea.KeyMsg{Type: tea.KeyRight})
+	got := result.(model)
+	if cmd == nil || !got.expandRefresh["h1"] {
+		t.Fatal("expected expanding to start a background refresh")
+	}
+	if got.tasks.runningCount(taskScan) != 0 || strings.Contains(got.View(), "Scanning") {
+		t.Fatal("expected no scan task or banner for the refresh")
+	}
+	out := ansi.Strip(got.list.View())
+	if !strings.Contains(out, "web") || !strings.Contains(out, "[1 containers] *") {
+		t.Fatalf("expected the cached containers and a row spinner\n%s", out)
+	}
+
+	result, _ = got.Update(scanDockerMsg{hostID: "h1", background: true, containers: []Host{
+		{ID: "c1", Alias: "web", IsContainer: true},
+		{ID: "c2", Alias: "db", IsContainer: true},
+	}})
+	got = result.(model)
+	out = ansi.Strip(got.list.View())
+	if len(got.expandRefresh) != 0 || strings.Contains(out, "*") || !strings.Contains(out, "[2 containers]") {
+		t.Fatalf("expected the refreshed count and no spinner\n%s", out)
+	}
+}
+
+func TestBackgroundScanKeepsHostCollapsed(t *testing.T) {
+	hosts := []Host{{ID: "h1", Alias: "docker", Hostname: "10.0.0.1"}}
+	m := model{state: stateList, rawHosts: hosts, list: newTestListModel(nil, hosts), historyList: newTestHistoryListModel()}
+	result, _ := m.Update(scanDockerMsg{hostID: "h1", background: true, containers: []Host{{ID: "c1", Alias: "web", IsContainer: true}}})
+	if got := result.(model); got.rawHosts[0].Expanded || len(got.rawHosts[0].Containers) != 1 {
+		t.Fatal("expected the containers stored without expanding the host")
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// --- Expiring Hosts ---
+
+// Temporary hosts carry an ExpiresAt date. A host stays valid through the
+// whole of that day and is flagged as expired from the following midnight.
+
+const expiryLayout = "2006-01-02"
+
+// parseExpiry accepts a YYYY-MM-DD date or a relative "Nd" day count and
+// returns the normalized date string stored on the host.
+func parseExpiry(value string, now time.Time) (string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", nil
+	}
+	if days, ok := strings.CutSuffix(strings.ToLower(value), "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return "", fmt.Errorf("expiry must be a date (YYYY-MM-DD) or a day count like 7d")
+		}
+		return now.AddDate(0, 0, n).Format(expiryLayout), nil
+	}
+	t, err := time.ParseInLocation(expiryLayout, value, time.Local)
+	if err != nil {
+		return "", fmt.Errorf("expiry must be a date (YYYY-MM-DD) or a day count like 7d")
+	}
+	return t.Format(expiryLayout), nil
+}
+
+// expiryDeadline returns the first instant at which the host counts as
+// expired. Unparsable dates are treated as no expiry.
+func (h Host) expiryDeadline() (time.Time, bool) {
+	if h.ExpiresAt == "" {
+		return time.Time{}, false
+	}
+	t, err := time.ParseInLocation(expiryLayout, h.ExpiresAt, time.Local)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t.AddDate(0, 0, 1), true
+}
+
+// Expired reports whether the host's expiry date has passed.
+func (h Host) Expired(now time.Time) bool {
+	deadline, ok := h.expiryDeadline()
+	return ok && !now.Before(deadline)
+}
+
+// expiryLabel is the short expiry note shown in the list and detail pane.
+func expiryLabel(h Host, now time.Time) string {
+	deadline, ok := h.expiryDeadline()
+	if !ok {
+		return ""
+	}
+	if !now.Before(deadline) {
+		return "expired " + h.ExpiresAt
+	}
+	switch days := int(deadline.Sub(now).Hours() / 24); days {
+	case 0:
+		return "expires today"
+	case 1:
+		return "expires tomorrow"
+	default:
+		return fmt.Sprintf("expires in %dd", days)
+	}
+}
+
+// autoArchiveExpired reports whether expired hosts are archived on startup.
+func autoArchiveExpired() bool {
+	return envBool("ASSHO_ARCHIVE_EXPIRED", false)
+}
+
+// archiveExpiredHosts archives every expired host in place and returns how
+// many were changed.
+func archiveExpiredHosts(hosts []Host, now time.Time) int {
+	var n int
+	for i := range hosts {
+		if !hosts[i].Archived && hosts[i].Expired(now) {
+			hosts[i].Archived = true
+			n++
+		}
+	}
+	return n
+}
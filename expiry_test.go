@@ -0,0 +1,89 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseExpiry(t *testing.T) {
+	now := time.Date(2026, 3, 30, 15, 0, 0, 0, time.Local)
+	cases := []struct {
+		in, want string
+		wantErr  bool
+	}{
+		{in: "", want: ""},
+		{in: " 2026-04-02 ", want: "2026-04-02"},
+		{in: "7d", want: "2026-04-06"},
+		{in: "0D", want: "2026-03-30"},
+		{in: "-1d", wantErr: true},
+		{in: "tomorrow", wantErr: true},
+		{in: "2026-13-01", wantErr: true},
+	}
+	for _, tc := range cases {
+		got, err := parseExpiry(tc.in, now)
+		if (err != nil) != tc.wantErr || got != tc.want {
+			t.Errorf("parseExpiry(%q) = %q, %v; want %q, err=%v", tc.in, got, err, tc.want, tc.wantErr)
+		}
+	}
+}
+
+func TestHostExpiredAfterExpiryDay(t *testing.T) {
+	h := Host{ExpiresAt: "2026-03-30"}
+	lastDay := time.Date(2026, 3, 30, 23, 59, 0, 0, time.Local)
+	if h.Expired(lastDay) {
+		t.Fatal("expected host to stay valid through its expiry date")
+	}
+	if got := expiryLabel(h, lastDay); got != "expires today" {
+		t.Fatalf("unexpected label %q", got)
+	}
+	if got := expiryLabel(h, lastDay.AddDate(0, 0, -3)); got != "expires in 3d" {
+		t.Fatalf("unexpected label %q", got)
+	}
+	nextDay := time.Date(2026, 3, 31, 0, 0, 0, 0, time.Local)
+	if !h.Expired(nextDay) || expiryLabel(h, nextDay) != "expired 2026-03-30" {
+		t.Fatalf("expected host expired the next day, label %q", expiryLabel(h, nextDay))
+	}
+	if (Host{}).Expired(nextDay) || (Host{ExpiresAt: "soon"}).Expired(nextDay) {
+		t.Fatal("expected hosts without a valid expiry never to expire")
+	}
+}
+
+func TestArchiveExpiredHosts(t *testing.T) {
+	now := time.Date(2026, 3, 30, 12, 0, 0, 0, time.Local)
+	hosts := []Host{
+		{ID: "a", ExpiresAt: "2026-03-29"},
+		{ID: "b", ExpiresAt: "2026-03-30"},
+		{ID: "c"},
+		{ID: "d", ExpiresAt: "2026-01-01", Archived: true},
+	}
+	if n := archiveExpiredHosts(hosts, now); n != 1 {
+		t.Fatalf("expected one host archived, got %d", n)
+	}
+	if !hosts[0].Archived || hosts[1].Archived || hosts[2].Archived {
+		t.Fatalf("unexpected archive flags: %+v", hosts)
+	}
+}
+
+func TestExpirySavedFromForm(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("ASSHO_STORE_PASSWORD", "0")
+
+	m := model{form: formState{inputs: newFormInputs()}, historyList: newTestHistoryListModel()}
+	m.list = newTestListModel(nil, nil)
+	m.form.inputs[fieldAlias].SetValue("demo")
+	m.form.inputs[fieldHostname].SetValue("10.0.0.9")
+	m.form.inputs[fieldExpires].SetValue("next week")
+	m.buildGroupOptions("")
+	if err := m.saveFromForm(); err == nil {
+		t.Fatal("expected invalid expiry to be rejected")
+	}
+
+	m.form.inputs[fieldExpires].SetValue("2d")
+	if err := m.saveFromForm(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := time.Now().AddDate(0, 0, 2).Format(expiryLayout)
+	if got := m.rawHosts[0].ExpiresAt; got != want {
+		t.Fatalf("expected relative expiry stored as %s, got %q", want, got)
+	}
+}
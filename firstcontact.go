@@ -0,0 +1,520 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// --- First Contact ---
+
+// f on a host (offered right after adding one) walks through the first
+// connection. The server's host key fingerprints are fetched with ssh-keyscan
+// so they can be compared before the usual trust review. Each auth method the
+// server offers is then tried in order, and failing key auth can be fixed
+// with ssh-copy-id from the same screen. The outcome is kept on the host and
+// shown in its detail pane.
+
+// FirstContact records the last first-contact check of a host.
+type FirstContact struct {
+	CheckedAt    int64    `json:"checked_at"`
+	Fingerprints []string `json:"fingerprints,omitempty"`
+	Offered      []string `json:"offered,omitempty"` // auth methods the server offered
+	Working      []string `json:"working,omitempty"` // auth methods that logged in
+	KeyInstalled bool     `json:"key_installed,omitempty"`
+}
+
+// firstContactMethods is the order auth methods are tried in.
+var firstContactMethods = []string{"publickey", "password", "keyboard-interactive"}
+
+var permissionDeniedPattern = regexp.MustCompile(`Permission denied \(([^)]*)\)`)
+
+type firstContactPhase int
+
+const (
+	firstContactScanning firstContactPhase = iota
+	firstContactFingerprint
+	firstContactTesting
+	firstContactResults
+	firstContactInstalling
+)
+
+type authProbe struct {
+	method string
+	status string // ok, failed, skipped, or not offered
+	detail string
+}
+
+type firstContactState struct {
+	hostID       string
+	returnTo     state
+	phase        firstContactPhase
+	fingerprints []string
+	known        bool
+	scanErr      string
+	offered      []string
+	probes       []authProbe
+	keyInstalled bool
+	errorText    string
+}
+
+type firstContactScanMsg struct {
+	hostID       string
+	fingerprints []string
+	known        bool
+	err          error
+}
+
+type firstContactAuthMsg struct {
+	hostID  string
+	offered []string
+	probes  []authProbe
+	err     error
+}
+
+type firstContactCopyIDMsg struct {
+	hostID string
+	err    error
+}
+
+// parseKeygenFingerprints turns `ssh-keygen -lf` output such as
+// "256 SHA256:abc host (ED25519)" into "SHA256:abc (ED25519)".
+func parseKeygenFingerprints(out string) []string {
+	var fingerprints []string
+	seen := map[string]bool{}
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || !strings.Contains(fields[1], ":") {
+			continue
+		}
+		fp := fields[1]
+		if last := fields[len(fields)-1]; len(fields) > 2 && strings.HasPrefix(last, "(") {
+			fp += " " + last
+		}
+		if !seen[fp] {
+			seen[fp] = true
+			fingerprints = append(fingerprints, fp)
+		}
+	}
+	return fingerprints
+}
+
+// parseOfferedAuthMethods reads the method list from OpenSSH's
+// "Permission denied (publickey,password)." message.
+func parseOfferedAuthMethods(out string) ([]string, bool) {
+	match := permissionDeniedPattern.FindStringSubmatch(out)
+	if match == nil {
+		return nil, false
+	}
+	var methods []string
+	for _, method := range strings.Split(match[1], ",") {
+		if method = strings.TrimSpace(method); method != "" {
+			methods = append(methods, method)
+		}
+	}
+	return methods, true
+}
+
+func scanHostKeys(h Host) ([]string, error) {
+	keys, err := keyscanHost(h)
+	if err != nil {
+		return nil, err
+	}
+	return fingerprintKeys(keys)
+}
+
+// keyscanHost returns h's host keys as ssh-keyscan prints them, one
+// known_hosts line per key.
+func keyscanHost(h Host) ([]byte, error) {
+	if h.behindProxy() {
+		return nil, errors.New("ssh-keyscan cannot reach hosts behind a ProxyJump or ProxyCommand; compare the fingerprint OpenSSH shows")
+	}
+	if !commandExists("ssh-keyscan") || !commandExists("ssh-keygen") {
+		return nil, errors.New("ssh-keyscan and ssh-keygen are required")
+	}
+	args := []string{"-T", "5"}
+	if h.Port != "" && h.Port != "22" {
+		args = append(args, "-p", h.Port)
+	}
+	args = append(args, bareHostname(h.Hostname))
+	keys, _ := exec.Command("ssh-keyscan", args...).Output()
+	if len(bytes.TrimSpace(keys)) == 0 {
+		return nil, errors.New("no host keys returned; is sshd reachable?")
+	}
+	return keys, nil
+}
+
+// fingerprintKeys fingerprints known_hosts lines with ssh-keygen.
+func fingerprintKeys(keys []byte) ([]string, error) {
+	cmd := exec.Command("ssh-keygen", "-lf", "-")
+	cmd.Stdin = bytes.NewReader(keys)
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("fingerprint failed: %w", err)
+	}
+	return parseKeygenFingerprints(string(out)), nil
+}
+
+func scanHostKeysCmd(h Host, inv hostInventory) tea.Cmd {
+	return func() tea.Msg {
+		h = resolveEndpoint(h, inv)
+		msg := firstContactScanMsg{hostID: h.ID}
+		msg.known, _ = hostKeyKnown(h)
+		msg.fingerprints, msg.err = scanHostKeys(h)
+		return msg
+	}
+}
+
+// firstContactSSHArgs builds a non-interactive login that only allows method.
+// "none" is used to learn which methods the server offers.
+func firstContactSSHArgs(h Host, method string) []string {
+	args := []string{
+		"-o", connectTimeoutOption(h.connectTimeout()),
+		"-o", "StrictHostKeyChecking=yes",
+		"-o", "PreferredAuthentications=" + method,
+	}
+	args = append(args, skipSecurityKeyArgs(h)...)
+	if method == "password" {
+		// sshpass answers the prompt, so BatchMode must stay off.
+		args = append(args, "-o", "NumberOfPasswordPrompts=1", "-o", "PubkeyAuthentication=no")
+	} else {
+		args = append(args, "-o", "BatchMode=yes")
+	}
+	if h.User != "" {
+		args = append(args, "-l", h.User)
+	}
+	if h.Port != "" {
+		args = append(args, "-p", h.Port)
+	}
+	if method == "publickey" {
+		if h.IdentityFile != "" {
+			args = append(args, "-i", expandPath(h.IdentityFile))
+		}
+		args = append(args, identityAgentArgs(h)...)
+	}
+	args = append(args, proxyArgs(h)...)
+	return append(args, bareHostname(h.Hostname), "exit")
+}
+
+func runFirstContactSSH(h Host, method string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), max(10*time.Second, h.commandTimeout()))
+	defer cancel()
+	args := firstContactSSHArgs(h, method)
+	cmd := exec.CommandContext(ctx, "ssh", args...)
+	if method == "password" {
+		cmd = exec.CommandContext(ctx, "sshpass", append([]string{"-e", "ssh"}, args...)...)
+		cmd.Env = append(os.Environ(), "SSHPASS="+h.Password)
+	}
+	out, err := cmd.CombinedOutput()
+	if ctx.Err() == context.DeadlineExceeded {
+		return string(out), errors.New("timed out")
+	}
+	return string(out), err
+}
+
+// probeAuthMethods asks the server which methods it offers, then tries each
+// of firstContactMethods that can run without a terminal.
+func probeAuthMethods(h Host) ([]string, []authProbe, error) {
+	h = withPassword(h)
+	out, err := runFirstContactSSH(h, "none")
+	if err == nil {
+		return []string{"none"}, nil, nil
+	}
+	offered, ok := parseOfferedAuthMethods(out)
+	if !ok {
+		if msg := strings.TrimSpace(out); msg != "" {
+			err = errors.New(msg)
+		}
+		status, _ := formatTestStatus(err)
+		return nil, nil, errors.New(status)
+	}
+	var probes []authProbe
+	for _, method := range firstContactMethods {
+		probe := authProbe{method: method}
+		switch {
+		case !slices.Contains(offered, method):
+			probe.status = "not offered"
+		case method == "keyboard-interactive":
+			probe.status, probe.detail = "skipped", "needs a terminal; used on connect"
+		case method == "password" && h.Password == "":
+			probe.status, probe.detail = "skipped", "no stored password"
+		case method == "password" && !commandExists("sshpass"):
+			probe.status, probe.detail = "skipped", "sshpass not installed"
+		default:
+			if out, err := runFirstContactSSH(h, method); err != nil {
+				probe.status = "failed"
+				probe.detail = securityKeySkipped(h, strings.TrimSpace(out))
+				if probe.detail == "" {
+					probe.detail = err.Error()
+				}
+			} else {
+				probe.status = "ok"
+			}
+		}
+		probes = append(probes, probe)
+	}
+	return offered, probes, nil
+}
+
+func firstContactAuthTrusted(h Host) tea.Cmd {
+	return func() tea.Msg {
+		offered, probes, err := probeAuthMethods(h)
+		recordAudit("first-contact", h.Alias, h, err)
+		return firstContactAuthMsg{hostID: h.ID, offered: offered, probes: probes, err: err}
+	}
+}
+
+func (m model) openFirstContact(h Host) (tea.Model, tea.Cmd) {
+	m.clearListDeleteConfirm()
+	m.firstContact = firstContactState{hostID: h.ID, returnTo: m.state, phase: firstContactScanning}
+	m.state = stateFirstContact
+	return m, scanHostKeysCmd(h, m.inventory())
+}
+
+func (m model) startFirstContactAuth() (tea.Model, tea.Cmd) {
+	idx := findHostIndexByID(m.rawHosts, m.firstContact.hostID)
+	if idx == -1 {
+		m.firstContact.errorText = "host no longer exists"
+		return m, nil
+	}
+	h := m.rawHosts[idx]
+	m.firstContact.phase = firstContactTesting
+	m.firstContact.errorText = ""
+	return m, checkHostTrustCmd(pendingSSHAction{kind: sshActionFirstContact, host: h, trustHost: h, inv: m.inventory()})
+}
+
+func (m model) updateFirstContact(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	fc := &m.firstContact
+	switch msg.String() {
+	case "ctrl+c":
+		m.quitting = true
+		return m, tea.Quit
+	case "esc", "q":
+		if fc.phase == firstContactTesting || fc.phase == firstContactInstalling {
+			return m, nil
+		}
+		m.state = fc.returnTo
+		return m, nil
+	case "enter":
+		switch fc.phase {
+		case firstContactFingerprint:
+			return m.startFirstContactAuth()
+		case firstContactResults:
+			m.state = fc.returnTo
+		}
+	case "r":
+		if fc.phase == firstContactResults {
+			return m.startFirstContactAuth()
+		}
+	case "p":
+		if fc.phase == firstContactFingerprint || fc.phase == firstContactResults {
+			return m.pinFirstContactKeys(false)
+		}
+	case "u":
+		if fc.phase == firstContactFingerprint || fc.phase == firstContactResults {
+			return m.pinFirstContactKeys(true)
+		}
+	case "k":
+		if fc.phase != firstContactResults || !fc.canInstallKey() {
+			return m, nil
+		}
+		idx := findHostIndexByID(m.rawHosts, fc.hostID)
+		if idx == -1 {
+			fc.errorText = "host no longer exists"
+			return m, nil
+		}
+		h := m.rawHosts[idx]
+		publicKey := ""
+		if strings.TrimSpace(h.IdentityFile) != "" {
+			path, err := publicKeyForIdentity(h.IdentityFile)
+			if err != nil {
+				fc.errorText = err.Error()
+				return m, nil
+			}
+			publicKey = path
+		}
+		cmd, err := buildCopyIDCommand(resolveEndpoint(h, m.inventory()), publicKey)
+		if err != nil {
+			fc.errorText = err.Error()
+			return m, nil
+		}
+		fc.phase = firstContactInstalling
+		fc.errorText = ""
+		hostID := h.ID
+		return m, tea.ExecProcess(cmd, func(err error) tea.Msg { return firstContactCopyIDMsg{hostID: hostID, err: err} })
+	}
+	return m, nil
+}
+
+// canInstallKey reports whether the server takes keys but ours was refused.
+func (s firstContactState) canInstallKey() bool {
+	for _, p := range s.probes {
+		if p.method == "publickey" {
+			return p.status == "failed"
+		}
+	}
+	return false
+}
+
+func (m model) finishFirstContactScan(msg firstContactScanMsg) (tea.Model, tea.Cmd) {
+	if m.state != stateFirstContact || msg.hostID != m.firstContact.hostID || m.firstContact.phase != firstContactScanning {
+		return m, nil
+	}
+	m.firstContact.phase = firstContactFingerprint
+	m.firstContact.fingerprints = msg.fingerprints
+	m.firstContact.known = msg.known
+	m.firstContact.scanErr = ""
+	if msg.err != nil {
+		m.firstContact.scanErr = msg.err.Error()
+	}
+	return m, nil
+}
+
+func (m model) finishFirstContactAuth(msg firstContactAuthMsg) (tea.Model, tea.Cmd) {
+	if m.state != stateFirstContact || msg.hostID != m.firstContact.hostID {
+		return m, nil
+	}
+	fc := &m.firstContact
+	fc.phase = firstContactResults
+	if msg.err != nil {
+		fc.errorText = msg.err.Error()
+		fc.offered, fc.probes = nil, nil
+		return m, nil
+	}
+	fc.errorText = ""
+	fc.offered, fc.probes = msg.offered, msg.probes
+	idx := findHostIndexByID(m.rawHosts, fc.hostID)
+	if idx == -1 {
+		return m, nil
+	}
+	record := &FirstContact{
+		CheckedAt:    time.Now().Unix(),
+		Fingerprints: fc.fingerprints,
+		Offered:      fc.offered,
+		KeyInstalled: fc.keyInstalled,
+	}
+	if prev := m.rawHosts[idx].FirstContact; prev != nil && prev.KeyInstalled {
+		record.KeyInstalled = true
+	}
+	for _, p := range fc.probes {
+		if p.status == "ok" {
+			record.Working = append(record.Working, p.method)
+		}
+	}
+	if len(fc.offered) == 1 && fc.offered[0] == "none" {
+		record.Working = []string{"none"}
+	}
+	snapshot := m.snapshot()
+	m.rawHosts[idx].FirstContact = record
+	if err := m.save(); err != nil {
+		m.restoreSnapshot(snapshot)
+		fc.errorText = fmt.Sprintf("failed to save results: %v", err)
+	}
+	return m, nil
+}
+
+func (m model) finishFirstContactCopyID(msg firstContactCopyIDMsg) (tea.Model, tea.Cmd) {
+	if m.state != stateFirstContact || msg.hostID != m.firstContact.hostID {
+		return m, nil
+	}
+	if msg.err != nil {
+		m.firstContact.phase = firstContactResults
+		m.firstContact.errorText = "ssh-copy-id failed: " + msg.err.Error()
+		return m, nil
+	}
+	m.firstContact.keyInstalled = true
+	return m.startFirstContactAuth()
+}
+
+func (m model) renderFirstContactView() string {
+	width, height := normalizedSize(m.width, m.height)
+	inner := max(min(width-6, 100)-6, 24)
+	fc := m.firstContact
+	idx := findHostIndexByID(m.rawHosts, fc.hostID)
+	if idx == -1 {
+		return centeredWorkspace(testFailStyle.Render("✘ Host no longer exists")+"\n\n"+helpEntry("esc", "back"), width, height)
+	}
+	h := m.rawHosts[idx]
+	var b strings.Builder
+	b.WriteString(lipgloss.NewStyle().Bold(true).Foreground(colorText).Render(ansi.Truncate("FIRST CONTACT · "+h.Alias, inner, "…")) + "\n")
+	b.WriteString(formHintStyle.Render(ansi.Truncate(sshTarget(h), inner, "…")) + "\n\n")
+
+	b.WriteString(formSectionStyle.Render("Host key") + "\n")
+	switch {
+	case fc.phase == firstContactScanning:
+		b.WriteString(m.spinner.View() + " Fetching host keys…\n")
+	case fc.scanErr != "":
+		b.WriteString(testPendingStyle.Render(ansi.Truncate("! "+fc.scanErr, inner, "…")) + "\n")
+	default:
+		for _, fp := range fc.fingerprints {
+			b.WriteString(ansi.Truncate("  "+fp, inner, "…") + "\n")
+		}
+	}
+	if line := pinStatusLine(h, fc.fingerprints); line != "" && fc.phase != firstContactScanning {
+		b.WriteString(line + "\n")
+	}
+	if fc.phase != firstContactScanning {
+		if fc.known {
+			b.WriteString(testSuccessStyle.Render("✔ already in known_hosts") + "\n")
+		} else {
+			b.WriteString(formHintStyle.Render(ansi.Truncate("Compare with the server console; OpenSSH asks to trust it next.", inner, "…")) + "\n")
+		}
+	}
+
+	if fc.phase >= firstContactTesting {
+		b.WriteString("\n" + formSectionStyle.Render("Authentication") + "\n")
+		switch fc.phase {
+		case firstContactTesting:
+			b.WriteString(m.spinner.View() + " Trying auth methods…\n")
+		case firstContactInstalling:
+			b.WriteString(m.spinner.View() + " Running ssh-copy-id; complete the prompt if one appears…\n")
+		default:
+			if len(fc.offered) == 1 && fc.offered[0] == "none" {
+				b.WriteString(testSuccessStyle.Render("✔ server accepts logins without authentication") + "\n")
+			}
+			for _, p := range fc.probes {
+				mark, style := "·", formHintStyle
+				switch p.status {
+				case "ok":
+					mark, style = "✔", testSuccessStyle
+				case "failed":
+					mark, style = "✘", testFailStyle
+				}
+				line := fmt.Sprintf("%s %-21s %s", mark, p.method, p.status)
+				if p.detail != "" {
+					line += " · " + p.detail
+				}
+				b.WriteString(style.Render(ansi.Truncate(line, inner, "…")) + "\n")
+			}
+		}
+	}
+	if fc.errorText != "" {
+		b.WriteString("\n" + testFailStyle.Render(ansi.Truncate("✘ "+fc.errorText, inner, "…")) + "\n")
+	}
+
+	b.WriteString("\n")
+	switch fc.phase {
+	case firstContactFingerprint:
+		b.WriteString(helpEntry("enter", "trust & test auth") + pinHelp(h, fc.fingerprints) + "  " + helpEntry("esc", "skip"))
+	case firstContactResults:
+		help := helpEntry("enter", "done") + "  " + helpEntry("r", "retry")
+		if fc.canInstallKey() {
+			help += "  " + helpEntry("k", "ssh-copy-id")
+		}
+		b.WriteString(help + pinHelp(h, fc.fingerprints))
+	case firstContactScanning:
+		b.WriteString(helpEntry("esc", "skip"))
+	}
+	return centeredWorkspace(b.String(), width, height)
+}
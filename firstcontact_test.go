@@ -0,0 +1,122 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestParseKeygenFingerprints(t *testing.T) {
+	out := "256 SHA256:abc web.example.com (ED25519)\n3072 SHA256:def web.example.com (RSA)\n256 SHA256:abc web.example.com (ED25519)\n# comment\n"
+	got := strings.Join(parseKeygenFingerprints(out), "|")
+	if got != "SHA256:abc (ED25519)|SHA256:def (RSA)" {
+		t.Fatalf("unexpected fingerprints %q", got)
+	}
+}
+
+func TestParseOfferedAuthMethods(t *testing.T) {
+	methods, ok := parseOfferedAuthMethods("deploy@10.0.0.1: Permission denied (publickey,password,keyboard-interactive).\r\n")
+	if !ok || strings.Join(methods, ",") != "publickey,password,keyboard-interactive" {
+		t.Fatalf("unexpected methods %v %v", methods, ok)
+	}
+	if _, ok := parseOfferedAuthMethods("ssh: connect to host 10.0.0.1 port 22: Connection refused"); ok {
+		t.Fatal("expected connection errors not to parse as a method list")
+	}
+}
+
+func TestFirstContactSSHArgs(t *testing.T) {
+	h := Host{Hostname: "10.0.0.1", User: "deploy", Port: "2222", IdentityFile: "/keys/id", ProxyJump: "bastion"}
+	key := strings.Join(firstContactSSHArgs(h, "publickey"), " ")
+	for _, want := range []string{"PreferredAuthentications=publickey", "BatchMode=yes", "StrictHostKeyChecking=yes", "-i /keys/id", "-J bastion", "-p 2222", "10.0.0.1 exit"} {
+		if !strings.Contains(key, want) {
+			t.Errorf("publickey args missing %q: %s", want, key)
+		}
+	}
+	password := strings.Join(firstContactSSHArgs(h, "password"), " ")
+	if strings.Contains(password, "BatchMode") || strings.Contains(password, "-i ") || !strings.Contains(password, "PubkeyAuthentication=no") {
+		t.Fatalf("password probe should allow one prompt and skip keys: %s", password)
+	}
+}
+
+func TestFirstContactRecordsResults(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("ASSHO_STORE_PASSWORD", "0")
+	hosts := []Host{{ID: "h1", Alias: "web", Hostname: "10.0.0.1"}}
+	m := model{rawHosts: hosts, list: newTestListModel(nil, hosts), historyList: newTestHistoryListModel()}
+	m.list.Select(0)
+
+	updated, _ := m.updateList(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("f")})
+	m = updated.(model)
+	if m.state != stateFirstContact || m.firstContact.phase != firstContactScanning {
+		t.Fatalf("expected the first-contact scan to start, got state %v", m.state)
+	}
+	updated, _ = m.finishFirstContactScan(firstContactScanMsg{hostID: "h1", fingerprints: []string{"SHA256:abc (ED25519)"}})
+	m = updated.(model)
+	updated, cmd := m.updateFirstContact(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(model)
+	if cmd == nil || m.firstContact.phase != firstContactTesting {
+		t.Fatal("expected enter to start the trust review and auth probe")
+	}
+
+	probes := []authProbe{{method: "publickey", status: "failed", detail: "Permission denied"}, {method: "password", status: "ok"}, {method: "keyboard-interactive", status: "not offered"}}
+	updated, _ = m.finishFirstContactAuth(firstContactAuthMsg{hostID: "h1", offered: []string{"publickey", "password"}, probes: probes})
+	m = updated.(model)
+	fc := m.rawHosts[0].FirstContact
+	if fc == nil || strings.Join(fc.Working, ",") != "password" || fc.Fingerprints[0] != "SHA256:abc (ED25519)" {
+		t.Fatalf("expected results recorded on the host, got %+v", fc)
+	}
+	if !m.firstContact.canInstallKey() || !strings.Contains(m.renderFirstContactView(), "ssh-copy-id") {
+		t.Fatal("expected ssh-copy-id to be offered after key auth failed")
+	}
+	_, saved, _, err := loadConfig()
+	if err != nil || len(saved) != 1 || saved[0].FirstContact == nil {
+		t.Fatalf("expected results to be saved, got %+v %v", saved, err)
+	}
+
+	updated, _ = m.updateFirstContact(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(model)
+	if m.state != stateList {
+		t.Fatalf("expected enter to return to the dashboard, got %v", m.state)
+	}
+	m.detailHostID = "h1"
+	if view := m.renderDetailView(); !strings.Contains(view, "First contact") {
+		t.Fatalf("expected detail pane to show the results, got:\n%s", view)
+	}
+
+	m.state = stateFirstContact
+	updated, _ = m.finishFirstContactAuth(firstContactAuthMsg{hostID: "h1", err: errors.New("Connection refused")})
+	if m = updated.(model); m.firstContact.errorText != "Connection refused" || m.rawHosts[0].FirstContact.Working[0] != "password" {
+		t.Fatal("expected a failed probe to keep the previous record")
+	}
+}
+
+func TestFirstContactViewFitsTerminal(t *testing.T) {
+	hosts := []Host{{ID: "h1", Alias: "a-very-long-host-alias-for-layout", Hostname: "very-long-hostname.internal.example.com", User: "deploy"}}
+	fc := firstContactState{
+		hostID:       "h1",
+		phase:        firstContactResults,
+		fingerprints: []string{"SHA256:2b0d7Vw5c2QeJz1mH4n8q7l3pXyZ0aBcDeFgHiJkLmN (ED25519)", "SHA256:9aXz (RSA)", "SHA256:ecdsa (ECDSA)"},
+		offered:      []string{"publickey", "password", "keyboard-interactive"},
+		probes: []authProbe{
+			{method: "publickey", status: "failed", detail: "deploy@very-long-hostname: Permission denied (publickey,password)."},
+			{method: "password", status: "skipped", detail: "no stored password"},
+			{method: "keyboard-interactive", status: "skipped", detail: "needs a terminal; used on connect"},
+		},
+		errorText: "ssh-copy-id failed: exit status 1",
+	}
+	for _, size := range []struct{ width, height int }{{40, 16}, {80, 24}, {140, 40}} {
+		m := model{width: size.width, height: size.height, rawHosts: hosts, firstContact: fc}
+		lines := strings.Split(m.renderFirstContactView(), "\n")
+		if len(lines) > size.height {
+			t.Fatalf("%dx%d: got %d lines", size.width, size.height, len(lines))
+		}
+		for i, line := range lines {
+			if ansi.StringWidth(line) > size.width {
+				t.Fatalf("%dx%d line %d has width %d", size.width, size.height, i, ansi.StringWidth(line))
+			}
+		}
+	}
+}
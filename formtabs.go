@@ -0,0 +1,69 @@
+package main
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// --- Form Tabs ---
+
+// The host form opens on a short Basic tab: endpoint, key, password, jump
+// host, and group, enough for a quick add. Forwards, proxies, the session
+// setup (remote command, tmux, TERM, locale, terminal, transport, timeout),
+// web UIs, expiry, ownership, and notes sit on an Advanced tab. Ctrl+O
+// switches tabs, Tab runs on from the last Basic field into Advanced, and a
+// save error on a hidden field brings its tab forward. The Advanced tab label
+// counts the options set there, so nothing configured is out of sight
+// unannounced.
+
+// formControlAdvanced reports whether control lives on the Advanced tab.
+func formControlAdvanced(control formControl) bool {
+	return control >= controlLocalForward && control <= controlNotes
+}
+
+// formAdvancedTab reports whether the form shows its Advanced tab.
+func (m model) formAdvancedTab() bool {
+	if m.form.focus == controlDelete {
+		return m.form.advanced
+	}
+	return formControlAdvanced(m.form.focus)
+}
+
+// switchFormTab moves focus to the first control of the other tab.
+func (m model) switchFormTab() (tea.Model, tea.Cmd) {
+	m.form.advanced = !m.formAdvancedTab()
+	m.form.focus = controlAlias
+	if m.form.advanced {
+		m.form.focus = controlLocalForward
+	}
+	m.form.formError = ""
+	m.form.deleteArmed = false
+	return m, m.focusInputs()
+}
+
+// advancedOptionsSet counts the Advanced tab options that hold a value.
+func (m model) advancedOptionsSet() int {
+	n := 0
+	for control := controlLocalForward; control <= controlNotes; control++ {
+		field, ok := fieldForFormControl(control)
+		if !ok {
+			continue
+		}
+		value := strings.TrimSpace(m.form.inputs[field].Value())
+		switch {
+		case control == controlTransport:
+			// Blank is plain ssh.
+			if value != "" {
+				n++
+			}
+		case isFormToggle(control):
+			if formToggleEnabled(value) {
+				n++
+			}
+		case value != "":
+			n++
+		}
+	}
+	return n
+}
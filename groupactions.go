@@ -0,0 +1,276 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// --- Group Actions ---
+
+// With a group row selected, ctrl+t tests every member in parallel, ctrl+d
+// scans all of them for containers, and E shows the group as ssh_config
+// stanzas. Results are collected on one summary screen. Tests skip members
+// under maintenance (see maintenance.go) rather than count them as failures.
+
+type groupRunKind int
+
+const (
+	groupRunTest groupRunKind = iota
+	groupRunScan
+	groupRunExport
+)
+
+type groupRunResult struct {
+	hostID  string
+	alias   string
+	done    bool
+	ok      bool
+	skipped bool // under maintenance, not tested
+	detail  string
+}
+
+type groupRunState struct {
+	id      int // bumped per run so late results from an earlier run are dropped
+	kind    groupRunKind
+	group   string
+	results []groupRunResult
+	config  string // groupRunExport only
+	notice  string
+	err     bool
+}
+
+type groupRunResultMsg struct {
+	run        int
+	index      int
+	latency    time.Duration
+	os         *HostOS
+	containers []Host
+	err        error
+}
+
+// groupMembers returns the non-container hosts listed under a group row,
+// including the synthetic pinned and archived sections.
+func groupMembers(g groupItem, groups []Group, hosts []Host) []Host {
+	var members []Host
+	switch {
+	case g.ID == "__archived__":
+		for _, h := range hosts {
+			if h.Archived {
+				members = append(members, h)
+			}
+		}
+		return members
+	case g.Smart():
+		var active []Host
+		for _, h := range hosts {
+			if !h.Archived {
+				active = append(active, h)
+			}
+		}
+		for _, i := range smartGroupMembers(g.Group, groups, active) {
+			members = append(members, active[i])
+		}
+		return members
+	}
+	for _, h := range hosts {
+		if h.Archived || h.IsContainer {
+			continue
+		}
+		if (g.ID == "__pinned__" && h.Pinned) || (g.ID != "__pinned__" && h.GroupID == g.ID) {
+			members = append(members, h)
+		}
+	}
+	return members
+}
+
+func (m model) startGroupRun(g groupItem, kind groupRunKind) (tea.Model, tea.Cmd) {
+	m.clearListDeleteConfirm()
+	members := groupMembers(g, m.rawGroups, m.rawHosts)
+	if len(members) == 0 {
+		m.status.message = g.Name + " has no hosts"
+		m.status.isError = true
+		m.status.version++
+		return m, statusClearCmd(m.status.version)
+	}
+	m.groupRun = groupRunState{id: m.groupRun.id + 1, kind: kind, group: g.Name}
+	m.state = stateGroupRun
+	if kind == groupRunExport {
+		var buf bytes.Buffer
+		fprintSSHConfig(&buf, withGroupDefaults(members, m.rawGroups))
+		m.groupRun.config = strings.TrimRight(buf.String(), "\n")
+		return m, nil
+	}
+	actionKind := sshActionGroupTest
+	if kind == groupRunScan {
+		actionKind = sshActionGroupScan
+	}
+	cmds := make([]tea.Cmd, 0, len(members))
+	inv := m.inventory()
+	now := time.Now()
+	for i, h := range members {
+		if kind == groupRunTest && h.inMaintenance(now) {
+			m.groupRun.results = append(m.groupRun.results, groupRunResult{hostID: h.ID, alias: h.Alias, done: true, skipped: true, detail: "skipped · " + maintenanceLabel(h, now)})
+			continue
+		}
+		m.groupRun.results = append(m.groupRun.results, groupRunResult{hostID: h.ID, alias: h.Alias})
+		cmds = append(cmds, checkHostTrustCmd(pendingSSHAction{kind: actionKind, host: h, trustHost: h, hostIndex: i, groupRun: m.groupRun.id, inv: inv}))
+	}
+	return m, tea.Batch(cmds...)
+}
+
+func groupRunTrusted(action pendingSSHAction) tea.Cmd {
+	return func() tea.Msg {
+		h := action.host
+		msg := groupRunResultMsg{run: action.groupRun, index: action.hostIndex}
+		if action.kind == sshActionGroupScan {
+			scan := runDockerScan(context.Background(), h, false)
+			recordAudit("scan", h.Alias, h, scan.err)
+			msg.containers, msg.err = scan.containers, scan.err
+			return msg
+		}
+		start := time.Now()
+		msg.err = runSSHTest(h, "exit")
+		msg.latency = time.Since(start)
+		recordAudit("test", h.Alias, h, msg.err)
+		if msg.err == nil && probeOSEnabled() {
+			msg.os = probeOS(h)
+		}
+		return msg
+	}
+}
+
+func (m model) finishGroupRunResult(msg groupRunResultMsg) (tea.Model, tea.Cmd) {
+	if msg.run != m.groupRun.id || msg.index < 0 || msg.index >= len(m.groupRun.results) {
+		return m, nil
+	}
+	result := &m.groupRun.results[msg.index]
+	result.done, result.ok = true, msg.err == nil
+	switch {
+	case msg.err != nil:
+		result.detail, _ = formatTestStatus(msg.err)
+	case m.groupRun.kind == groupRunScan:
+		result.detail = fmt.Sprintf("%d containers", len(msg.containers))
+	default:
+		result.detail = formatLatency(msg.latency)
+	}
+	if m.groupRun.kind == groupRunTest {
+		if msg.os != nil {
+			m.setHostOS(result.hostID, msg.os)
+		}
+		m.recordTestStats(result.hostID, msg.latency, msg.err)
+	} else if msg.err == nil {
+		if idx := findHostIndexByID(m.rawHosts, result.hostID); idx != -1 {
+			m.rawHosts[idx].Containers = msg.containers
+			m.rawHosts[idx].Expanded = true
+			m.refreshList()
+		}
+	}
+	if done, _, _ := m.groupRun.summary(); done == len(m.groupRun.results) {
+		return m, webhookCmd(groupRunEvent(m.groupRun))
+	}
+	return m, nil
+}
+
+// summary counts finished, succeeded, and failed members. Skipped members
+// count as finished but neither succeeded nor failed.
+func (s groupRunState) summary() (done, ok, failed int) {
+	for _, r := range s.results {
+		if !r.done {
+			continue
+		}
+		done++
+		if r.skipped {
+			continue
+		}
+		if r.ok {
+			ok++
+		} else {
+			failed++
+		}
+	}
+	return done, ok, failed
+}
+
+func (m model) updateGroupRun(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		m.quitting = true
+		return m, tea.Quit
+	case "esc", "q":
+		m.state = stateList
+	case "y", "c":
+		if m.groupRun.kind != groupRunExport {
+			return m, nil
+		}
+		if err := copyToClipboard(m.groupRun.config + "\n"); err != nil {
+			m.groupRun.notice, m.groupRun.err = "Copy failed: "+err.Error(), true
+		} else {
+			m.groupRun.notice, m.groupRun.err = "Copied to clipboard", false
+		}
+	}
+	return m, nil
+}
+
+func (m model) renderGroupRunView() string {
+	width, height := normalizedSize(m.width, m.height)
+	inner := max(min(width-6, 100)-6, 24)
+	run := m.groupRun
+	title := map[groupRunKind]string{groupRunTest: "TEST GROUP", groupRunScan: "SCAN GROUP", groupRunExport: "EXPORT GROUP"}[run.kind]
+	var b strings.Builder
+	b.WriteString(lipgloss.NewStyle().Bold(true).Foreground(colorText).Render(ansi.Truncate(title+" · "+run.group, inner, "…")) + "\n")
+
+	if run.kind == groupRunExport {
+		b.WriteString(formHintStyle.Render("ssh_config stanzas for every host in the group") + "\n\n")
+		lines := strings.Split(run.config, "\n")
+		maxLines := max(height-12, 3)
+		for i, line := range lines {
+			if i >= maxLines {
+				b.WriteString(formHintStyle.Render(fmt.Sprintf("… %d more lines", len(lines)-maxLines)) + "\n")
+				break
+			}
+			b.WriteString(lipgloss.NewStyle().Foreground(colorPrimary).Render(ansi.Truncate(line, inner, "…")) + "\n")
+		}
+		if run.notice != "" {
+			style := testSuccessStyle
+			if run.err {
+				style = testFailStyle
+			}
+			b.WriteString("\n" + style.Render(ansi.Truncate(run.notice, inner, "…")) + "\n")
+		}
+		b.WriteString("\n" + helpEntry("y", "copy") + "  " + helpEntry("esc", "back"))
+		return centeredWorkspace(b.String(), width, height)
+	}
+
+	done, ok, failed := run.summary()
+	counts := fmt.Sprintf("%d/%d done · %d ok · %d failed", done, len(run.results), ok, failed)
+	if skipped := done - ok - failed; skipped > 0 {
+		counts += fmt.Sprintf(" · %d in maintenance", skipped)
+	}
+	b.WriteString(formHintStyle.Render(counts) + "\n\n")
+	maxRows := max(height-12, 3)
+	for i, r := range run.results {
+		if i >= maxRows {
+			b.WriteString(formHintStyle.Render(fmt.Sprintf("… %d more", len(run.results)-maxRows)) + "\n")
+			break
+		}
+		mark, style := "…", testPendingStyle
+		if r.skipped {
+			mark, style = "🔧", formHintStyle
+		} else if r.done && r.ok {
+			mark, style = "✔", testSuccessStyle
+		} else if r.done {
+			mark, style = "✘", testFailStyle
+		}
+		line := fmt.Sprintf("%s %-20s %s", mark, ansi.Truncate(r.alias, 20, "…"), r.detail)
+		b.WriteString(style.Render(ansi.Truncate(line, inner, "…")) + "\n")
+	}
+	b.WriteString("\n" + helpEntry("esc", "back"))
+	return centeredWorkspace(b.String(), width, height)
+}
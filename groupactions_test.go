@@ -0,0 +1,145 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestGroupMembers(t *testing.T) {
+	groups := []Group{{ID: "g1", Name: "prod"}, {ID: "s1", Name: "roots", Query: "user=root"}}
+	hosts := []Host{
+		{ID: "a", Alias: "web", GroupID: "g1", User: "root", Pinned: true},
+		{ID: "b", Alias: "db", GroupID: "g1", Archived: true},
+		{ID: "c", Alias: "lab", User: "root"},
+		{ID: "d", Alias: "box", IsContainer: true, ParentID: "a", GroupID: "g1"},
+	}
+	cases := []struct {
+		group groupItem
+		want  string
+	}{
+		{groupItem{Group: groups[0]}, "web"},
+		{groupItem{Group: groups[1]}, "web,lab"},
+		{groupItem{Group: Group{ID: "__pinned__"}}, "web"},
+		{groupItem{Group: Group{ID: "__archived__"}}, "db"},
+	}
+	for _, tc := range cases {
+		var aliases []string
+		for _, h := range groupMembers(tc.group, groups, hosts) {
+			aliases = append(aliases, h.Alias)
+		}
+		if got := strings.Join(aliases, ","); got != tc.want {
+			t.Errorf("%s: expected %q, got %q", tc.group.ID, tc.want, got)
+		}
+	}
+}
+
+func TestGroupExportShowsStanzas(t *testing.T) {
+	groups := []Group{{ID: "g1", Name: "prod"}}
+	hosts := []Host{
+		{ID: "a", Alias: "web", Hostname: "10.0.0.1", GroupID: "g1"},
+		{ID: "b", Alias: "other", Hostname: "10.0.0.2"},
+	}
+	m := model{rawGroups: groups, rawHosts: hosts}
+	next, cmd := m.startGroupRun(groupItem{Group: groups[0]}, groupRunExport)
+	m = next.(model)
+	if cmd != nil || m.state != stateGroupRun {
+		t.Fatalf("expected export screen without commands, got state %v", m.state)
+	}
+	if !strings.Contains(m.groupRun.config, "Host web\n") || strings.Contains(m.groupRun.config, "other") {
+		t.Fatalf("expected only group members exported, got:\n%s", m.groupRun.config)
+	}
+
+	m = model{rawGroups: groups}
+	next, _ = m.startGroupRun(groupItem{Group: groups[0]}, groupRunExport)
+	if m = next.(model); m.state == stateGroupRun || !m.status.isError {
+		t.Fatal("expected an empty group to stay on the dashboard with an error")
+	}
+}
+
+func TestGroupActionKeys(t *testing.T) {
+	groups := []Group{{ID: "g1", Name: "prod", Expanded: true}}
+	hosts := []Host{{ID: "a", Alias: "web", Hostname: "10.0.0.1", GroupID: "g1"}}
+	m := model{rawGroups: groups, rawHosts: hosts, list: newTestListModel(groups, hosts)}
+	m.list.Select(0)
+
+	next, _ := m.updateList(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("t")})
+	if got := next.(model); got.state == stateGroupRun || got.state == stateTransfer {
+		t.Fatalf("t is the host transfer key and should do nothing on a group, got state %v", got.state)
+	}
+	next, _ = m.updateList(tea.KeyMsg{Type: tea.KeyCtrlT})
+	if got := next.(model); got.state != stateGroupRun || got.groupRun.kind != groupRunTest {
+		t.Fatalf("expected ctrl+t to test the group, got state %v", got.state)
+	}
+	next, _ = m.updateList(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s")})
+	if got := next.(model); got.state == stateGroupRun {
+		t.Fatal("s is the host command preview and should not export a group")
+	}
+	next, _ = m.updateList(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("E")})
+	if got := next.(model); got.state != stateGroupRun || got.groupRun.kind != groupRunExport {
+		t.Fatalf("expected E to export the group, got state %v", got.state)
+	}
+}
+
+func TestFinishGroupRunResult(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("ASSHO_STORE_PASSWORD", "0")
+	hosts := []Host{{ID: "a", Alias: "web"}, {ID: "b", Alias: "db"}}
+	m := model{rawHosts: hosts, list: newTestListModel(nil, hosts), historyList: newTestHistoryListModel()}
+	m.groupRun = groupRunState{id: 2, kind: groupRunTest, results: []groupRunResult{{hostID: "a", alias: "web"}, {hostID: "b", alias: "db"}}}
+
+	next, _ := m.finishGroupRunResult(groupRunResultMsg{run: 1, index: 0})
+	m = next.(model)
+	if done, _, _ := m.groupRun.summary(); done != 0 {
+		t.Fatal("expected a result from an earlier run to be ignored")
+	}
+	next, _ = m.finishGroupRunResult(groupRunResultMsg{run: 2, index: 0, latency: 40 * time.Millisecond})
+	next, _ = next.(model).finishGroupRunResult(groupRunResultMsg{run: 2, index: 1, err: errors.New("connection refused")})
+	m = next.(model)
+	if done, ok, failed := m.groupRun.summary(); done != 2 || ok != 1 || failed != 1 {
+		t.Fatalf("unexpected summary %d/%d/%d", done, ok, failed)
+	}
+	if s := m.rawHosts[0].Stats; s == nil || s.Tests != 1 || s.LastLatencyMs != 40 {
+		t.Fatalf("expected test stats recorded, got %+v", s)
+	}
+	if s := m.rawHosts[1].Stats; s == nil || s.Failures != 1 {
+		t.Fatalf("expected failure recorded, got %+v", s)
+	}
+
+	m.groupRun = groupRunState{id: 3, kind: groupRunScan, results: []groupRunResult{{hostID: "a", alias: "web"}}}
+	next, _ = m.finishGroupRunResult(groupRunResultMsg{run: 3, index: 0, containers: []Host{{ID: "c1", Alias: "nginx", IsContainer: true, ParentID: "a"}}})
+	m = next.(model)
+	if len(m.rawHosts[0].Containers) != 1 || !m.rawHosts[0].Expanded || m.groupRun.results[0].detail != "1 containers" {
+		t.Fatalf("expected scan containers applied, got %+v", m.rawHosts[0])
+	}
+}
+
+func TestGroupRunViewFitsTerminal(t *testing.T) {
+	var results []groupRunResult
+	for i := range 30 {
+		results = append(results, groupRunResult{alias: fmt.Sprintf("very-long-host-alias-%02d", i), done: i%2 == 0, ok: i%4 == 0, detail: "connection refused by remote host"})
+	}
+	runs := []groupRunState{
+		{kind: groupRunTest, group: "production-eu-west", results: results},
+		{kind: groupRunExport, group: "prod", config: strings.Repeat("Host web\n    HostName very-long-hostname.internal.example.com\n\n", 20), notice: "Copied to clipboard"},
+	}
+	for _, run := range runs {
+		for _, size := range []struct{ width, height int }{{40, 16}, {80, 24}, {140, 40}} {
+			m := model{width: size.width, height: size.height, groupRun: run}
+			lines := strings.Split(m.renderGroupRunView(), "\n")
+			if len(lines) > size.height {
+				t.Fatalf("%dx%d: got %d lines", size.width, size.height, len(lines))
+			}
+			for i, line := range lines {
+				if ansi.StringWidth(line) > size.width {
+					t.Fatalf("%dx%d line %d has width %d", size.width, size.height, i, ansi.StringWidth(line))
+				}
+			}
+		}
+	}
+}
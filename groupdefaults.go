@@ -0,0 +1,82 @@
+package main
+
+import "strings"
+
+// --- Group Defaults ---
+
+// A regular group may carry a default user, identity file, and ProxyJump.
+// Member hosts inherit them when their own field is blank; the stored host is
+// never changed, so editing the group updates every member at once.
+
+// applyGroupDefaults fills h's blank user, identity file, and ProxyJump from
+// its group. A host with a ProxyCommand keeps it instead of the jump host.
+func applyGroupDefaults(h Host, groups []Group) Host {
+	if h.GroupID == "" || h.IsContainer {
+		return h
+	}
+	idx := findGroupIndexByID(groups, h.GroupID)
+	if idx == -1 {
+		return h
+	}
+	g := groups[idx]
+	if h.User == "" {
+		h.User = g.DefaultUser
+	}
+	if h.IdentityFile == "" {
+		h.IdentityFile = g.DefaultIdentityFile
+	}
+	if h.ProxyJump == "" && h.ProxyCommand == "" {
+		h.ProxyJump = g.DefaultProxyJump
+	}
+	return h
+}
+
+// withGroupDefaults applies applyGroupDefaults to every host, for export.
+func withGroupDefaults(hosts []Host, groups []Group) []Host {
+	out := make([]Host, len(hosts))
+	for i, h := range hosts {
+		out[i] = applyGroupDefaults(h, groups)
+	}
+	return out
+}
+
+// formGroup returns the existing group currently selected in the form.
+func (m model) formGroup() (Group, bool) {
+	idx := findGroupByName(m.rawGroups, m.form.inputs[fieldGroup].Value())
+	if idx == -1 || m.rawGroups[idx].Smart() {
+		return Group{}, false
+	}
+	return m.rawGroups[idx], true
+}
+
+// refreshInheritedPlaceholders ghosts the selected group's defaults into the
+// user, key, and ProxyJump inputs so a blank field shows what it will inherit.
+func (m *model) refreshInheritedPlaceholders() {
+	g, _ := m.formGroup()
+	for field, inherited := range map[int]string{
+		fieldUser:      g.DefaultUser,
+		fieldKeyFile:   g.DefaultIdentityFile,
+		fieldProxyJump: g.DefaultProxyJump,
+	} {
+		if inherited != "" {
+			m.form.inputs[field].Placeholder = inherited + " (from " + g.Name + ")"
+		} else {
+			m.form.inputs[field].Placeholder = formPlaceholders[field]
+		}
+	}
+}
+
+// groupDefaultsLabel summarizes a group's defaults for its list row.
+func groupDefaultsLabel(g Group) string {
+	var parts []string
+	if g.DefaultUser != "" {
+		parts = append(parts, "user "+g.DefaultUser)
+	}
+	if g.DefaultIdentityFile != "" {
+		parts = append(parts, "key "+g.DefaultIdentityFile)
+	}
+	if g.DefaultProxyJump != "" {
+		parts = append(parts, "via "+g.DefaultProxyJump)
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestApplyGroupDefaults(t *testing.T) {
+	groups := []Group{{ID: "g1", Name: "prod", DefaultUser: "deploy", DefaultIdentityFile: "~/.ssh/id_prod", DefaultProxyJump: "bastion"}}
+	got := applyGroupDefaults(Host{Alias: "web", GroupID: "g1", User: "root"}, groups)
+	if got.User != "root" || got.IdentityFile != "~/.ssh/id_prod" || got.ProxyJump != "bastion" {
+		t.Fatalf("expected blanks filled and own user kept, got %+v", got)
+	}
+	if got := applyGroupDefaults(Host{Alias: "lab"}, groups); got.User != "" || got.ProxyJump != "" {
+		t.Fatalf("expected ungrouped host untouched, got %+v", got)
+	}
+}
+
+func TestResolveEndpointInheritsGroupDefaults(t *testing.T) {
+	stubNetwork(t, nil, nil)
+	groups := []Group{{ID: "g1", Name: "prod", DefaultUser: "deploy", DefaultProxyJump: "bastion"}}
+	parent := Host{ID: "p", Alias: "box", Hostname: "box.example.com", GroupID: "g1"}
+	cmd, err := buildConnectCommand(parent, hostInventory{hosts: []Host{parent}, groups: groups}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	args := strings.Join(cmd.args, " ")
+	if !strings.Contains(args, "-J bastion") || !strings.Contains(args, "-l deploy") {
+		t.Fatalf("expected inherited user and jump in %q", args)
+	}
+}
+
+func TestGroupExportIncludesDefaults(t *testing.T) {
+	groups := []Group{{ID: "g1", Name: "prod", DefaultUser: "deploy"}}
+	var buf bytes.Buffer
+	fprintSSHConfig(&buf, withGroupDefaults([]Host{{Alias: "web", Hostname: "10.0.0.1", GroupID: "g1"}}, groups))
+	if !strings.Contains(buf.String(), "    User deploy\n") {
+		t.Fatalf("expected inherited user in export, got:\n%s", buf.String())
+	}
+}
+
+func TestFormShowsInheritedPlaceholders(t *testing.T) {
+	m := model{form: formState{inputs: newFormInputs()}, historyList: newTestHistoryListModel()}
+	m.rawGroups = []Group{{ID: "g1", Name: "prod", DefaultUser: "deploy"}}
+	m.list = newTestListModel(m.rawGroups, nil)
+	m.buildGroupOptions("prod")
+	if got := m.form.inputs[fieldUser].Placeholder; got != "deploy (from prod)" {
+		t.Fatalf("expected inherited user placeholder, got %q", got)
+	}
+	if got := m.form.inputs[fieldProxyJump].Placeholder; got != formPlaceholders[fieldProxyJump] {
+		t.Fatalf("expected default jump placeholder, got %q", got)
+	}
+	m.form.groupIndex = 0
+	m.applyGroupSelectionToInput()
+	if got := m.form.inputs[fieldUser].Placeholder; got != formPlaceholders[fieldUser] {
+		t.Fatalf("expected placeholder reset after leaving the group, got %q", got)
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/list"
+)
+
+// --- Group Health ---
+
+// Group rows name their size and how many members are down, e.g.
+// "prod (12 hosts, 1 down)", and the row beneath lists the hosts that are.
+// A host is down when its last connection test failed, whether run from the
+// dashboard or `assho test`, or when a health check run by `assho daemon`
+// last found it failing. Hosts under maintenance are never counted down.
+// The daemon's results are re-read with the periodic container refresh.
+
+// reportedDown reports whether h's last test, or the health daemon's, failed.
+func (h Host) reportedDown(now time.Time) bool {
+	if h.IsContainer || h.inMaintenance(now) {
+		return false
+	}
+	return h.HealthFailing || (h.Stats != nil && h.Stats.LastTestAt > 0 && !h.Stats.LastTestOK)
+}
+
+// loadHealthFailing is the set of lowercased aliases failing in the health
+// daemon's last runs.
+func loadHealthFailing() map[string]bool {
+	failing := map[string]bool{}
+	for _, aliases := range loadHealthState() {
+		for _, alias := range aliases {
+			failing[strings.ToLower(alias)] = true
+		}
+	}
+	return failing
+}
+
+// refreshHealthFailing re-reads the daemon's results, redrawing the list when
+// they changed.
+func (m *model) refreshHealthFailing() {
+	failing := loadHealthFailing()
+	if len(failing) == len(m.healthFailing) {
+		same := true
+		for alias := range failing {
+			same = same && m.healthFailing[alias]
+		}
+		if same {
+			return
+		}
+	}
+	m.healthFailing = failing
+	if m.list.FilterState() == list.Unfiltered {
+		m.refreshList()
+	}
+}
+
+// groupCountLabel is the "(12 hosts, 1 down)" after a group's name.
+func groupCountLabel(g groupItem) string {
+	word := "hosts"
+	if g.HostCount == 1 {
+		word = "host"
+	}
+	label := fmt.Sprintf("%d %s", g.HostCount, word)
+	if len(g.Down) > 0 {
+		label += fmt.Sprintf(", %d down", len(g.Down))
+	}
+	return "(" + label + ")"
+}
+
+// downSummary lists the first few aliases that are down.
+func downSummary(aliases []string) string {
+	const shown = 3
+	if len(aliases) <= shown {
+		return "down: " + strings.Join(aliases, ", ")
+	}
+	return fmt.Sprintf("down: %s +%d", strings.Join(aliases[:shown], ", "), len(aliases)-shown)
+}
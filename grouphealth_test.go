@@ -0,0 +1,56 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestGroupRowCountsDownHosts(t *testing.T) {
+	groups := []Group{{ID: "g1", Name: "prod", Expanded: true}}
+	hosts := []Host{
+		{ID: "h1", Alias: "web", GroupID: "g1", Stats: &HostStats{LastTestAt: 1, LastTestOK: true}},
+		{ID: "h2", Alias: "db", GroupID: "g1", Stats: &HostStats{LastTestAt: 1}},
+		{ID: "h3", Alias: "cache", GroupID: "g1", HealthFailing: true},
+		{ID: "h4", Alias: "old", GroupID: "g1", Stats: &HostStats{LastTestAt: 1}, Maintenance: &Maintenance{}},
+	}
+	g := flattenHosts(groups, hosts)[0].(groupItem)
+	if strings.Join(g.Down, ",") != "db,cache" {
+		t.Fatalf("expected failed tests and daemon failures down but not maintenance, got %v", g.Down)
+	}
+	out := ansi.Strip(newTestListModel(groups, hosts).View())
+	if !strings.Contains(out, "prod (4 hosts, 2 down)") || !strings.Contains(out, "down: db, cache") {
+		t.Fatalf("expected the group row to count and name down hosts\n%s", out)
+	}
+
+	hosts[1].Stats.LastTestOK = true
+	hosts[2].HealthFailing = false
+	out = ansi.Strip(newTestListModel(groups, hosts).View())
+	if !strings.Contains(out, "prod (4 hosts)") || strings.Contains(out, "down") {
+		t.Fatalf("expected no down count once all hosts pass\n%s", out)
+	}
+}
+
+func TestHealthDaemonFailuresMarkHosts(t *testing.T) {
+	groups := []Group{{ID: "g1", Name: "prod", Expanded: true}}
+	hosts := []Host{{ID: "h1", Alias: "Web", GroupID: "g1"}, {ID: "h2", Alias: "db", GroupID: "g1"}}
+	writeTempConfig(t, hosts)
+	if err := saveHealthState(map[string][]string{"disk": {"web"}}); err != nil {
+		t.Fatal(err)
+	}
+	m := model{rawGroups: groups, rawHosts: hosts, list: newTestListModel(groups, hosts)}
+	m.refreshHealthFailing()
+	if g := m.list.Items()[0].(groupItem); len(g.Down) != 1 || g.Down[0] != "Web" {
+		t.Fatalf("expected the daemon's failing alias counted down, got %v", g.Down)
+	}
+	if m.rawHosts[0].HealthFailing {
+		t.Fatal("expected the daemon's results kept out of the saved hosts")
+	}
+}
+
+func TestDownSummary(t *testing.T) {
+	if got := downSummary([]string{"a", "b", "c", "d", "e"}); got != "down: a, b, c +2" {
+		t.Fatalf("got %q", got)
+	}
+}
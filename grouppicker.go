@@ -0,0 +1,151 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// --- Group Picker ---
+
+// Enter on the form's Group field, or typing there, opens a searchable list
+// of groups instead of cycling through them one at a time. Typing narrows
+// the list, ↑↓ move, and Enter picks. The last row creates a group: named
+// after the search when nothing matches it exactly, or, with an empty
+// search, back in the form where the name is typed. Smart groups are left
+// out, since hosts join them by query.
+
+type groupPickerState struct {
+	filter textinput.Model
+	cursor int
+}
+
+// groupPickerOption is a row of the picker: an index into the form's group
+// options, or a new group named name when index is -1.
+type groupPickerOption struct {
+	index int
+	name  string
+}
+
+// openGroupPicker shows the picker over the form, searching for query.
+func (m model) openGroupPicker(query string) (tea.Model, tea.Cmd) {
+	filter := textinput.New()
+	filter.Prompt = "  Search "
+	filter.Placeholder = "group name"
+	filter.PromptStyle = lipgloss.NewStyle().Foreground(colorHighlight).Bold(true)
+	filter.TextStyle = lipgloss.NewStyle().Foreground(colorText)
+	filter.Cursor.Style = lipgloss.NewStyle().Foreground(colorSecondary)
+	filter.SetValue(query)
+	m.groupPicker = groupPickerState{filter: filter}
+	if query == "" {
+		// Start on the current group.
+		for i, option := range m.groupPickerOptions() {
+			if option.index == m.form.groupIndex {
+				m.groupPicker.cursor = i
+			}
+		}
+	}
+	m.form.formError = ""
+	m.state = stateGroupPicker
+	return m, m.groupPicker.filter.Focus()
+}
+
+// groupPickerOptions are the form's group options matching the search, then
+// the row that creates a group.
+func (m model) groupPickerOptions() []groupPickerOption {
+	query := strings.TrimSpace(m.groupPicker.filter.Value())
+	var options []groupPickerOption
+	exact := false
+	for i, name := range m.form.groupOptions {
+		if name == "+ New group..." {
+			continue
+		}
+		if query != "" && !strings.Contains(strings.ToLower(name), strings.ToLower(query)) {
+			continue
+		}
+		exact = exact || strings.EqualFold(name, query)
+		options = append(options, groupPickerOption{index: i, name: name})
+	}
+	if !exact {
+		options = append(options, groupPickerOption{index: -1, name: query})
+	}
+	return options
+}
+
+// pickGroup applies option to the form and returns to it.
+func (m model) pickGroup(option groupPickerOption) (tea.Model, tea.Cmd) {
+	m.state = stateForm
+	if option.index >= 0 {
+		m.form.groupCustom = false
+		m.form.groupIndex = option.index
+		m.applyGroupSelectionToInput()
+		return m, nil
+	}
+	m.form.groupCustom = true
+	m.form.groupIndex = len(m.form.groupOptions) - 1
+	m.form.inputs[fieldGroup].SetValue(option.name)
+	m.form.inputs[fieldGroup].Placeholder = "new group name"
+	m.form.inputs[fieldGroup].CursorEnd()
+	m.refreshInheritedPlaceholders()
+	return m, m.focusInputs()
+}
+
+func (m model) updateGroupPicker(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	options := m.groupPickerOptions()
+	switch msg.String() {
+	case "ctrl+c":
+		m.quitting = true
+		return m, tea.Quit
+	case "esc":
+		m.state = stateForm
+		return m, nil
+	case "up", "shift+tab":
+		if m.groupPicker.cursor > 0 {
+			m.groupPicker.cursor--
+		}
+		return m, nil
+	case "down", "tab":
+		if m.groupPicker.cursor < len(options)-1 {
+			m.groupPicker.cursor++
+		}
+		return m, nil
+	case "enter":
+		return m.pickGroup(options[min(m.groupPicker.cursor, len(options)-1)])
+	}
+	var cmd tea.Cmd
+	m.groupPicker.filter, cmd = m.groupPicker.filter.Update(msg)
+	m.groupPicker.cursor = 0
+	return m, cmd
+}
+
+func (m model) renderGroupPickerView() string {
+	width, height := normalizedSize(m.width, m.height)
+	inner := max(min(width-6, 100)-6, 24)
+	options := m.groupPickerOptions()
+	var b strings.Builder
+	b.WriteString(lipgloss.NewStyle().Bold(true).Foreground(colorText).Render("CHOOSE GROUP") + "\n\n")
+	b.WriteString(m.groupPicker.filter.View() + "\n\n")
+	// Keep the cursor row on screen when there are more groups than rows.
+	rows := max(height-14, 3)
+	start := max(min(m.groupPicker.cursor-rows/2, len(options)-rows), 0)
+	for i := start; i < min(start+rows, len(options)); i++ {
+		option := options[i]
+		label := option.name
+		if option.index == -1 {
+			label = "+ New group…"
+			if option.name != "" {
+				label = fmt.Sprintf("+ New group %q", option.name)
+			}
+		}
+		b.WriteString(selectionLine(i == m.groupPicker.cursor, ansi.Truncate(label, inner-2, "…")) + "\n")
+	}
+	if len(options) > rows {
+		b.WriteString(formHintStyle.Render(fmt.Sprintf("  %d of %d rows shown; type to narrow", rows, len(options))) + "\n")
+	}
+	b.WriteString("\n" + helpEntry("enter", "choose") + "  " + helpEntry("↑↓", "move") + "  " + helpEntry("esc", "back"))
+	return centeredWorkspace(b.String(), width, height)
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+func newGroupPickerTestModel() model {
+	groups := []Group{{ID: "g1", Name: "web"}, {ID: "g2", Name: "db"}, {ID: "g3", Name: "webhooks"}, {ID: "g4", Name: "tagged", Query: "team=ops"}}
+	m := model{state: stateForm, rawGroups: groups, form: newFormState(newFormInputs())}
+	m.buildGroupOptions("db")
+	m.form.focus = controlGroup
+	return m
+}
+
+func TestGroupPickerSearchesAndPicks(t *testing.T) {
+	m := newGroupPickerTestModel()
+	result, _ := m.updateForm(tea.KeyMsg{Type: tea.KeyEnter})
+	got := result.(model)
+	if got.state != stateGroupPicker || got.groupPickerOptions()[got.groupPicker.cursor].name != "db" {
+		t.Fatalf("expected the picker open on the current group, state %v", got.state)
+	}
+	if out := ansi.Strip(got.renderGroupPickerView()); strings.Contains(out, "tagged") || !strings.Contains(out, "+ New group…") {
+		t.Fatalf("expected regular groups and a new-group row\n%s", out)
+	}
+
+	result, _ = got.updateGroupPicker(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("web")})
+	got = result.(model)
+	var names []string
+	for _, option := range got.groupPickerOptions() {
+		names = append(names, option.name)
+	}
+	if strings.Join(names, ",") != "web,webhooks" {
+		t.Fatalf("expected an exact match to hide the new-group row, got %v", names)
+	}
+	result, _ = got.updateGroupPicker(tea.KeyMsg{Type: tea.KeyDown})
+	result, _ = result.(model).updateGroupPicker(tea.KeyMsg{Type: tea.KeyEnter})
+	got = result.(model)
+	if got.state != stateForm || got.form.groupCustom || got.form.inputs[fieldGroup].Value() != "webhooks" {
+		t.Fatalf("expected webhooks picked, got %q", got.form.inputs[fieldGroup].Value())
+	}
+}
+
+func TestGroupPickerCreatesGroupFromSearch(t *testing.T) {
+	m := newGroupPickerTestModel()
+	result, _ := m.updateForm(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("stag")})
+	got := result.(model)
+	if got.state != stateGroupPicker || got.groupPicker.filter.Value() != "stag" {
+		t.Fatalf("expected typing on Group to search, state %v", got.state)
+	}
+	result, _ = got.updateGroupPicker(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("ing")})
+	result, _ = result.(model).updateGroupPicker(tea.KeyMsg{Type: tea.KeyEnter})
+	got = result.(model)
+	if !got.form.groupCustom || got.form.inputs[fieldGroup].Value() != "staging" {
+		t.Fatalf("expected a new group named staging, got %q", got.form.inputs[fieldGroup].Value())
+	}
+
+	result, _ = got.updateForm(tea.KeyMsg{Type: tea.KeyEnter})
+	if got = result.(model); got.state != stateForm || got.form.focus == controlGroup {
+		t.Fatal("expected Enter in the new group name to move on")
+	}
+}
@@ -47,6 +47,19 @@ func TestAllowInsecureTestVariants(t *testing.T) {
 	}
 }
 
+func TestEnvBool(t *testing.T) {
+	for value, want := range map[string]bool{"1": true, " TRUE ": true, "yes": true, "0": false, "No": false, "": true, "maybe": true} {
+		t.Setenv("ASSHO_TEST_SWITCH", value)
+		if got := envBool("ASSHO_TEST_SWITCH", true); got != want {
+			t.Errorf("envBool with %q = %v, want %v", value, got, want)
+		}
+	}
+	t.Setenv("ASSHO_TEST_SWITCH", "maybe")
+	if envBool("ASSHO_TEST_SWITCH", false) {
+		t.Fatal("expected an unrecognized value to fall back to the default")
+	}
+}
+
 func TestRecordHistoryDedupAndLimit(t *testing.T) {
 	history := []HistoryEntry{
 		{HostID: "dup", Alias: "old-dup", Timestamp: 1},
@@ -474,6 +487,34 @@ func TestPinnedHostSavedFromForm(t *testing.T) {
 	}
 }
 
+func TestFormEditKeepsScanState(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("ASSHO_STORE_PASSWORD", "0")
+
+	existing := Host{ID: "h1", Alias: "srv", Hostname: "10.0.0.1", User: "root", Port: "22", HostKeyPin: []string{"SHA256:abc (ED25519)"}, Banner: &HostBanner{Banner: "Authorized use only"}}
+	m := model{
+		rawHosts:    []Host{existing},
+		form:        formState{inputs: newFormInputs()},
+		historyList: newTestHistoryListModel(),
+	}
+	m.list = newTestListModel(nil, m.rawHosts)
+	m.form.selectedHost = &existing
+	m.populateForm(existing)
+	m.buildGroupOptions("")
+	m.form.inputs[fieldUser].SetValue("admin")
+
+	if err := m.saveFromForm(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got := m.rawHosts[0]
+	if got.User != "admin" || len(got.HostKeyPin) != 1 || got.HostKeyPin[0] != "SHA256:abc (ED25519)" {
+		t.Fatalf("expected the edit saved with the host key pin kept, got %+v", got)
+	}
+	if got.Banner == nil || got.Banner.Banner != "Authorized use only" {
+		t.Fatalf("expected the last banner kept, got %+v", got.Banner)
+	}
+}
+
 func TestFlattenHostsPinnedSection(t *testing.T) {
 	hosts := []Host{
 		{ID: "h1", Alias: "normal", Hostname: "a"},
@@ -618,3 +659,89 @@ func TestResolveAliasForCLITestRejectsAmbiguousContainers(t *testing.T) {
 		t.Fatalf("expected ambiguous container error, got %v", err)
 	}
 }
+
+func TestRemoteCommandSavedFromForm(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("ASSHO_STORE_PASSWORD", "0")
+
+	m := model{form: formState{inputs: newFormInputs()}, historyList: newTestHistoryListModel()}
+	m.list = newTestListModel(nil, nil)
+	m.form.inputs[fieldAlias].SetValue("app")
+	m.form.inputs[fieldHostname].SetValue("10.0.0.1")
+	m.form.inputs[fieldRemoteCommand].SetValue("  tmux attach || tmux new  ")
+	m.buildGroupOptions("")
+
+	if err := m.saveFromForm(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := m.rawHosts[len(m.rawHosts)-1].RemoteCommand; got != "tmux attach || tmux new" {
+		t.Errorf("expected trimmed RemoteCommand to be saved, got %q", got)
+	}
+}
+
+func TestBuildSSHArgsRemoteCommand(t *testing.T) {
+	h := Host{Hostname: "example.com", RemoteCommand: "cd /srv/app && exec bash"}
+	args := buildSSHArgs(h, false, "")
+	if args[0] != "-t" {
+		t.Fatalf("expected -t for remote command, got: %v", args)
+	}
+	if args[len(args)-2] != "example.com" || args[len(args)-1] != "cd /srv/app && exec bash" {
+		t.Fatalf("expected remote command after hostname, got: %v", args)
+	}
+
+	// Internal commands such as connection tests must not run the login command.
+	args = buildSSHArgs(h, false, "exit")
+	if args[len(args)-1] != "exit" || strings.Contains(strings.Join(args, " "), "/srv/app") {
+		t.Fatalf("expected explicit remote command to win, got: %v", args)
+	}
+}
+
+func TestTmuxSessionBuildsAttachCommand(t *testing.T) {
+	h := Host{Hostname: "example.com", TmuxSession: "work"}
+	args := buildSSHArgs(h, false, "")
+	if args[0] != "-t" {
+		t.Fatalf("expected -t for tmux session, got: %v", args)
+	}
+	login := args[len(args)-1]
+	if !strings.HasPrefix(login, "sh -c ") || !strings.Contains(login, "exec tmux new -As work") || !strings.Contains(login, "${SHELL:-sh}") {
+		t.Fatalf("unexpected tmux login command: %q", login)
+	}
+}
+
+func TestTmuxSessionValidatedFromForm(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("ASSHO_STORE_PASSWORD", "0")
+
+	newModel := func() model {
+		m := model{form: formState{inputs: newFormInputs()}, historyList: newTestHistoryListModel()}
+		m.list = newTestListModel(nil, nil)
+		m.form.inputs[fieldAlias].SetValue("app")
+		m.form.inputs[fieldHostname].SetValue("10.0.0.1")
+		m.buildGroupOptions("")
+		return m
+	}
+
+	m := newModel()
+	m.form.inputs[fieldTmuxSession].SetValue("my:session")
+	if err := m.saveFromForm(); err == nil {
+		t.Error("expected error for tmux session name containing ':'")
+	}
+
+	m = newModel()
+	m.form.inputs[fieldTmuxSession].SetValue("work")
+	m.form.inputs[fieldRemoteCommand].SetValue("htop")
+	if err := m.saveFromForm(); err == nil {
+		t.Error("expected error when both remote command and tmux session are set")
+	}
+
+	m = newModel()
+	m.form.inputs[fieldTmuxSession].SetValue("work")
+	if err := m.saveFromForm(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := m.rawHosts[len(m.rawHosts)-1].TmuxSession; got != "work" {
+		t.Errorf("expected TmuxSession to be saved, got %q", got)
+	}
+}
@@ -0,0 +1,518 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// --- Scheduled Health Checks ---
+
+// `assho daemon` stays in the foreground and runs the health checks listed
+// in hosts.json on their cron schedules (see schedule.go):
+//
+//	"health_checks": [
+//	  {"name": "prod", "schedule": "*/15 * * * *", "hosts": "group=prod",
+//	   "report": "~/assho-health.log", "webhook": "https://hooks.example.com/assho", "notify": true}
+//	]
+//
+// Hosts is a smart group query (see smartgroup.go); left out, every host is
+// tested. Archived hosts and hosts under maintenance are skipped. Each run
+// appends a summary to the report file. The webhook gets a JSON summary and
+// the desktop a notification only when a host starts failing or recovers,
+// judged against the previous run, which is remembered in health-state.json
+// next to hosts.json so a restart does not report old failures again. The
+// webhooks section hears the same changes as health_changed events.
+// The config is re-read every minute, so edits apply without a restart.
+// With --listen the daemon also serves Prometheus metrics at /metrics, see
+// metrics.go; it then keeps running even when no checks are configured.
+
+const healthParallel = 8
+
+// HealthCheck is one entry of the "health_checks" section.
+type HealthCheck struct {
+	Name     string `json:"name"`
+	Schedule string `json:"schedule"`
+	Hosts    string `json:"hosts,omitempty"`   // smart group query; empty for every host
+	Report   string `json:"report,omitempty"`  // file each summary is appended to
+	Webhook  string `json:"webhook,omitempty"` // URL changes are POSTed to as JSON
+	Notify   bool   `json:"notify,omitempty"`  // desktop notification on changes
+}
+
+type healthFailure struct {
+	Alias    string `json:"alias"`
+	Hostname string `json:"hostname"`
+	Error    string `json:"error"`
+}
+
+type healthReport struct {
+	Check        string          `json:"check"`
+	Time         time.Time       `json:"time"`
+	Tested       int             `json:"tested"`
+	Maintenance  int             `json:"maintenance"`
+	Failing      []healthFailure `json:"failing"`
+	NewlyFailing []string        `json:"newly_failing"`
+	Recovered    []string        `json:"recovered"`
+
+	results map[string]healthResult // by host ID, for the daemon's /metrics
+}
+
+// healthResult is how one host did in a check.
+type healthResult struct {
+	ok      bool
+	latency time.Duration
+	at      time.Time
+}
+
+func (r healthReport) changed() bool {
+	return len(r.NewlyFailing) > 0 || len(r.Recovered) > 0
+}
+
+func validateHealthChecks(checks []HealthCheck) error {
+	seen := map[string]bool{}
+	for _, c := range checks {
+		name := strings.TrimSpace(c.Name)
+		if name == "" {
+			return errors.New("a health check has no name")
+		}
+		if seen[name] {
+			return fmt.Errorf("health check %q is defined twice", name)
+		}
+		seen[name] = true
+		schedule, err := parseCron(c.Schedule)
+		if err != nil {
+			return fmt.Errorf("health check %q: %w", name, err)
+		}
+		if schedule.next(time.Now()).IsZero() {
+			return fmt.Errorf("health check %q: schedule %q never runs", name, c.Schedule)
+		}
+		if _, err := parseHostQuery(c.Hosts); c.Hosts != "" && err != nil {
+			return fmt.Errorf("health check %q: hosts: %w", name, err)
+		}
+		if c.Webhook != "" {
+			if u, err := url.Parse(c.Webhook); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return fmt.Errorf("health check %q: webhook must be an http or https URL", name)
+			}
+		}
+		if c.Report == "" && c.Webhook == "" && !c.Notify {
+			return fmt.Errorf("health check %q has nowhere to report; set report, webhook, or notify", name)
+		}
+	}
+	return nil
+}
+
+// healthTest tests one host the way assho test does; tests replace it.
+var healthTest = func(h Host) error {
+	if err := verifyHostKeyPin(h); err != nil {
+		return err
+	}
+	return runSSHTest(h, "exit")
+}
+
+// runHealthCheck tests the hosts c selects and compares the failures with
+// previous, the aliases that failed last time.
+func runHealthCheck(c HealthCheck, groups []Group, hosts []Host, previous []string, now time.Time) healthReport {
+	report := healthReport{Check: c.Name, Time: now}
+	var query hostQuery
+	if c.Hosts != "" {
+		query, _ = parseHostQuery(c.Hosts)
+	}
+	var targets []Host
+	for _, h := range withGroupDefaults(hosts, groups) {
+		switch {
+		case h.IsContainer || h.Archived || !query.Match(h, groups):
+		case h.inMaintenance(now):
+			report.Maintenance++
+		default:
+			targets = append(targets, h)
+		}
+	}
+	report.Tested = len(targets)
+
+	errs := make([]error, len(targets))
+	latencies := make([]time.Duration, len(targets))
+	sem := make(chan struct{}, healthParallel)
+	var wg sync.WaitGroup
+	inv := hostInventory{hosts: hosts, groups: groups}
+	for i, h := range targets {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			endpoint := resolveEndpoint(h, inv)
+			start := time.Now()
+			errs[i] = healthTest(endpoint)
+			latencies[i] = time.Since(start)
+			recordAudit("test", h.Alias, endpoint, errs[i])
+		}()
+	}
+	wg.Wait()
+
+	report.results = make(map[string]healthResult, len(targets))
+	failing := map[string]bool{}
+	for i, h := range targets {
+		report.results[h.ID] = healthResult{ok: errs[i] == nil, latency: latencies[i], at: now}
+		if errs[i] == nil {
+			continue
+		}
+		status, _ := formatTestStatus(errs[i])
+		report.Failing = append(report.Failing, healthFailure{Alias: h.Alias, Hostname: h.Hostname, Error: status})
+		failing[h.Alias] = true
+		if !slices.Contains(previous, h.Alias) {
+			report.NewlyFailing = append(report.NewlyFailing, h.Alias)
+		}
+	}
+	tested := map[string]bool{}
+	for _, h := range targets {
+		tested[h.Alias] = true
+	}
+	for _, alias := range previous {
+		// A host that left the set or went into maintenance has not recovered.
+		if tested[alias] && !failing[alias] {
+			report.Recovered = append(report.Recovered, alias)
+		}
+	}
+	return report
+}
+
+// failingAliases is what the next run of the same check compares against.
+// Hosts that were not tested keep their previous state.
+func (r healthReport) failingAliases(previous []string, hosts []Host) []string {
+	var out []string
+	for _, f := range r.Failing {
+		out = append(out, f.Alias)
+	}
+	for _, alias := range previous {
+		if slices.Contains(out, alias) || slices.Contains(r.Recovered, alias) {
+			continue
+		}
+		if slices.ContainsFunc(hosts, func(h Host) bool { return h.Alias == alias }) {
+			out = append(out, alias)
+		}
+	}
+	return out
+}
+
+func fprintHealthReport(w io.Writer, r healthReport) {
+	fmt.Fprintf(w, "%s %s: %d tested, %d failing", r.Time.Format("2006-01-02 15:04"), r.Check, r.Tested, len(r.Failing))
+	if r.Maintenance > 0 {
+		fmt.Fprintf(w, ", %d in maintenance", r.Maintenance)
+	}
+	fmt.Fprintln(w)
+	for _, f := range r.Failing {
+		note := ""
+		if slices.Contains(r.NewlyFailing, f.Alias) {
+			note = " (new)"
+		}
+		fmt.Fprintf(w, "  ✘ %s (%s): %s%s\n", f.Alias, f.Hostname, f.Error, note)
+	}
+	for _, alias := range r.Recovered {
+		fmt.Fprintf(w, "  ✔ %s recovered\n", alias)
+	}
+}
+
+func appendHealthReport(path string, r healthReport) error {
+	f, err := os.OpenFile(expandPath(path), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	fprintHealthReport(f, r)
+	return f.Close()
+}
+
+func postHealthReport(endpoint string, r healthReport) error {
+	body, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	return postWebhook(endpoint, body)
+}
+
+// notifyDesktop shows a desktop notification; tests replace it.
+var notifyDesktop = func(title, body string) error {
+	switch runtime.GOOS {
+	case "darwin":
+		script := fmt.Sprintf("display notification %q with title %q", body, title)
+		return exec.Command("osascript", "-e", script).Run()
+	case "windows":
+		return errors.New("desktop notifications are not supported on windows")
+	}
+	if !commandExists("notify-send") {
+		return errMissingTool("notify-send")
+	}
+	return exec.Command("notify-send", "--app-name=assho", title, body).Run()
+}
+
+func healthNotification(r healthReport) (string, string) {
+	title := "assho: " + r.Check
+	var lines []string
+	if len(r.NewlyFailing) > 0 {
+		lines = append(lines, "Failing: "+strings.Join(r.NewlyFailing, ", "))
+	}
+	if len(r.Recovered) > 0 {
+		lines = append(lines, "Recovered: "+strings.Join(r.Recovered, ", "))
+	}
+	return title, strings.Join(lines, "\n")
+}
+
+// deliverHealthReport writes r wherever c asks and returns the failures.
+func deliverHealthReport(c HealthCheck, r healthReport) []error {
+	var errs []error
+	if c.Report != "" {
+		if err := appendHealthReport(c.Report, r); err != nil {
+			errs = append(errs, fmt.Errorf("report: %w", err))
+		}
+	}
+	if !r.changed() {
+		return errs
+	}
+	if c.Webhook != "" {
+		if err := postHealthReport(c.Webhook, r); err != nil {
+			errs = append(errs, fmt.Errorf("webhook: %w", err))
+		}
+	}
+	if c.Notify {
+		if err := notifyDesktop(healthNotification(r)); err != nil {
+			errs = append(errs, fmt.Errorf("notification: %w", err))
+		}
+	}
+	return errs
+}
+
+// --- Health State ---
+
+func healthStatePath() string {
+	return filepath.Join(filepath.Dir(getConfigPath()), "health-state.json")
+}
+
+// loadHealthState returns the failing aliases of each check's last run.
+func loadHealthState() map[string][]string {
+	state := map[string][]string{}
+	data, err := os.ReadFile(healthStatePath())
+	if err == nil {
+		_ = json.Unmarshal(data, &state)
+	}
+	return state
+}
+
+func saveHealthState(state map[string][]string) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := healthStatePath()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// --- Daemon ---
+
+type healthDaemon struct {
+	mu      sync.Mutex
+	state   map[string][]string
+	running map[string]bool
+	out     io.Writer
+	results map[string]healthResult // latest per host ID, served on /metrics
+}
+
+// run runs c against the current config and records and delivers the result.
+func (d *healthDaemon) run(c HealthCheck, groups []Group, hosts []Host, now time.Time) healthReport {
+	d.mu.Lock()
+	previous := slices.Clone(d.state[c.Name])
+	d.mu.Unlock()
+
+	report := runHealthCheck(c, groups, hosts, previous, now)
+
+	d.mu.Lock()
+	d.state[c.Name] = report.failingAliases(previous, hosts)
+	if d.results == nil {
+		d.results = map[string]healthResult{}
+	}
+	maps.Copy(d.results, report.results)
+	errs := []error{saveHealthState(d.state)}
+	d.mu.Unlock()
+	errs = append(errs, deliverHealthReport(c, report)...)
+	if report.changed() {
+		errs = append(errs, fireWebhooks(healthChangedEvent(report))...)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	fprintHealthReport(d.out, report)
+	for _, err := range errs {
+		if err != nil {
+			fmt.Fprintf(d.out, "  ⚠ %s: %v\n", c.Name, err)
+		}
+	}
+	return report
+}
+
+// loadHealthConfig reads the checks and hosts for one round.
+func loadHealthConfig() ([]HealthCheck, []Group, []Host, error) {
+	cfg, err := loadConfigFile()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if err := validateHealthChecks(cfg.HealthChecks); err != nil {
+		return nil, nil, nil, err
+	}
+	return cfg.HealthChecks, cfg.Groups, cfg.Hosts, nil
+}
+
+// tick starts every check due in the minute containing now that is not
+// still running from an earlier minute.
+func (d *healthDaemon) tick(checks []HealthCheck, groups []Group, hosts []Host, now time.Time) {
+	for _, c := range checks {
+		schedule, _ := parseCron(c.Schedule)
+		if !schedule.matches(now) {
+			continue
+		}
+		d.mu.Lock()
+		busy := d.running[c.Name]
+		d.running[c.Name] = true
+		d.mu.Unlock()
+		if busy {
+			fmt.Fprintf(d.out, "%s %s: still running from its last start; skipped\n", now.Format("2006-01-02 15:04"), c.Name)
+			continue
+		}
+		go func() {
+			d.run(c, groups, hosts, now)
+			d.mu.Lock()
+			delete(d.running, c.Name)
+			d.mu.Unlock()
+		}()
+	}
+}
+
+// daemonMux routes the daemon's HTTP endpoints.
+func (d *healthDaemon) mux() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", d.metricsHandler)
+	return mux
+}
+
+// withResults returns hosts with the daemon's own check results in place of
+// older test stats.
+func (d *healthDaemon) withResults(hosts []Host) []Host {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for i, h := range hosts {
+		r, ok := d.results[h.ID]
+		if !ok {
+			continue
+		}
+		var stats HostStats
+		if h.Stats != nil {
+			stats = *h.Stats
+		}
+		if stats.LastTestAt > r.at.Unix() {
+			continue
+		}
+		stats.LastTestAt = r.at.Unix()
+		stats.LastTestOK = r.ok
+		if r.ok {
+			stats.LastLatencyMs = r.latency.Milliseconds()
+		}
+		hosts[i].Stats = &stats
+	}
+	return hosts
+}
+
+func cliDaemon(args []string) {
+	once := len(args) > 0 && args[0] == "--once"
+	listen := ""
+	if len(args) == 2 && args[0] == "--listen" {
+		listen = args[1]
+	} else if (!once && len(args) > 0) || len(args) > 2 {
+		fmt.Fprintln(os.Stderr, "usage: assho daemon [--once [check] | --listen <addr>]")
+		os.Exit(1)
+	}
+	checks, groups, hosts, err := loadHealthConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error loading health checks: %v\n", err)
+		os.Exit(1)
+	}
+	if once && len(args) == 2 {
+		checks = slices.DeleteFunc(checks, func(c HealthCheck) bool { return !strings.EqualFold(c.Name, args[1]) })
+	}
+	if len(checks) == 0 && listen == "" {
+		fmt.Fprintln(os.Stderr, "no matching health checks; add a \"health_checks\" array to hosts.json")
+		os.Exit(1)
+	}
+	d := &healthDaemon{state: loadHealthState(), running: map[string]bool{}, out: os.Stdout}
+
+	if once {
+		failed := false
+		for _, c := range checks {
+			if report := d.run(c, groups, hosts, time.Now()); len(report.Failing) > 0 {
+				failed = true
+			}
+		}
+		if failed {
+			os.Exit(1)
+		}
+		return
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if listen != "" {
+		ln, err := net.Listen("tcp", listen)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error listening on %s: %v\n", listen, err)
+			os.Exit(1)
+		}
+		server := &http.Server{Handler: d.mux(), ReadHeaderTimeout: 5 * time.Second}
+		go func() {
+			if err := server.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				fmt.Fprintf(os.Stderr, "metrics server failed: %v\n", err)
+				stop()
+			}
+		}()
+		defer server.Close()
+		fmt.Fprintf(os.Stderr, "serving metrics on http://%s/metrics\n", ln.Addr())
+	}
+	for _, c := range checks {
+		schedule, _ := parseCron(c.Schedule)
+		fmt.Fprintf(os.Stderr, "%s: next run %s\n", c.Name, schedule.next(time.Now()).Format("2006-01-02 15:04"))
+	}
+	for {
+		now := time.Now()
+		wait := now.Truncate(time.Minute).Add(time.Minute).Sub(now)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+		now = time.Now()
+		if reloaded, g, h, err := loadHealthConfig(); err != nil {
+			fmt.Fprintf(os.Stderr, "error reloading health checks, keeping the previous ones: %v\n", err)
+		} else {
+			checks, groups, hosts = reloaded, g, h
+		}
+		d.tick(checks, groups, hosts, now)
+	}
+}
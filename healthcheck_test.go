@@ -0,0 +1,165 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// stubHealthTest fails the hosts whose alias is in down.
+func stubHealthTest(t *testing.T, down ...string) {
+	t.Helper()
+	original := healthTest
+	healthTest = func(h Host) error {
+		if slices.Contains(down, h.Alias) {
+			return errors.New("connection refused")
+		}
+		return nil
+	}
+	t.Cleanup(func() { healthTest = original })
+}
+
+func TestValidateHealthChecks(t *testing.T) {
+	valid := HealthCheck{Name: "prod", Schedule: "*/15 * * * *", Hosts: "group=prod", Report: "~/health.log"}
+	if err := validateHealthChecks([]HealthCheck{valid}); err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []struct {
+		check HealthCheck
+		want  string
+	}{
+		{HealthCheck{Schedule: "@daily", Notify: true}, "no name"},
+		{HealthCheck{Name: "a", Schedule: "every day", Notify: true}, "five fields"},
+		{HealthCheck{Name: "a", Schedule: "0 0 31 2 *", Notify: true}, "never runs"},
+		{HealthCheck{Name: "a", Schedule: "@daily", Webhook: "ftp://example.com"}, "webhook"},
+		{HealthCheck{Name: "a", Schedule: "@daily"}, "nowhere to report"},
+	} {
+		if err := validateHealthChecks([]HealthCheck{c.check}); err == nil || !strings.Contains(err.Error(), c.want) {
+			t.Errorf("validate %+v = %v, want %q", c.check, err, c.want)
+		}
+	}
+	if err := validateHealthChecks([]HealthCheck{valid, valid}); err == nil {
+		t.Fatal("expected duplicate names to be rejected")
+	}
+}
+
+func TestRunHealthCheckReportsChanges(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	stubHealthTest(t, "web", "db")
+	groups := []Group{{ID: "g1", Name: "prod"}}
+	hosts := []Host{
+		{ID: "a", Alias: "web", Hostname: "10.0.0.1", GroupID: "g1"},
+		{ID: "b", Alias: "db", Hostname: "10.0.0.2", GroupID: "g1"},
+		{ID: "c", Alias: "cache", Hostname: "10.0.0.3", GroupID: "g1"},
+		{ID: "d", Alias: "mail", Hostname: "10.0.0.4", GroupID: "g1", Maintenance: &Maintenance{}},
+		{ID: "e", Alias: "old", Hostname: "10.0.0.5", GroupID: "g1", Archived: true},
+		{ID: "f", Alias: "laptop", Hostname: "10.0.0.6"},
+	}
+	check := HealthCheck{Name: "prod", Schedule: "@hourly", Hosts: "group=prod", Notify: true}
+	now := time.Date(2026, 10, 16, 9, 0, 0, 0, time.Local)
+
+	report := runHealthCheck(check, groups, hosts, []string{"db", "cache", "mail"}, now)
+	if report.Tested != 3 || report.Maintenance != 1 || len(report.Failing) != 2 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	if !slices.Equal(report.NewlyFailing, []string{"web"}) || !slices.Equal(report.Recovered, []string{"cache"}) {
+		t.Fatalf("expected web new and cache recovered, got %q %q", report.NewlyFailing, report.Recovered)
+	}
+	// mail was not tested, so it is still remembered as failing.
+	if got := report.failingAliases([]string{"db", "cache", "mail"}, hosts); !slices.Equal(got, []string{"web", "db", "mail"}) {
+		t.Fatalf("failingAliases = %q", got)
+	}
+
+	var out strings.Builder
+	fprintHealthReport(&out, report)
+	for _, want := range []string{"prod: 3 tested, 2 failing, 1 in maintenance", "✘ web (10.0.0.1): ", "(new)", "✔ cache recovered"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("report missing %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestDeliverHealthReport(t *testing.T) {
+	var posted healthReport
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&posted)
+	}))
+	defer server.Close()
+	var notified string
+	original := notifyDesktop
+	notifyDesktop = func(title, body string) error { notified = title + "|" + body; return nil }
+	t.Cleanup(func() { notifyDesktop = original })
+
+	path := filepath.Join(t.TempDir(), "health.log")
+	check := HealthCheck{Name: "prod", Report: path, Webhook: server.URL, Notify: true}
+	quiet := healthReport{Check: "prod", Tested: 2}
+	if errs := deliverHealthReport(check, quiet); len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	if posted.Check != "" || notified != "" {
+		t.Fatal("expected no webhook or notification without changes")
+	}
+
+	changed := healthReport{Check: "prod", Tested: 2, Failing: []healthFailure{{Alias: "web", Error: "refused"}}, NewlyFailing: []string{"web"}}
+	if errs := deliverHealthReport(check, changed); len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	if !slices.Equal(posted.NewlyFailing, []string{"web"}) || notified != "assho: prod|Failing: web" {
+		t.Fatalf("unexpected delivery %+v %q", posted, notified)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || strings.Count(string(data), "prod:") != 2 {
+		t.Fatalf("expected both runs appended to the report, got %q (%v)", data, err)
+	}
+}
+
+func TestHealthStateSurvivesRestart(t *testing.T) {
+	writeTempConfig(t, nil)
+	stubHealthTest(t, "web")
+	hosts := []Host{{ID: "a", Alias: "web", Hostname: "10.0.0.1"}}
+	check := HealthCheck{Name: "all", Schedule: "@hourly", Report: filepath.Join(t.TempDir(), "health.log")}
+
+	var out strings.Builder
+	d := &healthDaemon{state: loadHealthState(), running: map[string]bool{}, out: &out}
+	if report := d.run(check, nil, hosts, time.Now()); !slices.Equal(report.NewlyFailing, []string{"web"}) {
+		t.Fatalf("expected web to be newly failing, got %q", report.NewlyFailing)
+	}
+	d = &healthDaemon{state: loadHealthState(), running: map[string]bool{}, out: &out}
+	if report := d.run(check, nil, hosts, time.Now()); len(report.NewlyFailing) != 0 {
+		t.Fatalf("expected the saved state to keep web from being reported again, got %q", report.NewlyFailing)
+	}
+}
+
+func TestDaemonMetricsReportItsOwnChecks(t *testing.T) {
+	hosts := []Host{
+		{ID: "a", Alias: "web", Hostname: "10.0.0.1", Stats: &HostStats{Tests: 3, LastTestAt: 1700000000, LastTestOK: true}},
+		{ID: "b", Alias: "db", Hostname: "10.0.0.2"},
+	}
+	writeTempConfig(t, hosts)
+	stubHealthTest(t, "web")
+	check := HealthCheck{Name: "all", Schedule: "@hourly", Report: filepath.Join(t.TempDir(), "health.log")}
+
+	var out strings.Builder
+	d := &healthDaemon{state: loadHealthState(), running: map[string]bool{}, out: &out}
+	d.run(check, nil, hosts, time.Now())
+
+	rec := httptest.NewRecorder()
+	d.mux().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := rec.Body.String()
+	for _, want := range []string{
+		`assho_host_up{alias="web",hostname="10.0.0.1"} 0`,
+		`assho_host_up{alias="db",hostname="10.0.0.2"} 1`,
+		`assho_host_tests_total{alias="web",hostname="10.0.0.1"} 3`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Fatalf("expected %q from the daemon's check\n%s", want, body)
+		}
+	}
+}
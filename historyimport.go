@@ -0,0 +1,457 @@
+package main
+
+import (
+	"bufio"
+	"cmp"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// --- Shell History Import ---
+
+// I on the dashboard reads the bash, zsh, and fish history files for ssh
+// commands such as `ssh deploy@web1 -p 2222` and proposes the destinations
+// used most, with their user, port, key file, and jump host, as new hosts.
+// Destinations a saved host already covers are left out. Every proposal
+// starts ticked; space unticks one and enter adds the rest, so a fresh
+// install is seeded in two key presses.
+
+// maxHistoryCandidates caps the proposals, most used first.
+const maxHistoryCandidates = 20
+
+// sshArgFlags are the ssh options that take an argument.
+const sshArgFlags = "BbcDEeFIiJLlmOopQRSWw"
+
+// shellCommandSeparators split a history line into its commands.
+var shellCommandSeparators = regexp.MustCompile(`&&|\|\||[;|]`)
+
+// shellHistoryFiles lists the history files to read; tests replace it.
+var shellHistoryFiles = func() []string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil
+	}
+	fishData := os.Getenv("XDG_DATA_HOME")
+	if fishData == "" {
+		fishData = filepath.Join(home, ".local", "share")
+	}
+	files := []string{
+		filepath.Join(home, ".bash_history"),
+		filepath.Join(home, ".zsh_history"),
+		filepath.Join(home, ".zhistory"),
+		filepath.Join(fishData, "fish", "fish_history"),
+	}
+	if histfile := os.Getenv("HISTFILE"); histfile != "" && !slices.Contains(files, expandPath(histfile)) {
+		files = append(files, expandPath(histfile))
+	}
+	return files
+}
+
+type historyCandidate struct {
+	host     Host
+	uses     int
+	selected bool
+	dnsName  string // reverse DNS name of a known_hosts address, see knownhostsimport.go
+}
+
+// importSource is where the proposals on the review screen come from.
+type importSource int
+
+const (
+	importFromHistory importSource = iota
+	importFromKnownHosts
+	importFromPaste
+)
+
+// historyImportState is the review screen for hosts proposed from shell
+// history, known_hosts, or pasted ssh commands.
+type historyImportState struct {
+	candidates []historyCandidate
+	cursor     int
+	source     importSource
+	hashed     int  // hashed known_hosts entries that were skipped
+	resolving  bool // reverse DNS lookups are still running
+}
+
+// historyCommand strips zsh's and fish's framing from a history line, and
+// returns "" for bash timestamps.
+func historyCommand(line string) string {
+	line = strings.TrimSpace(line)
+	switch {
+	case strings.HasPrefix(line, ": "):
+		// zsh extended history: ": 1700000000:0;ssh web"
+		if i := strings.IndexByte(line, ';'); i != -1 {
+			return line[i+1:]
+		}
+		return ""
+	case strings.HasPrefix(line, "- cmd: "):
+		return strings.TrimPrefix(line, "- cmd: ")
+	case strings.HasPrefix(line, "#"):
+		return ""
+	}
+	return line
+}
+
+// parseSSHInvocation reads the destination and its options from one ssh
+// command. ssh takes options after the destination too, up to the remote
+// command. Commands with shell expansions or unusual quoting are skipped.
+func parseSSHInvocation(command string) (Host, bool) {
+	fields := strings.Fields(command)
+	if len(fields) == 0 || filepath.Base(fields[0]) != "ssh" {
+		return Host{}, false
+	}
+	var h Host
+	for i := 1; i < len(fields); i++ {
+		arg := fields[i]
+		if h.Hostname != "" && !strings.HasPrefix(arg, "-") {
+			break // the remote command
+		}
+		if strings.ContainsAny(arg, "$`'\"*?{}()<>\\") {
+			return Host{}, false
+		}
+		if arg == "--" {
+			continue
+		}
+		if strings.HasPrefix(arg, "-") {
+			flags := arg[1:]
+			for j := 0; j < len(flags); j++ {
+				if !strings.ContainsRune(sshArgFlags, rune(flags[j])) {
+					continue
+				}
+				value := flags[j+1:]
+				if value == "" {
+					if i+1 >= len(fields) {
+						return Host{}, false
+					}
+					i++
+					value = fields[i]
+				}
+				switch flags[j] {
+				case 'p':
+					h.Port = value
+				case 'l':
+					h.User = value
+				case 'i':
+					h.IdentityFile = value
+				case 'J':
+					h.ProxyJump = value
+				}
+				break
+			}
+			continue
+		}
+		if !parseSSHDestination(arg, &h) {
+			return Host{}, false
+		}
+	}
+	if h.Hostname == "" || strings.ContainsAny(h.Hostname, "/@:") && net.ParseIP(h.Hostname) == nil {
+		return Host{}, false
+	}
+	if h.Port != "" {
+		if port, err := strconv.Atoi(h.Port); err != nil || port < 1 || port > 65535 {
+			return Host{}, false
+		}
+		if h.Port == "22" {
+			h.Port = ""
+		}
+	}
+	return h, true
+}
+
+// parseSSHDestination fills in h from user@host or ssh://user@host:port.
+func parseSSHDestination(dest string, h *Host) bool {
+	if strings.HasPrefix(dest, "ssh://") {
+		u, err := url.Parse(dest)
+		if err != nil || u.Hostname() == "" {
+			return false
+		}
+		if u.User != nil {
+			h.User = u.User.Username()
+		}
+		if u.Port() != "" {
+			h.Port = u.Port()
+		}
+		h.Hostname = u.Hostname()
+		return true
+	}
+	if at := strings.LastIndex(dest, "@"); at != -1 {
+		h.User, dest = dest[:at], dest[at+1:]
+	}
+	h.Hostname = dest
+	return true
+}
+
+// historyKey identifies a destination: the same host with another user or
+// port is another candidate.
+func historyKey(h Host) string {
+	return strings.ToLower(h.User + "@" + h.Hostname + ":" + h.Port)
+}
+
+// historyCovered reports whether a saved host already reaches h.
+func historyCovered(hosts []Host, h Host) bool {
+	port := cmp.Or(h.Port, "22")
+	for _, saved := range hosts {
+		if saved.IsContainer {
+			continue
+		}
+		if strings.EqualFold(saved.Alias, h.Hostname) {
+			return true
+		}
+		if strings.EqualFold(bareHostname(saved.Hostname), h.Hostname) && cmp.Or(saved.Port, "22") == port &&
+			(h.User == "" || strings.EqualFold(saved.User, h.User)) {
+			return true
+		}
+	}
+	return false
+}
+
+// historyAlias is a free alias for h: the first label of its hostname, or
+// the whole name, or the name numbered. taken holds lowercased aliases.
+func historyAlias(h Host, taken map[string]bool) string {
+	alias := h.Hostname
+	if net.ParseIP(alias) == nil {
+		alias, _, _ = strings.Cut(alias, ".")
+	}
+	for _, candidate := range []string{alias, h.Hostname} {
+		if !taken[strings.ToLower(candidate)] {
+			return candidate
+		}
+	}
+	n := 2
+	for taken[strings.ToLower(fmt.Sprintf("%s-%d", h.Hostname, n))] {
+		n++
+	}
+	return fmt.Sprintf("%s-%d", h.Hostname, n)
+}
+
+// historyCandidates ranks the ssh destinations in lines by use, leaving out
+// those the saved hosts cover. The options of the latest use are kept.
+func historyCandidates(hosts []Host, lines []string) []historyCandidate {
+	commands := make([]string, 0, len(lines))
+	for _, line := range lines {
+		commands = append(commands, historyCommand(line))
+	}
+	candidates := sshCandidates(hosts, commands)
+	slices.SortStableFunc(candidates, func(a, b historyCandidate) int { return b.uses - a.uses })
+	if len(candidates) > maxHistoryCandidates {
+		candidates = candidates[:maxHistoryCandidates]
+	}
+	assignCandidateAliases(hosts, candidates)
+	return candidates
+}
+
+// sshCandidates collects the ssh destinations in lines in first-seen order,
+// counting repeats and leaving out those the saved hosts cover.
+func sshCandidates(hosts []Host, lines []string) []historyCandidate {
+	byKey := map[string]*historyCandidate{}
+	var order []string
+	for _, line := range lines {
+		for _, command := range shellCommandSeparators.Split(line, -1) {
+			h, ok := parseSSHInvocation(strings.TrimSpace(command))
+			if !ok || historyCovered(hosts, h) {
+				continue
+			}
+			key := historyKey(h)
+			if c, ok := byKey[key]; ok {
+				c.host, c.uses = h, c.uses+1
+				continue
+			}
+			byKey[key] = &historyCandidate{host: h, uses: 1, selected: true}
+			order = append(order, key)
+		}
+	}
+	candidates := make([]historyCandidate, 0, len(order))
+	for _, key := range order {
+		candidates = append(candidates, *byKey[key])
+	}
+	return candidates
+}
+
+// assignCandidateAliases gives every candidate a free alias.
+func assignCandidateAliases(hosts []Host, candidates []historyCandidate) {
+	taken := map[string]bool{}
+	for _, h := range hosts {
+		taken[strings.ToLower(strings.TrimSpace(h.Alias))] = true
+	}
+	for i := range candidates {
+		candidates[i].host.Alias = historyAlias(candidates[i].host, taken)
+		taken[strings.ToLower(candidates[i].host.Alias)] = true
+	}
+}
+
+// readLines returns the lines of every file that exists, in order.
+func readLines(files []string) []string {
+	var lines []string
+	for _, path := range files {
+		f, err := os.Open(path)
+		if err != nil {
+			continue
+		}
+		scanner := bufio.NewScanner(f)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			lines = append(lines, scanner.Text())
+		}
+		f.Close()
+	}
+	return lines
+}
+
+func (m model) openHistoryImport() (tea.Model, tea.Cmd) {
+	m.clearListDeleteConfirm()
+	candidates := historyCandidates(m.rawHosts, readLines(shellHistoryFiles()))
+	if len(candidates) == 0 {
+		m.status.message = "No new ssh destinations in your shell history"
+		m.status.isError = false
+		m.status.version++
+		return m, statusClearCmd(m.status.version)
+	}
+	m.historyImport = historyImportState{candidates: candidates}
+	m.state = stateHistoryImport
+	return m, nil
+}
+
+func (m model) updateHistoryImport(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	s := &m.historyImport
+	switch msg.String() {
+	case "ctrl+c":
+		m.quitting = true
+		return m, tea.Quit
+	case "esc", "q":
+		m.state = stateList
+		return m, nil
+	case "up", "k":
+		if s.cursor > 0 {
+			s.cursor--
+		}
+	case "down", "j":
+		if s.cursor < len(s.candidates)-1 {
+			s.cursor++
+		}
+	case " ", "space", "x":
+		s.candidates[s.cursor].selected = !s.candidates[s.cursor].selected
+	case "a":
+		all := true
+		for _, c := range s.candidates {
+			all = all && c.selected
+		}
+		for i := range s.candidates {
+			s.candidates[i].selected = !all
+		}
+	case "enter":
+		return m.applyHistoryImport()
+	}
+	return m, nil
+}
+
+func (m model) applyHistoryImport() (tea.Model, tea.Cmd) {
+	m.state = stateList
+	source := "shell history"
+	switch m.historyImport.source {
+	case importFromKnownHosts:
+		source = "known_hosts"
+	case importFromPaste:
+		source = "the pasted commands"
+	}
+	var added []Host
+	for _, c := range m.historyImport.candidates {
+		if c.selected {
+			h := c.host
+			h.ID = newHostID()
+			added = append(added, h)
+		}
+	}
+	if len(added) == 0 {
+		m.status.message = "No hosts added"
+		m.status.isError = false
+		m.status.version++
+		return m, statusClearCmd(m.status.version)
+	}
+	snapshot := m.snapshot()
+	if m.historyImport.source == importFromKnownHosts {
+		groupID := m.unsortedGroupID()
+		for i := range added {
+			added[i].GroupID = groupID
+		}
+	}
+	m.rawHosts = append(m.rawHosts, added...)
+	m.refreshList()
+	if err := m.save(); err != nil {
+		m.restoreSnapshot(snapshot)
+		m.status.message = fmt.Sprintf("Failed to save imported hosts: %v", err)
+		m.status.isError = true
+		m.status.version++
+		return m, statusClearCmd(m.status.version)
+	}
+	m.reselectItem(added[0].ID, false)
+	m.status.message = fmt.Sprintf("Added %d hosts from %s", len(added), source)
+	m.status.isError = false
+	m.status.version++
+	return m, statusClearCmd(m.status.version)
+}
+
+func (m model) renderHistoryImportView() string {
+	width, height := normalizedSize(m.width, m.height)
+	inner := max(min(width-6, 100)-6, 24)
+	s := m.historyImport
+	var b strings.Builder
+	title, summary := "IMPORT FROM SHELL HISTORY", fmt.Sprintf("%d ssh destinations not saved yet, most used first", len(s.candidates))
+	switch s.source {
+	case importFromKnownHosts:
+		title, summary = "IMPORT FROM KNOWN_HOSTS", knownHostsSummary(s)
+	case importFromPaste:
+		title, summary = "IMPORT PASTED SSH COMMANDS", fmt.Sprintf("%d hosts not saved yet, in paste order", len(s.candidates))
+	}
+	b.WriteString(lipgloss.NewStyle().Bold(true).Foreground(colorText).Render(title) + "\n")
+	b.WriteString(formHintStyle.Render(ansi.Truncate(summary, inner, "…")) + "\n\n")
+	rows := max(height-12, 4)
+	start := max(min(s.cursor-rows/2, len(s.candidates)-rows), 0)
+	for i := start; i < min(start+rows, len(s.candidates)); i++ {
+		c := s.candidates[i]
+		mark := "[ ]"
+		if c.selected {
+			mark = "[x]"
+		}
+		target := c.host.Hostname
+		if c.host.User != "" {
+			target = c.host.User + "@" + target
+		}
+		if c.host.Port != "" {
+			target += ":" + c.host.Port
+		}
+		if c.host.ProxyJump != "" {
+			target += " via " + c.host.ProxyJump
+		}
+		uses := "1 use"
+		if c.uses > 1 {
+			uses = fmt.Sprintf("%d uses", c.uses)
+		}
+		switch {
+		case s.source == importFromKnownHosts:
+			uses = knownHostsNote(s, c)
+		case s.source == importFromPaste && c.uses == 1:
+			uses = ""
+		}
+		label := fmt.Sprintf("%s %-16s %s", mark, c.host.Alias, target)
+		if uses != "" {
+			label += " · " + uses
+		}
+		b.WriteString(selectionLine(i == s.cursor, ansi.Truncate(label, inner-2, "…")) + "\n")
+	}
+	if len(s.candidates) > rows {
+		b.WriteString(formHintStyle.Render(fmt.Sprintf("  %d of %d shown", rows, len(s.candidates))) + "\n")
+	}
+	b.WriteString("\n" + helpEntry("space", "toggle") + "  " + helpEntry("a", "all") + "  " + helpEntry("enter", "add") + "  " + helpEntry("esc", "cancel"))
+	return centeredWorkspace(b.String(), width, height)
+}
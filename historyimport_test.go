@@ -0,0 +1,93 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestParseSSHInvocation(t *testing.T) {
+	cases := []struct {
+		command string
+		want    Host
+		ok      bool
+	}{
+		{"ssh deploy@web1.prod.example.com -p 2222", Host{User: "deploy", Hostname: "web1.prod.example.com", Port: "2222"}, true},
+		{"ssh -p22 -l root 10.0.0.5", Host{User: "root", Hostname: "10.0.0.5"}, true},
+		{"ssh -vi ~/.ssh/id_ci -J bastion app uptime", Host{Hostname: "app", IdentityFile: "~/.ssh/id_ci", ProxyJump: "bastion"}, true},
+		{"/usr/bin/ssh ssh://admin@db:2200", Host{User: "admin", Hostname: "db", Port: "2200"}, true},
+		{"ssh web 'df -h'", Host{Hostname: "web"}, true},
+		{"ssh $HOST", Host{}, false},
+		{"ssh -p", Host{}, false},
+		{"ssh -p 99999 web", Host{}, false},
+		{"sshfs web:/srv /mnt", Host{}, false},
+		{"git push", Host{}, false},
+	}
+	for _, c := range cases {
+		got, ok := parseSSHInvocation(c.command)
+		if ok != c.ok || got.User != c.want.User || got.Hostname != c.want.Hostname || got.Port != c.want.Port ||
+			got.IdentityFile != c.want.IdentityFile || got.ProxyJump != c.want.ProxyJump {
+			t.Errorf("parseSSHInvocation(%q) = %+v, %v; want %+v, %v", c.command, got, ok, c.want, c.ok)
+		}
+	}
+}
+
+func TestHistoryCandidatesRankByUse(t *testing.T) {
+	saved := []Host{{ID: "h1", Alias: "known", Hostname: "known.example.com", User: "root"}}
+	lines := []string{
+		"ssh deploy@web1.example.com",
+		": 1700000000:0;cd /tmp && ssh deploy@web1.example.com -p 22",
+		"#1700000001",
+		"- cmd: ssh admin@db.example.com",
+		"  when: 1700000002",
+		"ssh root@known.example.com",
+		"ssh known",
+		"ssh deploy@web1.example.com",
+		"ssh web1.other.net",
+	}
+	got := historyCandidates(saved, lines)
+	if len(got) != 3 {
+		t.Fatalf("expected three new destinations, got %+v", got)
+	}
+	if got[0].host.Alias != "web1" || got[0].uses != 3 || !got[0].selected {
+		t.Fatalf("expected web1 first with 3 uses, got %+v", got[0])
+	}
+	if got[1].host.Alias != "db" || got[2].host.Alias != "web1.other.net" {
+		t.Fatalf("expected free aliases in use order, got %q %q", got[1].host.Alias, got[2].host.Alias)
+	}
+}
+
+func TestHistoryImportAddsTickedHosts(t *testing.T) {
+	writeTempConfig(t, nil)
+	history := filepath.Join(t.TempDir(), ".bash_history")
+	if err := os.WriteFile(history, []byte("ssh deploy@web1\nssh admin@db -p 2200\nssh deploy@web1\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	old := shellHistoryFiles
+	shellHistoryFiles = func() []string { return []string{history, history + ".missing"} }
+	t.Cleanup(func() { shellHistoryFiles = old })
+
+	m := model{state: stateList, list: newTestListModel(nil, nil), historyList: newTestHistoryListModel()}
+	result, _ := m.updateList(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("I")})
+	got := result.(model)
+	if got.state != stateHistoryImport || len(got.historyImport.candidates) != 2 {
+		t.Fatalf("expected two proposals, got %+v", got.historyImport.candidates)
+	}
+	if view := got.renderHistoryImportView(); !strings.Contains(view, "deploy@web1 · 2 uses") {
+		t.Fatalf("expected the use count shown\n%s", view)
+	}
+	result, _ = got.updateHistoryImport(tea.KeyMsg{Type: tea.KeyDown})
+	result, _ = result.(model).updateHistoryImport(tea.KeyMsg{Type: tea.KeySpace})
+	result, _ = result.(model).updateHistoryImport(tea.KeyMsg{Type: tea.KeyEnter})
+	got = result.(model)
+	if got.state != stateList || len(got.rawHosts) != 1 || got.rawHosts[0].Alias != "web1" || got.rawHosts[0].ID == "" {
+		t.Fatalf("expected only web1 added, got %+v", got.rawHosts)
+	}
+	_, hosts, _, err := loadConfig()
+	if err != nil || len(hosts) != 1 {
+		t.Fatalf("expected the host saved, got %+v, %v", hosts, err)
+	}
+}
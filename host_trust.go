@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
@@ -21,6 +22,18 @@ const (
 	sshActionScan
 	sshActionInstallKey
 	sshActionRotation
+	sshActionTransfer
+	sshActionOpenWeb
+	sshActionGroupTest
+	sshActionGroupScan
+	sshActionFirstContact
+	sshActionQuickStats
+	sshActionServices
+	sshActionPortForward
+	sshActionCompose
+	sshActionComposeRun
+	sshActionRoundConnect
+	sshActionTunnel
 )
 
 type pendingSSHAction struct {
@@ -32,6 +45,12 @@ type pendingSSHAction struct {
 	publicKey     string
 	rotationIndex int
 	rotationStage rotationStage
+	webURL        string
+	ctx           context.Context // cancels a scan or test; set by their constructors
+	forward       containerPort
+	groupRun      int
+	tunnel        tunnelLeg
+	inv           hostInventory // what host and trustHost are resolved against
 }
 
 type hostTrustState struct {
@@ -54,10 +73,28 @@ type hostTrustFinishedMsg struct {
 	err   error
 }
 
-type hostTrustActionFailedMsg struct{ err error }
+type hostTrustActionFailedMsg struct {
+	host Host
+	err  error
+}
 
 func checkHostTrustCmd(action pendingSSHAction) tea.Cmd {
 	return func() tea.Msg {
+		action.host = resolveEndpoint(action.host, action.inv)
+		action.trustHost = resolveEndpoint(action.trustHost, action.inv)
+		if action.trustHost.isLocal() {
+			return hostTrustCheckMsg{action: action, known: true}
+		}
+		if err := sshMissing(); err != nil && usesSSH(action.trustHost) {
+			return hostTrustCheckMsg{action: action, err: err}
+		}
+		if err := verifyHostKeyPin(action.trustHost); err != nil {
+			return hostTrustCheckMsg{action: action, err: err}
+		}
+		if len(action.trustHost.HostKeyPin) > 0 {
+			// ssh checks a pinned host against its pinned keys, not known_hosts.
+			return hostTrustCheckMsg{action: action, known: true}
+		}
 		known, err := hostKeyKnown(action.trustHost)
 		return hostTrustCheckMsg{action: action, known: known, err: err}
 	}
@@ -66,9 +103,9 @@ func checkHostTrustCmd(action pendingSSHAction) tea.Cmd {
 func knownHostToken(host Host) string {
 	port := strings.TrimSpace(host.Port)
 	if port == "" || port == "22" {
-		return strings.TrimSpace(host.Hostname)
+		return bareHostname(host.Hostname)
 	}
-	return "[" + strings.TrimSpace(host.Hostname) + "]:" + port
+	return "[" + bareHostname(host.Hostname) + "]:" + port
 }
 
 func hostKeyKnown(host Host) (bool, error) {
@@ -113,12 +150,12 @@ func buildHostTrustCommand(host Host) (*exec.Cmd, error) {
 		return nil, errors.New("hostname is required")
 	}
 	if !commandExists("ssh") {
-		return nil, errors.New("ssh is required")
+		return nil, errMissingTool("ssh")
 	}
 	args := []string{
 		"-o", "StrictHostKeyChecking=ask",
 		"-o", "BatchMode=no",
-		"-o", "ConnectTimeout=10",
+		"-o", connectTimeoutOption(max(10, host.connectTimeout())),
 		"-o", "NumberOfPasswordPrompts=0",
 		"-o", "PreferredAuthentications=none",
 		"-o", "PasswordAuthentication=no",
@@ -131,15 +168,17 @@ func buildHostTrustCommand(host Host) (*exec.Cmd, error) {
 		}
 		args = append(args, "-p", host.Port)
 	}
-	if host.ProxyJump != "" {
-		args = append(args, "-J", host.ProxyJump)
-	}
+	args = append(args, proxyArgs(host)...)
 	args = append(args, sshTarget(host), "true")
 	return exec.Command("ssh", args...), nil
 }
 
 func (m model) handleHostTrustCheck(msg hostTrustCheckMsg) (tea.Model, tea.Cmd) {
 	if msg.err != nil {
+		var pinErr *hostKeyPinError
+		if errors.As(msg.err, &pinErr) && !msg.action.background {
+			m.hostKeyAlert = pinErr
+		}
 		return m.failPendingSSHAction(msg.action, msg.err)
 	}
 	if msg.known {
@@ -341,9 +380,9 @@ func (m model) resumePendingSSHActionModel(action pendingSSHAction) (model, tea.
 		updated, cmd := m.connectToHostTrusted(action.host)
 		return updated.(model), cmd
 	case sshActionTest:
-		return m, testConnectionTrusted(action.host)
+		return m, testConnectionTrusted(action.ctx, action.host)
 	case sshActionScan:
-		return m, scanDockerContainersTrusted(action.host, action.hostIndex, action.background)
+		return m, scanDockerContainersTrusted(action.ctx, action.host, action.background)
 	case sshActionInstallKey:
 		cmd, err := buildCopyIDCommand(action.host, action.publicKey)
 		if err != nil {
@@ -353,6 +392,29 @@ func (m model) resumePendingSSHActionModel(action pendingSSHAction) (model, tea.
 		return m, tea.ExecProcess(cmd, func(err error) tea.Msg { return keyInstallFinishedMsg{err: err} })
 	case sshActionRotation:
 		return m, m.rotationCommandTrusted(action.rotationIndex, action.rotationStage)
+	case sshActionTransfer:
+		return m.startTransferTrusted(action.host)
+	case sshActionOpenWeb:
+		return m, openWebURLTrusted(action.host, action.webURL)
+	case sshActionGroupTest, sshActionGroupScan:
+		return m, groupRunTrusted(action)
+	case sshActionFirstContact:
+		return m, firstContactAuthTrusted(action.host)
+	case sshActionQuickStats:
+		return m, quickStatsTrusted(action.host)
+	case sshActionServices:
+		return m, fetchWindowsServicesTrusted(action.host)
+	case sshActionPortForward:
+		return m, startPortForwardTrusted(action.trustHost, action.host, action.forward)
+	case sshActionCompose:
+		return m, listComposeProjectsTrusted(action.host)
+	case sshActionComposeRun:
+		return m.startComposeActionTrusted(action.host)
+	case sshActionRoundConnect:
+		updated, cmd := m.connectRoundTrusted(action.host)
+		return updated.(model), cmd
+	case sshActionTunnel:
+		return m, startTunnelLegTrusted(action.host, action.tunnel)
 	default:
 		return m, nil
 	}
@@ -365,15 +427,15 @@ func (m model) failPendingSSHAction(action pendingSSHAction, err error) (tea.Mod
 func (m model) failPendingSSHActionModel(action pendingSSHAction, err error) (model, tea.Cmd) {
 	switch action.kind {
 	case sshActionConnect:
-		return m, func() tea.Msg { return hostTrustActionFailedMsg{err: err} }
+		return m, func() tea.Msg { return hostTrustActionFailedMsg{host: action.host, err: err} }
 	case sshActionTest:
-		return m, func() tea.Msg { return testConnectionMsg{err: err} }
+		return m, func() tea.Msg { return testConnectionMsg{hostID: action.host.ID, err: err} }
 	case sshActionScan:
 		if action.background {
 			return m, nil
 		}
 		return m, func() tea.Msg {
-			return scanDockerMsg{hostIndex: action.hostIndex, background: action.background, err: err}
+			return scanDockerMsg{hostID: action.host.ID, background: action.background, err: err}
 		}
 	case sshActionInstallKey:
 		return m, func() tea.Msg { return keyInstallFinishedMsg{err: err} }
@@ -381,6 +443,34 @@ func (m model) failPendingSSHActionModel(action pendingSSHAction, err error) (mo
 		return m, func() tea.Msg {
 			return rotationStepMsg{hostIndex: action.rotationIndex, stage: action.rotationStage, err: err, rollbackTried: true}
 		}
+	case sshActionTransfer:
+		m.transfer.phase = transferRunning
+		return m, func() tea.Msg { return transferDoneMsg{err: err} }
+	case sshActionOpenWeb:
+		return m, func() tea.Msg { return webOpenedMsg{url: action.webURL, err: err} }
+	case sshActionGroupTest, sshActionGroupScan:
+		return m, func() tea.Msg { return groupRunResultMsg{run: action.groupRun, index: action.hostIndex, err: err} }
+	case sshActionFirstContact:
+		return m, func() tea.Msg { return firstContactAuthMsg{hostID: action.host.ID, err: err} }
+	case sshActionQuickStats:
+		return m, func() tea.Msg { return quickStatsMsg{hostID: action.host.ID, err: err} }
+	case sshActionServices:
+		return m, func() tea.Msg { return windowsServicesMsg{hostID: action.host.ID, err: err} }
+	case sshActionPortForward:
+		return m, func() tea.Msg { return portForwardMsg{alias: action.host.Alias, err: err} }
+	case sshActionCompose:
+		return m, func() tea.Msg { return composeProjectsMsg{hostID: action.host.ID, err: err} }
+	case sshActionComposeRun:
+		m.compose.phase = composeRunning
+		return m, func() tea.Msg { return composeDoneMsg{err: err} }
+	case sshActionRoundConnect:
+		return m, func() tea.Msg {
+			return roundSessionEndedMsg{alias: action.host.Alias, err: err, unreachable: &action.host}
+		}
+	case sshActionTunnel:
+		return m, func() tea.Msg {
+			return tunnelLegMsg{profile: action.tunnel.profile, alias: action.tunnel.alias, err: err}
+		}
 	default:
 		return m, nil
 	}
@@ -399,8 +489,8 @@ func (m model) renderHostTrustOverlay(base string) string {
 	b.WriteString(formSectionStyle.Render("Target") + "\n")
 	b.WriteString("Host  " + host.Hostname + "\n")
 	b.WriteString("Port  " + port + "\n")
-	if host.ProxyJump != "" {
-		b.WriteString("Via   " + host.ProxyJump + "\n")
+	if host.behindProxy() {
+		b.WriteString("Via   " + host.proxyVia() + "\n")
 	}
 	b.WriteString("\n")
 	b.WriteString("OpenSSH will show the SHA256 fingerprint in the terminal. Compare it with the server console, then answer yes to trust it.\n\n")
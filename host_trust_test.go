@@ -122,7 +122,7 @@ func TestPostEnrollmentCommandsRequireKnownHost(t *testing.T) {
 func TestUnknownActionsQueueAndDeduplicate(t *testing.T) {
 	t.Setenv("HOME", t.TempDir())
 	host := Host{ID: "one", Alias: "prod", Hostname: "prod.example", Port: "22"}
-	action := pendingSSHAction{kind: sshActionScan, host: host, trustHost: host, hostIndex: 0, background: true}
+	action := pendingSSHAction{kind: sshActionScan, host: host, trustHost: host, background: true}
 	m := model{}
 
 	updatedModel, _ := m.handleHostTrustCheck(hostTrustCheckMsg{action: action, known: false})
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"strings"
+)
+
+// --- Host Addresses ---
+
+// ssh takes IPv6 literals bare (::1, fe80::1%eth0) as its destination and in
+// HostName, but anything that appends a port or path needs them bracketed.
+
+// bareHostname strips the brackets users often type around IPv6 literals.
+func bareHostname(hostname string) string {
+	hostname = strings.TrimSpace(hostname)
+	if strings.HasPrefix(hostname, "[") && strings.HasSuffix(hostname, "]") {
+		return hostname[1 : len(hostname)-1]
+	}
+	return hostname
+}
+
+func isIPv6Literal(hostname string) bool {
+	return strings.Contains(bareHostname(hostname), ":")
+}
+
+// bracketHostname returns the hostname in a form that can be followed by
+// :port or :path.
+func bracketHostname(hostname string) string {
+	bare := bareHostname(hostname)
+	if isIPv6Literal(bare) {
+		return "[" + bare + "]"
+	}
+	return bare
+}
+
+// hostPort joins a hostname and port for display, bracketing IPv6 literals.
+// A blank port is left off.
+func hostPort(hostname, port string) string {
+	if port == "" {
+		return bareHostname(hostname)
+	}
+	return bracketHostname(hostname) + ":" + port
+}
+
+// splitForwardSpec splits a LocalForward spec on colons outside brackets, so
+// [::1]:8080:db:5432 yields four parts. The slash form ssh also accepts
+// (8080/::1/80) is split on slashes instead.
+func splitForwardSpec(spec string) []string {
+	spec = strings.TrimSpace(spec)
+	if strings.Contains(spec, "/") {
+		return strings.Split(spec, "/")
+	}
+	var parts []string
+	depth, start := 0, 0
+	for i, r := range spec {
+		switch r {
+		case '[':
+			depth++
+		case ']':
+			depth--
+		case ':':
+			if depth == 0 {
+				parts = append(parts, spec[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, spec[start:])
+}
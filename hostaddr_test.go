@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHostPortBracketsIPv6(t *testing.T) {
+	cases := []struct{ host, port, want string }{
+		{"example.com", "2222", "example.com:2222"},
+		{"::1", "2222", "[::1]:2222"},
+		{"[fe80::1%eth0]", "22", "[fe80::1%eth0]:22"},
+		{"[::1]", "", "::1"},
+	}
+	for _, tc := range cases {
+		if got := hostPort(tc.host, tc.port); got != tc.want {
+			t.Errorf("hostPort(%q, %q) = %q, want %q", tc.host, tc.port, got, tc.want)
+		}
+	}
+	if got := (Host{User: "root", Hostname: "2001:db8::5", Port: "2200"}).Description(); got != "root@[2001:db8::5]:2200" {
+		t.Errorf("unexpected description %q", got)
+	}
+}
+
+func TestIPv6DestinationIsBare(t *testing.T) {
+	h := Host{Hostname: "[fe80::1%eth0]", User: "root", Port: "2222"}
+	args := buildSSHArgs(h, false, "")
+	if args[len(args)-1] != "fe80::1%eth0" {
+		t.Fatalf("expected bare IPv6 destination, got %v", args)
+	}
+	if got := knownHostToken(h); got != "[fe80::1%eth0]:2222" {
+		t.Fatalf("unexpected known_hosts token %q", got)
+	}
+	if got := sshTarget(h); got != "root@fe80::1%eth0" {
+		t.Fatalf("unexpected ssh target %q", got)
+	}
+	if got := auditTarget(h); got != "[fe80::1%eth0]:2222" {
+		t.Fatalf("unexpected audit target %q", got)
+	}
+}
+
+func TestLocalForwardPortWithIPv6(t *testing.T) {
+	cases := map[string]string{
+		"8080:localhost:80":        "8080",
+		"[::1]:8080:localhost:80":  "8080",
+		"8080:[2001:db8::5]:80":    "8080",
+		"127.0.0.1:9000:[::1]:443": "9000",
+		"8080/::1/80":              "8080",
+		"nonsense":                 "",
+	}
+	for spec, want := range cases {
+		if got := localForwardPort(spec); got != want {
+			t.Errorf("localForwardPort(%q) = %q, want %q", spec, got, want)
+		}
+	}
+}
+
+func TestSSHConfigRoundTripsIPv6Zone(t *testing.T) {
+	var buf bytes.Buffer
+	fprintSSHConfig(&buf, []Host{{Alias: "link", Hostname: "fe80::1%eth0", Port: "2222"}})
+	if !strings.Contains(buf.String(), "    HostName fe80::1%%eth0\n") {
+		t.Fatalf("expected escaped zone ID in export, got:\n%s", buf.String())
+	}
+	path := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(path, buf.Bytes(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	hosts, err := parseSSHConfig(path)
+	if err != nil || len(hosts) != 1 || hosts[0].Hostname != "fe80::1%eth0" || hosts[0].Port != "2222" {
+		t.Fatalf("expected IPv6 host to round-trip, got %+v %v", hosts, err)
+	}
+}
+
+func TestSaveFromFormStoresBareIPv6(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("ASSHO_STORE_PASSWORD", "0")
+	m := model{form: formState{inputs: newFormInputs()}, historyList: newTestHistoryListModel()}
+	m.list = newTestListModel(nil, nil)
+	m.form.inputs[fieldAlias].SetValue("v6")
+	m.form.inputs[fieldHostname].SetValue("[2001:db8::5]")
+	m.buildGroupOptions("")
+	if err := m.saveFromForm(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := m.rawHosts[0].Hostname; got != "2001:db8::5" {
+		t.Fatalf("expected brackets stripped on save, got %q", got)
+	}
+}
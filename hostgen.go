@@ -0,0 +1,327 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// --- Host Generator ---
+
+// N on the dashboard expands a hostname pattern such as
+// web-[01..20].prod.example.com into one host per name, for fleets with
+// predictable naming. A bracket holds a numeric range (01..20 or 1-9, zero
+// padding kept), a letter range (a..f), or a list (eu,us). Several brackets
+// multiply. Aliases come from a template where $1, $2, ... are the bracket
+// values; left empty, the first label of each hostname is used. The hosts
+// join the group named on the screen, which is created when missing.
+
+const maxGeneratedHosts = 256
+
+const (
+	genFocusPattern = iota
+	genFocusAlias
+	genFocusGroup
+	genFocusCount
+)
+
+var (
+	genBracket  = regexp.MustCompile(`\[([^\[\]]*)\]`)
+	genRange    = regexp.MustCompile(`^(\w+)\s*(?:\.\.|-)\s*(\w+)$`)
+	genAliasRef = regexp.MustCompile(`\$([1-9])`)
+)
+
+type hostGeneratorState struct {
+	pattern textinput.Model
+	alias   textinput.Model
+	group   textinput.Model
+	focus   int
+	err     string
+}
+
+// generatedName is one expansion of a pattern and the bracket values in it.
+type generatedName struct {
+	hostname string
+	values   []string
+}
+
+// bracketValues expands the inside of one bracket.
+func bracketValues(body string) ([]string, error) {
+	body = strings.TrimSpace(body)
+	if strings.Contains(body, ",") {
+		var values []string
+		for _, v := range strings.Split(body, ",") {
+			if v = strings.TrimSpace(v); v == "" {
+				return nil, fmt.Errorf("[%s] has an empty entry", body)
+			}
+			values = append(values, v)
+		}
+		return values, nil
+	}
+	match := genRange.FindStringSubmatch(body)
+	if match == nil {
+		return nil, fmt.Errorf("cannot expand [%s]: use a range like 01..20 or a list like eu,us", body)
+	}
+	from, to := match[1], match[2]
+	if a, errA := strconv.Atoi(from); errA == nil {
+		b, errB := strconv.Atoi(to)
+		if errB != nil {
+			return nil, fmt.Errorf("cannot expand [%s]: both ends must be numbers", body)
+		}
+		if b < a {
+			return nil, fmt.Errorf("[%s] ends below its start", body)
+		}
+		if b-a+1 > maxGeneratedHosts {
+			return nil, fmt.Errorf("at most %d hosts at a time", maxGeneratedHosts)
+		}
+		width := 0
+		if strings.HasPrefix(from, "0") {
+			width = len(from)
+		}
+		values := make([]string, 0, b-a+1)
+		for n := a; n <= b; n++ {
+			values = append(values, padNumber(n, width))
+		}
+		return values, nil
+	}
+	if len(from) == 1 && len(to) == 1 && isLetter(from[0]) && isLetter(to[0]) {
+		if to[0] < from[0] {
+			return nil, fmt.Errorf("[%s] ends below its start", body)
+		}
+		var values []string
+		for c := from[0]; c <= to[0]; c++ {
+			values = append(values, string(c))
+		}
+		return values, nil
+	}
+	return nil, fmt.Errorf("cannot expand [%s]: use a range like 01..20 or a list like eu,us", body)
+}
+
+func isLetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+// expandHostPattern returns every hostname the pattern describes, the last
+// bracket varying fastest.
+func expandHostPattern(pattern string) ([]generatedName, error) {
+	pattern = strings.TrimSpace(pattern)
+	if pattern == "" {
+		return nil, fmt.Errorf("enter a pattern like web-[01..20].example.com")
+	}
+	locs := genBracket.FindAllStringSubmatchIndex(pattern, -1)
+	if len(locs) == 0 {
+		return nil, fmt.Errorf("add a range like [01..20] to the pattern")
+	}
+	if len(locs) > 9 {
+		return nil, fmt.Errorf("at most 9 ranges in a pattern")
+	}
+	names := []generatedName{{}}
+	last := 0
+	for _, loc := range locs {
+		values, err := bracketValues(pattern[loc[2]:loc[3]])
+		if err != nil {
+			return nil, err
+		}
+		if len(names)*len(values) > maxGeneratedHosts {
+			return nil, fmt.Errorf("at most %d hosts at a time", maxGeneratedHosts)
+		}
+		literal := pattern[last:loc[0]]
+		next := make([]generatedName, 0, len(names)*len(values))
+		for _, n := range names {
+			for _, v := range values {
+				next = append(next, generatedName{
+					hostname: n.hostname + literal + v,
+					values:   append(append([]string(nil), n.values...), v),
+				})
+			}
+		}
+		names, last = next, loc[1]
+	}
+	for i := range names {
+		names[i].hostname += pattern[last:]
+	}
+	return names, nil
+}
+
+// generatedAlias fills $1, $2, ... in template, or takes the first label of
+// the hostname when template is empty.
+func generatedAlias(template string, name generatedName) string {
+	template = strings.TrimSpace(template)
+	if template == "" {
+		if net.ParseIP(name.hostname) != nil {
+			return name.hostname
+		}
+		label, _, _ := strings.Cut(name.hostname, ".")
+		return label
+	}
+	return genAliasRef.ReplaceAllStringFunc(template, func(ref string) string {
+		i := int(ref[1] - '1')
+		if i < len(name.values) {
+			return name.values[i]
+		}
+		return ref
+	})
+}
+
+// planHostGenerator builds the hosts without touching hosts. Taken aliases
+// and aliases repeated within the batch are reported as an error.
+func planHostGenerator(pattern, aliasTemplate string, hosts []Host) ([]Host, error) {
+	names, err := expandHostPattern(pattern)
+	if err != nil {
+		return nil, err
+	}
+	generated := make([]Host, 0, len(names))
+	seen := map[string]bool{}
+	for _, name := range names {
+		if err := validateHostname(name.hostname); err != nil {
+			return nil, err
+		}
+		alias := generatedAlias(aliasTemplate, name)
+		key := strings.ToLower(alias)
+		if alias == "" || strings.ContainsAny(alias, " \t") {
+			return nil, fmt.Errorf("alias %q for %s is not usable", alias, name.hostname)
+		}
+		if aliasTaken(hosts, alias, "") || seen[key] {
+			return nil, fmt.Errorf("alias already exists: %s", alias)
+		}
+		seen[key] = true
+		generated = append(generated, Host{ID: newHostID(), Alias: alias, Hostname: name.hostname})
+	}
+	return generated, nil
+}
+
+func (m model) openHostGenerator() (tea.Model, tea.Cmd) {
+	m.clearListDeleteConfirm()
+	group := ""
+	switch item := m.list.SelectedItem().(type) {
+	case groupItem:
+		if idx := findGroupIndexByID(m.rawGroups, item.ID); idx != -1 && !m.rawGroups[idx].Smart() {
+			group = item.Name
+		}
+	case Host:
+		if idx := findGroupIndexByID(m.rawGroups, item.GroupID); idx != -1 {
+			group = m.rawGroups[idx].Name
+		}
+	}
+	m.hostGen = hostGeneratorState{
+		pattern: newPromptInput("  Pattern  ", "web-[01..20].prod.example.com"),
+		alias:   newPromptInput("  Alias    ", "(empty: first label, or web-$1)"),
+		group:   newPromptInput("  Group    ", "(none)"),
+	}
+	m.hostGen.group.SetValue(group)
+	m.state = stateHostGenerator
+	return m, m.hostGen.pattern.Focus()
+}
+
+func (s *hostGeneratorState) inputs() []*textinput.Model {
+	return []*textinput.Model{&s.pattern, &s.alias, &s.group}
+}
+
+func (m *model) focusHostGenerator(focus int) tea.Cmd {
+	m.hostGen.focus = focus
+	for _, input := range m.hostGen.inputs() {
+		input.Blur()
+	}
+	return m.hostGen.inputs()[focus].Focus()
+}
+
+func (m model) updateHostGenerator(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	s := &m.hostGen
+	switch msg.String() {
+	case "ctrl+c":
+		m.quitting = true
+		return m, tea.Quit
+	case "esc":
+		m.state = stateList
+		return m, nil
+	case "tab", "down":
+		return m, m.focusHostGenerator((s.focus + 1) % genFocusCount)
+	case "shift+tab", "up":
+		return m, m.focusHostGenerator((s.focus + genFocusCount - 1) % genFocusCount)
+	case "enter":
+		return m.applyHostGenerator()
+	}
+	var cmd tea.Cmd
+	input := s.inputs()[s.focus]
+	*input, cmd = input.Update(msg)
+	s.err = ""
+	return m, cmd
+}
+
+func (m model) applyHostGenerator() (tea.Model, tea.Cmd) {
+	s := &m.hostGen
+	generated, err := planHostGenerator(s.pattern.Value(), s.alias.Value(), m.rawHosts)
+	if err != nil {
+		s.err = err.Error()
+		return m, nil
+	}
+	snapshot := m.snapshot()
+	if name := strings.TrimSpace(s.group.Value()); name != "" {
+		idx := findGroupByName(m.rawGroups, name)
+		if idx != -1 && m.rawGroups[idx].Smart() {
+			s.err = fmt.Sprintf("%s is a smart group; hosts join it by matching its query", name)
+			return m, nil
+		}
+		if idx == -1 {
+			m.rawGroups = append(m.rawGroups, Group{ID: newGroupID(), Name: name, Expanded: true})
+			idx = len(m.rawGroups) - 1
+		}
+		for i := range generated {
+			generated[i].GroupID = m.rawGroups[idx].ID
+		}
+	}
+	m.rawHosts = append(m.rawHosts, generated...)
+	m.refreshList()
+	if err := m.save(); err != nil {
+		m.restoreSnapshot(snapshot)
+		s.err = fmt.Sprintf("failed to save hosts: %v", err)
+		return m, nil
+	}
+	m.reselectItem(generated[0].ID, false)
+	m.state = stateList
+	m.status.message = fmt.Sprintf("Generated %d hosts (%s … %s)", len(generated), generated[0].Alias, generated[len(generated)-1].Alias)
+	m.status.isError = false
+	m.status.version++
+	return m, statusClearCmd(m.status.version)
+}
+
+func (m model) renderHostGeneratorView() string {
+	width, height := normalizedSize(m.width, m.height)
+	inner := max(min(width-6, 100)-6, 24)
+	s := m.hostGen
+	var b strings.Builder
+	b.WriteString(lipgloss.NewStyle().Bold(true).Foreground(colorText).Render("GENERATE HOSTS") + "\n")
+	b.WriteString(formHintStyle.Render(ansi.Truncate("[01..20] ranges, [a..f] letters, [eu,us] lists · $1, $2 in the alias", inner, "…")) + "\n\n")
+	for _, input := range []textinput.Model{s.pattern, s.alias, s.group} {
+		input.Width = max(inner-lipgloss.Width(input.Prompt)-1, 1)
+		b.WriteString(ansi.Truncate(input.View(), inner, "…") + "\n")
+	}
+	b.WriteString("\n")
+
+	generated, err := planHostGenerator(s.pattern.Value(), s.alias.Value(), m.rawHosts)
+	maxRows := max(height-15, 2)
+	for i, h := range generated {
+		if i == maxRows {
+			b.WriteString(formHintStyle.Render(fmt.Sprintf("… %d more", len(generated)-maxRows)) + "\n")
+			break
+		}
+		b.WriteString(ansi.Truncate(fmt.Sprintf("  %-20s %s", h.Alias, h.Hostname), inner, "…") + "\n")
+	}
+	switch {
+	case s.err != "":
+		b.WriteString(testFailStyle.Render(ansi.Truncate("✘ "+s.err, inner, "…")) + "\n")
+	case err != nil && strings.TrimSpace(s.pattern.Value()) != "":
+		b.WriteString(testFailStyle.Render(ansi.Truncate("✘ "+err.Error(), inner, "…")) + "\n")
+	case len(generated) > 0:
+		b.WriteString(testSuccessStyle.Render(fmt.Sprintf("%d hosts to create", len(generated))) + "\n")
+	}
+	b.WriteString("\n" + helpEntry("tab", "next field") + "  " + helpEntry("enter", "create") + "  " + helpEntry("esc", "cancel"))
+	return centeredWorkspace(b.String(), width, height)
+}
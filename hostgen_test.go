@@ -0,0 +1,69 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestExpandHostPattern(t *testing.T) {
+	names, err := expandHostPattern("web-[08..10].[eu,us].example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, n := range names {
+		got = append(got, n.hostname)
+	}
+	want := "web-08.eu.example.com web-08.us.example.com web-09.eu.example.com web-09.us.example.com web-10.eu.example.com web-10.us.example.com"
+	if strings.Join(got, " ") != want {
+		t.Fatalf("got %v", got)
+	}
+	if letters, err := expandHostPattern("rack[a..c]"); err != nil || len(letters) != 3 || letters[2].hostname != "rackc" {
+		t.Fatalf("expected a letter range, got %+v %v", letters, err)
+	}
+	for _, bad := range []string{"", "web.example.com", "web-[10..1]", "web-[1..x]", "web-[1..999]", "db-[a,,b]"} {
+		if _, err := expandHostPattern(bad); err == nil {
+			t.Errorf("expected %q to be rejected", bad)
+		}
+	}
+}
+
+func TestPlanHostGeneratorAliases(t *testing.T) {
+	hosts := []Host{{ID: "h1", Alias: "web-02", Hostname: "old"}}
+	generated, err := planHostGenerator("web[1..3].prod.example.com", "", nil)
+	if err != nil || len(generated) != 3 || generated[0].Alias != "web1" || generated[0].Hostname != "web1.prod.example.com" {
+		t.Fatalf("expected first labels as aliases, got %+v %v", generated, err)
+	}
+	generated, err = planHostGenerator("node[03..04].[eu,us].example.com", "$2-node-$1", nil)
+	if err != nil || generated[1].Alias != "us-node-03" {
+		t.Fatalf("expected templated aliases, got %+v %v", generated, err)
+	}
+	if _, err := planHostGenerator("web[01..03].example.com", "web-$1", hosts); err == nil || !strings.Contains(err.Error(), "web-02") {
+		t.Fatalf("expected the taken alias reported, got %v", err)
+	}
+	if _, err := planHostGenerator("web[1..3].example.com", "web", nil); err == nil {
+		t.Fatal("expected a template without $1 to collide")
+	}
+}
+
+func TestHostGeneratorAddsToGroup(t *testing.T) {
+	writeTempConfig(t, nil)
+	groups := []Group{{ID: "g1", Name: "prod", Expanded: true}}
+	m := model{state: stateList, rawGroups: groups, list: newTestListModel(groups, nil), historyList: newTestHistoryListModel()}
+	result, _ := m.updateList(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("N")})
+	got := result.(model)
+	if got.state != stateHostGenerator || got.hostGen.group.Value() != "prod" {
+		t.Fatalf("expected the generator with the selected group, got state %v group %q", got.state, got.hostGen.group.Value())
+	}
+	got.hostGen.pattern.SetValue("db-[1..2].example.com")
+	if view := got.renderHostGeneratorView(); !strings.Contains(view, "db-2.example.com") || !strings.Contains(view, "2 hosts to create") {
+		t.Fatalf("expected a preview\n%s", view)
+	}
+	result, _ = got.updateHostGenerator(tea.KeyMsg{Type: tea.KeyEnter})
+	got = result.(model)
+	if got.state != stateList || len(got.rawHosts) != 2 || got.rawHosts[1].GroupID != "g1" {
+		t.Fatalf("expected two hosts in prod, got %+v (%s)", got.rawHosts, got.hostGen.err)
+	}
+}
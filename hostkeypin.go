@@ -0,0 +1,254 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// --- Host Key Pinning ---
+
+// p on the first-contact screen pins the server key fingerprints it fetched
+// to the host. From then on every SSH action in the TUI, and assho test and
+// assho connect, fetch the keys again with ssh-keyscan and compare them with
+// the pin before ssh runs. This is independent of known_hosts, so a changed
+// key is caught even when known_hosts was copied from another machine that
+// already trusts it. A mismatch stops the action and opens a red alert; the
+// pin only changes when it is pinned again from first contact.
+//
+// The scan alone would leave a gap: the server could swap its key between
+// the check and the connection. So the keys that passed are written to a
+// known_hosts file of the host's own, and ssh is told to trust that file and
+// nothing else, making ssh refuse any key that was not checked against the
+// pin.
+
+type hostKeyPinError struct {
+	hostID    string
+	alias     string
+	pinned    []string
+	presented []string
+}
+
+func (e *hostKeyPinError) Error() string {
+	return fmt.Sprintf("HOST KEY CHANGED: %s no longer presents its pinned key", e.alias)
+}
+
+var errPinBehindJump = errors.New("a pinned host key cannot be checked through a ProxyJump or ProxyCommand; unpin the host or reach it directly")
+
+// splitFingerprint splits "SHA256:abc (ED25519)" into its hash and key type.
+func splitFingerprint(fp string) (hash, keyType string) {
+	hash, keyType, _ = strings.Cut(strings.TrimSpace(fp), " ")
+	return hash, strings.Trim(keyType, "()")
+}
+
+// pinMatches reports whether the presented keys agree with the pinned ones:
+// at least one key type is in both, and each such type has the pinned hash.
+// A server that adds or retires a key type still matches.
+func pinMatches(pinned, presented []string) bool {
+	byType := make(map[string]string, len(pinned))
+	for _, fp := range pinned {
+		hash, keyType := splitFingerprint(fp)
+		byType[keyType] = hash
+	}
+	shared := false
+	for _, fp := range presented {
+		hash, keyType := splitFingerprint(fp)
+		want, ok := byType[keyType]
+		if !ok {
+			continue
+		}
+		if hash != want {
+			return false
+		}
+		shared = true
+	}
+	return shared
+}
+
+// verifyHostKeyPin fetches h's host keys and checks them against its pin.
+// Hosts without a pin pass without a scan.
+func verifyHostKeyPin(h Host) error {
+	if len(h.HostKeyPin) == 0 || h.isLocal() {
+		return nil
+	}
+	if h.behindProxy() {
+		return errPinBehindJump
+	}
+	keys, err := keyscanHost(h)
+	if err != nil {
+		return fmt.Errorf("could not check the pinned host key: %w", err)
+	}
+	presented, err := fingerprintKeys(keys)
+	if err != nil {
+		return fmt.Errorf("could not check the pinned host key: %w", err)
+	}
+	if !pinMatches(h.HostKeyPin, presented) {
+		return &hostKeyPinError{hostID: h.ID, alias: h.Alias, pinned: h.HostKeyPin, presented: presented}
+	}
+	if err := writePinnedKnownHosts(h, keys); err != nil {
+		return fmt.Errorf("could not save the pinned host key: %w", err)
+	}
+	return nil
+}
+
+// pinnedKnownHostsPath is the known_hosts file ssh checks h's key against
+// while h is pinned.
+func pinnedKnownHostsPath(h Host) string {
+	return filepath.Join(filepath.Dir(getConfigPath()), "pinned", h.ID+".known_hosts")
+}
+
+// writePinnedKnownHosts keeps the scanned keys whose fingerprint matches h's
+// pin in h's pinned known_hosts file.
+func writePinnedKnownHosts(h Host, keys []byte) error {
+	var kept []string
+	for _, line := range strings.Split(string(keys), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fingerprints, err := fingerprintKeys([]byte(line + "\n"))
+		if err != nil {
+			return err
+		}
+		if pinMatches(h.HostKeyPin, fingerprints) {
+			kept = append(kept, line)
+		}
+	}
+	if len(kept) == 0 {
+		return errors.New("no scanned key matches the pin")
+	}
+	path := pinnedKnownHostsPath(h)
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(strings.Join(kept, "\n")+"\n"), 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// pinnedKnownHostsArgs points ssh at h's pinned known_hosts file only, so a
+// key that did not pass verifyHostKeyPin is refused. Unpinned hosts get none.
+func pinnedKnownHostsArgs(h Host) []string {
+	if len(h.HostKeyPin) == 0 || h.isLocal() {
+		return nil
+	}
+	// ssh splits option values on spaces unless they are quoted.
+	return []string{
+		"-o", "StrictHostKeyChecking=yes",
+		"-o", `UserKnownHostsFile="` + pinnedKnownHostsPath(h) + `"`,
+		"-o", "GlobalKnownHostsFile=/dev/null",
+	}
+}
+
+// pinFirstContactKeys pins the fingerprints on the first-contact screen, or
+// removes the pin when unpin is set.
+func (m model) pinFirstContactKeys(unpin bool) (tea.Model, tea.Cmd) {
+	fc := &m.firstContact
+	idx := findHostIndexByID(m.rawHosts, fc.hostID)
+	if idx == -1 {
+		fc.errorText = "host no longer exists"
+		return m, nil
+	}
+	pin := slices.Clone(fc.fingerprints)
+	if unpin {
+		pin = nil
+	} else if len(pin) == 0 {
+		return m, nil
+	}
+	snapshot := m.snapshot()
+	if unpin {
+		os.Remove(pinnedKnownHostsPath(m.rawHosts[idx]))
+	}
+	m.rawHosts[idx].HostKeyPin = pin
+	if err := m.save(); err != nil {
+		m.restoreSnapshot(snapshot)
+		fc.errorText = fmt.Sprintf("failed to save pin: %v", err)
+		return m, nil
+	}
+	fc.errorText = ""
+	m.refreshList()
+	return m, nil
+}
+
+// pinStatusLine describes how the fetched keys compare with h's pin.
+func pinStatusLine(h Host, fingerprints []string) string {
+	switch {
+	case len(h.HostKeyPin) == 0:
+		return ""
+	case len(fingerprints) == 0:
+		return formHintStyle.Render("pinned: " + strings.Join(h.HostKeyPin, ", "))
+	case pinMatches(h.HostKeyPin, fingerprints):
+		return testSuccessStyle.Render("✔ matches the pinned key")
+	}
+	return testFailStyle.Bold(true).Render("✘ DIFFERS from the pinned key")
+}
+
+// pinHelp offers to pin the fetched keys, or to drop an existing pin.
+func pinHelp(h Host, fingerprints []string) string {
+	var help string
+	if len(fingerprints) > 0 && !slices.Equal(h.HostKeyPin, fingerprints) {
+		label := "pin key"
+		if len(h.HostKeyPin) > 0 {
+			label = "re-pin key"
+		}
+		help += "  " + helpEntry("p", label)
+	}
+	if len(h.HostKeyPin) > 0 {
+		help += "  " + helpEntry("u", "unpin")
+	}
+	return help
+}
+
+func (m model) updateHostKeyAlert(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		m.quitting = true
+		return m, tea.Quit
+	case "esc", "enter", "q":
+		m.hostKeyAlert = nil
+	case "f":
+		alert := m.hostKeyAlert
+		m.hostKeyAlert = nil
+		if idx := findHostIndexByID(m.rawHosts, alert.hostID); idx != -1 {
+			return m.openFirstContact(m.rawHosts[idx])
+		}
+	}
+	return m, nil
+}
+
+func (m model) renderHostKeyAlert(base string) string {
+	width, height := normalizedSize(m.width, m.height)
+	alert := m.hostKeyAlert
+	modalWidth := min(76, max(width-6, 30))
+	inner := modalWidth - 6
+	var b strings.Builder
+	b.WriteString(testFailStyle.Bold(true).Render("HOST KEY CHANGED · "+alert.alias) + "\n\n")
+	b.WriteString(ansi.Wrap("The server is not presenting the key pinned for this host. Someone may be intercepting the connection, or the server was reinstalled. No credentials were sent.", inner, "") + "\n\n")
+	b.WriteString(formSectionStyle.Render("Pinned") + "\n")
+	for _, fp := range alert.pinned {
+		b.WriteString(ansi.Truncate("  "+fp, inner, "…") + "\n")
+	}
+	b.WriteString(formSectionStyle.Render("Presented now") + "\n")
+	for _, fp := range alert.presented {
+		b.WriteString(testFailStyle.Render(ansi.Truncate("  "+fp, inner, "…")) + "\n")
+	}
+	b.WriteString("\n" + formHintStyle.Render(ansi.Wrap("If the change is expected, confirm the new fingerprint with the server console and pin it again from first contact.", inner, "")) + "\n\n")
+	b.WriteString(helpEntry("f", "first contact") + "  " + helpEntry("esc", "dismiss"))
+	modal := lipgloss.NewStyle().
+		Border(lipgloss.ThickBorder()).
+		BorderForeground(colorDanger).
+		Padding(1, 2).
+		Width(modalWidth).
+		Render(b.String())
+	backdrop := fitViewToBounds(dimBase(base), width, height)
+	return fitViewToBounds(overlayCenter(backdrop, modal, width, height), width, height)
+}
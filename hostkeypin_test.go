@@ -0,0 +1,137 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestPinMatches(t *testing.T) {
+	pinned := []string{"SHA256:abc (ED25519)", "SHA256:def (RSA)"}
+	cases := []struct {
+		presented []string
+		want      bool
+	}{
+		{[]string{"SHA256:abc (ED25519)", "SHA256:def (RSA)"}, true},
+		{[]string{"SHA256:abc (ED25519)"}, true},                       // RSA retired
+		{[]string{"SHA256:abc (ED25519)", "SHA256:ghi (ECDSA)"}, true}, // ECDSA added
+		{[]string{"SHA256:xyz (ED25519)", "SHA256:def (RSA)"}, false},  // ED25519 replaced
+		{[]string{"SHA256:ghi (ECDSA)"}, false},                        // nothing in common
+		{nil, false},
+	}
+	for _, c := range cases {
+		if got := pinMatches(pinned, c.presented); got != c.want {
+			t.Errorf("pinMatches(%v) = %v, want %v", c.presented, got, c.want)
+		}
+	}
+}
+
+func TestVerifyHostKeyPinSkipsUnpinnedAndRefusesJumps(t *testing.T) {
+	if err := verifyHostKeyPin(Host{Hostname: "10.0.0.1"}); err != nil {
+		t.Fatalf("an unpinned host should pass without a scan, got %v", err)
+	}
+	pinned := Host{Hostname: "10.0.0.1", ProxyJump: "bastion", HostKeyPin: []string{"SHA256:abc (ED25519)"}}
+	if err := verifyHostKeyPin(pinned); !errors.Is(err, errPinBehindJump) {
+		t.Fatalf("expected the ProxyJump error, got %v", err)
+	}
+}
+
+func TestPinMismatchOpensAlertAndFailsAction(t *testing.T) {
+	hosts := []Host{{ID: "h1", Alias: "web", Hostname: "10.0.0.1", HostKeyPin: []string{"SHA256:abc (ED25519)"}}}
+	m := model{rawHosts: hosts, list: newTestListModel(nil, hosts), width: 100, height: 30}
+	pinErr := &hostKeyPinError{hostID: "h1", alias: "web", pinned: hosts[0].HostKeyPin, presented: []string{"SHA256:evil (ED25519)"}}
+
+	updated, cmd := m.handleHostTrustCheck(hostTrustCheckMsg{action: pendingSSHAction{kind: sshActionConnect, host: hosts[0], trustHost: hosts[0]}, err: pinErr})
+	m = updated.(model)
+	if m.hostKeyAlert == nil {
+		t.Fatal("a pin mismatch should open the alert")
+	}
+	if msg, ok := cmd().(hostTrustActionFailedMsg); !ok || !strings.Contains(msg.err.Error(), "HOST KEY CHANGED") {
+		t.Fatalf("the connection should fail, got %#v", cmd())
+	}
+	view := ansi.Strip(m.View())
+	for _, want := range []string{"HOST KEY CHANGED · web", "SHA256:abc (ED25519)", "SHA256:evil (ED25519)"} {
+		if !strings.Contains(view, want) {
+			t.Fatalf("alert missing %q:\n%s", want, view)
+		}
+	}
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if updated.(model).hostKeyAlert != nil {
+		t.Fatal("esc should dismiss the alert")
+	}
+
+	m.hostKeyAlert = nil
+	updated, _ = m.handleHostTrustCheck(hostTrustCheckMsg{action: pendingSSHAction{kind: sshActionScan, host: hosts[0], trustHost: hosts[0], background: true}, err: pinErr})
+	if updated.(model).hostKeyAlert != nil {
+		t.Fatal("background refreshes should not pop the alert")
+	}
+}
+
+func TestFirstContactPinsAndUnpinsKeys(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("ASSHO_STORE_PASSWORD", "0")
+	hosts := []Host{{ID: "h1", Alias: "web", Hostname: "10.0.0.1"}}
+	m := model{rawHosts: hosts, list: newTestListModel(nil, hosts), historyList: newTestHistoryListModel(), state: stateFirstContact}
+	m.firstContact = firstContactState{hostID: "h1", phase: firstContactFingerprint, fingerprints: []string{"SHA256:abc (ED25519)"}}
+
+	updated, _ := m.updateFirstContact(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("p")})
+	m = updated.(model)
+	if strings.Join(m.rawHosts[0].HostKeyPin, ",") != "SHA256:abc (ED25519)" {
+		t.Fatalf("expected the key to be pinned, got %v", m.rawHosts[0].HostKeyPin)
+	}
+	if _, saved, _, err := loadConfig(); err != nil || len(saved) != 1 || len(saved[0].HostKeyPin) != 1 {
+		t.Fatalf("expected the pin to be saved, got %v, %v", saved, err)
+	}
+	updated, _ = m.updateFirstContact(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("u")})
+	if pin := updated.(model).rawHosts[0].HostKeyPin; pin != nil {
+		t.Fatalf("expected the pin to be removed, got %v", pin)
+	}
+}
+
+func TestPinnedKnownHostsKeepsOnlyPinnedKeys(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	line := func(name string) string {
+		path := filepath.Join(home, name)
+		if output, err := exec.Command("ssh-keygen", "-q", "-t", "ed25519", "-N", "", "-f", path).CombinedOutput(); err != nil {
+			t.Fatalf("generate key: %v (%s)", err, output)
+		}
+		pub, err := os.ReadFile(path + ".pub")
+		if err != nil {
+			t.Fatal(err)
+		}
+		fields := strings.Fields(string(pub))
+		return "10.0.0.1 " + fields[0] + " " + fields[1]
+	}
+	pinnedKey, otherKey := line("pinned"), line("other")
+	pin, err := fingerprintKeys([]byte(pinnedKey + "\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := Host{ID: "h1", Alias: "web", Hostname: "10.0.0.1", HostKeyPin: pin}
+
+	if err := writePinnedKnownHosts(h, []byte("# 10.0.0.1:22 SSH-2.0-OpenSSH\n"+otherKey+"\n"+pinnedKey+"\n")); err != nil {
+		t.Fatal(err)
+	}
+	saved, err := os.ReadFile(pinnedKnownHostsPath(h))
+	if err != nil || string(saved) != pinnedKey+"\n" {
+		t.Fatalf("expected only the pinned key saved, got %q (%v)", saved, err)
+	}
+	if err := writePinnedKnownHosts(h, []byte(otherKey+"\n")); err == nil {
+		t.Fatal("expected an error when no scanned key matches the pin")
+	}
+
+	args := strings.Join(buildTrustedSSHArgs(h, false, ""), " ")
+	if !strings.Contains(args, `UserKnownHostsFile="`+pinnedKnownHostsPath(h)+`"`) || !strings.Contains(args, "GlobalKnownHostsFile=/dev/null") {
+		t.Fatalf("expected ssh pointed at the pinned keys, got %s", args)
+	}
+	if args := strings.Join(buildTrustedSSHArgs(Host{Hostname: "10.0.0.2"}, false, ""), " "); strings.Contains(args, "UserKnownHostsFile") {
+		t.Fatalf("an unpinned host should keep its known_hosts, got %s", args)
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// --- Identity Agent ---
+
+// A host's IdentityAgent names the agent socket ssh takes keys from for it,
+// such as 1Password's or Secretive's, or "none" to use no agent, in place of
+// SSH_AUTH_SOCK. It is imported from ~/.ssh/config along with ProxyJump, so a
+// bastion set up there keeps its keys after import, is passed to every ssh
+// assho runs for the host as -o IdentityAgent=..., and is written back by
+// `assho export`. ssh expands ~, ${VAR}, and %-tokens in it itself.
+
+// identityAgentArgs are the ssh options that point h at its agent.
+func identityAgentArgs(h Host) []string {
+	if h.IdentityAgent == "" {
+		return nil
+	}
+	return []string{"-o", "IdentityAgent=" + quoteSSHOption(h.IdentityAgent)}
+}
+
+// quoteSSHOption double-quotes a value containing spaces, such as the
+// "Group Containers" in 1Password's socket path, so ssh reads it as one.
+func quoteSSHOption(value string) string {
+	if strings.ContainsAny(value, " \t") {
+		return `"` + value + `"`
+	}
+	return value
+}
+
+// unquoteSSHOption strips the double quotes ssh_config allows around a value.
+func unquoteSSHOption(value string) string {
+	if len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"' {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
+
+// validateIdentityAgent checks a host's IdentityAgent field.
+func validateIdentityAgent(value string) error {
+	if strings.ContainsAny(value, "\"\r\n") {
+		return fmt.Errorf("identity agent must be a single line without quotes")
+	}
+	return nil
+}
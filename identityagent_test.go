@@ -0,0 +1,66 @@
+package main
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSSHConfigIdentityAgentRoundTrip(t *testing.T) {
+	path := writeTempSSHConfig(t, `
+Host app
+    HostName app.internal
+    ProxyJump bastion
+
+Host bastion
+    HostName bastion.example.com
+
+Host plain
+    IdentityAgent SSH_AUTH_SOCK
+
+Host *
+    IdentityAgent "~/Library/Group Containers/2BUA8C4S2C.com.1password/t/agent.sock"
+`)
+	hosts, err := parseSSHConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const agent = "~/Library/Group Containers/2BUA8C4S2C.com.1password/t/agent.sock"
+	if len(hosts) != 3 || hosts[0].ProxyJump != "bastion" || hosts[0].IdentityAgent != agent || hosts[1].IdentityAgent != agent {
+		t.Fatalf("expected the jump and the wildcard's agent imported, got %+v", hosts)
+	}
+	if hosts[2].IdentityAgent != "" {
+		t.Fatalf("expected SSH_AUTH_SOCK left as the default, got %q", hosts[2].IdentityAgent)
+	}
+
+	var buf bytes.Buffer
+	fprintSSHConfig(&buf, hosts[:1])
+	if out := buf.String(); !strings.Contains(out, `    IdentityAgent "`+agent+`"`+"\n") {
+		t.Fatalf("expected the agent exported quoted, got:\n%s", out)
+	}
+}
+
+func TestSSHArgsPassIdentityAgent(t *testing.T) {
+	h := Host{Alias: "app", Hostname: "app.internal", IdentityAgent: "~/.1password/agent.sock"}
+	args := buildSSHArgs(h, false, "")
+	if i := slices.Index(args, "IdentityAgent=~/.1password/agent.sock"); i < 1 || args[i-1] != "-o" {
+		t.Fatalf("expected -o IdentityAgent=... in %q", args)
+	}
+	h.IdentityAgent = "/Users/me/Group Containers/agent.sock"
+	if got := identityAgentArgs(h); got[1] != `IdentityAgent="/Users/me/Group Containers/agent.sock"` {
+		t.Fatalf("expected a path with spaces quoted for ssh, got %q", got)
+	}
+}
+
+func TestReimportOffersIdentityAgentChange(t *testing.T) {
+	changes := diffImportedHost(Host{Alias: "app"}, Host{Alias: "app", IdentityAgent: "~/.1password/agent.sock"})
+	if len(changes) != 1 || changes[0].field != "IdentityAgent" {
+		t.Fatalf("expected the new agent offered, got %+v", changes)
+	}
+	var h Host
+	applyImportField(&h, changes[0].field, changes[0].new)
+	if h.IdentityAgent != "~/.1password/agent.sock" {
+		t.Fatalf("expected the agent applied, got %q", h.IdentityAgent)
+	}
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// --- Import Merge ---
+
+// Re-importing ~/.ssh/config adds new aliases straight away. Aliases that
+// already exist but whose HostName, User, Port, IdentityFile, ProxyJump, or
+// IdentityAgent changed open a review screen where each difference can be accepted or left
+// alone.
+
+type importFieldChange struct {
+	field    string // ssh_config keyword, e.g. "User"
+	old, new string
+	accept   bool
+}
+
+type importChange struct {
+	hostID string
+	alias  string
+	fields []importFieldChange
+}
+
+// diffImportedHost compares the fields ssh_config can carry. A blank imported
+// value is not a change, since an unset keyword and a removed one look the
+// same.
+func diffImportedHost(existing, imported Host) []importFieldChange {
+	var changes []importFieldChange
+	for _, f := range []struct{ field, old, new string }{
+		{"HostName", existing.Hostname, imported.Hostname},
+		{"User", existing.User, imported.User},
+		{"Port", existing.Port, imported.Port},
+		{"IdentityFile", existing.IdentityFile, imported.IdentityFile},
+		{"ProxyJump", existing.ProxyJump, imported.ProxyJump},
+		{"IdentityAgent", existing.IdentityAgent, imported.IdentityAgent},
+	} {
+		if f.new != "" && f.new != f.old && !(f.field == "Port" && f.old == "" && f.new == "22") {
+			changes = append(changes, importFieldChange{field: f.field, old: f.old, new: f.new})
+		}
+	}
+	return changes
+}
+
+func applyImportField(h *Host, field, value string) {
+	switch field {
+	case "HostName":
+		h.Hostname = value
+	case "User":
+		h.User = value
+	case "Port":
+		h.Port = value
+	case "IdentityFile":
+		h.IdentityFile = value
+	case "ProxyJump":
+		h.ProxyJump = value
+	case "IdentityAgent":
+		h.IdentityAgent = value
+	}
+}
+
+type importMergeState struct {
+	changes []importChange
+	cursor  int // index into rows()
+}
+
+type importMergeRow struct{ change, field int }
+
+// rows flattens the per-host changes into the selectable field lines.
+func (s importMergeState) rows() []importMergeRow {
+	var rows []importMergeRow
+	for i, c := range s.changes {
+		for j := range c.fields {
+			rows = append(rows, importMergeRow{change: i, field: j})
+		}
+	}
+	return rows
+}
+
+func (m model) openImportMerge(changes []importChange) model {
+	m.importMerge = importMergeState{changes: changes}
+	m.state = stateImportMerge
+	return m
+}
+
+func (m model) updateImportMerge(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	rows := m.importMerge.rows()
+	switch msg.String() {
+	case "ctrl+c":
+		m.quitting = true
+		return m, tea.Quit
+	case "esc", "q":
+		m.state = stateList
+		m.status.message = "Left existing hosts unchanged"
+		m.status.isError = false
+		m.status.version++
+		return m, statusClearCmd(m.status.version)
+	case "up", "k":
+		if m.importMerge.cursor > 0 {
+			m.importMerge.cursor--
+		}
+	case "down", "j":
+		if m.importMerge.cursor < len(rows)-1 {
+			m.importMerge.cursor++
+		}
+	case " ", "x":
+		if m.importMerge.cursor < len(rows) {
+			r := rows[m.importMerge.cursor]
+			f := &m.importMerge.changes[r.change].fields[r.field]
+			f.accept = !f.accept
+		}
+	case "a":
+		all := true
+		for _, r := range rows {
+			all = all && m.importMerge.changes[r.change].fields[r.field].accept
+		}
+		for _, r := range rows {
+			m.importMerge.changes[r.change].fields[r.field].accept = !all
+		}
+	case "enter":
+		return m.applyImportMerge()
+	}
+	return m, nil
+}
+
+func (m model) applyImportMerge() (tea.Model, tea.Cmd) {
+	snapshot := m.snapshot()
+	fields, hosts := 0, 0
+	for _, c := range m.importMerge.changes {
+		idx := findHostIndexByID(m.rawHosts, c.hostID)
+		if idx == -1 {
+			continue
+		}
+		touched := false
+		for _, f := range c.fields {
+			if f.accept {
+				applyImportField(&m.rawHosts[idx], f.field, f.new)
+				fields++
+				touched = true
+			}
+		}
+		if touched {
+			hosts++
+		}
+	}
+	m.state = stateList
+	if fields == 0 {
+		m.status.message = "Left existing hosts unchanged"
+		m.status.isError = false
+		m.status.version++
+		return m, statusClearCmd(m.status.version)
+	}
+	m.refreshList()
+	if err := m.save(); err != nil {
+		m.restoreSnapshot(snapshot)
+		m.status.message = fmt.Sprintf("Failed to save import updates: %v", err)
+		m.status.isError = true
+		m.status.version++
+		return m, statusClearCmd(m.status.version)
+	}
+	m.status.message = fmt.Sprintf("Updated %d fields on %d hosts from ~/.ssh/config", fields, hosts)
+	m.status.isError = false
+	m.status.version++
+	return m, statusClearCmd(m.status.version)
+}
+
+func (m model) renderImportMergeView() string {
+	width, height := normalizedSize(m.width, m.height)
+	inner := max(min(width-6, 100)-6, 24)
+	var b strings.Builder
+	b.WriteString(lipgloss.NewStyle().Bold(true).Foreground(colorText).Render("REVIEW IMPORT CHANGES") + "\n")
+	b.WriteString(formHintStyle.Render(ansi.Truncate(fmt.Sprintf("%d existing hosts differ from ~/.ssh/config", len(m.importMerge.changes)), inner, "…")) + "\n\n")
+
+	// Keep the cursor in view: each host adds a header line above its fields.
+	maxLines := max(height-12, 4)
+	var lines []string
+	cursorLine := 0
+	row := 0
+	for _, c := range m.importMerge.changes {
+		lines = append(lines, formSectionStyle.Render(ansi.Truncate(c.alias, inner, "…")))
+		for _, f := range c.fields {
+			mark := "[ ]"
+			if f.accept {
+				mark = "[x]"
+			}
+			old := f.old
+			if old == "" {
+				old = "—"
+			}
+			label := fmt.Sprintf("%s %-12s %s → %s", mark, f.field, old, f.new)
+			if row == m.importMerge.cursor {
+				cursorLine = len(lines)
+			}
+			lines = append(lines, selectionLine(row == m.importMerge.cursor, ansi.Truncate(label, inner-2, "…")))
+			row++
+		}
+	}
+	start := 0
+	if cursorLine >= maxLines {
+		start = cursorLine - maxLines + 1
+	}
+	end := min(start+maxLines, len(lines))
+	b.WriteString(strings.Join(lines[start:end], "\n") + "\n")
+	if end < len(lines) {
+		b.WriteString(formHintStyle.Render(fmt.Sprintf("… %d more", len(lines)-end)) + "\n")
+	}
+	b.WriteString("\n" + helpEntry("space", "toggle") + "  " + helpEntry("a", "all") + "  " + helpEntry("enter", "apply") + "  " + helpEntry("esc", "skip"))
+	return centeredWorkspace(b.String(), width, height)
+}
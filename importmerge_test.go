@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestImportSSHConfigReportsChangedHosts(t *testing.T) {
+	writeSSHConfigInHome(t, `
+Host web
+    HostName 10.0.0.9
+    User deploy
+
+Host db
+    HostName 10.0.0.2
+`)
+	existing := []Host{
+		{ID: "h1", Alias: "web", Hostname: "10.0.0.1", User: "root", Port: "22"},
+		{ID: "h2", Alias: "db", Hostname: "10.0.0.2", User: "admin", Port: "22"},
+	}
+	imported, changed, skipped, err := importSSHConfig(existing)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(imported) != 0 || skipped != 1 || len(changed) != 1 {
+		t.Fatalf("expected web changed and db skipped, got %d imported, %d changed, %d skipped", len(imported), len(changed), skipped)
+	}
+	c := changed[0]
+	if c.hostID != "h1" || len(c.fields) != 2 || c.fields[0].field != "HostName" || c.fields[1].new != "deploy" {
+		t.Fatalf("unexpected change %+v", c)
+	}
+}
+
+func TestImportMergeAppliesAcceptedFields(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("ASSHO_STORE_PASSWORD", "0")
+	hosts := []Host{{ID: "h1", Alias: "web", Hostname: "10.0.0.1", User: "root"}}
+	m := model{rawHosts: hosts, list: newTestListModel(nil, hosts), historyList: newTestHistoryListModel()}
+	m = m.openImportMerge([]importChange{{hostID: "h1", alias: "web", fields: []importFieldChange{
+		{field: "HostName", old: "10.0.0.1", new: "10.0.0.9"},
+		{field: "User", old: "root", new: "deploy"},
+	}}})
+
+	updated, _ := m.updateImportMerge(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("a")})
+	updated, _ = updated.(model).updateImportMerge(tea.KeyMsg{Type: tea.KeyDown})
+	updated, _ = updated.(model).updateImportMerge(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")})
+	updated, _ = updated.(model).updateImportMerge(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(model)
+	if m.state != stateList {
+		t.Fatalf("expected to return to the list, got state %v", m.state)
+	}
+	if h := m.rawHosts[0]; h.Hostname != "10.0.0.9" || h.User != "root" {
+		t.Fatalf("expected only the hostname updated, got %+v", h)
+	}
+	if !strings.Contains(m.status.message, "Updated 1 fields on 1 hosts") {
+		t.Fatalf("unexpected status %q", m.status.message)
+	}
+}
+
+func TestImportMergeViewFitsTerminal(t *testing.T) {
+	var changes []importChange
+	for i := range 12 {
+		changes = append(changes, importChange{hostID: fmt.Sprint(i), alias: fmt.Sprintf("very-long-imported-alias-%02d", i), fields: []importFieldChange{
+			{field: "HostName", old: "old-hostname.internal.example.com", new: "new-hostname.internal.example.com", accept: i%2 == 0},
+			{field: "IdentityFile", new: "~/.ssh/id_ed25519_work"},
+		}})
+	}
+	for _, size := range []struct{ width, height int }{{40, 16}, {80, 24}, {140, 40}} {
+		m := model{width: size.width, height: size.height, importMerge: importMergeState{changes: changes, cursor: 20}}
+		out := m.renderImportMergeView()
+		lines := strings.Split(out, "\n")
+		if len(lines) > size.height {
+			t.Fatalf("%dx%d: got %d lines", size.width, size.height, len(lines))
+		}
+		for i, line := range lines {
+			if ansi.StringWidth(line) > size.width {
+				t.Fatalf("%dx%d line %d has width %d", size.width, size.height, i, ansi.StringWidth(line))
+			}
+		}
+		if !strings.Contains(out, "› ") {
+			t.Fatalf("%dx%d: expected the cursor row to stay visible", size.width, size.height)
+		}
+	}
+}
@@ -0,0 +1,242 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// --- Integrity Check ---
+
+// Hand-edited or half-written config files can leave records the list cannot
+// place: hosts in a group that no longer exists, containers saved without
+// their parent, and history for hosts that were deleted. Startup looks for
+// them and opens a repair screen instead of silently hiding the hosts.
+
+type integrityKind int
+
+const (
+	integrityMissingGroup integrityKind = iota
+	integrityOrphanContainer
+	integrityStaleHistory
+)
+
+type integrityIssue struct {
+	kind   integrityKind
+	hostID string
+	alias  string
+	detail string
+	fix    bool
+}
+
+// fixLabel says what applying the issue will do.
+func (i integrityIssue) fixLabel() string {
+	switch i.kind {
+	case integrityMissingGroup:
+		return "move to ungrouped"
+	case integrityOrphanContainer:
+		return "remove (rediscovered from its host)"
+	default:
+		return "drop history"
+	}
+}
+
+// findIntegrityIssues lists the records flattenHosts and the history view
+// cannot attach to anything. Everything is marked to be fixed by default.
+func findIntegrityIssues(groups []Group, hosts []Host, history []HistoryEntry) []integrityIssue {
+	groupByID := make(map[string]Group, len(groups))
+	for _, g := range groups {
+		groupByID[g.ID] = g
+	}
+	known := make(map[string]bool, len(hosts))
+	var issues []integrityIssue
+	for _, h := range hosts {
+		known[h.ID] = true
+		for _, c := range h.Containers {
+			known[c.ID] = true
+		}
+		if h.IsContainer {
+			issues = append(issues, integrityIssue{kind: integrityOrphanContainer, hostID: h.ID, alias: h.Alias, detail: "container without a parent host", fix: true})
+			continue
+		}
+		if h.GroupID == "" {
+			continue
+		}
+		g, ok := groupByID[h.GroupID]
+		switch {
+		case !ok:
+			issues = append(issues, integrityIssue{kind: integrityMissingGroup, hostID: h.ID, alias: h.Alias, detail: "group " + h.GroupID + " does not exist", fix: true})
+		case g.Smart():
+			// Smart groups hold no hosts of their own, so the host never renders.
+			issues = append(issues, integrityIssue{kind: integrityMissingGroup, hostID: h.ID, alias: h.Alias, detail: "group " + g.Name + " is a smart group", fix: true})
+		}
+	}
+	stale := map[string]int{}
+	var order []string
+	aliases := map[string]string{}
+	for _, e := range history {
+		if known[e.HostID] {
+			continue
+		}
+		if stale[e.HostID] == 0 {
+			order = append(order, e.HostID)
+			aliases[e.HostID] = e.Alias
+		}
+		stale[e.HostID]++
+	}
+	for _, id := range order {
+		issues = append(issues, integrityIssue{kind: integrityStaleHistory, hostID: id, alias: aliases[id], detail: fmt.Sprintf("%d history entries for a deleted host", stale[id]), fix: true})
+	}
+	return issues
+}
+
+type repairState struct {
+	issues []integrityIssue
+	cursor int
+}
+
+func (m model) openRepair(issues []integrityIssue) model {
+	m.repair = repairState{issues: issues}
+	m.state = stateRepair
+	return m
+}
+
+func (m model) updateRepair(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		m.quitting = true
+		return m, tea.Quit
+	case "esc", "q":
+		m.state = stateList
+		m.status.message = fmt.Sprintf("Left %d integrity issues unrepaired; they will be shown again next start", len(m.repair.issues))
+		m.status.isError = false
+		m.status.version++
+		return m, statusClearCmd(m.status.version)
+	case "up", "k":
+		if m.repair.cursor > 0 {
+			m.repair.cursor--
+		}
+	case "down", "j":
+		if m.repair.cursor < len(m.repair.issues)-1 {
+			m.repair.cursor++
+		}
+	case " ", "x":
+		if m.repair.cursor < len(m.repair.issues) {
+			m.repair.issues[m.repair.cursor].fix = !m.repair.issues[m.repair.cursor].fix
+		}
+	case "a":
+		all := true
+		for _, i := range m.repair.issues {
+			all = all && i.fix
+		}
+		for i := range m.repair.issues {
+			m.repair.issues[i].fix = !all
+		}
+	case "enter":
+		return m.applyRepair()
+	}
+	return m, nil
+}
+
+func (m model) applyRepair() (tea.Model, tea.Cmd) {
+	snapshot := m.snapshot()
+	regroup := map[string]bool{}
+	dropHost := map[string]bool{}
+	dropHistory := map[string]bool{}
+	fixed := 0
+	for _, i := range m.repair.issues {
+		if !i.fix {
+			continue
+		}
+		fixed++
+		switch i.kind {
+		case integrityMissingGroup:
+			regroup[i.hostID] = true
+		case integrityOrphanContainer:
+			dropHost[i.hostID] = true
+		case integrityStaleHistory:
+			dropHistory[i.hostID] = true
+		}
+	}
+	m.state = stateList
+	if fixed == 0 {
+		m.status.message = fmt.Sprintf("Left %d integrity issues unrepaired; they will be shown again next start", len(m.repair.issues))
+		m.status.isError = false
+		m.status.version++
+		return m, statusClearCmd(m.status.version)
+	}
+
+	hosts := m.rawHosts[:0:0]
+	for _, h := range m.rawHosts {
+		if dropHost[h.ID] {
+			continue
+		}
+		if regroup[h.ID] {
+			h.GroupID = ""
+		}
+		hosts = append(hosts, h)
+	}
+	m.rawHosts = hosts
+	history := m.history[:0:0]
+	for _, e := range m.history {
+		if !dropHistory[e.HostID] {
+			history = append(history, e)
+		}
+	}
+	m.history = history
+	m.refreshList()
+	m.rebuildHistoryList()
+	m.refreshDelegate()
+	if err := m.save(); err != nil {
+		m.restoreSnapshot(snapshot)
+		m.status.message = fmt.Sprintf("Failed to save repairs: %v", err)
+		m.status.isError = true
+		m.status.version++
+		return m, statusClearCmd(m.status.version)
+	}
+	m.status.message = fmt.Sprintf("Repaired %d integrity issues", fixed)
+	if left := len(m.repair.issues) - fixed; left > 0 {
+		m.status.message += fmt.Sprintf(" · %d left as is", left)
+	}
+	m.status.isError = false
+	m.status.version++
+	return m, statusClearCmd(m.status.version)
+}
+
+func (m model) renderRepairView() string {
+	width, height := normalizedSize(m.width, m.height)
+	inner := max(min(width-6, 100)-6, 24)
+	var b strings.Builder
+	b.WriteString(lipgloss.NewStyle().Bold(true).Foreground(colorText).Render("REPAIR CONFIG") + "\n")
+	b.WriteString(formHintStyle.Render(ansi.Truncate(fmt.Sprintf("%d saved records cannot be placed", len(m.repair.issues)), inner, "…")) + "\n\n")
+
+	// Each issue takes two lines: the fix checkbox and what is wrong.
+	maxIssues := max((height-12)/2, 2)
+	start := 0
+	if m.repair.cursor >= maxIssues {
+		start = m.repair.cursor - maxIssues + 1
+	}
+	end := min(start+maxIssues, len(m.repair.issues))
+	for idx := start; idx < end; idx++ {
+		i := m.repair.issues[idx]
+		mark := "[ ]"
+		if i.fix {
+			mark = "[x]"
+		}
+		alias := i.alias
+		if alias == "" {
+			alias = i.hostID
+		}
+		label := fmt.Sprintf("%s %s · %s", mark, alias, i.fixLabel())
+		b.WriteString(selectionLine(idx == m.repair.cursor, ansi.Truncate(label, inner-2, "…")) + "\n")
+		b.WriteString(formHintStyle.Render(ansi.Truncate("      "+i.detail, inner, "…")) + "\n")
+	}
+	if end < len(m.repair.issues) {
+		b.WriteString(formHintStyle.Render(fmt.Sprintf("… %d more", len(m.repair.issues)-end)) + "\n")
+	}
+	b.WriteString("\n" + helpEntry("space", "toggle") + "  " + helpEntry("a", "all") + "  " + helpEntry("enter", "repair") + "  " + helpEntry("esc", "skip"))
+	return centeredWorkspace(b.String(), width, height)
+}
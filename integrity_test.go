@@ -0,0 +1,110 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestFindIntegrityIssues(t *testing.T) {
+	groups := []Group{{ID: "g1", Name: "prod"}, {ID: "s1", Name: "web", Query: "web"}}
+	hosts := []Host{
+		{ID: "h1", Alias: "ok", GroupID: "g1", Containers: []Host{{ID: "c1", Alias: "pg", IsContainer: true}}},
+		{ID: "h2", Alias: "lost", GroupID: "gone"},
+		{ID: "h3", Alias: "smart", GroupID: "s1"},
+		{ID: "c2", Alias: "stray", IsContainer: true},
+	}
+	history := []HistoryEntry{
+		{HostID: "h1", Alias: "ok"},
+		{HostID: "c1", Alias: "pg"},
+		{HostID: "x1", Alias: "deleted"},
+		{HostID: "x1", Alias: "deleted"},
+	}
+	issues := findIntegrityIssues(groups, hosts, history)
+	if len(issues) != 4 {
+		t.Fatalf("expected 4 issues, got %+v", issues)
+	}
+	want := []struct {
+		kind   integrityKind
+		hostID string
+	}{
+		{integrityMissingGroup, "h2"},
+		{integrityMissingGroup, "h3"},
+		{integrityOrphanContainer, "c2"},
+		{integrityStaleHistory, "x1"},
+	}
+	for i, w := range want {
+		if issues[i].kind != w.kind || issues[i].hostID != w.hostID || !issues[i].fix {
+			t.Fatalf("issue %d: expected %v for %s, got %+v", i, w.kind, w.hostID, issues[i])
+		}
+	}
+	if !strings.Contains(issues[3].detail, "2 history entries") {
+		t.Fatalf("expected the stale entries counted, got %q", issues[3].detail)
+	}
+	if len(findIntegrityIssues(groups, hosts[:1], history[:2])) != 0 {
+		t.Fatal("expected a clean config to have no issues")
+	}
+}
+
+func TestRepairAppliesSelectedFixes(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("ASSHO_STORE_PASSWORD", "0")
+	hosts := []Host{
+		{ID: "h1", Alias: "lost", GroupID: "gone"},
+		{ID: "c1", Alias: "stray", IsContainer: true},
+	}
+	history := []HistoryEntry{{HostID: "h1", Alias: "lost"}, {HostID: "x1", Alias: "deleted"}}
+	m := model{rawHosts: hosts, history: history, list: newTestListModel(nil, hosts), historyList: newTestHistoryListModel()}
+	m = m.openRepair(findIntegrityIssues(nil, hosts, history))
+
+	// Keep the stray container: move to it and untick it.
+	updated, _ := m.updateRepair(tea.KeyMsg{Type: tea.KeyDown})
+	updated, _ = updated.(model).updateRepair(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")})
+	updated, _ = updated.(model).updateRepair(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(model)
+	if m.state != stateList {
+		t.Fatalf("expected to return to the list, got state %v", m.state)
+	}
+	if len(m.rawHosts) != 2 || m.rawHosts[0].GroupID != "" {
+		t.Fatalf("expected the host ungrouped and the container kept, got %+v", m.rawHosts)
+	}
+	if len(m.history) != 1 || m.history[0].HostID != "h1" {
+		t.Fatalf("expected the deleted host's history dropped, got %+v", m.history)
+	}
+	if !strings.Contains(m.status.message, "Repaired 2 integrity issues · 1 left as is") {
+		t.Fatalf("unexpected status %q", m.status.message)
+	}
+	_, saved, savedHistory, err := loadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(saved) != 2 || saved[0].GroupID != "" || len(savedHistory) != 1 {
+		t.Fatalf("expected repairs saved, got %+v and %+v", saved, savedHistory)
+	}
+}
+
+func TestRepairViewFitsTerminal(t *testing.T) {
+	var issues []integrityIssue
+	for i := range 12 {
+		issues = append(issues, integrityIssue{kind: integrityKind(i % 3), hostID: fmt.Sprint(i), alias: fmt.Sprintf("very-long-orphaned-host-alias-%02d", i), detail: "group 7f3a9c2e-5b1d-4e8f-a6c0-9d2b1e4f7a38 does not exist", fix: i%2 == 0})
+	}
+	for _, size := range []struct{ width, height int }{{40, 16}, {80, 24}, {140, 40}} {
+		m := model{width: size.width, height: size.height, repair: repairState{issues: issues, cursor: 10}}
+		out := m.renderRepairView()
+		lines := strings.Split(out, "\n")
+		if len(lines) > size.height {
+			t.Fatalf("%dx%d: got %d lines", size.width, size.height, len(lines))
+		}
+		for i, line := range lines {
+			if ansi.StringWidth(line) > size.width {
+				t.Fatalf("%dx%d line %d has width %d", size.width, size.height, i, ansi.StringWidth(line))
+			}
+		}
+		if !strings.Contains(out, "› ") {
+			t.Fatalf("%dx%d: expected the cursor row to stay visible", size.width, size.height)
+		}
+	}
+}
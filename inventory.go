@@ -0,0 +1,319 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// --- Inventory Sync ---
+
+// `assho sync` pulls hosts from the inventories listed in hosts.json:
+//
+//	"inventories": [
+//	  {"name": "netbox", "type": "netbox", "url": "https://netbox.example.com", "token_env": "NETBOX_TOKEN"},
+//	  {"name": "cmdb", "type": "rest", "url": "https://cmdb.example.com/api/servers", "address_field": "ip"}
+//	]
+//
+// Netbox sources read devices and virtual machines that have a primary IP.
+// REST sources read a JSON array (or an object with "results") of records
+// with id, name, and address fields. Each synced host stores its source and
+// record ID, so the next sync updates its address instead of adding a copy.
+
+const (
+	inventoryNetbox   = "netbox"
+	inventoryREST     = "rest"
+	inventoryTimeout  = 30 * time.Second
+	inventoryMaxPages = 50
+)
+
+type InventorySource struct {
+	Name  string `json:"name"`
+	Type  string `json:"type"` // netbox or rest
+	URL   string `json:"url"`
+	Group string `json:"group,omitempty"` // defaults to Name
+	// TokenEnv names the environment variable holding the API token, so the
+	// token itself never lands in hosts.json.
+	TokenEnv string `json:"token_env,omitempty"`
+
+	// Field names for REST sources.
+	IDField      string `json:"id_field,omitempty"`      // default "id"
+	NameField    string `json:"name_field,omitempty"`    // default "name"
+	AddressField string `json:"address_field,omitempty"` // default "address"
+}
+
+type inventoryRecord struct {
+	id      string
+	name    string
+	address string
+}
+
+type inventorySyncReport struct {
+	added, updated, unchanged int
+	conflicts                 []string // aliases already used by hand-made hosts
+	missing                   []string // synced earlier, absent from the source now
+}
+
+func (s InventorySource) groupName() string {
+	if strings.TrimSpace(s.Group) != "" {
+		return strings.TrimSpace(s.Group)
+	}
+	return s.Name
+}
+
+// sourceKey is what a synced host stores in SourceID.
+func (s InventorySource) sourceKey(id string) string {
+	return s.Name + ":" + id
+}
+
+// stripPrefixLength turns Netbox's "10.0.0.5/24" into "10.0.0.5".
+func stripPrefixLength(address string) string {
+	if prefix, err := netip.ParsePrefix(address); err == nil {
+		return prefix.Addr().String()
+	}
+	return address
+}
+
+// syncInventory merges records into hosts. Records are matched by source ID
+// first; a new record whose alias a hand-made host already uses is reported
+// rather than duplicated.
+func syncInventory(groups []Group, hosts []Host, source InventorySource, records []inventoryRecord) ([]Group, []Host, inventorySyncReport) {
+	var report inventorySyncReport
+	bySource := map[string]int{}
+	for i, h := range hosts {
+		if h.SourceID != "" {
+			bySource[h.SourceID] = i
+		}
+	}
+	groupID := ""
+	seen := map[string]bool{}
+	for _, r := range records {
+		if r.name == "" || r.address == "" || validateHostname(r.address) != nil {
+			continue
+		}
+		key := source.sourceKey(r.id)
+		seen[key] = true
+		if i, ok := bySource[key]; ok {
+			if hosts[i].Hostname == r.address {
+				report.unchanged++
+			} else {
+				hosts[i].Hostname = r.address
+				report.updated++
+			}
+			continue
+		}
+		if aliasTaken(hosts, r.name, "") {
+			report.conflicts = append(report.conflicts, r.name)
+			continue
+		}
+		if groupID == "" {
+			idx := findGroupByName(groups, source.groupName())
+			if idx == -1 || groups[idx].Smart() {
+				groups = append(groups, Group{ID: newGroupID(), Name: source.groupName(), Expanded: true})
+				idx = len(groups) - 1
+			}
+			groupID = groups[idx].ID
+		}
+		hosts = append(hosts, Host{ID: newHostID(), Alias: r.name, Hostname: r.address, GroupID: groupID, SourceID: key})
+		report.added++
+	}
+	prefix := source.Name + ":"
+	for _, h := range hosts {
+		if strings.HasPrefix(h.SourceID, prefix) && !seen[h.SourceID] {
+			report.missing = append(report.missing, h.Alias)
+		}
+	}
+	return groups, hosts, report
+}
+
+func inventoryGet(client *http.Client, source InventorySource, endpoint string) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if source.TokenEnv != "" {
+		token := os.Getenv(source.TokenEnv)
+		if token == "" {
+			return nil, fmt.Errorf("%s is not set", source.TokenEnv)
+		}
+		if source.Type == inventoryNetbox {
+			req.Header.Set("Authorization", "Token "+token)
+		} else {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 64<<20))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s returned %s", endpoint, resp.Status)
+	}
+	return body, nil
+}
+
+type netboxPage struct {
+	Next    *string `json:"next"`
+	Results []struct {
+		ID        int     `json:"id"`
+		Name      *string `json:"name"`
+		PrimaryIP *struct {
+			Address string `json:"address"`
+		} `json:"primary_ip"`
+	} `json:"results"`
+}
+
+// fetchNetbox reads devices and virtual machines. Their IDs come from separate
+// tables, so virtual machines are keyed vm-<id>.
+func fetchNetbox(client *http.Client, source InventorySource) ([]inventoryRecord, error) {
+	base := strings.TrimSuffix(source.URL, "/")
+	var records []inventoryRecord
+	for _, endpoint := range []struct{ path, idPrefix string }{
+		{"/api/dcim/devices/", ""},
+		{"/api/virtualization/virtual-machines/", "vm-"},
+	} {
+		next := base + endpoint.path + "?has_primary_ip=true&limit=1000"
+		for page := 0; next != "" && page < inventoryMaxPages; page++ {
+			body, err := inventoryGet(client, source, next)
+			if err != nil {
+				return nil, err
+			}
+			var p netboxPage
+			if err := json.Unmarshal(body, &p); err != nil {
+				return nil, fmt.Errorf("unexpected Netbox response: %v", err)
+			}
+			for _, r := range p.Results {
+				if r.Name == nil || r.PrimaryIP == nil {
+					continue
+				}
+				records = append(records, inventoryRecord{id: endpoint.idPrefix + fmt.Sprint(r.ID), name: *r.Name, address: stripPrefixLength(r.PrimaryIP.Address)})
+			}
+			next = ""
+			if p.Next != nil {
+				next = *p.Next
+			}
+		}
+	}
+	return records, nil
+}
+
+// parseRESTInventory reads a JSON array, or an object whose "results" is one.
+func parseRESTInventory(body []byte, source InventorySource) ([]inventoryRecord, error) {
+	var items []map[string]any
+	if err := json.Unmarshal(body, &items); err != nil {
+		var wrapped struct {
+			Results []map[string]any `json:"results"`
+		}
+		if err := json.Unmarshal(body, &wrapped); err != nil {
+			return nil, fmt.Errorf("expected a JSON array of records: %v", err)
+		}
+		items = wrapped.Results
+	}
+	field := func(name, fallback string) string {
+		if name == "" {
+			return fallback
+		}
+		return name
+	}
+	idField, nameField, addressField := field(source.IDField, "id"), field(source.NameField, "name"), field(source.AddressField, "address")
+	var records []inventoryRecord
+	for _, item := range items {
+		value := func(key string) string {
+			switch v := item[key].(type) {
+			case string:
+				return strings.TrimSpace(v)
+			case float64:
+				return fmt.Sprint(int64(v))
+			}
+			return ""
+		}
+		records = append(records, inventoryRecord{id: value(idField), name: value(nameField), address: stripPrefixLength(value(addressField))})
+	}
+	return records, nil
+}
+
+func fetchInventory(client *http.Client, source InventorySource) ([]inventoryRecord, error) {
+	if _, err := url.ParseRequestURI(source.URL); err != nil {
+		return nil, fmt.Errorf("invalid url: %v", err)
+	}
+	switch source.Type {
+	case inventoryNetbox:
+		return fetchNetbox(client, source)
+	case inventoryREST:
+		body, err := inventoryGet(client, source, source.URL)
+		if err != nil {
+			return nil, err
+		}
+		return parseRESTInventory(body, source)
+	}
+	return nil, fmt.Errorf("unknown inventory type %q (use netbox or rest)", source.Type)
+}
+
+func fprintSyncReport(w io.Writer, source InventorySource, report inventorySyncReport) {
+	fmt.Fprintf(w, "✔ %s: %d added, %d updated, %d unchanged\n", source.Name, report.added, report.updated, report.unchanged)
+	for _, alias := range report.conflicts {
+		fmt.Fprintf(w, "  ⚠ %s already exists and was not synced\n", alias)
+	}
+	for _, alias := range report.missing {
+		fmt.Fprintf(w, "  ⚠ %s is no longer in %s\n", alias, source.Name)
+	}
+}
+
+func cliSync(args []string) {
+	if len(args) > 1 {
+		fmt.Fprintln(os.Stderr, "usage: assho sync [inventory]")
+		os.Exit(1)
+	}
+	cfg, err := loadConfigFile()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error loading config: %v\n", err)
+		os.Exit(1)
+	}
+	var sources []InventorySource
+	for _, s := range cfg.Inventories {
+		if len(args) == 0 || strings.EqualFold(s.Name, args[0]) {
+			sources = append(sources, s)
+		}
+	}
+	if len(sources) == 0 {
+		fmt.Fprintln(os.Stderr, "no matching inventories; add an \"inventories\" array to hosts.json")
+		os.Exit(1)
+	}
+	groups, hosts, history, err := loadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error loading config: %v\n", err)
+		os.Exit(1)
+	}
+	client := &http.Client{Timeout: inventoryTimeout}
+	failed := false
+	for _, source := range sources {
+		records, err := fetchInventory(client, source)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "✘ %s: %v\n", source.Name, err)
+			failed = true
+			continue
+		}
+		var report inventorySyncReport
+		groups, hosts, report = syncInventory(groups, hosts, source, records)
+		fprintSyncReport(os.Stdout, source, report)
+	}
+	if err := saveConfig(groups, hosts, history); err != nil {
+		fmt.Fprintf(os.Stderr, "error saving config: %v\n", err)
+		os.Exit(1)
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
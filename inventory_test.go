@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSyncInventoryUpdatesBySourceID(t *testing.T) {
+	source := InventorySource{Name: "netbox", Type: inventoryNetbox}
+	hosts := []Host{
+		{ID: "h1", Alias: "sw-renamed", Hostname: "10.0.0.1", SourceID: "netbox:1"},
+		{ID: "h2", Alias: "db", Hostname: "10.0.0.9"},
+		{ID: "h3", Alias: "retired", Hostname: "10.0.0.7", SourceID: "netbox:7"},
+	}
+	records := []inventoryRecord{
+		{id: "1", name: "sw1", address: "10.0.0.2"},
+		{id: "2", name: "fw1", address: "10.0.0.3"},
+		{id: "3", name: "db", address: "10.0.0.4"},
+		{id: "4", name: "unaddressed"},
+	}
+	groups, hosts, report := syncInventory(nil, hosts, source, records)
+	if report.added != 1 || report.updated != 1 || len(report.conflicts) != 1 || report.conflicts[0] != "db" {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	if len(report.missing) != 1 || report.missing[0] != "retired" {
+		t.Fatalf("expected the retired host reported, got %v", report.missing)
+	}
+	if hosts[0].Alias != "sw-renamed" || hosts[0].Hostname != "10.0.0.2" {
+		t.Fatalf("expected only the address updated, got %+v", hosts[0])
+	}
+	if len(groups) != 1 || groups[0].Name != "netbox" || hosts[3].GroupID != groups[0].ID || hosts[3].SourceID != "netbox:2" {
+		t.Fatalf("expected fw1 added to the netbox group, got %+v / %+v", groups, hosts[3])
+	}
+
+	_, again, report := syncInventory(groups, hosts, source, records)
+	if len(again) != len(hosts) || report.added != 0 || report.unchanged != 2 {
+		t.Fatalf("expected a repeat sync to change nothing, got %+v", report)
+	}
+}
+
+func TestFetchNetboxFollowsPages(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Token secret" {
+			http.Error(w, "no token", http.StatusForbidden)
+			return
+		}
+		switch {
+		case r.URL.Path == "/api/dcim/devices/" && r.URL.Query().Get("offset") == "":
+			w.Write([]byte(`{"next":"` + server.URL + `/api/dcim/devices/?offset=1","results":[{"id":1,"name":"sw1","primary_ip":{"address":"10.0.0.1/24"}}]}`))
+		case r.URL.Path == "/api/dcim/devices/":
+			w.Write([]byte(`{"next":null,"results":[{"id":2,"name":null,"primary_ip":{"address":"10.0.0.2/24"}}]}`))
+		default:
+			w.Write([]byte(`{"next":null,"results":[{"id":1,"name":"vm1","primary_ip":{"address":"2001:db8::1/64"}}]}`))
+		}
+	}))
+	defer server.Close()
+	t.Setenv("TEST_NETBOX_TOKEN", "secret")
+	source := InventorySource{Name: "netbox", Type: inventoryNetbox, URL: server.URL, TokenEnv: "TEST_NETBOX_TOKEN"}
+	records, err := fetchInventory(server.Client(), source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 || records[0].address != "10.0.0.1" || records[1].id != "vm-1" || records[1].address != "2001:db8::1" {
+		t.Fatalf("unexpected records %+v", records)
+	}
+}
+
+func TestFetchInventoryRequiresToken(t *testing.T) {
+	t.Setenv("TEST_NETBOX_TOKEN", "")
+	source := InventorySource{Name: "netbox", Type: inventoryNetbox, URL: "https://netbox.example.com", TokenEnv: "TEST_NETBOX_TOKEN"}
+	if _, err := fetchInventory(http.DefaultClient, source); err == nil || !strings.Contains(err.Error(), "is not set") {
+		t.Fatalf("expected a missing token error, got %v", err)
+	}
+}
+
+func TestParseRESTInventory(t *testing.T) {
+	source := InventorySource{Name: "cmdb", Type: inventoryREST, AddressField: "ip"}
+	records, err := parseRESTInventory([]byte(`{"results":[{"id":12,"name":"app1","ip":"10.1.0.5"},{"id":"x9","name":"app2","ip":"10.1.0.6/32"}]}`), source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 || records[0].id != "12" || records[1].address != "10.1.0.6" {
+		t.Fatalf("unexpected records %+v", records)
+	}
+	if _, err := parseRESTInventory([]byte(`"nope"`), source); err == nil {
+		t.Fatal("expected an error for a non-list response")
+	}
+}
+
+func TestFprintSyncReport(t *testing.T) {
+	var buf bytes.Buffer
+	fprintSyncReport(&buf, InventorySource{Name: "netbox"}, inventorySyncReport{added: 2, conflicts: []string{"db"}, missing: []string{"old"}})
+	out := buf.String()
+	if !strings.Contains(out, "2 added") || !strings.Contains(out, "db already exists") || !strings.Contains(out, "old is no longer in netbox") {
+		t.Fatalf("unexpected report output:\n%s", out)
+	}
+}
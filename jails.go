@@ -0,0 +1,57 @@
+package main
+
+import "fmt"
+
+// --- FreeBSD Jails / illumos Zones ---
+
+// On BSD and illumos hosts the scan lists running jails (jls) and non-global
+// zones (zoneadm). They nest like containers and are entered with jexec or
+// zlogin on the host, which needs root there.
+
+const (
+	hostKindJail = "jail"
+	hostKindZone = "zone"
+)
+
+// jailScanLines prints "jail\t<name>" and "zone\t<name>" lines.
+var jailScanLines = []string{
+	`if command -v jls >/dev/null 2>&1; then`,
+	`  found=1`,
+	`  jls name 2>/dev/null | while IFS= read -r j; do [ -n "$j" ] && printf "jail\t%s\n" "$j"; done`,
+	`fi`,
+	`if command -v zoneadm >/dev/null 2>&1; then`,
+	`  found=1`,
+	`  zoneadm list 2>/dev/null | while IFS= read -r z; do [ -n "$z" ] && [ "$z" != global ] && printf "zone\t%s\n" "$z"; done`,
+	`fi`,
+}
+
+func (h Host) isJail() bool {
+	return h.IsContainer && (h.Kind == hostKindJail || h.Kind == hostKindZone)
+}
+
+func newJailHost(kind, name, parentID string) Host {
+	return Host{
+		ID:          newHostID(),
+		Alias:       name,
+		Hostname:    name,
+		User:        "root",
+		IsContainer: true,
+		Kind:        kind,
+		ParentID:    parentID,
+	}
+}
+
+// jailExecCommand opens a login shell in the jail or zone; with remote set
+// it runs that command instead.
+func jailExecCommand(h Host, remote string) string {
+	if h.Kind == hostKindZone {
+		if remote != "" {
+			return fmt.Sprintf("zlogin %s %s", shellQuote(h.Alias), remote)
+		}
+		return "zlogin " + shellQuote(h.Alias)
+	}
+	if remote != "" {
+		return fmt.Sprintf("jexec %s %s", shellQuote(h.Alias), remote)
+	}
+	return "jexec -l " + shellQuote(h.Alias) + " sh -c 'command -v bash >/dev/null 2>&1 && exec bash || exec sh'"
+}
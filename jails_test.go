@@ -0,0 +1,41 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseGuestScanReadsJailsAndZones(t *testing.T) {
+	hosts := parseGuestScan("jail\twww\nzone\tbuild-01\njail\t\n", "p1")
+	if len(hosts) != 2 {
+		t.Fatalf("expected a jail and a zone, got %+v", hosts)
+	}
+	if hosts[0].Alias != "www" || hosts[0].Kind != hostKindJail || !hosts[0].isJail() {
+		t.Fatalf("unexpected jail %+v", hosts[0])
+	}
+	if hosts[1].Alias != "build-01" || hosts[1].Kind != hostKindZone || hosts[1].containerLabel() != "zone" {
+		t.Fatalf("unexpected zone %+v", hosts[1])
+	}
+}
+
+func TestBuildConnectCommandJailAndZone(t *testing.T) {
+	parent := Host{ID: "p1", Alias: "bsd", Hostname: "10.0.0.9", User: "root"}
+	tests := []struct {
+		kind string
+		want string
+	}{
+		{hostKindJail, "jexec -l www sh -c 'command -v bash >/dev/null 2>&1 && exec bash || exec sh'"},
+		{hostKindZone, "zlogin www"},
+	}
+	for _, tt := range tests {
+		h := Host{ID: "j1", Alias: "www", Hostname: "www", IsContainer: true, Kind: tt.kind, ParentID: "p1"}
+		cmd, err := buildConnectCommand(h, hostInventory{hosts: []Host{parent, h}}, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := []string{"-t", "-l", "root", "10.0.0.9", tt.want}
+		if !slices.Equal(cmd.args, want) {
+			t.Errorf("%s: got %v, want %v", tt.kind, cmd.args, want)
+		}
+	}
+}
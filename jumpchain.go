@@ -0,0 +1,230 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// --- Jump Chains ---
+
+// The detail pane draws the route to a host behind a ProxyJump as a chain
+// of hops, this machine → bastion → web, resolving each jump name the way
+// ssh will. A name with a Host block in ~/.ssh/config takes that block's
+// hostname, user, and port, and its own ProxyJump is followed first, since
+// ssh reaches the jump host through it. An IP address or a dotted name is
+// dialed as written. ssh never reads hosts.json, so before a connect, test,
+// or any other ssh run, a jump naming an assho host that ~/.ssh/config does
+// not know is rewritten to that host's user@hostname:port, behind its own
+// jump hosts. A bare name found nowhere is flagged, and so is a jump host
+// whose key file ssh -J cannot be told about.
+
+// maxJumpDepth bounds how deep ssh_config jump hosts are followed.
+const maxJumpDepth = 8
+
+type jumpHop struct {
+	name    string // as written in the ProxyJump
+	target  string // [user@]host[:port] ssh dials, when it differs from name
+	problem string // why ssh will not reach the hop as meant
+}
+
+// loadSSHConfigBlocks reads ~/.ssh/config, or returns nil when there is none.
+func loadSSHConfigBlocks() []sshConfigBlock {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil
+	}
+	blocks, err := parseSSHConfigBlocks(filepath.Join(home, ".ssh", "config"))
+	if err != nil {
+		return nil
+	}
+	return blocks
+}
+
+// splitJumpSpec splits one ProxyJump hop, [ssh://][user@]host[:port].
+func splitJumpSpec(spec string) (user, host, port string) {
+	spec = strings.TrimPrefix(strings.TrimSpace(spec), "ssh://")
+	if i := strings.LastIndex(spec, "@"); i != -1 {
+		user, spec = spec[:i], spec[i+1:]
+	}
+	if h, p, err := net.SplitHostPort(spec); err == nil {
+		return user, h, p
+	}
+	return user, bareHostname(spec), ""
+}
+
+// sshConfigKnows reports whether ssh resolves name through a Host block: a
+// block names it outright, or a matching pattern block sets its hostname.
+func sshConfigKnows(blocks []sshConfigBlock, name string) bool {
+	for _, b := range blocks {
+		if !sshPatternsMatch(b.patterns, name) {
+			continue
+		}
+		if b.hostname != "" {
+			return true
+		}
+		for _, p := range b.patterns {
+			if !isWildcard(p) && strings.EqualFold(p, name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// resolveJumpAliases rewrites the hops of a ProxyJump spec that name an
+// assho host unknown to ~/.ssh/config into the address ssh should dial. A
+// jump host reached through its own ProxyJump brings those hops along; one
+// behind a ProxyCommand cannot be written as a -J hop and is left as named.
+func resolveJumpAliases(spec string, hosts []Host, groups []Group, blocks []sshConfigBlock) string {
+	return strings.Join(resolveJumpHops(spec, hosts, groups, blocks, map[string]bool{}, 0), ",")
+}
+
+func resolveJumpHops(spec string, hosts []Host, groups []Group, blocks []sshConfigBlock, seen map[string]bool, depth int) []string {
+	var hops []string
+	for _, part := range strings.Split(spec, ",") {
+		if part = strings.TrimSpace(part); part == "" {
+			continue
+		}
+		user, name, port := splitJumpSpec(part)
+		key := strings.ToLower(name)
+		idx := findHostIndexByAlias(hosts, name)
+		if seen[key] || depth >= maxJumpDepth || idx == -1 || sshConfigKnows(blocks, name) {
+			hops = append(hops, part)
+			continue
+		}
+		jump := applyGroupDefaults(hosts[idx], groups)
+		if jump.ProxyCommand != "" || jump.UseSSHConfig || strings.EqualFold(bareHostname(jump.Hostname), name) {
+			hops = append(hops, part)
+			continue
+		}
+		if jump.ProxyJump != "" && jump.ProxyJump != "none" {
+			seen[key] = true
+			hops = append(hops, resolveJumpHops(jump.ProxyJump, hosts, groups, blocks, seen, depth+1)...)
+			delete(seen, key)
+		}
+		if user == "" {
+			user = jump.User
+		}
+		if port == "" && jump.Port != "22" {
+			port = strings.TrimSpace(jump.Port)
+		}
+		target := bracketHostname(jump.Hostname)
+		if port != "" {
+			target += ":" + port
+		}
+		if user != "" {
+			target = user + "@" + target
+		}
+		hops = append(hops, target)
+	}
+	return hops
+}
+
+// jumpChain resolves the hops ssh takes to reach h, nearest first. It is
+// empty when h is dialed directly or through a ProxyCommand.
+func jumpChain(h Host, hosts []Host, blocks []sshConfigBlock) []jumpHop {
+	if h.ProxyCommand != "" || h.isLocal() || h.IsContainer {
+		return nil
+	}
+	spec := h.ProxyJump
+	if spec == "" && h.UseSSHConfig {
+		spec = resolveSSHConfigAlias(blocks, h.Alias).proxyJump
+	}
+	if spec == "" || spec == "none" {
+		return nil
+	}
+	return expandJumpSpec(spec, hosts, blocks, map[string]bool{}, 0)
+}
+
+func expandJumpSpec(spec string, hosts []Host, blocks []sshConfigBlock, seen map[string]bool, depth int) []jumpHop {
+	var hops []jumpHop
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		user, name, port := splitJumpSpec(part)
+		hop := jumpHop{name: part}
+		key := strings.ToLower(name)
+		switch {
+		case seen[key] || depth >= maxJumpDepth:
+			hop.problem = "jump hosts loop back to " + name
+		case sshConfigKnows(blocks, name):
+			r := resolveSSHConfigAlias(blocks, name)
+			if r.proxyJump != "" && r.proxyJump != "none" && r.proxyCmd == "" {
+				seen[key] = true
+				hops = append(hops, expandJumpSpec(r.proxyJump, hosts, blocks, seen, depth+1)...)
+				delete(seen, key)
+			}
+			hostname := strings.ReplaceAll(strings.ReplaceAll(r.hostname, "%h", name), "%%", "%")
+			if hostname == "" {
+				hostname = name
+			}
+			if user == "" {
+				user = r.user
+			}
+			if port == "" && r.port != "22" {
+				port = r.port
+			}
+			hop.target = hostPort(hostname, port)
+			if user != "" {
+				hop.target = user + "@" + hop.target
+			}
+		default:
+			idx := findHostIndexByAlias(hosts, name)
+			if idx != -1 && !strings.EqualFold(bareHostname(hosts[idx].Hostname), name) {
+				hop.target = sshTarget(hosts[idx])
+				if hosts[idx].IdentityFile != "" {
+					hop.problem = fmt.Sprintf("ssh -J cannot use %s's key file; load it into the agent or run assho export --write", name)
+				}
+			} else if idx == -1 && net.ParseIP(name) == nil && !strings.Contains(name, ".") {
+				hop.problem = name + " is not in ~/.ssh/config or hosts.json"
+			}
+		}
+		if hop.target == part {
+			hop.target = ""
+		}
+		hops = append(hops, hop)
+	}
+	return hops
+}
+
+// jumpProblems lists the flagged hops of a chain.
+func jumpProblems(hops []jumpHop) []string {
+	var problems []string
+	for _, hop := range hops {
+		if hop.problem != "" {
+			problems = append(problems, hop.problem)
+		}
+	}
+	return problems
+}
+
+// localHopName names this machine at the start of a route.
+func localHopName() string {
+	name, _ := os.Hostname()
+	if name, _, _ = strings.Cut(name, "."); name == "" {
+		return "this machine"
+	}
+	return name
+}
+
+// renderJumpRoute draws the route from this machine through hops to alias,
+// marking flagged hops.
+func renderJumpRoute(hops []jumpHop, alias string) string {
+	parts := []string{localHopName()}
+	for _, hop := range hops {
+		label := hop.name
+		if hop.target != "" {
+			label += " (" + hop.target + ")"
+		}
+		if hop.problem != "" {
+			label = testFailStyle.Render("✘ " + label)
+		}
+		parts = append(parts, label)
+	}
+	return strings.Join(append(parts, alias), " → ")
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestJumpChainResolvesHops(t *testing.T) {
+	blocks, err := parseSSHConfigBlocks(writeTempSSHConfig(t, `
+Host bastion
+    HostName 203.0.113.5
+    User admin
+    ProxyJump gateway
+
+Host gateway
+    HostName gw.example.com
+    Port 2222
+
+Host loop
+    ProxyJump loop
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	hosts := []Host{
+		{ID: "a", Alias: "jumpbox", Hostname: "10.0.0.9", User: "ops"},
+		{ID: "b", Alias: "web", Hostname: "10.0.0.1", ProxyJump: "bastion,jumpbox,ghost,1.2.3.4,keyed"},
+		{ID: "c", Alias: "keyed", Hostname: "10.0.0.10", IdentityFile: "~/.ssh/id_keyed"},
+	}
+
+	hops := jumpChain(hosts[1], hosts, blocks)
+	var names []string
+	for _, hop := range hops {
+		names = append(names, hop.name)
+	}
+	if got := strings.Join(names, " "); got != "gateway bastion jumpbox ghost 1.2.3.4 keyed" {
+		t.Fatalf("unexpected hops %q", got)
+	}
+	if hops[0].target != "gw.example.com:2222" || hops[1].target != "admin@203.0.113.5" {
+		t.Fatalf("expected ssh_config targets, got %+v", hops[:2])
+	}
+	if hops[0].problem != "" || hops[1].problem != "" || hops[4].problem != "" {
+		t.Fatalf("expected ssh_config hosts and addresses to resolve, got %+v", hops)
+	}
+	if hops[2].target != "ops@10.0.0.9" || hops[2].problem != "" {
+		t.Fatalf("expected an assho-only jump host resolved through hosts.json, got %+v", hops[2])
+	}
+	if !strings.Contains(hops[5].problem, "cannot use keyed's key file") {
+		t.Fatalf("expected a jump host's key file to be flagged, got %+v", hops[5])
+	}
+	if !strings.Contains(hops[3].problem, "ghost is not in") {
+		t.Fatalf("expected an unknown name to be flagged, got %+v", hops[3])
+	}
+	if len(jumpProblems(hops)) != 2 {
+		t.Fatalf("expected two problems, got %q", jumpProblems(hops))
+	}
+
+	if loop := jumpChain(Host{Alias: "x", ProxyJump: "loop"}, nil, blocks); len(loop) != 2 || !strings.Contains(loop[0].problem, "loop") {
+		t.Fatalf("expected the loop to be flagged, got %+v", loop)
+	}
+	if jumpChain(Host{Alias: "x", ProxyJump: "bastion", ProxyCommand: "nc %h %p"}, nil, blocks) != nil {
+		t.Fatal("a ProxyCommand replaces the jump chain")
+	}
+	if got := jumpChain(Host{Alias: "bastion", UseSSHConfig: true}, nil, blocks); len(got) != 1 || got[0].name != "gateway" {
+		t.Fatalf("expected an ssh_config host to use its block's jump, got %+v", got)
+	}
+}
+
+func TestSplitJumpSpec(t *testing.T) {
+	for spec, want := range map[string][3]string{
+		"bastion":                      {"", "bastion", ""},
+		"admin@bastion:2222":           {"admin", "bastion", "2222"},
+		"ssh://admin@[2001:db8::1]:22": {"admin", "2001:db8::1", "22"},
+		"[2001:db8::1]":                {"", "2001:db8::1", ""},
+	} {
+		user, host, port := splitJumpSpec(spec)
+		if [3]string{user, host, port} != want {
+			t.Errorf("splitJumpSpec(%q) = %q %q %q, want %q", spec, user, host, port, want)
+		}
+	}
+}
+
+func TestDoctorFlagsUnresolvableJumps(t *testing.T) {
+	writeTempConfig(t, []Host{{ID: "a", Alias: "web", Hostname: "10.0.0.1", ProxyJump: "ghost"}})
+	checks, _ := doctorConfigChecks()
+	found := false
+	for _, c := range checks {
+		found = found || (c.name == "jumps" && strings.Contains(c.detail, "web"))
+	}
+	if !found {
+		t.Fatalf("expected a jumps warning, got %+v", checks)
+	}
+}
+
+func TestResolveJumpAliases(t *testing.T) {
+	blocks, err := parseSSHConfigBlocks(writeTempSSHConfig(t, "Host bastion\n    HostName 203.0.113.5\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	groups := []Group{{ID: "g1", Name: "dmz", DefaultUser: "jump"}}
+	hosts := []Host{
+		{ID: "a", Alias: "edge", Hostname: "198.51.100.7", Port: "2222", GroupID: "g1"},
+		{ID: "b", Alias: "inner", Hostname: "10.0.0.9", User: "ops", Port: "22", ProxyJump: "edge"},
+		{ID: "c", Alias: "bastion", Hostname: "192.0.2.1"},
+		{ID: "d", Alias: "tunnel", Hostname: "10.0.0.3", ProxyCommand: "cloudflared access ssh --hostname %h"},
+		{ID: "e", Alias: "v6", Hostname: "2001:db8::7"},
+		{ID: "f", Alias: "self", Hostname: "self", ProxyJump: "self"},
+	}
+	for spec, want := range map[string]string{
+		"edge":             "jump@198.51.100.7:2222",
+		"root@edge:22":     "root@198.51.100.7:22",
+		"inner":            "jump@198.51.100.7:2222,ops@10.0.0.9",
+		"bastion,gw.lan":   "bastion,gw.lan",
+		"tunnel":           "tunnel",
+		"v6":               "[2001:db8::7]",
+		"ghost, 10.0.0.1 ": "ghost,10.0.0.1",
+		"self":             "self",
+	} {
+		if got := resolveJumpAliases(spec, hosts, groups, blocks); got != want {
+			t.Errorf("resolveJumpAliases(%q) = %q, want %q", spec, got, want)
+		}
+	}
+}
+
+func TestFormTestResolvesJumpAlias(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	stubNetwork(t, nil, nil)
+
+	m := model{rawHosts: []Host{{ID: "a", Alias: "bastion", Hostname: "203.0.113.5", User: "admin"}}, form: newFormState(newFormInputs())}
+	m.form.inputs[fieldHostname].SetValue("10.0.0.1")
+	m.form.inputs[fieldProxyJump].SetValue("bastion")
+	h := resolveEndpoint(m.formTestHost(), m.inventory())
+	if h.ProxyJump != "admin@203.0.113.5" {
+		t.Fatalf("expected the jump alias resolved through the dashboard's hosts, got %q", h.ProxyJump)
+	}
+	if args := strings.Join(proxyArgs(h), " "); args != "-J admin@203.0.113.5" {
+		t.Fatalf("unexpected proxy args %q", args)
+	}
+}
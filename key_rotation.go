@@ -23,6 +23,7 @@ const (
 	pickerIdentity filePickerPurpose = iota
 	pickerInstallPublic
 	pickerRotationPrivate
+	pickerTransferLocal
 )
 
 type keyInstallPhase int
@@ -140,6 +141,9 @@ func hostFromForm(m model) Host {
 		IdentityFile: strings.TrimSpace(m.form.inputs[fieldKeyFile].Value()),
 		Password:     m.form.inputs[fieldPassword].Value(),
 		ProxyJump:    strings.TrimSpace(m.form.inputs[fieldProxyJump].Value()),
+		ProxyCommand: strings.TrimSpace(m.form.inputs[fieldProxyCommand].Value()),
+
+		IdentityAgent: strings.TrimSpace(m.form.inputs[fieldIdentityAgent].Value()),
 	}
 }
 
@@ -207,6 +211,7 @@ func (m model) updateKeyInstall(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				host:      m.keyInstall.host,
 				trustHost: m.keyInstall.host,
 				publicKey: m.keyInstall.publicKey,
+				inv:       m.inventory(),
 			})
 		case keyInstallDone:
 			m.state = stateForm
@@ -251,6 +256,13 @@ func (m *model) returnFromFilePicker(selected bool, path string) {
 			m.rotation.phase = rotationConfirm
 			m.rotation.run = &rotationRun{NewIdentity: path}
 		}
+	case pickerTransferLocal:
+		m.state = stateTransfer
+		m.filepicker.DirAllowed = false
+		if selected {
+			m.transfer.local.SetValue(path)
+			m.transfer.local.CursorEnd()
+		}
 	default:
 		m.state = stateForm
 		m.form.focus = controlKeyFile
@@ -329,7 +341,7 @@ func publicKeyFingerprint(path string) (string, error) {
 
 func buildCopyIDCommand(host Host, publicKey string) (*exec.Cmd, error) {
 	if !commandExists("ssh-copy-id") {
-		return nil, errors.New("ssh-copy-id is required")
+		return nil, errMissingTool("ssh-copy-id")
 	}
 	if strings.TrimSpace(host.Hostname) == "" {
 		return nil, errors.New("hostname is required")
@@ -344,12 +356,17 @@ func buildCopyIDCommand(host Host, publicKey string) (*exec.Cmd, error) {
 	if host.Port != "" && host.Port != "22" {
 		args = append(args, "-p", host.Port)
 	}
-	if host.ProxyJump != "" {
+	// ssh-copy-id passes -o options through to ssh but has no -J.
+	switch {
+	case host.ProxyCommand != "":
+		args = append(args, "-o", "ProxyCommand="+host.ProxyCommand)
+	case host.ProxyJump != "":
 		args = append(args, "-o", "ProxyJump="+host.ProxyJump)
 	}
 	args = append(args, "-o", "StrictHostKeyChecking=yes")
+	args = append(args, pinnedKnownHostsArgs(host)...)
 	args = append(args, sshTarget(host))
-	if host.Password != "" && commandExists("sshpass") {
+	if host = withPassword(host); host.Password != "" && commandExists("sshpass") {
 		cmd := exec.Command("sshpass", append([]string{"-e", "ssh-copy-id"}, args...)...)
 		cmd.Env = append(os.Environ(), "SSHPASS="+host.Password)
 		return cmd, nil
@@ -359,9 +376,9 @@ func buildCopyIDCommand(host Host, publicKey string) (*exec.Cmd, error) {
 
 func sshTarget(host Host) string {
 	if host.User == "" {
-		return host.Hostname
+		return bareHostname(host.Hostname)
 	}
-	return host.User + "@" + host.Hostname
+	return host.User + "@" + bareHostname(host.Hostname)
 }
 
 func (m model) openRotation() (tea.Model, tea.Cmd) {
@@ -384,7 +401,7 @@ func defaultRotationKeyPath() string {
 func selectableHosts(hosts []Host) []Host {
 	out := make([]Host, 0, len(hosts))
 	for _, host := range hosts {
-		if !host.IsContainer && host.Hostname != "" {
+		if !host.IsContainer && !host.Archived && host.Hostname != "" {
 			out = append(out, host)
 		}
 	}
@@ -561,15 +578,6 @@ func countSelected(selected map[string]bool) int {
 	return n
 }
 
-func sshAgentAvailable() bool {
-	socket := strings.TrimSpace(os.Getenv("SSH_AUTH_SOCK"))
-	if socket == "" {
-		return false
-	}
-	info, err := os.Stat(socket)
-	return err == nil && info.Mode()&os.ModeSocket != 0
-}
-
 func (m model) finishRotationKey(msg rotationKeyReadyMsg) (tea.Model, tea.Cmd) {
 	if msg.err != nil {
 		m.rotation.agentLoaded = false
@@ -639,6 +647,7 @@ func (m model) rotationCommand(index int, stage rotationStage) tea.Cmd {
 			trustHost:     host,
 			rotationIndex: index,
 			rotationStage: stage,
+			inv:           m.inventory(),
 		})
 	}
 	return m.rotationCommandTrusted(index, stage)
@@ -655,7 +664,8 @@ func (m model) rotationCommandTrusted(index int, stage rotationStage) tea.Cmd {
 			return rotationStepMsg{hostIndex: index, stage: stage, err: errors.New("host no longer exists in config")}
 		}
 	}
-	host := m.rawHosts[hostIndex]
+	// Group defaults and network profiles apply, as for every other ssh call.
+	host := resolveEndpoint(m.rawHosts[hostIndex], m.inventory())
 	if stage == stageRemove {
 		host.IdentityFile = run.Hosts[index].OldIdentity
 	}
@@ -785,7 +795,7 @@ func (m model) finishRotationStep(msg rotationStepMsg) (tea.Model, tea.Cmd) {
 			m.rawHosts[hostIndex].IdentityFile = oldIdentity
 			configErr := fmt.Errorf("local config update failed: %w", err)
 			if !result.NewPreexisting {
-				host := m.rawHosts[hostIndex]
+				host := resolveEndpoint(m.rawHosts[hostIndex], m.inventory())
 				return m, func() tea.Msg {
 					keyType, blob, parseErr := readPublicKey(run.NewPublicKey)
 					if parseErr == nil {
@@ -822,7 +832,7 @@ func (m model) finishRotationRun() (tea.Model, tea.Cmd) {
 	_ = saveRotationRun(m.rotation.run)
 	_ = pruneRotationRuns(50)
 	m.rotation.phase = rotationSummary
-	m.list.SetItems(flattenHosts(m.rawGroups, m.rawHosts))
+	m.refreshList()
 	return m, nil
 }
 
@@ -838,16 +848,16 @@ func verifyWithIdentity(host Host, identity string) error {
 }
 
 func sshArgs(host Host, identity string, strictIdentity bool) []string {
-	args := []string{"-o", "ConnectTimeout=10", "-o", "StrictHostKeyChecking=yes"}
+	args := []string{"-o", connectTimeoutOption(max(10, host.connectTimeout())), "-o", "StrictHostKeyChecking=yes"}
+	args = append(args, pinnedKnownHostsArgs(host)...)
 	if strictIdentity {
 		args = append(args, "-o", "BatchMode=yes", "-o", "IdentitiesOnly=yes")
 	}
 	if host.Port != "" && host.Port != "22" {
 		args = append(args, "-p", host.Port)
 	}
-	if host.ProxyJump != "" {
-		args = append(args, "-J", host.ProxyJump)
-	}
+	args = append(args, proxyArgs(host)...)
+	args = append(args, identityAgentArgs(host)...)
 	if identity != "" {
 		args = append(args, "-i", expandPath(identity))
 	}
@@ -884,7 +894,7 @@ printf 'BACKUP %s\n' "$backup"
 	args := sshArgs(host, host.IdentityFile, false)
 	args = append(args, sshTarget(host), "sh", "-s")
 	var cmd *exec.Cmd
-	if host.Password != "" && commandExists("sshpass") {
+	if host = withPassword(host); host.Password != "" && commandExists("sshpass") {
 		cmd = exec.Command("sshpass", append([]string{"-e", "ssh"}, args...)...)
 		cmd.Env = append(os.Environ(), "SSHPASS="+host.Password)
 	} else {
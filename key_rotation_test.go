@@ -280,3 +280,31 @@ func TestRotationRejectsPublicKeyAsPrivateIdentity(t *testing.T) {
 }
 
 var _ tea.Msg = rotationStepMsg{}
+
+func TestRotationStepsUseGroupDefaults(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	bin := t.TempDir()
+	argsFile := filepath.Join(bin, "args")
+	script := "#!/bin/sh\nprintf '%s\\n' \"$@\" > " + argsFile + "\n"
+	if err := os.WriteFile(filepath.Join(bin, "ssh"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin)
+
+	m := initialModel()
+	m.rawGroups = []Group{{ID: "g1", Name: "prod", DefaultUser: "deploy"}}
+	m.rawHosts = []Host{{ID: "host-1", Alias: "prod", Hostname: "prod.example", GroupID: "g1", IdentityFile: "/keys/old"}}
+	m.rotation = rotationState{phase: rotationRunning, run: &rotationRun{
+		ID:          "run-groups",
+		NewIdentity: "/keys/new",
+		Hosts:       []rotationHostResult{{HostID: "host-1", Alias: "prod", Status: rotationWorking, Stage: stageVerify, NewPreexisting: true}},
+	}}
+
+	if msg := m.rotationCommandTrusted(0, stageVerify)().(rotationStepMsg); msg.err != nil {
+		t.Fatalf("unexpected verification error: %v", msg.err)
+	}
+	args, err := os.ReadFile(argsFile)
+	if err != nil || !strings.Contains(string(args), "deploy@prod.example\n") {
+		t.Fatalf("expected the group's default user, got %q (%v)", args, err)
+	}
+}
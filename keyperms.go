@@ -0,0 +1,121 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// --- Key Permissions ---
+
+// ssh ignores a private key that others can read ("UNPROTECTED PRIVATE KEY
+// FILE", "bad permissions") and refuses to run at all when ~/.ssh/config is
+// writable by others. When a connection test fails that way the form names
+// the file, and ctrl+f fixes it in place: chmod 600 on the file and, when it
+// lives in ~/.ssh, chmod 700 on the directory. A connect leaves the TUI for
+// ssh, so there the key is checked before going: the status bar offers
+// ctrl+f, and enter again connects as it is. assho test and assho doctor
+// print the same chmod as a fix.
+
+var (
+	sshKeyTooOpenPattern   = regexp.MustCompile(`Permissions [0-7]+ for '([^']+)' are too open`)
+	sshLoadKeyPermsPattern = regexp.MustCompile(`Load key "([^"]+)": bad permissions`)
+	sshBadOwnerPattern     = regexp.MustCompile(`Bad owner or permissions on (\S+)`)
+)
+
+// permFixState is the file ctrl+f would fix, and the host whose connect is
+// waiting on it, if any.
+type permFixState struct {
+	path   string
+	hostID string
+}
+
+// sshPermissionProblem returns the file ssh refused over its permissions,
+// read from ssh's error output, or "".
+func sshPermissionProblem(err error) string {
+	if err == nil {
+		return ""
+	}
+	for _, pattern := range []*regexp.Regexp{sshKeyTooOpenPattern, sshLoadKeyPermsPattern, sshBadOwnerPattern} {
+		if match := pattern.FindStringSubmatch(err.Error()); match != nil {
+			return match[1]
+		}
+	}
+	return ""
+}
+
+// keyFileTooOpen reports whether ssh would ignore the private key at
+// identity because others can read it. Windows keeps permissions in ACLs,
+// which the mode bits do not show.
+func keyFileTooOpen(identity string) bool {
+	path := expandPath(strings.TrimSpace(identity))
+	if path == "" || strings.HasSuffix(path, ".pub") || runtime.GOOS == "windows" {
+		return false
+	}
+	info, err := os.Stat(path)
+	return err == nil && info.Mode().IsRegular() && info.Mode().Perm()&0o077 != 0
+}
+
+// fixSSHPermissions makes path private to its owner and, when it is inside
+// ~/.ssh, the directory too.
+func fixSSHPermissions(path string) error {
+	if runtime.GOOS == "windows" {
+		return errors.New("remove other users from the file's ACL in its Security properties")
+	}
+	path = expandPath(path)
+	if err := os.Chmod(path, 0o600); err != nil {
+		return err
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		dir := filepath.Join(home, ".ssh")
+		if filepath.Dir(path) == dir {
+			return os.Chmod(dir, 0o700)
+		}
+	}
+	return nil
+}
+
+// permFixHint is how the CLI and doctor word the fix for path.
+func permFixHint(path string) string {
+	return "chmod 600 " + path
+}
+
+// armKeyPermissionCheck stops a connect to h whose key file ssh would
+// ignore, unless the warning is already showing for h.
+func (m *model) armKeyPermissionCheck(h Host) bool {
+	identity := applyGroupDefaults(h, m.rawGroups).IdentityFile
+	if h.IsContainer || h.UseSSHConfig || h.Transport == transportPSRemoting || !keyFileTooOpen(identity) || m.permFix.hostID == h.ID {
+		return false
+	}
+	m.permFix = permFixState{path: expandPath(identity), hostID: h.ID}
+	m.status.message = fmt.Sprintf("Others can read %s, so ssh will ignore it · ctrl+f fixes · enter connects anyway", identity)
+	m.status.isError = true
+	m.status.version++
+	return true
+}
+
+// fixKeyPermissions runs the fix offered by the last failure.
+func (m model) fixKeyPermissions() (tea.Model, tea.Cmd) {
+	path := m.permFix.path
+	if path == "" {
+		return m, nil
+	}
+	m.permFix = permFixState{}
+	message, ok := "Made "+path+" private; try again", true
+	if err := fixSSHPermissions(path); err != nil {
+		message, ok = "Could not fix "+path+": "+err.Error(), false
+	}
+	if m.state == stateForm {
+		m.form.testStatus, m.form.testResult = message, ok
+		return m, nil
+	}
+	m.status.message, m.status.isError = message, !ok
+	m.status.version++
+	return m, statusClearCmd(m.status.version)
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestSSHPermissionProblem(t *testing.T) {
+	for output, want := range map[string]string{
+		"@ WARNING: UNPROTECTED PRIVATE KEY FILE! @\nPermissions 0644 for '/home/me/.ssh/id_ed25519' are too open.\nThis private key will be ignored.": "/home/me/.ssh/id_ed25519",
+		`Load key "/home/me/.ssh/work key": bad permissions`: "/home/me/.ssh/work key",
+		"Bad owner or permissions on /home/me/.ssh/config\n": "/home/me/.ssh/config",
+		"Permission denied (publickey).":                     "",
+	} {
+		if got := sshPermissionProblem(errors.New(output)); got != want {
+			t.Errorf("sshPermissionProblem(%q) = %q, want %q", output, got, want)
+		}
+	}
+	if status, _ := formatTestStatus(errors.New(`Load key "/k": bad permissions`)); !strings.Contains(status, "ssh refused /k") {
+		t.Errorf("unexpected status %q", status)
+	}
+}
+
+func TestFixKeyPermissionsFromFormAndConnect(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("permissions are ACLs on Windows")
+	}
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	sshDir := filepath.Join(home, ".ssh")
+	key := filepath.Join(sshDir, "id_ed25519")
+	if err := os.MkdirAll(sshDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(key, []byte("key"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if !keyFileTooOpen(key) || keyFileTooOpen(key+".pub") {
+		t.Fatal("expected a 0644 private key to be too open")
+	}
+
+	m := model{form: newFormState(newFormInputs()), state: stateForm}
+	updated, _ := m.Update(testConnectionMsg{err: errors.New("Permissions 0644 for '" + key + "' are too open.")})
+	m = updated.(model)
+	if m.permFix.path != key || !strings.Contains(m.renderFormStatus(), "fix permissions") {
+		t.Fatalf("expected the form to offer the fix, got %+v", m.permFix)
+	}
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlF})
+	m = updated.(model)
+	if !m.form.testResult || keyFileTooOpen(key) {
+		t.Fatalf("expected the key fixed, got %q", m.form.testStatus)
+	}
+	if info, _ := os.Stat(sshDir); info.Mode().Perm() != 0o700 {
+		t.Fatalf("expected ~/.ssh to be 0700, got %v", info.Mode().Perm())
+	}
+
+	os.Chmod(key, 0o640)
+	hosts := []Host{{ID: "h1", Alias: "web", Hostname: "10.0.0.1", IdentityFile: key}}
+	m = model{rawHosts: hosts, list: newTestListModel(nil, hosts), historyList: newTestHistoryListModel()}
+	updated, _ = m.connectToHost(hosts[0])
+	if m = updated.(model); m.permFix.hostID != "h1" || !strings.Contains(m.status.message, "ctrl+f") {
+		t.Fatalf("expected the connect to stop and offer the fix, got %q", m.status.message)
+	}
+	updated, cmd := m.connectToHost(hosts[0])
+	if m = updated.(model); cmd == nil || m.permFix.hostID != "" {
+		t.Fatal("expected enter again to connect anyway")
+	}
+}
+
+func TestSessionRoundOffersKeyPermissionFix(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("permissions are ACLs on Windows")
+	}
+	key := filepath.Join(t.TempDir(), "id_ed25519")
+	if err := os.WriteFile(key, []byte("key"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	hosts := []Host{{ID: "h1", Alias: "web", Hostname: "10.0.0.1", IdentityFile: key}}
+	m := model{rawHosts: hosts, list: newTestListModel(nil, hosts), width: 100, height: 30}
+	m.round = sessionRoundState{hostIDs: []string{"h1"}, open: true}
+
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(model)
+	if cmd != nil || !m.round.open || m.round.next != 0 || !strings.Contains(m.round.warning, "ctrl+f") {
+		t.Fatalf("expected the round to stop on the open key, got %+v", m.round)
+	}
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlF})
+	m = updated.(model)
+	if keyFileTooOpen(key) || !m.round.open || !strings.Contains(m.round.warning, "Made "+key+" private") {
+		t.Fatalf("expected ctrl+f to fix the key from the round, got %+v", m.round)
+	}
+	updated, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if m = updated.(model); cmd == nil || m.round.open {
+		t.Fatalf("expected enter to connect after the fix, got %+v", m.round)
+	}
+}
@@ -0,0 +1,240 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// --- known_hosts Import ---
+
+// ctrl+k on the dashboard proposes the hosts in ~/.ssh/known_hosts that no
+// saved host covers, on the same review screen as the shell history import.
+// Hashed entries cannot be read back and are only counted. Bare addresses
+// are looked up in reverse DNS in the background, and a name found there
+// becomes the alias, so 10.0.4.17 is proposed as "db3" rather than
+// "10.0.4.17". Imported hosts land in an "Unsorted" group for later triage.
+
+// unsortedGroupName is the group known_hosts imports are added to.
+const unsortedGroupName = "Unsorted"
+
+// knownHostsLookupTimeout bounds all reverse DNS lookups of one import.
+const knownHostsLookupTimeout = 4 * time.Second
+
+// knownHostsImportFiles lists the known_hosts files to read; tests replace it.
+var knownHostsImportFiles = func() []string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil
+	}
+	return []string{
+		filepath.Join(home, ".ssh", "known_hosts"),
+		filepath.Join(home, ".ssh", "known_hosts2"),
+	}
+}
+
+// reverseLookup resolves an address to its names; tests replace it.
+var reverseLookup = net.DefaultResolver.LookupAddr
+
+// knownHostsDNSMsg carries the reverse DNS names found, keyed by address.
+type knownHostsDNSMsg struct {
+	names map[string]string
+}
+
+// knownHostsEntry reads the host names of one known_hosts line. ok is false
+// for comments, markers, and entries without a usable name; hashed reports
+// a hashed entry.
+func knownHostsEntry(line string) (hosts []Host, hashed, ok bool) {
+	fields := strings.Fields(line)
+	if len(fields) < 3 || strings.HasPrefix(fields[0], "#") || strings.HasPrefix(fields[0], "@") {
+		return nil, false, false
+	}
+	if strings.HasPrefix(fields[0], "|") {
+		return nil, true, false
+	}
+	for _, pattern := range strings.Split(fields[0], ",") {
+		if pattern == "" || strings.ContainsAny(pattern, "*?!") {
+			continue
+		}
+		h := Host{Hostname: pattern}
+		if strings.HasPrefix(pattern, "[") {
+			name, port, found := strings.Cut(pattern[1:], "]:")
+			if !found {
+				continue
+			}
+			h.Hostname = name
+			if port != "22" {
+				h.Port = port
+			}
+		}
+		if validateHostname(h.Hostname) != nil {
+			continue
+		}
+		hosts = append(hosts, h)
+	}
+	return hosts, false, len(hosts) > 0
+}
+
+// knownHostsCandidates proposes one host per known_hosts entry that no saved
+// host covers. An entry listing a name and an address is proposed by name.
+func knownHostsCandidates(hosts []Host, lines []string) ([]historyCandidate, int) {
+	var candidates []historyCandidate
+	seen := map[string]bool{}
+	hashed := 0
+	for _, line := range lines {
+		names, isHashed, ok := knownHostsEntry(line)
+		if isHashed {
+			hashed++
+		}
+		if !ok {
+			continue
+		}
+		h := names[0]
+		for _, name := range names {
+			if net.ParseIP(name.Hostname) == nil {
+				h = name
+				break
+			}
+		}
+		covered := false
+		for _, name := range names {
+			covered = covered || seen[historyKey(name)] || historyCovered(hosts, name)
+		}
+		for _, name := range names {
+			seen[historyKey(name)] = true
+		}
+		if !covered {
+			candidates = append(candidates, historyCandidate{host: h, uses: 1, selected: true})
+		}
+	}
+	assignCandidateAliases(hosts, candidates)
+	return candidates, hashed
+}
+
+// reverseLookupCmd resolves the addresses among the candidates in parallel.
+func reverseLookupCmd(candidates []historyCandidate) tea.Cmd {
+	var addrs []string
+	for _, c := range candidates {
+		if net.ParseIP(c.host.Hostname) != nil {
+			addrs = append(addrs, c.host.Hostname)
+		}
+	}
+	if len(addrs) == 0 {
+		return nil
+	}
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), knownHostsLookupTimeout)
+		defer cancel()
+		names := map[string]string{}
+		var mu sync.Mutex
+		var wg sync.WaitGroup
+		for _, addr := range addrs {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				found, err := reverseLookup(ctx, addr)
+				if err != nil || len(found) == 0 {
+					return
+				}
+				name := strings.TrimSuffix(found[0], ".")
+				if validateHostname(name) != nil {
+					return
+				}
+				mu.Lock()
+				names[addr] = name
+				mu.Unlock()
+			}()
+		}
+		wg.Wait()
+		return knownHostsDNSMsg{names: names}
+	}
+}
+
+func (m model) openKnownHostsImport() (tea.Model, tea.Cmd) {
+	m.clearListDeleteConfirm()
+	candidates, hashed := knownHostsCandidates(m.rawHosts, readLines(knownHostsImportFiles()))
+	if len(candidates) == 0 {
+		m.status.message = "No new hosts in known_hosts"
+		if hashed > 0 {
+			m.status.message = fmt.Sprintf("No new hosts in known_hosts (%d hashed entries cannot be read)", hashed)
+		}
+		m.status.isError = false
+		m.status.version++
+		return m, statusClearCmd(m.status.version)
+	}
+	cmd := reverseLookupCmd(candidates)
+	m.historyImport = historyImportState{candidates: candidates, source: importFromKnownHosts, hashed: hashed, resolving: cmd != nil}
+	m.state = stateHistoryImport
+	return m, cmd
+}
+
+// finishKnownHostsLookup renames the proposals whose address has a reverse
+// DNS name, unless the screen was closed in the meantime.
+func (m model) finishKnownHostsLookup(msg knownHostsDNSMsg) (tea.Model, tea.Cmd) {
+	s := &m.historyImport
+	if m.state != stateHistoryImport || s.source != importFromKnownHosts || !s.resolving {
+		return m, nil
+	}
+	s.resolving = false
+	taken := map[string]bool{}
+	for _, h := range m.rawHosts {
+		taken[strings.ToLower(strings.TrimSpace(h.Alias))] = true
+	}
+	for _, c := range s.candidates {
+		if msg.names[c.host.Hostname] == "" {
+			taken[strings.ToLower(c.host.Alias)] = true
+		}
+	}
+	for i := range s.candidates {
+		c := &s.candidates[i]
+		name := msg.names[c.host.Hostname]
+		if name == "" {
+			continue
+		}
+		c.dnsName = name
+		c.host.Alias = historyAlias(Host{Hostname: name}, taken)
+		taken[strings.ToLower(c.host.Alias)] = true
+	}
+	return m, nil
+}
+
+// unsortedGroupID returns the Unsorted group, creating it when missing.
+func (m *model) unsortedGroupID() string {
+	idx := findGroupByName(m.rawGroups, unsortedGroupName)
+	if idx == -1 || m.rawGroups[idx].Smart() {
+		m.rawGroups = append(m.rawGroups, Group{ID: newGroupID(), Name: unsortedGroupName, Expanded: true})
+		idx = len(m.rawGroups) - 1
+	}
+	return m.rawGroups[idx].ID
+}
+
+func knownHostsSummary(s historyImportState) string {
+	summary := fmt.Sprintf("%d hosts not saved yet, added to %q", len(s.candidates), unsortedGroupName)
+	if s.hashed > 0 {
+		summary += fmt.Sprintf(" · %d hashed skipped", s.hashed)
+	}
+	if s.resolving {
+		summary += " · resolving names…"
+	}
+	return summary
+}
+
+// knownHostsNote is the row suffix for an address: its reverse DNS name.
+func knownHostsNote(s historyImportState, c historyCandidate) string {
+	switch {
+	case c.dnsName != "":
+		return c.dnsName
+	case net.ParseIP(c.host.Hostname) == nil:
+		return ""
+	case s.resolving:
+		return "resolving…"
+	}
+	return "no reverse DNS"
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestKnownHostsCandidates(t *testing.T) {
+	saved := []Host{{ID: "h1", Alias: "web", Hostname: "web.example.com"}}
+	lines := []string{
+		"# comment",
+		"web.example.com,10.0.0.1 ssh-ed25519 AAAA",
+		"db.example.com,10.0.0.2 ssh-ed25519 AAAA",
+		"10.0.0.2 ecdsa-sha2-nistp256 AAAA",
+		"[git.example.com]:2222 ssh-ed25519 AAAA",
+		"|1|c2FsdA==|aGFzaA== ssh-ed25519 AAAA",
+		"@cert-authority *.example.com ssh-ed25519 AAAA",
+		"*.lab ssh-ed25519 AAAA",
+		"192.168.1.9 ssh-ed25519 AAAA",
+	}
+	got, hashed := knownHostsCandidates(saved, lines)
+	if hashed != 1 {
+		t.Fatalf("expected one hashed entry, got %d", hashed)
+	}
+	if len(got) != 3 {
+		t.Fatalf("expected three proposals, got %+v", got)
+	}
+	if got[0].host.Hostname != "db.example.com" || got[0].host.Alias != "db" {
+		t.Fatalf("expected db proposed by name, got %+v", got[0].host)
+	}
+	if got[1].host.Hostname != "git.example.com" || got[1].host.Port != "2222" {
+		t.Fatalf("expected git on port 2222, got %+v", got[1].host)
+	}
+	if got[2].host.Alias != "192.168.1.9" {
+		t.Fatalf("expected the bare address as alias, got %+v", got[2].host)
+	}
+}
+
+func TestKnownHostsImportResolvesAndGroups(t *testing.T) {
+	writeTempConfig(t, nil)
+	file := filepath.Join(t.TempDir(), "known_hosts")
+	if err := os.WriteFile(file, []byte("10.0.0.7 ssh-ed25519 AAAA\n10.0.0.8 ssh-ed25519 AAAA\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	oldFiles, oldLookup := knownHostsImportFiles, reverseLookup
+	knownHostsImportFiles = func() []string { return []string{file} }
+	reverseLookup = func(_ context.Context, addr string) ([]string, error) {
+		if addr == "10.0.0.7" {
+			return []string{"db3.internal.example.com."}, nil
+		}
+		return nil, &os.PathError{Op: "lookup", Path: addr, Err: os.ErrNotExist}
+	}
+	t.Cleanup(func() { knownHostsImportFiles, reverseLookup = oldFiles, oldLookup })
+
+	m := model{state: stateList, list: newTestListModel(nil, nil), historyList: newTestHistoryListModel()}
+	result, cmd := m.updateList(tea.KeyMsg{Type: tea.KeyCtrlK})
+	got := result.(model)
+	if got.state != stateHistoryImport || !got.historyImport.resolving || cmd == nil {
+		t.Fatalf("expected the review screen with lookups running, got %+v", got.historyImport)
+	}
+	if view := got.renderHistoryImportView(); !strings.Contains(view, "resolving names") {
+		t.Fatalf("expected the lookup shown\n%s", view)
+	}
+	result, _ = got.Update(cmd())
+	got = result.(model)
+	if c := got.historyImport.candidates[0]; c.host.Alias != "db3" || c.host.Hostname != "10.0.0.7" {
+		t.Fatalf("expected db3 from reverse DNS, got %+v", c.host)
+	}
+	if view := got.renderHistoryImportView(); !strings.Contains(view, "no reverse DNS") {
+		t.Fatalf("expected the unresolved address noted\n%s", view)
+	}
+
+	result, _ = got.updateHistoryImport(tea.KeyMsg{Type: tea.KeyEnter})
+	got = result.(model)
+	idx := findGroupByName(got.rawGroups, unsortedGroupName)
+	if idx == -1 || len(got.rawHosts) != 2 {
+		t.Fatalf("expected two hosts and an Unsorted group, got %+v %+v", got.rawHosts, got.rawGroups)
+	}
+	for _, h := range got.rawHosts {
+		if h.GroupID != got.rawGroups[idx].ID {
+			t.Fatalf("expected %s in Unsorted, got group %q", h.Alias, h.GroupID)
+		}
+	}
+	if got.status.message != "Added 2 hosts from known_hosts" {
+		t.Fatalf("unexpected status %q", got.status.message)
+	}
+}
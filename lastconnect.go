@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// --- Reconnect To The Last Host ---
+
+// `l` on the dashboard and `assho last` on the command line connect straight
+// to the most recent host in the connection history, skipping navigation
+// and search. History entries for hosts deleted since are passed over.
+
+// lastHost is the most recently connected host that still exists.
+func lastHost(hosts []Host, history []HistoryEntry) (Host, bool) {
+	for _, e := range history {
+		for _, h := range hosts {
+			if h.ID == e.HostID {
+				return h, true
+			}
+			for _, c := range h.Containers {
+				if c.ID == e.HostID {
+					c.ParentID = h.ID
+					return c, true
+				}
+			}
+		}
+	}
+	return Host{}, false
+}
+
+// connectToLastHost selects the last host in the list and connects to it.
+func (m model) connectToLastHost() (tea.Model, tea.Cmd) {
+	h, ok := lastHost(m.rawHosts, m.history)
+	if !ok {
+		m.status.message = "No connection history yet"
+		m.status.isError = true
+		m.status.version++
+		return m, statusClearCmd(m.status.version)
+	}
+	m.reselectItem(h.ID, false)
+	return m.connectToHost(h)
+}
+
+func cliLast() {
+	groups, hosts, history, err := loadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error loading config: %v\n", err)
+		os.Exit(1)
+	}
+	h, ok := lastHost(hosts, history)
+	if !ok {
+		fmt.Fprintln(os.Stderr, "no connection history yet")
+		os.Exit(1)
+	}
+	fmt.Fprintln(os.Stderr, "→ "+h.Alias)
+	cliConnectHost(h, hostInventory{hosts: hosts, groups: groups})
+}
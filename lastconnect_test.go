@@ -0,0 +1,48 @@
+package main
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestLastHostSkipsDeletedHosts(t *testing.T) {
+	hosts := []Host{
+		{ID: "h1", Alias: "web"},
+		{ID: "h2", Alias: "docker", Containers: []Host{{ID: "c1", Alias: "redis", IsContainer: true}}},
+	}
+	history := []HistoryEntry{{HostID: "gone"}, {HostID: "c1"}, {HostID: "h1"}}
+	h, ok := lastHost(hosts, history)
+	if !ok || h.ID != "c1" || h.ParentID != "h2" {
+		t.Fatalf("expected the container with its parent, got %+v", h)
+	}
+	if _, ok := lastHost(hosts, []HistoryEntry{{HostID: "gone"}}); ok {
+		t.Fatal("expected no host when every entry is stale")
+	}
+}
+
+func TestLastKeyConnectsToMostRecentHost(t *testing.T) {
+	host := Host{ID: "h1", Alias: "web", Hostname: "10.0.0.1", User: "root", Port: "22", Transport: transportPSRemoting}
+	m := model{
+		state:       stateList,
+		rawHosts:    []Host{{ID: "h0", Alias: "db"}, host},
+		list:        newTestListModel(nil, []Host{{ID: "h0", Alias: "db"}, host}),
+		historyList: newTestHistoryListModel(),
+		history:     []HistoryEntry{{HostID: "h1", Alias: "web"}},
+	}
+	writeTempConfig(t, m.rawHosts)
+	result, cmd := m.updateList(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("l")})
+	got := result.(model)
+	if got.sshToRun == nil || got.sshToRun.ID != "h1" || cmd == nil {
+		t.Fatalf("expected l to connect to web, status %q", got.status.message)
+	}
+	if selected, _ := got.list.SelectedItem().(Host); selected.ID != "h1" {
+		t.Fatalf("expected web selected, got %q", selected.Alias)
+	}
+
+	m.history = nil
+	result, _ = m.updateList(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("l")})
+	if got = result.(model); !got.status.isError || got.sshToRun != nil {
+		t.Fatal("expected an error with no history")
+	}
+}
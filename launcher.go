@@ -0,0 +1,51 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// --- Launcher Mode ---
+
+// With ASSHO_LAUNCHER=1 the dashboard opens already searching, so typing
+// filters straight away, and Enter connects as soon as the search names one
+// host: either it is the only match, or its alias is exactly what was
+// typed. With several matches Enter keeps the filter as usual, and Esc
+// leaves the search for the regular dashboard keys.
+
+func launcherMode() bool {
+	return envBool("ASSHO_LAUNCHER", false)
+}
+
+// startFiltering opens the list's search with every host searchable, as
+// pressing / does.
+func (m *model) startFiltering() tea.Cmd {
+	m.list.SetItems(flattenHostsImpl(m.rawGroups, m.listHosts(), false, m.showArchived))
+	var cmd tea.Cmd
+	m.list, cmd = m.list.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'/'}})
+	return cmd
+}
+
+// launcherMatch is the host the search names on its own, if any.
+func launcherMatch(items []list.Item, query string) (Host, bool) {
+	query = strings.TrimSpace(query)
+	var matches []Host
+	seen := map[string]bool{}
+	for _, item := range items {
+		h, ok := item.(Host)
+		if !ok || seen[h.ID] {
+			continue
+		}
+		seen[h.ID] = true
+		if query != "" && strings.EqualFold(h.Alias, query) {
+			return h, true
+		}
+		matches = append(matches, h)
+	}
+	if len(matches) == 1 && query != "" {
+		return matches[0], true
+	}
+	return Host{}, false
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestLauncherMatch(t *testing.T) {
+	hosts := []Host{{ID: "h1", Alias: "web"}, {ID: "h2", Alias: "webhooks", Pinned: true}, {ID: "h3", Alias: "db"}}
+	items := flattenHosts(nil, hosts)
+	if h, ok := launcherMatch(items, "WEB"); !ok || h.ID != "h1" {
+		t.Fatalf("expected an exact alias to win among several matches, got %+v", h)
+	}
+	if _, ok := launcherMatch(items, "we"); ok {
+		t.Fatal("expected several matches to leave the choice to the user")
+	}
+	// A pinned host is listed twice but is still one match.
+	if h, ok := launcherMatch(flattenHosts(nil, hosts[1:2]), "hooks"); !ok || h.ID != "h2" {
+		t.Fatalf("expected the only host to match, got %+v", h)
+	}
+}
+
+func TestLauncherModeConnectsOnEnter(t *testing.T) {
+	t.Setenv("ASSHO_LAUNCHER", "1")
+	hosts := []Host{
+		{ID: "h1", Alias: "web", Hostname: "10.0.0.1", User: "root", Port: "22", Transport: transportPSRemoting},
+		{ID: "h2", Alias: "db", Hostname: "10.0.0.2", User: "root", Port: "22", Transport: transportPSRemoting},
+	}
+	writeTempConfig(t, hosts)
+	m := newModel(nil, hosts, nil, nil)
+	if m.list.FilterState() != list.Filtering {
+		t.Fatalf("expected the dashboard to open searching, got %v", m.list.FilterState())
+	}
+	for _, r := range "db" {
+		result, cmd := m.updateList(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+		m = result.(model)
+		m = applyFilterMatches(t, m, cmd)
+	}
+	result, _ := m.updateList(tea.KeyMsg{Type: tea.KeyEnter})
+	if got := result.(model); got.sshToRun == nil || got.sshToRun.ID != "h2" {
+		t.Fatalf("expected enter to connect to db, filter state %v", got.list.FilterState())
+	}
+}
+
+// applyFilterMatches runs the list's filter command and hands it the result.
+func applyFilterMatches(t *testing.T, m model, cmd tea.Cmd) model {
+	t.Helper()
+	if cmd == nil {
+		return m
+	}
+	var cmds []tea.Cmd
+	switch msg := cmd().(type) {
+	case tea.BatchMsg:
+		cmds = msg
+	case list.FilterMatchesMsg:
+		m.list, _ = m.list.Update(msg)
+		return m
+	}
+	for _, c := range cmds {
+		if c == nil {
+			continue
+		}
+		if msg, ok := c().(list.FilterMatchesMsg); ok {
+			m.list, _ = m.list.Update(msg)
+		}
+	}
+	return m
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"net/netip"
+	"slices"
+	"strings"
+)
+
+// --- libvirt Guests ---
+
+// Expanding a host scans it for Docker containers, LXD/Incus instances,
+// jails and zones, and libvirt guests in one ssh round trip. Guests are nested like containers; one with an address from
+// `virsh domifaddr` is reached over ssh with its host as the jump host, and
+// one without falls back to `virsh console` on the host.
+
+const (
+	hostKindVM = "vm"
+	libvirtURI = "qemu:///system"
+)
+
+// guestScanScript prints one tab-separated line per container ("docker"),
+// LXD/Incus listing ("lxc", "incus"), jail or zone ("jail", "zone"), guest
+// ("vm"), and guest address ("addr"). Each tool is probed on its own, so a
+// stopped docker daemon does not hide the other tools' results; the script
+// fails only when docker ps fails and no other tool is installed, or when
+// none is.
+var guestScanScript = strings.Join(slices.Concat([]string{
+	`found=`,
+	`dockerfailed=`,
+	`if command -v docker >/dev/null 2>&1; then`,
+	`  docker ps -a --format "docker` + "\t" + `{{.ID}}` + "\t" + `{{.Names}}` + "\t" + `{{.Image}}` + "\t" + `{{.Status}}` + "\t" + `{{.Ports}}" && found=1 || dockerfailed=1`,
+	`fi`,
+}, lxdScanLines, jailScanLines, []string{
+	`if command -v virsh >/dev/null 2>&1; then`,
+	`  found=1`,
+	`  virsh -q -c ` + libvirtURI + ` list --name 2>/dev/null | while IFS= read -r dom; do`,
+	`    [ -n "$dom" ] || continue`,
+	`    printf "vm\t%s\n" "$dom"`,
+	`    { virsh -c ` + libvirtURI + ` domifaddr "$dom"; virsh -c ` + libvirtURI + ` domifaddr --source arp "$dom"; } 2>/dev/null |`,
+	`      while read -r _ _ proto addr; do printf "addr\t%s\t%s\t%s\n" "$dom" "$proto" "$addr"; done`,
+	`  done`,
+	`fi`,
+	`[ -n "$found" ] || [ -z "$dockerfailed" ] || exit 1`,
+	`[ -n "$found" ] || { echo "none of docker, lxc, incus, jls, zoneadm, or virsh is installed" >&2; exit 127; }`,
+}), "\n")
+
+func (h Host) isGuest() bool {
+	return h.IsContainer && h.Kind == hostKindVM
+}
+
+// parseGuestScan reads guestScanScript output into nested hosts. A guest's
+// Hostname is its first usable IPv4 address, an IPv6 one if it has no IPv4,
+// and empty when libvirt reports none.
+func parseGuestScan(output, parentID string) []Host {
+	var hosts []Host
+	guests := map[string]int{}
+	for _, line := range strings.Split(output, "\n") {
+		parts := strings.Split(strings.TrimRight(line, "\r"), "\t")
+		switch {
+		case parts[0] == "docker" && len(parts) >= 3 && parts[2] != "":
+			hosts = append(hosts, newDockerHost(parts[2:], parentID))
+		case (parts[0] == "lxc" || parts[0] == "incus") && len(parts) >= 2:
+			hosts = append(hosts, parseLXDList(parts[0], strings.Join(parts[1:], "\t"), parentID)...)
+		case (parts[0] == hostKindJail || parts[0] == hostKindZone) && len(parts) >= 2 && parts[1] != "":
+			hosts = append(hosts, newJailHost(parts[0], parts[1], parentID))
+		case parts[0] == "vm" && len(parts) >= 2 && parts[1] != "":
+			if _, seen := guests[parts[1]]; seen {
+				continue
+			}
+			guests[parts[1]] = len(hosts)
+			hosts = append(hosts, Host{
+				ID:          newHostID(),
+				Alias:       parts[1],
+				IsContainer: true,
+				Kind:        hostKindVM,
+				ParentID:    parentID,
+			})
+		case parts[0] == "addr" && len(parts) >= 4:
+			i, ok := guests[parts[1]]
+			if !ok {
+				continue
+			}
+			addr, err := netip.ParseAddr(stripPrefixLength(strings.TrimSpace(parts[3])))
+			if err != nil || addr.IsLoopback() || addr.IsLinkLocalUnicast() {
+				continue
+			}
+			current := hosts[i].Hostname
+			if current == "" || (addr.Is4() && strings.Contains(current, ":")) {
+				hosts[i].Hostname = addr.String()
+			}
+		}
+	}
+	return hosts
+}
+
+// newDockerHost builds a container from the name, image, status, and ports
+// columns of a docker line.
+func newDockerHost(fields []string, parentID string) Host {
+	fields = append(fields, "", "", "")
+	h := Host{
+		ID:          newHostID(),
+		Alias:       fields[0],
+		Hostname:    fields[0],
+		User:        "root",
+		IsContainer: true,
+		ParentID:    parentID,
+	}
+	if fields[1] != "" || fields[2] != "" {
+		h.Docker = &DockerInfo{
+			Image:  fields[1],
+			State:  dockerState(fields[2]),
+			Health: dockerHealth(fields[2]),
+			Ports:  parseDockerCLIPorts(fields[3]),
+		}
+	}
+	return h
+}
+
+// guestJump is the -J spec that reaches a guest through its (resolved) host.
+func guestJump(parent Host) string {
+	if parent.isLocal() {
+		return ""
+	}
+	target := parent.Alias
+	if !parent.UseSSHConfig {
+		port := parent.Port
+		if port == "22" {
+			port = ""
+		}
+		target = hostPort(parent.Hostname, port)
+		if parent.User != "" {
+			target = parent.User + "@" + target
+		}
+		if parent.ProxyJump != "" {
+			target = parent.ProxyJump + "," + target
+		}
+	}
+	return target
+}
+
+// guestEndpoint is what ssh dials for a guest with an address. The guest
+// inherits its host's user and key, which is how most homelab images are
+// provisioned; the host's password is never sent to a guest.
+func guestEndpoint(parent, guest Host) Host {
+	return Host{
+		ID:           guest.ID,
+		Alias:        guest.Alias,
+		Hostname:     guest.Hostname,
+		User:         parent.User,
+		IdentityFile: parent.IdentityFile,
+		ProxyJump:    guestJump(parent),
+
+		ConnectTimeout: parent.ConnectTimeout,
+	}
+}
+
+func guestConsoleCommand(name string) string {
+	return "virsh -c " + libvirtURI + " console " + shellQuote(name)
+}
+
+func guestAddressLabel(h Host) string {
+	if h.Hostname == "" {
+		return "console only"
+	}
+	return h.Hostname
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseGuestScan(t *testing.T) {
+	output := strings.Join([]string{
+		"docker\tabc123\tweb\tnginx:latest\tUp 2 hours (healthy)\t0.0.0.0:8080->80/tcp, :::8080->80/tcp",
+		"docker\tdef456\tmigrate\tapp:1.4\tExited (0) 3 days ago\t",
+		"vm\tubuntu-lab",
+		"addr\tubuntu-lab\tipv6\tfd00::10/64",
+		"addr\tubuntu-lab\tipv4\t192.168.122.45/24",
+		"addr\tubuntu-lab\tipv4\t192.168.122.46/24",
+		"vm\twin11",
+		"addr\twin11\tipv6\tfe80::1/64",
+		"addr\twin11\taddress\tProtocol Address",
+		"error: failed to get domain interfaces",
+		"addr\tunknown\tipv4\t10.0.0.1/24",
+	}, "\n")
+	hosts := parseGuestScan(output, "p1")
+	if len(hosts) != 4 {
+		t.Fatalf("expected two containers and two guests, got %+v", hosts)
+	}
+	if hosts[0].Alias != "web" || hosts[0].isGuest() || hosts[0].ParentID != "p1" {
+		t.Fatalf("unexpected container %+v", hosts[0])
+	}
+	if got := hosts[0].Docker.summary(); got != "nginx:latest · healthy · 8080→80/tcp" {
+		t.Fatalf("unexpected container summary %q", got)
+	}
+	if hosts[1].Docker.running() || hosts[1].Docker.summary() != "app:1.4 · exited" {
+		t.Fatalf("expected an exited container, got %+v", hosts[1].Docker)
+	}
+	if !hosts[2].isGuest() || hosts[2].Hostname != "192.168.122.45" {
+		t.Fatalf("expected the first IPv4 address to win, got %+v", hosts[2])
+	}
+	if !hosts[3].isGuest() || hosts[3].Hostname != "" {
+		t.Fatalf("link-local only guest should be console only, got %+v", hosts[3])
+	}
+}
+
+func TestGuestScanSurvivesStoppedDocker(t *testing.T) {
+	bin := t.TempDir()
+	stub := func(name, body string) {
+		if err := os.WriteFile(filepath.Join(bin, name), []byte("#!/bin/sh\n"+body+"\n"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	stub("docker", "echo 'Cannot connect to the Docker daemon' >&2; exit 1")
+	run := func() (string, error) {
+		cmd := exec.Command("/bin/sh", "-c", guestScanScript)
+		cmd.Env = []string{"PATH=" + bin}
+		out, err := cmd.CombinedOutput()
+		return string(out), err
+	}
+	if _, err := run(); err == nil {
+		t.Fatal("expected the scan to fail when docker is the only tool and fails")
+	}
+
+	stub("virsh", `case "$*" in *"list --name"*) echo lab ;; esac`)
+	out, err := run()
+	if err != nil {
+		t.Fatalf("expected the virsh results despite docker failing, got %v\n%s", err, out)
+	}
+	if guests := parseGuestScan(out, "p1"); len(guests) != 1 || guests[0].Alias != "lab" {
+		t.Fatalf("expected the libvirt guest, got %+v", guests)
+	}
+}
+
+func TestBuildConnectCommandGuestJumpsThroughParent(t *testing.T) {
+	parent := Host{ID: "p1", Alias: "kvm", Hostname: "10.0.0.5", User: "admin", Port: "2222", IdentityFile: "/keys/lab", Password: "hunter2", ProxyJump: "bastion"}
+	guest := Host{ID: "g1", Alias: "ubuntu-lab", Hostname: "192.168.122.45", IsContainer: true, Kind: hostKindVM, ParentID: "p1"}
+
+	cmd, err := buildConnectCommand(guest, hostInventory{hosts: []Host{parent, guest}}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cmd.binary != "ssh" || len(cmd.extraEnv) != 0 {
+		t.Fatalf("the host's password must not be used for the guest: %+v", cmd)
+	}
+	want := []string{"-l", "admin", "-i", "/keys/lab", "-J", "bastion,admin@10.0.0.5:2222", "192.168.122.45"}
+	if !slices.Equal(cmd.args, want) {
+		t.Fatalf("got %v, want %v", cmd.args, want)
+	}
+}
+
+func TestBuildConnectCommandGuestFallsBackToConsole(t *testing.T) {
+	parent := Host{ID: "p1", Alias: "kvm", Hostname: "10.0.0.5", User: "root"}
+	guest := Host{ID: "g1", Alias: "win 11", IsContainer: true, Kind: hostKindVM, ParentID: "p1"}
+
+	cmd, err := buildConnectCommand(guest, hostInventory{hosts: []Host{parent, guest}}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"-t", "-l", "root", "10.0.0.5", "virsh -c qemu:///system console 'win 11'"}
+	if cmd.sshHost.ID != "p1" || !slices.Equal(cmd.args, want) {
+		t.Fatalf("got %v via %s, want %v", cmd.args, cmd.sshHost.Alias, want)
+	}
+}
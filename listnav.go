@@ -0,0 +1,210 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// --- List Navigation ---
+
+// Long host lists page with PgUp/PgDn, and a line above the list keeps the
+// group a page starts inside in view, with the page number and the rows
+// shown, so a page of indented hosts is never anonymous. Home or gg goes to
+// the top and G or End to the bottom. g still opens the new-group prompt at
+// once; a second g within gPrefixTimeout, before anything is typed, closes it
+// again and goes to the top instead. Ctrl+G opens a searchable list of groups
+// and jumps to the one picked, opening it if it is collapsed.
+
+// pageGroupHeader names the group the current page starts inside, or "" when
+// the page starts on a group header or an ungrouped host.
+func pageGroupHeader(items []list.Item, start int) string {
+	if start <= 0 || start >= len(items) {
+		return ""
+	}
+	if h, ok := items[start].(Host); !ok || h.ListIndent == 0 {
+		return ""
+	}
+	for i := start - 1; i >= 0; i-- {
+		switch item := items[i].(type) {
+		case groupItem:
+			return item.Name
+		case Host:
+			if item.ListIndent == 0 {
+				return ""
+			}
+		}
+	}
+	return ""
+}
+
+// renderListPosition is the line above the host list: the group the page
+// starts inside and, with more than one page, where the page is.
+func (m model) renderListPosition(width int) string {
+	items := m.list.VisibleItems()
+	start, end := m.list.Paginator.GetSliceBounds(len(items))
+	var left string
+	if name := pageGroupHeader(items, start); name != "" {
+		left = formSectionStyle.Render("▼ 📁 "+name) + formHintStyle.Render(" (continued)")
+	}
+	var right string
+	if m.list.Paginator.TotalPages > 1 {
+		right = formHintStyle.Render(fmt.Sprintf("page %d/%d · rows %d–%d of %d", m.list.Paginator.Page+1, m.list.Paginator.TotalPages, start+1, end, len(items)))
+	}
+	gap := max(width-1-lipgloss.Width(left)-lipgloss.Width(right), 1)
+	return ansi.Truncate(" "+left+strings.Repeat(" ", gap)+right, width, "") + "\n"
+}
+
+// gPrefixTimeout is how soon the second g of gg has to follow the first.
+const gPrefixTimeout = 400 * time.Millisecond
+
+type gPrefixMsg struct{ seq int }
+
+type gPrefixState struct {
+	pending bool
+	seq     int
+}
+
+// startGPrefix opens the new-group prompt and waits briefly for the second g
+// of gg.
+func (m model) startGPrefix() (tea.Model, tea.Cmd) {
+	m.openGroupPrompt("create", "", "")
+	m.gPrefix.pending = true
+	m.gPrefix.seq++
+	seq := m.gPrefix.seq
+	return m, tea.Tick(gPrefixTimeout, func(time.Time) tea.Msg { return gPrefixMsg{seq: seq} })
+}
+
+// completeGPrefix turns a g pressed into the still-empty new-group prompt
+// into gg, reporting whether it did.
+func (m *model) completeGPrefix(msg tea.KeyMsg) bool {
+	pending := m.gPrefix.pending
+	m.gPrefix.pending = false
+	if !pending || msg.String() != "g" || m.groupPrompt.action != "create" || m.groupPrompt.input.Value() != "" {
+		return false
+	}
+	m.state = stateList
+	m.groupPrompt.action = ""
+	m.groupPrompt.target = ""
+	m.form.formError = ""
+	m.list.Select(0)
+	return true
+}
+
+func (m model) handleGPrefixTimeout(msg gPrefixMsg) (tea.Model, tea.Cmd) {
+	if msg.seq == m.gPrefix.seq {
+		m.gPrefix.pending = false
+	}
+	return m, nil
+}
+
+// --- Jump To Group ---
+
+type groupJumpState struct {
+	filter textinput.Model
+	cursor int
+}
+
+// openGroupJump lists the dashboard's groups to jump to.
+func (m model) openGroupJump() (tea.Model, tea.Cmd) {
+	filter := textinput.New()
+	filter.Prompt = "  Search "
+	filter.Placeholder = "group name"
+	filter.PromptStyle = lipgloss.NewStyle().Foreground(colorHighlight).Bold(true)
+	filter.TextStyle = lipgloss.NewStyle().Foreground(colorText)
+	filter.Cursor.Style = lipgloss.NewStyle().Foreground(colorSecondary)
+	m.groupJump = groupJumpState{filter: filter}
+	if len(m.groupJumpOptions()) == 0 {
+		m.status.message = "No groups to jump to"
+		m.status.isError = true
+		m.status.version++
+		return m, statusClearCmd(m.status.version)
+	}
+	m.state = stateGroupJump
+	return m, m.groupJump.filter.Focus()
+}
+
+// groupJumpOptions are the groups in list order matching the search.
+func (m model) groupJumpOptions() []Group {
+	query := strings.ToLower(strings.TrimSpace(m.groupJump.filter.Value()))
+	var groups []Group
+	for _, item := range flattenHostsImpl(m.rawGroups, m.listHosts(), true, m.showArchived) {
+		if g, ok := item.(groupItem); ok && strings.Contains(strings.ToLower(g.Name), query) {
+			groups = append(groups, g.Group)
+		}
+	}
+	return groups
+}
+
+// jumpToGroup opens g if it is collapsed and selects its header.
+func (m model) jumpToGroup(g Group) (tea.Model, tea.Cmd) {
+	m.state = stateList
+	if m.list.FilterState() != list.Unfiltered {
+		m.list.ResetFilter()
+	}
+	if idx := findGroupIndexByID(m.rawGroups, g.ID); idx != -1 {
+		m.rawGroups[idx].Expanded = true
+	}
+	m.refreshList()
+	m.reselectItem(g.ID, true)
+	return m, nil
+}
+
+func (m model) updateGroupJump(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	options := m.groupJumpOptions()
+	switch msg.String() {
+	case "ctrl+c":
+		m.quitting = true
+		return m, tea.Quit
+	case "esc":
+		m.state = stateList
+		return m, nil
+	case "up", "shift+tab":
+		if m.groupJump.cursor > 0 {
+			m.groupJump.cursor--
+		}
+		return m, nil
+	case "down", "tab":
+		if m.groupJump.cursor < len(options)-1 {
+			m.groupJump.cursor++
+		}
+		return m, nil
+	case "enter":
+		if len(options) == 0 {
+			return m, nil
+		}
+		return m.jumpToGroup(options[min(m.groupJump.cursor, len(options)-1)])
+	}
+	var cmd tea.Cmd
+	m.groupJump.filter, cmd = m.groupJump.filter.Update(msg)
+	m.groupJump.cursor = 0
+	return m, cmd
+}
+
+func (m model) renderGroupJumpView() string {
+	width, height := normalizedSize(m.width, m.height)
+	inner := max(min(width-6, 100)-6, 24)
+	options := m.groupJumpOptions()
+	var b strings.Builder
+	b.WriteString(lipgloss.NewStyle().Bold(true).Foreground(colorText).Render("JUMP TO GROUP") + "\n\n")
+	b.WriteString(m.groupJump.filter.View() + "\n\n")
+	if len(options) == 0 {
+		b.WriteString(formHintStyle.Render("  No group matches") + "\n")
+	}
+	rows := max(height-14, 3)
+	start := max(min(m.groupJump.cursor-rows/2, len(options)-rows), 0)
+	for i := start; i < min(start+rows, len(options)); i++ {
+		b.WriteString(selectionLine(i == m.groupJump.cursor, ansi.Truncate(options[i].Name, inner-2, "…")) + "\n")
+	}
+	if len(options) > rows {
+		b.WriteString(formHintStyle.Render(fmt.Sprintf("  %d of %d rows shown; type to narrow", rows, len(options))) + "\n")
+	}
+	b.WriteString("\n" + helpEntry("enter", "jump") + "  " + helpEntry("↑↓", "move") + "  " + helpEntry("esc", "back"))
+	return centeredWorkspace(b.String(), width, height)
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+func newListNavTestModel() model {
+	groups := []Group{{ID: "g1", Name: "prod", Expanded: true}, {ID: "g2", Name: "staging"}}
+	var hosts []Host
+	for i := 0; i < 30; i++ {
+		hosts = append(hosts, Host{ID: fmt.Sprint("h", i), Alias: fmt.Sprint("web", i), Hostname: "10.0.0.1", User: "root", GroupID: "g1"})
+	}
+	hosts = append(hosts, Host{ID: "s1", Alias: "stage", Hostname: "10.0.1.1", GroupID: "g2"})
+	m := model{state: stateList, rawGroups: groups, rawHosts: hosts, list: newTestListModel(groups, hosts), historyList: newTestHistoryListModel()}
+	m.groupPrompt.input = textinput.New()
+	result, _ := m.Update(tea.WindowSizeMsg{Width: 100, Height: 40})
+	return result.(model)
+}
+
+func TestListPositionKeepsGroupInView(t *testing.T) {
+	m := newListNavTestModel()
+	if out := ansi.Strip(m.renderListPosition(96)); strings.Contains(out, "continued") || !strings.Contains(out, "page 1/") {
+		t.Fatalf("expected only the page on the first page, got %q", out)
+	}
+	result, _ := m.updateList(tea.KeyMsg{Type: tea.KeyPgDown})
+	m = result.(model)
+	if out := ansi.Strip(m.renderListPosition(96)); !strings.Contains(out, "prod (continued)") || !strings.Contains(out, "page 2/") {
+		t.Fatalf("expected the group carried onto the next page, got %q", out)
+	}
+}
+
+func TestHomeEndAndGroupPrompt(t *testing.T) {
+	m := newListNavTestModel()
+	m.list.Select(10)
+	result, _ := m.updateList(tea.KeyMsg{Type: tea.KeyHome})
+	if got := result.(model); got.list.Index() != 0 || got.state != stateList {
+		t.Fatalf("expected home to go to the top, index %d", got.list.Index())
+	}
+	result, _ = m.updateList(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("G")})
+	if got := result.(model); got.list.Index() != len(got.list.Items())-1 {
+		t.Fatalf("expected G to go to the bottom, index %d", got.list.Index())
+	}
+
+	result, cmd := m.updateList(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("g")})
+	prompt := result.(model)
+	if prompt.state != stateGroupPrompt || cmd == nil {
+		t.Fatalf("expected g to open the new-group prompt at once, state %v", prompt.state)
+	}
+	result, _ = prompt.updateGroupPrompt(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("g")})
+	if got := result.(model); got.state != stateList || got.list.Index() != 0 {
+		t.Fatalf("expected gg to go to the top, state %v index %d", got.state, got.list.Index())
+	}
+
+	result, _ = prompt.handleGPrefixTimeout(gPrefixMsg{seq: prompt.gPrefix.seq})
+	result, _ = result.(model).updateGroupPrompt(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("g")})
+	if got := result.(model); got.state != stateGroupPrompt || got.groupPrompt.input.Value() != "g" {
+		t.Fatalf("expected a late g to be typed into the prompt, got %q", got.groupPrompt.input.Value())
+	}
+}
+
+func TestJumpToGroup(t *testing.T) {
+	m := newListNavTestModel()
+	result, _ := m.updateList(tea.KeyMsg{Type: tea.KeyCtrlG})
+	got := result.(model)
+	if got.state != stateGroupJump {
+		t.Fatalf("expected the group picker, state %v", got.state)
+	}
+	result, _ = got.updateGroupJump(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("stag")})
+	result, _ = result.(model).updateGroupJump(tea.KeyMsg{Type: tea.KeyEnter})
+	got = result.(model)
+	g, ok := got.list.SelectedItem().(groupItem)
+	if got.state != stateList || !ok || g.ID != "g2" || !got.rawGroups[1].Expanded {
+		t.Fatalf("expected staging opened and selected, got %+v", got.list.SelectedItem())
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// --- Local Host ---
+
+// A host whose connection type is "local" stands for this machine. Its scan
+// runs the container script directly, so the workstation's Docker, LXD, and
+// libvirt guests sit in the same tree as remote ones, and connecting to it or
+// its children runs the shell or exec command here instead of over ssh.
+
+const transportLocal = "local"
+
+func (h Host) isLocal() bool {
+	return h.Transport == transportLocal && !h.IsContainer
+}
+
+// buildLocalCommand runs command with sh, or opens a login shell when it is
+// empty.
+func buildLocalCommand(h Host, command string) connectCommand {
+	if command != "" {
+		return connectCommand{binary: "sh", args: []string{"-c", command}, sshHost: h}
+	}
+	shell := os.Getenv("SHELL")
+	if shell == "" {
+		shell = "/bin/sh"
+	}
+	return connectCommand{binary: shell, args: []string{"-l"}, sshHost: h}
+}
+
+// runLocalCommand is runSSHWithArgsContext for the local host h, bounded by
+// the same command timeout as a remote one.
+func runLocalCommand(parent context.Context, h Host, command string) (string, string, error) {
+	ctx, cancel := context.WithTimeout(parent, h.commandTimeout())
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		if parent.Err() != nil {
+			return "", stderr.String(), parent.Err()
+		}
+		if ctx.Err() == context.DeadlineExceeded {
+			return "", stderr.String(), fmt.Errorf("command timed out after %s", h.commandTimeout())
+		}
+		out := strings.TrimSpace(stderr.String() + stdout.String())
+		if out == "" {
+			out = err.Error()
+		}
+		return "", stderr.String(), fmt.Errorf("%s", out)
+	}
+	return stdout.String(), stderr.String(), nil
+}
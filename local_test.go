@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestBuildConnectCommandLocalHost(t *testing.T) {
+	t.Setenv("SHELL", "/bin/zsh")
+	local := Host{ID: "l1", Alias: "workstation", Hostname: "localhost", Transport: transportLocal}
+	container := Host{ID: "c1", Alias: "postgres", Hostname: "postgres", IsContainer: true, ParentID: "l1"}
+
+	cmd, err := buildConnectCommand(local, hostInventory{hosts: []Host{local}}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cmd.binary != "/bin/zsh" || !slices.Equal(cmd.args, []string{"-l"}) {
+		t.Fatalf("expected a local login shell, got %s %v", cmd.binary, cmd.args)
+	}
+
+	cmd, err = buildConnectCommand(container, hostInventory{hosts: []Host{local, container}}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"-c", "docker exec -it postgres sh -c 'command -v bash >/dev/null 2>&1 && exec bash || exec sh'"}
+	if cmd.binary != "sh" || !slices.Equal(cmd.args, want) {
+		t.Fatalf("expected a local docker exec, got %s %v", cmd.binary, cmd.args)
+	}
+}
+
+func TestRunDockerScanLocalHostSkipsSSH(t *testing.T) {
+	bin := t.TempDir()
+	if err := os.Symlink("/bin/sh", filepath.Join(bin, "sh")); err != nil {
+		t.Fatal(err)
+	}
+	docker := "#!/bin/sh\nprintf 'docker\\tabc\\tpostgres\\tpostgres:16\\n'\n"
+	if err := os.WriteFile(filepath.Join(bin, "docker"), []byte(docker), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin)
+
+	msg := runDockerScan(context.Background(), Host{ID: "l1", Alias: "workstation", Hostname: "localhost", Transport: transportLocal}, false)
+	if msg.err != nil {
+		t.Fatal(msg.err)
+	}
+	if len(msg.containers) != 1 || msg.containers[0].Alias != "postgres" || msg.containers[0].ParentID != "l1" {
+		t.Fatalf("unexpected scan result %+v", msg.containers)
+	}
+}
+
+func TestLocalHostSkipsTrustCheck(t *testing.T) {
+	local := Host{ID: "l1", Alias: "workstation", Hostname: "localhost", Transport: transportLocal}
+	msg := checkHostTrustCmd(pendingSSHAction{kind: sshActionScan, host: local, trustHost: local})()
+	if check, ok := msg.(hostTrustCheckMsg); !ok || !check.known || check.err != nil {
+		t.Fatalf("expected the local host to count as trusted, got %+v", msg)
+	}
+}
+
+func TestRunLocalCommandStopsWhenCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		_, _, err := runLocalCommand(ctx, Host{Transport: transportLocal}, "sleep 5")
+		done <- err
+	}()
+	cancel()
+	select {
+	case err := <-done:
+		if err != context.Canceled {
+			t.Fatalf("expected context.Canceled, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("cancelling should stop the command before its timeout")
+	}
+}
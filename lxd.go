@@ -0,0 +1,88 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// --- LXD / Incus ---
+
+// The host scan also lists running LXD (lxc) and Incus instances. They nest
+// like Docker containers and are entered with `<tool> exec <name>`, which
+// works for both system containers and VMs running the agent.
+
+const (
+	hostKindLXD   = "lxd"
+	hostKindIncus = "incus"
+)
+
+// lxdScanLines prints "<tool>\t<json>" for each installed client, where the
+// JSON is the one-line output of `list --format json`.
+var lxdScanLines = []string{
+	`for tool in lxc incus; do`,
+	`  if command -v "$tool" >/dev/null 2>&1; then`,
+	`    found=1`,
+	`    printf "%s\t" "$tool"; "$tool" list --format json 2>/dev/null || echo`,
+	`  fi`,
+	`done`,
+}
+
+type lxdInstance struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+}
+
+func (h Host) isLXD() bool {
+	return h.IsContainer && (h.Kind == hostKindLXD || h.Kind == hostKindIncus)
+}
+
+// lxdTool is the client binary that manages the instance.
+func (h Host) lxdTool() string {
+	if h.Kind == hostKindIncus {
+		return "incus"
+	}
+	return "lxc"
+}
+
+// parseLXDList turns one client's JSON listing into nested hosts, skipping
+// stopped instances. Output that is not JSON (an uninitialised LXD, say)
+// yields nothing.
+func parseLXDList(tool, output, parentID string) []Host {
+	var instances []lxdInstance
+	if err := json.Unmarshal([]byte(output), &instances); err != nil {
+		return nil
+	}
+	kind := hostKindLXD
+	if tool == "incus" {
+		kind = hostKindIncus
+	}
+	var hosts []Host
+	for _, inst := range instances {
+		if inst.Name == "" || !strings.EqualFold(inst.Status, "running") {
+			continue
+		}
+		hosts = append(hosts, Host{
+			ID:          newHostID(),
+			Alias:       inst.Name,
+			Hostname:    inst.Name,
+			User:        "root",
+			IsContainer: true,
+			Kind:        kind,
+			ParentID:    parentID,
+		})
+	}
+	return hosts
+}
+
+func lxdExecCommand(h Host) string {
+	return fmt.Sprintf("%s exec %s -- sh -c 'command -v bash >/dev/null 2>&1 && exec bash || exec sh'", h.lxdTool(), shellQuote(h.Alias))
+}
+
+// containerLabel names the runtime in list descriptions.
+func (h Host) containerLabel() string {
+	if h.isLXD() || h.isJail() {
+		return h.Kind
+	}
+	return "container"
+}
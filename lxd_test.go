@@ -0,0 +1,40 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseGuestScanReadsLXDAndIncus(t *testing.T) {
+	output := strings.Join([]string{
+		`lxc	[{"name":"db","status":"Running","type":"container"},{"name":"old","status":"Stopped","type":"container"}]`,
+		`incus	[{"name":"ci-runner","status":"Running","type":"virtual-machine"}]`,
+		`lxc	`,
+		`incus	Error: not initialised`,
+	}, "\n")
+	hosts := parseGuestScan(output, "p1")
+	if len(hosts) != 2 {
+		t.Fatalf("expected the two running instances, got %+v", hosts)
+	}
+	if hosts[0].Alias != "db" || hosts[0].Kind != hostKindLXD || hosts[0].lxdTool() != "lxc" || hosts[0].ParentID != "p1" {
+		t.Fatalf("unexpected LXD host %+v", hosts[0])
+	}
+	if hosts[1].Alias != "ci-runner" || hosts[1].lxdTool() != "incus" || !hosts[1].isLXD() {
+		t.Fatalf("unexpected Incus host %+v", hosts[1])
+	}
+}
+
+func TestBuildConnectCommandLXDExecsThroughParent(t *testing.T) {
+	parent := Host{ID: "p1", Alias: "lxd-host", Hostname: "10.0.0.7", User: "ubuntu"}
+	inst := Host{ID: "c1", Alias: "db", Hostname: "db", IsContainer: true, Kind: hostKindIncus, ParentID: "p1"}
+
+	cmd, err := buildConnectCommand(inst, hostInventory{hosts: []Host{parent, inst}}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"-t", "-l", "ubuntu", "10.0.0.7", "incus exec db -- sh -c 'command -v bash >/dev/null 2>&1 && exec bash || exec sh'"}
+	if cmd.sshHost.ID != "p1" || !slices.Equal(cmd.args, want) {
+		t.Fatalf("got %v, want %v", cmd.args, want)
+	}
+}
@@ -1,12 +1,16 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
+	"runtime/debug"
+	"slices"
 	"strings"
 	"syscall"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -22,13 +26,29 @@ USAGE
 
 COMMANDS
   connect <alias>               connect directly to a host, no TUI
+  last                          reconnect to the most recently used host
   test <alias>                  test SSH connectivity; exits 0 on success
   list                          print all hosts as a table
   export                        print all hosts as SSH config stanzas
+  export --write [path]         update the assho block in ~/.ssh/config (or path)
+  export --grouped [--write]    share each group's user, key, and jump in one block
+  export --ansible [ini|yaml]   print hosts and groups as an Ansible inventory
+  metrics                       print Prometheus metrics
+  daemon [--once [check]]       run the scheduled health checks in hosts.json
+  daemon --listen <addr>        also serve Prometheus metrics at /metrics
+  network                       show the detected network and active profile
+  secrets migrate [--dry-run]   move stored passwords to ASSHO_SECRET_BACKEND
+  sync [inventory]              pull hosts from the inventories in hosts.json
+  update [--check]              install the latest release, verified by checksum
+  doctor                        check ssh, optional tools, secrets, and hosts.json
+  plugins [list]                list installed plugins and what they provide
+  plugins discover <name>       add the hosts a discovery plugin finds
+  plugins import <name> [args]  add the hosts an importer plugin reads from args
   completion <bash|zsh|fish>    print shell completion script
 
 OPTIONS
-  --version, -v                 print version and exit
+  --version, -v, version        print version and exit
+  --profile-startup             time each startup step and print the report
   --help, -h                    show this help
 
 SHELL COMPLETIONS
@@ -116,7 +136,7 @@ func cliList() {
 }
 
 func cliConnect(alias string) {
-	_, hosts, _, err := loadConfig()
+	groups, hosts, _, err := loadConfig()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "error loading config: %v\n", err)
 		os.Exit(1)
@@ -126,39 +146,98 @@ func cliConnect(alias string) {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
+	cliConnectHost(target.host, hostInventory{hosts: hosts, groups: groups})
+}
 
-	var sshArgs []string
-	var password string
-	if target.host.IsContainer {
-		if target.parent == nil {
-			fmt.Fprintf(os.Stderr, "container %q is missing its parent host reference\n", target.host.Alias)
-			os.Exit(1)
-		}
-		dockerCmd := fmt.Sprintf("docker exec -it %s sh -c 'command -v bash >/dev/null 2>&1 && exec bash || exec sh'", target.host.Alias)
-		sshArgs = buildSSHArgs(*target.parent, true, dockerCmd)
-		password = target.parent.Password
-	} else {
-		sshArgs = buildSSHArgs(target.host, false, "")
-		password = target.host.Password
+// cliConnectHost replaces assho with an ssh session to target.
+func cliConnectHost(target Host, inv hostInventory) {
+	cmd, err := buildConnectCommand(target, inv, false)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
 	}
-	binary, args, extraEnv, ok := buildSSHCommand(password, sshArgs)
-	if password != "" && !ok {
-		fmt.Fprintln(os.Stderr, "warning: password set but sshpass not found")
+	if err := verifyHostKeyPin(cmd.sshHost); err != nil {
+		recordAudit("connect", target.Alias, cmd.sshHost, err)
+		fmt.Fprintln(os.Stderr, "✘ "+err.Error())
+		warnWebhookErrors(fireWebhooks(connectionFailedEvent(target, err)))
+		os.Exit(1)
+	}
+	if cmd.missingSSHPass {
+		fmt.Fprintln(os.Stderr, "warning: password set but sshpass not found; "+installHint("sshpass"))
+	}
+	if target.inMaintenance(time.Now()) && !confirmMaintenanceConnect(target, os.Stdin, os.Stderr) {
+		os.Exit(1)
+	}
+	if notice := securityKeyNotice(cmd.sshHost); notice != "" {
+		fmt.Fprintln(os.Stderr, notice)
 	}
-	finalBinaryPath, lookErr := exec.LookPath(binary)
+	finalBinaryPath, lookErr := exec.LookPath(cmd.binary)
 	if lookErr != nil {
-		finalBinaryPath = binary
+		err := errMissingTool(cmd.binary)
+		recordAudit("connect", target.Alias, cmd.sshHost, err)
+		fmt.Fprintln(os.Stderr, "✘ "+err.Error()+"; run assho doctor to check the rest")
+		os.Exit(1)
 	}
-	env := append(os.Environ(), extraEnv...)
-	argv := append([]string{binary}, args...)
+	env := cmd.environ()
+	argv := append([]string{cmd.binary}, cmd.args...)
+	recordAudit("connect", target.Alias, cmd.sshHost, nil)
 	if err := syscall.Exec(finalBinaryPath, argv, env); err != nil {
+		recordAudit("connect", target.Alias, cmd.sshHost, err)
 		fmt.Fprintf(os.Stderr, "failed to exec SSH: %v\n", err)
 		os.Exit(1)
 	}
 }
 
+func cliExport(args []string) {
+	grouped := slices.Contains(args, "--grouped")
+	args = slices.DeleteFunc(args, func(arg string) bool { return arg == "--grouped" })
+	write := len(args) > 0 && args[0] == "--write"
+	ansible := len(args) > 0 && args[0] == "--ansible"
+	format := "ini"
+	if ansible && len(args) == 2 {
+		format = args[1]
+	}
+	if (!write && !ansible && len(args) > 0) || len(args) > 2 || (format != "ini" && format != "yaml") || (grouped && ansible) {
+		fmt.Fprintln(os.Stderr, "usage: assho export [--grouped] [--write [path]] | assho export --ansible [ini|yaml]")
+		os.Exit(1)
+	}
+	groups, hosts, _, err := loadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error loading config: %v\n", err)
+		os.Exit(1)
+	}
+	hosts = withGroupDefaults(hosts, groups)
+	switch {
+	case ansible && format == "yaml":
+		fprintAnsibleYAML(os.Stdout, groups, hosts)
+		return
+	case ansible:
+		fprintAnsibleINI(os.Stdout, groups, hosts)
+		return
+	}
+	var block strings.Builder
+	if grouped {
+		fprintGroupedSSHConfig(&block, groups, hosts)
+	} else {
+		fprintSSHConfig(&block, hosts)
+	}
+	if !write {
+		fmt.Print(block.String())
+		return
+	}
+	path := "~/.ssh/config"
+	if len(args) == 2 {
+		path = args[1]
+	}
+	if err := writeManagedSSHConfig(path, block.String()); err != nil {
+		fmt.Fprintf(os.Stderr, "error writing %s: %v\n", path, err)
+		os.Exit(1)
+	}
+	fmt.Printf("✔ Updated the assho block in %s\n", path)
+}
+
 func cliTest(alias string) {
-	_, hosts, _, err := loadConfig()
+	groups, hosts, _, err := loadConfig()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "error loading config: %v\n", err)
 		os.Exit(1)
@@ -168,35 +247,76 @@ func cliTest(alias string) {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
+	if label := maintenanceLabel(target.host, time.Now()); label != "" {
+		fmt.Println("- skipped · " + label)
+		os.Exit(0)
+	}
 	var testErr error
+	var sshfp sshfpResult
+	inv := hostInventory{hosts: hosts, groups: groups}
+	sshHost := resolveEndpoint(target.host, inv)
 	if target.host.IsContainer {
 		if target.parent == nil {
 			testErr = fmt.Errorf("container %q is missing its parent host reference", target.host.Alias)
 		} else {
-			testErr = runSSHTest(*target.parent, fmt.Sprintf("docker exec %s sh -c 'exit'", target.host.Alias))
+			sshHost = resolveEndpoint(*target.parent, inv)
+			testErr = verifyHostKeyPin(sshHost)
+			switch {
+			case testErr != nil:
+			case target.host.isGuest() && target.host.Hostname != "":
+				sshHost = guestEndpoint(sshHost, target.host)
+				testErr = runSSHTest(sshHost, "exit")
+			case target.host.isGuest():
+				testErr = runSSHTest(sshHost, "virsh -c "+libvirtURI+" domstate "+shellQuote(target.host.Alias)+" | grep -q running")
+			case target.host.isLXD():
+				testErr = runSSHTest(sshHost, target.host.lxdTool()+" exec "+shellQuote(target.host.Alias)+" -- true")
+			case target.host.isJail():
+				testErr = runSSHTest(sshHost, jailExecCommand(target.host, "true"))
+			default:
+				testErr = runSSHTest(sshHost, fmt.Sprintf("docker exec %s sh -c 'exit'", target.host.Alias))
+			}
 		}
-	} else {
-		testErr = runSSHTest(target.host, "exit")
+	} else if target.host.Transport == transportPSRemoting {
+		testErr = dialWinRM(context.Background(), sshHost)
+	} else if testErr = verifyHostKeyPin(sshHost); testErr == nil {
+		sshfp, _, testErr = runSSHTestSSHFP(context.Background(), sshHost, "exit")
 	}
+	recordAudit("test", target.host.Alias, sshHost, testErr)
 	status, success := formatTestStatus(testErr)
+	if sshfp != sshfpOff {
+		status += " · " + sshfp.label()
+	}
 	if success {
 		fmt.Println("✔ " + status)
 		os.Exit(0)
 	} else {
 		fmt.Fprintln(os.Stderr, "✘ "+status)
+		if path := sshPermissionProblem(testErr); path != "" {
+			fmt.Fprintln(os.Stderr, "  fix: "+permFixHint(path))
+		}
+		warnWebhookErrors(fireWebhooks(connectionFailedEvent(target.host, testErr)))
 		os.Exit(1)
 	}
 }
 
 func main() {
+	defer func() {
+		if r := recover(); r != nil {
+			reportCrash(crashReport{value: r, where: "startup", stack: debug.Stack()})
+			os.Exit(2)
+		}
+	}()
 	if len(os.Args) >= 2 {
 		switch os.Args[1] {
 		case "--help", "-h", "help":
 			fmt.Print(cliHelp)
 			return
-		case "--version", "-v":
+		case "--version", "-v", "version":
 			fmt.Println("assho " + version)
 			return
+		case "--profile-startup":
+			cliProfileStartup()
+			return
 		case "list":
 			cliList()
 			return
@@ -205,22 +325,49 @@ func main() {
 				fmt.Fprintln(os.Stderr, "usage: assho connect <alias>")
 				os.Exit(1)
 			}
+			useDetectedSSHAgent()
 			cliConnect(os.Args[2])
 			return
+		case "last":
+			useDetectedSSHAgent()
+			cliLast()
+			return
 		case "test":
 			if len(os.Args) < 3 {
 				fmt.Fprintln(os.Stderr, "usage: assho test <alias>")
 				os.Exit(1)
 			}
+			useDetectedSSHAgent()
 			cliTest(os.Args[2])
 			return
 		case "export":
-			_, hosts, _, err := loadConfig()
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "error loading config: %v\n", err)
-				os.Exit(1)
-			}
-			fprintSSHConfig(os.Stdout, hosts)
+			cliExport(os.Args[2:])
+			return
+		case "metrics":
+			cliMetrics(os.Args[2:])
+			return
+		case "daemon":
+			useDetectedSSHAgent()
+			cliDaemon(os.Args[2:])
+			return
+		case "network":
+			cliNetwork()
+			return
+		case "secrets":
+			cliSecrets(os.Args[2:])
+			return
+		case "plugins":
+			cliPlugins(os.Args[2:])
+			return
+		case "sync":
+			cliSync(os.Args[2:])
+			return
+		case "update":
+			cliUpdate(os.Args[2:])
+			return
+		case "doctor":
+			useDetectedSSHAgent()
+			cliDoctor()
 			return
 		case "_aliases":
 			_, hosts, _, err := loadConfig()
@@ -249,58 +396,78 @@ func main() {
 		}
 	}
 
-	p := tea.NewProgram(initialModel(), tea.WithAltScreen())
-	m, err := p.Run()
-	if err != nil {
-		fmt.Printf("Alas, there's been an error: %v", err)
-		os.Exit(1)
-	}
+	useDetectedSSHAgent()
+	var last *sessionResult
+	for {
+		start := initialModel()
+		if last != nil {
+			start = start.afterSession(*last)
+		}
+		guard := newCrashGuard(start)
+		p := tea.NewProgram(guard, tea.WithAltScreen())
+		m, err := p.Run()
+		if guard.report.crashed() {
+			reportCrash(*guard.report)
+			os.Exit(2)
+		}
+		if g, ok := m.(crashGuard); ok {
+			m = g.inner
+		}
+		if err != nil {
+			fmt.Printf("Alas, there's been an error: %v", err)
+			os.Exit(1)
+		}
 
-	// Exec SSH after TUI cleanup
-	if finalModel, ok := m.(model); ok && finalModel.sshToRun != nil {
+		// Run SSH after TUI cleanup
+		finalModel, ok := m.(model)
+		if !ok || finalModel.sshToRun == nil {
+			return
+		}
 		h := finalModel.sshToRun
 
 		connectStyle := lipgloss.NewStyle().Foreground(colorSecondary).Bold(true)
 		hostStyle := lipgloss.NewStyle().Foreground(colorPrimary).Bold(true)
 		fmt.Printf("\n %s %s\n\n", connectStyle.Render("→ Connecting to"), hostStyle.Render(h.Alias))
 
-		var sshArgs []string
-		var password string
-		if h.IsContainer {
-			if h.ParentID == "" {
-				fmt.Println("Error: container missing parent host reference.")
-				return
-			}
-			parentIdx := findHostIndexByID(finalModel.rawHosts, h.ParentID)
-			if parentIdx == -1 {
-				fmt.Println("Error: parent host not found for container.")
-				return
-			}
-			parent := finalModel.rawHosts[parentIdx]
-			dockerCmd := fmt.Sprintf("docker exec -it %s sh -c 'command -v bash >/dev/null 2>&1 && exec bash || exec sh'", h.Alias)
-			sshArgs = buildTrustedSSHArgs(parent, true, dockerCmd)
-			password = parent.Password
-		} else {
-			sshArgs = buildTrustedSSHArgs(*h, false, "")
-			password = h.Password
+		cmd, err := buildConnectCommand(*h, hostInventory{hosts: finalModel.rawHosts, groups: finalModel.rawGroups}, true)
+		if err != nil {
+			fmt.Printf("Error: %v.\n", err)
+			return
 		}
-
-		binary, args, extraEnv, ok := buildSSHCommand(password, sshArgs)
-		if password != "" && !ok {
-			fmt.Println("Warning: Password provided but 'sshpass' not found.")
+		if cmd.missingSSHPass {
+			fmt.Println("Warning: Password provided but 'sshpass' not found; " + installHint("sshpass"))
+		}
+		if notice := securityKeyNotice(cmd.sshHost); notice != "" {
+			fmt.Println(notice)
 		}
 
-		finalBinaryPath, lookErr := exec.LookPath(binary)
+		finalBinaryPath, lookErr := exec.LookPath(cmd.binary)
 		if lookErr != nil {
-			finalBinaryPath = binary
+			finalBinaryPath = cmd.binary
 		}
 
-		env := append(os.Environ(), extraEnv...)
-		argv := append([]string{binary}, args...)
+		env := cmd.environ()
+		argv := append([]string{cmd.binary}, cmd.args...)
 
-		if err := syscall.Exec(finalBinaryPath, argv, env); err != nil {
-			fmt.Fprintf(os.Stderr, "Error: failed to exec SSH: %v\n", err)
-			os.Exit(1)
+		recordAudit("connect", h.Alias, cmd.sshHost, nil)
+		if !returnToList() {
+			if err := syscall.Exec(finalBinaryPath, argv, env); err != nil {
+				recordAudit("connect", h.Alias, cmd.sshHost, err)
+				fmt.Fprintf(os.Stderr, "Error: failed to exec SSH: %v\n", err)
+				os.Exit(1)
+			}
+		}
+
+		result := sessionResult{hostID: h.ID, parentID: h.ParentID, alias: h.Alias}
+		result.err = runSession(finalBinaryPath, argv, env)
+		result.ended = time.Now()
+		if result.failed() {
+			if _, exited := result.err.(*exec.ExitError); !exited {
+				recordAudit("connect", h.Alias, cmd.sshHost, result.err)
+				fmt.Fprintf(os.Stderr, "Error: failed to run SSH: %v\n", result.err)
+			}
+			waitForEnter()
 		}
+		last = &result
 	}
 }
@@ -0,0 +1,241 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// --- Maintenance Mode ---
+
+// A host can be put under maintenance for planned downtime, with a note
+// saying why and an optional end. Until then the list marks it with a
+// wrench, connecting asks for a second enter (or a y at the CLI prompt), and
+// health checks — group tests, assho test, and the assho_host_up metric —
+// leave it out so the hosts being worked on do not show up as failures.
+// Maintenance ends by itself once the end has passed, or with w again.
+
+const maintenanceLayout = "2006-01-02 15:04"
+
+// Maintenance is a planned-downtime window on a host.
+type Maintenance struct {
+	Note string `json:"note,omitempty"`
+	// Until is a YYYY-MM-DD date (through the end of that day), a
+	// "YYYY-MM-DD HH:MM" local time, or empty for no end.
+	Until string `json:"until,omitempty"`
+}
+
+// parseMaintenanceUntil accepts a date, a date and time, or a relative
+// "Nh" or "Nd" and returns the value stored on the host.
+func parseMaintenanceUntil(value string, now time.Time) (string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", nil
+	}
+	invalid := fmt.Errorf("until must be a date (YYYY-MM-DD), a time (YYYY-MM-DD HH:MM), or a duration like 4h or 2d")
+	lower := strings.ToLower(value)
+	if n, ok := strings.CutSuffix(lower, "h"); ok {
+		hours, err := strconv.Atoi(n)
+		if err != nil || hours <= 0 {
+			return "", invalid
+		}
+		return now.Add(time.Duration(hours) * time.Hour).Format(maintenanceLayout), nil
+	}
+	if n, ok := strings.CutSuffix(lower, "d"); ok {
+		days, err := strconv.Atoi(n)
+		if err != nil || days <= 0 {
+			return "", invalid
+		}
+		return now.AddDate(0, 0, days).Format(maintenanceLayout), nil
+	}
+	if t, err := time.ParseInLocation(maintenanceLayout, value, time.Local); err == nil {
+		return t.Format(maintenanceLayout), nil
+	}
+	if t, err := time.ParseInLocation(expiryLayout, value, time.Local); err == nil {
+		return t.Format(expiryLayout), nil
+	}
+	return "", invalid
+}
+
+// end returns when the window closes. Unparsable values are treated as no
+// end, so a hand-edited typo keeps the host under maintenance.
+func (mt Maintenance) end() (time.Time, bool) {
+	if t, err := time.ParseInLocation(maintenanceLayout, mt.Until, time.Local); err == nil {
+		return t, true
+	}
+	if t, err := time.ParseInLocation(expiryLayout, mt.Until, time.Local); err == nil {
+		return t.AddDate(0, 0, 1), true
+	}
+	return time.Time{}, false
+}
+
+// inMaintenance reports whether h is under maintenance at now.
+func (h Host) inMaintenance(now time.Time) bool {
+	if h.Maintenance == nil {
+		return false
+	}
+	end, ok := h.Maintenance.end()
+	return !ok || now.Before(end)
+}
+
+// maintenanceLabel is the short note shown in the list and detail pane, or
+// "" when h is not under maintenance.
+func maintenanceLabel(h Host, now time.Time) string {
+	if !h.inMaintenance(now) {
+		return ""
+	}
+	label := "maintenance"
+	if _, ok := h.Maintenance.end(); ok {
+		label += " until " + h.Maintenance.Until
+	}
+	return label
+}
+
+// maintenanceWarning describes why connecting to h needs confirming.
+func maintenanceWarning(h Host) string {
+	warning := h.Alias + " is under maintenance"
+	if note := strings.TrimSpace(h.Maintenance.Note); note != "" {
+		warning += ": " + note
+	}
+	return warning
+}
+
+// confirmMaintenanceConnect asks on out whether to connect to h anyway and
+// reads the answer from in. Anything but y or yes declines.
+func confirmMaintenanceConnect(h Host, in io.Reader, out io.Writer) bool {
+	fmt.Fprintf(out, "%s. Connect anyway? [y/N] ", maintenanceWarning(h))
+	answer, _ := bufio.NewReader(in).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+type maintenanceState struct {
+	hostID    string
+	alias     string
+	note      textinput.Model
+	until     textinput.Model
+	focus     int // 0 note, 1 until
+	errorText string
+}
+
+// toggleMaintenance opens the maintenance prompt for h, or ends the
+// maintenance h is already under.
+func (m model) toggleMaintenance(h Host) (tea.Model, tea.Cmd) {
+	m.clearListDeleteConfirm()
+	if h.inMaintenance(time.Now()) {
+		if err := m.setMaintenance(h.ID, nil); err != nil {
+			m.status.message = err.Error()
+			m.status.isError = true
+		} else {
+			m.status.message = "Ended maintenance on " + h.Alias
+			m.status.isError = false
+		}
+		m.status.version++
+		return m, statusClearCmd(m.status.version)
+	}
+	newInput := func(prompt, placeholder string) textinput.Model {
+		input := textinput.New()
+		input.Prompt = prompt
+		input.Placeholder = placeholder
+		input.PromptStyle = lipgloss.NewStyle().Foreground(colorHighlight).Bold(true)
+		input.TextStyle = lipgloss.NewStyle().Foreground(colorText)
+		input.Cursor.Style = lipgloss.NewStyle().Foreground(colorSecondary)
+		return input
+	}
+	m.maintenance = maintenanceState{
+		hostID: h.ID,
+		alias:  h.Alias,
+		note:   newInput("  Note   ", "kernel upgrade, disk swap…"),
+		until:  newInput("  Until  ", "blank, 4h, 2d, 2026-01-31, or 2026-01-31 18:00"),
+	}
+	m.state = stateMaintenance
+	return m, m.maintenance.note.Focus()
+}
+
+// setMaintenance puts host id under mt, or ends its maintenance when mt is
+// nil, and saves.
+func (m *model) setMaintenance(id string, mt *Maintenance) error {
+	idx := findHostIndexByID(m.rawHosts, id)
+	if idx == -1 {
+		return fmt.Errorf("host no longer exists")
+	}
+	snapshot := m.snapshot()
+	m.rawHosts[idx].Maintenance = mt
+	m.refreshList()
+	if err := m.save(); err != nil {
+		m.restoreSnapshot(snapshot)
+		return fmt.Errorf("failed to save maintenance: %v", err)
+	}
+	m.reselectItem(id, false)
+	return nil
+}
+
+func (m *model) focusMaintenanceInput(focus int) tea.Cmd {
+	m.maintenance.focus = focus
+	if focus == 0 {
+		m.maintenance.until.Blur()
+		return m.maintenance.note.Focus()
+	}
+	m.maintenance.note.Blur()
+	return m.maintenance.until.Focus()
+}
+
+func (m model) updateMaintenance(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		m.quitting = true
+		return m, tea.Quit
+	case "esc":
+		m.state = stateList
+		return m, nil
+	case "tab", "shift+tab", "up", "down":
+		return m, m.focusMaintenanceInput(1 - m.maintenance.focus)
+	case "enter":
+		if m.maintenance.focus == 0 {
+			return m, m.focusMaintenanceInput(1)
+		}
+		until, err := parseMaintenanceUntil(m.maintenance.until.Value(), time.Now())
+		if err != nil {
+			m.maintenance.errorText = err.Error()
+			return m, nil
+		}
+		mt := &Maintenance{Note: strings.TrimSpace(m.maintenance.note.Value()), Until: until}
+		if err := m.setMaintenance(m.maintenance.hostID, mt); err != nil {
+			m.maintenance.errorText = err.Error()
+			return m, nil
+		}
+		m.state = stateList
+		m.status.message = m.maintenance.alias + " is under maintenance"
+		if until != "" {
+			m.status.message += " until " + until
+		}
+		m.status.isError = false
+		m.status.version++
+		return m, statusClearCmd(m.status.version)
+	}
+	var cmd tea.Cmd
+	if m.maintenance.focus == 0 {
+		m.maintenance.note, cmd = m.maintenance.note.Update(msg)
+	} else {
+		m.maintenance.until, cmd = m.maintenance.until.Update(msg)
+	}
+	return m, cmd
+}
+
+func (m model) renderMaintenanceView() string {
+	content := formHintStyle.Render(m.maintenance.alias+" · connecting asks for confirmation and health checks skip it") +
+		"\n\n" + m.maintenance.note.View() + "\n" + m.maintenance.until.View()
+	if m.maintenance.errorText != "" {
+		content += "\n\n" + testFailStyle.Render("✘ "+m.maintenance.errorText)
+	}
+	box := formBoxStyle.Render(formTitleStyle.Render("Maintenance") + "\n\n" + content)
+	help := "\n" + helpBarStyle.Render(helpEntry("enter", "start")+" | "+helpEntry("tab", "next field")+" | "+helpEntry("esc", "cancel"))
+	return appStyle.Render(box + help)
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestParseMaintenanceUntil(t *testing.T) {
+	now := time.Date(2026, 10, 16, 9, 30, 0, 0, time.Local)
+	for value, want := range map[string]string{
+		"":                 "",
+		"4h":               "2026-10-16 13:30",
+		"2D":               "2026-10-18 09:30",
+		"2026-10-20":       "2026-10-20",
+		"2026-10-20 18:00": "2026-10-20 18:00",
+	} {
+		got, err := parseMaintenanceUntil(value, now)
+		if err != nil || got != want {
+			t.Errorf("parseMaintenanceUntil(%q) = %q, %v; want %q", value, got, err, want)
+		}
+	}
+	for _, value := range []string{"0h", "-1d", "tomorrow", "2026-13-01"} {
+		if _, err := parseMaintenanceUntil(value, now); err == nil {
+			t.Errorf("expected %q to be rejected", value)
+		}
+	}
+}
+
+func TestInMaintenanceEndsAfterUntil(t *testing.T) {
+	now := time.Date(2026, 10, 16, 9, 30, 0, 0, time.Local)
+	h := Host{Alias: "web", Maintenance: &Maintenance{Note: "disk swap"}}
+	if !h.inMaintenance(now) || maintenanceLabel(h, now) != "maintenance" {
+		t.Fatal("maintenance without an end should stay on")
+	}
+	h.Maintenance.Until = "2026-10-16"
+	if !h.inMaintenance(now.Add(14*time.Hour)) || h.inMaintenance(now.Add(15*time.Hour)) {
+		t.Fatal("a date should last through the end of that day")
+	}
+	h.Maintenance.Until = "2026-10-16 12:00"
+	if got := maintenanceLabel(h, now); got != "maintenance until 2026-10-16 12:00" {
+		t.Fatalf("unexpected label %q", got)
+	}
+	if h.inMaintenance(now.Add(3*time.Hour)) || maintenanceLabel(h, now.Add(3*time.Hour)) != "" {
+		t.Fatal("maintenance should end at its until time")
+	}
+	if (Host{}).inMaintenance(now) {
+		t.Fatal("hosts without maintenance are not under it")
+	}
+}
+
+func TestMaintenancePromptAndConnectConfirmation(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("ASSHO_STORE_PASSWORD", "0")
+	hosts := []Host{{ID: "h1", Alias: "web", Hostname: "10.0.0.1"}}
+	m := model{rawHosts: hosts, list: newTestListModel(nil, hosts), historyList: newTestHistoryListModel()}
+	m.list.Select(0)
+
+	updated, _ := m.updateList(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("w")})
+	m = updated.(model)
+	if m.state != stateMaintenance {
+		t.Fatalf("expected the maintenance prompt, got state %v", m.state)
+	}
+	m.maintenance.note.SetValue("kernel upgrade")
+	m.maintenance.until.SetValue("soon")
+	updated, _ = m.updateMaintenance(tea.KeyMsg{Type: tea.KeyTab})
+	updated, _ = updated.(model).updateMaintenance(tea.KeyMsg{Type: tea.KeyEnter})
+	if m = updated.(model); m.state != stateMaintenance || m.maintenance.errorText == "" {
+		t.Fatal("expected an invalid until to keep the prompt open with an error")
+	}
+	m.maintenance.until.SetValue("")
+	updated, _ = m.updateMaintenance(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(model)
+	if mt := m.rawHosts[0].Maintenance; m.state != stateList || mt == nil || mt.Note != "kernel upgrade" {
+		t.Fatalf("expected maintenance saved, got state %v %+v", m.state, mt)
+	}
+
+	updated, _ = m.connectToHost(m.rawHosts[0])
+	m = updated.(model)
+	if m.maintenanceConfirm != "h1" || !m.status.isError || !strings.Contains(m.status.message, "kernel upgrade") {
+		t.Fatalf("expected the first connect to ask for confirmation, got %q", m.status.message)
+	}
+	updated, cmd := m.connectToHost(m.rawHosts[0])
+	if m = updated.(model); m.maintenanceConfirm != "" || cmd == nil {
+		t.Fatal("expected the second connect to go ahead")
+	}
+
+	m.list.Select(0)
+	updated, _ = m.updateList(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("w")})
+	if m = updated.(model); m.rawHosts[0].Maintenance != nil || m.state != stateList {
+		t.Fatal("expected w to end maintenance")
+	}
+}
+
+func TestConfirmMaintenanceConnect(t *testing.T) {
+	h := Host{Alias: "web", Maintenance: &Maintenance{Note: "disk swap"}}
+	var out bytes.Buffer
+	if !confirmMaintenanceConnect(h, strings.NewReader("y\n"), &out) {
+		t.Fatal("expected y to confirm")
+	}
+	if !strings.Contains(out.String(), "web is under maintenance: disk swap") {
+		t.Fatalf("unexpected prompt %q", out.String())
+	}
+	for _, answer := range []string{"\n", "n\n", ""} {
+		if confirmMaintenanceConnect(h, strings.NewReader(answer), &out) {
+			t.Errorf("expected %q to decline", answer)
+		}
+	}
+}
+
+func TestHealthChecksSkipMaintenance(t *testing.T) {
+	groups := []Group{{ID: "g1", Name: "prod"}}
+	hosts := []Host{
+		{ID: "a", Alias: "web", Hostname: "10.0.0.1", GroupID: "g1", Maintenance: &Maintenance{}, Stats: &HostStats{Tests: 1, Failures: 1, LastTestAt: 1700000000}},
+		{ID: "b", Alias: "db", Hostname: "10.0.0.2", GroupID: "g1", Stats: &HostStats{Tests: 1, LastTestAt: 1700000000, LastTestOK: true}},
+	}
+	m := model{rawGroups: groups, rawHosts: hosts}
+	next, _ := m.startGroupRun(groupItem{Group: groups[0]}, groupRunTest)
+	m = next.(model)
+	if r := m.groupRun.results[0]; !r.done || !r.skipped || m.groupRun.results[1].done {
+		t.Fatalf("expected only the maintenance host skipped, got %+v", m.groupRun.results)
+	}
+	if done, ok, failed := m.groupRun.summary(); done != 1 || ok != 0 || failed != 0 {
+		t.Fatalf("a skipped host should count as neither ok nor failed, got %d/%d/%d", done, ok, failed)
+	}
+
+	var buf bytes.Buffer
+	fprintMetrics(&buf, hosts)
+	out := buf.String()
+	if strings.Contains(out, `assho_host_up{alias="web"`) || !strings.Contains(out, `assho_host_up{alias="db"`) {
+		t.Fatalf("expected no up sample for the maintenance host\n%s", out)
+	}
+	if !strings.Contains(out, `assho_host_maintenance{alias="web",hostname="10.0.0.1"} 1`) ||
+		!strings.Contains(out, `assho_host_maintenance{alias="db",hostname="10.0.0.2"} 0`) {
+		t.Fatalf("expected maintenance gauges\n%s", out)
+	}
+}
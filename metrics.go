@@ -0,0 +1,132 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// --- Prometheus Metrics ---
+
+// fprintMetrics writes host statistics in the Prometheus text exposition
+// format. Reachability reflects the most recent connection test; hosts that
+// were never tested or are under maintenance have no assho_host_up sample,
+// so planned downtime does not fire alerts.
+func fprintMetrics(w io.Writer, hosts []Host) {
+	var hostCount int
+	for _, h := range hosts {
+		if !h.IsContainer {
+			hostCount++
+		}
+	}
+	fmt.Fprintln(w, "# HELP assho_hosts Number of configured SSH hosts.")
+	fmt.Fprintln(w, "# TYPE assho_hosts gauge")
+	fmt.Fprintf(w, "assho_hosts %d\n", hostCount)
+
+	type series struct {
+		name, help, kind string
+		value            func(HostStats) (float64, bool)
+	}
+	now := time.Now()
+	all := []series{
+		{"assho_host_up", "Whether the last connection test succeeded (1) or failed (0).", "gauge", func(s HostStats) (float64, bool) {
+			if s.LastTestAt == 0 {
+				return 0, false
+			}
+			if s.LastTestOK {
+				return 1, true
+			}
+			return 0, true
+		}},
+		{"assho_host_connections_total", "Interactive connections opened through assho.", "counter", func(s HostStats) (float64, bool) {
+			return float64(s.Connections), true
+		}},
+		{"assho_host_tests_total", "Connection tests run.", "counter", func(s HostStats) (float64, bool) {
+			return float64(s.Tests), true
+		}},
+		{"assho_host_test_failures_total", "Connection tests that failed.", "counter", func(s HostStats) (float64, bool) {
+			return float64(s.Failures), true
+		}},
+		{"assho_host_last_latency_seconds", "Duration of the last successful connection test.", "gauge", func(s HostStats) (float64, bool) {
+			return float64(s.LastLatencyMs) / 1000, s.LastLatencyMs > 0
+		}},
+		{"assho_host_last_test_timestamp_seconds", "Unix time of the last connection test.", "gauge", func(s HostStats) (float64, bool) {
+			return float64(s.LastTestAt), s.LastTestAt > 0
+		}},
+	}
+	for _, metric := range all {
+		fmt.Fprintf(w, "# HELP %s %s\n", metric.name, metric.help)
+		fmt.Fprintf(w, "# TYPE %s %s\n", metric.name, metric.kind)
+		for _, h := range hosts {
+			if h.IsContainer || (metric.name == "assho_host_up" && h.inMaintenance(now)) {
+				continue
+			}
+			var stats HostStats
+			if h.Stats != nil {
+				stats = *h.Stats
+			}
+			value, ok := metric.value(stats)
+			if !ok {
+				continue
+			}
+			fmt.Fprintf(w, "%s{alias=\"%s\",hostname=\"%s\"} %g\n", metric.name, escapeLabelValue(h.Alias), escapeLabelValue(h.Hostname), value)
+		}
+	}
+
+	fmt.Fprintln(w, "# HELP assho_host_maintenance Whether the host is under maintenance (1) or not (0).")
+	fmt.Fprintln(w, "# TYPE assho_host_maintenance gauge")
+	for _, h := range hosts {
+		if h.IsContainer {
+			continue
+		}
+		value := 0
+		if h.inMaintenance(now) {
+			value = 1
+		}
+		fmt.Fprintf(w, "assho_host_maintenance{alias=\"%s\",hostname=\"%s\"} %d\n", escapeLabelValue(h.Alias), escapeLabelValue(h.Hostname), value)
+	}
+}
+
+func escapeLabelValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+// loadMetricsHosts reads hosts without keychain hydration; metrics never need
+// passwords and a scrape must not shell out to the secret backend.
+func loadMetricsHosts() ([]Host, error) {
+	cfg, err := loadConfigFile()
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return cfg.Hosts, err
+}
+
+// metricsHandler serves /metrics on the daemon's listener. It reloads the
+// config on every scrape so the numbers reflect tests and connections made
+// from other assho processes, and reports reachability and latency from the
+// daemon's own health checks where they are newer.
+func (d *healthDaemon) metricsHandler(w http.ResponseWriter, _ *http.Request) {
+	hosts, err := loadMetricsHosts()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	fprintMetrics(w, d.withResults(hosts))
+}
+
+func cliMetrics(args []string) {
+	if len(args) != 0 {
+		fmt.Fprintln(os.Stderr, "usage: assho metrics (serve them with assho daemon --listen <addr>)")
+		os.Exit(1)
+	}
+	hosts, err := loadMetricsHosts()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error loading config: %v\n", err)
+		os.Exit(1)
+	}
+	fprintMetrics(os.Stdout, hosts)
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestFprintMetricsReportsHostStats(t *testing.T) {
+	hosts := []Host{
+		{ID: "a", Alias: "web", Hostname: "10.0.0.1", Stats: &HostStats{Connections: 3, Tests: 2, LastLatencyMs: 250, LastTestAt: 1700000000, LastTestOK: true}},
+		{ID: "b", Alias: `db "primary"`, Hostname: "10.0.0.2", Stats: &HostStats{Tests: 1, Failures: 1, LastTestAt: 1700000000}},
+		{ID: "c", Alias: "never-tested", Hostname: "10.0.0.3"},
+		{ID: "d", Alias: "app", IsContainer: true, ParentID: "a"},
+	}
+	var buf bytes.Buffer
+	fprintMetrics(&buf, hosts)
+	out := buf.String()
+
+	for _, want := range []string{
+		"assho_hosts 3\n",
+		`assho_host_up{alias="web",hostname="10.0.0.1"} 1`,
+		`assho_host_up{alias="db \"primary\"",hostname="10.0.0.2"} 0`,
+		`assho_host_connections_total{alias="web",hostname="10.0.0.1"} 3`,
+		`assho_host_test_failures_total{alias="db \"primary\"",hostname="10.0.0.2"} 1`,
+		`assho_host_last_latency_seconds{alias="web",hostname="10.0.0.1"} 0.25`,
+		"# TYPE assho_host_tests_total counter",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("metrics output missing %q\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, `assho_host_up{alias="never-tested"`) {
+		t.Errorf("untested host should have no up sample\n%s", out)
+	}
+	if strings.Contains(out, `alias="app"`) {
+		t.Errorf("containers should be omitted\n%s", out)
+	}
+}
+
+func TestDaemonServesMetrics(t *testing.T) {
+	writeTempConfig(t, []Host{{ID: "a", Alias: "web", Hostname: "10.0.0.1", Stats: &HostStats{Connections: 7}}})
+
+	rec := httptest.NewRecorder()
+	(&healthDaemon{}).mux().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/plain") {
+		t.Fatalf("unexpected content type %q", rec.Header().Get("Content-Type"))
+	}
+	if !strings.Contains(rec.Body.String(), `assho_host_connections_total{alias="web",hostname="10.0.0.1"} 7`) {
+		t.Fatalf("handler output missing connection count:\n%s", rec.Body.String())
+	}
+}
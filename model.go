@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -37,27 +38,74 @@ const (
 	stateHistory
 	stateKeyInstall
 	stateRotation
+	stateDetail
+	stateStats
+	stateCommandPreview
+	stateTransfer
+	stateBookmarks
+	stateGroupRun
+	stateImportMerge
+	stateHostRename
+	stateBulkClone
+	stateFirstContact
+	stateDiagnostics
+	stateRepair
+	stateSecretAudit
+	stateBatchRename
+	stateTrash
+	stateServices
+	statePortForward
+	stateCompose
+	stateTunnels
+	stateMaintenance
+	stateGroupPicker
+	stateGroupJump
+	stateHistoryImport
+	stateHostGenerator
+	stateTable
+	stateSessionNote
 )
 
 // Form field indices (must match newFormInputs order).
 const (
-	fieldAlias        = 0
-	fieldHostname     = 1
-	fieldUser         = 2
-	fieldPort         = 3
-	fieldKeyFile      = 4
-	fieldPassword     = 5
-	fieldForwardAgent = 6
-	fieldProxyJump    = 7
-	fieldLocalForward = 8
-	fieldGroup        = 9
-	fieldNotes        = 10
-	fieldCount        = 11
+	fieldAlias         = 0
+	fieldHostname      = 1
+	fieldUser          = 2
+	fieldPort          = 3
+	fieldKeyFile       = 4
+	fieldPassword      = 5
+	fieldForwardAgent  = 6
+	fieldProxyJump     = 7
+	fieldLocalForward  = 8
+	fieldRemoteCommand = 9
+	fieldTmuxSession   = 10
+	fieldGroup         = 11
+	fieldNotes         = 12
+	fieldWebURLs       = 13
+	fieldExpires       = 14
+	fieldOwner         = 15
+	fieldTeam          = 16
+	fieldContact       = 17
+	fieldInternalHost  = 18
+	fieldInternalNets  = 19
+	fieldUseSSHConfig  = 20
+	fieldTransport     = 21
+	fieldTimeout       = 22
+	fieldProxyCommand  = 23
+	fieldTerm          = 24
+	fieldNoLocale      = 25
+	fieldTerminal      = 26
+	fieldHotkey        = 27
+	fieldIdentityAgent = 28
+	fieldRole          = 29
+	fieldCount         = 30
 )
 
 // formControl describes the keyboard focus order independently from the
 // underlying data fields. Controls such as the key picker and delete action
-// participate in navigation without pretending to be text inputs.
+// participate in navigation without pretending to be text inputs. The quick
+// add fields come first; controlLocalForward through controlNotes live on
+// the Advanced tab.
 type formControl int
 
 const (
@@ -70,8 +118,27 @@ const (
 	controlPassword
 	controlForwardAgent
 	controlProxyJump
-	controlLocalForward
 	controlGroup
+	controlLocalForward
+	controlProxyCommand
+	controlInternalHost
+	controlInternalNets
+	controlRemoteCommand
+	controlTmuxSession
+	controlWebURLs
+	controlUseSSHConfig
+	controlTransport
+	controlTimeout
+	controlTerm
+	controlNoLocale
+	controlTerminal
+	controlHotkey
+	controlIdentityAgent
+	controlExpires
+	controlRole
+	controlOwner
+	controlTeam
+	controlContact
 	controlNotes
 	controlDelete
 )
@@ -88,7 +155,6 @@ type model struct {
 	err         error
 	quitting    bool
 	sshToRun    *Host // If set, will exec ssh on quit
-	scanning    bool  // true while Docker scan in progress
 	width       int   // terminal width
 	height      int   // terminal height
 	listDelete  listDeleteState
@@ -102,6 +168,76 @@ type model struct {
 	keyInstall  keyInstallState
 	rotation    rotationState
 	hostTrust   hostTrustState
+	// hostKeyAlert is set while a pinned key mismatch is on screen.
+	hostKeyAlert *hostKeyPinError
+	// detailHostID is the host shown in the detail pane.
+	detailHostID string
+	statsSort    statsSortKey
+	preview      commandPreviewState
+	showArchived bool
+	runningOnly  bool   // hide stopped containers
+	networkName  string // active network profile, empty when none matches
+	newRelease   string // newer release tag, see selfupdate.go
+	transfer     transferState
+	bookmarks    bookmarkPickerState
+	groupRun     groupRunState
+	importMerge  importMergeState
+	hostRename   hostRenameState
+	bulkClone    bulkCloneState
+	firstContact firstContactState
+	quickStats   quickStatsState
+	diagnostics  diagnosticsState
+	repair       repairState
+	secretAudit  secretAuditState
+	batchRename  batchRenameState
+	trash        trashState
+	services     windowsServicesState
+	portForward  portForwardState
+	compose      composeState
+	tunnels      tunnelsState
+	tasks        taskManager
+	dnsLookups   map[string]dnsLookup // by host ID; shared with the list delegate
+	dnsSeq       int
+	marked       map[string]bool // host IDs marked with m; shared with the list delegate
+	round        sessionRoundState
+	maintenance  maintenanceState
+	// maintenanceConfirm is the host whose maintenance warning is showing;
+	// connecting to it again goes ahead.
+	maintenanceConfirm string
+	// detailSSHConfig is ~/.ssh/config as read when the detail pane opened,
+	// for resolving jump hosts.
+	detailSSHConfig []sshConfigBlock
+	// permFix is the key or config file ctrl+f makes private, see keyperms.go.
+	permFix permFixState
+	// groupPicker is the form's group search, see grouppicker.go.
+	groupPicker groupPickerState
+	// gPrefix and groupJump are gg and Ctrl+G on the dashboard, see listnav.go.
+	gPrefix   gPrefixState
+	groupJump groupJumpState
+	// healthFailing holds the aliases the health daemon last found failing,
+	// see grouphealth.go.
+	healthFailing map[string]bool
+	// expandRefresh holds the IDs of hosts rescanned since they were
+	// expanded, see expandrefresh.go.
+	expandRefresh map[string]bool
+	// rowBusy is what the busy rows showed when the list was last drawn,
+	// see rowprogress.go.
+	rowBusy map[string]string
+	// noSSH is set when ssh was not on PATH at startup, see nossh.go.
+	noSSH error
+	// historyImport proposes hosts from shell history, see historyimport.go.
+	historyImport historyImportState
+	// hostGen expands a hostname pattern into hosts, see hostgen.go.
+	hostGen hostGeneratorState
+	// roles maps hosts to role icons and colors, see role.go.
+	roles roleSet
+	// compact draws one line per host, see compactlist.go.
+	compact bool
+	// table is the sortable flat host table, see tableview.go.
+	table tableViewState
+	// sessionNote asks for a journal note after a session, see
+	// sessionnote.go.
+	sessionNote sessionNoteState
 }
 
 type formState struct {
@@ -113,16 +249,46 @@ type formState struct {
 	deleteArmed  bool   // true when delete confirmation is armed
 	testStatus   string // Status message for connection test
 	testResult   bool   // true = success, false = failure
-	testing      bool   // true while connection test in progress
-	groupOptions []string
-	groupIndex   int
-	groupCustom  bool
+	// keepPasswordRef is set when the stored password could not be read for
+	// editing, so saving without one keeps the keychain entry.
+	keepPasswordRef bool
+	groupOptions    []string
+	groupIndex      int
+	groupCustom     bool
+	// advanced is the tab on show while the shared Delete button is focused;
+	// otherwise the focused control picks the tab.
+	advanced bool
 }
 
 type groupPromptState struct {
-	input  textinput.Model
-	action string // create|rename
-	target string // group id for rename
+	input       textinput.Model
+	query       textinput.Model // smart groups only
+	description textinput.Model
+	color       textinput.Model
+	defaultUser textinput.Model // regular groups only
+	defaultKey  textinput.Model
+	defaultJump textinput.Model
+	smart       bool
+	action      string // create|rename
+	target      string // group id for rename
+}
+
+// inputs returns the prompt's fields in tab order.
+func (p *groupPromptState) inputs() []*textinput.Model {
+	if p.smart {
+		return []*textinput.Model{&p.input, &p.query, &p.description, &p.color}
+	}
+	return []*textinput.Model{&p.input, &p.description, &p.color, &p.defaultUser, &p.defaultKey, &p.defaultJump}
+}
+
+// focused returns the input receiving keystrokes, defaulting to the name.
+func (p *groupPromptState) focused() *textinput.Model {
+	for _, input := range p.inputs() {
+		if input.Focused() {
+			return input
+		}
+	}
+	return &p.input
 }
 
 type aboutState struct {
@@ -185,8 +351,36 @@ func cloneHistory(history []HistoryEntry) []HistoryEntry {
 // When respectExpand is true, collapsed groups and unexpanded hosts hide their
 // children (normal list view). When false, all children are always included
 // (used before filter mode so collapsed items remain searchable).
-func flattenHostsImpl(groups []Group, hosts []Host, respectExpand bool) []list.Item {
+// Archived hosts are left out entirely unless showArchived is set, in which
+// case they are collected under a trailing Archived section.
+func flattenHostsImpl(groups []Group, hosts []Host, respectExpand, showArchived bool) []list.Item {
 	var items []list.Item
+	now := time.Now()
+	downAliases := func(members []Host) []string {
+		var down []string
+		for _, h := range members {
+			if h.reportedDown(now) {
+				down = append(down, h.Alias)
+			}
+		}
+		return down
+	}
+
+	colors := make(map[string]string, len(groups))
+	for _, g := range groups {
+		colors[g.ID] = g.Color
+	}
+	var archived []Host
+	visible := make([]Host, 0, len(hosts))
+	for _, h := range hosts {
+		h.ListColor = colors[h.GroupID]
+		if h.Archived {
+			archived = append(archived, h)
+		} else {
+			visible = append(visible, h)
+		}
+	}
+	hosts = visible
 
 	// Pinned hosts first under a synthetic group header.
 	var pinnedIdx []int
@@ -196,9 +390,14 @@ func flattenHostsImpl(groups []Group, hosts []Host, respectExpand bool) []list.I
 		}
 	}
 	if len(pinnedIdx) > 0 {
+		var pinned []Host
+		for _, i := range pinnedIdx {
+			pinned = append(pinned, hosts[i])
+		}
 		items = append(items, groupItem{
 			Group:     Group{ID: "__pinned__", Name: "★ Pinned", Expanded: true},
 			HostCount: len(pinnedIdx),
+			Down:      downAliases(pinned),
 		})
 		for _, i := range pinnedIdx {
 			h := hosts[i]
@@ -215,6 +414,35 @@ func flattenHostsImpl(groups []Group, hosts []Host, respectExpand bool) []list.I
 		}
 	}
 
+	// Smart groups list matching hosts again, like the pinned section.
+	for _, g := range groups {
+		if !g.Smart() {
+			continue
+		}
+		members := smartGroupMembers(g, groups, hosts)
+		var memberHosts []Host
+		for _, i := range members {
+			memberHosts = append(memberHosts, hosts[i])
+		}
+		items = append(items, groupItem{Group: g, HostCount: len(members), Down: downAliases(memberHosts)})
+		if respectExpand && !g.Expanded {
+			continue
+		}
+		for _, i := range members {
+			h := hosts[i]
+			h.ListIndent = 1
+			items = append(items, h)
+			if !respectExpand || h.Expanded {
+				for j := range h.Containers {
+					c := h.Containers[j]
+					c.ParentID = h.ID
+					c.ListIndent = 2
+					items = append(items, c)
+				}
+			}
+		}
+	}
+
 	// Ungrouped hosts.
 	for i := range hosts {
 		if hosts[i].GroupID != "" {
@@ -236,13 +464,16 @@ func flattenHostsImpl(groups []Group, hosts []Host, respectExpand bool) []list.I
 	// Then grouped hosts under each group row.
 	for i := range groups {
 		g := groups[i]
-		hostCount := 0
+		if g.Smart() {
+			continue
+		}
+		var members []Host
 		for j := range hosts {
 			if hosts[j].GroupID == g.ID {
-				hostCount++
+				members = append(members, hosts[j])
 			}
 		}
-		items = append(items, groupItem{Group: g, HostCount: hostCount})
+		items = append(items, groupItem{Group: g, HostCount: len(members), Down: downAliases(members)})
 		if respectExpand && !g.Expanded {
 			continue
 		}
@@ -263,17 +494,69 @@ func flattenHostsImpl(groups []Group, hosts []Host, respectExpand bool) []list.I
 			}
 		}
 	}
+
+	if showArchived && len(archived) > 0 {
+		items = append(items, groupItem{
+			Group:     Group{ID: "__archived__", Name: "🗄 Archived", Expanded: true},
+			HostCount: len(archived),
+		})
+		for _, h := range archived {
+			h.ListIndent = 1
+			items = append(items, h)
+		}
+	}
 	return items
 }
 
 func flattenHosts(groups []Group, hosts []Host) []list.Item {
-	return flattenHostsImpl(groups, hosts, true)
+	return flattenHostsImpl(groups, hosts, true, false)
+}
+
+// refreshList rebuilds the dashboard rows from rawGroups/rawHosts.
+func (m *model) refreshList() {
+	m.list.SetItems(flattenHostsImpl(m.rawGroups, m.listHosts(), true, m.showArchived))
+}
+
+// listHosts is rawHosts as the dashboard shows them, without stopped
+// containers when runningOnly is set.
+func (m model) listHosts() []Host {
+	hosts := m.rawHosts
+	if m.runningOnly {
+		hosts = withoutStoppedContainers(hosts)
+	}
+	if len(m.healthFailing) == 0 {
+		return hosts
+	}
+	annotated := slices.Clone(hosts)
+	for i := range annotated {
+		annotated[i].HealthFailing = m.healthFailing[strings.ToLower(annotated[i].Alias)]
+	}
+	return annotated
+}
+
+// withoutStoppedContainers copies hosts, leaving out containers a scan
+// reported as not running.
+func withoutStoppedContainers(hosts []Host) []Host {
+	filtered := make([]Host, len(hosts))
+	for i, h := range hosts {
+		filtered[i] = h
+		if len(h.Containers) == 0 {
+			continue
+		}
+		filtered[i].Containers = nil
+		for _, c := range h.Containers {
+			if c.Docker.running() {
+				filtered[i].Containers = append(filtered[i].Containers, c)
+			}
+		}
+	}
+	return filtered
 }
 
 // flattenAll includes every host and container regardless of expansion state.
 // Used to populate the list before filter mode so collapsed items are searchable.
 func flattenAll(groups []Group, hosts []Host) []list.Item {
-	return flattenHostsImpl(groups, hosts, false)
+	return flattenHostsImpl(groups, hosts, false, false)
 }
 
 // buildLastConnected returns a map of hostID → most-recent connection timestamp
@@ -296,16 +579,18 @@ func countContainers(hosts []Host) int {
 	return count
 }
 
+// formPlaceholders are indexed by field.
+var formPlaceholders = []string{"my-server", "192.168.1.100", "root", "22", "optional key path", "", "yes to enable (-A)", "user@bastion:port", "5432:localhost:5432", "tmux attach || tmux new", "session name (blank = off)", "optional group name", "optional note", "http://localhost:{forwarded_port}", "YYYY-MM-DD or 7d (blank = never)", "who runs this box", "owning team", "email, chat handle, or pager", "10.0.0.5 (office/VPN address)", "10.0.0.0/8 (blank = probe)", "yes to connect as ssh <alias>", "", "seconds (blank = default)", "Ctrl+P for a preset, e.g. cloudflared access ssh --hostname %h", "vt100, xterm (blank = local TERM)", "yes to keep LANG/LC_* local", "alacritty -e, kitty (blank = ASSHO_TERMINAL)", "1-9 (blank = by list position)", "~/.1password/agent.sock (blank = SSH_AUTH_SOCK)", "db, web, k8s… (blank = from the name)"}
+
 func newFormInputs() []textinput.Model {
 	inputs := make([]textinput.Model, fieldCount)
-	placeholders := []string{"my-server", "192.168.1.100", "root", "22", "optional key path", "", "yes to enable (-A)", "user@bastion:port", "5432:localhost:5432", "optional group name", "optional note"}
 	for i := range inputs {
 		t := textinput.New()
 		t.Cursor.Style = lipgloss.NewStyle().Foreground(colorSecondary)
 		t.Prompt = ""
 		t.PromptStyle = lipgloss.NewStyle().Foreground(colorHighlight).Bold(true)
 		t.TextStyle = lipgloss.NewStyle().Foreground(colorText)
-		t.Placeholder = placeholders[i]
+		t.Placeholder = formPlaceholders[i]
 		t.PlaceholderStyle = lipgloss.NewStyle().Foreground(colorDimText)
 		if i == fieldPassword {
 			t.EchoMode = textinput.EchoPassword
@@ -326,12 +611,21 @@ func newFormState(inputs []textinput.Model) formState {
 }
 
 func initialModel() model {
-	groups, hosts, history, loadErr := loadConfig()
+	return newModel(loadConfig())
+}
+
+// newModel builds the dashboard from a loaded config, assigning missing IDs,
+// archiving expired hosts, and queueing repairs.
+func newModel(groups []Group, hosts []Host, history []HistoryEntry, loadErr error) model {
 	var hostsUpdated bool
 	hosts, hostsUpdated = ensureHostIDs(hosts)
 	var groupsUpdated bool
 	groups, groupsUpdated = ensureGroupIDs(groups)
-	if hostsUpdated || groupsUpdated {
+	var expiredArchived int
+	if autoArchiveExpired() {
+		expiredArchived = archiveExpiredHosts(hosts, time.Now())
+	}
+	if hostsUpdated || groupsUpdated || expiredArchived > 0 {
 		if err := saveConfig(groups, hosts, history); err != nil {
 			if loadErr != nil {
 				loadErr = errors.Join(loadErr, err)
@@ -341,16 +635,11 @@ func initialModel() model {
 		}
 	}
 
-	// Separate keychain lookup warnings (non-fatal, shown as a timed status
-	// message) from real config errors (shown as a permanent banner).
-	var keychainWarning string
-	if loadErr != nil && strings.HasPrefix(loadErr.Error(), "keychain lookup failed:") {
-		keychainWarning = loadErr.Error()
-		loadErr = nil
-	}
 	items := flattenHosts(groups, hosts)
 
-	delegate := hostDelegate{lastConnected: buildLastConnected(history)}
+	roles := loadRoles()
+	compact := loadUIState().Compact
+	delegate := hostDelegate{lastConnected: buildLastConnected(history), quickConnect: true, roles: roles, compact: compact}
 	l := list.New(items, delegate, 0, 0)
 	l.Title = ""
 	l.SetShowStatusBar(false)
@@ -380,7 +669,7 @@ func initialModel() model {
 	sp.Spinner = spinner.Dot
 	sp.Style = spinnerStyle
 
-	hl := list.New([]list.Item{}, delegate, 0, 0)
+	hl := list.New([]list.Item{}, hostDelegate{lastConnected: delegate.lastConnected, lastSession: buildLastSession(history)}, 0, 0)
 	hl.Title = ""
 	hl.SetShowStatusBar(false)
 	hl.SetFilteringEnabled(false)
@@ -399,20 +688,37 @@ func initialModel() model {
 		err:         loadErr,
 		history:     history,
 		historyList: hl,
+		roles:       roles,
+		compact:     compact,
 	}
-	if keychainWarning != "" {
-		m.status.message = keychainWarning
-		m.status.isError = true
+	m.refreshHealthFailing()
+	m.noSSH = sshMissing()
+	if launcherMode() {
+		m.startFiltering()
+	}
+	if expiredArchived > 0 {
+		m.status.message = fmt.Sprintf("Archived %d expired host(s) · press . to show archived hosts", expiredArchived)
+		m.status.isError = false
 		m.status.version++
 	}
+	// Only offer repairs for a config that loaded; a failed load already
+	// shows its own banner.
+	if loadErr == nil {
+		if issues := findIntegrityIssues(groups, hosts, history); len(issues) > 0 {
+			m = m.openRepair(issues)
+		}
+	}
 	return m
 }
 
 func (m model) Init() tea.Cmd {
-	cmds := []tea.Cmd{m.spinner.Tick, headerTick(), dockerRefreshTick()}
+	cmds := []tea.Cmd{m.spinner.Tick, headerTick(), dockerRefreshTick(), detectNetworkCmd(), checkForUpdateCmd(), prefetchSecretsCmd(m.rawHosts)}
 	if m.status.message != "" {
 		cmds = append(cmds, statusClearCmd(m.status.version))
 	}
+	if m.list.FilterState() == list.Filtering {
+		cmds = append(cmds, textinput.Blink)
+	}
 	return tea.Batch(cmds...)
 }
 
@@ -473,6 +779,8 @@ func (m *model) focusInputs() tea.Cmd {
 
 func (m *model) resetForm() {
 	m.form.focus = controlAlias
+	m.form.advanced = false
+	m.setPasswordRevealed(false)
 	m.form.formError = ""
 	m.form.deleteArmed = false
 	for i := range m.form.inputs {
@@ -495,6 +803,18 @@ func fieldForFormControl(control formControl) (int, bool) {
 		return fieldUser, true
 	case controlPort:
 		return fieldPort, true
+	case controlTimeout:
+		return fieldTimeout, true
+	case controlTerm:
+		return fieldTerm, true
+	case controlNoLocale:
+		return fieldNoLocale, true
+	case controlTerminal:
+		return fieldTerminal, true
+	case controlHotkey:
+		return fieldHotkey, true
+	case controlIdentityAgent:
+		return fieldIdentityAgent, true
 	case controlKeyFile, controlKeyPicker:
 		return fieldKeyFile, true
 	case controlPassword:
@@ -505,17 +825,43 @@ func fieldForFormControl(control formControl) (int, bool) {
 		return fieldProxyJump, true
 	case controlLocalForward:
 		return fieldLocalForward, true
+	case controlProxyCommand:
+		return fieldProxyCommand, true
+	case controlInternalHost:
+		return fieldInternalHost, true
+	case controlInternalNets:
+		return fieldInternalNets, true
+	case controlRemoteCommand:
+		return fieldRemoteCommand, true
+	case controlTmuxSession:
+		return fieldTmuxSession, true
+	case controlUseSSHConfig:
+		return fieldUseSSHConfig, true
+	case controlTransport:
+		return fieldTransport, true
 	case controlGroup:
 		return fieldGroup, true
 	case controlNotes:
 		return fieldNotes, true
+	case controlWebURLs:
+		return fieldWebURLs, true
+	case controlExpires:
+		return fieldExpires, true
+	case controlRole:
+		return fieldRole, true
+	case controlOwner:
+		return fieldOwner, true
+	case controlTeam:
+		return fieldTeam, true
+	case controlContact:
+		return fieldContact, true
 	default:
 		return 0, false
 	}
 }
 
 func (m model) formControlAcceptsText(control formControl) bool {
-	if control == controlForwardAgent || control == controlKeyPicker || control == controlDelete {
+	if isFormToggle(control) || control == controlKeyPicker || control == controlDelete {
 		return false
 	}
 	if control == controlGroup && !m.form.groupCustom {
@@ -535,9 +881,18 @@ func (m *model) populateForm(h Host) {
 	m.form.inputs[fieldUser].CursorEnd()
 	m.form.inputs[fieldPort].SetValue(h.Port)
 	m.form.inputs[fieldPort].CursorEnd()
+	if h.ConnectTimeout > 0 {
+		m.form.inputs[fieldTimeout].SetValue(strconv.Itoa(h.ConnectTimeout))
+	}
+	m.form.inputs[fieldTimeout].CursorEnd()
 	m.form.inputs[fieldKeyFile].SetValue(h.IdentityFile)
 	m.form.inputs[fieldKeyFile].CursorEnd()
-	m.form.inputs[fieldPassword].SetValue(h.Password)
+	password, err := hostPassword(h)
+	m.form.keepPasswordRef = err != nil
+	if m.form.keepPasswordRef {
+		m.form.inputs[fieldPassword].Placeholder = "stored password unavailable; type to replace"
+	}
+	m.form.inputs[fieldPassword].SetValue(password)
 	m.form.inputs[fieldPassword].CursorEnd()
 	if h.ForwardAgent {
 		m.form.inputs[fieldForwardAgent].SetValue("yes")
@@ -545,10 +900,38 @@ func (m *model) populateForm(h Host) {
 		m.form.inputs[fieldForwardAgent].SetValue("")
 	}
 	m.form.inputs[fieldForwardAgent].CursorEnd()
+	if h.UseSSHConfig {
+		m.form.inputs[fieldUseSSHConfig].SetValue("yes")
+	} else {
+		m.form.inputs[fieldUseSSHConfig].SetValue("")
+	}
+	m.form.inputs[fieldTransport].SetValue(h.Transport)
+	m.form.inputs[fieldTerm].SetValue(h.Term)
+	m.form.inputs[fieldTerm].CursorEnd()
+	if h.NoLocale {
+		m.form.inputs[fieldNoLocale].SetValue("yes")
+	} else {
+		m.form.inputs[fieldNoLocale].SetValue("")
+	}
+	m.form.inputs[fieldTerminal].SetValue(h.Terminal)
+	m.form.inputs[fieldTerminal].CursorEnd()
+	if h.Hotkey != 0 {
+		m.form.inputs[fieldHotkey].SetValue(strconv.Itoa(h.Hotkey))
+	} else {
+		m.form.inputs[fieldHotkey].SetValue("")
+	}
 	m.form.inputs[fieldProxyJump].SetValue(h.ProxyJump)
 	m.form.inputs[fieldProxyJump].CursorEnd()
+	m.form.inputs[fieldProxyCommand].SetValue(h.ProxyCommand)
+	m.form.inputs[fieldProxyCommand].CursorEnd()
+	m.form.inputs[fieldIdentityAgent].SetValue(h.IdentityAgent)
+	m.form.inputs[fieldIdentityAgent].CursorEnd()
 	m.form.inputs[fieldLocalForward].SetValue(h.LocalForward)
 	m.form.inputs[fieldLocalForward].CursorEnd()
+	m.form.inputs[fieldRemoteCommand].SetValue(h.RemoteCommand)
+	m.form.inputs[fieldRemoteCommand].CursorEnd()
+	m.form.inputs[fieldTmuxSession].SetValue(h.TmuxSession)
+	m.form.inputs[fieldTmuxSession].CursorEnd()
 	groupName := ""
 	if h.GroupID != "" {
 		if idx := findGroupIndexByID(m.rawGroups, h.GroupID); idx != -1 {
@@ -559,6 +942,22 @@ func (m *model) populateForm(h Host) {
 	m.form.inputs[fieldGroup].CursorEnd()
 	m.form.inputs[fieldNotes].SetValue(h.Notes)
 	m.form.inputs[fieldNotes].CursorEnd()
+	m.form.inputs[fieldWebURLs].SetValue(strings.Join(h.WebURLs, ", "))
+	m.form.inputs[fieldWebURLs].CursorEnd()
+	m.form.inputs[fieldExpires].SetValue(h.ExpiresAt)
+	m.form.inputs[fieldExpires].CursorEnd()
+	m.form.inputs[fieldRole].SetValue(h.Role)
+	m.form.inputs[fieldRole].CursorEnd()
+	m.form.inputs[fieldOwner].SetValue(h.Owner)
+	m.form.inputs[fieldOwner].CursorEnd()
+	m.form.inputs[fieldTeam].SetValue(h.Team)
+	m.form.inputs[fieldTeam].CursorEnd()
+	m.form.inputs[fieldContact].SetValue(h.Contact)
+	m.form.inputs[fieldContact].CursorEnd()
+	m.form.inputs[fieldInternalHost].SetValue(h.InternalHostname)
+	m.form.inputs[fieldInternalHost].CursorEnd()
+	m.form.inputs[fieldInternalNets].SetValue(strings.Join(h.InternalSubnets, ", "))
+	m.form.inputs[fieldInternalNets].CursorEnd()
 }
 
 func (m *model) saveFromForm() error {
@@ -572,33 +971,124 @@ func (m *model) saveFromForm() error {
 	if hostname == "" {
 		return fmt.Errorf("hostname is required")
 	}
+	if err := validateHostname(hostname); err != nil {
+		return err
+	}
+	hostname = bareHostname(hostname)
+	internalHost := strings.TrimSpace(m.form.inputs[fieldInternalHost].Value())
+	if internalHost != "" {
+		if err := validateHostname(internalHost); err != nil {
+			return fmt.Errorf("internal %w", err)
+		}
+		internalHost = bareHostname(internalHost)
+	}
+	internalNets := parseSubnets(m.form.inputs[fieldInternalNets].Value())
+	if err := validateSubnets(internalNets); err != nil {
+		return err
+	}
+	if len(internalNets) > 0 && internalHost == "" {
+		return fmt.Errorf("internal subnet needs an internal hostname")
+	}
+	user := strings.TrimSpace(m.form.inputs[fieldUser].Value())
+	if err := checkArgValue("user", user); err != nil {
+		return err
+	}
+	proxyJump := strings.TrimSpace(m.form.inputs[fieldProxyJump].Value())
+	if err := checkArgValue("proxyjump", proxyJump); err != nil {
+		return err
+	}
+	proxyCommand := strings.TrimSpace(m.form.inputs[fieldProxyCommand].Value())
+	if err := validateProxyCommand(proxyCommand); err != nil {
+		return err
+	}
+	if proxyCommand != "" && proxyJump != "" {
+		return fmt.Errorf("proxycommand replaces the ProxyJump; clear one of them")
+	}
 	if portStr := strings.TrimSpace(m.form.inputs[fieldPort].Value()); portStr != "" {
 		n, err := strconv.Atoi(portStr)
 		if err != nil || n < 1 || n > 65535 {
 			return fmt.Errorf("port must be a number between 1 and 65535")
 		}
 	}
-	for i := range m.rawHosts {
-		if strings.EqualFold(strings.TrimSpace(m.rawHosts[i].Alias), alias) {
-			if m.form.selectedHost == nil || m.rawHosts[i].ID != m.form.selectedHost.ID {
-				return fmt.Errorf("alias already exists: %s", alias)
-			}
+	connectTimeout, err := parseConnectTimeout(m.form.inputs[fieldTimeout].Value())
+	if err != nil {
+		return err
+	}
+	term := strings.TrimSpace(m.form.inputs[fieldTerm].Value())
+	if err := checkArgValue("term", term); err != nil {
+		return err
+	}
+	terminal := strings.TrimSpace(m.form.inputs[fieldTerminal].Value())
+	if err := validateTerminal(terminal); err != nil {
+		return err
+	}
+	hotkey, err := parseHotkey(m.form.inputs[fieldHotkey].Value())
+	if err != nil {
+		return err
+	}
+	identityAgent := strings.TrimSpace(m.form.inputs[fieldIdentityAgent].Value())
+	if err := validateIdentityAgent(identityAgent); err != nil {
+		return err
+	}
+	remoteCommand := strings.TrimSpace(m.form.inputs[fieldRemoteCommand].Value())
+	tmuxSession := strings.TrimSpace(m.form.inputs[fieldTmuxSession].Value())
+	if tmuxSession != "" {
+		if !validTmuxSession(tmuxSession) {
+			return fmt.Errorf("tmux session may only contain letters, digits, '-' and '_'")
+		}
+		if remoteCommand != "" {
+			return fmt.Errorf("set either a remote command or a tmux session, not both")
 		}
 	}
+	webURLs := parseWebURLs(m.form.inputs[fieldWebURLs].Value())
+	if err := validateWebURLs(webURLs); err != nil {
+		return err
+	}
+	expiresAt, err := parseExpiry(m.form.inputs[fieldExpires].Value(), time.Now())
+	if err != nil {
+		return err
+	}
+	editingID := ""
+	if m.form.selectedHost != nil {
+		editingID = m.form.selectedHost.ID
+	}
+	if aliasTaken(m.rawHosts, alias, editingID) {
+		return fmt.Errorf("alias already exists: %s", alias)
+	}
 
 	fwdAgent := strings.ToLower(strings.TrimSpace(m.form.inputs[fieldForwardAgent].Value()))
 	newHost := Host{
-		ID:           "",
-		Alias:        alias,
-		Hostname:     hostname,
-		User:         m.form.inputs[fieldUser].Value(),
-		Port:         m.form.inputs[fieldPort].Value(),
-		ProxyJump:    m.form.inputs[fieldProxyJump].Value(),
-		LocalForward: m.form.inputs[fieldLocalForward].Value(),
-		IdentityFile: m.form.inputs[fieldKeyFile].Value(),
-		Notes:        m.form.inputs[fieldNotes].Value(),
-		Password:     m.form.inputs[fieldPassword].Value(),
-		ForwardAgent: fwdAgent == "yes" || fwdAgent == "1" || fwdAgent == "true",
+		ID:            "",
+		Alias:         alias,
+		Hostname:      hostname,
+		User:          user,
+		Port:          m.form.inputs[fieldPort].Value(),
+		ProxyJump:     proxyJump,
+		ProxyCommand:  proxyCommand,
+		IdentityAgent: identityAgent,
+		LocalForward:  m.form.inputs[fieldLocalForward].Value(),
+		RemoteCommand: remoteCommand,
+		TmuxSession:   tmuxSession,
+		WebURLs:       webURLs,
+		ExpiresAt:     expiresAt,
+
+		ConnectTimeout:   connectTimeout,
+		InternalHostname: internalHost,
+		InternalSubnets:  internalNets,
+		Role:             strings.ToLower(strings.TrimSpace(m.form.inputs[fieldRole].Value())),
+		Owner:            strings.TrimSpace(m.form.inputs[fieldOwner].Value()),
+		Team:             strings.TrimSpace(m.form.inputs[fieldTeam].Value()),
+		Contact:          strings.TrimSpace(m.form.inputs[fieldContact].Value()),
+		IdentityFile:     m.form.inputs[fieldKeyFile].Value(),
+		Notes:            m.form.inputs[fieldNotes].Value(),
+		Password:         m.form.inputs[fieldPassword].Value(),
+		ForwardAgent:     fwdAgent == "yes" || fwdAgent == "1" || fwdAgent == "true",
+		UseSSHConfig:     formToggleEnabled(m.form.inputs[fieldUseSSHConfig].Value()),
+		Transport:        m.form.inputs[fieldTransport].Value(),
+		Term:             term,
+		NoLocale:         formToggleEnabled(m.form.inputs[fieldNoLocale].Value()),
+		Terminal:         terminal,
+		Hotkey:           hotkey,
 	}
 	groupName := strings.TrimSpace(m.form.inputs[fieldGroup].Value())
 	if !m.form.groupCustom {
@@ -620,6 +1110,9 @@ func (m *model) saveFromForm() error {
 		newHost.GroupID = ""
 	} else {
 		groupIdx := findGroupByName(m.rawGroups, groupName)
+		if groupIdx != -1 && m.rawGroups[groupIdx].Smart() {
+			return fmt.Errorf("%s is a smart group; hosts join it by matching its query", groupName)
+		}
 		if groupIdx == -1 {
 			m.rawGroups = append(m.rawGroups, Group{
 				ID:       newGroupID(),
@@ -640,6 +1133,20 @@ func (m *model) saveFromForm() error {
 				newHost.Containers = h.Containers
 				newHost.Expanded = h.Expanded
 				newHost.Pinned = h.Pinned
+				newHost.Archived = h.Archived
+				newHost.Stats = h.Stats
+				newHost.FirstContact = h.FirstContact
+				newHost.OS = h.OS
+				newHost.Maintenance = h.Maintenance
+				newHost.SourceID = h.SourceID
+				newHost.HostKeyPin = h.HostKeyPin
+				newHost.Banner = h.Banner
+				if m.form.keepPasswordRef && (newHost.Password == "" || secretsOffline()) {
+					newHost.PasswordRef = h.PasswordRef
+				}
+				if newHost.Hostname == h.Hostname {
+					newHost.LastIPs = h.LastIPs
+				}
 				m.rawHosts[i] = newHost
 				break
 			}
@@ -649,7 +1156,7 @@ func (m *model) saveFromForm() error {
 		m.rawHosts = append(m.rawHosts, newHost)
 	}
 
-	m.list.SetItems(flattenHosts(m.rawGroups, m.rawHosts))
+	m.refreshList()
 	if err := m.save(); err != nil {
 		m.restoreSnapshot(snapshot)
 		return fmt.Errorf("failed to save changes: %w", err)
@@ -658,11 +1165,17 @@ func (m *model) saveFromForm() error {
 }
 
 func (m *model) save() error {
-	return saveConfig(m.rawGroups, m.rawHosts, m.history)
+	history, err := writeConfig(m.rawGroups, m.rawHosts, m.history)
+	if err == nil {
+		m.history = history
+	}
+	return err
 }
 
 func (m *model) refreshDelegate() {
-	m.list.SetDelegate(hostDelegate{lastConnected: buildLastConnected(m.history)})
+	m.rowBusy = m.rowActivity()
+	m.list.SetDelegate(hostDelegate{lastConnected: buildLastConnected(m.history), lookups: m.dnsLookups, marked: m.marked, quickConnect: true,
+		busy: m.rowBusy, spinner: m.rowSpinner(), roles: m.roles, compact: m.compact})
 }
 
 func (m *model) rebuildHistoryList() {
@@ -697,13 +1210,17 @@ func (m *model) rebuildHistoryList() {
 		_ = m.save()
 	}
 	m.historyList.SetItems(items)
+	m.historyList.SetDelegate(hostDelegate{lastConnected: buildLastConnected(m.history), lastSession: buildLastSession(m.history)})
 	m.refreshDelegate()
 }
 
 func (m *model) buildGroupOptions(selectedName string) {
+	defer m.refreshInheritedPlaceholders()
 	m.form.groupOptions = []string{"(none)"}
 	for i := range m.rawGroups {
-		m.form.groupOptions = append(m.form.groupOptions, m.rawGroups[i].Name)
+		if !m.rawGroups[i].Smart() {
+			m.form.groupOptions = append(m.form.groupOptions, m.rawGroups[i].Name)
+		}
 	}
 	m.form.groupOptions = append(m.form.groupOptions, "+ New group...")
 	m.form.groupIndex = 0
@@ -742,6 +1259,7 @@ func (m *model) applyGroupSelectionToInput() {
 		m.form.groupIndex = len(m.form.groupOptions) - 1
 	}
 	m.form.inputs[fieldGroup].SetValue(m.form.groupOptions[m.form.groupIndex])
+	m.refreshInheritedPlaceholders()
 }
 
 func (m *model) deleteGroupByID(groupID string) error {
@@ -758,7 +1276,7 @@ func (m *model) deleteGroupByID(groupID string) error {
 			m.rawHosts[i].GroupID = ""
 		}
 	}
-	m.list.SetItems(flattenHosts(m.rawGroups, m.rawHosts))
+	m.refreshList()
 	if err := m.save(); err != nil {
 		m.restoreSnapshot(snapshot)
 		return err
@@ -775,6 +1293,43 @@ func (m *model) openGroupPrompt(action, targetID, initialName string) {
 	m.groupPrompt.input.SetValue(initialName)
 	m.groupPrompt.input.CursorEnd()
 	m.groupPrompt.input.Focus()
+	m.groupPrompt.smart = false
+	m.groupPrompt.query = newGroupQueryInput()
+	m.groupPrompt.description = newGroupDetailInput("  Description ", "optional, e.g. EU customer clusters")
+	m.groupPrompt.color = newGroupDetailInput("  Color       ", "optional, e.g. teal or #2DD4BF")
+	m.groupPrompt.defaultUser = newGroupDetailInput("  User        ", "default for members, e.g. deploy")
+	m.groupPrompt.defaultKey = newGroupDetailInput("  Key File    ", "default for members, e.g. ~/.ssh/id_prod")
+	m.groupPrompt.defaultJump = newGroupDetailInput("  ProxyJump   ", "default for members, e.g. bastion")
+	if idx := findGroupIndexByID(m.rawGroups, targetID); idx != -1 {
+		g := m.rawGroups[idx]
+		m.groupPrompt.description.SetValue(g.Description)
+		m.groupPrompt.color.SetValue(g.Color)
+		m.groupPrompt.defaultUser.SetValue(g.DefaultUser)
+		m.groupPrompt.defaultKey.SetValue(g.DefaultIdentityFile)
+		m.groupPrompt.defaultJump.SetValue(g.DefaultProxyJump)
+	}
+}
+
+func newGroupDetailInput(prompt, placeholder string) textinput.Model {
+	input := textinput.New()
+	input.Prompt = prompt
+	input.Placeholder = placeholder
+	input.PromptStyle = lipgloss.NewStyle().Foreground(colorMuted)
+	input.TextStyle = lipgloss.NewStyle().Foreground(colorText)
+	input.PlaceholderStyle = lipgloss.NewStyle().Foreground(colorSubtle)
+	input.Cursor.Style = lipgloss.NewStyle().Foreground(colorSecondary)
+	return input
+}
+
+func (m *model) openSmartGroupPrompt(targetID, initialName, initialQuery string) {
+	action := "create"
+	if targetID != "" {
+		action = "rename"
+	}
+	m.openGroupPrompt(action, targetID, initialName)
+	m.groupPrompt.smart = true
+	m.groupPrompt.query.SetValue(initialQuery)
+	m.groupPrompt.query.CursorEnd()
 }
 
 // moveItem reorders the selected item in the list by swapping it with its
@@ -799,7 +1354,7 @@ func (m *model) moveItem(direction int) string {
 		}
 		snapshot := m.snapshot()
 		m.rawGroups[idx], m.rawGroups[newIdx] = m.rawGroups[newIdx], m.rawGroups[idx]
-		m.list.SetItems(flattenHosts(m.rawGroups, m.rawHosts))
+		m.refreshList()
 		if err := m.save(); err != nil {
 			m.restoreSnapshot(snapshot)
 			return fmt.Sprintf("Failed to reorder: %v", err)
@@ -841,7 +1396,7 @@ func (m *model) moveItem(direction int) string {
 
 		snapshot := m.snapshot()
 		m.rawHosts[idx], m.rawHosts[neighborIdx] = m.rawHosts[neighborIdx], m.rawHosts[idx]
-		m.list.SetItems(flattenHosts(m.rawGroups, m.rawHosts))
+		m.refreshList()
 		if err := m.save(); err != nil {
 			m.restoreSnapshot(snapshot)
 			return fmt.Sprintf("Failed to reorder: %v", err)
@@ -853,6 +1408,52 @@ func (m *model) moveItem(direction int) string {
 	return ""
 }
 
+// moveItemAcross moves the selected host into the previous (-1) or next (+1)
+// regular group, in list order with the ungrouped section first. The host
+// lands at the bottom of its new group, which is expanded so it stays in
+// view. Returns a non-empty status message on error or no-op.
+func (m *model) moveItemAcross(direction int) string {
+	item, ok := m.list.SelectedItem().(Host)
+	if !ok || item.IsContainer || item.Archived {
+		return ""
+	}
+	idx := findHostIndexByID(m.rawHosts, item.ID)
+	if idx == -1 {
+		return ""
+	}
+	lanes := []string{""}
+	for _, g := range m.rawGroups {
+		if !g.Smart() {
+			lanes = append(lanes, g.ID)
+		}
+	}
+	lane := 0
+	for i, id := range lanes {
+		if id == m.rawHosts[idx].GroupID {
+			lane = i
+		}
+	}
+	target := lane + direction
+	if target < 0 || target >= len(lanes) {
+		return ""
+	}
+
+	snapshot := m.snapshot()
+	h := m.rawHosts[idx]
+	h.GroupID = lanes[target]
+	m.rawHosts = append(append(m.rawHosts[:idx:idx], m.rawHosts[idx+1:]...), h)
+	if groupIdx := findGroupIndexByID(m.rawGroups, h.GroupID); groupIdx != -1 {
+		m.rawGroups[groupIdx].Expanded = true
+	}
+	m.refreshList()
+	if err := m.save(); err != nil {
+		m.restoreSnapshot(snapshot)
+		return fmt.Sprintf("Failed to move host: %v", err)
+	}
+	m.reselectItem(item.ID, false)
+	return ""
+}
+
 // reselectItem finds an item by ID in the flat list and selects it.
 func (m *model) reselectItem(id string, isGroup bool) {
 	for i, it := range m.list.Items() {
@@ -875,19 +1476,76 @@ func (m *model) clearListDeleteConfirm() {
 }
 
 func (m model) connectToHost(h Host) (tea.Model, tea.Cmd) {
+	return m.connectWith(h, sshActionConnect)
+}
+
+// connectWith checks h's host key and then opens a session, ending the TUI
+// for sshActionConnect or suspending it for sshActionRoundConnect.
+func (m model) connectWith(h Host, kind sshActionKind) (tea.Model, tea.Cmd) {
+	if h.inMaintenance(time.Now()) && m.maintenanceConfirm != h.ID {
+		m.maintenanceConfirm = h.ID
+		if kind == sshActionRoundConnect {
+			return m.holdRound(maintenanceWarning(h) + " · press enter again to connect")
+		}
+		m.status.message = maintenanceWarning(h) + " · press enter again to connect"
+		m.status.isError = true
+		m.status.version++
+		return m, statusClearCmd(m.status.version)
+	}
+	if m.armKeyPermissionCheck(h) {
+		if kind == sshActionRoundConnect {
+			return m.holdRound(m.status.message)
+		}
+		return m, statusClearCmd(m.status.version)
+	}
+	m.maintenanceConfirm = ""
+	m.permFix = permFixState{}
+	if h.Transport == transportPSRemoting && !h.IsContainer {
+		if kind == sshActionRoundConnect {
+			return m.connectRoundTrusted(h)
+		}
+		return m.connectToHostTrusted(h)
+	}
+	if h.IsContainer && !h.Docker.running() {
+		m.status.message = fmt.Sprintf("%s is %s; start it before connecting", h.Alias, h.Docker.State)
+		m.status.isError = true
+		m.status.version++
+		return m, statusClearCmd(m.status.version)
+	}
 	trustHost := h
 	if h.IsContainer && h.ParentID != "" {
 		if parentIndex := findHostIndexByID(m.rawHosts, h.ParentID); parentIndex >= 0 {
 			trustHost = m.rawHosts[parentIndex]
+			if h.isGuest() && h.Hostname != "" {
+				trustHost = guestEndpoint(resolveEndpoint(trustHost, m.inventory()), h)
+			}
 		}
 	}
-	return m, checkHostTrustCmd(pendingSSHAction{kind: sshActionConnect, host: h, trustHost: trustHost})
+	if m.noSSH != nil && usesSSH(trustHost) {
+		m.status.message = m.noSSH.Error() + " · run assho doctor"
+		m.status.isError = true
+		m.status.version++
+		return m, statusClearCmd(m.status.version)
+	}
+	return m, checkHostTrustCmd(pendingSSHAction{kind: kind, host: h, trustHost: trustHost, inv: m.inventory()})
 }
 
 func (m model) connectToHostTrusted(h Host) (tea.Model, tea.Cmd) {
 	m.clearListDeleteConfirm()
+	launcher := terminalLauncher(h)
+	var launched connectCommand
+	if launcher != nil {
+		var err error
+		if launched, err = launchInTerminal(h, m.inventory(), launcher); err != nil {
+			m.status.message = fmt.Sprintf("Failed to open %s: %v", h.Alias, err)
+			m.status.isError = true
+			m.status.version++
+			return m, statusClearCmd(m.status.version)
+		}
+	}
 	snapshot := m.snapshot()
 	m.history = recordHistory(h.ID, h.Alias, m.history)
+	m.updateHostStats(h.ID, func(s *HostStats) { s.Connections++ })
 	if err := m.save(); err != nil {
 		m.restoreSnapshot(snapshot)
 		m.status.message = fmt.Sprintf("Failed to save history: %v", err)
@@ -896,6 +1554,15 @@ func (m model) connectToHostTrusted(h Host) (tea.Model, tea.Cmd) {
 		return m, statusClearCmd(m.status.version)
 	}
 	m.refreshDelegate()
+	if launcher != nil {
+		m.status.message = fmt.Sprintf("Opened %s in %s", h.Alias, launcher[0])
+		if launched.missingSSHPass {
+			m.status.message += "; sshpass not found, so ssh asks for the password"
+		}
+		m.status.isError = false
+		m.status.version++
+		return m, statusClearCmd(m.status.version)
+	}
 	m.sshToRun = &h
 	return m, tea.Quit
 }
@@ -912,6 +1579,6 @@ func (m *model) restoreSnapshot(snapshot modelSnapshot) {
 	m.rawGroups = snapshot.rawGroups
 	m.rawHosts = snapshot.rawHosts
 	m.history = snapshot.history
-	m.list.SetItems(flattenHosts(m.rawGroups, m.rawHosts))
+	m.refreshList()
 	m.rebuildHistoryList()
 }
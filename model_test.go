@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/x/ansi"
 )
@@ -152,7 +153,7 @@ func TestPopulateFormNoGroup(t *testing.T) {
 func TestRenderFormViewWideShowsReorganizedSections(t *testing.T) {
 	m := model{
 		width:  120,
-		height: 36,
+		height: 38,
 		form:   newFormState(newFormInputs()),
 	}
 	out := m.renderFormView()
@@ -269,6 +270,51 @@ func TestRenderFormKeepsEveryFocusedControlVisible(t *testing.T) {
 	}
 }
 
+func TestRenderFormMarksSaveErrorOnItsField(t *testing.T) {
+	m := model{width: 80, height: 24, form: newFormState(newFormInputs()), historyList: newTestHistoryListModel()}
+	m.list = newTestListModel(nil, nil)
+	m.buildGroupOptions("")
+	m.form.inputs[fieldAlias].SetValue("app")
+	m.form.inputs[fieldHostname].SetValue("10.0.0.1")
+	m.form.inputs[fieldExpires].SetValue("soon")
+
+	updated, _ := m.updateForm(tea.KeyMsg{Type: tea.KeyCtrlS})
+	m = updated.(model)
+	if m.form.focus != controlExpires {
+		t.Fatalf("expected focus on Expires, got control %d", m.form.focus)
+	}
+	lines := strings.Split(ansi.Strip(m.renderFormView()), "\n")
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "soon" {
+			if i+1 >= len(lines) || !strings.Contains(lines[i+1], "✘ expiry must be") {
+				t.Fatalf("expected the error beneath the Expires value\n%s", strings.Join(lines, "\n"))
+			}
+			return
+		}
+	}
+	t.Fatal("expected the Expires field scrolled into view")
+}
+
+func TestRenderFormActionRowStaysBelowFields(t *testing.T) {
+	for _, size := range []struct{ width, height int }{{52, 16}, {80, 24}, {120, 36}} {
+		m := model{width: size.width, height: size.height, form: newFormState(newFormInputs())}
+		m.buildGroupOptions("")
+		if out := ansi.Strip(m.renderFormView()); !strings.Contains(out, "Save ^S") || strings.Contains(out, "Delete host") {
+			t.Fatalf("%dx%d: expected Save without Delete when adding", size.width, size.height)
+		}
+		host := Host{ID: "h1", Alias: "edge", Hostname: "10.0.0.8"}
+		m.form.selectedHost = &host
+		m.form.focus = controlAlias
+		out := ansi.Strip(m.renderFormView())
+		if !strings.Contains(out, "Save ^S   Test ^T   Delete host") {
+			t.Fatalf("%dx%d: expected the action row while the top field is focused\n%s", size.width, size.height, out)
+		}
+		if strings.Contains(out, "Danger zone") {
+			t.Fatalf("%dx%d: expected Delete only in the action row", size.width, size.height)
+		}
+	}
+}
+
 func TestRenderFormTooSmallNoticeFits(t *testing.T) {
 	m := model{width: 30, height: 8, form: newFormState(newFormInputs())}
 	out := m.renderFormView()
@@ -372,12 +418,12 @@ func TestFormAgentForwardingToggle(t *testing.T) {
 
 	result, _ := m.updateForm(tea.KeyMsg{Type: tea.KeyEnter})
 	got := result.(model)
-	if !forwardAgentEnabled(got.form.inputs[fieldForwardAgent].Value()) {
+	if !formToggleEnabled(got.form.inputs[fieldForwardAgent].Value()) {
 		t.Fatal("Enter should enable agent forwarding")
 	}
 	result, _ = got.updateForm(tea.KeyMsg{Type: tea.KeySpace})
 	got = result.(model)
-	if forwardAgentEnabled(got.form.inputs[fieldForwardAgent].Value()) {
+	if formToggleEnabled(got.form.inputs[fieldForwardAgent].Value()) {
 		t.Fatal("Space should disable agent forwarding")
 	}
 }
@@ -416,6 +462,42 @@ func TestFormNavigationIncludesKeyPicker(t *testing.T) {
 	}
 }
 
+func TestFormAdvancedTab(t *testing.T) {
+	m := model{state: stateForm, width: 80, height: 24, form: newFormState(newFormInputs())}
+	m.buildGroupOptions("")
+	out := ansi.Strip(m.renderFormView())
+	if !strings.Contains(out, "Advanced   ctrl+o") || strings.Contains(out, "Remote command") {
+		t.Fatalf("expected a short Basic tab\n%s", out)
+	}
+
+	result, _ := m.updateForm(tea.KeyMsg{Type: tea.KeyCtrlO})
+	got := result.(model)
+	if got.form.focus != controlLocalForward || !strings.Contains(ansi.Strip(got.renderFormView()), "Remote command") {
+		t.Fatalf("expected ctrl+o to open the Advanced tab, focus %v", got.form.focus)
+	}
+	got.form.inputs[fieldTmuxSession].SetValue("main")
+	got.form.inputs[fieldTransport].SetValue(transportPowerShell)
+	result, _ = got.updateForm(tea.KeyMsg{Type: tea.KeyCtrlO})
+	got = result.(model)
+	if got.form.focus != controlAlias || !strings.Contains(ansi.Strip(got.renderFormView()), "Advanced · 2 set") {
+		t.Fatalf("expected Basic again with the Advanced options counted, focus %v", got.form.focus)
+	}
+
+	got.form.focus = controlGroup
+	result, _ = got.updateForm(tea.KeyMsg{Type: tea.KeyTab})
+	if got = result.(model); !got.formAdvancedTab() {
+		t.Fatal("expected Tab past the last Basic field to open Advanced")
+	}
+
+	host := Host{ID: "h1", Alias: "edge", Hostname: "10.0.0.8"}
+	got.form.selectedHost = &host
+	got.form.focus = controlAlias
+	result, _ = got.updateForm(tea.KeyMsg{Type: tea.KeyShiftTab})
+	if got = result.(model); got.form.focus != controlDelete || got.formAdvancedTab() {
+		t.Fatal("expected Delete reached from Alias to keep the Basic tab")
+	}
+}
+
 func TestFormDeleteStillRequiresTwoConfirmations(t *testing.T) {
 	t.Setenv("HOME", t.TempDir())
 	t.Setenv("ASSHO_STORE_PASSWORD", "0")
@@ -531,3 +613,67 @@ func TestRebuildHistoryListNoPruneWhenAllExist(t *testing.T) {
 // Verify that the history list items satisfy list.Item interface.
 var _ list.Item = Host{}
 var _ list.Item = groupItem{}
+
+func TestRenderListHelpFitsWidth(t *testing.T) {
+	for _, width := range []int{40, 76, 136} {
+		out := renderListHelp(Host{ID: "h1", Alias: "web"}, width, false)
+		for i, line := range strings.Split(out, "\n") {
+			if got := ansi.StringWidth(line); got > width {
+				t.Fatalf("width %d: help line %d is %d cells", width, i, got)
+			}
+		}
+		if width < 136 && !strings.Contains(out, "more") {
+			t.Fatalf("width %d: expected truncated help to point at ? more", width)
+		}
+	}
+}
+
+// --- group prompt ---
+
+func TestGroupPromptSavesDescriptionAndColor(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("ASSHO_STORE_PASSWORD", "0")
+	groups := []Group{{ID: "g1", Name: "prod", Expanded: true}}
+	hosts := []Host{{ID: "h1", Alias: "web", GroupID: "g1"}}
+	m := model{rawGroups: groups, rawHosts: hosts, list: newTestListModel(groups, hosts), historyList: newTestHistoryListModel()}
+	m.groupPrompt.input = textinput.New()
+	m.openGroupPrompt("rename", "g1", "prod")
+
+	updated, _ := m.updateGroupPrompt(tea.KeyMsg{Type: tea.KeyTab})
+	m = updated.(model)
+	if !m.groupPrompt.description.Focused() {
+		t.Fatal("expected tab to move focus to the description")
+	}
+	m.groupPrompt.description.SetValue("EU customer clusters")
+	m.groupPrompt.color.SetValue("mauve")
+	updated, _ = m.updateGroupPrompt(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(model)
+	if m.state != stateGroupPrompt || !strings.Contains(m.form.formError, "color must be") {
+		t.Fatalf("expected unknown color to be rejected, got %q", m.form.formError)
+	}
+
+	m.groupPrompt.color.SetValue("teal")
+	updated, _ = m.updateGroupPrompt(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(model)
+	if g := m.rawGroups[0]; g.Description != "EU customer clusters" || g.Color != "teal" {
+		t.Fatalf("expected description and color saved, got %+v", g)
+	}
+	for _, item := range m.list.Items() {
+		if h, ok := item.(Host); ok && h.ListColor != "teal" {
+			t.Fatalf("expected member host tinted with the group color, got %q", h.ListColor)
+		}
+	}
+
+	m.openGroupPrompt("rename", "g1", "prod")
+	if m.groupPrompt.description.Value() != "EU customer clusters" || m.groupPrompt.color.Value() != "teal" {
+		t.Fatal("expected rename prompt prefilled with the current description and color")
+	}
+}
+
+func TestGroupColor(t *testing.T) {
+	for value, want := range map[string]bool{"Teal": true, "#2dd4bf": true, "#abc": true, "#12345": false, "#gggggg": false, "mauve": false} {
+		if _, ok := groupColor(value); ok != want {
+			t.Errorf("groupColor(%q) ok = %v, want %v", value, ok, want)
+		}
+	}
+}
@@ -0,0 +1,412 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// --- Internal / External Endpoints ---
+
+// A host may carry a second, internal address for when the laptop is on the
+// office network or VPN. With InternalSubnets set, the internal address is
+// used whenever a local interface sits in one of those subnets; without them
+// it is used whenever its SSH port answers a quick probe.
+
+// localAddrs and probeTCP are replaced in tests.
+var localAddrs = func() []net.IP {
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return nil
+	}
+	var ips []net.IP
+	for _, addr := range addrs {
+		if ipNet, ok := addr.(*net.IPNet); ok {
+			ips = append(ips, ipNet.IP)
+		}
+	}
+	return ips
+}
+
+var probeTCP = func(address string) bool {
+	conn, err := net.DialTimeout("tcp", address, 500*time.Millisecond)
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
+
+// parseSubnets splits the form value on commas and whitespace.
+func parseSubnets(value string) []string {
+	return parseWebURLs(value)
+}
+
+func validateSubnets(subnets []string) error {
+	for _, subnet := range subnets {
+		if _, _, err := net.ParseCIDR(subnet); err != nil {
+			return fmt.Errorf("internal subnet %q must be in CIDR form, e.g. 10.0.0.0/8", subnet)
+		}
+	}
+	return nil
+}
+
+func onSubnet(subnets []string, ips []net.IP) bool {
+	for _, subnet := range subnets {
+		_, ipNet, err := net.ParseCIDR(subnet)
+		if err != nil {
+			continue
+		}
+		for _, ip := range ips {
+			if ipNet.Contains(ip) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// preferInternal reports whether the internal address should be used right
+// now.
+func preferInternal(h Host) bool {
+	if h.InternalHostname == "" {
+		return false
+	}
+	if len(h.InternalSubnets) > 0 {
+		return onSubnet(h.InternalSubnets, localAddrs())
+	}
+	port := h.Port
+	if port == "" {
+		port = "22"
+	}
+	return probeTCP(hostPort(h.InternalHostname, port))
+}
+
+// hostInventory is the hosts and groups an endpoint is resolved against:
+// its group's defaults and the assho hosts its ProxyJump names.
+type hostInventory struct {
+	hosts  []Host
+	groups []Group
+}
+
+// inventory copies the dashboard's hosts and groups, so commands resolving
+// endpoints off the UI thread see unsaved edits without sharing its slices.
+func (m model) inventory() hostInventory {
+	return hostInventory{hosts: cloneHosts(m.rawHosts), groups: cloneGroups(m.rawGroups)}
+}
+
+// resolveEndpoint returns a copy of h addressed the way it should be reached
+// from the current network, after filling in its group defaults and applying
+// the active network profile. The copy has its internal address cleared, so
+// resolving it again never probes twice.
+func resolveEndpoint(h Host, inv hostInventory) Host {
+	h = applyGroupDefaults(h, inv.groups)
+	if profile, profileGroups, ok := activeNetwork(); ok {
+		h = applyNetworkOverrides(h, profile, profileGroups)
+	}
+	if h.ProxyJump != "" && h.ProxyCommand == "" && !h.UseSSHConfig {
+		h.ProxyJump = resolveJumpAliases(h.ProxyJump, inv.hosts, inv.groups, loadSSHConfigBlocks())
+	}
+	if h.InternalHostname == "" {
+		return h
+	}
+	if preferInternal(h) {
+		h.Hostname = h.InternalHostname
+	}
+	h.InternalHostname = ""
+	h.InternalSubnets = nil
+	return h
+}
+
+// internalRuleLabel describes when the internal address is chosen.
+func internalRuleLabel(h Host) string {
+	if len(h.InternalSubnets) == 0 {
+		return h.InternalHostname + " when reachable"
+	}
+	return h.InternalHostname + " on " + strings.Join(h.InternalSubnets, ", ")
+}
+
+// --- Network Profiles ---
+
+// NetworkProfile applies per-location overrides. A profile is active when
+// every condition it sets holds; the first active profile in config order
+// wins. Profiles are edited by hand in the "networks" section of hosts.json.
+type NetworkProfile struct {
+	Name      string            `json:"name"`
+	Gateway   string            `json:"gateway,omitempty"`
+	SSID      string            `json:"ssid,omitempty"`
+	Subnet    string            `json:"subnet,omitempty"`
+	Tailscale bool              `json:"tailscale,omitempty"`
+	Overrides []NetworkOverride `json:"overrides,omitempty"`
+}
+
+// NetworkOverride replaces connection fields on hosts matching a smart-group
+// query (all hosts when Match is empty). ProxyJump "none" removes the jump.
+type NetworkOverride struct {
+	Match     string `json:"match,omitempty"`
+	Hostname  string `json:"hostname,omitempty"`
+	User      string `json:"user,omitempty"`
+	Port      string `json:"port,omitempty"`
+	ProxyJump string `json:"proxy_jump,omitempty"`
+}
+
+type networkFacts struct {
+	gateway   string
+	ssid      string
+	tailscale bool
+	addrs     []net.IP
+}
+
+// defaultGateway, wifiSSID, and tailscaleUp shell out or read /proc; tests
+// replace them.
+var defaultGateway = func() string {
+	if runtime.GOOS == "linux" {
+		data, err := os.ReadFile("/proc/net/route")
+		if err != nil {
+			return ""
+		}
+		return parseProcRouteGateway(string(data))
+	}
+	out := commandOutput("route", "-n", "get", "default")
+	for _, line := range strings.Split(out, "\n") {
+		if value, ok := strings.CutPrefix(strings.TrimSpace(line), "gateway:"); ok {
+			return strings.TrimSpace(value)
+		}
+	}
+	return ""
+}
+
+var wifiSSID = func() string {
+	if runtime.GOOS == "darwin" {
+		out := commandOutput("networksetup", "-getairportnetwork", "en0")
+		if _, ssid, ok := strings.Cut(out, ": "); ok {
+			return strings.TrimSpace(ssid)
+		}
+		return ""
+	}
+	if ssid := strings.TrimSpace(commandOutput("iwgetid", "-r")); ssid != "" {
+		return ssid
+	}
+	for _, line := range strings.Split(commandOutput("nmcli", "-t", "-f", "active,ssid", "dev", "wifi"), "\n") {
+		if ssid, ok := strings.CutPrefix(line, "yes:"); ok {
+			return ssid
+		}
+	}
+	return ""
+}
+
+var tailscaleUp = func() bool {
+	var status struct {
+		BackendState string
+	}
+	out := commandOutput("tailscale", "status", "--json")
+	return json.Unmarshal([]byte(out), &status) == nil && status.BackendState == "Running"
+}
+
+func commandOutput(name string, args ...string) string {
+	if !commandExists(name) {
+		return ""
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, name, args...).Output()
+	if err != nil {
+		return ""
+	}
+	return string(out)
+}
+
+// parseProcRouteGateway reads the default route from /proc/net/route, where
+// addresses are little-endian hex.
+func parseProcRouteGateway(table string) string {
+	for _, line := range strings.Split(table, "\n")[1:] {
+		fields := strings.Fields(line)
+		if len(fields) < 3 || fields[1] != "00000000" {
+			continue
+		}
+		raw, err := strconv.ParseUint(fields[2], 16, 32)
+		if err != nil || raw == 0 {
+			continue
+		}
+		return net.IPv4(byte(raw), byte(raw>>8), byte(raw>>16), byte(raw>>24)).String()
+	}
+	return ""
+}
+
+// detectNetworkFacts only gathers what the profiles ask about, so a config
+// without SSID rules never shells out to the Wi-Fi tools.
+func detectNetworkFacts(profiles []NetworkProfile, all bool) networkFacts {
+	var f networkFacts
+	var gateway, ssid, tailscale, subnet bool
+	for _, p := range profiles {
+		gateway = gateway || p.Gateway != ""
+		ssid = ssid || p.SSID != ""
+		tailscale = tailscale || p.Tailscale
+		subnet = subnet || p.Subnet != ""
+	}
+	if all || gateway {
+		f.gateway = defaultGateway()
+	}
+	if all || ssid {
+		f.ssid = wifiSSID()
+	}
+	if all || tailscale {
+		f.tailscale = tailscaleUp()
+	}
+	if all || subnet {
+		f.addrs = localAddrs()
+	}
+	return f
+}
+
+func (p NetworkProfile) matches(f networkFacts) bool {
+	if p.Gateway == "" && p.SSID == "" && p.Subnet == "" && !p.Tailscale {
+		return false
+	}
+	if p.Gateway != "" && p.Gateway != f.gateway {
+		return false
+	}
+	if p.SSID != "" && p.SSID != f.ssid {
+		return false
+	}
+	if p.Subnet != "" && !onSubnet([]string{p.Subnet}, f.addrs) {
+		return false
+	}
+	return !p.Tailscale || f.tailscale
+}
+
+func matchNetworkProfile(profiles []NetworkProfile, f networkFacts) (NetworkProfile, bool) {
+	for _, p := range profiles {
+		if p.matches(f) {
+			return p, true
+		}
+	}
+	return NetworkProfile{}, false
+}
+
+// applyNetworkOverrides returns h with the profile's overrides applied. An
+// overridden hostname replaces the internal/external rule for that host.
+func applyNetworkOverrides(h Host, p NetworkProfile, groups []Group) Host {
+	for _, o := range p.Overrides {
+		if o.Match != "" {
+			q, err := parseHostQuery(o.Match)
+			if err != nil || !q.Match(h, groups) {
+				continue
+			}
+		}
+		if o.Hostname != "" {
+			h.Hostname = o.Hostname
+			h.InternalHostname = ""
+			h.InternalSubnets = nil
+		}
+		if o.User != "" {
+			h.User = o.User
+		}
+		if o.Port != "" {
+			h.Port = o.Port
+		}
+		switch o.ProxyJump {
+		case "":
+		case "none":
+			h.ProxyJump = ""
+		default:
+			h.ProxyJump = o.ProxyJump
+		}
+	}
+	return h
+}
+
+// activeNetwork re-reads the profiles and re-detects the network at most
+// every networkCacheTTL, since every SSH action resolves through it.
+const networkCacheTTL = 30 * time.Second
+
+var networkCache struct {
+	sync.Mutex
+	path    string
+	at      time.Time
+	profile NetworkProfile
+	groups  []Group
+	ok      bool
+}
+
+var activeNetwork = func() (NetworkProfile, []Group, bool) {
+	networkCache.Lock()
+	defer networkCache.Unlock()
+	path := getConfigPath()
+	if networkCache.path == path && time.Since(networkCache.at) < networkCacheTTL {
+		return networkCache.profile, networkCache.groups, networkCache.ok
+	}
+	networkCache.path, networkCache.at = path, time.Now()
+	networkCache.profile, networkCache.groups, networkCache.ok = NetworkProfile{}, nil, false
+	cfg, err := loadConfigFile()
+	if err != nil || len(cfg.Networks) == 0 {
+		return NetworkProfile{}, nil, false
+	}
+	profile, ok := matchNetworkProfile(cfg.Networks, detectNetworkFacts(cfg.Networks, false))
+	networkCache.profile, networkCache.groups, networkCache.ok = profile, cfg.Groups, ok
+	return profile, cfg.Groups, ok
+}
+
+type networkDetectedMsg struct{ name string }
+
+func detectNetworkCmd() tea.Cmd {
+	return func() tea.Msg {
+		profile, _, ok := activeNetwork()
+		if !ok {
+			return networkDetectedMsg{}
+		}
+		return networkDetectedMsg{name: profile.Name}
+	}
+}
+
+func fprintNetwork(w io.Writer, profiles []NetworkProfile, f networkFacts) {
+	value := func(s string) string {
+		if s == "" {
+			return "—"
+		}
+		return s
+	}
+	var addrs []string
+	for _, ip := range f.addrs {
+		if !ip.IsLoopback() {
+			addrs = append(addrs, ip.String())
+		}
+	}
+	tailscale := "down"
+	if f.tailscale {
+		tailscale = "up"
+	}
+	fmt.Fprintf(w, "%-10s %s\n", "gateway", value(f.gateway))
+	fmt.Fprintf(w, "%-10s %s\n", "ssid", value(f.ssid))
+	fmt.Fprintf(w, "%-10s %s\n", "tailscale", tailscale)
+	fmt.Fprintf(w, "%-10s %s\n", "addresses", value(strings.Join(addrs, ", ")))
+	profile, ok := matchNetworkProfile(profiles, f)
+	switch {
+	case len(profiles) == 0:
+		fmt.Fprintf(w, "%-10s %s\n", "profile", "— (no networks configured)")
+	case ok:
+		fmt.Fprintf(w, "%-10s %s (%d overrides)\n", "profile", profile.Name, len(profile.Overrides))
+	default:
+		fmt.Fprintf(w, "%-10s %s\n", "profile", "— (none match)")
+	}
+}
+
+func cliNetwork() {
+	cfg, err := loadConfigFile()
+	if err != nil && !os.IsNotExist(err) {
+		fmt.Fprintf(os.Stderr, "error loading config: %v\n", err)
+		os.Exit(1)
+	}
+	fprintNetwork(os.Stdout, cfg.Networks, detectNetworkFacts(cfg.Networks, true))
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net"
+	"os"
+	"strings"
+	"testing"
+)
+
+func stubNetwork(t *testing.T, ips []string, reachable map[string]bool) *[]string {
+	t.Helper()
+	origAddrs, origProbe, origActive := localAddrs, probeTCP, activeNetwork
+	t.Cleanup(func() {
+		localAddrs, probeTCP, activeNetwork = origAddrs, origProbe, origActive
+	})
+	activeNetwork = func() (NetworkProfile, []Group, bool) { return NetworkProfile{}, nil, false }
+	localAddrs = func() []net.IP {
+		var out []net.IP
+		for _, ip := range ips {
+			out = append(out, net.ParseIP(ip))
+		}
+		return out
+	}
+	var probed []string
+	probeTCP = func(address string) bool {
+		probed = append(probed, address)
+		return reachable[address]
+	}
+	return &probed
+}
+
+func TestResolveEndpointBySubnet(t *testing.T) {
+	h := Host{Hostname: "vpn.example.com", InternalHostname: "10.1.2.3", InternalSubnets: []string{"10.1.0.0/16"}}
+
+	stubNetwork(t, []string{"192.168.1.20", "10.1.40.7"}, nil)
+	got := resolveEndpoint(h, hostInventory{})
+	if got.Hostname != "10.1.2.3" || got.InternalHostname != "" {
+		t.Fatalf("expected internal address on the office subnet, got %+v", got)
+	}
+
+	stubNetwork(t, []string{"192.168.1.20"}, nil)
+	if got := resolveEndpoint(h, hostInventory{}); got.Hostname != "vpn.example.com" {
+		t.Fatalf("expected external address off the office subnet, got %q", got.Hostname)
+	}
+}
+
+func TestResolveEndpointByProbe(t *testing.T) {
+	h := Host{Hostname: "home.example.com", Port: "2222", InternalHostname: "fd00::7"}
+	probed := stubNetwork(t, nil, map[string]bool{"[fd00::7]:2222": true})
+
+	got := resolveEndpoint(h, hostInventory{})
+	if got.Hostname != "fd00::7" {
+		t.Fatalf("expected reachable internal address, got %q", got.Hostname)
+	}
+	if again := resolveEndpoint(got, hostInventory{}); again.Hostname != "fd00::7" || len(*probed) != 1 {
+		t.Fatalf("expected resolving twice to probe once, probed %v", *probed)
+	}
+
+	stubNetwork(t, nil, nil)
+	if got := resolveEndpoint(h, hostInventory{}); got.Hostname != "home.example.com" {
+		t.Fatalf("expected external address when the probe fails, got %q", got.Hostname)
+	}
+}
+
+func TestBuildConnectCommandUsesInternalEndpoint(t *testing.T) {
+	stubNetwork(t, []string{"10.0.5.5"}, nil)
+	parent := Host{ID: "p", Alias: "box", Hostname: "box.example.com", InternalHostname: "10.0.0.9", InternalSubnets: []string{"10.0.0.0/16"}}
+	container := Host{ID: "c", Alias: "web", IsContainer: true, ParentID: "p"}
+
+	for _, h := range []Host{parent, container} {
+		cmd, err := buildConnectCommand(h, hostInventory{hosts: []Host{parent}}, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cmd.sshHost.Hostname != "10.0.0.9" {
+			t.Fatalf("%s: expected internal address, got %q in %v", h.Alias, cmd.sshHost.Hostname, cmd.args)
+		}
+	}
+}
+
+func TestSaveFromFormValidatesInternalEndpoint(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("ASSHO_STORE_PASSWORD", "0")
+	m := model{form: formState{inputs: newFormInputs()}, historyList: newTestHistoryListModel()}
+	m.list = newTestListModel(nil, nil)
+	m.form.inputs[fieldAlias].SetValue("laptop-target")
+	m.form.inputs[fieldHostname].SetValue("host.example.com")
+	m.form.inputs[fieldInternalNets].SetValue("10.0.0.0/8")
+	m.buildGroupOptions("")
+
+	err := m.saveFromForm()
+	if err == nil {
+		t.Fatal("expected subnet without internal hostname to be rejected")
+	}
+	m.focusFormError(err)
+	if m.form.focus != controlInternalNets {
+		t.Fatalf("expected focus on internal subnets, got %d", m.form.focus)
+	}
+
+	m.form.inputs[fieldInternalHost].SetValue("10.0.0.5")
+	m.form.inputs[fieldInternalNets].SetValue("10.0.0.0/8, 172.16.0.0/12")
+	if err := m.saveFromForm(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	h := m.rawHosts[0]
+	if h.InternalHostname != "10.0.0.5" || len(h.InternalSubnets) != 2 {
+		t.Fatalf("expected internal endpoint saved, got %+v", h)
+	}
+}
+
+func TestParseProcRouteGateway(t *testing.T) {
+	table := "Iface\tDestination\tGateway \tFlags\n" +
+		"wlan0\t0000A8C0\t00000000\t0001\n" +
+		"wlan0\t00000000\t0101A8C0\t0003\n"
+	if got := parseProcRouteGateway(table); got != "192.168.1.1" {
+		t.Fatalf("expected 192.168.1.1, got %q", got)
+	}
+	if got := parseProcRouteGateway("Iface\tDestination\tGateway\n"); got != "" {
+		t.Fatalf("expected no gateway, got %q", got)
+	}
+}
+
+func TestMatchNetworkProfile(t *testing.T) {
+	profiles := []NetworkProfile{
+		{Name: "empty"},
+		{Name: "office", SSID: "Corp", Gateway: "10.0.0.1"},
+		{Name: "vpn", Subnet: "100.64.0.0/10", Tailscale: true},
+	}
+	cases := []struct {
+		facts networkFacts
+		want  string
+	}{
+		{networkFacts{ssid: "Corp", gateway: "10.0.0.1"}, "office"},
+		{networkFacts{ssid: "Corp", gateway: "192.168.1.1"}, ""},
+		{networkFacts{tailscale: true, addrs: []net.IP{net.ParseIP("100.100.1.2")}}, "vpn"},
+		{networkFacts{addrs: []net.IP{net.ParseIP("100.100.1.2")}}, ""},
+	}
+	for _, tc := range cases {
+		p, ok := matchNetworkProfile(profiles, tc.facts)
+		if (tc.want == "") == ok || p.Name != tc.want {
+			t.Errorf("facts %+v: expected %q, got %q (ok=%v)", tc.facts, tc.want, p.Name, ok)
+		}
+	}
+}
+
+func TestApplyNetworkOverrides(t *testing.T) {
+	groups := []Group{{ID: "g1", Name: "lab"}}
+	profile := NetworkProfile{Name: "home", Overrides: []NetworkOverride{
+		{Match: "group=lab", ProxyJump: "none", Hostname: "192.168.50.4"},
+		{ProxyJump: "me@home-bastion"},
+		{Match: "alias=never-*", User: "nobody"},
+	}}
+	lab := Host{Alias: "lab-box", Hostname: "lab.example.com", ProxyJump: "office", GroupID: "g1", InternalHostname: "10.0.0.4"}
+	got := applyNetworkOverrides(lab, profile, groups)
+	if got.Hostname != "192.168.50.4" || got.InternalHostname != "" || got.ProxyJump != "me@home-bastion" || got.User != "" {
+		t.Fatalf("unexpected override result %+v", got)
+	}
+
+	stubNetwork(t, nil, nil)
+	activeNetwork = func() (NetworkProfile, []Group, bool) { return profile, groups, true }
+	other := resolveEndpoint(Host{Alias: "web", Hostname: "web.example.com"}, hostInventory{})
+	if other.Hostname != "web.example.com" || other.ProxyJump != "me@home-bastion" {
+		t.Fatalf("expected catch-all override only, got %+v", other)
+	}
+}
+
+func TestSaveConfigPreservesNetworks(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("ASSHO_STORE_PASSWORD", "0")
+	if err := saveConfig(nil, []Host{{ID: "a", Alias: "web", Hostname: "10.0.0.1"}}, nil); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := loadConfigFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.Networks = []NetworkProfile{{Name: "office", SSID: "Corp"}}
+	data, _ := json.Marshal(cfg)
+	if err := os.WriteFile(getConfigPath(), data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := saveConfig(nil, []Host{{ID: "a", Alias: "web2", Hostname: "10.0.0.1"}}, nil); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err = loadConfigFile()
+	if err != nil || len(cfg.Networks) != 1 || cfg.Networks[0].Name != "office" || cfg.Hosts[0].Alias != "web2" {
+		t.Fatalf("expected hand-edited networks kept across saves, got %+v %v", cfg, err)
+	}
+}
+
+func TestFprintNetwork(t *testing.T) {
+	var buf bytes.Buffer
+	facts := networkFacts{gateway: "10.0.0.1", ssid: "Corp", addrs: []net.IP{net.ParseIP("127.0.0.1"), net.ParseIP("10.0.0.8")}}
+	fprintNetwork(&buf, []NetworkProfile{{Name: "office", SSID: "Corp", Overrides: []NetworkOverride{{}}}}, facts)
+	out := buf.String()
+	for _, want := range []string{"gateway    10.0.0.1", "ssid       Corp", "tailscale  down", "addresses  10.0.0.8\n", "profile    office (1 overrides)"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in output:\n%s", want, out)
+		}
+	}
+}
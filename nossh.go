@@ -0,0 +1,56 @@
+package main
+
+import (
+	"os/exec"
+)
+
+// --- Missing ssh ---
+
+// Connections, tests, and scans all run the system's ssh, which used to fail
+// only once it was executed. The dashboard now looks for ssh when it starts;
+// without it a banner names the install command and `assho doctor`, the key
+// hints leave out what needs ssh, and trying it anyway reports the same fix.
+// Local hosts and PS remoting hosts do not use ssh and keep working. assho
+// has no built-in SSH client to fall back on, so installing OpenSSH is the
+// way out.
+
+// sshLookPath finds ssh; tests replace it.
+var sshLookPath = exec.LookPath
+
+// sshMissing reports a missing ssh along with how to install it, or nil.
+func sshMissing() error {
+	if _, err := sshLookPath("ssh"); err != nil {
+		return errMissingTool("ssh")
+	}
+	return nil
+}
+
+// usesSSH reports whether reaching trustHost, the host or the parent of a
+// container, goes through ssh.
+func usesSSH(trustHost Host) bool {
+	return !trustHost.isLocal() && trustHost.Transport != transportPSRemoting
+}
+
+// selectedNeedsSSH reports whether the selected row's actions go through
+// ssh. Group rows count, as their test and scan reach every member.
+func (m model) selectedNeedsSSH() bool {
+	h, ok := m.list.SelectedItem().(Host)
+	if !ok {
+		return true
+	}
+	if h.IsContainer && h.ParentID != "" {
+		if idx := findHostIndexByID(m.rawHosts, h.ParentID); idx != -1 {
+			h = m.rawHosts[idx]
+		}
+	}
+	return usesSSH(h)
+}
+
+// renderNoSSHBanner is the dashboard's warning while ssh is missing.
+func (m model) renderNoSSHBanner() string {
+	if m.noSSH == nil {
+		return ""
+	}
+	return "\n " + testFailStyle.Render("✘ "+m.noSSH.Error()) + "\n   " +
+		formHintStyle.Render("Connections, tests, and scans over ssh are off; local and PS remoting hosts still work. Run assho doctor to check the rest.") + "\n"
+}
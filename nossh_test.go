@@ -0,0 +1,52 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestMissingSSHDetectedAtStartup(t *testing.T) {
+	hosts := []Host{{ID: "h1", Alias: "web", Hostname: "10.0.0.1"}}
+	writeTempConfig(t, hosts)
+	old := sshLookPath
+	sshLookPath = func(string) (string, error) { return "", errors.New("not found") }
+	t.Cleanup(func() { sshLookPath = old })
+
+	m := newModel(nil, hosts, nil, nil)
+	m.width, m.height = 120, 40
+	m.list.SetSize(100, 30)
+	if m.noSSH == nil {
+		t.Fatal("expected the missing ssh detected")
+	}
+	out := ansi.Strip(m.View())
+	if !strings.Contains(out, "ssh is not installed") || !strings.Contains(out, "assho doctor") {
+		t.Fatalf("expected a banner with the fix\n%s", out)
+	}
+	if strings.Contains(out, "enter connect") {
+		t.Fatalf("expected connect left out of the key hints\n%s", out)
+	}
+}
+
+func TestConnectWithoutSSHNamesTheFix(t *testing.T) {
+	hosts := []Host{
+		{ID: "h1", Alias: "web", Hostname: "10.0.0.1"},
+		{ID: "h2", Alias: "laptop", Transport: transportLocal, Containers: []Host{
+			{ID: "c1", Alias: "db", IsContainer: true, ParentID: "h2", Docker: &DockerInfo{State: "running"}},
+		}},
+	}
+	m := model{state: stateList, rawHosts: hosts, list: newTestListModel(nil, hosts), noSSH: errMissingTool("ssh")}
+
+	result, _ := m.connectToHost(hosts[0])
+	got := result.(model)
+	if !got.status.isError || !strings.Contains(got.status.message, "ssh is not installed") {
+		t.Fatalf("expected the install hint, got %q", got.status.message)
+	}
+
+	result, cmd := m.connectToHost(hosts[1].Containers[0])
+	if got := result.(model); got.status.message != "" || cmd == nil {
+		t.Fatalf("expected a local container to connect without ssh, got %q", got.status.message)
+	}
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+// --- OS Fingerprinting ---
+
+// With ASSHO_PROBE_OS=1 a successful connection test also runs a tiny probe
+// (uname, /etc/os-release or sw_vers, uptime) and caches what it finds on the
+// host, so the list and detail pane can show which system is on the other end.
+
+// HostOS is the cached result of the last OS probe.
+type HostOS struct {
+	Kernel   string `json:"kernel,omitempty"`  // uname -s, e.g. Linux
+	Release  string `json:"release,omitempty"` // uname -r
+	Arch     string `json:"arch,omitempty"`    // uname -m
+	Name     string `json:"name,omitempty"`    // distribution, e.g. Ubuntu
+	Version  string `json:"version,omitempty"` // e.g. 22.04
+	Uptime   string `json:"uptime,omitempty"`
+	ProbedAt int64  `json:"probed_at,omitempty"`
+}
+
+// probeSeparator splits the sections of the remote probe scripts.
+const probeSeparator = "--assho--"
+
+// osProbeScript prints uname, the release file, and uptime in three sections.
+const osProbeScript = "uname -s; uname -r; uname -m; echo " + probeSeparator +
+	"; cat /etc/os-release 2>/dev/null || sw_vers 2>/dev/null; echo " + probeSeparator + "; uptime"
+
+var uptimePattern = regexp.MustCompile(`\bup\s+(.*?),\s+(?:\d+\s+users?|load average)`)
+
+func probeOSEnabled() bool {
+	return envBool("ASSHO_PROBE_OS", false)
+}
+
+// probeOS runs osProbeScript on h. It returns nil when the probe fails, since
+// a missing fingerprint should never fail the test that triggered it.
+func probeOS(h Host) *HostOS {
+	out, err := runSSHCommand(h, osProbeScript)
+	if err != nil {
+		return nil
+	}
+	info := parseOSProbe(out, time.Now())
+	if info.Kernel == "" {
+		return nil
+	}
+	return &info
+}
+
+func parseOSProbe(out string, now time.Time) HostOS {
+	info := HostOS{ProbedAt: now.Unix()}
+	sections := strings.SplitN(out, probeSeparator, 3)
+	uname := strings.Fields(sections[0])
+	for i, value := range uname {
+		switch i {
+		case 0:
+			info.Kernel = value
+		case 1:
+			info.Release = value
+		case 2:
+			info.Arch = value
+		}
+	}
+	if len(sections) > 1 {
+		for _, line := range strings.Split(sections[1], "\n") {
+			key, value, ok := strings.Cut(strings.TrimSpace(line), "=")
+			if !ok {
+				// sw_vers prints "ProductName:	macOS".
+				key, value, ok = strings.Cut(strings.TrimSpace(line), ":")
+			}
+			if !ok {
+				continue
+			}
+			value = strings.Trim(strings.TrimSpace(value), `"'`)
+			switch strings.TrimSpace(key) {
+			case "NAME", "ProductName":
+				info.Name = value
+			case "VERSION_ID", "ProductVersion":
+				info.Version = value
+			}
+		}
+	}
+	if len(sections) > 2 {
+		line := strings.TrimSpace(sections[2])
+		if match := uptimePattern.FindStringSubmatch(line); match != nil {
+			info.Uptime = strings.Join(strings.Fields(match[1]), " ")
+		} else {
+			info.Uptime = line
+		}
+	}
+	return info
+}
+
+// Icon picks a glyph for the kernel family.
+func (o HostOS) Icon() string {
+	kernel := strings.ToLower(o.Kernel)
+	switch {
+	case kernel == "linux":
+		return "🐧"
+	case kernel == "darwin":
+		return "🍎"
+	case strings.HasSuffix(kernel, "bsd") || kernel == "dragonfly":
+		return "😈"
+	case strings.Contains(kernel, "mingw"), strings.Contains(kernel, "cygwin"), strings.Contains(kernel, "msys"), strings.Contains(kernel, "windows"):
+		return "🪟"
+	case kernel == "":
+		return ""
+	default:
+		return "💻"
+	}
+}
+
+// Label is the short "Ubuntu 22.04 · x86_64" form used in the detail pane.
+func (o HostOS) Label() string {
+	name := strings.TrimSpace(o.Name + " " + o.Version)
+	if name == "" {
+		name = strings.TrimSpace(o.Kernel + " " + o.Release)
+	}
+	if o.Arch != "" {
+		name += " · " + o.Arch
+	}
+	return name
+}
+
+// setHostOS caches info on the host; saving is left to the caller, which
+// records test stats right after.
+func (m *model) setHostOS(hostID string, info *HostOS) {
+	if idx := findHostIndexByID(m.rawHosts, hostID); idx != -1 {
+		m.rawHosts[idx].OS = info
+		m.refreshList()
+	}
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseOSProbe(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	linux := "Linux\n5.15.0-91-generic\nx86_64\n--assho--\nNAME=\"Ubuntu\"\nVERSION_ID=\"22.04\"\nID=ubuntu\n--assho--\n 10:04:01 up 12 days,  3:41,  2 users,  load average: 0.00, 0.01, 0.05\n"
+	got := parseOSProbe(linux, now)
+	want := HostOS{Kernel: "Linux", Release: "5.15.0-91-generic", Arch: "x86_64", Name: "Ubuntu", Version: "22.04", Uptime: "12 days, 3:41", ProbedAt: now.Unix()}
+	if got != want {
+		t.Fatalf("unexpected linux probe\n got %+v\nwant %+v", got, want)
+	}
+	if got.Icon() != "🐧" || got.Label() != "Ubuntu 22.04 · x86_64" {
+		t.Fatalf("unexpected icon/label %q %q", got.Icon(), got.Label())
+	}
+
+	mac := parseOSProbe("Darwin\n23.1.0\narm64\n--assho--\nProductName:\t\tmacOS\nProductVersion:\t\t14.1\n--assho--\n10:04  up 3 mins, 1 user, load averages: 1.2 1.1 1.0\n", now)
+	if mac.Name != "macOS" || mac.Version != "14.1" || mac.Uptime != "3 mins" || mac.Icon() != "🍎" {
+		t.Fatalf("unexpected macOS probe %+v", mac)
+	}
+
+	bsd := parseOSProbe("FreeBSD\n14.0-RELEASE\namd64\n--assho--\n--assho--\n", now)
+	if bsd.Icon() != "😈" || bsd.Label() != "FreeBSD 14.0-RELEASE · amd64" {
+		t.Fatalf("unexpected BSD probe %+v", bsd)
+	}
+}
+
+func TestTestResultCachesOS(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("ASSHO_STORE_PASSWORD", "0")
+	hosts := []Host{{ID: "h1", Alias: "web", Hostname: "10.0.0.1"}}
+	m := model{rawHosts: hosts, list: newTestListModel(nil, hosts), historyList: newTestHistoryListModel()}
+	updated, _ := m.Update(testConnectionMsg{hostID: "h1", latency: time.Millisecond, os: &HostOS{Kernel: "Linux", Name: "Debian"}})
+	m = updated.(model)
+	if m.rawHosts[0].OS == nil || m.rawHosts[0].OS.Name != "Debian" {
+		t.Fatalf("expected OS cached on the host, got %+v", m.rawHosts[0].OS)
+	}
+	if h, ok := m.list.Items()[0].(Host); !ok || h.OS == nil {
+		t.Fatal("expected the list to show the cached OS")
+	}
+	m.detailHostID = "h1"
+	if view := m.renderDetailView(); !strings.Contains(view, "Debian") {
+		t.Fatalf("expected detail pane to show the OS, got:\n%s", view)
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+)
+
+// --- Password Field ---
+
+// Ctrl+R on the form's Password field shows what was typed, to check it
+// before saving; moving to another field, or leaving the form, masks it
+// again. Beneath the field a warning points out a password kept alongside a
+// key file, which is often left over from before the key was installed and
+// still sits in the keychain or hosts.json, a short password, and a password
+// that would be saved in plaintext because ASSHO_SECRET_BACKEND=config.
+
+// minPasswordLength is the length under which the form calls a password
+// short.
+const minPasswordLength = 12
+
+// setPasswordRevealed shows or masks the form's password.
+func (m *model) setPasswordRevealed(revealed bool) {
+	if revealed {
+		m.form.inputs[fieldPassword].EchoMode = textinput.EchoNormal
+	} else {
+		m.form.inputs[fieldPassword].EchoMode = textinput.EchoPassword
+	}
+}
+
+// passwordRevealed reports whether the form shows its password.
+func (m model) passwordRevealed() bool {
+	return m.form.inputs[fieldPassword].EchoMode == textinput.EchoNormal
+}
+
+// passwordWarning is the hint shown beneath the Password field, or "".
+func (m model) passwordWarning() string {
+	password := m.form.inputs[fieldPassword].Value()
+	if password == "" && !m.form.keepPasswordRef {
+		return ""
+	}
+	keyFile := strings.TrimSpace(m.form.inputs[fieldKeyFile].Value())
+	if g, ok := m.formGroup(); ok && keyFile == "" {
+		keyFile = g.DefaultIdentityFile
+	}
+	switch {
+	case keyFile != "":
+		return "a key file is set too, so this password may be stale; clear it if the key logs in"
+	case password == "":
+		return ""
+	case secretBackend() == secretBackendConfig:
+		return "saved in plaintext in hosts.json; unset ASSHO_SECRET_BACKEND to use the keychain"
+	case len([]rune(password)) < minPasswordLength:
+		return fmt.Sprintf("short password (%d characters); a key file is safer", len([]rune(password)))
+	}
+	return ""
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestFormPasswordReveal(t *testing.T) {
+	m := model{state: stateForm, form: newFormState(newFormInputs())}
+	m.form.focus = controlPassword
+	m.form.inputs[fieldPassword].SetValue("hunter2")
+
+	result, _ := m.updateForm(tea.KeyMsg{Type: tea.KeyCtrlR})
+	got := result.(model)
+	if !got.passwordRevealed() || !strings.Contains(got.form.inputs[fieldPassword].View(), "hunter2") {
+		t.Fatal("expected ctrl+r to show the password")
+	}
+	result, _ = got.updateForm(tea.KeyMsg{Type: tea.KeyTab})
+	if got = result.(model); got.passwordRevealed() {
+		t.Fatal("expected leaving the field to mask the password again")
+	}
+
+	got.form.focus = controlAlias
+	result, _ = got.updateForm(tea.KeyMsg{Type: tea.KeyCtrlR})
+	if got = result.(model); got.passwordRevealed() {
+		t.Fatal("expected ctrl+r to act only on the Password field")
+	}
+}
+
+func TestPasswordWarning(t *testing.T) {
+	t.Setenv("ASSHO_SECRET_BACKEND", "")
+	groups := []Group{{ID: "g1", Name: "ci", DefaultIdentityFile: "~/.ssh/id_ci"}}
+	cases := []struct {
+		name, password, keyFile, group string
+		keepRef                        bool
+		want                           string
+	}{
+		{name: "none"},
+		{name: "strong", password: "correct horse battery", want: ""},
+		{name: "short", password: "hunter2", want: "short password (7 characters)"},
+		{name: "key file", password: "correct horse battery", keyFile: "~/.ssh/id_ed25519", want: "may be stale"},
+		{name: "group key", password: "correct horse battery", group: "ci", want: "may be stale"},
+		{name: "stored in keychain", keepRef: true, keyFile: "~/.ssh/id_ed25519", want: "may be stale"},
+	}
+	for _, tc := range cases {
+		m := model{rawGroups: groups, form: newFormState(newFormInputs())}
+		m.form.inputs[fieldPassword].SetValue(tc.password)
+		m.form.inputs[fieldKeyFile].SetValue(tc.keyFile)
+		m.form.inputs[fieldGroup].SetValue(tc.group)
+		m.form.keepPasswordRef = tc.keepRef
+		got, blocking := m.formControlIssue(controlPassword)
+		if blocking || (tc.want == "") != (got == "") || !strings.Contains(got, tc.want) {
+			t.Errorf("%s: got %q, want %q", tc.name, got, tc.want)
+		}
+	}
+
+	t.Setenv("ASSHO_SECRET_BACKEND", "config")
+	m := model{form: newFormState(newFormInputs())}
+	m.form.inputs[fieldPassword].SetValue("correct horse battery")
+	if got := m.passwordWarning(); !strings.Contains(got, "plaintext") {
+		t.Fatalf("expected the plaintext backend called out, got %q", got)
+	}
+}
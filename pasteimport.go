@@ -0,0 +1,79 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/atotto/clipboard"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// --- Paste Import ---
+
+// V on the dashboard reads ssh one-liners from the clipboard, and pasting
+// into the dashboard does the same with the pasted text. Every line such as
+// `ssh -i key -p 2222 admin@1.2.3.4` becomes a proposed host with its user,
+// port, key file, and jump host, on the same review screen as the shell
+// history import. Runbook framing is tolerated: shell prompts, backticks,
+// and backslash line continuations.
+
+// readClipboard returns the clipboard text; tests replace it.
+var readClipboard = clipboard.ReadAll
+
+// pastedCommands splits pasted text into commands, joining continued lines
+// and dropping prompts and backticks.
+func pastedCommands(text string) []string {
+	var commands []string
+	var pending string
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		line = strings.TrimSpace(line)
+		if continued, ok := strings.CutSuffix(line, "\\"); ok {
+			pending += continued + " "
+			continue
+		}
+		line = strings.TrimSpace(pending + line)
+		pending = ""
+		for _, prompt := range []string{"$ ", "% ", "> "} {
+			line = strings.TrimPrefix(line, prompt)
+		}
+		line = strings.Trim(line, "`")
+		if line != "" {
+			commands = append(commands, line)
+		}
+	}
+	if pending != "" {
+		commands = append(commands, strings.TrimSpace(pending))
+	}
+	return commands
+}
+
+// pasteCandidates proposes the hosts in pasted text, in paste order.
+func pasteCandidates(hosts []Host, text string) []historyCandidate {
+	candidates := sshCandidates(hosts, pastedCommands(text))
+	assignCandidateAliases(hosts, candidates)
+	return candidates
+}
+
+func (m model) openClipboardImport() (tea.Model, tea.Cmd) {
+	text, err := readClipboard()
+	if err != nil {
+		m.status.message = "Cannot read the clipboard: " + err.Error()
+		m.status.isError = true
+		m.status.version++
+		return m, statusClearCmd(m.status.version)
+	}
+	return m.openPasteImport(text)
+}
+
+func (m model) openPasteImport(text string) (tea.Model, tea.Cmd) {
+	m.clearListDeleteConfirm()
+	candidates := pasteCandidates(m.rawHosts, text)
+	if len(candidates) == 0 {
+		m.status.message = "No new ssh commands in the pasted text"
+		m.status.isError = false
+		m.status.version++
+		return m, statusClearCmd(m.status.version)
+	}
+	m.historyImport = historyImportState{candidates: candidates, source: importFromPaste}
+	m.state = stateHistoryImport
+	return m, nil
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestPasteCandidates(t *testing.T) {
+	saved := []Host{{ID: "h1", Alias: "web", Hostname: "web.example.com", User: "deploy"}}
+	text := "Log in with:\r\n" +
+		"$ ssh -i ~/.ssh/ops -p 2222 admin@1.2.3.4\r\n" +
+		"`ssh deploy@web.example.com`\n" +
+		"ssh -J bastion \\\n" +
+		"    root@db.internal\n" +
+		"ssh -i ~/.ssh/ops -p 2222 admin@1.2.3.4 uptime\n"
+	got := pasteCandidates(saved, text)
+	if len(got) != 2 {
+		t.Fatalf("expected two proposals, got %+v", got)
+	}
+	first := got[0].host
+	if first.Hostname != "1.2.3.4" || first.User != "admin" || first.Port != "2222" || first.IdentityFile != "~/.ssh/ops" {
+		t.Fatalf("expected flags parsed into fields, got %+v", first)
+	}
+	if got[0].uses != 2 {
+		t.Fatalf("expected the repeat counted, got %d", got[0].uses)
+	}
+	if second := got[1].host; second.Alias != "db" || second.ProxyJump != "bastion" || second.User != "root" {
+		t.Fatalf("expected the continued line joined, got %+v", second)
+	}
+}
+
+func TestPasteIntoDashboardOpensImport(t *testing.T) {
+	writeTempConfig(t, nil)
+	m := model{state: stateList, list: newTestListModel(nil, nil), historyList: newTestHistoryListModel()}
+	paste := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("ssh -p 2222 admin@1.2.3.4"), Paste: true}
+	result, _ := m.updateList(paste)
+	got := result.(model)
+	if got.state != stateHistoryImport || got.historyImport.source != importFromPaste {
+		t.Fatalf("expected the paste review screen, got state %v", got.state)
+	}
+	if view := got.renderHistoryImportView(); !strings.Contains(view, "IMPORT PASTED SSH COMMANDS") || strings.Contains(view, "1 use") {
+		t.Fatalf("unexpected review screen\n%s", view)
+	}
+	result, _ = got.updateHistoryImport(tea.KeyMsg{Type: tea.KeyEnter})
+	got = result.(model)
+	if len(got.rawHosts) != 1 || got.rawHosts[0].Port != "2222" || got.status.message != "Added 1 hosts from the pasted commands" {
+		t.Fatalf("expected the host added, got %+v %q", got.rawHosts, got.status.message)
+	}
+}
+
+func TestClipboardImportWithoutCommands(t *testing.T) {
+	old := readClipboard
+	readClipboard = func() (string, error) { return "hello", nil }
+	t.Cleanup(func() { readClipboard = old })
+
+	m := model{state: stateList, list: newTestListModel(nil, nil), historyList: newTestHistoryListModel()}
+	result, _ := m.updateList(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("V")})
+	got := result.(model)
+	if got.state != stateList || !strings.Contains(got.status.message, "No new ssh commands") {
+		t.Fatalf("expected a status and no review screen, got %v %q", got.state, got.status.message)
+	}
+}
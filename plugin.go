@@ -0,0 +1,361 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// --- Plugins ---
+
+// A plugin is an executable named assho-plugin-<name>, found in
+// ~/.config/assho/plugins or on PATH. assho runs it once per request, writes
+// one JSON request to its stdin, and reads one JSON response from its stdout.
+// Plugins declare what they provide in reply to "describe":
+//
+//	discover  list hosts from an inventory (Proxmox, vSphere, Netbox, ...)
+//	import    convert the files or arguments given after the plugin name
+//	secrets   store passwords; selected with ASSHO_SECRET_BACKEND=plugin:<name>
+//
+// Anything the plugin prints to stderr is shown when it fails.
+
+const (
+	pluginPrefix          = "assho-plugin-"
+	pluginProtocolVersion = 1
+	pluginTimeout         = 30 * time.Second
+)
+
+const (
+	pluginDiscover = "discover"
+	pluginImport   = "import"
+)
+
+type pluginRequest struct {
+	Protocol int      `json:"protocol"`
+	Action   string   `json:"action"` // describe, discover, import, get_secret, set_secret, delete_secret
+	Args     []string `json:"args,omitempty"`
+	Ref      string   `json:"ref,omitempty"`
+	Secret   string   `json:"secret,omitempty"`
+}
+
+type pluginResponse struct {
+	Name         string       `json:"name,omitempty"`
+	Description  string       `json:"description,omitempty"`
+	Capabilities []string     `json:"capabilities,omitempty"`
+	Hosts        []pluginHost `json:"hosts,omitempty"`
+	Secret       string       `json:"secret,omitempty"`
+	Error        string       `json:"error,omitempty"`
+}
+
+// pluginHost is the subset of Host a plugin can describe.
+type pluginHost struct {
+	Alias        string `json:"alias"`
+	Hostname     string `json:"hostname"`
+	User         string `json:"user,omitempty"`
+	Port         string `json:"port,omitempty"`
+	IdentityFile string `json:"identity_file,omitempty"`
+	ProxyJump    string `json:"proxy_jump,omitempty"`
+	Group        string `json:"group,omitempty"`
+	Notes        string `json:"notes,omitempty"`
+}
+
+type pluginInfo struct {
+	name string
+	path string
+}
+
+func getPluginDir() string {
+	return filepath.Join(filepath.Dir(getConfigPath()), "plugins")
+}
+
+// pluginName is the plugin name a file in a plugin directory carries, if it
+// is one. Windows has no execute bit, so there a plugin is a file ending in
+// one of PATHEXT's extensions, and the extension is not part of its name.
+func pluginName(file, goos, pathext string) (string, bool) {
+	name, ok := strings.CutPrefix(file, pluginPrefix)
+	if !ok {
+		return "", false
+	}
+	if goos == "windows" {
+		if pathext == "" {
+			pathext = ".com;.exe;.bat;.cmd"
+		}
+		ok = false
+		for _, ext := range strings.Split(pathext, ";") {
+			if ext != "" && len(name) > len(ext) && strings.EqualFold(name[len(name)-len(ext):], ext) {
+				name, ok = name[:len(name)-len(ext)], true
+				break
+			}
+		}
+	}
+	if !ok || name == "" {
+		return "", false
+	}
+	return name, true
+}
+
+// findPlugins lists installed plugins by name. The plugin directory wins over
+// PATH, and executables other users can modify are ignored.
+func findPlugins() []pluginInfo {
+	byName := map[string]pluginInfo{}
+	dirs := append([]string{getPluginDir()}, filepath.SplitList(os.Getenv("PATH"))...)
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			name, ok := pluginName(entry.Name(), runtime.GOOS, os.Getenv("PATHEXT"))
+			if !ok {
+				continue
+			}
+			if _, seen := byName[name]; seen {
+				continue
+			}
+			path := filepath.Join(dir, entry.Name())
+			info, err := os.Stat(path)
+			if err != nil || !info.Mode().IsRegular() {
+				continue
+			}
+			if runtime.GOOS != "windows" && (info.Mode().Perm()&0o111 == 0 || info.Mode().Perm()&0o022 != 0) {
+				continue
+			}
+			byName[name] = pluginInfo{name: name, path: path}
+		}
+	}
+	plugins := make([]pluginInfo, 0, len(byName))
+	for _, p := range byName {
+		plugins = append(plugins, p)
+	}
+	sort.Slice(plugins, func(i, j int) bool { return plugins[i].name < plugins[j].name })
+	return plugins
+}
+
+func findPlugin(name string) (pluginInfo, error) {
+	for _, p := range findPlugins() {
+		if p.name == name {
+			return p, nil
+		}
+	}
+	return pluginInfo{}, fmt.Errorf("plugin not found: %s (looked for %s%s in %s and PATH)", name, pluginPrefix, name, getPluginDir())
+}
+
+// callPlugin runs one request. A non-zero exit or an "error" in the response
+// fails the call.
+func callPlugin(p pluginInfo, req pluginRequest) (pluginResponse, error) {
+	req.Protocol = pluginProtocolVersion
+	input, err := json.Marshal(req)
+	if err != nil {
+		return pluginResponse{}, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), pluginTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, p.path)
+	cmd.Stdin = bytes.NewReader(input)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return pluginResponse{}, fmt.Errorf("plugin %s timed out after %s", p.name, pluginTimeout)
+		}
+		if detail := strings.TrimSpace(stderr.String()); detail != "" {
+			return pluginResponse{}, fmt.Errorf("plugin %s failed: %v (%s)", p.name, err, detail)
+		}
+		return pluginResponse{}, fmt.Errorf("plugin %s failed: %v", p.name, err)
+	}
+	var resp pluginResponse
+	if err := json.Unmarshal(stdout.Bytes(), &resp); err != nil {
+		return pluginResponse{}, fmt.Errorf("plugin %s returned invalid JSON: %v", p.name, err)
+	}
+	if resp.Error != "" {
+		return resp, fmt.Errorf("plugin %s: %s", p.name, resp.Error)
+	}
+	return resp, nil
+}
+
+func (r pluginResponse) supports(capability string) bool {
+	for _, c := range r.Capabilities {
+		if c == capability {
+			return true
+		}
+	}
+	return false
+}
+
+// pluginHosts turns plugin output into hosts, creating any named groups that
+// do not exist yet. Entries without an alias or with an unsafe field are
+// skipped and reported.
+func pluginHosts(groups []Group, found []pluginHost) ([]Group, []Host, []string) {
+	var hosts []Host
+	var problems []string
+	for _, ph := range found {
+		h := Host{
+			ID:           newHostID(),
+			Alias:        strings.TrimSpace(ph.Alias),
+			Hostname:     strings.TrimSpace(ph.Hostname),
+			User:         strings.TrimSpace(ph.User),
+			Port:         strings.TrimSpace(ph.Port),
+			IdentityFile: strings.TrimSpace(ph.IdentityFile),
+			ProxyJump:    strings.TrimSpace(ph.ProxyJump),
+			Notes:        strings.TrimSpace(ph.Notes),
+		}
+		if h.Alias == "" {
+			problems = append(problems, fmt.Sprintf("entry for %q has no alias", h.Hostname))
+			continue
+		}
+		if err := validatePluginHost(h); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", h.Alias, err))
+			continue
+		}
+		if name := strings.TrimSpace(ph.Group); name != "" {
+			idx := findGroupByName(groups, name)
+			if idx == -1 {
+				groups = append(groups, Group{ID: newGroupID(), Name: name, Expanded: true})
+				idx = len(groups) - 1
+			}
+			if !groups[idx].Smart() {
+				h.GroupID = groups[idx].ID
+			}
+		}
+		hosts = append(hosts, h)
+	}
+	return groups, hosts, problems
+}
+
+func validatePluginHost(h Host) error {
+	if err := validateHostname(h.Hostname); err != nil {
+		return err
+	}
+	if h.Port != "" {
+		if n, err := strconv.Atoi(h.Port); err != nil || n < 1 || n > 65535 {
+			return fmt.Errorf("port must be a number between 1 and 65535")
+		}
+	}
+	for _, field := range [][2]string{{"user", h.User}, {"proxyjump", h.ProxyJump}, {"identity file", h.IdentityFile}} {
+		if err := checkArgValue(field[0], field[1]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// secretPlugin returns the plugin named by ASSHO_SECRET_BACKEND=plugin:<name>.
+func secretPlugin() (pluginInfo, bool, error) {
+	value := strings.TrimSpace(os.Getenv("ASSHO_SECRET_BACKEND"))
+	name, ok := strings.CutPrefix(strings.ToLower(value), "plugin:")
+	if !ok {
+		return pluginInfo{}, false, nil
+	}
+	p, err := findPlugin(name)
+	return p, true, err
+}
+
+func pluginSecretCall(req pluginRequest) (pluginResponse, bool, error) {
+	p, ok, err := secretPlugin()
+	if !ok || err != nil {
+		return pluginResponse{}, ok, err
+	}
+	resp, err := callPlugin(p, req)
+	return resp, true, err
+}
+
+func fprintPlugins(w io.Writer, plugins []pluginInfo, describe func(pluginInfo) (pluginResponse, error)) {
+	if len(plugins) == 0 {
+		fmt.Fprintf(w, "No plugins installed. Put %s<name> executables in %s or on PATH.\n", pluginPrefix, getPluginDir())
+		return
+	}
+	for _, p := range plugins {
+		resp, err := describe(p)
+		if err != nil {
+			fmt.Fprintf(w, "%-16s ✘ %v\n", p.name, err)
+			continue
+		}
+		line := fmt.Sprintf("%-16s %s", p.name, strings.Join(resp.Capabilities, ", "))
+		if resp.Description != "" {
+			line += " — " + resp.Description
+		}
+		fmt.Fprintln(w, line)
+	}
+}
+
+func cliPlugins(args []string) {
+	const usage = "usage: assho plugins [list | discover <name> | import <name> [args...]]"
+	if len(args) == 0 || args[0] == "list" {
+		fprintPlugins(os.Stdout, findPlugins(), func(p pluginInfo) (pluginResponse, error) {
+			return callPlugin(p, pluginRequest{Action: "describe"})
+		})
+		return
+	}
+	if len(args) < 2 || (args[0] != pluginDiscover && args[0] != pluginImport) {
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(1)
+	}
+	action, name := args[0], args[1]
+	p, err := findPlugin(name)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if desc, err := callPlugin(p, pluginRequest{Action: "describe"}); err != nil || !desc.supports(action) {
+		if err == nil {
+			err = fmt.Errorf("plugin %s does not support %s", name, action)
+		}
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	resp, err := callPlugin(p, pluginRequest{Action: action, Args: args[2:]})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	groups, hosts, history, err := loadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error loading config: %v\n", err)
+		os.Exit(1)
+	}
+	known := len(groups)
+	groups, found, problems := pluginHosts(groups, resp.Hosts)
+	for _, problem := range problems {
+		fmt.Fprintf(os.Stderr, "⚠ skipped %s\n", problem)
+	}
+	imported, changed, skipped := mergeImportedHosts(hosts, found)
+	if len(imported) == 0 {
+		fmt.Printf("Nothing new from %s (%d already present)\n", name, skipped+len(changed))
+		return
+	}
+	if err := saveConfig(pruneUnusedGroups(groups, known, imported), append(hosts, imported...), history); err != nil {
+		fmt.Fprintf(os.Stderr, "error saving config: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("✔ Imported %d hosts from %s (%d already present)\n", len(imported), name, skipped+len(changed))
+	if len(changed) > 0 {
+		fmt.Printf("  %d existing aliases differ and were left unchanged\n", len(changed))
+	}
+}
+
+// pruneUnusedGroups drops the groups pluginHosts appended after the first
+// known ones when none of their hosts were imported after all.
+func pruneUnusedGroups(groups []Group, known int, imported []Host) []Group {
+	used := map[string]bool{}
+	for _, h := range imported {
+		used[h.GroupID] = true
+	}
+	kept := groups[:known:known]
+	for _, g := range groups[known:] {
+		if used[g.ID] {
+			kept = append(kept, g)
+		}
+	}
+	return kept
+}
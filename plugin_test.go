@@ -0,0 +1,152 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// installTestPlugin writes a shell plugin into the plugin directory of a
+// fresh HOME.
+func installTestPlugin(t *testing.T, name, script string) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("PATH", "/usr/bin:/bin")
+	dir := filepath.Join(home, ".config", "assho", "plugins")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, pluginPrefix+name)
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestCallPluginDiscover(t *testing.T) {
+	installTestPlugin(t, "inventory", `read -r req
+case "$req" in
+*'"action":"describe"'*) echo '{"name":"inventory","capabilities":["discover"]}' ;;
+*'"action":"discover"'*) echo '{"hosts":[{"alias":"pve-01","hostname":"10.0.0.5","user":"root","group":"proxmox"}]}' ;;
+*) echo '{"error":"unsupported"}' ;;
+esac
+`)
+	p, err := findPlugin("inventory")
+	if err != nil {
+		t.Fatal(err)
+	}
+	desc, err := callPlugin(p, pluginRequest{Action: "describe"})
+	if err != nil || !desc.supports(pluginDiscover) || desc.supports(pluginImport) {
+		t.Fatalf("unexpected describe response %+v (%v)", desc, err)
+	}
+	resp, err := callPlugin(p, pluginRequest{Action: pluginDiscover})
+	if err != nil || len(resp.Hosts) != 1 || resp.Hosts[0].Alias != "pve-01" {
+		t.Fatalf("unexpected discover response %+v (%v)", resp, err)
+	}
+	if _, err := callPlugin(p, pluginRequest{Action: "get_secret"}); err == nil || !strings.Contains(err.Error(), "unsupported") {
+		t.Fatalf("expected the plugin's error reported, got %v", err)
+	}
+}
+
+func TestCallPluginReportsStderr(t *testing.T) {
+	installTestPlugin(t, "broken", "echo 'token expired' >&2\nexit 3\n")
+	p, err := findPlugin("broken")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := callPlugin(p, pluginRequest{Action: "describe"}); err == nil || !strings.Contains(err.Error(), "token expired") {
+		t.Fatalf("expected stderr in the error, got %v", err)
+	}
+}
+
+func TestFindPluginsSkipsWritableByOthers(t *testing.T) {
+	path := installTestPlugin(t, "shared", "exit 0\n")
+	if err := os.Chmod(path, 0o777); err != nil {
+		t.Fatal(err)
+	}
+	if plugins := findPlugins(); len(plugins) != 0 {
+		t.Fatalf("expected a world-writable plugin ignored, got %+v", plugins)
+	}
+}
+
+func TestPluginName(t *testing.T) {
+	cases := []struct {
+		file, goos, pathext string
+		name                string
+		ok                  bool
+	}{
+		{"assho-plugin-proxmox", "linux", "", "proxmox", true},
+		{"assho-plugin-proxmox.exe", "linux", "", "proxmox.exe", true},
+		{"assho-plugin-proxmox.exe", "windows", "", "proxmox", true},
+		{"assho-plugin-proxmox.CMD", "windows", ".COM;.EXE;.BAT;.CMD", "proxmox", true},
+		{"assho-plugin-proxmox", "windows", "", "", false},
+		{"assho-plugin-.exe", "windows", "", "", false},
+		{"assho-plugin-", "linux", "", "", false},
+		{"proxmox.exe", "windows", "", "", false},
+	}
+	for _, c := range cases {
+		name, ok := pluginName(c.file, c.goos, c.pathext)
+		if name != c.name || ok != c.ok {
+			t.Errorf("pluginName(%q, %q) = %q, %v; want %q, %v", c.file, c.goos, name, ok, c.name, c.ok)
+		}
+	}
+}
+
+func TestPluginHosts(t *testing.T) {
+	groups := []Group{{ID: "g1", Name: "Proxmox"}}
+	groups, hosts, problems := pluginHosts(groups, []pluginHost{
+		{Alias: "pve-01", Hostname: "10.0.0.5", Group: "proxmox"},
+		{Alias: "vm-01", Hostname: "10.0.1.5", Port: "2222", Group: "vms"},
+		{Alias: "bad", Hostname: "10.0.0.6; rm -rf /"},
+		{Hostname: "10.0.0.7"},
+	})
+	if len(hosts) != 2 || hosts[0].GroupID != "g1" || hosts[1].Port != "2222" {
+		t.Fatalf("unexpected hosts %+v", hosts)
+	}
+	if len(groups) != 2 || groups[1].Name != "vms" || hosts[1].GroupID != groups[1].ID {
+		t.Fatalf("expected the vms group created, got %+v", groups)
+	}
+	if len(problems) != 2 {
+		t.Fatalf("expected two skipped entries, got %v", problems)
+	}
+	if kept := pruneUnusedGroups(groups, 1, hosts[:1]); len(kept) != 1 {
+		t.Fatalf("expected the unused new group dropped, got %+v", kept)
+	}
+}
+
+func TestSecretPluginBackend(t *testing.T) {
+	installTestPlugin(t, "vault", `read -r req
+case "$req" in
+*'"action":"set_secret"'*) echo "$req" > "$HOME/stored"; echo '{}' ;;
+*'"action":"get_secret"'*) echo '{"secret":"s3cret"}' ;;
+*) echo '{}' ;;
+esac
+`)
+	t.Setenv("ASSHO_SECRET_BACKEND", "plugin:vault")
+	if err := storePasswordSecret("h1", "s3cret"); err != nil {
+		t.Fatal(err)
+	}
+	stored, err := os.ReadFile(filepath.Join(os.Getenv("HOME"), "stored"))
+	if err != nil || !strings.Contains(string(stored), `"ref":"h1"`) {
+		t.Fatalf("expected the set request passed to the plugin, got %q (%v)", stored, err)
+	}
+	if secret, err := lookupPasswordSecret("h1"); err != nil || secret != "s3cret" {
+		t.Fatalf("expected the secret read through the plugin, got %q (%v)", secret, err)
+	}
+	t.Setenv("ASSHO_SECRET_BACKEND", "plugin:missing")
+	if _, err := lookupPasswordSecret("h1"); err == nil || !strings.Contains(err.Error(), "plugin not found") {
+		t.Fatalf("expected a missing plugin error, got %v", err)
+	}
+}
+
+func TestFprintPluginsEmpty(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	var buf bytes.Buffer
+	fprintPlugins(&buf, nil, nil)
+	if !strings.Contains(buf.String(), "No plugins installed") {
+		t.Fatalf("unexpected output %q", buf.String())
+	}
+}
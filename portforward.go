@@ -0,0 +1,278 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// --- Container Port Forwarding ---
+
+// F on a Docker container lists the ports the last scan saw and forwards the
+// chosen one to this machine through the container's host. A published port
+// is forwarded to the host's own listener; an exposed-only port to the
+// container's bridge address, which is read with docker inspect first. The
+// tunnel backgrounds itself like a web UI bookmark's and outlives assho.
+
+// containerPort is one TCP port from DockerInfo.Ports.
+type containerPort struct {
+	port      string // inside the container
+	published string // on the host, empty when only exposed
+	hostIP    string // the host address it is published on, if not a wildcard
+}
+
+func (p containerPort) String() string {
+	if p.published == "" {
+		return p.port + "/tcp · exposed only"
+	}
+	return p.port + "/tcp · published on " + hostPort(p.hostIP, p.published)
+}
+
+type portForwardState struct {
+	host   Host // the container
+	ports  []containerPort
+	cursor int
+}
+
+type portForwardMsg struct {
+	alias  string
+	parent string
+	local  string
+	port   string
+	err    error
+}
+
+// parseContainerPort reads one DockerInfo port such as "8080→80/tcp",
+// "127.0.0.1:9000→9000/tcp", or "443/tcp". Port ranges and UDP are skipped;
+// ssh -L forwards single TCP ports.
+func parseContainerPort(s string) (containerPort, bool) {
+	published, private, mapped := strings.Cut(s, "→")
+	if !mapped {
+		private, published = published, ""
+	}
+	port, proto, _ := strings.Cut(private, "/")
+	if proto != "tcp" || !isPortNumber(port) {
+		return containerPort{}, false
+	}
+	p := containerPort{port: port}
+	if published != "" {
+		p.published = published
+		if i := strings.LastIndex(published, ":"); i >= 0 {
+			p.hostIP, p.published = strings.Trim(published[:i], "[]"), published[i+1:]
+		}
+		if !isPortNumber(p.published) {
+			return containerPort{}, false
+		}
+	}
+	return p, true
+}
+
+func isPortNumber(s string) bool {
+	n, err := strconv.Atoi(s)
+	return err == nil && n > 0 && n < 65536
+}
+
+func containerPorts(h Host) []containerPort {
+	if h.Docker == nil {
+		return nil
+	}
+	var ports []containerPort
+	for _, raw := range h.Docker.Ports {
+		if p, ok := parseContainerPort(raw); ok {
+			ports = append(ports, p)
+		}
+	}
+	return ports
+}
+
+// forwardTarget is the host:port the parent connects to for p; containerIP
+// is only needed when p is not published.
+func forwardTarget(p containerPort, containerIP string) string {
+	if p.published == "" {
+		return hostPort(containerIP, p.port)
+	}
+	ip := p.hostIP
+	if ip == "" {
+		ip = "localhost"
+	}
+	return hostPort(ip, p.published)
+}
+
+// localForwardFor picks the local listening port: the published (or
+// container) port when it is free here, otherwise any free port.
+func localForwardFor(p containerPort) (string, error) {
+	preferred := p.published
+	if preferred == "" {
+		preferred = p.port
+	}
+	if l, err := net.Listen("tcp", net.JoinHostPort("127.0.0.1", preferred)); err == nil {
+		l.Close()
+		return preferred, nil
+	}
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", err
+	}
+	defer l.Close()
+	return strconv.Itoa(l.Addr().(*net.TCPAddr).Port), nil
+}
+
+// containerAddress asks the parent for the container's first network
+// address.
+func containerAddress(parent Host, name string) (string, error) {
+	out, err := runSSHCommand(parent, "docker inspect -f '{{range .NetworkSettings.Networks}}{{.IPAddress}} {{end}}' "+shellQuote(name))
+	if err != nil {
+		return "", err
+	}
+	fields := strings.Fields(out)
+	if len(fields) == 0 {
+		return "", fmt.Errorf("%s has no network address; publish the port instead", name)
+	}
+	return fields[0], nil
+}
+
+func startPortForwardTrusted(parent, container Host, p containerPort) tea.Cmd {
+	return func() tea.Msg {
+		msg := portForwardMsg{alias: container.Alias, parent: parent.Alias, port: p.port}
+		containerIP := ""
+		if p.published == "" {
+			ip, err := containerAddress(parent, container.Hostname)
+			if err != nil {
+				msg.err = err
+				return msg
+			}
+			containerIP = ip
+		}
+		local, err := localForwardFor(p)
+		if err != nil {
+			msg.err = err
+			return msg
+		}
+		msg.local = local
+		tunnel := withPassword(parent)
+		tunnel.LocalForward = local + ":" + forwardTarget(p, containerIP)
+		binary, args, extraEnv, _ := buildSSHCommand(tunnel.Password, buildTunnelArgs(tunnel))
+		cmd := exec.Command(binary, args...)
+		cmd.Env = append(cmd.Environ(), extraEnv...)
+		output, err := cmd.CombinedOutput()
+		recordAudit("tunnel", container.Alias, parent, err)
+		if err != nil {
+			if out := strings.TrimSpace(string(output)); out != "" {
+				err = errors.New(out)
+			}
+			msg.err = fmt.Errorf("tunnel failed: %w", err)
+		}
+		return msg
+	}
+}
+
+func (m model) openPortForward() (tea.Model, tea.Cmd) {
+	h, ok := m.list.SelectedItem().(Host)
+	if !ok || !h.IsContainer {
+		return m, nil
+	}
+	m.clearListDeleteConfirm()
+	fail := func(message string) (tea.Model, tea.Cmd) {
+		m.status.message = message
+		m.status.isError = true
+		m.status.version++
+		return m, statusClearCmd(m.status.version)
+	}
+	if h.Kind != "" {
+		return fail("Port forwarding works on Docker containers")
+	}
+	parentIndex := findHostIndexByID(m.rawHosts, h.ParentID)
+	if parentIndex < 0 {
+		return fail("Host for " + h.Alias + " not found")
+	}
+	if m.rawHosts[parentIndex].isLocal() {
+		return fail(h.Alias + " runs on this machine; its published ports are already on localhost")
+	}
+	ports := containerPorts(h)
+	if len(ports) == 0 {
+		return fail("No TCP ports known for " + h.Alias + " · ctrl+d rescans its host")
+	}
+	if !h.Docker.running() {
+		return fail(fmt.Sprintf("%s is %s; start it before forwarding", h.Alias, h.Docker.State))
+	}
+	m.portForward = portForwardState{host: h, ports: ports}
+	m.state = statePortForward
+	return m, nil
+}
+
+func (m model) startPortForward(p containerPort) (tea.Model, tea.Cmd) {
+	h := m.portForward.host
+	parentIndex := findHostIndexByID(m.rawHosts, h.ParentID)
+	if parentIndex < 0 {
+		m.status.message = "Host for " + h.Alias + " not found"
+		m.status.isError = true
+		m.status.version++
+		return m, statusClearCmd(m.status.version)
+	}
+	parent := m.rawHosts[parentIndex]
+	m.status.message = fmt.Sprintf("Forwarding %s %s/tcp through %s…", h.Alias, p.port, parent.Alias)
+	m.status.isError = false
+	m.status.version++
+	return m, checkHostTrustCmd(pendingSSHAction{kind: sshActionPortForward, host: h, trustHost: parent, inv: m.inventory(), forward: p})
+}
+
+func (m model) finishPortForward(msg portForwardMsg) (tea.Model, tea.Cmd) {
+	if msg.err != nil {
+		m.status.message = "Port forward failed: " + msg.err.Error()
+		m.status.isError = true
+	} else {
+		m.status.message = fmt.Sprintf("localhost:%s → %s:%s/tcp via %s (tunnel runs in the background)", msg.local, msg.alias, msg.port, msg.parent)
+		m.status.isError = false
+	}
+	m.status.version++
+	return m, statusClearCmd(m.status.version)
+}
+
+func (m model) updatePortForward(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		m.quitting = true
+		return m, tea.Quit
+	case "esc", "q", "F":
+		m.state = stateList
+	case "up", "k":
+		if m.portForward.cursor > 0 {
+			m.portForward.cursor--
+		}
+	case "down", "j":
+		if m.portForward.cursor < len(m.portForward.ports)-1 {
+			m.portForward.cursor++
+		}
+	case "enter":
+		if m.portForward.cursor < len(m.portForward.ports) {
+			m.state = stateList
+			return m.startPortForward(m.portForward.ports[m.portForward.cursor])
+		}
+	}
+	return m, nil
+}
+
+func (m model) renderPortForwardView() string {
+	width, height := normalizedSize(m.width, m.height)
+	inner := max(min(width-6, 100)-6, 24)
+	s := m.portForward
+	parent := "its host"
+	if idx := findHostIndexByID(m.rawHosts, s.host.ParentID); idx >= 0 {
+		parent = m.rawHosts[idx].Alias
+	}
+	var b strings.Builder
+	b.WriteString(lipgloss.NewStyle().Bold(true).Foreground(colorText).Render(ansi.Truncate("FORWARD PORT · "+s.host.Alias, inner, "…")) + "\n")
+	b.WriteString(formHintStyle.Render(ansi.Truncate("Forward locally with ssh -L through "+parent, inner, "…")) + "\n\n")
+	for i, p := range s.ports {
+		b.WriteString(selectionLine(i == s.cursor, ansi.Truncate(p.String(), inner-2, "…")) + "\n")
+	}
+	b.WriteString("\n" + helpEntry("enter", "forward") + "  " + helpEntry("esc", "back"))
+	return centeredWorkspace(b.String(), width, height)
+}
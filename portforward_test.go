@@ -0,0 +1,66 @@
+package main
+
+import (
+	"slices"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestParseContainerPort(t *testing.T) {
+	for raw, want := range map[string]containerPort{
+		"8080→80/tcp":             {port: "80", published: "8080"},
+		"127.0.0.1:9000→9000/tcp": {port: "9000", published: "9000", hostIP: "127.0.0.1"},
+		"[::1]:5433→5432/tcp":     {port: "5432", published: "5433", hostIP: "::1"},
+		"443/tcp":                 {port: "443"},
+	} {
+		got, ok := parseContainerPort(raw)
+		if !ok || got != want {
+			t.Errorf("parseContainerPort(%q) = %+v, %t; want %+v", raw, got, ok, want)
+		}
+	}
+	for _, raw := range []string{"53/udp", "8000-8001→8000-8001/tcp", "garbage"} {
+		if _, ok := parseContainerPort(raw); ok {
+			t.Errorf("parseContainerPort(%q) should be skipped", raw)
+		}
+	}
+}
+
+func TestForwardTarget(t *testing.T) {
+	if got := forwardTarget(containerPort{port: "80", published: "8080"}, ""); got != "localhost:8080" {
+		t.Fatalf("published port should go to the host's listener, got %q", got)
+	}
+	if got := forwardTarget(containerPort{port: "5432", published: "5433", hostIP: "::1"}, ""); got != "[::1]:5433" {
+		t.Fatalf("got %q", got)
+	}
+	if got := forwardTarget(containerPort{port: "6379"}, "172.17.0.3"); got != "172.17.0.3:6379" {
+		t.Fatalf("exposed port should go to the container address, got %q", got)
+	}
+}
+
+func TestOpenPortForwardListsTCPPorts(t *testing.T) {
+	parent := Host{ID: "p1", Alias: "docker01", Hostname: "10.0.0.5", Expanded: true, Containers: []Host{{
+		ID: "c1", Alias: "web", Hostname: "web", IsContainer: true, ParentID: "p1",
+		Docker: &DockerInfo{State: "running", Ports: []string{"443/tcp", "53/udp", "8080→80/tcp"}},
+	}}}
+	m := model{rawHosts: []Host{parent}}
+	m.list = newTestListModel(nil, m.rawHosts)
+	m.list.Select(1)
+
+	updated, _ := m.updateList(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("F")})
+	m = updated.(model)
+	want := []containerPort{{port: "443"}, {port: "80", published: "8080"}}
+	if m.state != statePortForward || !slices.Equal(m.portForward.ports, want) {
+		t.Fatalf("expected the port picker with TCP ports, got state %d ports %+v", m.state, m.portForward.ports)
+	}
+
+	m.state = stateList
+	m.rawHosts[0].Containers[0].Docker.State = "exited"
+	m.list = newTestListModel(nil, m.rawHosts)
+	m.list.Select(1)
+	updated, _ = m.updateList(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("F")})
+	m = updated.(model)
+	if m.state != stateList || !m.status.isError {
+		t.Fatalf("expected an error for a stopped container, got state %d status %+v", m.state, m.status)
+	}
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// --- Startup Profile ---
+
+// `assho --profile-startup` goes through what launching the TUI does, without
+// taking over the terminal, and prints how long each step took: reading and
+// parsing hosts.json, building the dashboard, the keychain prefetch that
+// normally runs in the background, and the first render. Steps slower than
+// their threshold are flagged with what usually causes it. Nothing leaves the
+// machine; the report is for pasting into an issue.
+
+const (
+	profileWidth  = 120
+	profileHeight = 40
+)
+
+type profilePhase struct {
+	name   string
+	took   time.Duration
+	detail string
+	slow   time.Duration // threshold above which the phase is flagged
+	hint   string
+	// background phases run after the dashboard shows and do not count
+	// toward the total.
+	background bool
+}
+
+type startupProfile struct {
+	configBytes int64
+	hosts       int
+	containers  int
+	groups      int
+	history     int
+	phases      []profilePhase
+}
+
+// total is the time until the dashboard is drawn.
+func (p startupProfile) total() time.Duration {
+	var total time.Duration
+	for _, phase := range p.phases {
+		if !phase.background {
+			total += phase.took
+		}
+	}
+	return total
+}
+
+// profileStartup times the startup steps against the real config.
+func profileStartup() startupProfile {
+	var p startupProfile
+	if info, err := os.Stat(getConfigPath()); err == nil {
+		p.configBytes = info.Size()
+	}
+
+	start := time.Now()
+	groups, hosts, history, loadErr := loadConfig()
+	load := profilePhase{name: "load config", took: time.Since(start), slow: 200 * time.Millisecond,
+		hint: "reading hosts.json is slow; check that ~/.config is on a local disk"}
+	if loadErr != nil {
+		load.detail = loadErr.Error()
+	}
+	p.hosts, p.groups, p.history = len(hosts), len(groups), len(history)
+	walkHosts(hosts, func(Host) { p.containers++ })
+	p.containers -= p.hosts
+
+	start = time.Now()
+	m := newModel(groups, hosts, history, loadErr)
+	build := profilePhase{name: "build model", took: time.Since(start), slow: 200 * time.Millisecond,
+		detail: fmt.Sprintf("%d list rows", len(m.list.Items())),
+		hint:   "archive hosts you rarely use to shorten the list"}
+
+	p.phases = append(p.phases, load, build, profileKeychain(m.rawHosts))
+
+	start = time.Now()
+	next, _ := m.Update(tea.WindowSizeMsg{Width: profileWidth, Height: profileHeight})
+	view := next.(model).View()
+	p.phases = append(p.phases, profilePhase{name: "first render", took: time.Since(start), slow: 100 * time.Millisecond,
+		detail: fmt.Sprintf("%dx%d, %d bytes", profileWidth, profileHeight, len(view)),
+		hint:   "rendering runs on every keypress, so a slow one here is worth an issue"})
+	return p
+}
+
+// profileKeychain runs the background password prefetch in the foreground
+// and times each lookup.
+func profileKeychain(hosts []Host) profilePhase {
+	phase := profilePhase{name: "keychain", slow: 2 * time.Second, background: true,
+		hint: "the dashboard does not wait for this; set ASSHO_SECRETS_OFFLINE=1 if the keychain hangs"}
+	if secretsOffline() {
+		phase.detail = "skipped (ASSHO_SECRETS_OFFLINE)"
+		return phase
+	}
+	var mu sync.Mutex
+	var lookups int
+	var slowest time.Duration
+	var slowestAlias string
+	start := time.Now()
+	msg := prefetchPasswords(cloneHosts(hosts), secretPrefetchBudget, func(h Host) (string, error) {
+		began := time.Now()
+		secret, err := hostPassword(h)
+		took := time.Since(began)
+		mu.Lock()
+		defer mu.Unlock()
+		lookups++
+		if took > slowest {
+			slowest, slowestAlias = took, h.Alias
+		}
+		return secret, err
+	})
+	phase.took = time.Since(start)
+	if lookups == 0 && msg.skipped == 0 {
+		phase.detail = "no stored passwords"
+		return phase
+	}
+	phase.detail = fmt.Sprintf("%d lookup(s), %d at a time · slowest %q %s", lookups, secretPrefetchWorkers, slowestAlias, formatProfileDuration(slowest))
+	if len(msg.failed) > 0 {
+		phase.detail += fmt.Sprintf(" · %d failed", len(msg.failed))
+	}
+	if msg.skipped > 0 {
+		phase.detail += fmt.Sprintf(" · %d past the budget", msg.skipped)
+	}
+	return phase
+}
+
+func formatProfileDuration(d time.Duration) string {
+	switch {
+	case d >= time.Second:
+		return fmt.Sprintf("%.2fs", d.Seconds())
+	case d >= time.Millisecond:
+		return fmt.Sprintf("%.1fms", float64(d)/float64(time.Millisecond))
+	}
+	return fmt.Sprintf("%dµs", d.Microseconds())
+}
+
+func fprintStartupProfile(w io.Writer, p startupProfile) {
+	fmt.Fprintf(w, "assho %s · %s/%s · %s\n", version, runtime.GOOS, runtime.GOARCH, getConfigPath())
+	fmt.Fprintf(w, "%d hosts, %d containers, %d groups, %d history entries · %d KB\n\n",
+		p.hosts, p.containers, p.groups, p.history, p.configBytes/1024)
+	for _, phase := range p.phases {
+		icon := doctorOK.icon()
+		if phase.took > phase.slow {
+			icon = doctorWarn.icon()
+		}
+		detail := phase.detail
+		if phase.background {
+			detail = "background · " + detail
+		}
+		line := fmt.Sprintf("%s %-13s %9s  %s", icon, phase.name, formatProfileDuration(phase.took), detail)
+		fmt.Fprintln(w, strings.TrimRight(line, " "))
+		if phase.took > phase.slow && phase.hint != "" {
+			fmt.Fprintf(w, "  %-13s %9s  %s\n", "", "", phase.hint)
+		}
+	}
+	fmt.Fprintf(w, "\n  %-13s %9s\n", "to dashboard", formatProfileDuration(p.total()))
+}
+
+func cliProfileStartup() {
+	fprintStartupProfile(os.Stdout, profileStartup())
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProfileStartup(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("ASSHO_SECRETS_OFFLINE", "1")
+	cfg := configFile{
+		Version: configVersion,
+		Groups:  []Group{{ID: "g1", Name: "prod"}},
+		Hosts: []Host{
+			{ID: "h1", Alias: "web", Hostname: "web.example", GroupID: "g1", PasswordRef: "h1"},
+			{ID: "h2", Alias: "box", Hostname: "box.example", Containers: []Host{{ID: "c1", Alias: "db"}}},
+		},
+	}
+	data, _ := json.Marshal(cfg)
+	path := filepath.Join(home, ".config", "assho", "hosts.json")
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	p := profileStartup()
+	if p.hosts != 2 || p.containers != 1 || p.groups != 1 || p.configBytes == 0 {
+		t.Fatalf("unexpected counts %+v", p)
+	}
+	var names []string
+	for _, phase := range p.phases {
+		names = append(names, phase.name)
+	}
+	if got := strings.Join(names, ","); got != "load config,build model,keychain,first render" {
+		t.Fatalf("unexpected phases %s", got)
+	}
+	if p.phases[2].detail != "skipped (ASSHO_SECRETS_OFFLINE)" {
+		t.Fatalf("offline mode should skip the keychain, got %q", p.phases[2].detail)
+	}
+}
+
+func TestFprintStartupProfile(t *testing.T) {
+	p := startupProfile{hosts: 1000, phases: []profilePhase{
+		{name: "load config", took: 20 * time.Millisecond, slow: 200 * time.Millisecond, hint: "load hint"},
+		{name: "keychain", took: 5 * time.Second, slow: 2 * time.Second, hint: "keychain hint", background: true, detail: "3 lookup(s)"},
+		{name: "first render", took: 300 * time.Millisecond, slow: 100 * time.Millisecond, hint: "render hint"},
+	}}
+	var out bytes.Buffer
+	fprintStartupProfile(&out, p)
+	text := out.String()
+	if strings.Contains(text, "load hint") || !strings.Contains(text, "keychain hint") || !strings.Contains(text, "render hint") {
+		t.Fatalf("hints should show only for slow phases:\n%s", text)
+	}
+	if !strings.Contains(text, "to dashboard") || !strings.Contains(text, "320.0ms") {
+		t.Fatalf("the total should leave out background phases:\n%s", text)
+	}
+	if !strings.Contains(text, "1000 hosts") || !strings.Contains(text, "background · 3 lookup(s)") {
+		t.Fatalf("unexpected report:\n%s", text)
+	}
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+// --- Proxy Commands ---
+
+// Some hosts can only be reached through an HTTPS or WebSocket proxy: a
+// corporate proxy that allows nothing but CONNECT, or a Cloudflare Access
+// tunnel. A host's ProxyCommand is passed to ssh as -o ProxyCommand=..., and
+// ssh expands %h, %p, and %r in it. In the form, Ctrl+P on the ProxyCommand
+// field cycles through templates for the usual tools; the example proxy
+// addresses in them are meant to be replaced. A ProxyCommand takes the place
+// of a ProxyJump, and because ssh-keyscan cannot use one, first-contact scans
+// and key pins leave the fingerprint check to ssh.
+
+// proxyExampleHost stands in for the proxy in the HTTP CONNECT presets.
+const proxyExampleHost = "proxy.example.com"
+
+var proxyPresets = []string{
+	"corkscrew " + proxyExampleHost + " 8080 %h %p",        // HTTP CONNECT proxy
+	"cloudflared access ssh --hostname %h",                 // Cloudflare Access
+	"nc -X connect -x " + proxyExampleHost + ":8080 %h %p", // HTTP CONNECT proxy, OpenBSD nc
+	"nc -X 5 -x 127.0.0.1:1080 %h %p",                      // SOCKS5 proxy, e.g. ssh -D
+	"websocat --binary wss://%h/ssh",                       // WebSocket endpoint
+}
+
+// nextProxyPreset steps from current to the neighbouring preset, with a
+// blank command between the last preset and the first. A command that is
+// not a preset steps to the first or last one.
+func nextProxyPreset(current string, delta int) string {
+	options := append([]string{""}, proxyPresets...)
+	index := 0
+	for i, option := range options {
+		if option == strings.TrimSpace(current) {
+			index = i
+			break
+		}
+	}
+	index = (index + delta + len(options)) % len(options)
+	return options[index]
+}
+
+// validateProxyCommand rejects commands ssh would misread. Shell syntax is
+// allowed, since ssh runs the command through the user's shell.
+func validateProxyCommand(command string) error {
+	if strings.HasPrefix(command, "-") {
+		return fmt.Errorf("proxycommand must not start with '-'")
+	}
+	for _, r := range command {
+		if unicode.IsControl(r) {
+			return fmt.Errorf("proxycommand must be a single line")
+		}
+	}
+	return nil
+}
+
+// proxyProgram is the program a ProxyCommand runs.
+func proxyProgram(command string) string {
+	fields := strings.Fields(command)
+	if len(fields) == 0 {
+		return ""
+	}
+	return fields[0]
+}
+
+// behindProxy reports whether ssh reaches h through a jump host or a proxy
+// command rather than dialing it.
+func (h Host) behindProxy() bool {
+	return h.ProxyJump != "" || h.ProxyCommand != ""
+}
+
+// proxyVia names what h is reached through: the ProxyCommand's program or
+// the ProxyJump host.
+func (h Host) proxyVia() string {
+	if h.ProxyCommand != "" {
+		return filepath.Base(proxyProgram(h.ProxyCommand))
+	}
+	return h.ProxyJump
+}
+
+// proxyArgs are the ssh options that route a connection to h through its
+// ProxyCommand or ProxyJump. The command wins when both are set, as it does
+// when a network override adds a jump host.
+func proxyArgs(h Host) []string {
+	switch {
+	case h.ProxyCommand != "":
+		return []string{"-o", "ProxyCommand=" + h.ProxyCommand}
+	case h.ProxyJump != "":
+		return []string{"-J", h.ProxyJump}
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestProxyArgsPreferProxyCommand(t *testing.T) {
+	cases := []struct {
+		host Host
+		want []string
+	}{
+		{Host{}, nil},
+		{Host{ProxyJump: "bastion"}, []string{"-J", "bastion"}},
+		{Host{ProxyCommand: "cloudflared access ssh --hostname %h"}, []string{"-o", "ProxyCommand=cloudflared access ssh --hostname %h"}},
+		{Host{ProxyJump: "bastion", ProxyCommand: "nc -X 5 -x 127.0.0.1:1080 %h %p"}, []string{"-o", "ProxyCommand=nc -X 5 -x 127.0.0.1:1080 %h %p"}},
+	}
+	for _, c := range cases {
+		if got := proxyArgs(c.host); !slices.Equal(got, c.want) {
+			t.Errorf("proxyArgs(%+v) = %q, want %q", c.host, got, c.want)
+		}
+	}
+}
+
+func TestSSHArgsPassProxyCommandAsOneArgument(t *testing.T) {
+	h := Host{Alias: "app", Hostname: "app.internal", User: "deploy", ProxyCommand: "cloudflared access ssh --hostname %h"}
+	args := buildSSHArgs(h, false, "")
+	i := slices.Index(args, "ProxyCommand=cloudflared access ssh --hostname %h")
+	if i < 1 || args[i-1] != "-o" {
+		t.Fatalf("expected -o ProxyCommand=... in %q", args)
+	}
+	if slices.Contains(args, "-J") {
+		t.Fatalf("a ProxyCommand host should not get -J: %q", args)
+	}
+}
+
+func TestNextProxyPresetCycles(t *testing.T) {
+	value := ""
+	for range proxyPresets {
+		value = nextProxyPreset(value, 1)
+		if value == "" {
+			t.Fatal("expected every preset before wrapping to blank")
+		}
+	}
+	if got := nextProxyPreset(value, 1); got != "" {
+		t.Fatalf("expected blank after the last preset, got %q", got)
+	}
+	if got := nextProxyPreset("", -1); got != proxyPresets[len(proxyPresets)-1] {
+		t.Fatalf("expected stepping back from blank to reach the last preset, got %q", got)
+	}
+	if got := nextProxyPreset("my-own-proxy %h %p", 1); got != proxyPresets[0] {
+		t.Fatalf("expected a custom command to step to the first preset, got %q", got)
+	}
+}
+
+func TestValidateProxyCommand(t *testing.T) {
+	for _, ok := range append([]string{"", "ssh -W %h:%p bastion | tee /dev/null"}, proxyPresets...) {
+		if err := validateProxyCommand(ok); err != nil {
+			t.Errorf("validateProxyCommand(%q) = %v", ok, err)
+		}
+	}
+	for _, bad := range []string{"-oProxyCommand=x", "nc %h %p\nrm -rf ~"} {
+		if err := validateProxyCommand(bad); err == nil {
+			t.Errorf("expected %q to be rejected", bad)
+		}
+	}
+}
+
+func TestSaveFromFormRejectsProxyCommandWithJump(t *testing.T) {
+	m := model{form: newFormState(newFormInputs())}
+	m.form.inputs[fieldAlias].SetValue("edge")
+	m.form.inputs[fieldHostname].SetValue("edge.example.com")
+	m.form.inputs[fieldProxyJump].SetValue("bastion")
+	m.form.inputs[fieldProxyCommand].SetValue("cloudflared access ssh --hostname %h")
+	err := m.saveFromForm()
+	if err == nil || !strings.HasPrefix(err.Error(), "proxycommand") {
+		t.Fatalf("expected a proxycommand error, got %v", err)
+	}
+	m.focusFormError(err)
+	if m.form.focus != controlProxyCommand {
+		t.Fatalf("expected focus on the ProxyCommand field, got %v", m.form.focus)
+	}
+}
+
+func TestSSHConfigProxyCommandRoundTrip(t *testing.T) {
+	path := writeTempSSHConfig(t, `
+Host edge
+    HostName edge.example.com
+    ProxyCommand cloudflared access ssh --hostname %h
+
+Host plain
+    HostName plain.example.com
+    ProxyCommand none
+`)
+	hosts, err := parseSSHConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hosts) != 2 || hosts[0].ProxyCommand != "cloudflared access ssh --hostname %h" || hosts[1].ProxyCommand != "" {
+		t.Fatalf("unexpected proxy commands: %+v", hosts)
+	}
+	var buf bytes.Buffer
+	fprintSSHConfig(&buf, []Host{{Alias: "edge", Hostname: "edge.example.com", ProxyJump: "bastion", ProxyCommand: hosts[0].ProxyCommand}})
+	out := buf.String()
+	if !strings.Contains(out, "    ProxyCommand cloudflared access ssh --hostname %h\n") || strings.Contains(out, "ProxyJump") {
+		t.Fatalf("expected only the ProxyCommand in the export, got:\n%s", out)
+	}
+}
+
+func TestProxyCommandHostsSkipKeyscanAndPortDial(t *testing.T) {
+	h := Host{Hostname: "edge.example.com", ProxyCommand: "/usr/local/bin/cloudflared access ssh --hostname %h", HostKeyPin: []string{"SHA256:x"}}
+	if _, err := scanHostKeys(h); err == nil {
+		t.Fatal("expected keyscan to refuse a ProxyCommand host")
+	}
+	if err := verifyHostKeyPin(h); err != errPinBehindJump {
+		t.Fatalf("expected errPinBehindJump, got %v", err)
+	}
+	c := diagnosePort("edge.example.com", "22", h.proxyVia())
+	if !c.skipped || !strings.Contains(c.summary, "cloudflared") {
+		t.Fatalf("expected the port check to be skipped through cloudflared, got %+v", c)
+	}
+}
+
+func TestDoctorChecksProxyCommandPrograms(t *testing.T) {
+	hosts := []Host{
+		{Alias: "a", ProxyCommand: "cloudflared access ssh --hostname %h"},
+		{Alias: "b", ProxyCommand: "cloudflared access ssh --hostname %h"},
+		{Alias: "c", ProxyCommand: "corkscrew proxy.example.com 8080 %h %p"},
+	}
+	checks := doctorToolChecks(hosts, fakeLookPath("ssh", "corkscrew"))
+	if c := findCheck(checks, "cloudflared"); c.level != doctorWarn || !strings.Contains(c.detail, "2 host(s)") || c.fix == "" {
+		t.Fatalf("expected a warning for missing cloudflared, got %+v", c)
+	}
+	if c := findCheck(checks, "corkscrew"); c.level != doctorOK {
+		t.Fatalf("expected corkscrew to be found, got %+v", c)
+	}
+}
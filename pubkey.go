@@ -0,0 +1,74 @@
+package main
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// --- Copy Public Key ---
+
+// y on a host, in the list or its detail pane, copies the public half of
+// its key file to the clipboard for pasting into a cloud console or a
+// Gitea/GitHub deploy key form. The .pub next to the key is copied as it
+// is, comment included. Without one the public key is read from the private
+// key itself, whose public half OpenSSH keeps unencrypted, so no passphrase
+// is asked for. A key file inherited from the host's group counts.
+
+// publicKeyText returns the authorized_keys line for identity.
+func publicKeyText(identity string) (string, error) {
+	path := expandPath(strings.TrimSpace(identity))
+	if path == "" {
+		return "", errors.New("no key file; ssh uses the agent or its default keys")
+	}
+	pubPath := path
+	if !strings.HasSuffix(path, ".pub") {
+		pubPath = path + ".pub"
+	}
+	if data, err := os.ReadFile(pubPath); err == nil {
+		for _, line := range strings.Split(string(data), "\n") {
+			if fields := strings.Fields(line); len(fields) >= 2 && isPublicKeyType(fields[0]) {
+				return strings.TrimSpace(line), nil
+			}
+		}
+		return "", fmt.Errorf("%s is not an OpenSSH public key", pubPath)
+	}
+	if pubPath == path {
+		return "", fmt.Errorf("%s is unavailable", path)
+	}
+	if _, err := os.Stat(path); err != nil {
+		return "", fmt.Errorf("key file %s is unavailable", path)
+	}
+	blob := privateKeyPublicBlob(path)
+	if blob == nil {
+		return "", fmt.Errorf("no %s, and %s is not an OpenSSH private key; run ssh-keygen -y -f %s > %s", pubPath, path, path, pubPath)
+	}
+	keyType, _, ok := readSSHString(blob)
+	if !ok {
+		return "", fmt.Errorf("%s holds a malformed public key", path)
+	}
+	return string(keyType) + " " + base64.StdEncoding.EncodeToString(blob), nil
+}
+
+// copyPublicKey copies h's public key and reports it in the status bar.
+func (m model) copyPublicKey(h Host) (tea.Model, tea.Cmd) {
+	m.clearListDeleteConfirm()
+	identity := applyGroupDefaults(h, m.rawGroups).IdentityFile
+	key, err := publicKeyText(identity)
+	if err == nil {
+		err = copyToClipboard(key + "\n")
+	}
+	if err != nil {
+		m.status.message = "Copy public key of " + h.Alias + ": " + err.Error()
+		m.status.isError = true
+	} else {
+		m.status.message = "Copied the public key of " + h.Alias + " (" + strings.Fields(key)[0] + ")"
+		m.status.isError = false
+	}
+	m.status.version++
+	return m, statusClearCmd(m.status.version)
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// --- Quick-connect Hotkeys ---
+
+// Digits 1–9 on the dashboard connect at once to the host shown with that
+// number. A host's Hotkey field binds it to a digit for good; the digits
+// left over go, in list order, to the pinned hosts when any are pinned and
+// to the visible hosts otherwise, so with a filter applied 1 is the first
+// match. The numbers are worked out from the rows the list shows, which is
+// also what the delegate draws them from.
+
+// quickConnectSlots maps digits to hosts; index 0 is unused and an empty ID
+// is a free digit.
+func quickConnectSlots(items []list.Item) [10]Host {
+	var slots [10]Host
+	var hosts []Host
+	taken := map[string]bool{}
+	pinned := false
+	for _, item := range items {
+		h, ok := item.(Host)
+		if !ok {
+			continue
+		}
+		hosts = append(hosts, h)
+		pinned = pinned || h.Pinned
+		if h.Hotkey >= 1 && h.Hotkey <= 9 && slots[h.Hotkey].ID == "" && !taken[h.ID] {
+			slots[h.Hotkey] = h
+			taken[h.ID] = true
+		}
+	}
+	next := 1
+	for _, h := range hosts {
+		if taken[h.ID] || (pinned && !h.Pinned) {
+			continue
+		}
+		for next <= 9 && slots[next].ID != "" {
+			next++
+		}
+		if next > 9 {
+			break
+		}
+		slots[next] = h
+		taken[h.ID] = true
+	}
+	return slots
+}
+
+// quickConnectDigit is the digit shown on h's row, or 0.
+func quickConnectDigit(items []list.Item, h Host) int {
+	slots := quickConnectSlots(items)
+	for digit := 1; digit <= 9; digit++ {
+		if slots[digit].ID == h.ID {
+			return digit
+		}
+	}
+	return 0
+}
+
+// quickConnect connects to the host numbered digit.
+func (m model) quickConnect(digit int) (tea.Model, tea.Cmd) {
+	h := quickConnectSlots(m.list.VisibleItems())[digit]
+	if h.ID == "" {
+		m.status.message = fmt.Sprintf("No host on %d", digit)
+		m.status.isError = true
+		m.status.version++
+		return m, statusClearCmd(m.status.version)
+	}
+	m.reselectItem(h.ID, false)
+	return m.connectToHost(h)
+}
+
+// parseHotkey reads the form's Hotkey field; blank is 0.
+func parseHotkey(value string) (int, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 || n > 9 {
+		return 0, fmt.Errorf("hotkey must be a digit from 1 to 9")
+	}
+	return n, nil
+}
+
+// hotkeyWarning names another host already bound to the form's digit.
+func (m model) hotkeyWarning(digit int) string {
+	editingID := ""
+	if m.form.selectedHost != nil {
+		editingID = m.form.selectedHost.ID
+	}
+	for _, h := range m.rawHosts {
+		if h.Hotkey == digit && h.ID != editingID {
+			return fmt.Sprintf("%d is also bound to %s; the first in the list wins", digit, h.Alias)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestQuickConnectSlots(t *testing.T) {
+	hosts := []Host{
+		{ID: "h1", Alias: "web"},
+		{ID: "h2", Alias: "db", Hotkey: 1},
+		{ID: "h3", Alias: "cache"},
+	}
+	slots := quickConnectSlots(flattenHosts(nil, hosts))
+	if slots[1].ID != "h2" || slots[2].ID != "h1" || slots[3].ID != "h3" || slots[4].ID != "" {
+		t.Fatalf("expected the bound host first and the rest in list order, got %q %q %q", slots[1].Alias, slots[2].Alias, slots[3].Alias)
+	}
+
+	hosts[2].Pinned = true
+	slots = quickConnectSlots(flattenHosts(nil, hosts))
+	if slots[1].ID != "h2" || slots[2].ID != "h3" || slots[3].ID != "" {
+		t.Fatalf("expected free digits to go to pinned hosts only, got %q %q", slots[2].Alias, slots[3].Alias)
+	}
+}
+
+func TestDigitConnectsToNumberedHost(t *testing.T) {
+	hosts := []Host{
+		{ID: "h1", Alias: "web", Hostname: "10.0.0.1", User: "root", Transport: transportPSRemoting},
+		{ID: "h2", Alias: "db", Hostname: "10.0.0.2", User: "root", Transport: transportPSRemoting},
+	}
+	writeTempConfig(t, hosts)
+	m := model{state: stateList, rawHosts: hosts, list: newTestListModel(nil, hosts), historyList: newTestHistoryListModel()}
+	m.refreshDelegate()
+	if out := ansi.Strip(m.list.View()); !strings.Contains(out, "db  [2]") {
+		t.Fatalf("expected rows numbered\n%s", out)
+	}
+
+	result, _ := m.updateList(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("2")})
+	if got := result.(model); got.sshToRun == nil || got.sshToRun.ID != "h2" {
+		t.Fatalf("expected 2 to connect to db, status %q", got.status.message)
+	}
+	result, _ = m.updateList(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("7")})
+	if got := result.(model); !got.status.isError || got.sshToRun != nil {
+		t.Fatal("expected an unbound digit to report an error")
+	}
+}
+
+func TestFormHotkeyValidation(t *testing.T) {
+	m := model{rawHosts: []Host{{ID: "h1", Alias: "web", Hotkey: 3}}, form: newFormState(newFormInputs())}
+	m.form.inputs[fieldHotkey].SetValue("0")
+	if _, blocking := m.formControlIssue(controlHotkey); !blocking {
+		t.Fatal("expected 0 to be rejected")
+	}
+	m.form.inputs[fieldHotkey].SetValue("3")
+	if warning, blocking := m.formControlIssue(controlHotkey); blocking || !strings.Contains(warning, "also bound to web") {
+		t.Fatalf("expected a shared digit to warn, got %q", warning)
+	}
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// --- Quick Stats ---
+
+// s in the detail pane runs one short, read-only command bundle (df, free,
+// uptime, who) and shows a disk/memory/load snapshot without opening a shell.
+// Snapshots are kept in memory only; they go stale too quickly to save.
+
+const quickStatsScript = "df -hP 2>/dev/null; echo " + probeSeparator +
+	"; free -m 2>/dev/null; echo " + probeSeparator +
+	"; uptime; echo " + probeSeparator + "; who"
+
+// maxQuickStatsDisks caps the filesystems listed in the detail pane.
+const maxQuickStatsDisks = 4
+
+var loadAveragePattern = regexp.MustCompile(`load averages?:\s*(.*)$`)
+
+type diskUsage struct {
+	mount, size, used, percent string
+}
+
+type quickStats struct {
+	disks    []diskUsage
+	memTotal int // MiB; zero when free is unavailable
+	memUsed  int
+	memAvail int
+	load     string
+	uptime   string
+	users    []string
+	takenAt  time.Time
+}
+
+type quickStatsState struct {
+	hostID  string
+	loading bool
+	stats   *quickStats
+	err     string
+}
+
+type quickStatsMsg struct {
+	hostID string
+	stats  *quickStats
+	err    error
+}
+
+func parseQuickStats(out string, now time.Time) quickStats {
+	stats := quickStats{takenAt: now}
+	sections := strings.SplitN(out, probeSeparator, 4)
+	for len(sections) < 4 {
+		sections = append(sections, "")
+	}
+
+	for _, line := range strings.Split(sections[0], "\n") {
+		fields := strings.Fields(line)
+		// Only real devices; tmpfs, overlay, and friends are noise here.
+		if len(fields) < 6 || !strings.HasPrefix(fields[0], "/") || strings.HasPrefix(fields[0], "/dev/loop") {
+			continue
+		}
+		stats.disks = append(stats.disks, diskUsage{mount: strings.Join(fields[5:], " "), size: fields[1], used: fields[2], percent: fields[4]})
+	}
+
+	for _, line := range strings.Split(sections[1], "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 3 || fields[0] != "Mem:" {
+			continue
+		}
+		stats.memTotal, _ = strconv.Atoi(fields[1])
+		stats.memUsed, _ = strconv.Atoi(fields[2])
+		stats.memAvail = stats.memTotal - stats.memUsed
+		if len(fields) >= 7 {
+			stats.memAvail, _ = strconv.Atoi(fields[6])
+		}
+	}
+
+	uptime := strings.TrimSpace(sections[2])
+	if match := loadAveragePattern.FindStringSubmatch(uptime); match != nil {
+		stats.load = strings.Join(strings.Fields(strings.ReplaceAll(match[1], ",", " ")), " ")
+	}
+	if match := uptimePattern.FindStringSubmatch(uptime); match != nil {
+		stats.uptime = strings.Join(strings.Fields(match[1]), " ")
+	}
+
+	seen := map[string]bool{}
+	for _, line := range strings.Split(sections[3], "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || seen[fields[0]] {
+			continue
+		}
+		seen[fields[0]] = true
+		stats.users = append(stats.users, fields[0])
+	}
+	return stats
+}
+
+func formatMiB(mib int) string {
+	if mib >= 1024 {
+		return fmt.Sprintf("%.1fG", float64(mib)/1024)
+	}
+	return fmt.Sprintf("%dM", mib)
+}
+
+// rows renders the snapshot as detail-pane label/value pairs.
+func (s quickStats) rows() [][2]string {
+	var rows [][2]string
+	load := s.load
+	if s.uptime != "" {
+		load = strings.TrimSpace(load + " · up " + s.uptime)
+	}
+	rows = append(rows, [2]string{"Load", load})
+	if s.memTotal > 0 {
+		rows = append(rows, [2]string{"Memory", fmt.Sprintf("%s of %s used · %s available", formatMiB(s.memUsed), formatMiB(s.memTotal), formatMiB(s.memAvail))})
+	}
+	for i, d := range s.disks {
+		if i == maxQuickStatsDisks {
+			rows = append(rows, [2]string{"", fmt.Sprintf("… %d more filesystems", len(s.disks)-maxQuickStatsDisks)})
+			break
+		}
+		// detailRow's label column is 14 cells wide.
+		rows = append(rows, [2]string{ansi.Truncate("Disk "+d.mount, 13, "…"), fmt.Sprintf("%s of %s (%s)", d.used, d.size, d.percent)})
+	}
+	users := fmt.Sprintf("%d logged in", len(s.users))
+	if len(s.users) > 0 {
+		users += " (" + strings.Join(s.users, ", ") + ")"
+	}
+	rows = append(rows, [2]string{"Users", users})
+	return rows
+}
+
+func quickStatsTrusted(h Host) tea.Cmd {
+	return func() tea.Msg {
+		out, err := runSSHCommand(h, quickStatsScript)
+		if err != nil {
+			return quickStatsMsg{hostID: h.ID, err: err}
+		}
+		stats := parseQuickStats(out, time.Now())
+		return quickStatsMsg{hostID: h.ID, stats: &stats}
+	}
+}
+
+func (m model) startQuickStats(h Host) (tea.Model, tea.Cmd) {
+	if h.IsContainer {
+		return m, nil
+	}
+	m.quickStats = quickStatsState{hostID: h.ID, loading: true}
+	return m, checkHostTrustCmd(pendingSSHAction{kind: sshActionQuickStats, host: h, trustHost: h, inv: m.inventory()})
+}
+
+func (m model) finishQuickStats(msg quickStatsMsg) (tea.Model, tea.Cmd) {
+	if msg.hostID != m.quickStats.hostID {
+		return m, nil
+	}
+	m.quickStats.loading = false
+	if msg.err != nil {
+		m.quickStats.err, _ = formatTestStatus(msg.err)
+		return m, nil
+	}
+	m.quickStats.err = ""
+	m.quickStats.stats = msg.stats
+	return m, nil
+}
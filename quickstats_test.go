@@ -0,0 +1,74 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestParseQuickStats(t *testing.T) {
+	out := `Filesystem      Size  Used Avail Use% Mounted on
+udev            1.9G     0  1.9G   0% /dev
+/dev/sda1        40G   12G   26G  32% /
+/dev/loop0       64M   64M     0 100% /snap/core20/1
+/dev/sdb1       1.8T  900G  850G  52% /srv/backup data
+--assho--
+               total        used        free      shared  buff/cache   available
+Mem:            3900        1200         500          10        2200        2600
+Swap:           2047           0        2047
+--assho--
+ 10:04:01 up 12 days,  3:41,  2 users,  load average: 0.10, 0.20, 0.30
+--assho--
+root     pts/0        2024-01-01 10:00 (10.0.0.5)
+deploy   pts/1        2024-01-01 10:01 (10.0.0.6)
+root     pts/2        2024-01-01 10:02 (10.0.0.7)
+`
+	s := parseQuickStats(out, time.Unix(0, 0))
+	if len(s.disks) != 2 || s.disks[0].mount != "/" || s.disks[1].mount != "/srv/backup data" || s.disks[1].percent != "52%" {
+		t.Fatalf("unexpected disks %+v", s.disks)
+	}
+	if s.memTotal != 3900 || s.memUsed != 1200 || s.memAvail != 2600 {
+		t.Fatalf("unexpected memory %d/%d/%d", s.memTotal, s.memUsed, s.memAvail)
+	}
+	if s.load != "0.10 0.20 0.30" || s.uptime != "12 days, 3:41" || strings.Join(s.users, ",") != "root,deploy" {
+		t.Fatalf("unexpected load/uptime/users %q %q %v", s.load, s.uptime, s.users)
+	}
+	rows := s.rows()
+	if rows[1][1] != "1.2G of 3.8G used · 2.5G available" || rows[2][0] != "Disk /" {
+		t.Fatalf("unexpected rows %v", rows)
+	}
+
+	mac := parseQuickStats("/dev/disk3s1s1  460Gi   10Gi  300Gi     4%  /\n--assho--\n--assho--\n10:04  up 3 mins, 1 user, load averages: 1.52 1.31 1.20\n--assho--\n", time.Unix(0, 0))
+	if mac.memTotal != 0 || mac.load != "1.52 1.31 1.20" || len(mac.disks) != 1 {
+		t.Fatalf("unexpected macOS stats %+v", mac)
+	}
+	for _, row := range mac.rows() {
+		if row[0] == "Memory" {
+			t.Fatal("expected memory to be omitted without free")
+		}
+	}
+}
+
+func TestDetailQuickStats(t *testing.T) {
+	hosts := []Host{{ID: "h1", Alias: "web", Hostname: "10.0.0.1"}}
+	m := model{rawHosts: hosts, detailHostID: "h1", state: stateDetail}
+	updated, cmd := m.updateDetail(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s")})
+	m = updated.(model)
+	if cmd == nil || !m.quickStats.loading || !strings.Contains(m.renderDetailView(), "Quick stats") {
+		t.Fatal("expected quick stats to start loading in the detail pane")
+	}
+
+	updated, _ = m.finishQuickStats(quickStatsMsg{hostID: "other", err: errors.New("late")})
+	if m = updated.(model); !m.quickStats.loading {
+		t.Fatal("expected results for another host to be ignored")
+	}
+	stats := parseQuickStats("/dev/sda1 40G 12G 26G 32% /\n--assho--\n--assho--\nup 2 days, 1 user, load average: 0.01, 0.02, 0.03\n--assho--\n", time.Now())
+	updated, _ = m.finishQuickStats(quickStatsMsg{hostID: "h1", stats: &stats})
+	m = updated.(model)
+	if view := m.renderDetailView(); !strings.Contains(view, "12G of 40G (32%)") || !strings.Contains(view, "0.01 0.02 0.03") {
+		t.Fatalf("expected snapshot in the detail pane, got:\n%s", view)
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// --- Inline Host Rename ---
+
+type hostRenameState struct {
+	hostID string
+	input  textinput.Model
+}
+
+// aliasTaken reports whether another host already uses alias
+// (case-insensitive). exceptID is the host being renamed or edited.
+func aliasTaken(hosts []Host, alias, exceptID string) bool {
+	for _, h := range hosts {
+		if h.ID != exceptID && strings.EqualFold(strings.TrimSpace(h.Alias), alias) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m model) openHostRename(h Host) (tea.Model, tea.Cmd) {
+	m.clearListDeleteConfirm()
+	input := textinput.New()
+	input.Prompt = "  Alias  "
+	input.PromptStyle = lipgloss.NewStyle().Foreground(colorHighlight).Bold(true)
+	input.TextStyle = lipgloss.NewStyle().Foreground(colorText)
+	input.Cursor.Style = lipgloss.NewStyle().Foreground(colorSecondary)
+	input.SetValue(h.Alias)
+	input.CursorEnd()
+	m.hostRename = hostRenameState{hostID: h.ID, input: input}
+	m.form.formError = ""
+	m.state = stateHostRename
+	return m, m.hostRename.input.Focus()
+}
+
+// renameHost validates alias like saveFromForm and persists it.
+func (m *model) renameHost(id, alias string) error {
+	alias = strings.TrimSpace(alias)
+	if alias == "" {
+		return fmt.Errorf("alias is required")
+	}
+	idx := findHostIndexByID(m.rawHosts, id)
+	if idx == -1 {
+		return fmt.Errorf("host no longer exists")
+	}
+	if aliasTaken(m.rawHosts, alias, id) {
+		return fmt.Errorf("alias already exists: %s", alias)
+	}
+	snapshot := m.snapshot()
+	m.rawHosts[idx].Alias = alias
+	m.refreshList()
+	if err := m.save(); err != nil {
+		m.restoreSnapshot(snapshot)
+		return fmt.Errorf("failed to save rename: %v", err)
+	}
+	m.rebuildHistoryList()
+	m.reselectItem(id, false)
+	return nil
+}
+
+func (m model) updateHostRename(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.state = stateList
+		m.form.formError = ""
+		return m, nil
+	case "enter":
+		if err := m.renameHost(m.hostRename.hostID, m.hostRename.input.Value()); err != nil {
+			m.form.formError = err.Error()
+			return m, nil
+		}
+		m.state = stateList
+		m.form.formError = ""
+		m.status.message = "Renamed to " + strings.TrimSpace(m.hostRename.input.Value())
+		m.status.isError = false
+		m.status.version++
+		return m, statusClearCmd(m.status.version)
+	}
+	var cmd tea.Cmd
+	m.hostRename.input, cmd = m.hostRename.input.Update(msg)
+	return m, cmd
+}
+
+func (m model) renderHostRenameView() string {
+	content := m.hostRename.input.View()
+	if m.form.formError != "" {
+		content += "\n\n" + testFailStyle.Render("✘ "+m.form.formError)
+	}
+	box := formBoxStyle.Render(formTitleStyle.Render("Rename Host") + "\n\n" + content)
+	help := "\n" + helpBarStyle.Render(helpEntry("enter", "save")+" | "+helpEntry("esc", "cancel"))
+	return appStyle.Render(box + help)
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestHostRenameValidatesAndSaves(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("ASSHO_STORE_PASSWORD", "0")
+	hosts := []Host{{ID: "h1", Alias: "web", Hostname: "10.0.0.1"}, {ID: "h2", Alias: "db", Hostname: "10.0.0.2"}}
+	m := model{rawHosts: hosts, list: newTestListModel(nil, hosts), historyList: newTestHistoryListModel()}
+	m.list.Select(0)
+
+	updated, _ := m.updateList(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")})
+	m = updated.(model)
+	if m.state != stateHostRename || m.hostRename.input.Value() != "web" {
+		t.Fatalf("expected rename prompt prefilled with the alias, got state %v %q", m.state, m.hostRename.input.Value())
+	}
+
+	m.hostRename.input.SetValue("DB")
+	updated, _ = m.updateHostRename(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(model)
+	if m.state != stateHostRename || m.form.formError != "alias already exists: DB" {
+		t.Fatalf("expected duplicate alias rejected, got %q", m.form.formError)
+	}
+
+	m.hostRename.input.SetValue("  frontend ")
+	updated, _ = m.updateHostRename(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(model)
+	if m.state != stateList || m.rawHosts[0].Alias != "frontend" {
+		t.Fatalf("expected rename saved, got %+v", m.rawHosts[0])
+	}
+	if h, ok := m.list.SelectedItem().(Host); !ok || h.ID != "h1" {
+		t.Fatal("expected renamed host to stay selected")
+	}
+	cfg, err := loadConfigFile()
+	if err != nil || cfg.Hosts[0].Alias != "frontend" {
+		t.Fatalf("expected rename persisted, got %+v %v", cfg.Hosts, err)
+	}
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// --- Return To The List ---
+
+// By default assho hands the terminal to ssh with exec, so closing the
+// session drops back to the shell assho was started from. With
+// ASSHO_RETURN=1 ssh runs as a child instead, and when the session closes
+// the dashboard comes back with the host just used selected, the way GUI SSH
+// managers behave. A session that never got going (ssh exits 255, or could
+// not start) waits for Enter first, so its error can be read before the
+// dashboard covers it.
+
+// sshFailureStatus is the exit status ssh reports for its own errors, as
+// opposed to the remote command's.
+const sshFailureStatus = 255
+
+func returnToList() bool {
+	return envBool("ASSHO_RETURN", false)
+}
+
+// sessionResult is how the last interactive session went, carried into the
+// next dashboard.
+type sessionResult struct {
+	hostID   string
+	parentID string // set for containers and guests
+	alias    string
+	err      error
+	ended    time.Time
+}
+
+// failed reports whether the session never reached the remote side.
+func (r sessionResult) failed() bool {
+	var exitErr *exec.ExitError
+	if errors.As(r.err, &exitErr) {
+		return exitErr.ExitCode() == sshFailureStatus
+	}
+	return r.err != nil
+}
+
+// runSession runs ssh in the foreground and waits for it. Interrupts typed
+// while ssh is still connecting go to ssh alone.
+func runSession(path string, argv, env []string) error {
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, os.Interrupt, syscall.SIGQUIT)
+	defer signal.Stop(interrupts)
+
+	cmd := exec.Command(path, argv[1:]...)
+	cmd.Args = argv
+	cmd.Env = env
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	return cmd.Run()
+}
+
+// waitForEnter holds a failed session's output on screen.
+func waitForEnter() {
+	fmt.Print("\n Press Enter to return to assho ")
+	_, _ = bufio.NewReader(os.Stdin).ReadString('\n')
+}
+
+// afterSession selects the host of the last session, opening its group and
+// parent row if they are collapsed, and reports how the session ended.
+func (m model) afterSession(r sessionResult) model {
+	rowID := r.hostID
+	if r.parentID != "" {
+		rowID = r.parentID
+	}
+	if idx := findHostIndexByID(m.rawHosts, rowID); idx != -1 {
+		if r.parentID != "" {
+			m.rawHosts[idx].Expanded = true
+		}
+		if groupIdx := findGroupIndexByID(m.rawGroups, m.rawHosts[idx].GroupID); groupIdx != -1 {
+			m.rawGroups[groupIdx].Expanded = true
+		}
+		m.refreshList()
+	}
+	m.reselectItem(r.hostID, false)
+	recorded := m.recordSessionEnd(r)
+	if recorded {
+		if err := m.save(); err != nil {
+			m.status.message = fmt.Sprintf("Failed to save session time: %v", err)
+			m.status.isError = true
+			m.status.version++
+			return m
+		}
+		m.rebuildHistoryList()
+	}
+
+	var exitErr *exec.ExitError
+	switch {
+	case r.failed():
+		m.status.message = fmt.Sprintf("Could not connect to %s: %v", r.alias, r.err)
+		m.status.isError = true
+	case errors.As(r.err, &exitErr):
+		m.status.message = fmt.Sprintf("Back from %s · exit status %d", r.alias, exitErr.ExitCode())
+		m.status.isError = false
+	default:
+		m.status.message = "Back from " + r.alias
+		m.status.isError = false
+	}
+	if d, ok := buildLastSession(m.history)[r.hostID]; ok && recorded {
+		m.status.message += " · " + formatSessionDuration(d)
+	}
+	m.status.version++
+	if !r.failed() && sessionNotesEnabled() {
+		m = m.openSessionNote(r)
+	}
+	return m
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestAfterSessionSelectsLastHost(t *testing.T) {
+	groups := []Group{{ID: "g1", Name: "prod"}}
+	hosts := []Host{
+		{ID: "h1", Alias: "web", Hostname: "10.0.0.1"},
+		{ID: "h2", Alias: "db", Hostname: "10.0.0.2", GroupID: "g1", Containers: []Host{{ID: "c1", Alias: "postgres", IsContainer: true}}},
+	}
+	m := model{rawGroups: groups, rawHosts: hosts, list: newTestListModel(groups, hosts)}
+
+	got := m.afterSession(sessionResult{hostID: "c1", parentID: "h2", alias: "postgres"})
+	selected, ok := got.list.SelectedItem().(Host)
+	if !ok || selected.ID != "c1" {
+		t.Fatalf("expected the container selected inside its collapsed group, got %+v", got.list.SelectedItem())
+	}
+	if got.status.isError || got.status.message != "Back from postgres" {
+		t.Fatalf("unexpected status %q", got.status.message)
+	}
+
+	failure := exec.Command("sh", "-c", "exit 255").Run()
+	got = m.afterSession(sessionResult{hostID: "h1", alias: "web", err: failure})
+	if !got.status.isError || !strings.Contains(got.status.message, "Could not connect to web") {
+		t.Fatalf("expected ssh's own failure reported as an error, got %q", got.status.message)
+	}
+	remote := exec.Command("sh", "-c", "exit 3").Run()
+	got = m.afterSession(sessionResult{hostID: "h1", alias: "web", err: remote})
+	if got.status.isError || !strings.Contains(got.status.message, "exit status 3") {
+		t.Fatalf("expected the remote exit status noted, got %q", got.status.message)
+	}
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// --- Host Roles ---
+
+// A host's role comes from its Role field or, when that is blank, from
+// keywords in its alias and hostname: db01 is a database and k8s-worker-3 a
+// Kubernetes node. The dashboard shows the role's icon after the alias and
+// tints the title in the role's color unless the host's group has a color.
+// The hand-edited "roles" section of hosts.json overrides the built-in
+// roles or adds new ones:
+//
+//	"roles": {
+//	  "db": {"icon": "🐘"},
+//	  "ci": {"icon": "🤖", "color": "orange", "keywords": ["jenkins", "runner"]}
+//	}
+
+// RoleStyle is one entry of the "roles" section. Empty fields keep the
+// built-in value of a role of the same name.
+type RoleStyle struct {
+	Icon     string   `json:"icon,omitempty"`
+	Color    string   `json:"color,omitempty"` // palette name or #rrggbb
+	Keywords []string `json:"keywords,omitempty"`
+}
+
+// roleSet is the built-in roles merged with the config, in matching order.
+type roleSet struct {
+	names  []string
+	styles map[string]RoleStyle
+}
+
+var builtinRoleNames = []string{"db", "web", "k8s", "gpu", "mail"}
+
+var builtinRoles = map[string]RoleStyle{
+	"db":   {Icon: "💾", Color: "yellow", Keywords: []string{"db", "database", "sql", "mysql", "mariadb", "postgres", "postgresql", "pg", "mongo", "mongodb", "redis"}},
+	"web":  {Icon: "🌍", Color: "teal", Keywords: []string{"web", "www", "nginx", "apache", "http", "frontend", "lb"}},
+	"k8s":  {Icon: "🚢", Color: "blue", Keywords: []string{"k8s", "k3s", "kube", "kubernetes"}},
+	"gpu":  {Icon: "🧠", Color: "purple", Keywords: []string{"gpu", "cuda", "ml"}},
+	"mail": {Icon: "📧", Color: "green", Keywords: []string{"mail", "smtp", "imap", "mx", "postfix"}},
+}
+
+// newRoleSet merges configured roles over the built-in ones. Roles only the
+// config knows are matched after the built-in ones, in name order.
+func newRoleSet(configured map[string]RoleStyle) roleSet {
+	set := roleSet{names: slices.Clone(builtinRoleNames), styles: map[string]RoleStyle{}}
+	for name, style := range builtinRoles {
+		set.styles[name] = style
+	}
+	var added []string
+	for name, style := range configured {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		base, known := set.styles[name]
+		if !known {
+			added = append(added, name)
+		}
+		if style.Icon != "" {
+			base.Icon = style.Icon
+		}
+		if style.Color != "" {
+			base.Color = style.Color
+		}
+		if style.Keywords != nil {
+			base.Keywords = style.Keywords
+		}
+		set.styles[name] = base
+	}
+	slices.Sort(added)
+	set.names = append(set.names, added...)
+	return set
+}
+
+// loadRoles reads the "roles" section; a missing or unreadable config
+// leaves the built-in roles.
+func loadRoles() roleSet {
+	cfg, err := loadConfigFile()
+	if err != nil {
+		return newRoleSet(nil)
+	}
+	return newRoleSet(cfg.Roles)
+}
+
+// roleWords splits the alias and hostname into lowercase words with trailing
+// digits dropped, so web-01.prod yields web and prod.
+func roleWords(h Host) []string {
+	split := func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }
+	var words []string
+	for _, field := range strings.FieldsFunc(strings.ToLower(h.Alias+" "+bareHostname(h.Hostname)), split) {
+		if word := strings.TrimRightFunc(field, unicode.IsDigit); word != "" {
+			words = append(words, word)
+		}
+	}
+	return words
+}
+
+// roleOf returns the host's explicit role, or the first role with a keyword
+// among its words.
+func (s roleSet) roleOf(h Host) string {
+	if role := strings.ToLower(strings.TrimSpace(h.Role)); role != "" {
+		return role
+	}
+	words := roleWords(h)
+	for _, name := range s.names {
+		for _, keyword := range s.styles[name].Keywords {
+			if slices.Contains(words, strings.ToLower(keyword)) {
+				return name
+			}
+		}
+	}
+	return ""
+}
+
+// style returns the icon and color of the host's role.
+func (s roleSet) style(h Host) (string, lipgloss.Color, bool) {
+	role := s.roleOf(h)
+	if role == "" {
+		return "", "", false
+	}
+	style := s.styles[role]
+	c, ok := groupColor(style.Color)
+	return style.Icon, c, ok
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestRoleOfInfersFromNames(t *testing.T) {
+	roles := newRoleSet(nil)
+	cases := []struct {
+		host Host
+		want string
+	}{
+		{Host{Alias: "db01", Hostname: "10.0.0.5"}, "db"},
+		{Host{Alias: "prod-1", Hostname: "k8s-worker-3.example.com"}, "k8s"},
+		{Host{Alias: "mx2", Hostname: "mail.example.com"}, "mail"},
+		{Host{Alias: "webhook", Hostname: "hooks.example.com"}, ""},
+		{Host{Alias: "db01", Hostname: "db01", Role: "GPU"}, "gpu"},
+	}
+	for _, c := range cases {
+		if got := roles.roleOf(c.host); got != c.want {
+			t.Errorf("roleOf(%s/%s) = %q, want %q", c.host.Alias, c.host.Hostname, got, c.want)
+		}
+	}
+}
+
+func TestRolesFromConfig(t *testing.T) {
+	writeTempConfig(t, nil)
+	data, err := os.ReadFile(getConfigPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := strings.Replace(string(data), "{", `{"roles": {"db": {"icon": "🐘"}, "ci": {"icon": "🤖", "color": "orange", "keywords": ["jenkins"]}},`, 1)
+	if err := os.WriteFile(getConfigPath(), []byte(cfg), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	roles := loadRoles()
+	if icon, c, ok := roles.style(Host{Alias: "pg1", Hostname: "pg1"}); icon != "🐘" || !ok || c != groupPalette["yellow"] {
+		t.Fatalf("expected the db icon overridden and its color kept, got %q %q %v", icon, c, ok)
+	}
+	if icon, c, _ := roles.style(Host{Alias: "jenkins-2"}); icon != "🤖" || c != groupPalette["orange"] {
+		t.Fatalf("expected the configured ci role, got %q %q", icon, c)
+	}
+
+	// Saving from the dashboard keeps the hand-edited section.
+	if err := saveConfig(nil, []Host{{ID: "h1", Alias: "a", Hostname: "a"}}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := loadRoles().styles["ci"].Icon; got != "🤖" {
+		t.Fatalf("expected roles kept across saves, got %q", got)
+	}
+}
+
+func TestDelegateShowsRoleIcon(t *testing.T) {
+	hosts := []Host{{ID: "h1", Alias: "db01", Hostname: "db01.example.com", User: "root"}}
+	m := model{rawHosts: hosts, list: newTestListModel(nil, hosts), roles: newRoleSet(nil)}
+	m.refreshDelegate()
+	if view := m.list.View(); !strings.Contains(view, "db01 💾") {
+		t.Fatalf("expected the db icon after the alias\n%s", view)
+	}
+}
@@ -240,3 +240,55 @@ func TestUpdateEnterRollsBackHistoryOnSaveError(t *testing.T) {
 		t.Fatalf("expected visible history save error, got status=%q", got.status.message)
 	}
 }
+
+func TestMoveItemAcrossGroups(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("ASSHO_STORE_PASSWORD", "0")
+
+	groups := []Group{
+		{ID: "g1", Name: "alpha", Expanded: true},
+		{ID: "s1", Name: "roots", Query: "user=root"},
+		{ID: "g2", Name: "beta"},
+	}
+	hosts := []Host{
+		{ID: "h1", Alias: "mover", Hostname: "10.0.0.1"},
+		{ID: "h2", Alias: "a-host", Hostname: "10.0.0.2", GroupID: "g1"},
+	}
+	m := model{
+		rawGroups:   groups,
+		rawHosts:    hosts,
+		list:        newTestListModel(groups, hosts),
+		historyList: newTestHistoryListModel(),
+	}
+
+	m.reselectItem("h1", false)
+	if msg := m.moveItemAcross(-1); msg != "" || m.rawHosts[0].GroupID != "" {
+		t.Fatalf("expected no-op moving left out of ungrouped, got %q %+v", msg, m.rawHosts[0])
+	}
+	if msg := m.moveItemAcross(+1); msg != "" {
+		t.Fatalf("unexpected error: %s", msg)
+	}
+	if m.rawHosts[1].ID != "h1" || m.rawHosts[1].GroupID != "g1" {
+		t.Fatalf("expected h1 at the bottom of alpha, got %+v", m.rawHosts)
+	}
+	if h, ok := m.list.SelectedItem().(Host); !ok || h.ID != "h1" {
+		t.Fatalf("expected moved host to stay selected, got %#v", m.list.SelectedItem())
+	}
+
+	// Smart groups are skipped; the collapsed target expands.
+	if msg := m.moveItemAcross(+1); msg != "" {
+		t.Fatalf("unexpected error: %s", msg)
+	}
+	if m.rawHosts[1].GroupID != "g2" || !m.rawGroups[2].Expanded {
+		t.Fatalf("expected h1 in expanded beta, got %+v %+v", m.rawHosts[1], m.rawGroups[2])
+	}
+	if msg := m.moveItemAcross(+1); msg != "" || m.rawHosts[1].GroupID != "g2" {
+		t.Fatalf("expected no-op past the last group, got %q", msg)
+	}
+
+	cfg, err := loadConfigFile()
+	if err != nil || cfg.Hosts[1].GroupID != "g2" {
+		t.Fatalf("expected group change persisted, got %+v %v", cfg.Hosts, err)
+	}
+}
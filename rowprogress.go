@@ -0,0 +1,65 @@
+package main
+
+import (
+	"github.com/charmbracelet/x/ansi"
+)
+
+// --- Row Progress ---
+
+// Scans, tests, transfers, and compose runs show on the row of the host they
+// are for: a spinner and what is happening, such as "testing" or a
+// transfer's percentage, so several at once can be told apart. Members of a
+// group test or scan that have not answered yet spin too, once the summary
+// screen is left. The rows are redrawn with each spinner frame while any of
+// them is busy.
+
+// maxRowProgress caps the progress text shown after a row's spinner.
+const maxRowProgress = 24
+
+// rowLabel is what a running task shows on its host's row.
+func (t task) rowLabel() string {
+	label := "scanning"
+	switch t.kind {
+	case taskTest:
+		label = "testing"
+	case taskTransfer:
+		label = "copying"
+	case taskCompose:
+		label = "compose"
+	}
+	if t.progress != "" {
+		label += " · " + ansi.Truncate(t.progress, maxRowProgress, "…")
+	}
+	return label
+}
+
+// rowActivity maps the IDs of busy hosts to what their rows show.
+func (m model) rowActivity() map[string]string {
+	busy := map[string]string{}
+	for id := range m.expandRefresh {
+		busy[id] = "refreshing"
+	}
+	if m.groupRun.kind != groupRunExport {
+		label := "testing"
+		if m.groupRun.kind == groupRunScan {
+			label = "scanning"
+		}
+		for _, r := range m.groupRun.results {
+			if !r.done {
+				busy[r.hostID] = label
+			}
+		}
+	}
+	for _, t := range m.tasks.tasks {
+		if t.status == taskRunning && t.hostID != "" {
+			busy[t.hostID] = t.rowLabel()
+		}
+	}
+	return busy
+}
+
+// rowSpinner is the row spinner's current frame. The spinner's own styling
+// would end the row's, so it is drawn plain.
+func (m model) rowSpinner() string {
+	return ansi.Strip(m.spinner.View())
+}
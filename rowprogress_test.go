@@ -0,0 +1,51 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestBusyRowsShowTheirOwnProgress(t *testing.T) {
+	hosts := []Host{{ID: "h1", Alias: "web"}, {ID: "h2", Alias: "db"}, {ID: "h3", Alias: "cache"}}
+	m := model{state: stateList, rawHosts: hosts, list: newTestListModel(nil, hosts), historyList: newTestHistoryListModel()}
+	m.spinner.Spinner.Frames = []string{"*"}
+	m.tasks.start(taskScan, "h1", "web", nil)
+	m.tasks.start(taskTransfer, "h2", "db", nil)
+	m.tasks.setProgress(taskTransfer, "h2", "42%")
+
+	result, _ := m.Update(spinner.TickMsg{})
+	got := result.(model)
+	out := ansi.Strip(got.list.View())
+	if !strings.Contains(out, "* scanning") || !strings.Contains(out, "* copying · 42%") {
+		t.Fatalf("expected each busy row labelled\n%s", out)
+	}
+	if strings.Count(out, "*") != 2 {
+		t.Fatalf("expected the idle row left alone\n%s", out)
+	}
+
+	got.tasks.finish(taskScan, "h1", nil)
+	got.tasks.finish(taskTransfer, "h2", nil)
+	result, _ = got.Update(spinner.TickMsg{})
+	got = result.(model)
+	if out := ansi.Strip(got.list.View()); strings.Contains(out, "*") {
+		t.Fatalf("expected the spinners gone once the tasks finished\n%s", out)
+	}
+}
+
+func TestGroupRunMembersSpinUntilAnswered(t *testing.T) {
+	m := model{groupRun: groupRunState{kind: groupRunScan, results: []groupRunResult{
+		{hostID: "h1", done: true},
+		{hostID: "h2"},
+	}}}
+	busy := m.rowActivity()
+	if len(busy) != 1 || busy["h2"] != "scanning" {
+		t.Fatalf("expected only the pending member busy, got %v", busy)
+	}
+	m.groupRun.kind = groupRunExport
+	if busy := m.rowActivity(); len(busy) != 0 {
+		t.Fatalf("expected an export to mark no rows, got %v", busy)
+	}
+}
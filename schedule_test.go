@@ -0,0 +1,60 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCronMatches(t *testing.T) {
+	at := func(s string) time.Time {
+		t.Helper()
+		v, err := time.ParseInLocation("2006-01-02 15:04", s, time.Local)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+	cases := []struct {
+		spec string
+		time string
+		want bool
+	}{
+		{"*/15 * * * *", "2026-10-16 09:45", true},
+		{"*/15 * * * *", "2026-10-16 09:46", false},
+		{"0 9-17/4 * * 1-5", "2026-10-16 13:00", true}, // a Friday
+		{"0 9-17/4 * * 1-5", "2026-10-17 13:00", false},
+		{"30 2 * * 7", "2026-10-18 02:30", true}, // 7 is Sunday too
+		{"0 0 1 * 1", "2026-10-19 00:00", true},  // either day field may match
+		{"0 0 1 * 1", "2026-10-20 00:00", false},
+		{"5,35 * * 10 *", "2026-10-16 11:35", true},
+		{"@daily", "2026-10-16 00:00", true},
+		{"@hourly", "2026-10-16 00:30", false},
+	}
+	for _, c := range cases {
+		s, err := parseCron(c.spec)
+		if err != nil {
+			t.Fatalf("parseCron(%q): %v", c.spec, err)
+		}
+		if got := s.matches(at(c.time)); got != c.want {
+			t.Errorf("%q at %s = %v, want %v", c.spec, c.time, got, c.want)
+		}
+	}
+
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "* * 0 * *", "*/0 * * * *", "5-1 * * * *", "a * * * *"} {
+		if _, err := parseCron(spec); err == nil {
+			t.Errorf("expected %q to be rejected", spec)
+		}
+	}
+}
+
+func TestCronNext(t *testing.T) {
+	s, _ := parseCron("0 6 * * *")
+	from := time.Date(2026, 10, 16, 6, 0, 30, 0, time.Local)
+	if got := s.next(from); !got.Equal(time.Date(2026, 10, 17, 6, 0, 0, 0, time.Local)) {
+		t.Fatalf("next = %v", got)
+	}
+	never, _ := parseCron("0 0 30 2 *")
+	if !never.next(from).IsZero() {
+		t.Fatal("February 30th should never come")
+	}
+}
@@ -0,0 +1,240 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// --- Secret Audit ---
+
+// H on the dashboard lists credentials that need attention: passwords kept in
+// plaintext in hosts.json, keychain references that no longer resolve, key
+// files other users can read, and keys older than ASSHO_KEY_MAX_AGE years
+// (default 2). Enter applies the fix for the selected row.
+
+type secretAuditKind int
+
+const (
+	auditPlaintextPassword secretAuditKind = iota
+	auditMissingSecret
+	auditKeyPermissions
+	auditOldKey
+)
+
+// defaultKeyMaxAgeYears is used when ASSHO_KEY_MAX_AGE is unset or invalid.
+const defaultKeyMaxAgeYears = 2
+
+type secretAuditIssue struct {
+	kind   secretAuditKind
+	hostID string
+	alias  string
+	path   string // key file, for the key checks
+	detail string
+}
+
+// fixLabel names what enter does for the issue.
+func (i secretAuditIssue) fixLabel() string {
+	switch i.kind {
+	case auditPlaintextPassword:
+		return "move to keychain"
+	case auditMissingSecret:
+		return "re-enter password"
+	case auditKeyPermissions:
+		return "chmod 600"
+	default:
+		return "rotate"
+	}
+}
+
+type secretAuditState struct {
+	issues []secretAuditIssue
+	cursor int
+}
+
+func keyMaxAge() time.Duration {
+	years, err := strconv.Atoi(strings.TrimSpace(os.Getenv("ASSHO_KEY_MAX_AGE")))
+	if err != nil || years <= 0 {
+		years = defaultKeyMaxAgeYears
+	}
+	return time.Duration(years) * 365 * 24 * time.Hour
+}
+
+// auditSecrets checks hosts as loaded, reading keychain entries to confirm
+// they exist. plaintext holds the IDs whose password is stored in hosts.json
+// itself, which a host cannot tell apart from one typed in this run.
+func auditSecrets(hosts []Host, plaintext map[string]bool, now time.Time, maxAge time.Duration) []secretAuditIssue {
+	var issues []secretAuditIssue
+	for _, h := range hosts {
+		if h.IsContainer {
+			continue
+		}
+		switch {
+		case plaintext[h.ID]:
+			issues = append(issues, secretAuditIssue{kind: auditPlaintextPassword, hostID: h.ID, alias: h.Alias, detail: "password stored in plaintext in hosts.json"})
+		case h.PasswordRef != "" && !secretsOffline():
+			if _, err := hostPassword(h); err != nil {
+				issues = append(issues, secretAuditIssue{kind: auditMissingSecret, hostID: h.ID, alias: h.Alias, detail: "keychain entry " + h.PasswordRef + " could not be read"})
+			}
+		}
+		if h.IdentityFile == "" {
+			continue
+		}
+		path := expandPath(h.IdentityFile)
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		if mode := info.Mode().Perm(); mode&0o077 != 0 {
+			issues = append(issues, secretAuditIssue{kind: auditKeyPermissions, hostID: h.ID, alias: h.Alias, path: path, detail: fmt.Sprintf("%s is mode %04o; ssh refuses keys others can read", h.IdentityFile, mode)})
+		}
+		if age := now.Sub(info.ModTime()); age > maxAge {
+			issues = append(issues, secretAuditIssue{kind: auditOldKey, hostID: h.ID, alias: h.Alias, path: path, detail: fmt.Sprintf("%s is %.1f years old", h.IdentityFile, age.Hours()/24/365)})
+		}
+	}
+	return issues
+}
+
+// plaintextPasswordIDs reads hosts.json directly, since loadConfig hydrates
+// keychain passwords into the same field.
+func plaintextPasswordIDs() map[string]bool {
+	ids := map[string]bool{}
+	cfg, err := loadConfigFile()
+	if err != nil {
+		return ids
+	}
+	for _, h := range cfg.Hosts {
+		if h.Password != "" {
+			ids[h.ID] = true
+		}
+	}
+	return ids
+}
+
+func (m *model) runSecretAudit() {
+	m.secretAudit.issues = auditSecrets(m.rawHosts, plaintextPasswordIDs(), time.Now(), keyMaxAge())
+	m.secretAudit.cursor = min(m.secretAudit.cursor, max(len(m.secretAudit.issues)-1, 0))
+}
+
+func (m model) openSecretAudit() (tea.Model, tea.Cmd) {
+	m.secretAudit = secretAuditState{}
+	m.runSecretAudit()
+	m.state = stateSecretAudit
+	return m, nil
+}
+
+func (m model) updateSecretAudit(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		m.quitting = true
+		return m, tea.Quit
+	case "esc", "q":
+		m.state = stateList
+	case "up", "k":
+		if m.secretAudit.cursor > 0 {
+			m.secretAudit.cursor--
+		}
+	case "down", "j":
+		if m.secretAudit.cursor < len(m.secretAudit.issues)-1 {
+			m.secretAudit.cursor++
+		}
+	case "enter":
+		if m.secretAudit.cursor < len(m.secretAudit.issues) {
+			return m.fixSecretAuditIssue(m.secretAudit.issues[m.secretAudit.cursor])
+		}
+	}
+	return m, nil
+}
+
+func (m model) fixSecretAuditIssue(issue secretAuditIssue) (tea.Model, tea.Cmd) {
+	idx := findHostIndexByID(m.rawHosts, issue.hostID)
+	if idx == -1 {
+		return m, nil
+	}
+	switch issue.kind {
+	case auditPlaintextPassword:
+		if secretBackend() == secretBackendConfig {
+			m.status.message = "ASSHO_SECRET_BACKEND=config keeps passwords in hosts.json"
+			m.status.isError = true
+			m.status.version++
+			return m, statusClearCmd(m.status.version)
+		}
+		if err := storePasswordSecret(issue.hostID, m.rawHosts[idx].Password); err != nil {
+			m.status.message = fmt.Sprintf("Keychain unavailable: %v", err)
+			m.status.isError = true
+			m.status.version++
+			return m, statusClearCmd(m.status.version)
+		}
+		m.rawHosts[idx].PasswordRef = issue.hostID
+		if err := m.save(); err != nil {
+			m.status.message = fmt.Sprintf("Failed to save: %v", err)
+			m.status.isError = true
+			m.status.version++
+			return m, statusClearCmd(m.status.version)
+		}
+		m.status.message = "Moved the password for " + issue.alias + " to the keychain"
+	case auditMissingSecret:
+		h := m.rawHosts[idx]
+		m.state = stateForm
+		m.form.selectedHost = &h
+		m.form.inputs = newFormInputs()
+		m.populateForm(h)
+		m.form.focus = controlPassword
+		return m, m.focusInputs()
+	case auditKeyPermissions:
+		if err := os.Chmod(issue.path, 0600); err != nil {
+			m.status.message = fmt.Sprintf("chmod failed: %v", err)
+			m.status.isError = true
+			m.status.version++
+			return m, statusClearCmd(m.status.version)
+		}
+		m.status.message = "Restricted " + issue.path + " to mode 0600"
+	case auditOldKey:
+		next, cmd := m.openRotation()
+		rotated := next.(model)
+		rotated.rotation.selected[issue.hostID] = true
+		return rotated, cmd
+	}
+	m.status.isError = false
+	m.status.version++
+	m.runSecretAudit()
+	return m, statusClearCmd(m.status.version)
+}
+
+func (m model) renderSecretAuditView() string {
+	width, height := normalizedSize(m.width, m.height)
+	inner := max(min(width-6, 100)-6, 24)
+	var b strings.Builder
+	b.WriteString(lipgloss.NewStyle().Bold(true).Foreground(colorText).Render("SECRET AUDIT") + "\n")
+	if len(m.secretAudit.issues) == 0 {
+		b.WriteString(testSuccessStyle.Render(ansi.Truncate("✔ No credential issues found", inner, "…")) + "\n")
+		b.WriteString("\n" + helpEntry("esc", "back"))
+		return centeredWorkspace(b.String(), width, height)
+	}
+	b.WriteString(formHintStyle.Render(ansi.Truncate(fmt.Sprintf("%d credential issues", len(m.secretAudit.issues)), inner, "…")) + "\n\n")
+
+	// Each issue takes two lines: the host and fix, then what is wrong.
+	maxIssues := max((height-12)/2, 2)
+	start := 0
+	if m.secretAudit.cursor >= maxIssues {
+		start = m.secretAudit.cursor - maxIssues + 1
+	}
+	end := min(start+maxIssues, len(m.secretAudit.issues))
+	for idx := start; idx < end; idx++ {
+		i := m.secretAudit.issues[idx]
+		label := fmt.Sprintf("%s · enter: %s", i.alias, i.fixLabel())
+		b.WriteString(selectionLine(idx == m.secretAudit.cursor, ansi.Truncate(label, inner-2, "…")) + "\n")
+		b.WriteString(testFailStyle.Render(ansi.Truncate("    "+i.detail, inner, "…")) + "\n")
+	}
+	if end < len(m.secretAudit.issues) {
+		b.WriteString(formHintStyle.Render(fmt.Sprintf("… %d more", len(m.secretAudit.issues)-end)) + "\n")
+	}
+	b.WriteString("\n" + helpEntry("enter", "fix") + "  " + helpEntry("esc", "back"))
+	return centeredWorkspace(b.String(), width, height)
+}
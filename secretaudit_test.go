@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestAuditSecrets(t *testing.T) {
+	dir := t.TempDir()
+	open := filepath.Join(dir, "id_open")
+	old := filepath.Join(dir, "id_old")
+	fine := filepath.Join(dir, "id_fine")
+	for path, mode := range map[string]os.FileMode{open: 0644, old: 0600, fine: 0600} {
+		if err := os.WriteFile(path, []byte("key"), mode); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chmod(path, mode); err != nil {
+			t.Fatal(err)
+		}
+	}
+	now := time.Now()
+	if err := os.Chtimes(old, now, now.AddDate(-3, 0, 0)); err != nil {
+		t.Fatal(err)
+	}
+	hosts := []Host{
+		{ID: "h1", Alias: "plain", Password: "pw"},
+		{ID: "h2", Alias: "lost", PasswordRef: "h2"},
+		{ID: "h3", Alias: "open", IdentityFile: open},
+		{ID: "h4", Alias: "old", IdentityFile: old},
+		{ID: "h5", Alias: "fine", IdentityFile: fine, Password: "pw", PasswordRef: "h5"},
+		{ID: "h6", Alias: "gone", IdentityFile: filepath.Join(dir, "missing")},
+	}
+	issues := auditSecrets(hosts, map[string]bool{"h1": true}, now, keyMaxAge())
+	want := []struct {
+		kind   secretAuditKind
+		hostID string
+	}{
+		{auditPlaintextPassword, "h1"},
+		{auditMissingSecret, "h2"},
+		{auditKeyPermissions, "h3"},
+		{auditOldKey, "h4"},
+	}
+	if len(issues) != len(want) {
+		t.Fatalf("expected %d issues, got %+v", len(want), issues)
+	}
+	for i, w := range want {
+		if issues[i].kind != w.kind || issues[i].hostID != w.hostID {
+			t.Fatalf("issue %d: expected %v for %s, got %+v", i, w.kind, w.hostID, issues[i])
+		}
+	}
+}
+
+func TestKeyMaxAge(t *testing.T) {
+	t.Setenv("ASSHO_KEY_MAX_AGE", "5")
+	if got := keyMaxAge(); got != 5*365*24*time.Hour {
+		t.Fatalf("expected five years, got %v", got)
+	}
+	t.Setenv("ASSHO_KEY_MAX_AGE", "soon")
+	if got := keyMaxAge(); got != defaultKeyMaxAgeYears*365*24*time.Hour {
+		t.Fatalf("expected the default for an invalid value, got %v", got)
+	}
+}
+
+func TestSecretAuditFixesKeyPermissions(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("ASSHO_STORE_PASSWORD", "0")
+	key := filepath.Join(t.TempDir(), "id_ed25519")
+	if err := os.WriteFile(key, []byte("key"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(key, 0644); err != nil {
+		t.Fatal(err)
+	}
+	hosts := []Host{{ID: "h1", Alias: "web", IdentityFile: key}}
+	m := model{rawHosts: hosts, list: newTestListModel(nil, hosts), historyList: newTestHistoryListModel()}
+	next, _ := m.openSecretAudit()
+	m = next.(model)
+	if m.state != stateSecretAudit || len(m.secretAudit.issues) != 1 {
+		t.Fatalf("expected one issue, got %+v", m.secretAudit.issues)
+	}
+	next, _ = m.updateSecretAudit(tea.KeyMsg{Type: tea.KeyEnter})
+	m = next.(model)
+	info, err := os.Stat(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 || len(m.secretAudit.issues) != 0 {
+		t.Fatalf("expected the key restricted and the issue gone, got mode %04o and %+v", info.Mode().Perm(), m.secretAudit.issues)
+	}
+}
+
+func TestSecretAuditOldKeyOpensRotation(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	m := model{secretAudit: secretAuditState{issues: []secretAuditIssue{{kind: auditOldKey, hostID: "h1", alias: "web"}}}}
+	m.rawHosts = []Host{{ID: "h1", Alias: "web", Hostname: "10.0.0.1"}}
+	next, _ := m.updateSecretAudit(tea.KeyMsg{Type: tea.KeyEnter})
+	m = next.(model)
+	if m.state != stateRotation || !m.rotation.selected["h1"] {
+		t.Fatalf("expected rotation with the host selected, got state %v and %v", m.state, m.rotation.selected)
+	}
+}
+
+func TestSecretAuditViewFitsTerminal(t *testing.T) {
+	var issues []secretAuditIssue
+	for i := range 12 {
+		issues = append(issues, secretAuditIssue{kind: secretAuditKind(i % 4), hostID: fmt.Sprint(i), alias: fmt.Sprintf("very-long-audited-host-alias-%02d", i), detail: "~/.ssh/id_rsa_legacy_production_deploy is mode 0644; ssh refuses keys others can read"})
+	}
+	for _, size := range []struct{ width, height int }{{40, 16}, {80, 24}, {140, 40}} {
+		m := model{width: size.width, height: size.height, secretAudit: secretAuditState{issues: issues, cursor: 10}}
+		out := m.renderSecretAuditView()
+		lines := strings.Split(out, "\n")
+		if len(lines) > size.height {
+			t.Fatalf("%dx%d: got %d lines", size.width, size.height, len(lines))
+		}
+		for i, line := range lines {
+			if ansi.StringWidth(line) > size.width {
+				t.Fatalf("%dx%d line %d has width %d", size.width, size.height, i, ansi.StringWidth(line))
+			}
+		}
+		if !strings.Contains(out, "› ") {
+			t.Fatalf("%dx%d: expected the cursor row to stay visible", size.width, size.height)
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// --- Secret Cache ---
+
+// Keychain passwords are read when a host needs one (connecting, testing,
+// editing, and the like) rather than for every host before the dashboard
+// shows, and each is read at most once per run. Right after startup the
+// TUI also warms the cache in the background, a few lookups at a time and
+// within secretPrefetchBudget, so the first connection rarely waits on the
+// keychain; anything left over is read on use. ASSHO_SECRETS_OFFLINE=1 keeps
+// assho away from the secret backend altogether: stored passwords stay where
+// they are, ssh prompts for them instead, and passwords typed meanwhile last
+// for the session only.
+
+const (
+	secretPrefetchWorkers = 4
+	secretPrefetchBudget  = 10 * time.Second
+)
+
+var errSecretsOffline = errors.New("secret backend is offline (ASSHO_SECRETS_OFFLINE)")
+
+// passwordCache holds the secrets read or stored this run, by ref.
+var passwordCache = struct {
+	sync.Mutex
+	secrets map[string]string
+}{secrets: map[string]string{}}
+
+type secretsPrefetchedMsg struct {
+	failed  []string // "alias": error, for lookups that failed
+	skipped int      // lookups not started within the budget
+}
+
+func secretsOffline() bool {
+	return envBool("ASSHO_SECRETS_OFFLINE", false)
+}
+
+// hostPassword returns h's password, reading it from the secret backend the
+// first time it is asked for.
+func hostPassword(h Host) (string, error) {
+	if h.Password != "" || h.PasswordRef == "" {
+		return h.Password, nil
+	}
+	if secretsOffline() {
+		return "", errSecretsOffline
+	}
+	if secret, ok := cachedPassword(h.PasswordRef); ok {
+		return secret, nil
+	}
+	secret, err := lookupPasswordSecret(h.PasswordRef)
+	if err != nil {
+		return "", err
+	}
+	cachePasswordSecret(h.PasswordRef, secret)
+	return secret, nil
+}
+
+// withPassword is h with its keychain password filled in. A password that
+// cannot be read is left out, so ssh falls back to prompting or to keys.
+func withPassword(h Host) Host {
+	h.Password, _ = hostPassword(h)
+	return h
+}
+
+func cachedPassword(ref string) (string, bool) {
+	passwordCache.Lock()
+	defer passwordCache.Unlock()
+	secret, ok := passwordCache.secrets[ref]
+	return secret, ok
+}
+
+func cachePasswordSecret(ref, password string) {
+	passwordCache.Lock()
+	defer passwordCache.Unlock()
+	if password == "" {
+		delete(passwordCache.secrets, ref)
+	} else {
+		passwordCache.secrets[ref] = password
+	}
+}
+
+// hydrateHostPasswords fills in the keychain password of every host and
+// container, reading them concurrently, and returns the lookups that failed.
+func hydrateHostPasswords(hosts []Host) ([]Host, []string) {
+	if secretsOffline() {
+		return hosts, nil
+	}
+	result := prefetchPasswords(hosts, secretPrefetchBudget, hostPassword)
+	var fill func([]Host)
+	fill = func(hosts []Host) {
+		for i := range hosts {
+			if secret, ok := cachedPassword(hosts[i].PasswordRef); ok && hosts[i].Password == "" {
+				hosts[i].Password = secret
+			}
+			fill(hosts[i].Containers)
+		}
+	}
+	fill(hosts)
+	return hosts, result.failed
+}
+
+// prefetchPasswords looks up the password of every host with a keychain ref
+// using a few workers. Lookups not started before budget runs out are
+// skipped and counted.
+func prefetchPasswords(hosts []Host, budget time.Duration, lookup func(Host) (string, error)) secretsPrefetchedMsg {
+	var pending []Host
+	seen := map[string]bool{}
+	walkHosts(hosts, func(h Host) {
+		if h.Password == "" && h.PasswordRef != "" && !seen[h.PasswordRef] {
+			seen[h.PasswordRef] = true
+			pending = append(pending, h)
+		}
+	})
+	var msg secretsPrefetchedMsg
+	if len(pending) == 0 {
+		return msg
+	}
+	deadline := time.Now().Add(budget)
+	jobs := make(chan Host)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for range min(secretPrefetchWorkers, len(pending)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for h := range jobs {
+				if _, err := lookup(h); err != nil {
+					mu.Lock()
+					msg.failed = append(msg.failed, fmt.Sprintf("%q: %v", h.Alias, err))
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	for i, h := range pending {
+		if time.Now().After(deadline) {
+			msg.skipped = len(pending) - i
+			break
+		}
+		jobs <- h
+	}
+	close(jobs)
+	wg.Wait()
+	return msg
+}
+
+// prefetchSecretsCmd warms the password cache for the hosts on the
+// dashboard without holding it up.
+func prefetchSecretsCmd(hosts []Host) tea.Cmd {
+	if secretsOffline() {
+		return nil
+	}
+	hosts = cloneHosts(hosts)
+	return func() tea.Msg {
+		return prefetchPasswords(hosts, secretPrefetchBudget, hostPassword)
+	}
+}
+
+func (m model) finishSecretsPrefetch(msg secretsPrefetchedMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case len(msg.failed) > 0:
+		m.status.message = "Keychain lookup failed: " + strings.Join(msg.failed, "; ")
+		m.status.isError = true
+	case msg.skipped > 0:
+		m.status.message = fmt.Sprintf("Keychain is slow; %d password(s) will be read when used", msg.skipped)
+		m.status.isError = false
+	default:
+		return m, nil
+	}
+	m.status.version++
+	return m, statusClearCmd(m.status.version)
+}
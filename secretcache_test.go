@@ -0,0 +1,93 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPrefetchPasswordsDedupesAndReportsFailures(t *testing.T) {
+	hosts := []Host{
+		{ID: "h1", Alias: "web", PasswordRef: "r1"},
+		{ID: "h2", Alias: "web-copy", PasswordRef: "r1"},
+		{ID: "h3", Alias: "plain", Password: "pw"},
+		{ID: "h4", Alias: "box", Containers: []Host{{ID: "c1", Alias: "db", PasswordRef: "r2"}}},
+	}
+	var mu sync.Mutex
+	var looked []string
+	msg := prefetchPasswords(hosts, time.Minute, func(h Host) (string, error) {
+		mu.Lock()
+		looked = append(looked, h.PasswordRef)
+		mu.Unlock()
+		if h.PasswordRef == "r2" {
+			return "", errors.New("locked")
+		}
+		return "secret", nil
+	})
+	if len(looked) != 2 {
+		t.Fatalf("expected one lookup per ref, got %v", looked)
+	}
+	if len(msg.failed) != 1 || !strings.Contains(msg.failed[0], `"db": locked`) || msg.skipped != 0 {
+		t.Fatalf("unexpected result %+v", msg)
+	}
+}
+
+func TestPrefetchPasswordsRunsConcurrently(t *testing.T) {
+	var hosts []Host
+	for _, ref := range []string{"a", "b", "c", "d", "e", "f"} {
+		hosts = append(hosts, Host{Alias: ref, PasswordRef: ref})
+	}
+	var running, peak atomic.Int32
+	prefetchPasswords(hosts, time.Minute, func(Host) (string, error) {
+		n := running.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		running.Add(-1)
+		return "pw", nil
+	})
+	if got := peak.Load(); got < 2 || got > secretPrefetchWorkers {
+		t.Fatalf("expected between 2 and %d lookups at once, got %d", secretPrefetchWorkers, got)
+	}
+}
+
+func TestPrefetchPasswordsSkipsPastBudget(t *testing.T) {
+	hosts := []Host{{Alias: "a", PasswordRef: "a"}, {Alias: "b", PasswordRef: "b"}}
+	msg := prefetchPasswords(hosts, -time.Second, func(Host) (string, error) {
+		t.Fatal("no lookup should start once the budget is spent")
+		return "", nil
+	})
+	if msg.skipped != 2 {
+		t.Fatalf("expected both lookups skipped, got %+v", msg)
+	}
+}
+
+func TestFinishSecretsPrefetch(t *testing.T) {
+	m := model{}
+	next, cmd := m.finishSecretsPrefetch(secretsPrefetchedMsg{})
+	if cmd != nil || next.(model).status.message != "" {
+		t.Fatal("a clean prefetch should stay quiet")
+	}
+	next, _ = m.finishSecretsPrefetch(secretsPrefetchedMsg{skipped: 3})
+	if got := next.(model).status; got.isError || !strings.Contains(got.message, "3 password(s)") {
+		t.Fatalf("unexpected status %+v", got)
+	}
+	next, _ = m.finishSecretsPrefetch(secretsPrefetchedMsg{failed: []string{`"db": locked`}})
+	if got := next.(model).status; !got.isError || !strings.Contains(got.message, `"db": locked`) {
+		t.Fatalf("unexpected status %+v", got)
+	}
+}
+
+func TestPrefetchSecretsCmdOffline(t *testing.T) {
+	t.Setenv("ASSHO_SECRETS_OFFLINE", "1")
+	if prefetchSecretsCmd([]Host{{PasswordRef: "r1"}}) != nil {
+		t.Fatal("offline mode should not prefetch")
+	}
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// --- Secret Migration ---
+
+// Passwords live in the OS keychain by default, or in hosts.json itself with
+// ASSHO_SECRET_BACKEND=config. Changing the setting only affects passwords
+// saved afterwards, so `assho secrets migrate` moves the existing ones: each is
+// read from where it is now, stored under the configured backend (keychain
+// entries are re-keyed to the host ID), and the old copy is scrubbed once the
+// config has been saved. --dry-run prints the plan without touching anything.
+
+const (
+	secretBackendKeychain = "keychain"
+	secretBackendConfig   = "config"
+)
+
+// secretBackend reads ASSHO_SECRET_BACKEND; anything but "config" means the
+// keychain.
+func secretBackend() string {
+	if strings.ToLower(strings.TrimSpace(os.Getenv("ASSHO_SECRET_BACKEND"))) == secretBackendConfig {
+		return secretBackendConfig
+	}
+	return secretBackendKeychain
+}
+
+type secretMove struct {
+	hostID string
+	alias  string
+	from   string // "config" or "keychain"
+	oldRef string // keychain entry to scrub after the move; "" when none
+	err    error
+}
+
+// planSecretMigration lists the hosts (and containers) whose password is not
+// stored the way backend expects.
+func planSecretMigration(hosts []Host, backend string) []secretMove {
+	var moves []secretMove
+	for _, h := range hosts {
+		switch {
+		case backend == secretBackendKeychain && h.Password != "":
+			moves = append(moves, secretMove{hostID: h.ID, alias: h.Alias, from: secretBackendConfig, oldRef: staleRef(h)})
+		case backend == secretBackendKeychain && h.PasswordRef != "" && h.PasswordRef != h.ID:
+			moves = append(moves, secretMove{hostID: h.ID, alias: h.Alias, from: secretBackendKeychain, oldRef: h.PasswordRef})
+		case backend == secretBackendConfig && h.PasswordRef != "":
+			moves = append(moves, secretMove{hostID: h.ID, alias: h.Alias, from: secretBackendKeychain, oldRef: h.PasswordRef})
+		}
+		moves = append(moves, planSecretMigration(h.Containers, backend)...)
+	}
+	return moves
+}
+
+// staleRef is the keychain entry a plaintext password leaves behind, if any.
+func staleRef(h Host) string {
+	if h.PasswordRef == h.ID {
+		return ""
+	}
+	return h.PasswordRef
+}
+
+// applySecretMoves rewrites the hosts in place. A move whose secret cannot be
+// read or stored keeps the host as it was and records the error.
+func applySecretMoves(hosts []Host, moves []secretMove, backend string, lookup func(string) (string, error), store func(string, string) error) {
+	byID := map[string]*Host{}
+	var index func([]Host)
+	index = func(hs []Host) {
+		for i := range hs {
+			byID[hs[i].ID] = &hs[i]
+			index(hs[i].Containers)
+		}
+	}
+	index(hosts)
+	for i := range moves {
+		mv := &moves[i]
+		h := byID[mv.hostID]
+		if h == nil {
+			mv.err = fmt.Errorf("host not found")
+			continue
+		}
+		password := h.Password
+		if password == "" {
+			secret, err := lookup(mv.oldRef)
+			if err != nil {
+				mv.err = fmt.Errorf("read from keychain: %w", err)
+				continue
+			}
+			password = secret
+		}
+		if backend == secretBackendConfig {
+			h.Password, h.PasswordRef = password, ""
+			continue
+		}
+		if err := store(h.ID, password); err != nil {
+			mv.err = fmt.Errorf("store in keychain: %w", err)
+			continue
+		}
+		h.Password, h.PasswordRef = "", h.ID
+	}
+}
+
+func fprintSecretPlan(w io.Writer, moves []secretMove, backend string) {
+	if len(moves) == 0 {
+		fmt.Fprintf(w, "All passwords are already stored in the %s.\n", backend)
+		return
+	}
+	fmt.Fprintf(w, "%-24s %-10s %-10s %s\n", "ALIAS", "FROM", "TO", "SCRUB")
+	fmt.Fprintln(w, strings.Repeat("-", 60))
+	for _, mv := range moves {
+		scrub := "—"
+		switch {
+		case mv.oldRef != "":
+			scrub = "keychain entry " + mv.oldRef
+		case mv.from == secretBackendConfig:
+			scrub = "plaintext in hosts.json"
+		}
+		fmt.Fprintf(w, "%-24s %-10s %-10s %s\n", mv.alias, mv.from, backend, scrub)
+	}
+}
+
+func cliSecrets(args []string) {
+	if len(args) == 0 || args[0] != "migrate" || len(args) > 2 || (len(args) == 2 && args[1] != "--dry-run") {
+		fmt.Fprintln(os.Stderr, "usage: assho secrets migrate [--dry-run]")
+		os.Exit(1)
+	}
+	if !shouldPersistPassword() {
+		fmt.Fprintln(os.Stderr, "password storage is disabled (ASSHO_STORE_PASSWORD); nothing to migrate")
+		os.Exit(1)
+	}
+	dryRun := len(args) == 2
+	cfg, err := loadConfigFile()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error loading config: %v\n", err)
+		os.Exit(1)
+	}
+	backend := secretBackend()
+	moves := planSecretMigration(cfg.Hosts, backend)
+	fprintSecretPlan(os.Stdout, moves, backend)
+	if dryRun || len(moves) == 0 {
+		return
+	}
+
+	applySecretMoves(cfg.Hosts, moves, backend, lookupPasswordSecret, storePasswordSecret)
+	if err := saveConfig(cfg.Groups, cfg.Hosts, cfg.History); err != nil {
+		fmt.Fprintf(os.Stderr, "error saving config: %v\n", err)
+		os.Exit(1)
+	}
+	// Old copies are removed only after the new ones are saved.
+	failed := 0
+	for _, mv := range moves {
+		if mv.err != nil {
+			failed++
+			fmt.Fprintf(os.Stderr, "✘ %s: %v\n", mv.alias, mv.err)
+			continue
+		}
+		if mv.oldRef != "" {
+			if err := deletePasswordSecret(mv.oldRef); err != nil {
+				fmt.Fprintf(os.Stderr, "⚠ %s: moved, but the old keychain entry remains: %v\n", mv.alias, err)
+			}
+		}
+	}
+	fmt.Printf("✔ Moved %d passwords to the %s\n", len(moves)-failed, backend)
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestPlanSecretMigration(t *testing.T) {
+	hosts := []Host{
+		{ID: "h1", Alias: "plain", Password: "pw"},
+		{ID: "h2", Alias: "stored", PasswordRef: "h2"},
+		{ID: "h3", Alias: "old-ref", PasswordRef: "legacy"},
+		{ID: "h4", Alias: "none", Containers: []Host{{ID: "c1", Alias: "db", Password: "pw"}}},
+	}
+
+	toKeychain := planSecretMigration(hosts, secretBackendKeychain)
+	if len(toKeychain) != 3 || toKeychain[0].alias != "plain" || toKeychain[1].oldRef != "legacy" || toKeychain[2].alias != "db" {
+		t.Fatalf("unexpected keychain plan %+v", toKeychain)
+	}
+	if toKeychain[0].from != secretBackendConfig || toKeychain[0].oldRef != "" {
+		t.Fatalf("expected the plaintext password moved without a scrub, got %+v", toKeychain[0])
+	}
+
+	toConfig := planSecretMigration(hosts, secretBackendConfig)
+	if len(toConfig) != 2 || toConfig[0].oldRef != "h2" || toConfig[1].oldRef != "legacy" {
+		t.Fatalf("unexpected config plan %+v", toConfig)
+	}
+}
+
+func TestApplySecretMovesToKeychain(t *testing.T) {
+	hosts := []Host{
+		{ID: "h1", Alias: "plain", Password: "pw1"},
+		{ID: "h2", Alias: "old-ref", PasswordRef: "legacy"},
+		{ID: "h3", Alias: "lost", PasswordRef: "missing"},
+	}
+	keychain := map[string]string{"legacy": "pw2"}
+	lookup := func(ref string) (string, error) {
+		if secret, ok := keychain[ref]; ok {
+			return secret, nil
+		}
+		return "", errors.New("not found")
+	}
+	store := func(ref, password string) error {
+		keychain[ref] = password
+		return nil
+	}
+	moves := planSecretMigration(hosts, secretBackendKeychain)
+	applySecretMoves(hosts, moves, secretBackendKeychain, lookup, store)
+
+	if hosts[0].Password != "" || hosts[0].PasswordRef != "h1" || keychain["h1"] != "pw1" {
+		t.Fatalf("expected the plaintext password moved to the keychain, got %+v", hosts[0])
+	}
+	if hosts[1].PasswordRef != "h2" || keychain["h2"] != "pw2" {
+		t.Fatalf("expected the entry re-keyed to the host ID, got %+v", hosts[1])
+	}
+	if hosts[2].PasswordRef != "missing" || moves[2].err == nil {
+		t.Fatalf("expected the unreadable entry left alone with an error, got %+v / %v", hosts[2], moves[2].err)
+	}
+}
+
+func TestApplySecretMovesToConfig(t *testing.T) {
+	hosts := []Host{{ID: "h1", Alias: "stored", PasswordRef: "h1"}}
+	lookup := func(string) (string, error) { return "pw", nil }
+	store := func(string, string) error { t.Fatal("config backend must not store in the keychain"); return nil }
+	moves := planSecretMigration(hosts, secretBackendConfig)
+	applySecretMoves(hosts, moves, secretBackendConfig, lookup, store)
+	if hosts[0].Password != "pw" || hosts[0].PasswordRef != "" || moves[0].err != nil {
+		t.Fatalf("expected the password written to the config, got %+v", hosts[0])
+	}
+}
+
+func TestSanitizeKeepsPlaintextWithConfigBackend(t *testing.T) {
+	t.Setenv("ASSHO_STORE_PASSWORD", "1")
+	t.Setenv("ASSHO_SECRET_BACKEND", "config")
+	got := sanitizeHostsForSave([]Host{{ID: "h1", Password: "pw", PasswordRef: "h1"}})
+	if got[0].Password != "pw" || got[0].PasswordRef != "" {
+		t.Fatalf("expected the password kept in the config, got %+v", got[0])
+	}
+}
+
+func TestFprintSecretPlan(t *testing.T) {
+	var buf bytes.Buffer
+	fprintSecretPlan(&buf, []secretMove{{alias: "web", from: secretBackendConfig}, {alias: "db", from: secretBackendKeychain, oldRef: "legacy"}}, secretBackendKeychain)
+	out := buf.String()
+	if !strings.Contains(out, "plaintext in hosts.json") || !strings.Contains(out, "keychain entry legacy") {
+		t.Fatalf("unexpected plan output:\n%s", out)
+	}
+	buf.Reset()
+	fprintSecretPlan(&buf, nil, secretBackendConfig)
+	if !strings.Contains(buf.String(), "already stored in the config") {
+		t.Fatalf("unexpected empty plan output %q", buf.String())
+	}
+}
+
+func TestHostPasswordOffline(t *testing.T) {
+	t.Setenv("ASSHO_SECRETS_OFFLINE", "1")
+	if _, err := hostPassword(Host{PasswordRef: "h1"}); !errors.Is(err, errSecretsOffline) {
+		t.Fatalf("expected the offline error, got %v", err)
+	}
+	if got := withPassword(Host{Password: "typed", PasswordRef: "h1"}); got.Password != "typed" {
+		t.Fatalf("a password already in memory should be used, got %q", got.Password)
+	}
+	got := sanitizeHostsForSave([]Host{{ID: "h1", Password: "typed", PasswordRef: "h1"}})
+	if got[0].Password != "" || got[0].PasswordRef != "h1" {
+		t.Fatalf("offline saves should neither write plaintext nor drop the ref, got %+v", got[0])
+	}
+}
+
+func TestHostPasswordUsesCache(t *testing.T) {
+	cachePasswordSecret("cached-ref", "s3cret")
+	t.Cleanup(func() { cachePasswordSecret("cached-ref", "") })
+	if got, err := hostPassword(Host{PasswordRef: "cached-ref"}); err != nil || got != "s3cret" {
+		t.Fatalf("got %q, %v", got, err)
+	}
+}
+
+func TestLoadConfigLeavesKeychainAlone(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("ASSHO_STORE_PASSWORD", "1")
+	t.Setenv("ASSHO_SECRETS_OFFLINE", "1")
+	if err := saveConfig(nil, []Host{{ID: "h1", Alias: "db", Hostname: "db", PasswordRef: "h1"}}, nil); err != nil {
+		t.Fatal(err)
+	}
+	_, hosts, _, err := loadConfig()
+	if err != nil || hosts[0].PasswordRef != "h1" || hosts[0].Password != "" {
+		t.Fatalf("expected the ref to load unread, got %+v, %v", hosts, err)
+	}
+}
+
+func TestEditingOfflineKeepsStoredPassword(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("ASSHO_STORE_PASSWORD", "1")
+	t.Setenv("ASSHO_SECRETS_OFFLINE", "1")
+	existing := Host{ID: "h1", Alias: "db", Hostname: "10.0.0.1", PasswordRef: "h1"}
+	m := model{rawHosts: []Host{existing}, form: formState{inputs: newFormInputs()}, historyList: newTestHistoryListModel()}
+	m.list = newTestListModel(nil, m.rawHosts)
+	m.form.selectedHost = &existing
+	m.populateForm(existing)
+	m.buildGroupOptions("")
+	if !m.form.keepPasswordRef {
+		t.Fatal("an unreadable password should be kept")
+	}
+	if err := m.saveFromForm(); err != nil {
+		t.Fatal(err)
+	}
+	if m.rawHosts[0].PasswordRef != "h1" {
+		t.Fatalf("saving offline dropped the keychain ref: %+v", m.rawHosts[0])
+	}
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// --- Security Keys ---
+
+// A FIDO2 identity (sk-ssh-ed25519 or sk-ecdsa) signs only after someone
+// touches the security key, so ssh waits for a touch on every login that
+// tries it. When a host's key file is one, connecting says a touch is
+// needed, and the detail pane and form mark it. Tests, scans, and the other
+// commands assho runs in the background leave sk keys out of the keys ssh
+// offers, so ssh moves on to other keys or the password instead of
+// waiting until the timeout for a touch nobody knows to give.
+
+// securityKeyTypes are the key and certificate types that need a touch.
+var securityKeyTypes = []string{
+	"sk-ssh-ed25519@openssh.com",
+	"sk-ssh-ed25519-cert-v01@openssh.com",
+	"sk-ecdsa-sha2-nistp256@openssh.com",
+	"sk-ecdsa-sha2-nistp256-cert-v01@openssh.com",
+}
+
+type keyTypeCacheKey struct {
+	path     string
+	modified time.Time
+}
+
+// keyTypeCache remembers identityKeyType by path and modification time,
+// since the detail pane and form ask on every render.
+var keyTypeCache sync.Map
+
+// identityKeyType is the type of the key in identity, read from its .pub
+// file or, failing that, from the public half of the OpenSSH private key,
+// which is stored unencrypted. It is "" when neither can be read.
+func identityKeyType(identity string) string {
+	path := expandPath(strings.TrimSpace(identity))
+	if path == "" {
+		return ""
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return ""
+	}
+	key := keyTypeCacheKey{path, info.ModTime()}
+	if cached, ok := keyTypeCache.Load(key); ok {
+		return cached.(string)
+	}
+	keyType := ""
+	if strings.HasSuffix(path, ".pub") {
+		keyType, _, _ = readPublicKey(path)
+	} else if pubType, _, err := readPublicKey(path + ".pub"); err == nil {
+		keyType = pubType
+	} else {
+		keyType = privateKeyType(path)
+	}
+	keyTypeCache.Store(key, keyType)
+	return keyType
+}
+
+// privateKeyType reads the key type from an "openssh-key-v1" private key.
+func privateKeyType(path string) string {
+	publicKey := privateKeyPublicBlob(path)
+	if publicKey == nil {
+		return ""
+	}
+	keyType, _, ok := readSSHString(publicKey)
+	if !ok {
+		return ""
+	}
+	return string(keyType)
+}
+
+// privateKeyPublicBlob returns the public key stored in the clear in an
+// "openssh-key-v1" private key, or nil.
+func privateKeyPublicBlob(path string) []byte {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "OPENSSH PRIVATE KEY" {
+		return nil
+	}
+	const magic = "openssh-key-v1\x00"
+	rest, ok := bytes.CutPrefix(block.Bytes, []byte(magic))
+	if !ok {
+		return nil
+	}
+	// Cipher name, KDF name, and KDF options come before the key count.
+	for range 3 {
+		if _, rest, ok = readSSHString(rest); !ok {
+			return nil
+		}
+	}
+	if len(rest) < 4 {
+		return nil
+	}
+	publicKey, _, ok := readSSHString(rest[4:])
+	if !ok {
+		return nil
+	}
+	return publicKey
+}
+
+// readSSHString splits a length-prefixed string off b.
+func readSSHString(b []byte) ([]byte, []byte, bool) {
+	if len(b) < 4 {
+		return nil, nil, false
+	}
+	n := binary.BigEndian.Uint32(b)
+	if uint64(n) > uint64(len(b)-4) {
+		return nil, nil, false
+	}
+	return b[4 : 4+n], b[4+n:], true
+}
+
+func isSecurityKeyType(keyType string) bool {
+	return strings.HasPrefix(keyType, "sk-")
+}
+
+// usesSecurityKey reports whether h's key file is a FIDO2 security key.
+func (h Host) usesSecurityKey() bool {
+	return h.IdentityFile != "" && isSecurityKeyType(identityKeyType(h.IdentityFile))
+}
+
+// securityKeyNotice is shown before a session with h starts, or "" when its
+// key needs no touch.
+func securityKeyNotice(h Host) string {
+	if !h.usesSecurityKey() {
+		return ""
+	}
+	return "Touch your security key when it blinks (" + filepath.Base(h.IdentityFile) + ")"
+}
+
+// skipSecurityKeyArgs keep ssh from offering sk keys to h where nobody is
+// there to touch the key. PubkeyAcceptedKeyTypes is the spelling every
+// OpenSSH with security key support understands.
+func skipSecurityKeyArgs(h Host) []string {
+	if !h.usesSecurityKey() {
+		return nil
+	}
+	return []string{"-o", "PubkeyAcceptedKeyTypes=-" + strings.Join(securityKeyTypes, ",")}
+}
+
+// securityKeySkipped adds to an authentication failure on h that its
+// security key was left out on purpose.
+func securityKeySkipped(h Host, message string) string {
+	if h.usesSecurityKey() && strings.Contains(message, "Permission denied") {
+		return message + " (security key " + filepath.Base(h.IdentityFile) + " skipped: it needs a touch, so only interactive sessions use it)"
+	}
+	return message
+}
@@ -0,0 +1,329 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// --- Self-Update ---
+
+// `assho update` replaces the running binary with the latest GitHub release
+// for this platform once its SHA-256 matches the release's checksums.txt.
+// Binaries a package manager owns are left to that manager. The TUI looks for
+// a newer release in the background at most once a day and names it in the
+// header; ASSHO_UPDATE_CHECK=0 turns that check off.
+
+const (
+	releaseRepo         = "allisonhere/assho"
+	releaseChecksums    = "checksums.txt"
+	updateCheckInterval = 24 * time.Hour
+	updateCheckTimeout  = 5 * time.Second
+	updateTimeout       = 2 * time.Minute
+	maxReleaseBinary    = 128 << 20
+)
+
+// releaseAPI is a variable so tests can point it at a local server.
+var releaseAPI = "https://api.github.com/repos/" + releaseRepo + "/releases/latest"
+
+type githubRelease struct {
+	TagName string `json:"tag_name"`
+	Assets  []struct {
+		Name string `json:"name"`
+		URL  string `json:"browser_download_url"`
+	} `json:"assets"`
+}
+
+// updateCheck is cached next to hosts.json so the TUI asks GitHub at most
+// once per updateCheckInterval.
+type updateCheck struct {
+	CheckedAt time.Time `json:"checked_at"`
+	Latest    string    `json:"latest"`
+}
+
+type updateAvailableMsg struct{ version string }
+
+func updateCheckEnabled() bool {
+	return envBool("ASSHO_UPDATE_CHECK", true)
+}
+
+func getUpdateCheckPath() string {
+	return filepath.Join(filepath.Dir(getConfigPath()), "update-check.json")
+}
+
+func releaseAssetName(goos, goarch string) string {
+	return "assho-" + goos + "-" + goarch
+}
+
+func (r githubRelease) assetURL(name string) string {
+	for _, a := range r.Assets {
+		if a.Name == name {
+			return a.URL
+		}
+	}
+	return ""
+}
+
+// parseVersion reads "v1.2.3" (a pre-release suffix is ignored); it fails
+// for development builds.
+func parseVersion(v string) ([3]int, bool) {
+	var parts [3]int
+	core, _, _ := strings.Cut(strings.TrimPrefix(strings.TrimSpace(v), "v"), "-")
+	fields := strings.Split(core, ".")
+	if len(fields) == 0 || len(fields) > 3 {
+		return parts, false
+	}
+	for i, f := range fields {
+		n, err := strconv.Atoi(f)
+		if err != nil || n < 0 {
+			return parts, false
+		}
+		parts[i] = n
+	}
+	return parts, true
+}
+
+// newerVersion reports whether latest is a later release than current.
+func newerVersion(latest, current string) bool {
+	l, ok := parseVersion(latest)
+	if !ok {
+		return false
+	}
+	c, ok := parseVersion(current)
+	if !ok {
+		return false
+	}
+	for i := range l {
+		if l[i] != c[i] {
+			return l[i] > c[i]
+		}
+	}
+	return false
+}
+
+// checksumFor finds name in sha256sum output.
+func checksumFor(checksums, name string) (string, bool) {
+	for _, line := range strings.Split(checksums, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == name {
+			return strings.ToLower(fields[0]), true
+		}
+	}
+	return "", false
+}
+
+// packageManager names the manager that owns the binary at path, if any.
+func packageManager(path string) string {
+	switch {
+	case strings.Contains(path, "/Cellar/") || strings.Contains(path, "/homebrew/") || strings.Contains(path, "/linuxbrew/"):
+		return "Homebrew"
+	case strings.HasPrefix(path, "/nix/store/"):
+		return "Nix"
+	case strings.Contains(path, "/scoop/"):
+		return "Scoop"
+	case strings.HasPrefix(path, "/usr/bin/") || strings.HasPrefix(path, "/bin/"):
+		return "your system package manager"
+	}
+	return ""
+}
+
+func httpGet(client *http.Client, url string, limit int64) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "assho/"+version)
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s returned %s", url, resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, limit))
+}
+
+func fetchLatestRelease(client *http.Client) (githubRelease, error) {
+	body, err := httpGet(client, releaseAPI, 1<<20)
+	if err != nil {
+		return githubRelease{}, err
+	}
+	var release githubRelease
+	if err := json.Unmarshal(body, &release); err != nil {
+		return githubRelease{}, fmt.Errorf("unexpected release response: %v", err)
+	}
+	if release.TagName == "" {
+		return githubRelease{}, errors.New("the latest release has no tag")
+	}
+	return release, nil
+}
+
+// downloadRelease fetches the binary for goos/goarch and checks it against
+// the release's checksums.
+func downloadRelease(client *http.Client, release githubRelease, goos, goarch string) ([]byte, error) {
+	name := releaseAssetName(goos, goarch)
+	binaryURL := release.assetURL(name)
+	if binaryURL == "" {
+		return nil, fmt.Errorf("%s has no build for %s/%s", release.TagName, goos, goarch)
+	}
+	checksumsURL := release.assetURL(releaseChecksums)
+	if checksumsURL == "" {
+		return nil, fmt.Errorf("%s publishes no %s to verify against", release.TagName, releaseChecksums)
+	}
+	checksums, err := httpGet(client, checksumsURL, 1<<20)
+	if err != nil {
+		return nil, err
+	}
+	want, ok := checksumFor(string(checksums), name)
+	if !ok {
+		return nil, fmt.Errorf("%s does not list %s", releaseChecksums, name)
+	}
+	binary, err := httpGet(client, binaryURL, maxReleaseBinary)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(binary)
+	if got := hex.EncodeToString(sum[:]); got != want {
+		return nil, fmt.Errorf("checksum mismatch for %s: got %s, want %s", name, got, want)
+	}
+	return binary, nil
+}
+
+// replaceExecutable swaps path for binary through a temporary file in the
+// same directory, so a failed write never leaves a half-written binary.
+func replaceExecutable(path string, binary []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".assho-update-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(binary); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o755); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// selfUpdate installs the latest release over exe and returns its tag, or
+// "" when current is already the latest.
+func selfUpdate(client *http.Client, exe, current string) (string, error) {
+	if manager := packageManager(exe); manager != "" {
+		return "", fmt.Errorf("%s is managed by %s; update it there", exe, manager)
+	}
+	if _, ok := parseVersion(current); !ok {
+		return "", fmt.Errorf("this is a development build (%s); rebuild from source or reinstall with install.sh", current)
+	}
+	release, err := fetchLatestRelease(client)
+	if err != nil {
+		return "", err
+	}
+	if !newerVersion(release.TagName, current) {
+		return "", nil
+	}
+	binary, err := downloadRelease(client, release, runtime.GOOS, runtime.GOARCH)
+	if err != nil {
+		return "", err
+	}
+	if err := replaceExecutable(exe, binary); err != nil {
+		if errors.Is(err, os.ErrPermission) {
+			return "", fmt.Errorf("cannot replace %s: permission denied; run sudo assho update", exe)
+		}
+		return "", err
+	}
+	return release.TagName, nil
+}
+
+func cliUpdate(args []string) {
+	check := len(args) == 1 && args[0] == "--check"
+	if len(args) > 1 || (len(args) == 1 && !check) {
+		fmt.Fprintln(os.Stderr, "usage: assho update [--check]")
+		os.Exit(1)
+	}
+	client := &http.Client{Timeout: updateTimeout}
+	if check {
+		release, err := fetchLatestRelease(client)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error checking for updates: %v\n", err)
+			os.Exit(1)
+		}
+		if newerVersion(release.TagName, version) {
+			fmt.Printf("assho %s is available (running %s); run assho update\n", release.TagName, version)
+			return
+		}
+		fmt.Printf("assho %s is the latest release\n", version)
+		return
+	}
+	exe, err := os.Executable()
+	if err == nil {
+		exe, err = filepath.EvalSymlinks(exe)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error locating the assho binary: %v\n", err)
+		os.Exit(1)
+	}
+	installed, err := selfUpdate(client, exe, version)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "✘ %v\n", err)
+		os.Exit(1)
+	}
+	if installed == "" {
+		fmt.Printf("✔ assho %s is the latest release\n", version)
+		return
+	}
+	fmt.Printf("✔ Updated %s from %s to %s\n", exe, version, installed)
+}
+
+// checkForUpdateCmd reports a newer release to the TUI, using the cached
+// answer when it is less than a day old.
+func checkForUpdateCmd() tea.Cmd {
+	if !updateCheckEnabled() {
+		return nil
+	}
+	if _, ok := parseVersion(version); !ok {
+		return nil
+	}
+	return func() tea.Msg {
+		latest, err := latestReleaseCached(&http.Client{Timeout: updateCheckTimeout}, time.Now())
+		if err != nil || !newerVersion(latest, version) {
+			return nil
+		}
+		return updateAvailableMsg{version: latest}
+	}
+}
+
+func latestReleaseCached(client *http.Client, now time.Time) (string, error) {
+	var cached updateCheck
+	if data, err := os.ReadFile(getUpdateCheckPath()); err == nil && json.Unmarshal(data, &cached) == nil {
+		if now.Sub(cached.CheckedAt) < updateCheckInterval {
+			return cached.Latest, nil
+		}
+	}
+	release, err := fetchLatestRelease(client)
+	if err != nil {
+		return "", err
+	}
+	if data, err := json.Marshal(updateCheck{CheckedAt: now, Latest: release.TagName}); err == nil {
+		_ = os.MkdirAll(filepath.Dir(getUpdateCheckPath()), 0o700)
+		_ = os.WriteFile(getUpdateCheckPath(), data, 0o600)
+	}
+	return release.TagName, nil
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewerVersion(t *testing.T) {
+	cases := []struct {
+		latest, current string
+		want            bool
+	}{
+		{"v1.2.0", "v1.1.9", true},
+		{"v1.10.0", "v1.9.0", true},
+		{"v2.0", "v1.9.9", true},
+		{"v1.2.0", "v1.2.0", false},
+		{"v1.2.0", "v1.3.0", false},
+		{"v1.2.1", "v1.2.1-rc1", false},
+		{"v1.2.0", "dev", false},
+		{"nightly", "v1.0.0", false},
+	}
+	for _, c := range cases {
+		if got := newerVersion(c.latest, c.current); got != c.want {
+			t.Errorf("newerVersion(%q, %q) = %v, want %v", c.latest, c.current, got, c.want)
+		}
+	}
+}
+
+func TestChecksumFor(t *testing.T) {
+	sums := "abc123  assho-linux-amd64\nDEF456 *assho-darwin-arm64\n"
+	if got, ok := checksumFor(sums, "assho-darwin-arm64"); !ok || got != "def456" {
+		t.Fatalf("got %q, %v", got, ok)
+	}
+	if _, ok := checksumFor(sums, "assho-linux-arm64"); ok {
+		t.Fatal("an unlisted asset should not match")
+	}
+}
+
+func TestPackageManager(t *testing.T) {
+	for path, want := range map[string]string{
+		"/opt/homebrew/Cellar/assho/1.0/bin/assho": "Homebrew",
+		"/nix/store/abc-assho/bin/assho":           "Nix",
+		"/usr/bin/assho":                           "your system package manager",
+		"/usr/local/bin/assho":                     "",
+		"/home/me/.local/bin/assho":                "",
+	} {
+		if got := packageManager(path); got != want {
+			t.Errorf("%s: got %q, want %q", path, got, want)
+		}
+	}
+}
+
+// releaseServer serves a latest release whose binary is binary and whose
+// checksums list sum for it.
+func releaseServer(t *testing.T, tag string, binary []byte, sum string) *httptest.Server {
+	t.Helper()
+	asset := releaseAssetName(runtime.GOOS, runtime.GOARCH)
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/latest":
+			fmt.Fprintf(w, `{"tag_name":%q,"assets":[{"name":%q,"browser_download_url":"%s/bin"},{"name":"checksums.txt","browser_download_url":"%s/sums"}]}`,
+				tag, asset, server.URL, server.URL)
+		case "/bin":
+			w.Write(binary)
+		case "/sums":
+			fmt.Fprintf(w, "%s  %s\n", sum, asset)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	old := releaseAPI
+	releaseAPI = server.URL + "/latest"
+	t.Cleanup(func() { releaseAPI = old })
+	return server
+}
+
+func TestSelfUpdateReplacesBinary(t *testing.T) {
+	binary := []byte("#!/bin/sh\necho new\n")
+	sum := sha256.Sum256(binary)
+	server := releaseServer(t, "v1.3.0", binary, hex.EncodeToString(sum[:]))
+
+	exe := filepath.Join(t.TempDir(), "assho")
+	if err := os.WriteFile(exe, []byte("old"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	installed, err := selfUpdate(server.Client(), exe, "v1.2.0")
+	if err != nil || installed != "v1.3.0" {
+		t.Fatalf("got %q, %v", installed, err)
+	}
+	data, _ := os.ReadFile(exe)
+	if string(data) != string(binary) {
+		t.Fatalf("binary was not replaced: %q", data)
+	}
+	if info, _ := os.Stat(exe); info.Mode().Perm() != 0o755 {
+		t.Fatalf("expected an executable, got %v", info.Mode().Perm())
+	}
+
+	installed, err = selfUpdate(server.Client(), exe, "v1.3.0")
+	if err != nil || installed != "" {
+		t.Fatalf("an up-to-date binary should be left alone, got %q, %v", installed, err)
+	}
+}
+
+func TestSelfUpdateRejectsChecksumMismatch(t *testing.T) {
+	server := releaseServer(t, "v1.3.0", []byte("tampered"), strings.Repeat("0", 64))
+	exe := filepath.Join(t.TempDir(), "assho")
+	if err := os.WriteFile(exe, []byte("old"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := selfUpdate(server.Client(), exe, "v1.2.0"); err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Fatalf("expected a checksum mismatch, got %v", err)
+	}
+	if data, _ := os.ReadFile(exe); string(data) != "old" {
+		t.Fatal("a failed update must leave the binary alone")
+	}
+}
+
+func TestSelfUpdateRefusesDevBuildsAndManagedInstalls(t *testing.T) {
+	if _, err := selfUpdate(http.DefaultClient, "/home/me/bin/assho", "dev"); err == nil || !strings.Contains(err.Error(), "development build") {
+		t.Fatalf("expected a development build error, got %v", err)
+	}
+	if _, err := selfUpdate(http.DefaultClient, "/opt/homebrew/Cellar/assho/1.0/bin/assho", "v1.0.0"); err == nil || !strings.Contains(err.Error(), "Homebrew") {
+		t.Fatalf("expected a package manager error, got %v", err)
+	}
+}
+
+func TestLatestReleaseCached(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	server := releaseServer(t, "v1.3.0", nil, "")
+	now := time.Now()
+	if got, err := latestReleaseCached(server.Client(), now); err != nil || got != "v1.3.0" {
+		t.Fatalf("got %q, %v", got, err)
+	}
+	server.Close()
+	if got, err := latestReleaseCached(server.Client(), now.Add(time.Hour)); err != nil || got != "v1.3.0" {
+		t.Fatalf("a fresh answer should come from the cache, got %q, %v", got, err)
+	}
+	if _, err := latestReleaseCached(server.Client(), now.Add(updateCheckInterval+time.Minute)); err == nil {
+		t.Fatal("a stale cache should ask again")
+	}
+}
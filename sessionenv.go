@@ -0,0 +1,44 @@
+package main
+
+import (
+	"os"
+	"strings"
+)
+
+// --- Session Environment ---
+
+// Some old appliances and embedded shells misbehave with a modern TERM such
+// as xterm-256color or with a locale they do not have. A host can name the
+// TERM its sessions should use instead, and can keep the local locale from
+// being sent. ssh takes the terminal type for the remote pty from TERM and
+// sends LANG and LC_* when ssh_config's SendEnv asks for them, and SendEnv
+// cannot be undone from the command line, so both are handled by adjusting
+// the environment ssh starts with. They apply to interactive sessions only.
+
+// sessionEnviron is environ adjusted for an interactive session with h.
+func sessionEnviron(h Host, environ []string) []string {
+	out := make([]string, 0, len(environ)+1)
+	for _, kv := range environ {
+		name, _, _ := strings.Cut(kv, "=")
+		switch {
+		case h.Term != "" && name == "TERM":
+			continue
+		case h.NoLocale && isLocaleVariable(name):
+			continue
+		}
+		out = append(out, kv)
+	}
+	if h.Term != "" {
+		out = append(out, "TERM="+h.Term)
+	}
+	return out
+}
+
+func isLocaleVariable(name string) bool {
+	return name == "LANG" || name == "LANGUAGE" || strings.HasPrefix(name, "LC_")
+}
+
+// environ is the environment to exec c with.
+func (c connectCommand) environ() []string {
+	return append(sessionEnviron(c.sshHost, os.Environ()), c.extraEnv...)
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSessionEnvironOverridesTermAndDropsLocale(t *testing.T) {
+	environ := []string{"HOME=/home/me", "TERM=xterm-256color", "LANG=en_GB.UTF-8", "LC_ALL=en_GB.UTF-8", "LANGUAGE=en", "LCD=keep"}
+
+	if got := sessionEnviron(Host{}, environ); !slices.Equal(got, environ) {
+		t.Fatalf("expected the environment untouched, got %q", got)
+	}
+
+	got := sessionEnviron(Host{Term: "vt100", NoLocale: true}, environ)
+	want := []string{"HOME=/home/me", "LCD=keep", "TERM=vt100"}
+	if !slices.Equal(got, want) {
+		t.Fatalf("sessionEnviron = %q, want %q", got, want)
+	}
+}
+
+func TestConnectCommandCarriesSessionSettings(t *testing.T) {
+	t.Setenv("TERM", "xterm-256color")
+	t.Setenv("LANG", "de_DE.UTF-8")
+	h := Host{ID: "h1", Alias: "switch", Hostname: "10.0.0.9", User: "admin", Term: "vt100", NoLocale: true}
+	cmd, err := buildConnectCommand(h, hostInventory{hosts: []Host{h}}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	env := cmd.environ()
+	if !slices.Contains(env, "TERM=vt100") || slices.Contains(env, "TERM=xterm-256color") || slices.Contains(env, "LANG=de_DE.UTF-8") {
+		t.Fatalf("unexpected session environment %q", env)
+	}
+	if !strings.HasPrefix(cmd.String(), "TERM=vt100 ssh ") {
+		t.Fatalf("expected the preview to show the TERM override, got %q", cmd.String())
+	}
+}
+
+func TestFormSavesSessionSettings(t *testing.T) {
+	m := model{form: newFormState(newFormInputs())}
+	m.form.inputs[fieldAlias].SetValue("switch")
+	m.form.inputs[fieldHostname].SetValue("10.0.0.9")
+	m.form.inputs[fieldTerm].SetValue("vt 100")
+	err := m.saveFromForm()
+	if err == nil || !strings.HasPrefix(err.Error(), "term") {
+		t.Fatalf("expected a term error, got %v", err)
+	}
+	m.focusFormError(err)
+	if m.form.focus != controlTerm {
+		t.Fatalf("expected focus on TERM, got %v", m.form.focus)
+	}
+
+	m.populateForm(Host{Alias: "switch", Hostname: "10.0.0.9", Term: "vt100", NoLocale: true})
+	if m.form.inputs[fieldTerm].Value() != "vt100" || !formToggleEnabled(m.form.inputs[fieldNoLocale].Value()) {
+		t.Fatal("expected populateForm to fill TERM and the locale toggle")
+	}
+	m.toggleFormControl(controlNoLocale)
+	if formToggleEnabled(m.form.inputs[fieldNoLocale].Value()) {
+		t.Fatal("expected the locale toggle to switch off")
+	}
+}
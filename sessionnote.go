@@ -0,0 +1,126 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// --- Session Notes ---
+
+// With ASSHO_RETURN=1 and ASSHO_SESSION_NOTES=1, closing a session asks for
+// a one-line note such as "rotated certs" before the dashboard takes over.
+// Enter appends it to the host's notes behind a timestamp, so the notes
+// grow into a small ops journal; an empty note or Esc skips it. Entries are
+// kept on one line, separated by "; ", because the Notes field of the host
+// form is a single line too.
+
+// sessionNoteLayout is the timestamp in front of each journal entry.
+const sessionNoteLayout = "2006-01-02 15:04"
+
+type sessionNoteState struct {
+	hostID string
+	alias  string
+	input  textinput.Model
+}
+
+func sessionNotesEnabled() bool {
+	return envBool("ASSHO_SESSION_NOTES", false)
+}
+
+// appendSessionNote adds a timestamped entry to notes. Separators and line
+// breaks typed into the note are flattened so the journal stays one line.
+func appendSessionNote(notes, note string, at time.Time) string {
+	note = strings.Join(strings.Fields(note), " ")
+	if note == "" {
+		return notes
+	}
+	entry := at.Format(sessionNoteLayout) + " " + note
+	if notes = strings.TrimSpace(notes); notes == "" {
+		return entry
+	}
+	return notes + "; " + entry
+}
+
+// openSessionNote asks for a note about the session that just ended.
+// Container and guest sessions are not asked about; their rows are rebuilt
+// from each scan.
+func (m model) openSessionNote(r sessionResult) model {
+	if findHostIndexByID(m.rawHosts, r.hostID) == -1 {
+		return m
+	}
+	m.sessionNote = sessionNoteState{
+		hostID: r.hostID,
+		alias:  r.alias,
+		input:  newPromptInput("  Note  ", "rotated certs, rebooted for kernel, …"),
+	}
+	m.sessionNote.input.Focus()
+	m.state = stateSessionNote
+	return m
+}
+
+func (m model) updateSessionNote(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		m.quitting = true
+		return m, tea.Quit
+	case "esc":
+		m.state = stateList
+		return m, nil
+	case "enter":
+		return m.saveSessionNote(time.Now())
+	}
+	var cmd tea.Cmd
+	m.sessionNote.input, cmd = m.sessionNote.input.Update(msg)
+	return m, cmd
+}
+
+func (m model) saveSessionNote(now time.Time) (tea.Model, tea.Cmd) {
+	m.state = stateList
+	note := strings.TrimSpace(m.sessionNote.input.Value())
+	if note == "" {
+		return m, nil
+	}
+	idx := findHostIndexByID(m.rawHosts, m.sessionNote.hostID)
+	if idx == -1 {
+		m.status.message = "Note not saved: the host no longer exists"
+		m.status.isError = true
+		m.status.version++
+		return m, statusClearCmd(m.status.version)
+	}
+	snapshot := m.snapshot()
+	m.rawHosts[idx].Notes = appendSessionNote(m.rawHosts[idx].Notes, note, now)
+	m.refreshList()
+	if err := m.save(); err != nil {
+		m.restoreSnapshot(snapshot)
+		m.status.message = fmt.Sprintf("Failed to save note: %v", err)
+		m.status.isError = true
+		m.status.version++
+		return m, statusClearCmd(m.status.version)
+	}
+	m.reselectItem(m.sessionNote.hostID, false)
+	m.status.message = "Noted on " + m.sessionNote.alias
+	m.status.isError = false
+	m.status.version++
+	return m, statusClearCmd(m.status.version)
+}
+
+func (m model) renderSessionNoteView() string {
+	width, _ := normalizedSize(m.width, m.height)
+	input := m.sessionNote.input
+	input.Width = max(min(width-12, 90)-len(input.Prompt), 10)
+	content := formHintStyle.Render("Anything worth remembering about this session? It is added to the host's notes.") + "\n\n" + input.View()
+	if m.status.message != "" {
+		style := formHintStyle
+		if m.status.isError {
+			style = testFailStyle
+		}
+		content = style.Render(m.status.message) + "\n\n" + content
+	}
+	box := formBoxStyle.Render(formTitleStyle.Render("Session Note · "+m.sessionNote.alias) + "\n\n" + content)
+	help := "\n" + helpBarStyle.Render(helpEntry("enter", "save")+" | "+helpEntry("esc", "skip"))
+	return appStyle.Render(box + help)
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestAppendSessionNote(t *testing.T) {
+	at := time.Date(2026, 3, 4, 9, 5, 0, 0, time.Local)
+	if got := appendSessionNote("", "rotated certs", at); got != "2026-03-04 09:05 rotated certs" {
+		t.Fatalf("unexpected first entry %q", got)
+	}
+	got := appendSessionNote("legacy box ", "rebooted\nfor   kernel", at)
+	if got != "legacy box; 2026-03-04 09:05 rebooted for kernel" {
+		t.Fatalf("expected the entry appended on one line, got %q", got)
+	}
+	if got := appendSessionNote("legacy box", "  ", at); got != "legacy box" {
+		t.Fatalf("expected a blank note to leave the notes alone, got %q", got)
+	}
+}
+
+func TestSessionNoteAfterSession(t *testing.T) {
+	hosts := []Host{{ID: "h1", Alias: "web", Hostname: "10.0.0.1", Notes: "legacy box"}}
+	writeTempConfig(t, hosts)
+	fresh := func() model {
+		hosts := cloneHosts(hosts)
+		return model{rawHosts: hosts, list: newTestListModel(nil, hosts), historyList: newTestHistoryListModel()}
+	}
+	m := fresh()
+
+	if got := m.afterSession(sessionResult{hostID: "h1", alias: "web"}); got.state != stateList {
+		t.Fatalf("expected no prompt without ASSHO_SESSION_NOTES, got state %v", got.state)
+	}
+	t.Setenv("ASSHO_SESSION_NOTES", "1")
+	got := fresh().afterSession(sessionResult{hostID: "h1", alias: "web"})
+	if got.state != stateSessionNote {
+		t.Fatalf("expected the note prompt, got state %v", got.state)
+	}
+	if view := got.renderSessionNoteView(); !strings.Contains(view, "Back from web") {
+		t.Fatalf("expected the session outcome above the prompt\n%s", view)
+	}
+
+	got.sessionNote.input.SetValue("rotated certs")
+	result, _ := got.updateSessionNote(tea.KeyMsg{Type: tea.KeyEnter})
+	got = result.(model)
+	notes := got.rawHosts[0].Notes
+	if got.state != stateList || !strings.HasPrefix(notes, "legacy box; ") || !strings.HasSuffix(notes, " rotated certs") {
+		t.Fatalf("expected the note appended, got state %v notes %q", got.state, notes)
+	}
+	saved, err := loadConfigFile()
+	if err != nil || len(saved.Hosts) != 1 || saved.Hosts[0].Notes != notes {
+		t.Fatalf("expected the note saved, got %+v (%v)", saved.Hosts, err)
+	}
+
+	failure := sessionResult{hostID: "h1", alias: "web", err: errors.New("ssh not found")}
+	if got := fresh().afterSession(failure); got.state != stateList {
+		t.Fatalf("expected no prompt after a failed connection, got state %v", got.state)
+	}
+	got = fresh().afterSession(sessionResult{hostID: "h1", alias: "web"})
+	got.sessionNote.input.SetValue("never mind")
+	result, _ = got.updateSessionNote(tea.KeyMsg{Type: tea.KeyEsc})
+	if got := result.(model); got.state != stateList || got.rawHosts[0].Notes != "legacy box" {
+		t.Fatalf("expected esc to skip the note, got %q", got.rawHosts[0].Notes)
+	}
+}
@@ -0,0 +1,270 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os/exec"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// --- Session Rounds ---
+
+// m marks the highlighted host and moves down, so a handful of boxes can be
+// picked quickly; esc clears the marks. M then connects to the marked hosts
+// one after another, in list order: assho steps aside for each session as it
+// does for any other ssh it runs, and when a session ends it comes back with
+// the next host offered. Enter connects to it, s skips it, and esc ends the
+// round. Each session goes through the usual host key checks, and a host
+// under maintenance or with a key file ssh would ignore is held in the
+// dialog until enter is pressed again.
+
+type sessionRoundState struct {
+	hostIDs []string
+	next    int // index in hostIDs of the host offered next
+	open    bool
+	ended   string // alias of the session that just ended
+	err     error  // how it ended, when it failed
+	done    int    // sessions opened so far
+	warning string // why the offered host needs a second enter
+}
+
+type roundSessionEndedMsg struct {
+	alias string
+	err   error
+	// unreachable is set when ssh could not connect at all, for the
+	// connection_failed webhook.
+	unreachable *Host
+}
+
+// sshCouldNotConnect reports whether err is ssh's exit status 255, which it
+// uses for connection failures rather than for the remote command's status.
+func sshCouldNotConnect(err error) bool {
+	var exitErr *exec.ExitError
+	return errors.As(err, &exitErr) && exitErr.ExitCode() == 255
+}
+
+// toggleMark marks or unmarks the highlighted host and moves to the next row.
+func (m model) toggleMark() (tea.Model, tea.Cmd) {
+	h, ok := m.list.SelectedItem().(Host)
+	if !ok {
+		return m, nil
+	}
+	m.marked = maps.Clone(m.marked)
+	if m.marked == nil {
+		m.marked = map[string]bool{}
+	}
+	if m.marked[h.ID] {
+		delete(m.marked, h.ID)
+	} else {
+		m.marked[h.ID] = true
+	}
+	m.refreshDelegate()
+	m.list.CursorDown()
+	return m, nil
+}
+
+func (m *model) clearMarks() {
+	m.marked = nil
+	m.refreshDelegate()
+}
+
+// markedInListOrder returns the marked hosts in the order the dashboard
+// lists them, including those in collapsed groups. A host listed twice, as
+// pinned hosts are, comes up once.
+func (m model) markedInListOrder() []Host {
+	var hosts []Host
+	seen := map[string]bool{}
+	for _, item := range flattenHostsImpl(m.rawGroups, m.listHosts(), false, m.showArchived) {
+		if h, ok := item.(Host); ok && m.marked[h.ID] && !seen[h.ID] {
+			seen[h.ID] = true
+			hosts = append(hosts, h)
+		}
+	}
+	return hosts
+}
+
+func (m model) startSessionRound() (tea.Model, tea.Cmd) {
+	hosts := m.markedInListOrder()
+	if len(hosts) == 0 {
+		m.status.message = "Mark hosts with m first, then press M to connect to them in turn"
+		m.status.isError = true
+		m.status.version++
+		return m, statusClearCmd(m.status.version)
+	}
+	m.round = sessionRoundState{}
+	for _, h := range hosts {
+		m.round.hostIDs = append(m.round.hostIDs, h.ID)
+	}
+	m.clearMarks()
+	return m.connectNextInRound()
+}
+
+// roundHost finds a host of the round, which may be a container.
+func (m model) roundHost(id string) (Host, bool) {
+	var found Host
+	ok := false
+	walkHosts(m.rawHosts, func(h Host) {
+		if !ok && h.ID == id {
+			found, ok = h, true
+		}
+	})
+	return found, ok
+}
+
+// connectNextInRound opens the session for the host offered next, skipping
+// hosts deleted since the round started.
+func (m model) connectNextInRound() (tea.Model, tea.Cmd) {
+	m.round.open = false
+	m.round.warning = ""
+	for m.round.next < len(m.round.hostIDs) {
+		h, ok := m.roundHost(m.round.hostIDs[m.round.next])
+		m.round.next++
+		if ok {
+			return m.connectWith(h, sshActionRoundConnect)
+		}
+	}
+	return m.finishSessionRound()
+}
+
+// holdRound offers the host connectNextInRound just tried again, with the
+// warning that stopped it; enter then connects anyway.
+func (m model) holdRound(warning string) (tea.Model, tea.Cmd) {
+	m.round.next--
+	m.round.open = true
+	m.round.warning = warning
+	return m, nil
+}
+
+// connectRoundTrusted runs ssh for one session of the round and returns to
+// the TUI when it exits.
+func (m model) connectRoundTrusted(h Host) (tea.Model, tea.Cmd) {
+	cmd, err := buildConnectCommand(h, m.inventory(), true)
+	if err != nil {
+		return m, func() tea.Msg { return roundSessionEndedMsg{alias: h.Alias, err: err} }
+	}
+	snapshot := m.snapshot()
+	m.history = recordHistory(h.ID, h.Alias, m.history)
+	m.updateHostStats(h.ID, func(s *HostStats) { s.Connections++ })
+	if err := m.save(); err != nil {
+		m.restoreSnapshot(snapshot)
+		return m, func() tea.Msg {
+			return roundSessionEndedMsg{alias: h.Alias, err: fmt.Errorf("failed to save history: %w", err)}
+		}
+	}
+	m.refreshDelegate()
+	m.round.done++
+	run := exec.Command(cmd.binary, cmd.args...)
+	run.Env = cmd.environ()
+	recordAudit("connect", h.Alias, cmd.sshHost, nil)
+	return m, tea.ExecProcess(run, func(err error) tea.Msg {
+		msg := roundSessionEndedMsg{alias: h.Alias, err: err}
+		if sshCouldNotConnect(err) {
+			msg.unreachable = &h
+		}
+		return msg
+	})
+}
+
+func (m model) finishRoundSession(msg roundSessionEndedMsg) (tea.Model, tea.Cmd) {
+	if len(m.round.hostIDs) == 0 {
+		return m, nil
+	}
+	m.round.ended, m.round.err = msg.alias, msg.err
+	var notify tea.Cmd
+	if msg.unreachable != nil {
+		notify = webhookCmd(connectionFailedEvent(*msg.unreachable, msg.err))
+	}
+	if m.round.next >= len(m.round.hostIDs) {
+		next, cmd := m.finishSessionRound()
+		return next, tea.Batch(cmd, notify)
+	}
+	m.round.open = true
+	return m, notify
+}
+
+func (m model) finishSessionRound() (tea.Model, tea.Cmd) {
+	done, total := m.round.done, len(m.round.hostIDs)
+	m.round = sessionRoundState{}
+	m.status.message = fmt.Sprintf("Round finished · %d of %d session(s) opened", done, total)
+	m.status.isError = false
+	m.status.version++
+	return m, statusClearCmd(m.status.version)
+}
+
+func (m model) updateSessionRound(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		m.quitting = true
+		return m, tea.Quit
+	case "enter":
+		return m.connectNextInRound()
+	case "ctrl+f":
+		if m.permFix.path == "" {
+			return m, nil
+		}
+		next, cmd := m.fixKeyPermissions()
+		m = next.(model)
+		m.round.warning = m.status.message
+		return m, cmd
+	case "s":
+		m.round.next++
+		m.round.warning = ""
+		if m.round.next >= len(m.round.hostIDs) {
+			return m.finishSessionRound()
+		}
+	case "esc", "q":
+		return m.finishSessionRound()
+	}
+	return m, nil
+}
+
+func (m model) renderSessionRound(base string) string {
+	width, height := normalizedSize(m.width, m.height)
+	modalWidth := min(64, max(width-6, 30))
+	inner := modalWidth - 6
+	var b strings.Builder
+	b.WriteString(formSectionStyle.Render(fmt.Sprintf("Round · %d of %d", m.round.next+1, len(m.round.hostIDs))) + "\n\n")
+	switch {
+	case m.round.err != nil:
+		b.WriteString(testFailStyle.Render(ansi.Truncate("✘ "+m.round.ended+": "+m.round.err.Error(), inner, "…")) + "\n\n")
+	case m.round.ended != "":
+		b.WriteString(testSuccessStyle.Render(ansi.Truncate("✔ session with "+m.round.ended+" ended", inner, "…")) + "\n\n")
+	}
+	for i, id := range m.round.hostIDs {
+		alias := id
+		if h, ok := m.roundHost(id); ok {
+			alias = h.Alias
+			if i == m.round.next && h.usesSecurityKey() {
+				alias += " · touch your security key"
+			}
+		}
+		line := "  " + alias
+		switch {
+		case i == m.round.next:
+			line = formSectionStyle.Render("→ " + alias)
+		case i < m.round.next:
+			line = formHintStyle.Render(line)
+		}
+		b.WriteString(ansi.Truncate(line, inner, "…") + "\n")
+	}
+	if m.round.warning != "" {
+		b.WriteString("\n" + testFailStyle.Render(ansi.Wrap(m.round.warning, inner, " ")) + "\n")
+	}
+	b.WriteString("\n" + helpEntry("enter", "connect") + "  " + helpEntry("s", "skip") + "  " + helpEntry("esc", "end round"))
+	if m.permFix.path != "" {
+		b.WriteString("  " + helpEntry("ctrl+f", "fix permissions"))
+	}
+	modal := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(colorPrimary).
+		Padding(1, 2).
+		Width(modalWidth).
+		Render(b.String())
+	backdrop := fitViewToBounds(dimBase(base), width, height)
+	return fitViewToBounds(overlayCenter(backdrop, modal, width, height), width, height)
+}
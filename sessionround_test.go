@@ -0,0 +1,111 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestMarkHostsAndListOrder(t *testing.T) {
+	groups := []Group{{ID: "g1", Name: "prod", Expanded: true}}
+	hosts := []Host{
+		{ID: "h1", Alias: "web", GroupID: "g1", Pinned: true},
+		{ID: "h2", Alias: "db", GroupID: "g1"},
+		{ID: "h3", Alias: "cache"},
+	}
+	// ★ Pinned, web, cache, prod, web, db
+	m := model{rawGroups: groups, rawHosts: hosts, list: newTestListModel(groups, hosts)}
+	m.list.Select(4)
+
+	next, _ := m.toggleMark()
+	m = next.(model)
+	if !m.marked["h1"] || m.list.Index() != 5 {
+		t.Fatalf("expected web marked and the cursor on db, got %v at %d", m.marked, m.list.Index())
+	}
+	m.list.Select(2)
+	next, _ = m.toggleMark()
+	m = next.(model)
+	if got := m.markedInListOrder(); len(got) != 2 || got[0].Alias != "web" || got[1].Alias != "cache" {
+		t.Fatalf("unexpected round order %v", got)
+	}
+
+	m.list.Select(1)
+	next, _ = m.toggleMark()
+	if got := next.(model).marked; got["h1"] || !got["h3"] {
+		t.Fatalf("m again should unmark, got %v", got)
+	}
+	if !m.marked["h1"] {
+		t.Fatal("toggling must not change the marks of an earlier model")
+	}
+}
+
+func TestStartSessionRoundNeedsMarks(t *testing.T) {
+	m := model{list: newTestListModel(nil, nil)}
+	next, _ := m.startSessionRound()
+	if got := next.(model); !got.status.isError || got.round.hostIDs != nil {
+		t.Fatalf("expected an error without marks, got %+v", got.status)
+	}
+}
+
+func TestSessionRoundOffersNextHost(t *testing.T) {
+	hosts := []Host{{ID: "h1", Alias: "web"}, {ID: "h2", Alias: "db"}, {ID: "h3", Alias: "cache"}}
+	m := model{rawHosts: hosts, list: newTestListModel(nil, hosts), width: 100, height: 30}
+	m.round = sessionRoundState{hostIDs: []string{"h1", "h2", "h3"}, next: 1, done: 1}
+
+	next, _ := m.Update(roundSessionEndedMsg{alias: "web", err: errors.New("exit status 255")})
+	m = next.(model)
+	if !m.round.open {
+		t.Fatal("the next host should be offered when a session ends")
+	}
+	view := ansi.Strip(m.View())
+	for _, want := range []string{"Round · 2 of 3", "web: exit status 255", "→ db"} {
+		if !strings.Contains(view, want) {
+			t.Fatalf("prompt missing %q:\n%s", want, view)
+		}
+	}
+
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s")})
+	m = next.(model)
+	if m.round.next != 2 || !m.round.open {
+		t.Fatalf("s should skip to cache, got %+v", m.round)
+	}
+
+	m.rawHosts = hosts[:2] // cache was deleted meanwhile
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = next.(model)
+	if m.round.hostIDs != nil || !strings.Contains(m.status.message, "1 of 3") {
+		t.Fatalf("the round should end once no host is left, got %+v / %q", m.round, m.status.message)
+	}
+}
+
+func TestSessionRoundTrustFailureReturnsToPrompt(t *testing.T) {
+	action := pendingSSHAction{kind: sshActionRoundConnect, host: Host{ID: "h1", Alias: "web"}}
+	_, cmd := model{}.failPendingSSHActionModel(action, errors.New("host key not trusted"))
+	if msg, ok := cmd().(roundSessionEndedMsg); !ok || msg.alias != "web" || msg.err == nil {
+		t.Fatalf("expected the round to hear about the failure, got %#v", cmd())
+	}
+}
+
+func TestSessionRoundHoldsMaintenanceHost(t *testing.T) {
+	hosts := []Host{{ID: "h1", Alias: "web"}, {ID: "h2", Alias: "db", Hostname: "10.0.0.2", Maintenance: &Maintenance{Note: "disk swap"}}}
+	m := model{rawHosts: hosts, list: newTestListModel(nil, hosts), width: 100, height: 30}
+	m.round = sessionRoundState{hostIDs: []string{"h1", "h2"}, next: 1, done: 1, open: true, ended: "web"}
+
+	next, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = next.(model)
+	if cmd != nil || !m.round.open || m.round.next != 1 || m.round.warning == "" {
+		t.Fatalf("expected db held for a second enter, got %+v", m.round)
+	}
+	if view := ansi.Strip(m.View()); !strings.Contains(view, "press enter again") {
+		t.Fatalf("prompt missing the maintenance warning:\n%s", view)
+	}
+
+	next, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = next.(model)
+	if cmd == nil || m.round.open || m.round.next != 2 || m.round.warning != "" {
+		t.Fatalf("expected the second enter to connect, got %+v", m.round)
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// --- Session Durations ---
+
+// A history entry's Timestamp is when the connection was made. In the
+// ASSHO_RETURN mode assho also sees the session close, so the entry gains an
+// EndedAt and the host's stats add the time spent connected. The history
+// view shows how long the last session lasted, and the stats screen and
+// detail pane the total. Sessions that exec'd ssh, or that ssh could not
+// start, have no end and are left out.
+
+// recordSessionEnd stamps the end of r's session on its history entry and
+// stats, reporting whether anything changed.
+func (m *model) recordSessionEnd(r sessionResult) bool {
+	if r.failed() || r.ended.IsZero() {
+		return false
+	}
+	for i := range m.history {
+		e := &m.history[i]
+		if e.HostID != r.hostID || e.EndedAt != 0 || e.Timestamp > r.ended.Unix() {
+			continue
+		}
+		e.EndedAt = r.ended.Unix()
+		seconds := e.EndedAt - e.Timestamp
+		m.updateHostStats(r.hostID, func(s *HostStats) {
+			s.Sessions++
+			s.SessionSeconds += seconds
+		})
+		return true
+	}
+	return false
+}
+
+// sessionDuration is how long the entry's session lasted, if its end is
+// known.
+func (e HistoryEntry) sessionDuration() (time.Duration, bool) {
+	if e.EndedAt == 0 || e.EndedAt < e.Timestamp {
+		return 0, false
+	}
+	return time.Duration(e.EndedAt-e.Timestamp) * time.Second, true
+}
+
+// buildLastSession maps host IDs to the duration of their latest session.
+func buildLastSession(history []HistoryEntry) map[string]time.Duration {
+	m := make(map[string]time.Duration)
+	seen := make(map[string]bool)
+	for _, e := range history {
+		if seen[e.HostID] {
+			continue
+		}
+		seen[e.HostID] = true
+		if d, ok := e.sessionDuration(); ok {
+			m[e.HostID] = d
+		}
+	}
+	return m
+}
+
+func formatSessionDuration(d time.Duration) string {
+	d = d.Round(time.Second)
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+	default:
+		return fmt.Sprintf("%dd%02dh", int(d.Hours())/24, int(d.Hours())%24)
+	}
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestAfterSessionRecordsDuration(t *testing.T) {
+	hosts := []Host{{ID: "h1", Alias: "web", Hostname: "10.0.0.1", User: "root"}}
+	writeTempConfig(t, hosts)
+	started := time.Now().Add(-72 * time.Minute)
+	m := model{
+		rawHosts:    hosts,
+		list:        newTestListModel(nil, hosts),
+		historyList: newTestHistoryListModel(),
+		history:     []HistoryEntry{{HostID: "h1", Alias: "web", Timestamp: started.Unix()}},
+	}
+
+	got := m.afterSession(sessionResult{hostID: "h1", alias: "web", ended: started.Add(72 * time.Minute)})
+	if got.history[0].EndedAt == 0 || got.rawHosts[0].Stats == nil || got.rawHosts[0].Stats.SessionSeconds != 72*60 {
+		t.Fatalf("expected the session end recorded, got %+v %+v", got.history[0], got.rawHosts[0].Stats)
+	}
+	if !strings.HasSuffix(got.status.message, "1h12m") {
+		t.Fatalf("expected the duration in the status, got %q", got.status.message)
+	}
+	if out := ansi.Strip(got.historyList.View()); !strings.Contains(out, "1h12m session") {
+		t.Fatalf("expected the history row to show the duration\n%s", out)
+	}
+
+	again := got.afterSession(sessionResult{hostID: "h1", alias: "web", ended: time.Now()})
+	if again.rawHosts[0].Stats.Sessions != 1 {
+		t.Fatal("expected an entry's end to be recorded once")
+	}
+}
+
+func TestFormatSessionDuration(t *testing.T) {
+	cases := map[time.Duration]string{
+		42 * time.Second:             "42s",
+		12 * time.Minute:             "12m",
+		90 * time.Minute:             "1h30m",
+		50*time.Hour + time.Minute*5: "2d02h",
+	}
+	for d, want := range cases {
+		if got := formatSessionDuration(d); got != want {
+			t.Errorf("formatSessionDuration(%v) = %q, want %q", d, got, want)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// --- Smart Groups ---
+
+// A smart group has a Query instead of members. Hosts matching the query are
+// listed under it in addition to their normal place, so nothing is copied.
+//
+// Query syntax: terms joined by AND (or whitespace). A term is key=glob,
+// key!=glob, or a bare glob matched against alias and hostname. Keys are
+// alias, host (hostname), user, port, group, jump (ProxyJump), notes, owner,
+// team, and contact.
+// Globs are case-insensitive.
+
+type queryTerm struct {
+	key    string // empty for a bare glob
+	negate bool
+	glob   string
+}
+
+type hostQuery []queryTerm
+
+var queryKeys = map[string]string{
+	"alias":     "alias",
+	"host":      "host",
+	"hostname":  "host",
+	"user":      "user",
+	"port":      "port",
+	"group":     "group",
+	"jump":      "jump",
+	"proxyjump": "jump",
+	"notes":     "notes",
+	"owner":     "owner",
+	"team":      "team",
+	"contact":   "contact",
+}
+
+func parseHostQuery(query string) (hostQuery, error) {
+	var q hostQuery
+	for _, field := range strings.Fields(query) {
+		if strings.EqualFold(field, "AND") {
+			continue
+		}
+		term := queryTerm{}
+		if key, value, ok := strings.Cut(field, "="); ok {
+			term.negate = strings.HasSuffix(key, "!")
+			key = strings.ToLower(strings.TrimSuffix(key, "!"))
+			canonical, known := queryKeys[key]
+			if !known {
+				return nil, fmt.Errorf("unknown query key %q", key)
+			}
+			term.key, term.glob = canonical, strings.ToLower(value)
+		} else {
+			term.glob = strings.ToLower(field)
+		}
+		if _, err := path.Match(term.glob, ""); err != nil {
+			return nil, fmt.Errorf("invalid pattern %q", term.glob)
+		}
+		q = append(q, term)
+	}
+	if len(q) == 0 {
+		return nil, fmt.Errorf("query is empty")
+	}
+	return q, nil
+}
+
+func globMatch(glob, value string) bool {
+	ok, _ := path.Match(glob, strings.ToLower(value))
+	return ok
+}
+
+// Match reports whether every term matches h. groups resolves group=.
+func (q hostQuery) Match(h Host, groups []Group) bool {
+	if h.IsContainer {
+		return false
+	}
+	for _, term := range q {
+		var matched bool
+		switch term.key {
+		case "":
+			matched = globMatch(term.glob, h.Alias) || globMatch(term.glob, h.Hostname)
+		case "alias":
+			matched = globMatch(term.glob, h.Alias)
+		case "host":
+			matched = globMatch(term.glob, h.Hostname)
+		case "user":
+			matched = globMatch(term.glob, h.User)
+		case "port":
+			port := h.Port
+			if port == "" {
+				port = "22"
+			}
+			matched = globMatch(term.glob, port)
+		case "group":
+			name := ""
+			if idx := findGroupIndexByID(groups, h.GroupID); idx != -1 {
+				name = groups[idx].Name
+			}
+			matched = globMatch(term.glob, name)
+		case "jump":
+			matched = globMatch(term.glob, h.ProxyJump)
+		case "notes":
+			matched = globMatch(term.glob, h.Notes)
+		case "owner":
+			matched = globMatch(term.glob, h.Owner)
+		case "team":
+			matched = globMatch(term.glob, h.Team)
+		case "contact":
+			matched = globMatch(term.glob, h.Contact)
+		}
+		if matched == term.negate {
+			return false
+		}
+	}
+	return true
+}
+
+// smartGroupMembers returns the indexes of hosts matching a smart group.
+// An unparsable query matches nothing.
+func smartGroupMembers(g Group, groups []Group, hosts []Host) []int {
+	q, err := parseHostQuery(g.Query)
+	if err != nil {
+		return nil
+	}
+	var members []int
+	for i := range hosts {
+		if q.Match(hosts[i], groups) {
+			members = append(members, i)
+		}
+	}
+	return members
+}
+
+func newGroupQueryInput() textinput.Model {
+	input := textinput.New()
+	input.Prompt = "  Query       "
+	input.Placeholder = "e.g. user=root AND host=*.prod"
+	input.PromptStyle = lipgloss.NewStyle().Foreground(colorMuted)
+	input.TextStyle = lipgloss.NewStyle().Foreground(colorText)
+	input.PlaceholderStyle = lipgloss.NewStyle().Foreground(colorSubtle)
+	input.Cursor.Style = lipgloss.NewStyle().Foreground(colorSecondary)
+	return input
+}
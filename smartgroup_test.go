@@ -0,0 +1,124 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestHostQueryMatch(t *testing.T) {
+	groups := []Group{{ID: "g1", Name: "prod"}}
+	hosts := []Host{
+		{ID: "a", Alias: "web-1", Hostname: "web1.prod.example.com", User: "root", GroupID: "g1"},
+		{ID: "b", Alias: "web-2", Hostname: "web2.staging.example.com", User: "deploy"},
+		{ID: "c", Alias: "db", Hostname: "10.0.0.5", User: "root", Port: "2222", Team: "Data", Owner: "sam"},
+	}
+	cases := []struct {
+		query string
+		want  []string
+	}{
+		{"group=prod AND user=root", []string{"a"}},
+		{"host=*.example.com", []string{"a", "b"}},
+		{"user=root port!=22", []string{"c"}},
+		{"WEB-*", []string{"a", "b"}},
+		{"team=data owner=s*", []string{"c"}},
+	}
+	for _, tc := range cases {
+		q, err := parseHostQuery(tc.query)
+		if err != nil {
+			t.Fatalf("%q: %v", tc.query, err)
+		}
+		var got []string
+		for _, h := range hosts {
+			if q.Match(h, groups) {
+				got = append(got, h.ID)
+			}
+		}
+		if len(got) != len(tc.want) {
+			t.Fatalf("%q: expected %v, got %v", tc.query, tc.want, got)
+		}
+		for i := range got {
+			if got[i] != tc.want[i] {
+				t.Fatalf("%q: expected %v, got %v", tc.query, tc.want, got)
+			}
+		}
+	}
+	for _, bad := range []string{"", "AND", "color=red", "host=[bad"} {
+		if _, err := parseHostQuery(bad); err == nil {
+			t.Errorf("expected error for query %q", bad)
+		}
+	}
+}
+
+func TestFlattenHostsListsSmartGroupMembers(t *testing.T) {
+	groups := []Group{
+		{ID: "g1", Name: "prod", Expanded: true},
+		{ID: "s1", Name: "root boxes", Expanded: true, Query: "user=root"},
+	}
+	hosts := []Host{
+		{ID: "a", Alias: "web", User: "root", GroupID: "g1"},
+		{ID: "b", Alias: "app", User: "deploy"},
+	}
+	items := flattenHosts(groups, hosts)
+	// smart header, web, ungrouped app, prod header, web
+	if len(items) != 5 {
+		t.Fatalf("expected 5 items, got %d", len(items))
+	}
+	smart, ok := items[0].(groupItem)
+	if !ok || smart.ID != "s1" || smart.HostCount != 1 {
+		t.Fatalf("expected smart group header first, got %#v", items[0])
+	}
+	if h, ok := items[1].(Host); !ok || h.ID != "a" {
+		t.Fatalf("expected web under smart group, got %#v", items[1])
+	}
+	if g, ok := items[3].(groupItem); !ok || g.ID != "g1" || g.HostCount != 1 {
+		t.Fatalf("expected regular group to keep its own count, got %#v", items[3])
+	}
+}
+
+func TestSmartGroupPromptCreatesGroup(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("ASSHO_STORE_PASSWORD", "0")
+	m := model{rawHosts: []Host{{ID: "a", Alias: "web", User: "root"}}, historyList: newTestHistoryListModel()}
+	m.list = newTestListModel(nil, m.rawHosts)
+	m.groupPrompt.input = textinput.New()
+
+	updated, _ := m.updateList(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("Q")})
+	m = updated.(model)
+	m.groupPrompt.input.SetValue("roots")
+	updated, _ = m.updateGroupPrompt(tea.KeyMsg{Type: tea.KeyTab})
+	m = updated.(model)
+	m.groupPrompt.query.SetValue("bogus=1")
+	updated, _ = m.updateGroupPrompt(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(model)
+	if m.state != stateGroupPrompt || m.form.formError == "" {
+		t.Fatal("expected invalid query to keep the prompt open with an error")
+	}
+	m.groupPrompt.query.SetValue("user=root")
+	updated, _ = m.updateGroupPrompt(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(model)
+	if len(m.rawGroups) != 1 || m.rawGroups[0].Query != "user=root" || !m.rawGroups[0].Smart() {
+		t.Fatalf("expected smart group to be created, got %+v", m.rawGroups)
+	}
+}
+
+func TestSaveFromFormRejectsSmartGroup(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("ASSHO_STORE_PASSWORD", "0")
+	m := model{rawGroups: []Group{{ID: "s1", Name: "roots", Query: "user=root"}}, form: formState{inputs: newFormInputs()}, historyList: newTestHistoryListModel()}
+	m.list = newTestListModel(m.rawGroups, nil)
+	m.buildGroupOptions("")
+	for _, opt := range m.form.groupOptions {
+		if opt == "roots" {
+			t.Fatal("smart groups must not be offered as form group options")
+		}
+	}
+	m.form.inputs[fieldAlias].SetValue("web")
+	m.form.inputs[fieldHostname].SetValue("10.0.0.1")
+	m.form.groupCustom = true
+	m.form.inputs[fieldGroup].SetValue("roots")
+	if err := m.saveFromForm(); err == nil {
+		t.Fatal("expected error when assigning a host to a smart group")
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"os"
@@ -12,31 +13,77 @@ import (
 )
 
 type scanDockerMsg struct {
-	hostIndex  int
+	hostID     string
 	containers []Host
 	err        error
 	background bool // true for automatic refresh scans
 }
 
 type testConnectionMsg struct {
-	err error
+	hostID  string // empty for unsaved hosts; stats are only kept for saved ones
+	latency time.Duration
+	os      *HostOS     // set when ASSHO_PROBE_OS is on and the test passed
+	sshfp   sshfpResult // set when ASSHO_VERIFY_SSHFP is on
+	banner  *HostBanner // set when the test passed and the server showed one
+	err     error
 }
 
-func testConnection(h Host) tea.Cmd {
+// testConnection tests h until ctx is cancelled or the test times out.
+func testConnection(ctx context.Context, h Host, inv hostInventory) tea.Cmd {
+	if h.Transport == transportPSRemoting {
+		return testWinRM(ctx, h, inv)
+	}
 	if allowInsecureTest() {
-		return testConnectionTrusted(h)
+		return testConnectionTrusted(ctx, h)
 	}
-	return checkHostTrustCmd(pendingSSHAction{kind: sshActionTest, host: h, trustHost: h})
+	return checkHostTrustCmd(pendingSSHAction{kind: sshActionTest, host: h, trustHost: h, inv: inv, ctx: ctx})
 }
 
-func testConnectionTrusted(h Host) tea.Cmd {
-	return func() tea.Msg { return testConnectionMsg{err: runSSHTest(h, "exit")} }
+func testConnectionTrusted(ctx context.Context, h Host) tea.Cmd {
+	return func() tea.Msg {
+		start := time.Now()
+		sshfp, banner, err := runSSHTestSSHFP(ctx, h, testCommand(h))
+		latency := time.Since(start)
+		recordAudit("test", h.Alias, h, err)
+		msg := testConnectionMsg{hostID: h.ID, latency: latency, sshfp: sshfp, err: err}
+		if err == nil {
+			msg.banner = banner
+		}
+		if err == nil && h.ID != "" && probeOSEnabled() {
+			msg.os = probeOS(h)
+		}
+		return msg
+	}
 }
 
 func runSSHTest(h Host, remoteCmd string) error {
+	_, err := runSSHCommand(h, remoteCmd)
+	return err
+}
+
+// runSSHCommand runs remoteCmd non-interactively with the same options as a
+// connection test and returns its standard output.
+func runSSHCommand(h Host, remoteCmd string) (string, error) {
+	stdout, _, err := runSSHWithArgs(h, remoteCmd, nil)
+	return stdout, err
+}
+
+// runSSHWithArgs is runSSHCommand with extra ssh options, and also returns
+// standard error for callers that parse ssh's own diagnostics.
+func runSSHWithArgs(h Host, remoteCmd string, extra []string) (string, string, error) {
+	return runSSHWithArgsContext(context.Background(), h, remoteCmd, extra)
+}
+
+// runSSHWithArgsContext is runSSHWithArgs that stops early when parent is
+// cancelled, returning context.Canceled.
+func runSSHWithArgsContext(parent context.Context, h Host, remoteCmd string, extra []string) (string, string, error) {
+	if h.isLocal() {
+		return runLocalCommand(parent, h, remoteCmd)
+	}
 	if h.Hostname == "" {
-		return fmt.Errorf("hostname required")
+		return "", "", fmt.Errorf("hostname required")
 	}
+	h = withPassword(h)
 	port := h.Port
 	if port == "" {
 		port = "22"
@@ -45,33 +92,39 @@ func runSSHTest(h Host, remoteCmd string) error {
 	if user == "" {
 		user = os.Getenv("USER")
 		if user == "" {
-			return fmt.Errorf("user required")
+			return "", "", fmt.Errorf("user required")
 		}
 	}
 
-	args := []string{
-		"-o", "ConnectTimeout=5",
+	args := pinnedKnownHostsArgs(h)
+	args = append(args,
+		"-o", connectTimeoutOption(h.connectTimeout()),
 		"-o", "NumberOfPasswordPrompts=1",
 		"-o", "PreferredAuthentications=publickey,password,keyboard-interactive",
-	}
+	)
 	if allowInsecureTest() {
 		args = append(args, "-o", "StrictHostKeyChecking=no", "-o", "UserKnownHostsFile=/dev/null")
 	} else {
 		args = append(args, "-o", "StrictHostKeyChecking=yes")
 	}
-	if user != "" {
-		args = append(args, "-l", user)
-	}
-	if port != "" {
-		args = append(args, "-p", port)
-	}
-	if h.IdentityFile != "" {
-		args = append(args, "-i", expandPath(h.IdentityFile))
-	}
-	if h.ProxyJump != "" {
-		args = append(args, "-J", h.ProxyJump)
+	args = append(args, extra...)
+	args = append(args, skipSecurityKeyArgs(h)...)
+	if h.UseSSHConfig {
+		args = append(args, h.Alias, remoteCmd)
+	} else {
+		if user != "" {
+			args = append(args, "-l", user)
+		}
+		if port != "" {
+			args = append(args, "-p", port)
+		}
+		if h.IdentityFile != "" {
+			args = append(args, "-i", expandPath(h.IdentityFile))
+		}
+		args = append(args, identityAgentArgs(h)...)
+		args = append(args, proxyArgs(h)...)
+		args = append(args, bareHostname(h.Hostname), remoteCmd)
 	}
-	args = append(args, h.Hostname, remoteCmd)
 
 	binary := "ssh"
 	cmdArgs := args
@@ -80,106 +133,145 @@ func runSSHTest(h Host, remoteCmd string) error {
 	if h.Password != "" && strings.TrimSpace(h.IdentityFile) == "" {
 		sshpassPath, err := exec.LookPath("sshpass")
 		if err != nil {
-			return fmt.Errorf("password provided but sshpass not installed")
+			return "", "", fmt.Errorf("password provided but %w", errMissingTool("sshpass"))
 		}
 		binary = sshpassPath
 		cmdArgs = append([]string{"-e", "ssh"}, args...)
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 8*time.Second)
+	ctx, cancel := context.WithTimeout(parent, h.commandTimeout())
 	defer cancel()
 	cmd := exec.CommandContext(ctx, binary, cmdArgs...)
 	if h.Password != "" && binary != "ssh" {
 		cmd.Env = append(os.Environ(), "SSHPASS="+h.Password)
 	}
-	output, err := cmd.CombinedOutput()
-	if err != nil {
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		if parent.Err() != nil {
+			return "", stderr.String(), parent.Err()
+		}
 		if ctx.Err() == context.DeadlineExceeded {
-			return fmt.Errorf("connection test timed out")
+			return "", stderr.String(), fmt.Errorf("connection test timed out after %s", h.commandTimeout())
 		}
-		out := strings.TrimSpace(string(output))
+		out := strings.TrimSpace(stripSSHDebug(stderr.String()) + stdout.String())
 		if out == "" {
 			out = err.Error()
 		}
-		return fmt.Errorf("%s", out)
+		return "", stderr.String(), fmt.Errorf("%s", securityKeySkipped(h, out))
 	}
-	return nil
+	return stdout.String(), stderr.String(), nil
 }
 
-func scanDockerContainers(h Host, index int, background bool) tea.Cmd {
-	return checkHostTrustCmd(pendingSSHAction{kind: sshActionScan, host: h, trustHost: h, hostIndex: index, background: background})
+// scanDockerContainers scans h until ctx is cancelled or the scan times out.
+func scanDockerContainers(ctx context.Context, h Host, inv hostInventory, background bool) tea.Cmd {
+	if h.isWindows() {
+		return func() tea.Msg {
+			return scanDockerMsg{hostID: h.ID, err: errWindowsScan, background: background}
+		}
+	}
+	return checkHostTrustCmd(pendingSSHAction{kind: sshActionScan, host: h, trustHost: h, inv: inv, background: background, ctx: ctx})
 }
 
-func scanDockerContainersTrusted(h Host, index int, background bool) tea.Cmd {
+func scanDockerContainersTrusted(ctx context.Context, h Host, background bool) tea.Cmd {
 	return func() tea.Msg {
-		// Run ssh command to get docker containers
-		// docker ps --format "{{.ID}}\t{{.Names}}\t{{.Image}}"
-		cmdStr := `docker ps --format "{{.ID}}` + "\t" + `{{.Names}}` + "\t" + `{{.Image}}"`
-
-		args := []string{
-			"-o", "BatchMode=yes",
-			"-o", "ConnectTimeout=5",
-			"-o", "StrictHostKeyChecking=yes",
+		msg := runDockerScan(ctx, h, background)
+		// Automatic refreshes run every 30s; only user-initiated scans are audited.
+		if !background {
+			recordAudit("scan", h.Alias, h, msg.err)
 		}
-		args = append(args, h.Hostname)
-		if h.User != "" {
-			args = append([]string{"-l", h.User}, args...)
-		}
-		if h.Port != "" {
-			args = append([]string{"-p", h.Port}, args...)
+		return msg
+	}
+}
+
+func runDockerScan(parent context.Context, h Host, background bool) scanDockerMsg {
+	if h.isWindows() {
+		return scanDockerMsg{hostID: h.ID, err: errWindowsScan, background: background}
+	}
+	// One round trip lists both docker containers and libvirt guests.
+	cmdStr := "sh -c " + shellQuote(guestScanScript)
+	h = withPassword(h)
+
+	args := pinnedKnownHostsArgs(h)
+	args = append(args,
+		"-o", "BatchMode=yes",
+		"-o", connectTimeoutOption(h.connectTimeout()),
+		"-o", "StrictHostKeyChecking=yes",
+	)
+	args = append(args, bareHostname(h.Hostname))
+	if h.User != "" {
+		args = append([]string{"-l", h.User}, args...)
+	}
+	if h.Port != "" {
+		args = append([]string{"-p", h.Port}, args...)
+	}
+	if h.IdentityFile != "" {
+		args = append([]string{"-i", expandPath(h.IdentityFile)}, args...)
+	}
+	args = append(proxyArgs(h), args...)
+	args = append(identityAgentArgs(h), args...)
+	args = append(skipSecurityKeyArgs(h), args...)
+	finalCmd := "ssh"
+	sshArgs := append(args, cmdStr)
+
+	if h.Password != "" {
+		sshpassPath, err := exec.LookPath("sshpass")
+		if err == nil {
+			sshArgs = append([]string{"-e", "ssh"}, sshArgs...)
+			finalCmd = sshpassPath
 		}
-		if h.IdentityFile != "" {
-			args = append([]string{"-i", expandPath(h.IdentityFile)}, args...)
+	}
+
+	ctx, cancel := context.WithTimeout(parent, h.commandTimeout())
+	defer cancel()
+	cmd := exec.CommandContext(ctx, finalCmd, sshArgs...)
+	if h.isLocal() {
+		// The workstation itself runs the same script without ssh.
+		cmd = exec.CommandContext(ctx, "sh", "-c", guestScanScript)
+	} else if h.Password != "" && finalCmd != "ssh" {
+		cmd.Env = append(os.Environ(), "SSHPASS="+h.Password)
+	}
+	output, err := cmd.CombinedOutput()
+	var containers []Host
+	if err == nil {
+		containers = parseGuestScan(string(output), h.ID)
+	}
+	if dockerAPIEnabled() && parent.Err() == nil {
+		if api, apiErr := scanDockerAPI(parent, h); apiErr == nil {
+			containers, err = mergeDockerAPIScan(containers, api), nil
 		}
-		if h.ProxyJump != "" {
-			args = append([]string{"-J", h.ProxyJump}, args...)
+	}
+	if err != nil {
+		if parent.Err() != nil {
+			return scanDockerMsg{hostID: h.ID, err: parent.Err(), background: background}
 		}
-		finalCmd := "ssh"
-		sshArgs := append(args, cmdStr)
-
-		if h.Password != "" {
-			sshpassPath, err := exec.LookPath("sshpass")
-			if err == nil {
-				sshArgs = append([]string{"-e", "ssh"}, sshArgs...)
-				finalCmd = sshpassPath
-			}
+		if ctx.Err() == context.DeadlineExceeded {
+			return scanDockerMsg{hostID: h.ID, err: fmt.Errorf("scan timed out after %s", h.commandTimeout()), background: background}
 		}
+		return scanDockerMsg{hostID: h.ID, err: fmt.Errorf("scan failed: %v", err), background: background}
+	}
+	return scanDockerMsg{hostID: h.ID, containers: containers, background: background}
+}
 
-		ctx, cancel := context.WithTimeout(context.Background(), 8*time.Second)
-		defer cancel()
-		cmd := exec.CommandContext(ctx, finalCmd, sshArgs...)
-		if h.Password != "" && finalCmd != "ssh" {
-			cmd.Env = append(os.Environ(), "SSHPASS="+h.Password)
+// loginCommand is what an interactive session runs instead of a plain shell.
+// The tmux variant is wrapped in sh so it behaves the same under any login
+// shell and falls back to that shell when tmux is not installed.
+func (h Host) loginCommand() string {
+	if h.TmuxSession == "" {
+		if h.RemoteCommand == "" && h.Transport == transportPowerShell {
+			return windowsLogin
 		}
-		output, err := cmd.CombinedOutput()
-		if err != nil {
-			if ctx.Err() == context.DeadlineExceeded {
-				return scanDockerMsg{hostIndex: index, err: fmt.Errorf("scan timed out"), background: background}
-			}
-			return scanDockerMsg{hostIndex: index, err: fmt.Errorf("scan failed: %v", err), background: background}
-		}
-
-		var containers []Host
-		lines := strings.Split(string(output), "\n")
-		for _, line := range lines {
-			if strings.TrimSpace(line) == "" {
-				continue
-			}
-			parts := strings.Split(line, "\t")
-			if len(parts) >= 2 {
-				name := parts[1]
-				containers = append(containers, Host{
-					ID:          newHostID(),
-					Alias:       name,
-					Hostname:    name,
-					User:        "root",
-					IsContainer: true,
-					ParentID:    h.ID,
-				})
-			}
-		}
-		return scanDockerMsg{hostIndex: index, containers: containers, background: background}
+		return h.RemoteCommand
 	}
+	script := "if command -v tmux >/dev/null 2>&1; then exec tmux new -As " + shellQuote(h.TmuxSession) + "; fi; " +
+		`echo "assho: tmux not found, starting a login shell" >&2; exec "${SHELL:-sh}" -l`
+	return "sh -c " + shellQuote(script)
+}
+
+func validTmuxSession(name string) bool {
+	return name != "" && strings.IndexFunc(name, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_')
+	}) == -1
 }
 
 func buildSSHArgs(h Host, forceTTY bool, remoteCmd string) []string {
@@ -191,13 +283,30 @@ func buildTrustedSSHArgs(h Host, forceTTY bool, remoteCmd string) []string {
 }
 
 func buildSSHArgsWithTrust(h Host, forceTTY bool, remoteCmd string, strictHostKey bool) []string {
-	args := []string{}
+	// A host's remote command only replaces the interactive shell; callers
+	// running their own command (tests, scans, docker exec) take precedence.
+	if login := h.loginCommand(); remoteCmd == "" && login != "" {
+		remoteCmd = login
+		forceTTY = true
+	}
+	args := pinnedKnownHostsArgs(h)
 	if strictHostKey {
 		args = append(args, "-o", "StrictHostKeyChecking=yes")
 	}
+	if h.ConnectTimeout > 0 || globalConnectTimeout() > 0 {
+		args = append(args, "-o", connectTimeoutOption(h.connectTimeout()))
+	}
 	if forceTTY {
 		args = append(args, "-t")
 	}
+	if h.UseSSHConfig {
+		// OpenSSH resolves everything else from ~/.ssh/config.
+		args = append(args, h.Alias)
+		if remoteCmd != "" {
+			args = append(args, remoteCmd)
+		}
+		return args
+	}
 	if h.ForwardAgent {
 		args = append(args, "-A")
 	}
@@ -210,13 +319,12 @@ func buildSSHArgsWithTrust(h Host, forceTTY bool, remoteCmd string, strictHostKe
 	if h.IdentityFile != "" {
 		args = append(args, "-i", expandPath(h.IdentityFile))
 	}
-	if h.ProxyJump != "" {
-		args = append(args, "-J", h.ProxyJump)
-	}
+	args = append(args, identityAgentArgs(h)...)
+	args = append(args, proxyArgs(h)...)
 	if h.LocalForward != "" {
 		args = append(args, "-L", h.LocalForward)
 	}
-	args = append(args, h.Hostname)
+	args = append(args, bareHostname(h.Hostname))
 	if remoteCmd != "" {
 		args = append(args, remoteCmd)
 	}
@@ -234,6 +342,103 @@ func buildSSHCommand(password string, sshArgs []string) (string, []string, []str
 	return sshpassPath, append([]string{"-e", "ssh"}, sshArgs...), []string{"SSHPASS=" + password}, true
 }
 
+// connectCommand is the fully assembled process assho execs for an
+// interactive session. extraEnv may carry SSHPASS and is never displayed.
+type connectCommand struct {
+	binary         string
+	args           []string
+	extraEnv       []string
+	sshHost        Host // host ssh actually dials; the parent for containers
+	missingSSHPass bool
+}
+
+// buildConnectCommand resolves containers to their parent host and wraps ssh
+// in sshpass when a password is stored. trusted selects strict host-key
+// checking, which the TUI uses after its own trust review.
+func buildConnectCommand(h Host, inv hostInventory, trusted bool) (connectCommand, error) {
+	build := buildSSHArgs
+	if trusted {
+		build = buildTrustedSSHArgs
+	}
+	cmd := connectCommand{sshHost: h}
+	var sshArgs []string
+	if h.IsContainer {
+		parentIdx := -1
+		if h.ParentID != "" {
+			parentIdx = findHostIndexByID(inv.hosts, h.ParentID)
+		}
+		if parentIdx == -1 {
+			return connectCommand{}, fmt.Errorf("container %q is missing its parent host reference", h.Alias)
+		}
+		cmd.sshHost = resolveEndpoint(inv.hosts[parentIdx], inv)
+		switch {
+		case h.isGuest() && h.Hostname != "":
+			cmd.sshHost = guestEndpoint(cmd.sshHost, h)
+			sshArgs = build(cmd.sshHost, false, "")
+		case cmd.sshHost.isLocal():
+			return buildLocalCommand(cmd.sshHost, childShellCommand(h)), nil
+		default:
+			sshArgs = build(cmd.sshHost, true, childShellCommand(h))
+		}
+	} else if h.Transport == transportPSRemoting {
+		return buildPSRemotingCommand(resolveEndpoint(h, inv)), nil
+	} else if h.isLocal() {
+		return buildLocalCommand(resolveEndpoint(h, inv), h.loginCommand()), nil
+	} else {
+		cmd.sshHost = resolveEndpoint(h, inv)
+		sshArgs = build(cmd.sshHost, false, "")
+	}
+	password, _ := hostPassword(cmd.sshHost)
+	var ok bool
+	cmd.binary, cmd.args, cmd.extraEnv, ok = buildSSHCommand(password, sshArgs)
+	cmd.missingSSHPass = password != "" && !ok
+	return cmd, nil
+}
+
+// childShellCommand opens a shell in a nested container, instance, jail, or
+// console-only guest; it runs on the parent host.
+func childShellCommand(h Host) string {
+	switch {
+	case h.isGuest():
+		return guestConsoleCommand(h.Alias)
+	case h.isLXD():
+		return lxdExecCommand(h)
+	case h.isJail():
+		return jailExecCommand(h, "")
+	}
+	return fmt.Sprintf("docker exec -it %s sh -c 'command -v bash >/dev/null 2>&1 && exec bash || exec sh'", h.Alias)
+}
+
+// String renders the command as a copy-pasteable shell line with any
+// environment secrets redacted.
+func (c connectCommand) String() string {
+	var parts []string
+	if c.sshHost.Term != "" {
+		parts = append(parts, "TERM="+shellQuote(c.sshHost.Term))
+	}
+	for _, kv := range c.extraEnv {
+		name, _, _ := strings.Cut(kv, "=")
+		parts = append(parts, name+"=<redacted>")
+	}
+	parts = append(parts, shellQuote(c.binary))
+	for _, arg := range c.args {
+		parts = append(parts, shellQuote(arg))
+	}
+	return strings.Join(parts, " ")
+}
+
+func shellQuote(s string) string {
+	if s == "" {
+		return "''"
+	}
+	if strings.IndexFunc(s, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("@%+=:,./_-", r))
+	}) == -1 {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
 func formatTestStatus(err error) (string, bool) {
 	if err == nil {
 		return "Connection successful", true
@@ -245,6 +450,9 @@ func formatTestStatus(err error) (string, bool) {
 	if strings.Contains(msg, "REVOKED HOST KEY") {
 		return "Host key is revoked in ~/.ssh/known_hosts.", false
 	}
+	if path := sshPermissionProblem(err); path != "" {
+		return "ssh refused " + path + ": others can read or write it", false
+	}
 	if strings.Contains(msg, "Host key verification failed") ||
 		strings.Contains(msg, "authenticity of host") ||
 		strings.Contains(msg, "No RSA host key is known") {
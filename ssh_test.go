@@ -102,3 +102,11 @@ func TestBuildSSHCommandNoSshpass(t *testing.T) {
 		t.Errorf("expected original args returned, got %v", got)
 	}
 }
+
+func TestBuildSSHArgsUseSSHConfig(t *testing.T) {
+	h := Host{Alias: "bastion", Hostname: "10.0.0.1", User: "root", Port: "2222", IdentityFile: "~/.ssh/id_ed25519", ProxyJump: "jump", UseSSHConfig: true}
+	got := strings.Join(buildTrustedSSHArgs(h, true, "uptime"), " ")
+	if got != "-o StrictHostKeyChecking=yes -t bastion uptime" {
+		t.Fatalf("expected only the alias passed to ssh, got %q", got)
+	}
+}
@@ -6,15 +6,80 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 )
 
-// parseSSHConfig reads an SSH config file and extracts Host blocks into []Host.
+// sshConfigBlock is one Host block: its patterns and the first value of each
+// supported keyword. Empty values are unset.
+type sshConfigBlock struct {
+	patterns  []string
+	hostname  string
+	user      string
+	port      string
+	identity  string
+	proxyJump string
+	proxyCmd  string
+	agent     string
+}
 
 // parseSSHConfig reads an SSH config file and extracts Host blocks into []Host.
-// It skips wildcard patterns (e.g. Host *, Host 192.168.*) and Match blocks.
-// Include directives are followed recursively.
+// Wildcard blocks (e.g. Host *, Host 192.168.*) do not become hosts, but their
+// values are applied to every concrete alias they match with OpenSSH's
+// first-value-wins rule, so a later `Host *` fills in only what is still unset
+// and an earlier one overrides. Match blocks are skipped. Include directives
+// are followed recursively and spliced in at their position.
 func parseSSHConfig(path string) ([]Host, error) {
+	blocks, err := parseSSHConfigBlocks(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// Convert blocks to Host entries — one per concrete alias.
+	var hosts []Host
+	seen := map[string]bool{}
+	for _, b := range blocks {
+		for _, alias := range b.patterns {
+			if isWildcard(alias) || strings.HasPrefix(alias, "!") || seen[alias] {
+				continue
+			}
+			seen[alias] = true
+			r := resolveSSHConfigAlias(blocks, alias)
+			h := Host{
+				ID:           newHostID(),
+				Alias:        alias,
+				Hostname:     bareHostname(strings.ReplaceAll(strings.ReplaceAll(r.hostname, "%h", alias), "%%", "%")),
+				User:         r.user,
+				Port:         r.port,
+				IdentityFile: r.identity,
+				ProxyJump:    r.proxyJump,
+				ProxyCommand: r.proxyCmd,
+				// SSH_AUTH_SOCK is what ssh uses anyway.
+				IdentityAgent: unquoteSSHOption(r.agent),
+			}
+			if h.IdentityAgent == "SSH_AUTH_SOCK" {
+				h.IdentityAgent = ""
+			}
+			if h.ProxyJump == "none" {
+				h.ProxyJump = ""
+			}
+			if h.ProxyCommand == "none" {
+				h.ProxyCommand = ""
+			}
+			// Default hostname to alias if not set.
+			if h.Hostname == "" {
+				h.Hostname = alias
+			}
+			if h.Port == "" {
+				h.Port = "22"
+			}
+			hosts = append(hosts, h)
+		}
+	}
+	return hosts, nil
+}
+
+func parseSSHConfigBlocks(path string) ([]sshConfigBlock, error) {
 	path = expandPath(path)
 	f, err := os.Open(path)
 	if err != nil {
@@ -22,17 +87,8 @@ func parseSSHConfig(path string) ([]Host, error) {
 	}
 	defer f.Close()
 
-	type hostBlock struct {
-		aliases  []string
-		hostname string
-		user     string
-		port     string
-		identity string
-	}
-
-	var blocks []hostBlock
-	var current *hostBlock
-	var included []Host // hosts resolved from Include directives
+	var blocks []sshConfigBlock
+	var current *sshConfigBlock
 	inMatch := false
 
 	scanner := bufio.NewScanner(f)
@@ -63,9 +119,9 @@ func parseSSHConfig(path string) ([]Host, error) {
 			}
 			matches, _ := filepath.Glob(pattern)
 			for _, p := range matches {
-				sub, subErr := parseSSHConfig(p)
+				sub, subErr := parseSSHConfigBlocks(p)
 				if subErr == nil {
-					included = append(included, sub...)
+					blocks = append(blocks, sub...)
 				}
 			}
 			continue
@@ -85,22 +141,9 @@ func parseSSHConfig(path string) ([]Host, error) {
 			// End previous block.
 			if current != nil {
 				blocks = append(blocks, *current)
-				current = nil
 			}
 			inMatch = false
-
-			aliases := strings.Fields(args)
-			// Filter out wildcard aliases.
-			var clean []string
-			for _, a := range aliases {
-				if !isWildcard(a) {
-					clean = append(clean, a)
-				}
-			}
-			if len(clean) == 0 {
-				continue // All aliases were wildcards; skip this block.
-			}
-			current = &hostBlock{aliases: clean}
+			current = &sshConfigBlock{patterns: strings.Fields(args)}
 			continue
 		}
 
@@ -108,15 +151,26 @@ func parseSSHConfig(path string) ([]Host, error) {
 			continue
 		}
 
+		// Within a block the first occurrence of a keyword wins too.
+		var field *string
 		switch keyword {
 		case "hostname":
-			current.hostname = args
+			field = &current.hostname
 		case "user":
-			current.user = args
+			field = &current.user
 		case "port":
-			current.port = args
+			field = &current.port
 		case "identityfile":
-			current.identity = args
+			field = &current.identity
+		case "proxyjump":
+			field = &current.proxyJump
+		case "proxycommand":
+			field = &current.proxyCmd
+		case "identityagent":
+			field = &current.agent
+		}
+		if field != nil && *field == "" {
+			*field = args
 		}
 	}
 	if err := scanner.Err(); err != nil {
@@ -126,62 +180,129 @@ func parseSSHConfig(path string) ([]Host, error) {
 	if current != nil {
 		blocks = append(blocks, *current)
 	}
+	return blocks, nil
+}
 
-	// Convert blocks to Host entries — one per alias.
-	var hosts []Host
+// resolveSSHConfigAlias merges every block matching alias in file order,
+// keeping the first value seen for each keyword.
+func resolveSSHConfigAlias(blocks []sshConfigBlock, alias string) sshConfigBlock {
+	var r sshConfigBlock
 	for _, b := range blocks {
-		for _, alias := range b.aliases {
-			h := Host{
-				ID:           newHostID(),
-				Alias:        alias,
-				Hostname:     b.hostname,
-				User:         b.user,
-				Port:         b.port,
-				IdentityFile: b.identity,
+		if !sshPatternsMatch(b.patterns, alias) {
+			continue
+		}
+		for _, f := range []struct {
+			dst *string
+			src string
+		}{
+			{&r.hostname, b.hostname},
+			{&r.user, b.user},
+			{&r.port, b.port},
+			{&r.identity, b.identity},
+			{&r.proxyJump, b.proxyJump},
+			{&r.proxyCmd, b.proxyCmd},
+			{&r.agent, b.agent},
+		} {
+			if *f.dst == "" {
+				*f.dst = f.src
 			}
-			// Default hostname to alias if not set.
-			if h.Hostname == "" {
-				h.Hostname = alias
+		}
+	}
+	return r
+}
+
+// sshPatternsMatch applies ssh_config pattern rules: some pattern must match
+// and no negated (!) pattern may.
+func sshPatternsMatch(patterns []string, alias string) bool {
+	matched := false
+	for _, p := range patterns {
+		negated := strings.HasPrefix(p, "!")
+		if !sshPatternMatch(strings.TrimPrefix(p, "!"), alias) {
+			continue
+		}
+		if negated {
+			return false
+		}
+		matched = true
+	}
+	return matched
+}
+
+// sshPatternMatch matches * and ? case-insensitively; unlike path.Match it
+// has no character classes, as in OpenSSH.
+func sshPatternMatch(pattern, value string) bool {
+	pattern, value = strings.ToLower(pattern), strings.ToLower(value)
+	for len(pattern) > 0 {
+		switch pattern[0] {
+		case '*':
+			for i := len(value); i >= 0; i-- {
+				if sshPatternMatch(pattern[1:], value[i:]) {
+					return true
+				}
 			}
-			if h.Port == "" {
-				h.Port = "22"
+			return false
+		case '?':
+			if value == "" {
+				return false
 			}
-			hosts = append(hosts, h)
+			pattern, value = pattern[1:], value[1:]
+		default:
+			if value == "" || pattern[0] != value[0] {
+				return false
+			}
+			pattern, value = pattern[1:], value[1:]
 		}
 	}
-	return append(included, hosts...), nil
+	return value == ""
 }
 
-// importSSHConfig parses ~/.ssh/config and returns only hosts whose alias
-// doesn't already exist in existing (case-insensitive comparison).
-func importSSHConfig(existing []Host) (imported []Host, skipped int, err error) {
+// importSSHConfig parses ~/.ssh/config and returns the hosts whose alias
+// doesn't already exist in existing (case-insensitive comparison), plus the
+// field differences for aliases that do. Existing aliases with no
+// differences, and repeats within the file, count as skipped.
+func importSSHConfig(existing []Host) (imported []Host, changed []importChange, skipped int, err error) {
 	home, err := os.UserHomeDir()
 	if err != nil {
-		return nil, 0, fmt.Errorf("cannot determine home directory: %w", err)
+		return nil, nil, 0, fmt.Errorf("cannot determine home directory: %w", err)
 	}
 	configPath := filepath.Join(home, ".ssh", "config")
 
 	parsed, err := parseSSHConfig(configPath)
 	if err != nil {
-		return nil, 0, err
+		return nil, nil, 0, err
 	}
+	imported, changed, skipped = mergeImportedHosts(existing, parsed)
+	return imported, changed, skipped, nil
+}
 
-	// Build lookup set of existing aliases.
-	existingAliases := make(map[string]bool, len(existing))
-	for _, h := range existing {
-		existingAliases[strings.ToLower(strings.TrimSpace(h.Alias))] = true
+// mergeImportedHosts sorts parsed hosts into new aliases, field changes for
+// existing ones, and a count of repeats and unchanged aliases.
+func mergeImportedHosts(existing, parsed []Host) (imported []Host, changed []importChange, skipped int) {
+	// Build lookup of existing aliases.
+	existingAliases := make(map[string]int, len(existing))
+	for i, h := range existing {
+		existingAliases[strings.ToLower(strings.TrimSpace(h.Alias))] = i
 	}
 
+	seen := make(map[string]bool, len(parsed))
 	for _, h := range parsed {
 		key := strings.ToLower(strings.TrimSpace(h.Alias))
-		if existingAliases[key] {
+		if seen[key] {
 			skipped++
 			continue
 		}
-		existingAliases[key] = true // prevent dupes within the import itself
+		seen[key] = true // prevent dupes within the import itself
+		if i, ok := existingAliases[key]; ok {
+			if fields := diffImportedHost(existing[i], h); len(fields) > 0 && !existing[i].IsContainer {
+				changed = append(changed, importChange{hostID: existing[i].ID, alias: existing[i].Alias, fields: fields})
+			} else {
+				skipped++
+			}
+			continue
+		}
 		imported = append(imported, h)
 	}
-	return imported, skipped, nil
+	return imported, changed, skipped
 }
 
 // splitDirective splits an SSH config line into keyword and the rest.
@@ -206,15 +327,23 @@ func isWildcard(alias string) bool {
 }
 
 // fprintSSHConfig writes all non-container hosts as SSH config stanzas.
-// Pipe into ~/.ssh/config or redirect with >> to append.
+// Pipe into ~/.ssh/config or redirect with >> to append. Hosts that connect
+// through ssh_config are skipped, since their stanza already lives there, and
+// so are WinRM and local hosts, which ssh does not reach.
 func fprintSSHConfig(w io.Writer, hosts []Host) {
 	for _, h := range hosts {
-		if h.IsContainer {
+		if !sshConfigExportable(h) {
 			continue
 		}
+		for _, meta := range [][2]string{{"Owner", h.Owner}, {"Team", h.Team}, {"Contact", h.Contact}} {
+			if meta[1] != "" {
+				fmt.Fprintf(w, "# %s: %s\n", meta[0], meta[1])
+			}
+		}
 		fmt.Fprintf(w, "Host %s\n", h.Alias)
 		if h.Hostname != "" {
-			fmt.Fprintf(w, "    HostName %s\n", h.Hostname)
+			// ssh expands %-tokens in HostName, so IPv6 zone IDs need %%.
+			fmt.Fprintf(w, "    HostName %s\n", strings.ReplaceAll(bareHostname(h.Hostname), "%", "%%"))
 		}
 		if h.User != "" {
 			fmt.Fprintf(w, "    User %s\n", h.User)
@@ -225,15 +354,165 @@ func fprintSSHConfig(w io.Writer, hosts []Host) {
 		if h.IdentityFile != "" {
 			fmt.Fprintf(w, "    IdentityFile %s\n", h.IdentityFile)
 		}
+		if h.IdentityAgent != "" {
+			fmt.Fprintf(w, "    IdentityAgent %s\n", quoteSSHOption(h.IdentityAgent))
+		}
+		if h.ConnectTimeout > 0 {
+			fmt.Fprintf(w, "    ConnectTimeout %d\n", h.ConnectTimeout)
+		}
 		if h.ForwardAgent {
 			fmt.Fprintf(w, "    ForwardAgent yes\n")
 		}
-		if h.ProxyJump != "" {
+		if h.ProxyCommand != "" {
+			fmt.Fprintf(w, "    ProxyCommand %s\n", h.ProxyCommand)
+		} else if h.ProxyJump != "" {
 			fmt.Fprintf(w, "    ProxyJump %s\n", h.ProxyJump)
 		}
 		if h.LocalForward != "" {
 			fmt.Fprintf(w, "    LocalForward %s\n", h.LocalForward)
 		}
+		if login := h.loginCommand(); login != "" {
+			fmt.Fprintf(w, "    RemoteCommand %s\n", login)
+			fmt.Fprintf(w, "    RequestTTY yes\n")
+		}
 		fmt.Fprintln(w)
 	}
 }
+
+// sshConfigExportable reports whether h gets a stanza in the export.
+func sshConfigExportable(h Host) bool {
+	return !h.IsContainer && !h.UseSSHConfig && h.Transport != transportPSRemoting && !h.isLocal()
+}
+
+// fprintGroupedSSHConfig writes the same stanzas as fprintSSHConfig, but the
+// user, key file, and ProxyJump that every exported member of a group shares
+// move into one Host block per group, written after the host stanzas so a
+// host's own values still win under ssh's first-value rule. hosts have their
+// group defaults applied already.
+func fprintGroupedSSHConfig(w io.Writer, groups []Group, hosts []Host) {
+	hosts = slices.Clone(hosts)
+	var blocks strings.Builder
+	for _, g := range groups {
+		if g.Smart() {
+			continue
+		}
+		var members []int
+		for i, h := range hosts {
+			if h.GroupID == g.ID && sshConfigExportable(h) {
+				members = append(members, i)
+			}
+		}
+		if len(members) < 2 {
+			continue
+		}
+		shared := func(field func(Host) string) string {
+			value := field(hosts[members[0]])
+			for _, i := range members[1:] {
+				if field(hosts[i]) != value {
+					return ""
+				}
+			}
+			return value
+		}
+		user := shared(func(h Host) string { return h.User })
+		key := shared(func(h Host) string { return h.IdentityFile })
+		jump := shared(func(h Host) string {
+			if h.ProxyCommand != "" {
+				return ""
+			}
+			return h.ProxyJump
+		})
+		if user == "" && key == "" && jump == "" {
+			continue
+		}
+		var aliases []string
+		for _, i := range members {
+			aliases = append(aliases, hosts[i].Alias)
+			if user != "" {
+				hosts[i].User = ""
+			}
+			if key != "" {
+				hosts[i].IdentityFile = ""
+			}
+			if jump != "" {
+				hosts[i].ProxyJump = ""
+			}
+		}
+		fmt.Fprintf(&blocks, "# Group: %s\n", g.Name)
+		fmt.Fprintf(&blocks, "Host %s\n", strings.Join(aliases, " "))
+		for _, setting := range [][2]string{{"User", user}, {"IdentityFile", key}, {"ProxyJump", jump}} {
+			if setting[1] != "" {
+				fmt.Fprintf(&blocks, "    %s %s\n", setting[0], setting[1])
+			}
+		}
+		blocks.WriteString("\n")
+	}
+	fprintSSHConfig(w, hosts)
+	io.WriteString(w, blocks.String())
+}
+
+// Markers around the hosts written by `assho export --write`. Everything
+// between them is replaced on each export; the rest of the file is untouched.
+const (
+	managedBlockBegin = "# BEGIN assho managed hosts — edits here are overwritten"
+	managedBlockEnd   = "# END assho managed hosts"
+)
+
+// replaceManagedBlock swaps the marked block in config for block, or appends
+// block when no complete marker pair exists.
+func replaceManagedBlock(config, block string) string {
+	block = managedBlockBegin + "\n" + block + managedBlockEnd + "\n"
+	lines := strings.SplitAfter(config, "\n")
+	begin, end := -1, -1
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if begin == -1 && strings.HasPrefix(trimmed, "# BEGIN assho") {
+			begin = i
+		} else if begin != -1 && trimmed == managedBlockEnd {
+			end = i
+			break
+		}
+	}
+	if begin == -1 || end == -1 {
+		if config == "" {
+			return block
+		}
+		if !strings.HasSuffix(config, "\n") {
+			config += "\n"
+		}
+		return config + "\n" + block
+	}
+	return strings.Join(lines[:begin], "") + block + strings.Join(lines[end+1:], "")
+}
+
+// writeManagedSSHConfig replaces the assho block in the ssh config at path
+// with block, following a symlinked config to its target and keeping its
+// permissions.
+func writeManagedSSHConfig(path, block string) error {
+	path = expandPath(path)
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	mode := os.FileMode(0600)
+	existing, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		if info, statErr := os.Stat(path); statErr == nil {
+			mode = info.Mode().Perm()
+		}
+	case os.IsNotExist(err):
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			return err
+		}
+	default:
+		return err
+	}
+	tmp := path + ".assho.tmp"
+	if err := os.WriteFile(tmp, []byte(replaceManagedBlock(string(existing), block)), mode); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp, mode); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"bytes"
 	"os"
 	"path/filepath"
 	"strings"
@@ -227,7 +228,7 @@ Host db
     HostName 10.0.0.2
     User root
 `)
-	imported, skipped, err := importSSHConfig(nil)
+	imported, _, skipped, err := importSSHConfig(nil)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -251,7 +252,7 @@ Host WEB
     HostName 10.0.0.1
 `)
 	existing := []Host{{ID: "h1", Alias: "web", Hostname: "10.0.0.1"}}
-	imported, skipped, err := importSSHConfig(existing)
+	imported, _, skipped, err := importSSHConfig(existing)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -277,7 +278,7 @@ Host FOO
 Host bar
     HostName 3.3.3.3
 `)
-	imported, skipped, err := importSSHConfig(nil)
+	imported, _, skipped, err := importSSHConfig(nil)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -293,7 +294,7 @@ func TestImportSSHConfigMissingFile(t *testing.T) {
 	home := t.TempDir()
 	t.Setenv("HOME", home)
 	// No ~/.ssh/config present.
-	_, _, err := importSSHConfig(nil)
+	_, _, _, err := importSSHConfig(nil)
 	if err == nil {
 		t.Fatal("expected error when ~/.ssh/config does not exist")
 	}
@@ -349,3 +350,199 @@ Host EXISTING-HOST
 		t.Errorf("expected 2 skipped, got %d", skipped)
 	}
 }
+
+func TestFprintSSHConfigRemoteCommand(t *testing.T) {
+	var buf bytes.Buffer
+	fprintSSHConfig(&buf, []Host{{Alias: "app", Hostname: "10.0.0.1", RemoteCommand: "tmux attach || tmux new"}})
+	out := buf.String()
+	if !strings.Contains(out, "    RemoteCommand tmux attach || tmux new\n") || !strings.Contains(out, "    RequestTTY yes\n") {
+		t.Fatalf("expected RemoteCommand and RequestTTY in export, got:\n%s", out)
+	}
+}
+
+func TestFprintSSHConfigOwnershipComments(t *testing.T) {
+	var buf bytes.Buffer
+	fprintSSHConfig(&buf, []Host{{Alias: "db", Hostname: "10.0.0.2", Owner: "sam", Contact: "#db-oncall"}})
+	out := buf.String()
+	if !strings.HasPrefix(out, "# Owner: sam\n# Contact: #db-oncall\nHost db\n") {
+		t.Fatalf("expected ownership comments above the stanza, got:\n%s", out)
+	}
+
+	path := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(path, buf.Bytes(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	hosts, err := parseSSHConfig(path)
+	if err != nil || len(hosts) != 1 || hosts[0].Alias != "db" {
+		t.Fatalf("expected exported config to parse back, got %+v %v", hosts, err)
+	}
+}
+
+func TestReplaceManagedBlock(t *testing.T) {
+	block := "Host web\n    HostName 10.0.0.1\n\n"
+	first := replaceManagedBlock("Host mine\n    User me", block)
+	want := "Host mine\n    User me\n\n" + managedBlockBegin + "\n" + block + managedBlockEnd + "\n"
+	if first != want {
+		t.Fatalf("unexpected append result:\n%s", first)
+	}
+	withTail := first + "\nHost after\n"
+	second := replaceManagedBlock(withTail, "Host db\n\n")
+	if strings.Contains(second, "Host web") || strings.Count(second, managedBlockBegin) != 1 ||
+		!strings.HasPrefix(second, "Host mine\n") || !strings.HasSuffix(second, managedBlockEnd+"\n\nHost after\n") {
+		t.Fatalf("expected block replaced in place, got:\n%s", second)
+	}
+	if again := replaceManagedBlock(second, "Host db\n\n"); again != second {
+		t.Fatalf("expected export to be idempotent, got:\n%s", again)
+	}
+	if got := replaceManagedBlock("", block); !strings.HasPrefix(got, managedBlockBegin) {
+		t.Fatalf("expected empty config to hold only the block, got:\n%s", got)
+	}
+}
+
+func TestWriteManagedSSHConfigFollowsSymlink(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "dotfiles-config")
+	if err := os.WriteFile(target, []byte("Host mine\n"), 0640); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(dir, "config")
+	if err := os.Symlink(target, link); err != nil {
+		t.Fatal(err)
+	}
+	var block strings.Builder
+	fprintSSHConfig(&block, []Host{{Alias: "web", Hostname: "10.0.0.1"}})
+	for range 2 {
+		if err := writeManagedSSHConfig(link, block.String()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if info, err := os.Lstat(link); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Fatalf("expected symlink kept, got %v %v", info, err)
+	}
+	data, _ := os.ReadFile(target)
+	if strings.Count(string(data), "Host web\n") != 1 || !strings.HasPrefix(string(data), "Host mine\n") {
+		t.Fatalf("unexpected config:\n%s", data)
+	}
+	if info, _ := os.Stat(target); info.Mode().Perm() != 0640 {
+		t.Fatalf("expected permissions kept, got %v", info.Mode().Perm())
+	}
+}
+
+func TestParseSSHConfigAppliesWildcardDefaults(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config")
+	content := `Host *.corp
+    ProxyJump bastion.corp
+    HostName %h.internal
+
+Host web.corp
+    User deploy
+
+Host db.corp !legacy.corp
+    Port 5022
+
+Host legacy.corp
+    ProxyJump none
+
+Host *
+    User fallback
+    IdentityFile ~/.ssh/id_default
+    Port 2200
+`
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	hosts, err := parseSSHConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	byAlias := map[string]Host{}
+	for _, h := range hosts {
+		byAlias[h.Alias] = h
+	}
+	if len(hosts) != 3 {
+		t.Fatalf("expected 3 concrete hosts, got %+v", hosts)
+	}
+	web := byAlias["web.corp"]
+	if web.User != "deploy" || web.ProxyJump != "bastion.corp" || web.Hostname != "web.corp.internal" || web.Port != "2200" || web.IdentityFile != "~/.ssh/id_default" {
+		t.Fatalf("unexpected web.corp %+v", web)
+	}
+	if db := byAlias["db.corp"]; db.Port != "5022" || db.User != "fallback" {
+		t.Fatalf("unexpected db.corp %+v", db)
+	}
+	// The earlier *.corp ProxyJump wins over the later "none".
+	if legacy := byAlias["legacy.corp"]; legacy.ProxyJump != "bastion.corp" || legacy.Port != "2200" {
+		t.Fatalf("unexpected legacy.corp %+v", legacy)
+	}
+}
+
+func TestSSHPatternsMatch(t *testing.T) {
+	cases := []struct {
+		patterns []string
+		alias    string
+		want     bool
+	}{
+		{[]string{"*"}, "web", true},
+		{[]string{"web-??"}, "WEB-01", true},
+		{[]string{"web-??"}, "web-1", false},
+		{[]string{"*", "!db*"}, "db1", false},
+		{[]string{"!db*"}, "web", false},
+		{[]string{"[ab]"}, "a", false},
+	}
+	for _, tc := range cases {
+		if got := sshPatternsMatch(tc.patterns, tc.alias); got != tc.want {
+			t.Errorf("sshPatternsMatch(%v, %q) = %v, want %v", tc.patterns, tc.alias, got, tc.want)
+		}
+	}
+}
+
+func TestFprintSSHConfigSkipsUseSSHConfigHosts(t *testing.T) {
+	var buf bytes.Buffer
+	fprintSSHConfig(&buf, []Host{{Alias: "app", Hostname: "10.0.0.1"}, {Alias: "bastion", UseSSHConfig: true}})
+	out := buf.String()
+	if !strings.Contains(out, "Host app\n") || strings.Contains(out, "bastion") {
+		t.Fatalf("expected the ssh_config host left out of the export, got:\n%s", out)
+	}
+}
+
+func TestFprintGroupedSSHConfig(t *testing.T) {
+	groups := []Group{{ID: "g1", Name: "prod", DefaultUser: "deploy"}, {ID: "g2", Name: "solo"}}
+	hosts := withGroupDefaults([]Host{
+		{Alias: "web1", Hostname: "10.0.0.1", GroupID: "g1", IdentityFile: "~/.ssh/prod", ProxyJump: "bastion"},
+		{Alias: "web2", Hostname: "10.0.0.2", GroupID: "g1", IdentityFile: "~/.ssh/prod", ProxyJump: "bastion"},
+		{Alias: "db", Hostname: "10.0.0.3", GroupID: "g1", User: "postgres", IdentityFile: "~/.ssh/prod", ProxyCommand: "nc %h %p"},
+		{Alias: "lone", Hostname: "10.0.0.4", GroupID: "g2", User: "me"},
+	}, groups)
+	var buf bytes.Buffer
+	fprintGroupedSSHConfig(&buf, groups, hosts)
+	want := `Host web1
+    HostName 10.0.0.1
+    User deploy
+    ProxyJump bastion
+
+Host web2
+    HostName 10.0.0.2
+    User deploy
+    ProxyJump bastion
+
+Host db
+    HostName 10.0.0.3
+    User postgres
+    ProxyCommand nc %h %p
+
+Host lone
+    HostName 10.0.0.4
+    User me
+
+# Group: prod
+Host web1 web2 db
+    IdentityFile ~/.ssh/prod
+
+`
+	if got := buf.String(); got != want {
+		t.Fatalf("unexpected grouped export:\n%s\nwant:\n%s", got, want)
+	}
+	if hosts[0].IdentityFile != "~/.ssh/prod" {
+		t.Fatal("expected the hosts passed in to be left alone")
+	}
+}
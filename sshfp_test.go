@@ -0,0 +1,29 @@
+package main
+
+import "testing"
+
+func TestParseSSHFPDebug(t *testing.T) {
+	tests := []struct {
+		name   string
+		stderr string
+		want   sshfpResult
+	}{
+		{"secure match", "debug1: found 2 secure fingerprints in DNS\ndebug1: matching host key fingerprint found in DNS\n", sshfpVerified},
+		{"insecure match", "debug1: found 2 insecure fingerprints in DNS\ndebug1: matching host key fingerprint found in DNS\n", sshfpInsecure},
+		{"mismatch", "debug1: found 1 secure fingerprints in DNS\ndebug1: mismatching host key fingerprint found in DNS\n", sshfpMismatch},
+		{"lookup error", "DNS lookup error: name does not exist\n", sshfpLookupError},
+		{"no records", "debug1: Connecting to web port 22.\n", sshfpNoRecords},
+	}
+	for _, tt := range tests {
+		if got := parseSSHFPDebug(tt.stderr); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestStripSSHDebug(t *testing.T) {
+	stderr := "OpenSSH_9.6p1, OpenSSL 3.0.13\ndebug1: Reading configuration data\nroot@web: Permission denied (publickey).\n"
+	if got := stripSSHDebug(stderr); got != "root@web: Permission denied (publickey).\n" {
+		t.Fatalf("unexpected stripped output %q", got)
+	}
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// --- Per-host Connection Statistics ---
+
+type HostStats struct {
+	Connections    int    `json:"connections,omitempty"`
+	Tests          int    `json:"tests,omitempty"`
+	Failures       int    `json:"failures,omitempty"`
+	LatencyTotalMs int64  `json:"latency_total_ms,omitempty"`
+	LatencySamples int    `json:"latency_samples,omitempty"`
+	LastLatencyMs  int64  `json:"last_latency_ms,omitempty"`
+	LastFailure    string `json:"last_failure,omitempty"`
+	LastFailureAt  int64  `json:"last_failure_at,omitempty"`
+	LastTestAt     int64  `json:"last_test_at,omitempty"`
+	LastTestOK     bool   `json:"last_test_ok,omitempty"`
+
+	// Sessions and SessionSeconds count the sessions whose end was seen.
+	Sessions       int   `json:"sessions,omitempty"`
+	SessionSeconds int64 `json:"session_seconds,omitempty"`
+}
+
+// AverageLatency returns the mean duration of successful connection tests.
+func (s HostStats) AverageLatency() time.Duration {
+	if s.LatencySamples == 0 {
+		return 0
+	}
+	return time.Duration(s.LatencyTotalMs/int64(s.LatencySamples)) * time.Millisecond
+}
+
+// updateHostStats applies update to a copy of the host's stats so snapshots
+// taken before the change keep their original values.
+func (m *model) updateHostStats(hostID string, update func(*HostStats)) bool {
+	idx := findHostIndexByID(m.rawHosts, hostID)
+	if idx == -1 {
+		return false
+	}
+	var stats HostStats
+	if m.rawHosts[idx].Stats != nil {
+		stats = *m.rawHosts[idx].Stats
+	}
+	update(&stats)
+	m.rawHosts[idx].Stats = &stats
+	return true
+}
+
+func (m *model) recordTestStats(hostID string, latency time.Duration, err error) {
+	updated := m.updateHostStats(hostID, func(s *HostStats) {
+		s.Tests++
+		s.LastTestAt = time.Now().Unix()
+		s.LastTestOK = err == nil
+		if err != nil {
+			s.Failures++
+			s.LastFailure, _ = formatTestStatus(err)
+			s.LastFailureAt = time.Now().Unix()
+			return
+		}
+		s.LastLatencyMs = latency.Milliseconds()
+		s.LatencyTotalMs += latency.Milliseconds()
+		s.LatencySamples++
+	})
+	if updated {
+		_ = m.save()
+	}
+}
+
+// totalSessionTime is the time spent in sessions whose end was seen.
+func (s HostStats) totalSessionTime() string {
+	if s.Sessions == 0 {
+		return "—"
+	}
+	return formatSessionDuration(time.Duration(s.SessionSeconds) * time.Second)
+}
+
+func formatLatency(d time.Duration) string {
+	if d <= 0 {
+		return "—"
+	}
+	return fmt.Sprintf("%dms", d.Milliseconds())
+}
+
+// --- Stats Screen ---
+
+type statsSortKey int
+
+const (
+	statsSortConnections statsSortKey = iota
+	statsSortFailures
+	statsSortLatency
+	statsSortAlias
+	statsSortCount
+)
+
+func (k statsSortKey) String() string {
+	switch k {
+	case statsSortFailures:
+		return "failures"
+	case statsSortLatency:
+		return "latency"
+	case statsSortAlias:
+		return "alias"
+	default:
+		return "connections"
+	}
+}
+
+func sortedStatsHosts(hosts []Host, key statsSortKey) []Host {
+	out := make([]Host, 0, len(hosts))
+	for _, h := range hosts {
+		if !h.IsContainer {
+			out = append(out, h)
+		}
+	}
+	stat := func(h Host) HostStats {
+		if h.Stats == nil {
+			return HostStats{}
+		}
+		return *h.Stats
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		a, b := stat(out[i]), stat(out[j])
+		switch key {
+		case statsSortFailures:
+			if a.Failures != b.Failures {
+				return a.Failures > b.Failures
+			}
+		case statsSortLatency:
+			if a.AverageLatency() != b.AverageLatency() {
+				return a.AverageLatency() > b.AverageLatency()
+			}
+		case statsSortAlias:
+			return strings.ToLower(out[i].Alias) < strings.ToLower(out[j].Alias)
+		default:
+			if a.Connections != b.Connections {
+				return a.Connections > b.Connections
+			}
+		}
+		return strings.ToLower(out[i].Alias) < strings.ToLower(out[j].Alias)
+	})
+	return out
+}
+
+func (m model) updateStats(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		m.quitting = true
+		return m, tea.Quit
+	case "S", "esc", "q":
+		m.state = stateList
+	case "s":
+		m.statsSort = (m.statsSort + 1) % statsSortCount
+	}
+	return m, nil
+}
+
+func (m model) renderStatsView() string {
+	width, height := normalizedSize(m.width, m.height)
+	inner := max(min(width-6, 100)-6, 24)
+	var b strings.Builder
+	b.WriteString(lipgloss.NewStyle().Bold(true).Foreground(colorText).Render("CONNECTION STATISTICS") + "\n")
+
+	hosts := sortedStatsHosts(m.rawHosts, m.statsSort)
+	var total HostStats
+	for _, h := range hosts {
+		if h.Stats == nil {
+			continue
+		}
+		total.Connections += h.Stats.Connections
+		total.Tests += h.Stats.Tests
+		total.Failures += h.Stats.Failures
+		total.LatencyTotalMs += h.Stats.LatencyTotalMs
+		total.LatencySamples += h.Stats.LatencySamples
+	}
+	b.WriteString(formHintStyle.Render(fmt.Sprintf("%d connections · %d tests · %d failures · avg %s · sorted by %s",
+		total.Connections, total.Tests, total.Failures, formatLatency(total.AverageLatency()), m.statsSort)) + "\n\n")
+
+	header := fmt.Sprintf("%-20s %6s %6s %6s %8s %8s  %s", "ALIAS", "CONN", "TESTS", "FAILS", "AVG", "TIME", "LAST FAILURE")
+	b.WriteString(formSectionStyle.Render(ansi.Truncate(header, inner, "")) + "\n")
+	maxRows := max(height-14, 3)
+	for i, h := range hosts {
+		if i >= maxRows {
+			b.WriteString(formHintStyle.Render(fmt.Sprintf("… %d more", len(hosts)-maxRows)) + "\n")
+			break
+		}
+		var s HostStats
+		if h.Stats != nil {
+			s = *h.Stats
+		}
+		line := fmt.Sprintf("%-20s %6d %6d %6d %8s %8s  %s", ansi.Truncate(h.Alias, 20, "…"), s.Connections, s.Tests, s.Failures, formatLatency(s.AverageLatency()), s.totalSessionTime(), s.LastFailure)
+		b.WriteString(ansi.Truncate(line, inner, "…") + "\n")
+	}
+	b.WriteString("\n" + helpEntry("s", "sort") + "  " + helpEntry("esc", "back"))
+	return centeredWorkspace(b.String(), width, height)
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestRecordTestStatsTracksLatencyAndFailures(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("ASSHO_STORE_PASSWORD", "0")
+	m := model{rawHosts: []Host{{ID: "h1", Alias: "web", Hostname: "10.0.0.1"}}}
+
+	m.recordTestStats("h1", 100*time.Millisecond, nil)
+	m.recordTestStats("h1", 300*time.Millisecond, nil)
+	m.recordTestStats("h1", 0, errors.New("Permission denied (publickey)"))
+
+	s := m.rawHosts[0].Stats
+	if s == nil {
+		t.Fatal("expected stats to be recorded")
+	}
+	if s.Tests != 3 || s.Failures != 1 {
+		t.Fatalf("expected 3 tests and 1 failure, got %+v", *s)
+	}
+	if got := s.AverageLatency(); got != 200*time.Millisecond {
+		t.Fatalf("expected 200ms average latency, got %v", got)
+	}
+	if !strings.Contains(s.LastFailure, "Permission denied") || s.LastFailureAt == 0 {
+		t.Fatalf("expected last failure to be recorded, got %+v", *s)
+	}
+}
+
+func TestUpdateHostStatsDoesNotMutateSnapshots(t *testing.T) {
+	m := model{rawHosts: []Host{{ID: "h1", Alias: "web", Stats: &HostStats{Connections: 1}}}}
+	snapshot := m.snapshot()
+	m.updateHostStats("h1", func(s *HostStats) { s.Connections++ })
+	if snapshot.rawHosts[0].Stats.Connections != 1 {
+		t.Fatalf("snapshot stats changed to %d", snapshot.rawHosts[0].Stats.Connections)
+	}
+	if m.rawHosts[0].Stats.Connections != 2 {
+		t.Fatalf("expected 2 connections, got %d", m.rawHosts[0].Stats.Connections)
+	}
+}
+
+func TestSortedStatsHostsOrdersByMostUsed(t *testing.T) {
+	hosts := []Host{
+		{ID: "a", Alias: "alpha", Stats: &HostStats{Connections: 1, Failures: 5}},
+		{ID: "b", Alias: "bravo", Stats: &HostStats{Connections: 9}},
+		{ID: "c", Alias: "charlie"},
+	}
+	byUse := sortedStatsHosts(hosts, statsSortConnections)
+	if byUse[0].Alias != "bravo" || byUse[2].Alias != "charlie" {
+		t.Fatalf("unexpected connection order: %s, %s, %s", byUse[0].Alias, byUse[1].Alias, byUse[2].Alias)
+	}
+	byFailures := sortedStatsHosts(hosts, statsSortFailures)
+	if byFailures[0].Alias != "alpha" {
+		t.Fatalf("expected alpha first by failures, got %s", byFailures[0].Alias)
+	}
+}
+
+func TestSaveFromFormPreservesStats(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("ASSHO_STORE_PASSWORD", "0")
+	host := Host{ID: "h1", Alias: "web", Hostname: "10.0.0.1", User: "root", Port: "22", Stats: &HostStats{Connections: 4}}
+	m := model{rawHosts: []Host{host}, form: formState{inputs: newFormInputs()}, historyList: newTestHistoryListModel()}
+	m.list = newTestListModel(nil, m.rawHosts)
+	m.form.selectedHost = &host
+	m.populateForm(host)
+	m.form.inputs[fieldHostname].SetValue("10.0.0.2")
+
+	if err := m.saveFromForm(); err != nil {
+		t.Fatalf("saveFromForm: %v", err)
+	}
+	if m.rawHosts[0].Stats == nil || m.rawHosts[0].Stats.Connections != 4 {
+		t.Fatalf("stats were not preserved: %+v", m.rawHosts[0].Stats)
+	}
+}
+
+func TestDetailAndStatsViewsFitTerminal(t *testing.T) {
+	host := Host{ID: "h1", Alias: "web", Hostname: "10.0.0.1", User: "root", Owner: "sam", Team: "platform", Contact: "#platform-oncall", ExpiresAt: "2020-01-01", Stats: &HostStats{Connections: 3, Tests: 2, Failures: 1, LastFailure: "Connection refused", LastFailureAt: 1}}
+	for _, size := range []struct{ width, height int }{{40, 16}, {80, 24}, {140, 40}} {
+		m := model{width: size.width, height: size.height, rawHosts: []Host{host}, detailHostID: "h1"}
+		for name, out := range map[string]string{"detail": m.renderDetailView(), "stats": m.renderStatsView()} {
+			lines := strings.Split(out, "\n")
+			if len(lines) > size.height {
+				t.Fatalf("%s %dx%d: got %d lines", name, size.width, size.height, len(lines))
+			}
+			for i, line := range lines {
+				if ansi.StringWidth(line) > size.width {
+					t.Fatalf("%s %dx%d line %d has width %d", name, size.width, size.height, i, ansi.StringWidth(line))
+				}
+			}
+		}
+		if size.height >= 40 && !strings.Contains(ansi.Strip(m.renderDetailView()), "#platform-oncall") {
+			t.Fatalf("expected ownership contact in detail view")
+		}
+	}
+}
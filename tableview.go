@@ -0,0 +1,304 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// --- Table View ---
+
+// b switches the dashboard to a flat table of every host with alias, host,
+// user, port, group, last connection, and status columns, for large
+// inventories that are easier to scan in columns than as a tree. 1-7 sort by
+// a column and pressing the same digit again reverses it; s steps through
+// the columns. / filters the rows, and enter connects as on the dashboard.
+
+type tableColumn int
+
+const (
+	tableAlias tableColumn = iota
+	tableHost
+	tableUser
+	tablePort
+	tableGroup
+	tableLast
+	tableStatus
+	tableColumnCount
+)
+
+func (c tableColumn) String() string {
+	return [...]string{"ALIAS", "HOST", "USER", "PORT", "GROUP", "LAST", "STATUS"}[c]
+}
+
+type tableViewState struct {
+	sort      tableColumn
+	desc      bool
+	cursor    int
+	filter    textinput.Model
+	filtering bool
+}
+
+type tableRow struct {
+	host   Host
+	group  string
+	last   int64
+	status string
+}
+
+// tableStatusRank orders statuses for sorting, the ones needing attention
+// first.
+var tableStatusRank = map[string]int{"down": 0, "expired": 1, "maintenance": 2, "ok": 3, "untested": 4}
+
+func hostTableStatus(h Host, now time.Time) string {
+	switch {
+	case h.inMaintenance(now):
+		return "maintenance"
+	case h.reportedDown(now):
+		return "down"
+	case h.Expired(now):
+		return "expired"
+	case h.Stats != nil && h.Stats.LastTestAt > 0 && h.Stats.LastTestOK:
+		return "ok"
+	}
+	return "untested"
+}
+
+// tableRows lists the hosts the table shows, filtered and sorted. Archived
+// hosts are left out unless the dashboard shows them.
+func (m model) tableRows() []tableRow {
+	now := time.Now()
+	last := buildLastConnected(m.history)
+	query := strings.ToLower(strings.TrimSpace(m.table.filter.Value()))
+	var rows []tableRow
+	for _, h := range m.listHosts() {
+		if h.IsContainer || (h.Archived && !m.showArchived) {
+			continue
+		}
+		row := tableRow{host: h, last: last[h.ID], status: hostTableStatus(h, now)}
+		if idx := findGroupIndexByID(m.rawGroups, h.GroupID); idx != -1 {
+			row.group = m.rawGroups[idx].Name
+		}
+		if query != "" && !strings.Contains(strings.ToLower(strings.Join([]string{h.Alias, h.Hostname, h.User, row.group}, " ")), query) {
+			continue
+		}
+		rows = append(rows, row)
+	}
+	slices.SortStableFunc(rows, func(a, b tableRow) int {
+		var c int
+		switch m.table.sort {
+		case tableAlias:
+			c = cmp.Compare(strings.ToLower(a.host.Alias), strings.ToLower(b.host.Alias))
+		case tableHost:
+			c = cmp.Compare(strings.ToLower(a.host.Hostname), strings.ToLower(b.host.Hostname))
+		case tableUser:
+			c = cmp.Compare(strings.ToLower(a.host.User), strings.ToLower(b.host.User))
+		case tablePort:
+			c = cmp.Compare(tablePortNumber(a.host), tablePortNumber(b.host))
+		case tableGroup:
+			c = cmp.Compare(strings.ToLower(a.group), strings.ToLower(b.group))
+		case tableLast:
+			// Most recent first reads best for "last connected".
+			c = cmp.Compare(b.last, a.last)
+		case tableStatus:
+			c = cmp.Compare(tableStatusRank[a.status], tableStatusRank[b.status])
+		}
+		if c == 0 && m.table.sort != tableAlias {
+			return cmp.Compare(strings.ToLower(a.host.Alias), strings.ToLower(b.host.Alias))
+		}
+		if m.table.desc {
+			return -c
+		}
+		return c
+	})
+	return rows
+}
+
+func tablePortNumber(h Host) int {
+	port, err := strconv.Atoi(cmp.Or(h.Port, "22"))
+	if err != nil {
+		return 22
+	}
+	return port
+}
+
+func (m model) openTableView() (tea.Model, tea.Cmd) {
+	m.clearListDeleteConfirm()
+	if m.table.filter.Prompt == "" {
+		m.table.filter = newPromptInput("/ ", "alias, host, user, or group")
+	}
+	m.table.cursor = 0
+	if h, ok := m.list.SelectedItem().(Host); ok {
+		for i, row := range m.tableRows() {
+			if row.host.ID == h.ID {
+				m.table.cursor = i
+			}
+		}
+	}
+	m.state = stateTable
+	return m, nil
+}
+
+func (m model) updateTableView(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	s := &m.table
+	if s.filtering {
+		switch msg.String() {
+		case "ctrl+c":
+			m.quitting = true
+			return m, tea.Quit
+		case "esc":
+			s.filter.SetValue("")
+			fallthrough
+		case "enter", "down", "up":
+			s.filtering = false
+			s.filter.Blur()
+			return m, nil
+		}
+		var cmd tea.Cmd
+		s.filter, cmd = s.filter.Update(msg)
+		s.cursor = 0
+		return m, cmd
+	}
+	rows := m.tableRows()
+	switch key := msg.String(); key {
+	case "ctrl+c":
+		m.quitting = true
+		return m, tea.Quit
+	case "esc":
+		if s.filter.Value() != "" {
+			s.filter.SetValue("")
+			return m, nil
+		}
+		m.state = stateList
+	case "b", "q":
+		m.state = stateList
+	case "/":
+		s.filtering = true
+		return m, s.filter.Focus()
+	case "up", "k":
+		s.cursor = max(s.cursor-1, 0)
+	case "down", "j":
+		s.cursor = min(s.cursor+1, max(len(rows)-1, 0))
+	case "pgup":
+		s.cursor = max(s.cursor-m.tableVisibleRows(), 0)
+	case "pgdown":
+		s.cursor = min(s.cursor+m.tableVisibleRows(), max(len(rows)-1, 0))
+	case "home", "g":
+		s.cursor = 0
+	case "end", "G":
+		s.cursor = max(len(rows)-1, 0)
+	case "s":
+		s.sort, s.desc = (s.sort+1)%tableColumnCount, false
+	case "1", "2", "3", "4", "5", "6", "7":
+		column := tableColumn(key[0] - '1')
+		if s.sort == column {
+			s.desc = !s.desc
+		} else {
+			s.sort, s.desc = column, false
+		}
+	case "enter":
+		if s.cursor < len(rows) {
+			return m.connectToHost(rows[s.cursor].host)
+		}
+	}
+	return m, nil
+}
+
+// tableVisibleRows is how many rows fit below the title, header, and help.
+func (m model) tableVisibleRows() int {
+	_, height := normalizedSize(m.width, m.height)
+	return max(height-9, 3)
+}
+
+// tableCell pads or cuts s to exactly width cells.
+func tableCell(s string, width int) string {
+	s = ansi.Truncate(s, width, "…")
+	return s + strings.Repeat(" ", max(width-ansi.StringWidth(s), 0))
+}
+
+func (m model) renderTableView() string {
+	width, _ := normalizedSize(m.width, m.height)
+	inner := max(width-6, 40)
+	s := m.table
+	rows := m.tableRows()
+
+	// Columns fit their widest cell within a cap, and the header with its
+	// sort arrow.
+	var widths [tableColumnCount]int
+	for c := range tableColumnCount {
+		widths[c] = len(c.String()) + 2
+	}
+	widths[tableLast], widths[tableStatus] = max(widths[tableLast], 8), 11
+	for _, row := range rows {
+		widths[tableAlias] = max(widths[tableAlias], min(ansi.StringWidth(row.host.Alias), 24))
+		widths[tableHost] = max(widths[tableHost], min(ansi.StringWidth(bareHostname(row.host.Hostname)), 32))
+		widths[tableUser] = max(widths[tableUser], min(ansi.StringWidth(row.host.User), 14))
+		widths[tableGroup] = max(widths[tableGroup], min(ansi.StringWidth(row.group), 16))
+	}
+
+	var header []string
+	for c := range tableColumnCount {
+		label := c.String()
+		if c == s.sort && s.desc {
+			label += " ▼"
+		} else if c == s.sort {
+			label += " ▲"
+		}
+		header = append(header, tableCell(label, widths[c]))
+	}
+
+	var b strings.Builder
+	title := fmt.Sprintf("Hosts · %d", len(rows))
+	b.WriteString(formTitleStyle.Render(title) + "\n")
+	if s.filtering || s.filter.Value() != "" {
+		b.WriteString(s.filter.View() + "\n")
+	} else {
+		b.WriteString(formHintStyle.Render("sorted by "+strings.ToLower(s.sort.String())) + "\n")
+	}
+	b.WriteString(formSectionStyle.Render(ansi.Truncate("  "+strings.Join(header, "  "), inner, "")) + "\n")
+
+	visible := m.tableVisibleRows()
+	start := max(min(s.cursor-visible/2, len(rows)-visible), 0)
+	for i := start; i < min(start+visible, len(rows)); i++ {
+		row := rows[i]
+		port := cmp.Or(row.host.Port, "22")
+		lastLabel := "never"
+		if row.last > 0 {
+			lastLabel = relativeTime(row.last)
+		}
+		cells := []string{
+			tableCell(row.host.Alias, widths[tableAlias]),
+			tableCell(bareHostname(row.host.Hostname), widths[tableHost]),
+			tableCell(row.host.User, widths[tableUser]),
+			tableCell(port, widths[tablePort]),
+			tableCell(row.group, widths[tableGroup]),
+			tableCell(lastLabel, widths[tableLast]),
+		}
+		status := tableCell(row.status, widths[tableStatus])
+		if i != s.cursor {
+			switch row.status {
+			case "down", "expired":
+				status = testFailStyle.Render(status)
+			case "maintenance":
+				status = lipgloss.NewStyle().Foreground(colorSecondary).Render(status)
+			case "ok":
+				status = testSuccessStyle.Render(status)
+			}
+		}
+		line := ansi.Truncate(strings.Join(append(cells, status), "  "), inner-2, "…")
+		b.WriteString(selectionLine(i == s.cursor, line) + "\n")
+	}
+	if len(rows) == 0 {
+		b.WriteString(formHintStyle.Render("  no hosts match") + "\n")
+	}
+	help := helpEntry("enter", "connect") + "  " + helpEntry("1-7", "sort") + "  " + helpEntry("/", "filter") + "  " + helpEntry("b", "tree") + "  " + helpEntry("esc", "back")
+	return appStyle.Render(b.String() + "\n" + help)
+}
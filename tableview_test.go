@@ -0,0 +1,86 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func tableTestModel() model {
+	groups := []Group{{ID: "g1", Name: "prod"}}
+	hosts := []Host{
+		{ID: "h1", Alias: "web", Hostname: "web.example.com", User: "deploy", GroupID: "g1", Stats: &HostStats{LastTestAt: 1, LastTestOK: true}},
+		{ID: "h2", Alias: "db", Hostname: "db.example.com", User: "postgres", Port: "5433", Stats: &HostStats{LastTestAt: 1}},
+		{ID: "h3", Alias: "cache", Hostname: "cache.example.com", User: "root", Archived: true},
+		{ID: "h4", Alias: "api", Hostname: "api.example.com", User: "root", Port: "2200", GroupID: "g1"},
+	}
+	history := []HistoryEntry{{HostID: "h4", Timestamp: time.Now().Add(-time.Hour).Unix()}}
+	return model{state: stateList, rawGroups: groups, rawHosts: hosts, history: history, list: newTestListModel(groups, hosts), width: 120, height: 30}
+}
+
+func tableAliases(m model) string {
+	var aliases []string
+	for _, row := range m.tableRows() {
+		aliases = append(aliases, row.host.Alias)
+	}
+	return strings.Join(aliases, " ")
+}
+
+func TestTableViewSortsByColumn(t *testing.T) {
+	result, _ := tableTestModel().updateList(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("b")})
+	m := result.(model)
+	if m.state != stateTable || tableAliases(m) != "api db web" {
+		t.Fatalf("expected archived hosts left out and alias order, got %q", tableAliases(m))
+	}
+	press := func(key string) {
+		result, _ := m.updateTableView(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+		m = result.(model)
+	}
+	press("4")
+	if got := tableAliases(m); got != "web api db" {
+		t.Fatalf("expected port order, got %q", got)
+	}
+	press("4")
+	if got := tableAliases(m); got != "db api web" {
+		t.Fatalf("expected the port order reversed, got %q", got)
+	}
+	press("6")
+	if got := tableAliases(m); got != "api db web" {
+		t.Fatalf("expected the recently used host first, got %q", got)
+	}
+	press("7")
+	if got := tableAliases(m); got != "db web api" || m.tableRows()[0].status != "down" {
+		t.Fatalf("expected the failed host first, got %q", got)
+	}
+}
+
+func TestTableViewFilterAndRender(t *testing.T) {
+	result, _ := tableTestModel().updateList(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("b")})
+	m := result.(model)
+	result, _ = m.updateTableView(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("/")})
+	m = result.(model)
+	for _, r := range "prod" {
+		result, _ = m.updateTableView(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+		m = result.(model)
+	}
+	if got := tableAliases(m); got != "api web" {
+		t.Fatalf("expected the group filter, got %q", got)
+	}
+	view := m.renderTableView()
+	for _, want := range []string{"Hosts · 2", "ALIAS ▲", "GROUP", "api.example.com", "1h ago", "never"} {
+		if !strings.Contains(view, want) {
+			t.Fatalf("expected %q in\n%s", want, view)
+		}
+	}
+	result, _ = m.updateTableView(tea.KeyMsg{Type: tea.KeyEsc})
+	m = result.(model)
+	if m.table.filtering || tableAliases(m) != "api db web" {
+		t.Fatalf("expected esc to clear the filter, got %q", tableAliases(m))
+	}
+	result, _ = m.updateTableView(tea.KeyMsg{Type: tea.KeyEsc})
+	if result.(model).state != stateList {
+		t.Fatal("expected esc to return to the tree")
+	}
+}
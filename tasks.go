@@ -0,0 +1,374 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// --- Background Tasks ---
+
+// Container scans, connection tests, transfers, and compose runs register a
+// task while they are in flight, keyed by kind and host, so several can run
+// at once and J lists them all. Each task carries the cancel func of the
+// context its command runs under, so cancelling kills the process at once;
+// esc does the same for scans on the dashboard and a test in the form.
+// Failed and cancelled tasks can be retried from the list.
+// Automatic 30-second rescans are not tracked.
+
+const maxFinishedTasks = 20
+
+type taskKind int
+
+const (
+	taskScan taskKind = iota
+	taskTest
+	taskTransfer
+	taskCompose
+)
+
+func (k taskKind) String() string {
+	switch k {
+	case taskTest:
+		return "test"
+	case taskTransfer:
+		return "transfer"
+	case taskCompose:
+		return "compose"
+	}
+	return "scan"
+}
+
+type taskStatus int
+
+const (
+	taskRunning taskStatus = iota
+	taskDone
+	taskFailed
+	taskCancelled
+)
+
+type task struct {
+	kind     taskKind
+	hostID   string
+	label    string
+	status   taskStatus
+	progress string
+	err      string
+	started  time.Time
+	finished time.Time
+	// discard is set when a scan or test is cancelled; the result it still
+	// delivers is dropped.
+	discard bool
+	cancel  func()
+	retry   func(model) (model, tea.Cmd)
+}
+
+type taskManager struct {
+	tasks  []task
+	open   bool
+	cursor int
+}
+
+// start registers a running task.
+func (t *taskManager) start(kind taskKind, hostID, label string, retry func(model) (model, tea.Cmd)) {
+	t.tasks = append(t.tasks, task{
+		kind:    kind,
+		hostID:  hostID,
+		label:   label,
+		started: time.Now(),
+		retry:   retry,
+	})
+	t.prune()
+}
+
+// prune keeps every running task and the newest finished ones.
+func (t *taskManager) prune() {
+	finished := 0
+	for i := len(t.tasks) - 1; i >= 0; i-- {
+		if t.tasks[i].status == taskRunning || t.tasks[i].discard {
+			continue
+		}
+		if finished++; finished > maxFinishedTasks {
+			t.tasks = append(t.tasks[:i], t.tasks[i+1:]...)
+		}
+	}
+	t.cursor = min(t.cursor, max(len(t.tasks)-1, 0))
+}
+
+// running returns the running task of kind for hostID, or nil.
+func (t *taskManager) running(kind taskKind, hostID string) *task {
+	for i := len(t.tasks) - 1; i >= 0; i-- {
+		if task := &t.tasks[i]; task.kind == kind && task.hostID == hostID && task.status == taskRunning {
+			return task
+		}
+	}
+	return nil
+}
+
+func (t *taskManager) runningCount(kind taskKind) int {
+	count := 0
+	for _, task := range t.tasks {
+		if task.kind == kind && task.status == taskRunning {
+			count++
+		}
+	}
+	return count
+}
+
+// finish records a result for the kind and host. It reports false when the
+// task was cancelled and the result should be dropped; results nobody
+// registered a task for pass through.
+func (t *taskManager) finish(kind taskKind, hostID string, err error) bool {
+	for i := len(t.tasks) - 1; i >= 0; i-- {
+		task := &t.tasks[i]
+		if task.kind != kind || task.hostID != hostID {
+			continue
+		}
+		if task.discard {
+			task.discard = false
+			return false
+		}
+		if task.status != taskRunning {
+			continue
+		}
+		task.status, task.finished, task.cancel = taskDone, time.Now(), nil
+		if err != nil {
+			task.status, task.err = taskFailed, err.Error()
+		}
+		t.prune()
+		return true
+	}
+	return true
+}
+
+// markCancelled ends the running task of kind for hostID as cancelled.
+func (t *taskManager) markCancelled(kind taskKind, hostID string) {
+	if task := t.running(kind, hostID); task != nil {
+		task.status, task.finished, task.cancel = taskCancelled, time.Now(), nil
+	}
+}
+
+func (t *taskManager) setProgress(kind taskKind, hostID, line string) {
+	if task := t.running(kind, hostID); task != nil {
+		task.progress = line
+	}
+}
+
+func (t *taskManager) setCancel(kind taskKind, hostID string, cancel func()) {
+	if task := t.running(kind, hostID); task != nil {
+		task.cancel = cancel
+	}
+}
+
+func (t *taskManager) clearFinished() {
+	kept := t.tasks[:0]
+	for _, task := range t.tasks {
+		if task.status == taskRunning || task.discard {
+			kept = append(kept, task)
+		}
+	}
+	t.tasks = kept
+	t.cursor = min(t.cursor, max(len(t.tasks)-1, 0))
+}
+
+// cancelTask stops the task at index i. The screens that own a transfer or
+// compose run are told, so they report the cancellation rather than an error;
+// a cancelled scan or test has its result dropped.
+func (m *model) cancelTask(i int) {
+	task := &m.tasks.tasks[i]
+	if task.status != taskRunning {
+		return
+	}
+	if task.cancel != nil {
+		task.cancel()
+	}
+	task.status, task.finished, task.cancel = taskCancelled, time.Now(), nil
+	switch task.kind {
+	case taskTransfer:
+		m.transfer.cancelled = true
+	case taskCompose:
+		m.compose.cancelled = true
+	case taskScan:
+		task.discard = true
+		if idx := findHostIndexByID(m.rawHosts, task.hostID); idx >= 0 && len(m.rawHosts[idx].Containers) == 0 {
+			m.rawHosts[idx].Expanded = false
+			m.refreshList()
+		}
+	case taskTest:
+		task.discard = true
+	}
+}
+
+// cancelTaskFor cancels the running task of kind for hostID, if any.
+func (m *model) cancelTaskFor(kind taskKind, hostID string) bool {
+	for i := len(m.tasks.tasks) - 1; i >= 0; i-- {
+		if t := m.tasks.tasks[i]; t.kind == kind && t.hostID == hostID && t.status == taskRunning {
+			m.cancelTask(i)
+			return true
+		}
+	}
+	return false
+}
+
+// cancelRunning cancels every running task of kind and reports how many.
+func (m *model) cancelRunning(kind taskKind) int {
+	cancelled := 0
+	for i := range m.tasks.tasks {
+		if m.tasks.tasks[i].kind == kind && m.tasks.tasks[i].status == taskRunning {
+			m.cancelTask(i)
+			cancelled++
+		}
+	}
+	return cancelled
+}
+
+func (m model) openTasks() (tea.Model, tea.Cmd) {
+	m.tasks.open = true
+	m.tasks.cursor = max(len(m.tasks.tasks)-1, 0)
+	return m, nil
+}
+
+func (m model) updateTasks(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		m.quitting = true
+		return m, tea.Quit
+	case "esc", "q", "J":
+		m.tasks.open = false
+	case "up", "k":
+		if m.tasks.cursor > 0 {
+			m.tasks.cursor--
+		}
+	case "down", "j":
+		if m.tasks.cursor < len(m.tasks.tasks)-1 {
+			m.tasks.cursor++
+		}
+	case "x":
+		if m.tasks.cursor < len(m.tasks.tasks) {
+			m.cancelTask(m.tasks.cursor)
+		}
+	case "r":
+		if m.tasks.cursor >= len(m.tasks.tasks) {
+			return m, nil
+		}
+		task := m.tasks.tasks[m.tasks.cursor]
+		if task.retry == nil || (task.status != taskFailed && task.status != taskCancelled) {
+			return m, nil
+		}
+		m.tasks.open = false
+		return task.retry(m)
+	case "c":
+		m.tasks.clearFinished()
+	}
+	return m, nil
+}
+
+func (t task) icon(spinner string) string {
+	switch t.status {
+	case taskDone:
+		return testSuccessStyle.Render("✔")
+	case taskFailed:
+		return testFailStyle.Render("✘")
+	case taskCancelled:
+		return formHintStyle.Render("⊘")
+	}
+	return spinner
+}
+
+func (t task) detail(now time.Time) string {
+	switch t.status {
+	case taskRunning:
+		elapsed := now.Sub(t.started).Truncate(time.Second).String()
+		if t.progress != "" {
+			return elapsed + " · " + t.progress
+		}
+		return elapsed
+	case taskFailed:
+		return t.err
+	case taskCancelled:
+		return "cancelled"
+	}
+	return "done in " + t.finished.Sub(t.started).Round(100*time.Millisecond).String()
+}
+
+func (m model) renderTasksOverlay(base string) string {
+	width, height := normalizedSize(m.width, m.height)
+	modalWidth := min(80, max(width-6, 30))
+	inner := modalWidth - 4
+	var b strings.Builder
+	b.WriteString(lipgloss.NewStyle().Foreground(colorText).Bold(true).Render("TASKS") + "\n")
+	running := 0
+	for _, t := range m.tasks.tasks {
+		if t.status == taskRunning {
+			running++
+		}
+	}
+	b.WriteString(formHintStyle.Render(fmt.Sprintf("%d running · %d finished", running, len(m.tasks.tasks)-running)) + "\n\n")
+	if len(m.tasks.tasks) == 0 {
+		b.WriteString(formHintStyle.Render("Scans, tests, transfers, and compose runs show up here.") + "\n")
+	}
+	maxRows := max(height-14, 2)
+	start := 0
+	if m.tasks.cursor >= maxRows {
+		start = m.tasks.cursor - maxRows + 1
+	}
+	now := time.Now()
+	for i := start; i < min(start+maxRows, len(m.tasks.tasks)); i++ {
+		t := m.tasks.tasks[i]
+		line := t.icon(m.spinner.View()) + " " + t.kind.String() + " " + t.label + " · " + t.detail(now)
+		b.WriteString(selectionLine(i == m.tasks.cursor, ansi.Truncate(line, inner-2, "…")) + "\n")
+	}
+	b.WriteString("\n" + helpEntry("x", "cancel") + "  " + helpEntry("r", "retry") + "  " +
+		helpEntry("c", "clear finished") + "  " + helpEntry("esc", "close"))
+	modal := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(colorPrimary).
+		Padding(1, 2).
+		Width(modalWidth).
+		Render(b.String())
+	backdrop := fitViewToBounds(dimBase(base), width, height)
+	return fitViewToBounds(overlayCenter(backdrop, modal, width, height), width, height)
+}
+
+// startScan scans the host with hostID for containers unless a scan of it
+// is already running.
+func (m model) startScan(hostID string) (model, tea.Cmd) {
+	idx := findHostIndexByID(m.rawHosts, hostID)
+	if idx == -1 || m.tasks.running(taskScan, hostID) != nil {
+		return m, nil
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	m.tasks.start(taskScan, hostID, m.rawHosts[idx].Alias, func(m model) (model, tea.Cmd) {
+		return m.startScan(hostID)
+	})
+	m.tasks.setCancel(taskScan, hostID, cancel)
+	return m, scanDockerContainers(ctx, m.rawHosts[idx], m.inventory(), false)
+}
+
+// startTest runs a connection test for h unless one is already running.
+func (m model) startTest(h Host) (model, tea.Cmd) {
+	if m.tasks.running(taskTest, h.ID) != nil {
+		return m, nil
+	}
+	label := h.Alias
+	if label == "" {
+		label = h.Hostname
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	m.tasks.start(taskTest, h.ID, label, func(m model) (model, tea.Cmd) {
+		return m.startTest(h)
+	})
+	m.tasks.setCancel(taskTest, h.ID, cancel)
+	return m, testConnection(ctx, h, m.inventory())
+}
+
+// formTesting reports whether the form's host has a test in flight.
+func (m model) formTesting() bool {
+	return m.tasks.running(taskTest, m.formTestHost().ID) != nil
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestScansRunAsIndependentTasks(t *testing.T) {
+	m := model{rawHosts: []Host{
+		{ID: "a", Alias: "web", Hostname: "10.0.0.1"},
+		{ID: "b", Alias: "db", Hostname: "10.0.0.2"},
+	}}
+	m.list = newTestListModel(nil, m.rawHosts)
+
+	m, cmdA := m.startScan("a")
+	m, cmdB := m.startScan("b")
+	m, again := m.startScan("a")
+	if cmdA == nil || cmdB == nil || again != nil {
+		t.Fatal("expected one scan per host and no duplicate for a running one")
+	}
+	if got := m.tasks.runningCount(taskScan); got != 2 {
+		t.Fatalf("expected two running scans, got %d", got)
+	}
+
+	updated, _ := m.Update(scanDockerMsg{hostID: "b", containers: []Host{{ID: "c1", Alias: "pg", IsContainer: true, ParentID: "b"}}})
+	m = updated.(model)
+	if m.tasks.running(taskScan, "b") != nil || m.tasks.running(taskScan, "a") == nil {
+		t.Fatal("finishing one scan must leave the other running")
+	}
+	if len(m.rawHosts[1].Containers) != 1 {
+		t.Fatalf("scan result not applied: %+v", m.rawHosts[1])
+	}
+}
+
+func TestCancelledScanResultIsDropped(t *testing.T) {
+	m := model{rawHosts: []Host{{ID: "a", Alias: "web", Hostname: "10.0.0.1"}}}
+	m.list = newTestListModel(nil, m.rawHosts)
+	m, _ = m.startScan("a")
+
+	updated, _ := m.updateTasks(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x")})
+	m = updated.(model)
+	if m.tasks.tasks[0].status != taskCancelled {
+		t.Fatalf("expected the scan cancelled, got %+v", m.tasks.tasks[0])
+	}
+	updated, _ = m.Update(scanDockerMsg{hostID: "a", containers: []Host{{ID: "c1", IsContainer: true, ParentID: "a"}}})
+	m = updated.(model)
+	if len(m.rawHosts[0].Containers) != 0 {
+		t.Fatal("a cancelled scan's result should be dropped")
+	}
+
+	updated, cmd := m.updateTasks(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")})
+	m = updated.(model)
+	if cmd == nil || m.tasks.running(taskScan, "a") == nil {
+		t.Fatal("retry should start a new scan")
+	}
+}
+
+func TestScanResultFollowsMovedHost(t *testing.T) {
+	m := model{rawHosts: []Host{{ID: "a", Alias: "web", Hostname: "10.0.0.1"}, {ID: "b", Alias: "db", Hostname: "10.0.0.2"}}}
+	m.list = newTestListModel(nil, m.rawHosts)
+	m, _ = m.startScan("a")
+
+	// The host moves to the end while its scan runs.
+	m.rawHosts = []Host{m.rawHosts[1], m.rawHosts[0]}
+	updated, _ := m.Update(scanDockerMsg{hostID: "a", containers: []Host{{ID: "c1", IsContainer: true, ParentID: "a"}}})
+	m = updated.(model)
+	if len(m.rawHosts[1].Containers) != 1 || len(m.rawHosts[0].Containers) != 0 {
+		t.Fatalf("expected the containers on the scanned host, got %+v", m.rawHosts)
+	}
+	if m.tasks.running(taskScan, "a") != nil {
+		t.Fatal("expected the scan task finished")
+	}
+}
+
+func TestFailedTestIsRecorded(t *testing.T) {
+	m := model{}
+	m, _ = m.startTest(Host{ID: "h1", Alias: "web", Hostname: "10.0.0.1"})
+	updated, _ := m.Update(testConnectionMsg{hostID: "h1", err: errors.New("connection refused")})
+	m = updated.(model)
+	task := m.tasks.tasks[0]
+	if task.status != taskFailed || task.err != "connection refused" || task.retry == nil {
+		t.Fatalf("unexpected task %+v", task)
+	}
+}
+
+func TestTaskManagerKeepsRecentFinishedTasks(t *testing.T) {
+	var tm taskManager
+	tm.start(taskScan, "running", "kept", nil)
+	for i := 0; i < maxFinishedTasks+5; i++ {
+		tm.start(taskTest, "h", "t", nil)
+		tm.finish(taskTest, "h", nil)
+	}
+	if len(tm.tasks) != maxFinishedTasks+1 || tm.tasks[0].hostID != "running" {
+		t.Fatalf("expected the running task plus %d finished, got %d", maxFinishedTasks, len(tm.tasks))
+	}
+	tm.clearFinished()
+	if len(tm.tasks) != 1 {
+		t.Fatalf("clear should keep only running tasks, got %d", len(tm.tasks))
+	}
+}
+
+func TestEscCancelsRunningScan(t *testing.T) {
+	m := model{rawHosts: []Host{{ID: "a", Alias: "web", Hostname: "10.0.0.1", Expanded: true}}}
+	m.list = newTestListModel(nil, m.rawHosts)
+	m, _ = m.startScan("a")
+
+	updated, _ := m.updateList(tea.KeyMsg{Type: tea.KeyEsc})
+	m = updated.(model)
+	if m.tasks.runningCount(taskScan) != 0 || m.status.message != "Scan cancelled" {
+		t.Fatalf("expected esc to cancel the scan, got %+v / %q", m.tasks.tasks, m.status.message)
+	}
+	if m.rawHosts[0].Expanded {
+		t.Fatal("a host whose first scan was cancelled should collapse again")
+	}
+}
+
+func TestEscCancelsFormTestBeforeLeaving(t *testing.T) {
+	m := model{state: stateForm, form: newFormState(newFormInputs())}
+	m.form.inputs[fieldHostname].SetValue("10.0.0.1")
+	m, _ = m.startTest(m.formTestHost())
+
+	updated, _ := m.updateForm(tea.KeyMsg{Type: tea.KeyEsc})
+	m = updated.(model)
+	if m.state != stateForm || m.formTesting() || m.form.testStatus != "Test cancelled" {
+		t.Fatalf("first esc should cancel the test and stay in the form, got state %d status %q", m.state, m.form.testStatus)
+	}
+	updated, _ = m.updateForm(tea.KeyMsg{Type: tea.KeyEsc})
+	if updated.(model).state != stateList {
+		t.Fatal("second esc should leave the form")
+	}
+}
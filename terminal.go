@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// --- External Terminal ---
+
+// assho can act as a pure launcher. With ASSHO_TERMINAL set, or a host's
+// Terminal field, connecting from the dashboard opens the session in a new
+// terminal window and leaves the dashboard running instead of taking over
+// the current terminal. The setting is the command that runs a program in a
+// new window, such as `alacritty -e`, `kitty`, `wezterm start --`, or
+// `gnome-terminal --`, and ssh's command line is appended to it. A host's
+// value overrides the global one, and "none" keeps that host in the current
+// terminal. `assho connect` always runs in the terminal it was started from.
+
+// terminalNone is the Terminal value that keeps a host in the current
+// terminal when ASSHO_TERMINAL is set.
+const terminalNone = "none"
+
+// terminalLauncher is the command that opens h's sessions in a new window,
+// or nil to take over the current terminal.
+func terminalLauncher(h Host) []string {
+	value := strings.TrimSpace(h.Terminal)
+	if value == "" {
+		value = strings.TrimSpace(os.Getenv("ASSHO_TERMINAL"))
+	}
+	if value == "" || strings.EqualFold(value, terminalNone) {
+		return nil
+	}
+	return strings.Fields(value)
+}
+
+// validateTerminal checks a host's Terminal field.
+func validateTerminal(value string) error {
+	if strings.ContainsAny(value, "\r\n") {
+		return fmt.Errorf("terminal must be a single line")
+	}
+	if fields := strings.Fields(value); len(fields) > 0 && strings.HasPrefix(fields[0], "-") {
+		return fmt.Errorf("terminal must start with a program name")
+	}
+	return nil
+}
+
+// terminalWarning points out a Terminal program that is not installed.
+func terminalWarning(value string) string {
+	fields := strings.Fields(value)
+	if len(fields) == 0 || strings.EqualFold(fields[0], terminalNone) {
+		return ""
+	}
+	if _, err := exec.LookPath(fields[0]); err != nil {
+		return fields[0] + " not found in PATH"
+	}
+	return ""
+}
+
+// startTerminal starts argv in its own session so it outlives the
+// dashboard; tests replace it.
+var startTerminal = func(argv, env []string) error {
+	cmd := exec.Command(argv[0], argv[1:]...)
+	cmd.Env = env
+	detachProcess(cmd)
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	return cmd.Process.Release()
+}
+
+// launchInTerminal opens an interactive session with h through launcher.
+// The terminal sets TERM for the programs it runs, so a host's TERM is
+// passed through env instead of the terminal's environment.
+func launchInTerminal(h Host, inv hostInventory, launcher []string) (connectCommand, error) {
+	cmd, err := buildConnectCommand(h, inv, true)
+	if err != nil {
+		return cmd, err
+	}
+	argv := append([]string{}, launcher...)
+	if cmd.sshHost.Term != "" {
+		argv = append(argv, "env", "TERM="+cmd.sshHost.Term)
+	}
+	argv = append(argv, cmd.binary)
+	argv = append(argv, cmd.args...)
+	err = startTerminal(argv, cmd.environ())
+	recordAudit("connect", h.Alias, cmd.sshHost, err)
+	if err != nil {
+		return cmd, fmt.Errorf("could not start %s: %w", launcher[0], err)
+	}
+	return cmd, nil
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestTerminalLauncher(t *testing.T) {
+	t.Setenv("ASSHO_TERMINAL", "")
+	if got := terminalLauncher(Host{}); got != nil {
+		t.Fatalf("expected the current terminal by default, got %v", got)
+	}
+	t.Setenv("ASSHO_TERMINAL", "alacritty -e")
+	if got := terminalLauncher(Host{}); strings.Join(got, " ") != "alacritty -e" {
+		t.Fatalf("expected ASSHO_TERMINAL, got %v", got)
+	}
+	if got := terminalLauncher(Host{Terminal: "wezterm start --"}); strings.Join(got, " ") != "wezterm start --" {
+		t.Fatalf("expected the host's terminal to win, got %v", got)
+	}
+	if got := terminalLauncher(Host{Terminal: "none"}); got != nil {
+		t.Fatalf("expected none to keep the current terminal, got %v", got)
+	}
+	if err := validateTerminal("-e kitty"); err == nil {
+		t.Fatal("expected a leading flag to be rejected")
+	}
+}
+
+func TestConnectOpensExternalTerminal(t *testing.T) {
+	writeTempConfig(t, nil)
+	t.Setenv("ASSHO_TERMINAL", "")
+	var gotArgv []string
+	restore := startTerminal
+	startTerminal = func(argv, env []string) error {
+		gotArgv = argv
+		return nil
+	}
+	t.Cleanup(func() { startTerminal = restore })
+
+	host := Host{ID: "h1", Alias: "router", Hostname: "10.0.0.1", User: "admin", Port: "22", Term: "vt100", Terminal: "kitty"}
+	m := model{
+		state:       stateList,
+		rawHosts:    []Host{host},
+		list:        newTestListModel(nil, []Host{host}),
+		historyList: newTestHistoryListModel(),
+	}
+	updated, _ := m.connectToHostTrusted(host)
+	got := updated.(model)
+	if got.sshToRun != nil {
+		t.Fatal("expected the dashboard to keep running")
+	}
+	if len(gotArgv) < 4 || strings.Join(gotArgv[:3], " ") != "kitty env TERM=vt100" || !slices.Contains(gotArgv, "10.0.0.1") {
+		t.Fatalf("unexpected launch command %q", gotArgv)
+	}
+	if len(got.history) != 1 || !strings.Contains(got.status.message, "Opened router in kitty") {
+		t.Fatalf("expected history recorded and a status, got %q", got.status.message)
+	}
+}
@@ -2,9 +2,11 @@ package main
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/lipgloss"
 )
 
@@ -23,6 +25,18 @@ var (
 	colorDimText   = lipgloss.Color("#9CA3AF") // Dim text
 	colorHighlight = lipgloss.Color("#A78BFA") // Light purple
 
+	// Group tints, chosen to read on a dark background next to the core palette.
+	groupPalette = map[string]lipgloss.Color{
+		"red":    "#F87171",
+		"orange": "#FB923C",
+		"yellow": "#FACC15",
+		"green":  "#4ADE80",
+		"teal":   "#2DD4BF",
+		"blue":   "#60A5FA",
+		"purple": "#C084FC",
+		"pink":   "#F472B6",
+	}
+
 	// App chrome
 	appStyle = lipgloss.NewStyle().Padding(1, 2)
 
@@ -125,9 +139,22 @@ var (
 	spinnerStyle = lipgloss.NewStyle().Foreground(colorSecondary)
 )
 
+// newPromptInput is a one-line text input in the theme's prompt colors, as
+// used by the dashboard's small prompts and filters.
+func newPromptInput(prompt, placeholder string) textinput.Model {
+	input := textinput.New()
+	input.Prompt = prompt
+	input.Placeholder = placeholder
+	input.PromptStyle = lipgloss.NewStyle().Foreground(colorHighlight).Bold(true)
+	input.TextStyle = lipgloss.NewStyle().Foreground(colorText)
+	input.PlaceholderStyle = lipgloss.NewStyle().Foreground(colorSubtle)
+	input.Cursor.Style = lipgloss.NewStyle().Foreground(colorSecondary)
+	return input
+}
+
 // --- ASCII Art Header ---
 
-func renderHeader(frame int, hostCount int, containerCount int) string {
+func renderHeader(frame int, hostCount int, containerCount int, network, newRelease string) string {
 	logo := renderLogo(frame)
 
 	taglinePlain := "Another SSH Organizer"
@@ -145,6 +172,12 @@ func renderHeader(frame int, hostCount int, containerCount int) string {
 	if containerCount > 0 {
 		stats += headerDimStyle.Render(fmt.Sprintf(" · %d containers", containerCount))
 	}
+	if network != "" {
+		stats += headerDimStyle.Render(" · network " + network)
+	}
+	if newRelease != "" {
+		stats += headerDimStyle.Render(" · ") + lipgloss.NewStyle().Foreground(colorSecondary).Render(newRelease+" available: assho update")
+	}
 
 	return logo + tagline + "\n" + stats + "\n"
 }
@@ -155,7 +188,32 @@ func helpEntry(key, desc string) string {
 	return helpKeyStyle.Render(key) + " " + helpDescStyle.Render(desc)
 }
 
-func renderListHelp(selected list.Item) string {
+// fitHelpEntries joins as many entries as fit in width, in priority order.
+// When some are dropped the line ends with a pointer to the full reference.
+func fitHelpEntries(entries []string, width int) string {
+	sep := helpSepStyle.Render(" | ")
+	more := helpEntry("?", "more")
+	// helpBarStyle adds one cell of padding on each side.
+	avail := width - 2
+	if width <= 0 || lipgloss.Width(strings.Join(entries, sep)) <= avail {
+		return helpBarStyle.Render(strings.Join(entries, sep))
+	}
+	var kept []string
+	used := lipgloss.Width(more)
+	for _, entry := range entries {
+		w := lipgloss.Width(entry) + lipgloss.Width(sep)
+		if used+w > avail {
+			break
+		}
+		kept = append(kept, entry)
+		used += w
+	}
+	return helpBarStyle.Render(strings.Join(append(kept, more), sep))
+}
+
+// renderListHelp lists the keys for the selected row. hideSSH leaves out
+// the ones that need ssh, while it is missing.
+func renderListHelp(selected list.Item, width int, hideSSH bool) string {
 	var contextEntries []string
 
 	switch item := selected.(type) {
@@ -163,47 +221,98 @@ func renderListHelp(selected list.Item) string {
 		if item.IsContainer {
 			contextEntries = []string{
 				helpEntry("enter", "connect"),
+				helpEntry("m", "mark"),
+				helpEntry("s", "show command"),
+				helpEntry("F", "forward port"),
+				helpEntry("o", "running only"),
+			}
+		} else if item.isWindows() {
+			contextEntries = []string{
+				helpEntry("enter", "connect"),
+				helpEntry("m", "mark"),
+				helpEntry("v", "details"),
+				helpEntry("W", "services"),
+				helpEntry("s", "command"),
+				helpEntry("e", "edit"),
+				helpEntry("r", "rename"),
+				helpEntry("c", "duplicate"),
+				helpEntry("d", "delete"),
+				helpEntry("p", "pin"),
+				helpEntry("A", "archive"),
+				helpEntry("⇧↑↓", "move"),
+				helpEntry("⇧←→", "regroup"),
 			}
 		} else {
 			contextEntries = []string{
 				helpEntry("enter", "connect"),
+				helpEntry("m", "mark"),
+				helpEntry("v", "details"),
+				helpEntry("f", "first contact"),
+				helpEntry("s", "command"),
+				helpEntry("t", "transfer"),
+				helpEntry("u", "web UI"),
 				helpEntry("e", "edit"),
+				helpEntry("r", "rename"),
 				helpEntry("c", "duplicate"),
+				helpEntry("C", "bulk clone"),
 				helpEntry("d", "delete"),
 				helpEntry("p", "pin"),
+				helpEntry("A", "archive"),
 				helpEntry("space", "expand"),
 				helpEntry("ctrl+d", "scan"),
+				helpEntry("P", "compose"),
 				helpEntry("⇧↑↓", "move"),
+				helpEntry("⇧←→", "regroup"),
 			}
 		}
 	case groupItem:
 		contextEntries = []string{
 			helpEntry("enter", "toggle"),
+			helpEntry("ctrl+t", "test all"),
+			helpEntry("ctrl+d", "scan all"),
+			helpEntry("E", "export"),
 			helpEntry("r", "rename"),
+			helpEntry("R", "batch rename"),
 			helpEntry("d", "delete"),
 			helpEntry("⇧↑↓", "move"),
 		}
 	}
 
+	if hideSSH {
+		needsSSH := map[string]bool{
+			helpEntry("enter", "connect"):   true,
+			helpEntry("f", "first contact"): true,
+			helpEntry("t", "transfer"):      true,
+			helpEntry("ctrl+d", "scan"):     true,
+			helpEntry("P", "compose"):       true,
+			helpEntry("W", "services"):      true,
+			helpEntry("ctrl+t", "test all"): true,
+			helpEntry("ctrl+d", "scan all"): true,
+		}
+		contextEntries = slices.DeleteFunc(contextEntries, func(entry string) bool { return needsSSH[entry] })
+	}
+
 	baseEntries := []string{
 		helpEntry("n", "new"),
 		helpEntry("K", "rotate keys"),
+		helpEntry("H", "audit"),
+		helpEntry("T", "trash"),
 		helpEntry("g", "group"),
 		helpEntry("/", "filter"),
 		helpEntry("h", "history"),
+		helpEntry("S", "stats"),
+		helpEntry("J", "tasks"),
+		helpEntry("L", "tunnels"),
 		helpEntry("i", "import"),
 		helpEntry("a", "about"),
 		helpEntry("?", "help"),
 		helpEntry("q", "quit"),
 	}
 
-	sep := helpSepStyle.Render(" | ")
 	if len(contextEntries) == 0 {
-		return helpBarStyle.Render(strings.Join(baseEntries, sep))
+		return fitHelpEntries(baseEntries, width)
 	}
-	line1 := helpBarStyle.Render(strings.Join(contextEntries, sep))
-	line2 := helpBarStyle.Render(strings.Join(baseEntries, sep))
-	return line1 + "\n" + line2
+	return fitHelpEntries(contextEntries, width) + "\n" + fitHelpEntries(baseEntries, width)
 }
 
 func renderFormHelp() string {
@@ -243,3 +352,29 @@ func renderFilePickerHelp() string {
 	sep := helpSepStyle.Render(" | ")
 	return helpBarStyle.Render(strings.Join(entries, sep))
 }
+
+var groupPaletteNames = []string{"red", "orange", "yellow", "green", "teal", "blue", "purple", "pink"}
+
+// groupColor resolves a group color setting to a terminal color. It accepts a
+// palette name or a #rgb / #rrggbb hex value.
+func groupColor(value string) (lipgloss.Color, bool) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if c, ok := groupPalette[value]; ok {
+		return c, true
+	}
+	hex, ok := strings.CutPrefix(value, "#")
+	if !ok || (len(hex) != 3 && len(hex) != 6) || strings.Trim(hex, "0123456789abcdef") != "" {
+		return "", false
+	}
+	return lipgloss.Color(value), true
+}
+
+func validateGroupColor(value string) error {
+	if value == "" {
+		return nil
+	}
+	if _, ok := groupColor(value); !ok {
+		return fmt.Errorf("color must be one of %s or a #rrggbb value", strings.Join(groupPaletteNames, ", "))
+	}
+	return nil
+}
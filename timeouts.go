@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// --- Timeouts ---
+
+// ssh gives up on an unreachable host after the connect timeout: the host's
+// own Timeout field, else ASSHO_CONNECT_TIMEOUT, else 5 seconds. Tests,
+// scans, and other background commands get commandGrace on top of it to run
+// once connected. Interactive sessions only pass ConnectTimeout when one is
+// configured, so ssh_config keeps the final say otherwise.
+
+const (
+	defaultConnectTimeout = 5
+	maxConnectTimeout     = 600
+	commandGrace          = 3 * time.Second
+)
+
+// globalConnectTimeout reads ASSHO_CONNECT_TIMEOUT in seconds, or 0 when it
+// is unset or invalid.
+func globalConnectTimeout() int {
+	seconds, err := strconv.Atoi(strings.TrimSpace(os.Getenv("ASSHO_CONNECT_TIMEOUT")))
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+	return min(seconds, maxConnectTimeout)
+}
+
+// connectTimeout is h's ssh ConnectTimeout in seconds.
+func (h Host) connectTimeout() int {
+	if h.ConnectTimeout > 0 {
+		return h.ConnectTimeout
+	}
+	if seconds := globalConnectTimeout(); seconds > 0 {
+		return seconds
+	}
+	return defaultConnectTimeout
+}
+
+// commandTimeout bounds a whole non-interactive command against h.
+func (h Host) commandTimeout() time.Duration {
+	return time.Duration(h.connectTimeout())*time.Second + commandGrace
+}
+
+// connectTimeoutOption is the -o value for seconds.
+func connectTimeoutOption(seconds int) string {
+	return "ConnectTimeout=" + strconv.Itoa(seconds)
+}
+
+// parseConnectTimeout reads the form's Timeout field; blank means inherit.
+func parseConnectTimeout(value string) (int, error) {
+	value = strings.TrimSuffix(strings.TrimSpace(value), "s")
+	if value == "" {
+		return 0, nil
+	}
+	seconds, err := strconv.Atoi(value)
+	if err != nil || seconds < 1 || seconds > maxConnectTimeout {
+		return 0, fmt.Errorf("timeout must be a number of seconds between 1 and %d", maxConnectTimeout)
+	}
+	return seconds, nil
+}
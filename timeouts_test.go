@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConnectTimeoutPrecedence(t *testing.T) {
+	t.Setenv("ASSHO_CONNECT_TIMEOUT", "")
+	h := Host{Hostname: "sat.example"}
+	if got := h.connectTimeout(); got != defaultConnectTimeout {
+		t.Fatalf("expected the default, got %d", got)
+	}
+	if got := h.commandTimeout(); got != 8*time.Second {
+		t.Fatalf("expected the 8s command timeout, got %s", got)
+	}
+
+	t.Setenv("ASSHO_CONNECT_TIMEOUT", "2")
+	if got := h.connectTimeout(); got != 2 {
+		t.Fatalf("expected the global setting, got %d", got)
+	}
+
+	h.ConnectTimeout = 30
+	if got := h.connectTimeout(); got != 30 {
+		t.Fatalf("expected the host setting to win, got %d", got)
+	}
+
+	t.Setenv("ASSHO_CONNECT_TIMEOUT", "soon")
+	if got := (Host{}).connectTimeout(); got != defaultConnectTimeout {
+		t.Fatalf("an invalid global setting should fall back, got %d", got)
+	}
+}
+
+func TestParseConnectTimeout(t *testing.T) {
+	for value, want := range map[string]int{"": 0, " 30 ": 30, "45s": 45} {
+		got, err := parseConnectTimeout(value)
+		if err != nil || got != want {
+			t.Fatalf("%q: got %d, %v; want %d", value, got, err, want)
+		}
+	}
+	for _, value := range []string{"0", "-1", "ten", "601"} {
+		if _, err := parseConnectTimeout(value); err == nil || !strings.HasPrefix(err.Error(), "timeout") {
+			t.Fatalf("%q: expected a timeout error, got %v", value, err)
+		}
+	}
+}
+
+func TestConnectTimeoutReachesSSH(t *testing.T) {
+	t.Setenv("ASSHO_CONNECT_TIMEOUT", "")
+	h := Host{Hostname: "sat.example", User: "ops", ConnectTimeout: 30}
+
+	cmd := remoteExecCommand(context.Background(), h, "true")
+	if !slices.Contains(cmd.Args, "ConnectTimeout=30") {
+		t.Fatalf("background commands should use the host timeout: %v", cmd.Args)
+	}
+	if args := buildSSHArgs(h, false, ""); !slices.Contains(args, "ConnectTimeout=30") {
+		t.Fatalf("sessions should use a configured timeout: %v", args)
+	}
+	if args := buildSSHArgs(Host{Hostname: "lan.example"}, false, ""); slices.Contains(args, "-o") {
+		t.Fatalf("sessions without a configured timeout should leave ssh's default: %v", args)
+	}
+	if args := firstContactSSHArgs(h, "none"); !slices.Contains(args, "ConnectTimeout=30") {
+		t.Fatalf("first contact should use the host timeout: %v", args)
+	}
+}
+
+func TestFormTimeoutField(t *testing.T) {
+	m := model{form: newFormState(newFormInputs())}
+	m.populateForm(Host{Alias: "sat", Hostname: "sat.example", ConnectTimeout: 30})
+	if got := m.form.inputs[fieldTimeout].Value(); got != "30" {
+		t.Fatalf("expected the stored timeout in the form, got %q", got)
+	}
+	if got := m.formTestHost().ConnectTimeout; got != 30 {
+		t.Fatalf("form tests should use the timeout being edited, got %d", got)
+	}
+
+	m.form.inputs[fieldTimeout].SetValue("abc")
+	if err := m.saveFromForm(); err == nil || !strings.HasPrefix(err.Error(), "timeout") {
+		t.Fatalf("expected a timeout error, got %v", err)
+	}
+}
@@ -0,0 +1,417 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// --- Quick File Transfer (scp / rsync) ---
+
+type transferDirection int
+
+const (
+	transferUpload transferDirection = iota
+	transferDownload
+)
+
+func (d transferDirection) String() string {
+	if d == transferDownload {
+		return "download"
+	}
+	return "upload"
+}
+
+type transferPhase int
+
+const (
+	transferEdit transferPhase = iota
+	transferRunning
+	transferDone
+)
+
+type transferState struct {
+	phase     transferPhase
+	host      Host
+	direction transferDirection
+	local     textinput.Model
+	remote    textinput.Model
+	focus     int // 0 = local path, 1 = remote path
+	tool      string
+	progress  string
+	percent   int
+	started   time.Time
+	cancel    context.CancelFunc
+	cancelled bool
+	errorText string
+}
+
+type transferStartedMsg struct {
+	updates <-chan tea.Msg
+	cancel  context.CancelFunc
+}
+
+type transferProgressMsg struct {
+	updates <-chan tea.Msg
+	line    string
+}
+
+type transferDoneMsg struct{ err error }
+
+var transferPercentPattern = regexp.MustCompile(`(\d{1,3})%`)
+
+func newTransferInput(prompt, placeholder string) textinput.Model {
+	input := textinput.New()
+	input.Prompt = prompt
+	input.Placeholder = placeholder
+	input.PromptStyle = lipgloss.NewStyle().Foreground(colorMuted)
+	input.TextStyle = lipgloss.NewStyle().Foreground(colorText)
+	input.PlaceholderStyle = lipgloss.NewStyle().Foreground(colorDimText)
+	input.Cursor.Style = lipgloss.NewStyle().Foreground(colorSecondary)
+	return input
+}
+
+func (m model) openTransfer(h Host) (tea.Model, tea.Cmd) {
+	m.clearListDeleteConfirm()
+	m.transfer = transferState{
+		host:   h,
+		local:  newTransferInput("Local   ", "~/file-or-directory"),
+		remote: newTransferInput("Remote  ", "~/"),
+	}
+	m.state = stateTransfer
+	return m, m.transfer.local.Focus()
+}
+
+func (m *model) focusTransferInput(index int) tea.Cmd {
+	m.transfer.focus = index
+	if index == 0 {
+		m.transfer.remote.Blur()
+		return m.transfer.local.Focus()
+	}
+	m.transfer.local.Blur()
+	return m.transfer.remote.Focus()
+}
+
+func (m model) updateTransfer(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch m.transfer.phase {
+	case transferRunning:
+		switch msg.String() {
+		case "J":
+			return m.openTasks()
+		case "ctrl+c", "esc":
+			if m.transfer.cancel != nil {
+				m.transfer.cancel()
+				m.transfer.cancelled = true
+			}
+		}
+		return m, nil
+	case transferDone:
+		switch msg.String() {
+		case "ctrl+c":
+			m.quitting = true
+			return m, tea.Quit
+		case "enter", "esc", "q":
+			m.state = stateList
+		case "J":
+			return m.openTasks()
+		}
+		return m, nil
+	}
+
+	switch msg.String() {
+	case "ctrl+c":
+		m.quitting = true
+		return m, tea.Quit
+	case "esc":
+		m.state = stateList
+		return m, nil
+	case "tab", "shift+tab", "up", "down":
+		return m, m.focusTransferInput(1 - m.transfer.focus)
+	case "ctrl+r":
+		m.transfer.direction = 1 - m.transfer.direction
+		return m, nil
+	case "ctrl+o":
+		m.pickerUse = pickerTransferLocal
+		m.filepicker.AllowedTypes = []string{}
+		m.filepicker.DirAllowed = true
+		m.state = stateFilePicker
+		return m, m.filepicker.Init()
+	case "enter":
+		if m.transfer.focus == 0 {
+			return m, m.focusTransferInput(1)
+		}
+		if strings.TrimSpace(m.transfer.local.Value()) == "" || strings.TrimSpace(m.transfer.remote.Value()) == "" {
+			m.transfer.errorText = "Both a local and a remote path are required."
+			return m, nil
+		}
+		m.transfer.errorText = ""
+		return m, checkHostTrustCmd(pendingSSHAction{kind: sshActionTransfer, host: m.transfer.host, trustHost: m.transfer.host, inv: m.inventory()})
+	}
+	var cmd tea.Cmd
+	if m.transfer.focus == 0 {
+		m.transfer.local, cmd = m.transfer.local.Update(msg)
+	} else {
+		m.transfer.remote, cmd = m.transfer.remote.Update(msg)
+	}
+	m.transfer.errorText = ""
+	return m, cmd
+}
+
+// transferRemoteSpec formats user@host:path, bracketing IPv6 literals.
+func transferRemoteSpec(h Host, path string) string {
+	host := bracketHostname(h.Hostname)
+	if h.User != "" {
+		host = h.User + "@" + host
+	}
+	return host + ":" + path
+}
+
+// buildTransferCommand assembles an rsync (preferred) or scp invocation that
+// reuses the host's port, identity, and ProxyJump or ProxyCommand. Without a
+// stored password ssh runs in batch mode because there is no terminal to
+// prompt on; with one, sshpass is required for the same reason.
+func buildTransferCommand(h Host, direction transferDirection, local, remote string, useRsync bool) (string, []string, []string, error) {
+	h = withPassword(h)
+	sshOpts := []string{"-o", "StrictHostKeyChecking=yes"}
+	sshOpts = append(sshOpts, pinnedKnownHostsArgs(h)...)
+	if h.Password == "" {
+		sshOpts = append(sshOpts, "-o", "BatchMode=yes")
+	}
+	if h.IdentityFile != "" {
+		sshOpts = append(sshOpts, "-i", expandPath(h.IdentityFile))
+	}
+	sshOpts = append(sshOpts, identityAgentArgs(h)...)
+	sshOpts = append(sshOpts, proxyArgs(h)...)
+
+	local = expandPath(local)
+	src, dst := local, transferRemoteSpec(h, remote)
+	if direction == transferDownload {
+		src, dst = dst, src
+	}
+
+	var binary string
+	var args []string
+	if useRsync {
+		rsh := []string{"ssh"}
+		if h.Port != "" {
+			rsh = append(rsh, "-p", h.Port)
+		}
+		for _, opt := range sshOpts {
+			rsh = append(rsh, shellQuote(opt))
+		}
+		binary = "rsync"
+		args = []string{"-a", "--progress", "-e", strings.Join(rsh, " "), src, dst}
+	} else {
+		binary = "scp"
+		args = []string{"-r"}
+		if h.Port != "" {
+			args = append(args, "-P", h.Port)
+		}
+		args = append(append(args, sshOpts...), src, dst)
+	}
+
+	if h.Password == "" {
+		return binary, args, nil, nil
+	}
+	sshpassPath, err := exec.LookPath("sshpass")
+	if err != nil {
+		return "", nil, nil, fmt.Errorf("password provided but %w", errMissingTool("sshpass"))
+	}
+	return sshpassPath, append([]string{"-e", binary}, args...), []string{"SSHPASS=" + h.Password}, nil
+}
+
+// scanProgressLines splits on both \r and \n so in-place progress updates
+// from rsync arrive as separate lines.
+func scanProgressLines(data []byte, atEOF bool) (int, []byte, error) {
+	if i := bytes.IndexAny(data, "\r\n"); i >= 0 {
+		return i + 1, data[:i], nil
+	}
+	if atEOF && len(data) > 0 {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
+
+// startTransferTrusted runs the transfer against h, the transfer host as
+// resolved by the trust check: group defaults and network profile applied.
+func (m model) startTransferTrusted(h Host) (model, tea.Cmd) {
+	t := m.transfer
+	useRsync := commandExists("rsync")
+	binary, args, extraEnv, err := buildTransferCommand(h, t.direction, strings.TrimSpace(t.local.Value()), strings.TrimSpace(t.remote.Value()), useRsync)
+	if err != nil {
+		recordAudit("transfer", t.host.Alias, h, err)
+		m.transfer.phase = transferDone
+		m.transfer.errorText = err.Error()
+		return m, nil
+	}
+	m.transfer.tool = "scp"
+	if useRsync {
+		m.transfer.tool = "rsync"
+	}
+	m.transfer.phase = transferRunning
+	m.transfer.progress = ""
+	m.transfer.percent = -1
+	m.transfer.started = time.Now()
+	direction, local, remote := t.direction, t.local.Value(), t.remote.Value()
+	m.tasks.start(taskTransfer, t.host.ID, t.direction.String()+" "+t.host.Alias, func(m model) (model, tea.Cmd) {
+		updated, _ := m.openTransfer(t.host)
+		m = updated.(model)
+		m.transfer.direction = direction
+		m.transfer.local.SetValue(local)
+		m.transfer.remote.SetValue(remote)
+		return m, checkHostTrustCmd(pendingSSHAction{kind: sshActionTransfer, host: t.host, trustHost: t.host, inv: m.inventory()})
+	})
+	host, alias := h, t.host.Alias
+	return m, func() tea.Msg {
+		ctx, cancel := context.WithCancel(context.Background())
+		cmd := exec.CommandContext(ctx, binary, args...)
+		if len(extraEnv) > 0 {
+			cmd.Env = append(cmd.Environ(), extraEnv...)
+		}
+		pr, pw := io.Pipe()
+		cmd.Stdout, cmd.Stderr = pw, pw
+		if err := cmd.Start(); err != nil {
+			cancel()
+			recordAudit("transfer", alias, host, err)
+			return transferDoneMsg{err: err}
+		}
+		waitErr := make(chan error, 1)
+		go func() {
+			err := cmd.Wait()
+			pw.Close()
+			waitErr <- err
+		}()
+		updates := make(chan tea.Msg, 64)
+		go func() {
+			var last string
+			scanner := bufio.NewScanner(pr)
+			scanner.Split(scanProgressLines)
+			for scanner.Scan() {
+				line := strings.TrimSpace(scanner.Text())
+				if line == "" {
+					continue
+				}
+				last = line
+				select {
+				case updates <- transferProgressMsg{updates: updates, line: line}:
+				default: // drop intermediate progress when the UI falls behind
+				}
+			}
+			err := <-waitErr
+			if err != nil && ctx.Err() == nil && last != "" {
+				err = errors.New(last)
+			}
+			recordAudit("transfer", alias, host, err)
+			updates <- transferDoneMsg{err: err}
+		}()
+		return transferStartedMsg{updates: updates, cancel: cancel}
+	}
+}
+
+func waitTransferUpdate(updates <-chan tea.Msg) tea.Cmd {
+	return func() tea.Msg { return <-updates }
+}
+
+func (m model) handleTransferStarted(msg transferStartedMsg) (tea.Model, tea.Cmd) {
+	m.transfer.cancel = msg.cancel
+	m.tasks.setCancel(taskTransfer, m.transfer.host.ID, msg.cancel)
+	return m, waitTransferUpdate(msg.updates)
+}
+
+func (m model) handleTransferProgress(msg transferProgressMsg) (tea.Model, tea.Cmd) {
+	m.transfer.progress = msg.line
+	m.tasks.setProgress(taskTransfer, m.transfer.host.ID, msg.line)
+	if match := transferPercentPattern.FindStringSubmatch(msg.line); match != nil {
+		fmt.Sscanf(match[1], "%d", &m.transfer.percent)
+	}
+	return m, waitTransferUpdate(msg.updates)
+}
+
+func (m model) finishTransfer(msg transferDoneMsg) (tea.Model, tea.Cmd) {
+	if m.transfer.cancel != nil {
+		m.transfer.cancel()
+		m.transfer.cancel = nil
+	}
+	m.transfer.phase = transferDone
+	if m.transfer.cancelled {
+		m.tasks.markCancelled(taskTransfer, m.transfer.host.ID)
+	} else {
+		m.tasks.finish(taskTransfer, m.transfer.host.ID, msg.err)
+	}
+	switch {
+	case m.transfer.cancelled:
+		m.transfer.errorText = "Transfer cancelled."
+	case msg.err != nil:
+		m.transfer.errorText = msg.err.Error()
+	default:
+		m.transfer.errorText = ""
+	}
+	return m, nil
+}
+
+func renderTransferBar(percent, width int) string {
+	filled := width * percent / 100
+	return lipgloss.NewStyle().Foreground(colorPrimary).Render(strings.Repeat("█", filled)) +
+		lipgloss.NewStyle().Foreground(colorSubtle).Render(strings.Repeat("░", width-filled)) +
+		fmt.Sprintf(" %3d%%", percent)
+}
+
+func (m model) renderTransferView() string {
+	width, height := normalizedSize(m.width, m.height)
+	inner := max(min(width-6, 100)-6, 24)
+	t := m.transfer
+	var b strings.Builder
+	b.WriteString(lipgloss.NewStyle().Bold(true).Foreground(colorText).Render("TRANSFER FILES") + "\n")
+	b.WriteString(formHintStyle.Render(ansi.Truncate(t.host.Alias+" · "+sshTarget(t.host), inner, "…")) + "\n\n")
+
+	arrow := "local → remote"
+	if t.direction == transferDownload {
+		arrow = "remote → local"
+	}
+	b.WriteString(formSectionStyle.Render(strings.ToUpper(t.direction.String()[:1])+t.direction.String()[1:]) + formHintStyle.Render("  "+arrow) + "\n\n")
+
+	switch t.phase {
+	case transferEdit:
+		local, remote := t.local, t.remote
+		local.Width, remote.Width = max(inner-10, 1), max(inner-10, 1)
+		b.WriteString(local.View() + "\n")
+		b.WriteString(remote.View() + "\n")
+		if t.errorText != "" {
+			b.WriteString("\n" + testFailStyle.Render(ansi.Truncate("✘ "+t.errorText, inner, "…")) + "\n")
+		}
+		b.WriteString("\n" + helpEntry("enter", "start") + "  " + helpEntry("ctrl+r", "reverse") + "  " +
+			helpEntry("ctrl+o", "browse") + "  " + helpEntry("esc", "back"))
+	case transferRunning:
+		b.WriteString(ansi.Truncate("Local   "+t.local.Value(), inner, "…") + "\n")
+		b.WriteString(ansi.Truncate("Remote  "+t.remote.Value(), inner, "…") + "\n\n")
+		elapsed := time.Since(t.started).Truncate(time.Second)
+		b.WriteString(m.spinner.View() + fmt.Sprintf(" Copying with %s · %s", t.tool, elapsed) + "\n")
+		if t.percent >= 0 {
+			b.WriteString(renderTransferBar(t.percent, max(min(inner-6, 40), 10)) + "\n")
+		}
+		if t.progress != "" {
+			b.WriteString(formHintStyle.Render(ansi.Truncate(t.progress, inner, "…")) + "\n")
+		}
+		b.WriteString("\n" + helpEntry("esc", "cancel") + "  " + helpEntry("J", "tasks"))
+	case transferDone:
+		if t.errorText == "" {
+			b.WriteString(testSuccessStyle.Render(fmt.Sprintf("✔ Transfer complete (%s)", t.tool)) + "\n")
+		} else {
+			b.WriteString(testFailStyle.Render(ansi.Wrap("✘ "+t.errorText, inner, " ")) + "\n")
+		}
+		b.WriteString("\n" + helpEntry("enter", "done"))
+	}
+	return centeredWorkspace(b.String(), width, height)
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestBuildTransferCommandScp(t *testing.T) {
+	h := Host{Hostname: "10.0.0.1", User: "deploy", Port: "2222", IdentityFile: "/keys/id", ProxyJump: "bastion"}
+	binary, args, env, _ := buildTransferCommand(h, transferUpload, "/tmp/site", "/srv/www", false)
+	joined := strings.Join(args, " ")
+	if binary != "scp" || env != nil {
+		t.Fatalf("expected plain scp, got %s %v", binary, env)
+	}
+	for _, want := range []string{"-r", "-P 2222", "-i /keys/id", "-J bastion", "BatchMode=yes", "/tmp/site deploy@10.0.0.1:/srv/www"} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("scp args %q missing %q", joined, want)
+		}
+	}
+}
+
+func TestBuildTransferCommandNeedsSSHPassForPassword(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+	h := Host{Hostname: "10.0.0.1", User: "deploy", Password: "secret"}
+	if _, _, _, err := buildTransferCommand(h, transferUpload, "/tmp/site", "/srv/www", false); err == nil || !strings.Contains(err.Error(), "sshpass") {
+		t.Fatalf("expected a missing sshpass error, got %v", err)
+	}
+}
+
+func TestBuildTransferCommandRsyncDownload(t *testing.T) {
+	h := Host{Hostname: "fe80::1", Port: "2222", IdentityFile: "/my keys/id"}
+	binary, args, _, _ := buildTransferCommand(h, transferDownload, "/tmp/out", "/var/log/app.log", true)
+	if binary != "rsync" {
+		t.Fatalf("expected rsync, got %s", binary)
+	}
+	if got := args[len(args)-2:]; got[0] != "[fe80::1]:/var/log/app.log" || got[1] != "/tmp/out" {
+		t.Fatalf("expected remote source and local destination, got %v", got)
+	}
+	if rsh := args[3]; !strings.HasPrefix(rsh, "ssh -p 2222 ") || !strings.Contains(rsh, "'/my keys/id'") {
+		t.Fatalf("unexpected rsync -e value %q", rsh)
+	}
+}
+
+func TestScanProgressLinesSplitsCarriageReturns(t *testing.T) {
+	scanner := bufio.NewScanner(strings.NewReader("a.txt\r  10%\r  100%\nerr"))
+	scanner.Split(scanProgressLines)
+	var lines []string
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if strings.Join(lines, "|") != "a.txt|  10%|  100%|err" {
+		t.Fatalf("unexpected lines %q", lines)
+	}
+}
+
+func TestTransferRunsAndReportsProgress(t *testing.T) {
+	bin := t.TempDir()
+	script := "#!/bin/sh\nprintf 'file.txt\\n   512  50%%\\r  1024 100%%\\n'\n"
+	if err := os.WriteFile(filepath.Join(bin, "rsync"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin)
+	t.Setenv("ASSHO_AUDIT_LOG", "")
+
+	m := model{width: 80, height: 24}
+	updated, _ := m.openTransfer(Host{ID: "h1", Alias: "web", Hostname: "10.0.0.1"})
+	m = updated.(model)
+	m.transfer.local.SetValue("/tmp/file.txt")
+	m.transfer.remote.SetValue("/srv/")
+
+	m, cmd := m.startTransferTrusted(m.transfer.host)
+	if m.transfer.tool != "rsync" || m.transfer.phase != transferRunning {
+		t.Fatalf("expected running rsync transfer, got %+v", m.transfer)
+	}
+	msg := cmd()
+	for i := 0; i < 20; i++ {
+		var next tea.Model
+		switch typed := msg.(type) {
+		case transferStartedMsg:
+			next, cmd = m.handleTransferStarted(typed)
+		case transferProgressMsg:
+			next, cmd = m.handleTransferProgress(typed)
+		case transferDoneMsg:
+			next, _ = m.finishTransfer(typed)
+			m = next.(model)
+			if typed.err != nil || m.transfer.phase != transferDone || m.transfer.percent != 100 {
+				t.Fatalf("unexpected final transfer state: err=%v %+v", typed.err, m.transfer)
+			}
+			return
+		default:
+			t.Fatalf("unexpected message %T", msg)
+		}
+		m = next.(model)
+		msg = cmd()
+	}
+	t.Fatal("transfer never finished")
+}
+
+func TestTransferUsesResolvedHost(t *testing.T) {
+	bin := t.TempDir()
+	argsFile := filepath.Join(bin, "args")
+	script := "#!/bin/sh\nprintf '%s\\n' \"$@\" > " + argsFile + "\n"
+	if err := os.WriteFile(filepath.Join(bin, "rsync"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin)
+	t.Setenv("ASSHO_AUDIT_LOG", "")
+
+	groups := []Group{{ID: "g1", Name: "prod", DefaultUser: "deploy"}}
+	raw := Host{ID: "h1", Alias: "web", Hostname: "10.0.0.1", GroupID: "g1"}
+	m := model{rawGroups: groups, rawHosts: []Host{raw}, width: 80, height: 24}
+	updated, _ := m.openTransfer(raw)
+	m = updated.(model)
+	m.transfer.local.SetValue("/tmp/file.txt")
+	m.transfer.remote.SetValue("/srv/")
+
+	action := pendingSSHAction{kind: sshActionTransfer, host: resolveEndpoint(raw, m.inventory()), trustHost: raw}
+	m, cmd := m.resumePendingSSHActionModel(action)
+	started, ok := cmd().(transferStartedMsg)
+	if !ok {
+		t.Fatal("expected the transfer to start")
+	}
+	for msg := range started.updates {
+		if _, done := msg.(transferDoneMsg); done {
+			break
+		}
+	}
+	args, err := os.ReadFile(argsFile)
+	if err != nil || !strings.Contains(string(args), "deploy@10.0.0.1:/srv/") {
+		t.Fatalf("expected the group's default user in the transfer, got %q (%v)", args, err)
+	}
+}
+
+func TestTransferViewFitsTerminal(t *testing.T) {
+	for _, phase := range []transferPhase{transferEdit, transferRunning, transferDone} {
+		for _, size := range []struct{ width, height int }{{40, 16}, {80, 24}, {140, 40}} {
+			m := model{width: size.width, height: size.height}
+			updated, _ := m.openTransfer(Host{ID: "h1", Alias: "web", Hostname: "very-long-hostname.internal.example.com", User: "deploy"})
+			m = updated.(model)
+			m.transfer.phase = phase
+			m.transfer.percent = 42
+			m.transfer.progress = strings.Repeat("x", 200)
+			m.transfer.errorText = strings.Repeat("permission denied ", 6)
+			lines := strings.Split(m.renderTransferView(), "\n")
+			if len(lines) > size.height {
+				t.Fatalf("phase %d %dx%d: got %d lines", phase, size.width, size.height, len(lines))
+			}
+			for i, line := range lines {
+				if ansi.StringWidth(line) > size.width {
+					t.Fatalf("phase %d %dx%d line %d has width %d", phase, size.width, size.height, i, ansi.StringWidth(line))
+				}
+			}
+		}
+	}
+}
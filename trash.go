@@ -0,0 +1,282 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// --- Trash ---
+
+// Deleted hosts move to trash.json next to hosts.json and stay restorable from
+// the T screen for ASSHO_TRASH_DAYS days (default 30). Entries are saved like
+// hosts.json, so passwords stay in the keychain until the entry is purged.
+
+const defaultTrashDays = 30
+
+type TrashEntry struct {
+	Host      Host  `json:"host"`
+	DeletedAt int64 `json:"deleted_at"`
+}
+
+type trashState struct {
+	entries    []TrashEntry
+	cursor     int
+	purgeArmed bool
+}
+
+func getTrashPath() string {
+	return filepath.Join(filepath.Dir(getConfigPath()), "trash.json")
+}
+
+func trashRetention() time.Duration {
+	days, err := strconv.Atoi(strings.TrimSpace(os.Getenv("ASSHO_TRASH_DAYS")))
+	if err != nil || days <= 0 {
+		days = defaultTrashDays
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// loadTrash returns the entries newest first. A missing file is an empty trash.
+func loadTrash() ([]TrashEntry, error) {
+	data, err := os.ReadFile(getTrashPath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var entries []TrashEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("invalid trash format: %w", err)
+	}
+	return entries, nil
+}
+
+func saveTrash(entries []TrashEntry) error {
+	path := getTrashPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	if entries == nil {
+		entries = []TrashEntry{}
+	}
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// pruneTrash splits off the entries older than the retention period.
+func pruneTrash(entries []TrashEntry, now time.Time, retention time.Duration) (kept, expired []TrashEntry) {
+	cutoff := now.Add(-retention).Unix()
+	for _, e := range entries {
+		if e.DeletedAt < cutoff {
+			expired = append(expired, e)
+		} else {
+			kept = append(kept, e)
+		}
+	}
+	return kept, expired
+}
+
+// purgeTrashSecrets drops the keychain entries of hosts leaving the trash for
+// good. Failures are ignored; a leftover entry is harmless.
+func purgeTrashSecrets(entries []TrashEntry) {
+	var purge func(hosts []Host)
+	purge = func(hosts []Host) {
+		for _, h := range hosts {
+			_ = deletePasswordSecret(h.PasswordRef)
+			purge(h.Containers)
+		}
+	}
+	for _, e := range entries {
+		purge([]Host{e.Host})
+	}
+}
+
+// trashHost moves a host from the config into the trash. The trash is written
+// first, so a failed config save never loses the host.
+func (m *model) trashHost(id string) error {
+	idx := findHostIndexByID(m.rawHosts, id)
+	if idx == -1 {
+		return fmt.Errorf("host no longer exists")
+	}
+	previous, err := loadTrash()
+	if err != nil {
+		return err
+	}
+	kept, expired := pruneTrash(previous, time.Now(), trashRetention())
+	entry := TrashEntry{Host: sanitizeHostsForSave(m.rawHosts[idx : idx+1])[0], DeletedAt: time.Now().Unix()}
+	if err := saveTrash(append([]TrashEntry{entry}, kept...)); err != nil {
+		return fmt.Errorf("could not write the trash: %v", err)
+	}
+	snapshot := m.snapshot()
+	m.rawHosts = append(m.rawHosts[:idx], m.rawHosts[idx+1:]...)
+	m.refreshList()
+	if err := m.save(); err != nil {
+		m.restoreSnapshot(snapshot)
+		_ = saveTrash(previous)
+		return err
+	}
+	purgeTrashSecrets(expired)
+	return nil
+}
+
+func (m model) openTrash() (tea.Model, tea.Cmd) {
+	m.clearListDeleteConfirm()
+	entries, err := loadTrash()
+	if err != nil {
+		m.status.message = fmt.Sprintf("Failed to read the trash: %v", err)
+		m.status.isError = true
+		m.status.version++
+		return m, statusClearCmd(m.status.version)
+	}
+	kept, expired := pruneTrash(entries, time.Now(), trashRetention())
+	if len(expired) > 0 && saveTrash(kept) == nil {
+		purgeTrashSecrets(expired)
+	}
+	m.trash = trashState{entries: kept}
+	m.state = stateTrash
+	return m, nil
+}
+
+// restoreFromTrash puts an entry back on the dashboard. A host whose group was
+// deleted in the meantime comes back ungrouped.
+func (m *model) restoreFromTrash(i int) error {
+	entry := m.trash.entries[i]
+	if aliasTaken(m.rawHosts, entry.Host.Alias, "") {
+		return fmt.Errorf("alias already exists: %s", entry.Host.Alias)
+	}
+	if findHostIndexByID(m.rawHosts, entry.Host.ID) != -1 {
+		return fmt.Errorf("%s is already on the dashboard", entry.Host.Alias)
+	}
+	restored, _ := hydrateHostPasswords([]Host{entry.Host})
+	host := restored[0]
+	if g := findGroupIndexByID(m.rawGroups, host.GroupID); g == -1 || m.rawGroups[g].Smart() {
+		host.GroupID = ""
+	}
+	snapshot := m.snapshot()
+	m.rawHosts = append(m.rawHosts, host)
+	m.refreshList()
+	if err := m.save(); err != nil {
+		m.restoreSnapshot(snapshot)
+		return fmt.Errorf("failed to save restore: %v", err)
+	}
+	remaining := append(append([]TrashEntry(nil), m.trash.entries[:i]...), m.trash.entries[i+1:]...)
+	if err := saveTrash(remaining); err != nil {
+		return fmt.Errorf("restored, but the trash could not be updated: %v", err)
+	}
+	m.trash.entries = remaining
+	m.trash.cursor = min(m.trash.cursor, max(len(remaining)-1, 0))
+	m.reselectItem(host.ID, false)
+	return nil
+}
+
+func (m model) updateTrash(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if msg.String() != "x" {
+		m.trash.purgeArmed = false
+	}
+	switch msg.String() {
+	case "ctrl+c":
+		m.quitting = true
+		return m, tea.Quit
+	case "esc", "q", "T":
+		m.state = stateList
+	case "up", "k":
+		if m.trash.cursor > 0 {
+			m.trash.cursor--
+		}
+	case "down", "j":
+		if m.trash.cursor < len(m.trash.entries)-1 {
+			m.trash.cursor++
+		}
+	case "enter":
+		if m.trash.cursor >= len(m.trash.entries) {
+			return m, nil
+		}
+		alias := m.trash.entries[m.trash.cursor].Host.Alias
+		if err := m.restoreFromTrash(m.trash.cursor); err != nil {
+			m.status.message = err.Error()
+			m.status.isError = true
+			m.status.version++
+			return m, statusClearCmd(m.status.version)
+		}
+		m.status.message = "Restored " + alias
+		m.status.isError = false
+		m.status.version++
+		return m, statusClearCmd(m.status.version)
+	case "x":
+		if m.trash.cursor >= len(m.trash.entries) {
+			return m, nil
+		}
+		if !m.trash.purgeArmed {
+			m.trash.purgeArmed = true
+			return m, nil
+		}
+		m.trash.purgeArmed = false
+		purged := m.trash.entries[m.trash.cursor]
+		remaining := append(append([]TrashEntry(nil), m.trash.entries[:m.trash.cursor]...), m.trash.entries[m.trash.cursor+1:]...)
+		if err := saveTrash(remaining); err != nil {
+			m.status.message = fmt.Sprintf("Failed to update the trash: %v", err)
+			m.status.isError = true
+			m.status.version++
+			return m, statusClearCmd(m.status.version)
+		}
+		purgeTrashSecrets([]TrashEntry{purged})
+		m.trash.entries = remaining
+		m.trash.cursor = min(m.trash.cursor, max(len(remaining)-1, 0))
+		m.status.message = "Deleted " + purged.Host.Alias + " permanently"
+		m.status.isError = false
+		m.status.version++
+		return m, statusClearCmd(m.status.version)
+	}
+	return m, nil
+}
+
+func (m model) renderTrashView() string {
+	width, height := normalizedSize(m.width, m.height)
+	inner := max(min(width-6, 100)-6, 24)
+	days := int(trashRetention().Hours() / 24)
+	var b strings.Builder
+	b.WriteString(lipgloss.NewStyle().Bold(true).Foreground(colorText).Render("TRASH") + "\n")
+	if len(m.trash.entries) == 0 {
+		b.WriteString(formHintStyle.Render(ansi.Truncate(fmt.Sprintf("Empty. Deleted hosts are kept here for %d days.", days), inner, "…")) + "\n")
+		b.WriteString("\n" + helpEntry("esc", "back"))
+		return centeredWorkspace(b.String(), width, height)
+	}
+	b.WriteString(formHintStyle.Render(ansi.Truncate(fmt.Sprintf("%d deleted hosts, each kept for %d days", len(m.trash.entries), days), inner, "…")) + "\n\n")
+
+	maxRows := max(height-12, 2)
+	start := 0
+	if m.trash.cursor >= maxRows {
+		start = m.trash.cursor - maxRows + 1
+	}
+	end := min(start+maxRows, len(m.trash.entries))
+	for idx := start; idx < end; idx++ {
+		e := m.trash.entries[idx]
+		label := fmt.Sprintf("%s · %s · deleted %s", e.Host.Alias, hostPort(e.Host.Hostname, e.Host.Port), relativeTime(e.DeletedAt))
+		b.WriteString(selectionLine(idx == m.trash.cursor, ansi.Truncate(label, inner-2, "…")) + "\n")
+	}
+	if end < len(m.trash.entries) {
+		b.WriteString(formHintStyle.Render(fmt.Sprintf("… %d more", len(m.trash.entries)-end)) + "\n")
+	}
+	if m.trash.purgeArmed {
+		b.WriteString(testFailStyle.Render(ansi.Truncate("Press x again to delete permanently", inner, "…")) + "\n")
+	}
+	b.WriteString("\n" + helpEntry("enter", "restore") + "  " + helpEntry("x x", "delete forever") + "  " + helpEntry("esc", "back"))
+	return centeredWorkspace(b.String(), width, height)
+}
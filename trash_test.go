@@ -0,0 +1,120 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestPruneTrash(t *testing.T) {
+	now := time.Now()
+	entries := []TrashEntry{
+		{Host: Host{Alias: "fresh"}, DeletedAt: now.Add(-24 * time.Hour).Unix()},
+		{Host: Host{Alias: "stale"}, DeletedAt: now.Add(-40 * 24 * time.Hour).Unix()},
+	}
+	kept, expired := pruneTrash(entries, now, 30*24*time.Hour)
+	if len(kept) != 1 || kept[0].Host.Alias != "fresh" || len(expired) != 1 || expired[0].Host.Alias != "stale" {
+		t.Fatalf("unexpected prune result %+v / %+v", kept, expired)
+	}
+}
+
+func TestTrashRetention(t *testing.T) {
+	t.Setenv("ASSHO_TRASH_DAYS", "7")
+	if got := trashRetention(); got != 7*24*time.Hour {
+		t.Fatalf("expected seven days, got %v", got)
+	}
+	t.Setenv("ASSHO_TRASH_DAYS", "0")
+	if got := trashRetention(); got != defaultTrashDays*24*time.Hour {
+		t.Fatalf("expected the default for zero, got %v", got)
+	}
+}
+
+func TestDeleteMovesHostToTrashAndRestores(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("ASSHO_STORE_PASSWORD", "0")
+	groups := []Group{{ID: "g1", Name: "prod", Expanded: true}}
+	hosts := []Host{{ID: "h1", Alias: "web", Hostname: "10.0.0.1", GroupID: "g1"}}
+	m := model{rawGroups: groups, rawHosts: hosts, list: newTestListModel(groups, hosts), historyList: newTestHistoryListModel()}
+	m.list.Select(1)
+	for range 2 {
+		next, _ := m.updateList(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'d'}})
+		m = next.(model)
+	}
+	if len(m.rawHosts) != 0 || !strings.Contains(m.status.message, "trash") {
+		t.Fatalf("expected the host deleted with a trash hint, got %+v / %q", m.rawHosts, m.status.message)
+	}
+	entries, err := loadTrash()
+	if err != nil || len(entries) != 1 || entries[0].Host.ID != "h1" {
+		t.Fatalf("expected the host in the trash, got %+v (%v)", entries, err)
+	}
+
+	// The group is gone by the time the host comes back.
+	m.rawGroups = nil
+	next, _ := m.openTrash()
+	m = next.(model)
+	next, _ = m.updateTrash(tea.KeyMsg{Type: tea.KeyEnter})
+	m = next.(model)
+	if len(m.rawHosts) != 1 || m.rawHosts[0].Alias != "web" || m.rawHosts[0].GroupID != "" {
+		t.Fatalf("expected the host restored ungrouped, got %+v", m.rawHosts)
+	}
+	if entries, _ := loadTrash(); len(entries) != 0 || len(m.trash.entries) != 0 {
+		t.Fatalf("expected the trash emptied, got %+v", entries)
+	}
+}
+
+func TestRestoreFromTrashRefusesTakenAlias(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("ASSHO_STORE_PASSWORD", "0")
+	m := model{rawHosts: []Host{{ID: "h2", Alias: "web"}}, list: newTestListModel(nil, nil), historyList: newTestHistoryListModel()}
+	m.trash.entries = []TrashEntry{{Host: Host{ID: "h1", Alias: "WEB"}, DeletedAt: time.Now().Unix()}}
+	if err := m.restoreFromTrash(0); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Fatalf("expected an alias clash, got %v", err)
+	}
+}
+
+func TestTrashPurgeNeedsTwoPresses(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	entries := []TrashEntry{{Host: Host{ID: "h1", Alias: "web"}, DeletedAt: time.Now().Unix()}}
+	if err := saveTrash(entries); err != nil {
+		t.Fatal(err)
+	}
+	m := model{trash: trashState{entries: entries}, state: stateTrash}
+	x := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'x'}}
+	next, _ := m.updateTrash(x)
+	m = next.(model)
+	if len(m.trash.entries) != 1 || !m.trash.purgeArmed {
+		t.Fatal("expected the first x to only arm the purge")
+	}
+	next, _ = m.updateTrash(x)
+	m = next.(model)
+	if saved, _ := loadTrash(); len(m.trash.entries) != 0 || len(saved) != 0 {
+		t.Fatalf("expected the entry purged, got %+v", saved)
+	}
+}
+
+func TestTrashViewFitsTerminal(t *testing.T) {
+	var entries []TrashEntry
+	for i := range 20 {
+		entries = append(entries, TrashEntry{Host: Host{Alias: fmt.Sprintf("very-long-deleted-host-alias-%02d", i), Hostname: "host.internal.example.com"}, DeletedAt: time.Now().Unix()})
+	}
+	for _, size := range []struct{ width, height int }{{40, 16}, {80, 24}, {140, 40}} {
+		m := model{width: size.width, height: size.height, trash: trashState{entries: entries, cursor: 15, purgeArmed: true}}
+		out := m.renderTrashView()
+		lines := strings.Split(out, "\n")
+		if len(lines) > size.height {
+			t.Fatalf("%dx%d: got %d lines", size.width, size.height, len(lines))
+		}
+		for i, line := range lines {
+			if ansi.StringWidth(line) > size.width {
+				t.Fatalf("%dx%d line %d has width %d", size.width, size.height, i, ansi.StringWidth(line))
+			}
+		}
+		if !strings.Contains(out, "› ") {
+			t.Fatalf("%dx%d: expected the cursor row to stay visible", size.width, size.height)
+		}
+	}
+}
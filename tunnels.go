@@ -0,0 +1,356 @@
+package main
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// --- Tunnel Profiles ---
+
+// A tunnel profile is a named set of forwards, possibly through several
+// hosts, that comes up and goes down as one: "dev-stack" might forward the
+// database through a bastion, Redis from a cache box, and the API back from a
+// staging host. Profiles are edited by hand in the "tunnels" section of
+// hosts.json. L opens the Tunnels screen, where enter starts a profile, or
+// stops it when it is fully up.
+//
+// The forwards through one host share one backgrounded ssh, started as a
+// control master whose socket lives in the tunnels directory next to
+// hosts.json. The socket is how assho finds the tunnel again: its status is
+// read with ssh -O check and it is stopped with ssh -O exit, so tunnels
+// outlive assho and a later run can still stop them.
+
+// TunnelProfile is one entry of the "tunnels" section.
+type TunnelProfile struct {
+	Name     string          `json:"name"`
+	Forwards []TunnelForward `json:"forwards"`
+}
+
+// TunnelForward is one forward of a profile, through the host with the
+// given alias. Exactly one of Local (ssh -L) and Remote (ssh -R) is set.
+type TunnelForward struct {
+	Host   string `json:"host"`
+	Local  string `json:"local,omitempty"`
+	Remote string `json:"remote,omitempty"`
+}
+
+func (f TunnelForward) flag() (string, string) {
+	if f.Remote != "" {
+		return "-R", f.Remote
+	}
+	return "-L", f.Local
+}
+
+func (f TunnelForward) String() string {
+	flag, spec := f.flag()
+	return strings.TrimPrefix(flag, "-") + " " + spec
+}
+
+// tunnelLeg is the forwards of a profile that go through one host, which
+// share an ssh.
+type tunnelLeg struct {
+	profile  string
+	alias    string
+	host     Host
+	forwards []TunnelForward
+	err      error // the host could not be found
+}
+
+type tunnelsState struct {
+	profiles []TunnelProfile
+	up       map[string]bool // by control socket
+	cursor   int
+	pending  int // legs being started or stopped
+	err      string
+}
+
+type tunnelStatusMsg struct{ up map[string]bool }
+
+type tunnelLegMsg struct {
+	profile string
+	alias   string
+	stopped bool
+	err     error
+}
+
+func validateTunnelProfiles(profiles []TunnelProfile) error {
+	seen := map[string]bool{}
+	for _, p := range profiles {
+		name := strings.TrimSpace(p.Name)
+		if name == "" {
+			return errors.New("a tunnel profile has no name")
+		}
+		if seen[name] {
+			return fmt.Errorf("tunnel profile %q is defined twice", name)
+		}
+		seen[name] = true
+		if len(p.Forwards) == 0 {
+			return fmt.Errorf("tunnel profile %q has no forwards", name)
+		}
+		for _, f := range p.Forwards {
+			if strings.TrimSpace(f.Host) == "" {
+				return fmt.Errorf("a forward in tunnel profile %q has no host", name)
+			}
+			if (f.Local == "") == (f.Remote == "") {
+				return fmt.Errorf("a forward through %s in tunnel profile %q needs exactly one of local and remote", f.Host, name)
+			}
+			_, spec := f.flag()
+			if parts := splitForwardSpec(spec); (len(parts) != 3 && len(parts) != 4) || !isPortNumber(parts[len(parts)-3]) {
+				return fmt.Errorf("forward %q in tunnel profile %q must look like 8080:localhost:80", spec, name)
+			}
+		}
+	}
+	return nil
+}
+
+// planTunnelLegs groups p's forwards by host, in the order the hosts first
+// appear.
+func planTunnelLegs(p TunnelProfile, hosts []Host) []tunnelLeg {
+	var legs []tunnelLeg
+	index := map[string]int{}
+	for _, f := range p.Forwards {
+		i, ok := index[f.Host]
+		if !ok {
+			leg := tunnelLeg{profile: p.Name, alias: f.Host}
+			if idx := findHostIndexByAlias(hosts, f.Host); idx >= 0 {
+				leg.host = hosts[idx]
+			} else {
+				leg.err = fmt.Errorf("no host named %s", f.Host)
+			}
+			i = len(legs)
+			index[f.Host] = i
+			legs = append(legs, leg)
+		}
+		legs[i].forwards = append(legs[i].forwards, f)
+	}
+	return legs
+}
+
+func findHostIndexByAlias(hosts []Host, alias string) int {
+	for i := range hosts {
+		if hosts[i].Alias == alias {
+			return i
+		}
+	}
+	return -1
+}
+
+func tunnelDirectory() string { return filepath.Join(filepath.Dir(getConfigPath()), "tunnels") }
+
+// tunnelSocket is the control socket of a leg. The name is a short hash, as
+// socket paths are limited to about a hundred bytes.
+func tunnelSocket(profile, alias string) string {
+	sum := sha256.Sum256([]byte(profile + "\x00" + alias))
+	return filepath.Join(tunnelDirectory(), fmt.Sprintf("%x", sum[:6]))
+}
+
+// tunnelControl runs ssh -O op against a leg's control socket.
+var tunnelControl = func(socket, op string) error {
+	return exec.Command("ssh", "-S", socket, "-O", op, "assho-tunnel").Run()
+}
+
+func tunnelStatusCmd(profiles []TunnelProfile) tea.Cmd {
+	return func() tea.Msg {
+		up := map[string]bool{}
+		for _, p := range profiles {
+			for _, f := range p.Forwards {
+				socket := tunnelSocket(p.Name, f.Host)
+				if _, seen := up[socket]; seen {
+					continue
+				}
+				_, err := os.Stat(socket)
+				up[socket] = err == nil && tunnelControl(socket, "check") == nil
+			}
+		}
+		return tunnelStatusMsg{up: up}
+	}
+}
+
+// startTunnelLegTrusted brings up a leg as a backgrounded control master.
+func startTunnelLegTrusted(h Host, leg tunnelLeg) tea.Cmd {
+	return func() tea.Msg {
+		msg := tunnelLegMsg{profile: leg.profile, alias: leg.alias}
+		if err := os.MkdirAll(tunnelDirectory(), 0o700); err != nil {
+			msg.err = err
+			return msg
+		}
+		socket := tunnelSocket(leg.profile, leg.alias)
+		os.Remove(socket) // left behind by a tunnel that died
+		h = withPassword(h)
+		extra := []string{"-o", "ControlMaster=yes", "-o", "ControlPath=" + socket}
+		for _, f := range leg.forwards {
+			flag, spec := f.flag()
+			extra = append(extra, flag, spec)
+		}
+		binary, args, extraEnv, _ := buildSSHCommand(h.Password, tunnelArgs(h, extra...))
+		cmd := exec.Command(binary, args...)
+		cmd.Env = append(cmd.Environ(), extraEnv...)
+		output, err := cmd.CombinedOutput()
+		recordAudit("tunnel", leg.alias, h, err)
+		if err != nil {
+			if out := strings.TrimSpace(string(output)); out != "" {
+				err = errors.New(out)
+			}
+			msg.err = fmt.Errorf("tunnel failed: %w", err)
+		}
+		return msg
+	}
+}
+
+func stopTunnelLegCmd(leg tunnelLeg) tea.Cmd {
+	return func() tea.Msg {
+		socket := tunnelSocket(leg.profile, leg.alias)
+		err := tunnelControl(socket, "exit")
+		os.Remove(socket)
+		return tunnelLegMsg{profile: leg.profile, alias: leg.alias, stopped: true, err: err}
+	}
+}
+
+// profileUp counts the legs of p that are running.
+func (s tunnelsState) profileUp(p TunnelProfile, hosts []Host) (up, total int) {
+	for _, leg := range planTunnelLegs(p, hosts) {
+		total++
+		if s.up[tunnelSocket(leg.profile, leg.alias)] {
+			up++
+		}
+	}
+	return up, total
+}
+
+func (m model) openTunnels() (tea.Model, tea.Cmd) {
+	m.clearListDeleteConfirm()
+	m.tunnels = tunnelsState{}
+	cfg, err := loadConfigFile()
+	switch {
+	case err != nil && !os.IsNotExist(err):
+		m.tunnels.err = err.Error()
+	case err == nil:
+		if err := validateTunnelProfiles(cfg.Tunnels); err != nil {
+			m.tunnels.err = err.Error()
+		} else {
+			m.tunnels.profiles = cfg.Tunnels
+		}
+	}
+	m.state = stateTunnels
+	return m, tunnelStatusCmd(m.tunnels.profiles)
+}
+
+// toggleTunnelProfile starts the legs of the selected profile that are down,
+// or, with stop, stops the ones that are up.
+func (m model) toggleTunnelProfile(stop bool) (tea.Model, tea.Cmd) {
+	if m.tunnels.cursor >= len(m.tunnels.profiles) || m.tunnels.pending > 0 {
+		return m, nil
+	}
+	p := m.tunnels.profiles[m.tunnels.cursor]
+	m.tunnels.err = ""
+	var cmds []tea.Cmd
+	inv := m.inventory()
+	for _, leg := range planTunnelLegs(p, m.rawHosts) {
+		up := m.tunnels.up[tunnelSocket(leg.profile, leg.alias)]
+		if stop {
+			if up {
+				cmds = append(cmds, stopTunnelLegCmd(leg))
+			}
+			continue
+		}
+		if up {
+			continue
+		}
+		if leg.err != nil {
+			m.tunnels.err = leg.err.Error()
+			continue
+		}
+		cmds = append(cmds, checkHostTrustCmd(pendingSSHAction{kind: sshActionTunnel, host: leg.host, trustHost: leg.host, inv: inv, tunnel: leg}))
+	}
+	m.tunnels.pending = len(cmds)
+	return m, tea.Batch(cmds...)
+}
+
+func (m model) finishTunnelLeg(msg tunnelLegMsg) (tea.Model, tea.Cmd) {
+	if m.tunnels.pending > 0 {
+		m.tunnels.pending--
+	}
+	if msg.err != nil && !msg.stopped {
+		m.tunnels.err = msg.alias + ": " + msg.err.Error()
+	}
+	if m.tunnels.pending > 0 {
+		return m, nil
+	}
+	return m, tunnelStatusCmd(m.tunnels.profiles)
+}
+
+func (m model) updateTunnels(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		m.quitting = true
+		return m, tea.Quit
+	case "esc", "q", "L":
+		m.state = stateList
+	case "up", "k":
+		if m.tunnels.cursor > 0 {
+			m.tunnels.cursor--
+		}
+	case "down", "j":
+		if m.tunnels.cursor < len(m.tunnels.profiles)-1 {
+			m.tunnels.cursor++
+		}
+	case "enter":
+		if m.tunnels.cursor < len(m.tunnels.profiles) {
+			up, total := m.tunnels.profileUp(m.tunnels.profiles[m.tunnels.cursor], m.rawHosts)
+			return m.toggleTunnelProfile(up == total)
+		}
+	case "s":
+		return m.toggleTunnelProfile(false)
+	case "x":
+		return m.toggleTunnelProfile(true)
+	case "r":
+		return m, tunnelStatusCmd(m.tunnels.profiles)
+	}
+	return m, nil
+}
+
+func (m model) renderTunnelsView() string {
+	width, height := normalizedSize(m.width, m.height)
+	inner := max(min(width-6, 100)-6, 24)
+	s := m.tunnels
+	var b strings.Builder
+	b.WriteString(lipgloss.NewStyle().Bold(true).Foreground(colorText).Render("TUNNELS") + "\n")
+	b.WriteString(formHintStyle.Render(ansi.Truncate("Profiles from the tunnels section of hosts.json", inner, "…")) + "\n\n")
+	if len(s.profiles) == 0 && s.err == "" {
+		b.WriteString(formHintStyle.Render(ansi.Wrap(`No tunnel profiles yet. Add one to hosts.json, e.g. "tunnels": [{"name": "dev-stack", "forwards": [{"host": "bastion", "local": "5432:db.internal:5432"}]}]`, inner, "")) + "\n")
+	}
+	for i, p := range s.profiles {
+		up, total := s.profileUp(p, m.rawHosts)
+		state := formHintStyle.Render(fmt.Sprintf("%d/%d up", up, total))
+		if up == total {
+			state = testSuccessStyle.Render("up")
+		}
+		b.WriteString(selectionLine(i == s.cursor, p.Name) + "  " + state + "\n")
+		for _, leg := range planTunnelLegs(p, m.rawHosts) {
+			marker := formHintStyle.Render("·")
+			if s.up[tunnelSocket(leg.profile, leg.alias)] {
+				marker = testSuccessStyle.Render("✔")
+			}
+			for _, f := range leg.forwards {
+				b.WriteString("    " + marker + " " + ansi.Truncate(leg.alias+"  "+f.String(), inner-6, "…") + "\n")
+			}
+		}
+	}
+	if s.pending > 0 {
+		b.WriteString("\n" + m.spinner.View() + " " + formHintStyle.Render("Working…") + "\n")
+	}
+	if s.err != "" {
+		b.WriteString("\n" + testFailStyle.Render(ansi.Wrap(s.err, inner, "")) + "\n")
+	}
+	b.WriteString("\n" + helpEntry("enter", "start/stop") + "  " + helpEntry("s", "start") + "  " + helpEntry("x", "stop") + "  " + helpEntry("r", "refresh") + "  " + helpEntry("esc", "back"))
+	return centeredWorkspace(b.String(), width, height)
+}
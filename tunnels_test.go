@@ -0,0 +1,123 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestValidateTunnelProfiles(t *testing.T) {
+	ok := []TunnelProfile{{Name: "dev-stack", Forwards: []TunnelForward{
+		{Host: "bastion", Local: "5432:db.internal:5432"},
+		{Host: "staging", Remote: "127.0.0.1:9000:localhost:3000"},
+	}}}
+	if err := validateTunnelProfiles(ok); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	for _, bad := range [][]TunnelProfile{
+		{{Name: "", Forwards: ok[0].Forwards}},
+		{ok[0], ok[0]},
+		{{Name: "empty"}},
+		{{Name: "both", Forwards: []TunnelForward{{Host: "a", Local: "1:b:1", Remote: "1:b:1"}}}},
+		{{Name: "neither", Forwards: []TunnelForward{{Host: "a"}}}},
+		{{Name: "short", Forwards: []TunnelForward{{Host: "a", Local: "5432"}}}},
+		{{Name: "port", Forwards: []TunnelForward{{Host: "a", Local: "db:db:5432"}}}},
+	} {
+		if err := validateTunnelProfiles(bad); err == nil {
+			t.Errorf("expected an error for %+v", bad)
+		}
+	}
+}
+
+func TestPlanTunnelLegsGroupsByHost(t *testing.T) {
+	hosts := []Host{{ID: "h1", Alias: "bastion"}, {ID: "h2", Alias: "cache"}}
+	p := TunnelProfile{Name: "dev", Forwards: []TunnelForward{
+		{Host: "bastion", Local: "5432:db:5432"},
+		{Host: "cache", Local: "6379:localhost:6379"},
+		{Host: "bastion", Local: "8080:api:80"},
+		{Host: "gone", Local: "1:x:1"},
+	}}
+	legs := planTunnelLegs(p, hosts)
+	if len(legs) != 3 || legs[0].alias != "bastion" || len(legs[0].forwards) != 2 || legs[1].host.ID != "h2" {
+		t.Fatalf("unexpected legs %+v", legs)
+	}
+	if legs[2].err == nil {
+		t.Fatal("a forward through an unknown host should be reported")
+	}
+}
+
+func TestToggleTunnelProfile(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	hosts := []Host{{ID: "h1", Alias: "bastion"}, {ID: "h2", Alias: "cache"}}
+	p := TunnelProfile{Name: "dev", Forwards: []TunnelForward{
+		{Host: "bastion", Local: "5432:db:5432"},
+		{Host: "cache", Local: "6379:localhost:6379"},
+	}}
+	m := model{rawHosts: hosts, state: stateTunnels, width: 100, height: 30}
+	m.tunnels = tunnelsState{profiles: []TunnelProfile{p}, up: map[string]bool{tunnelSocket("dev", "bastion"): true}}
+
+	var mu sync.Mutex
+	var ops []string
+	old := tunnelControl
+	tunnelControl = func(socket, op string) error {
+		mu.Lock()
+		defer mu.Unlock()
+		ops = append(ops, op+" "+socket)
+		return nil
+	}
+	t.Cleanup(func() { tunnelControl = old })
+
+	view := ansi.Strip(m.View())
+	if !strings.Contains(view, "dev  1/2 up") || !strings.Contains(view, "✔ bastion  L 5432:db:5432") {
+		t.Fatalf("unexpected view:\n%s", view)
+	}
+
+	// Half up: enter starts only the leg that is down.
+	next, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	started := next.(model)
+	if started.tunnels.pending != 1 || cmd == nil {
+		t.Fatalf("expected one leg to start, got %+v", started.tunnels)
+	}
+	if msg, ok := cmd().(hostTrustCheckMsg); !ok || msg.action.kind != sshActionTunnel || msg.action.tunnel.alias != "cache" {
+		t.Fatalf("expected a trust check for cache, got %#v", msg)
+	}
+
+	// x stops only the leg that is up.
+	next, cmd = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x")})
+	stopping := next.(model)
+	msg := cmd().(tunnelLegMsg)
+	if len(ops) != 1 || ops[0] != "exit "+tunnelSocket("dev", "bastion") || !msg.stopped {
+		t.Fatalf("expected bastion stopped, got %v %+v", ops, msg)
+	}
+	next, cmd = stopping.Update(msg)
+	if next.(model).tunnels.pending != 0 || cmd == nil {
+		t.Fatal("the status should be refreshed once every leg is done")
+	}
+}
+
+func TestSaveConfigPreservesTunnels(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("ASSHO_STORE_PASSWORD", "0")
+	if err := saveConfig(nil, []Host{{ID: "a", Alias: "web", Hostname: "10.0.0.1"}}, nil); err != nil {
+		t.Fatal(err)
+	}
+	cfg, _ := loadConfigFile()
+	cfg.Tunnels = []TunnelProfile{{Name: "dev", Forwards: []TunnelForward{{Host: "web", Local: "8080:localhost:80"}}}}
+	data, _ := json.Marshal(cfg)
+	if err := os.WriteFile(getConfigPath(), data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := saveConfig(nil, []Host{{ID: "a", Alias: "web2", Hostname: "10.0.0.1"}}, nil); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := loadConfigFile()
+	if err != nil || len(cfg.Tunnels) != 1 || cfg.Tunnels[0].Name != "dev" {
+		t.Fatalf("expected hand-edited tunnels kept across saves, got %+v %v", cfg.Tunnels, err)
+	}
+}
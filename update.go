@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/charmbracelet/bubbles/spinner"
@@ -12,6 +13,9 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case spinner.TickMsg:
 		var cmd tea.Cmd
 		m.spinner, cmd = m.spinner.Update(msg)
+		if len(m.rowBusy) > 0 || len(m.rowActivity()) > 0 {
+			m.refreshDelegate()
+		}
 		return m, cmd
 	case aboutTickMsg:
 		if m.about.open {
@@ -25,8 +29,31 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		return m, headerTick()
 	case testConnectionMsg:
+		if !m.tasks.finish(taskTest, msg.hostID, msg.err) {
+			return m, nil
+		}
 		m.form.testStatus, m.form.testResult = formatTestStatus(msg.err)
-		m.form.testing = false
+		if path := sshPermissionProblem(msg.err); path != "" {
+			m.permFix = permFixState{path: path}
+		}
+		if msg.sshfp != sshfpOff {
+			m.form.testStatus += " · " + msg.sshfp.label()
+		}
+		if summary := msg.banner.summary(); summary != "" {
+			m.form.testStatus += " · “" + summary + "”"
+		}
+		if msg.hostID != "" {
+			if msg.os != nil {
+				m.setHostOS(msg.hostID, msg.os)
+			}
+			if msg.err == nil && m.setHostBanner(msg.hostID, msg.banner) {
+				m.form.testStatus += " · banner changed since the last test"
+			}
+			m.recordTestStats(msg.hostID, msg.latency, msg.err)
+			if idx := findHostIndexByID(m.rawHosts, msg.hostID); msg.err != nil && idx != -1 {
+				return m, webhookCmd(connectionFailedEvent(m.rawHosts[idx], msg.err))
+			}
+		}
 		return m, nil
 	case keyInstallFinishedMsg:
 		return m.finishKeyInstall(msg)
@@ -34,6 +61,48 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m.finishRotationStep(msg)
 	case rotationKeyReadyMsg:
 		return m.finishRotationKey(msg)
+	case transferStartedMsg:
+		return m.handleTransferStarted(msg)
+	case transferProgressMsg:
+		return m.handleTransferProgress(msg)
+	case transferDoneMsg:
+		return m.finishTransfer(msg)
+	case groupRunResultMsg:
+		return m.finishGroupRunResult(msg)
+	case dnsLookupTickMsg:
+		return m.startDNSLookup(msg)
+	case dnsLookupMsg:
+		return m.finishDNSLookup(msg)
+	case knownHostsDNSMsg:
+		return m.finishKnownHostsLookup(msg)
+	case diagResolvedMsg:
+		return m.startDiagnostics(msg)
+	case diagResultMsg:
+		return m.finishDiagnostic(msg)
+	case quickStatsMsg:
+		return m.finishQuickStats(msg)
+	case windowsServicesMsg:
+		return m.finishWindowsServices(msg)
+	case portForwardMsg:
+		return m.finishPortForward(msg)
+	case composeProjectsMsg:
+		return m.finishComposeProjects(msg)
+	case composeStartedMsg:
+		return m.handleComposeStarted(msg)
+	case composeOutputMsg:
+		return m.handleComposeOutput(msg)
+	case composeDoneMsg:
+		return m.finishComposeAction(msg)
+	case firstContactScanMsg:
+		return m.finishFirstContactScan(msg)
+	case firstContactAuthMsg:
+		return m.finishFirstContactAuth(msg)
+	case firstContactCopyIDMsg:
+		return m.finishFirstContactCopyID(msg)
+	case gPrefixMsg:
+		return m.handleGPrefixTimeout(msg)
+	case webOpenedMsg:
+		return m.handleWebOpened(msg)
 	case hostTrustCheckMsg:
 		return m.handleHostTrustCheck(msg)
 	case hostTrustFinishedMsg:
@@ -42,10 +111,11 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.status.message = msg.err.Error()
 		m.status.isError = true
 		m.status.version++
-		return m, statusClearCmd(m.status.version)
+		return m, tea.Batch(statusClearCmd(m.status.version), webhookCmd(connectionFailedEvent(msg.host, msg.err)))
 	case scanDockerMsg:
-		if !msg.background {
-			m.scanning = false
+		m.finishExpandRefresh(msg)
+		if !msg.background && !m.tasks.finish(taskScan, msg.hostID, msg.err) {
+			return m, nil
 		}
 		if msg.err != nil {
 			m.status.message = fmt.Sprintf("Scan failed: %v", msg.err)
@@ -53,22 +123,46 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.status.version++
 			return m, statusClearCmd(m.status.version)
 		} else {
-			if msg.hostIndex >= 0 && msg.hostIndex < len(m.rawHosts) {
-				m.rawHosts[msg.hostIndex].Containers = msg.containers
-				m.rawHosts[msg.hostIndex].Expanded = true
-				m.list.SetItems(flattenHosts(m.rawGroups, m.rawHosts))
+			// Scans are matched by ID: the host may have moved, or been
+			// deleted, while its scan ran.
+			if idx := findHostIndexByID(m.rawHosts, msg.hostID); idx != -1 {
+				m.rawHosts[idx].Containers = msg.containers
+				// Leave a host collapsed during a background scan collapsed.
+				if !msg.background {
+					m.rawHosts[idx].Expanded = true
+				}
+				m.refreshList()
 			}
 		}
 		return m, nil
 	case dockerRefreshTickMsg:
+		m.refreshHealthFailing()
 		var cmds []tea.Cmd
-		cmds = append(cmds, dockerRefreshTick())
-		for idx, h := range m.rawHosts {
+		cmds = append(cmds, dockerRefreshTick(), detectNetworkCmd())
+		inv := m.inventory()
+		for _, h := range m.rawHosts {
 			if h.Expanded && !h.IsContainer {
-				cmds = append(cmds, scanDockerContainers(m.rawHosts[idx], idx, true))
+				cmds = append(cmds, scanDockerContainers(context.Background(), h, inv, true))
 			}
 		}
 		return m, tea.Batch(cmds...)
+	case networkDetectedMsg:
+		m.networkName = msg.name
+		return m, nil
+	case tunnelStatusMsg:
+		m.tunnels.up = msg.up
+		return m, nil
+	case tunnelLegMsg:
+		return m.finishTunnelLeg(msg)
+	case roundSessionEndedMsg:
+		return m.finishRoundSession(msg)
+	case commandPreviewMsg:
+		return m.finishCommandPreview(msg)
+	case secretsPrefetchedMsg:
+		return m.finishSecretsPrefetch(msg)
+	case updateAvailableMsg:
+		m.newRelease = msg.version
+		return m, nil
 	case statusClearMsg:
 		if msg.version == m.status.version {
 			m.status.message = ""
@@ -79,7 +173,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.width = msg.Width
 		m.height = msg.Height
 		m.list.SetWidth(msg.Width)
-		listHeight := msg.Height - 13 // Room for header + two-row help bar
+		listHeight := msg.Height - 14 // Room for header, position line, and two-row help bar
 		if listHeight < 4 {
 			listHeight = 4
 		}
@@ -89,9 +183,24 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.filepicker.Height = msg.Height - 8
 		return m, nil
 	case tea.KeyMsg:
+		if msg.String() != "enter" {
+			m.maintenanceConfirm = ""
+		}
+		if key := msg.String(); key != "enter" && key != "ctrl+f" {
+			m.permFix = permFixState{}
+		}
+		if m.hostKeyAlert != nil {
+			return m.updateHostKeyAlert(msg)
+		}
 		if m.hostTrust.open {
 			return m.updateHostTrust(msg)
 		}
+		if m.round.open {
+			return m.updateSessionRound(msg)
+		}
+		if m.tasks.open {
+			return m.updateTasks(msg)
+		}
 		if m.helpOpen {
 			return m.updateHelp(msg)
 		}
@@ -100,7 +209,11 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		switch m.state {
 		case stateList:
-			return m.updateList(msg)
+			next, cmd := m.updateList(msg)
+			if lm, ok := next.(model); ok && lm.state == stateList {
+				return lm.queueSelectedLookup(cmd)
+			}
+			return next, cmd
 		case stateFilePicker:
 			return m.updateFilePicker(msg)
 		case stateForm:
@@ -113,6 +226,58 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m.updateKeyInstall(msg)
 		case stateRotation:
 			return m.updateRotation(msg)
+		case stateDetail:
+			return m.updateDetail(msg)
+		case stateStats:
+			return m.updateStats(msg)
+		case stateCommandPreview:
+			return m.updateCommandPreview(msg)
+		case stateTransfer:
+			return m.updateTransfer(msg)
+		case stateBookmarks:
+			return m.updateBookmarks(msg)
+		case stateGroupRun:
+			return m.updateGroupRun(msg)
+		case stateImportMerge:
+			return m.updateImportMerge(msg)
+		case stateHostRename:
+			return m.updateHostRename(msg)
+		case stateBulkClone:
+			return m.updateBulkClone(msg)
+		case stateFirstContact:
+			return m.updateFirstContact(msg)
+		case stateDiagnostics:
+			return m.updateDiagnostics(msg)
+		case stateRepair:
+			return m.updateRepair(msg)
+		case stateSecretAudit:
+			return m.updateSecretAudit(msg)
+		case stateBatchRename:
+			return m.updateBatchRename(msg)
+		case stateTrash:
+			return m.updateTrash(msg)
+		case stateServices:
+			return m.updateWindowsServices(msg)
+		case statePortForward:
+			return m.updatePortForward(msg)
+		case stateTunnels:
+			return m.updateTunnels(msg)
+		case stateCompose:
+			return m.updateCompose(msg)
+		case stateMaintenance:
+			return m.updateMaintenance(msg)
+		case stateGroupPicker:
+			return m.updateGroupPicker(msg)
+		case stateGroupJump:
+			return m.updateGroupJump(msg)
+		case stateHistoryImport:
+			return m.updateHistoryImport(msg)
+		case stateHostGenerator:
+			return m.updateHostGenerator(msg)
+		case stateTable:
+			return m.updateTableView(msg)
+		case stateSessionNote:
+			return m.updateSessionNote(msg)
 		}
 	}
 	// Forward non-key messages to the active sub-component (cursor blink, etc.)
@@ -155,9 +320,47 @@ func (m model) forwardMsg(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.form.inputs[field], cmd = m.form.inputs[field].Update(msg)
 		}
 	case stateGroupPrompt:
-		m.groupPrompt.input, cmd = m.groupPrompt.input.Update(msg)
+		input := m.groupPrompt.focused()
+		*input, cmd = input.Update(msg)
+	case stateHostRename:
+		m.hostRename.input, cmd = m.hostRename.input.Update(msg)
+	case stateGroupPicker:
+		m.groupPicker.filter, cmd = m.groupPicker.filter.Update(msg)
+	case stateGroupJump:
+		m.groupJump.filter, cmd = m.groupJump.filter.Update(msg)
+	case stateMaintenance:
+		if m.maintenance.focus == 0 {
+			m.maintenance.note, cmd = m.maintenance.note.Update(msg)
+		} else {
+			m.maintenance.until, cmd = m.maintenance.until.Update(msg)
+		}
+	case stateBulkClone:
+		m.bulkClone.input, cmd = m.bulkClone.input.Update(msg)
+	case stateHostGenerator:
+		input := m.hostGen.inputs()[m.hostGen.focus]
+		*input, cmd = input.Update(msg)
+	case stateTable:
+		if m.table.filtering {
+			m.table.filter, cmd = m.table.filter.Update(msg)
+		}
+	case stateSessionNote:
+		m.sessionNote.input, cmd = m.sessionNote.input.Update(msg)
+	case stateBatchRename:
+		if m.batchRename.focus == batchFocusFind {
+			m.batchRename.find, cmd = m.batchRename.find.Update(msg)
+		} else if m.batchRename.focus == batchFocusReplace {
+			m.batchRename.replace, cmd = m.batchRename.replace.Update(msg)
+		}
 	case stateHistory:
 		m.historyList, cmd = m.historyList.Update(msg)
+	case stateTransfer:
+		if m.transfer.phase == transferEdit {
+			if m.transfer.focus == 0 {
+				m.transfer.local, cmd = m.transfer.local.Update(msg)
+			} else {
+				m.transfer.remote, cmd = m.transfer.remote.Update(msg)
+			}
+		}
 	case stateRotation:
 		if m.rotation.phase == rotationGenerateKey {
 			m.rotation.pathInput, cmd = m.rotation.pathInput.Update(msg)
@@ -26,6 +26,30 @@ func (m model) updateFilePicker(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
+// formTestHost is the unsaved host the form's test and diagnostics act on.
+func (m model) formTestHost() Host {
+	h := Host{
+		Alias:        m.form.inputs[fieldAlias].Value(),
+		Hostname:     m.form.inputs[fieldHostname].Value(),
+		User:         m.form.inputs[fieldUser].Value(),
+		Port:         m.form.inputs[fieldPort].Value(),
+		ProxyJump:    m.form.inputs[fieldProxyJump].Value(),
+		ProxyCommand: strings.TrimSpace(m.form.inputs[fieldProxyCommand].Value()),
+		IdentityFile: m.form.inputs[fieldKeyFile].Value(),
+		Password:     m.form.inputs[fieldPassword].Value(),
+
+		IdentityAgent: strings.TrimSpace(m.form.inputs[fieldIdentityAgent].Value()),
+	}
+	h.ConnectTimeout, _ = parseConnectTimeout(m.form.inputs[fieldTimeout].Value())
+	if m.form.selectedHost != nil {
+		h.ID = m.form.selectedHost.ID
+	}
+	if g, ok := m.formGroup(); ok {
+		h.GroupID = g.ID
+	}
+	return h
+}
+
 func (m model) updateForm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
 	case "ctrl+c":
@@ -35,23 +59,37 @@ func (m model) updateForm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.helpOpen = true
 		return m, nil
 	case "ctrl+t":
-		h := Host{
-			Hostname:     m.form.inputs[fieldHostname].Value(),
-			User:         m.form.inputs[fieldUser].Value(),
-			Port:         m.form.inputs[fieldPort].Value(),
-			ProxyJump:    m.form.inputs[fieldProxyJump].Value(),
-			IdentityFile: m.form.inputs[fieldKeyFile].Value(),
-			Password:     m.form.inputs[fieldPassword].Value(),
-		}
 		m.form.testStatus = ""
-		m.form.testing = true
-		return m, testConnection(h)
+		return m.startTest(m.formTestHost())
+	case "ctrl+g":
+		if m.formTesting() {
+			return m, nil
+		}
+		return m.openDiagnostics(m.formTestHost())
+	case "ctrl+f":
+		return m.fixKeyPermissions()
 	case "ctrl+k":
 		if m.form.selectedHost != nil {
 			return m.openKeyInstall()
 		}
 		return m, nil
+	case "ctrl+o":
+		return m.switchFormTab()
+	case "ctrl+r":
+		if m.form.focus == controlPassword {
+			m.setPasswordRevealed(!m.passwordRevealed())
+		}
+		return m, nil
+	case "ctrl+p":
+		if m.form.focus == controlProxyCommand {
+			m.form.inputs[fieldProxyCommand].SetValue(nextProxyPreset(m.form.inputs[fieldProxyCommand].Value(), 1))
+			m.form.inputs[fieldProxyCommand].CursorEnd()
+			m.form.formError = ""
+		}
+		return m, nil
 	case "ctrl+s":
+		added := m.form.selectedHost == nil
+		duplicate := m.formDuplicateWarning()
 		if err := m.saveFromForm(); err != nil {
 			m.form.formError = err.Error()
 			m.focusFormError(err)
@@ -60,12 +98,34 @@ func (m model) updateForm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.form.formError = ""
 		m.form.deleteArmed = false
 		m.state = stateList
+		warning := identityFileWarning(m.form.inputs[fieldKeyFile].Value())
+		if duplicate != "" {
+			warning = duplicate
+		}
+		if warning != "" {
+			m.status.message = "Saved · warning: " + warning
+			m.status.isError = true
+			m.status.version++
+			return m, statusClearCmd(m.status.version)
+		}
+		if added {
+			// Select the new host so the offered first-contact check targets it.
+			m.reselectItem(m.rawHosts[len(m.rawHosts)-1].ID, false)
+			m.status.message = "Added · press f for a first-contact check"
+			m.status.isError = false
+			m.status.version++
+			return m, statusClearCmd(m.status.version)
+		}
 		return m, nil
 	case "esc":
 		if m.form.focus == controlDelete && m.form.deleteArmed {
 			m.form.deleteArmed = false
 			return m, nil
 		}
+		if m.cancelTaskFor(taskTest, m.formTestHost().ID) {
+			m.form.testStatus, m.form.testResult = "Test cancelled", false
+			return m, nil
+		}
 		m.state = stateList
 		m.form.testStatus = ""
 		m.form.formError = ""
@@ -81,16 +141,7 @@ func (m model) updateForm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				m.form.deleteArmed = true
 				return m, nil
 			}
-			snapshot := m.snapshot()
-			for idx, h := range m.rawHosts {
-				if h.ID == m.form.selectedHost.ID {
-					m.rawHosts = append(m.rawHosts[:idx], m.rawHosts[idx+1:]...)
-					break
-				}
-			}
-			m.list.SetItems(flattenHosts(m.rawGroups, m.rawHosts))
-			if err := m.save(); err != nil {
-				m.restoreSnapshot(snapshot)
+			if err := m.trashHost(m.form.selectedHost.ID); err != nil {
 				m.state = stateList
 				m.status.message = fmt.Sprintf("Failed to save host deletion: %v", err)
 				m.status.isError = true
@@ -100,7 +151,10 @@ func (m model) updateForm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			}
 			m.state = stateList
 			m.form.deleteArmed = false
-			return m, nil
+			m.status.message = "Moved " + m.form.selectedHost.Alias + " to the trash · T to restore"
+			m.status.isError = false
+			m.status.version++
+			return m, statusClearCmd(m.status.version)
 		}
 		if m.form.focus == controlKeyPicker {
 			m.pickerUse = pickerIdentity
@@ -108,43 +162,32 @@ func (m model) updateForm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.state = stateFilePicker
 			return m, m.filepicker.Init()
 		}
-		if m.form.focus == controlForwardAgent {
-			m.toggleForwardAgent()
+		if isFormToggle(m.form.focus) {
+			m.toggleFormControl(m.form.focus)
 			return m, nil
 		}
 		if m.form.focus == controlGroup && !m.form.groupCustom {
-			if len(m.form.groupOptions) > 0 && m.form.groupOptions[m.form.groupIndex] == "+ New group..." {
-				m.form.groupCustom = true
-				m.form.inputs[fieldGroup].SetValue("")
-				m.form.inputs[fieldGroup].Placeholder = "new group name"
-				return m, m.focusInputs()
-			}
+			return m.openGroupPicker("")
 		}
 		return m.moveFormFocus(1)
 	case " ":
-		if m.form.focus == controlForwardAgent {
-			m.toggleForwardAgent()
+		if isFormToggle(m.form.focus) {
+			m.toggleFormControl(m.form.focus)
 			return m, nil
 		}
+		if m.form.focus == controlGroup && !m.form.groupCustom {
+			return m.openGroupPicker("")
+		}
 		return m.updateFocusedFormInput(msg)
 	case "left":
-		if m.form.focus == controlGroup && !m.form.groupCustom {
-			if len(m.form.groupOptions) > 0 {
-				m.form.groupIndex--
-				if m.form.groupIndex < 0 {
-					m.form.groupIndex = len(m.form.groupOptions) - 1
-				}
-				m.applyGroupSelectionToInput()
-			}
+		if m.form.focus == controlTransport {
+			m.form.inputs[fieldTransport].SetValue(nextTransport(m.form.inputs[fieldTransport].Value(), -1))
 			return m, nil
 		}
 		return m.updateFocusedFormInput(msg)
 	case "right":
-		if m.form.focus == controlGroup && !m.form.groupCustom {
-			if len(m.form.groupOptions) > 0 {
-				m.form.groupIndex = (m.form.groupIndex + 1) % len(m.form.groupOptions)
-				m.applyGroupSelectionToInput()
-			}
+		if m.form.focus == controlTransport {
+			m.toggleFormControl(controlTransport)
 			return m, nil
 		}
 		return m.updateFocusedFormInput(msg)
@@ -154,6 +197,10 @@ func (m model) updateForm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			return m, nil
 		}
 		if m.form.focus == controlGroup && !m.form.groupCustom {
+			if msg.Type == tea.KeyRunes {
+				// Typing on the group searches for it.
+				return m.openGroupPicker(string(msg.Runes))
+			}
 			return m, nil
 		}
 		return m.updateFocusedFormInput(msg)
@@ -183,30 +230,78 @@ func (m model) moveFormFocus(delta int) (tea.Model, tea.Cmd) {
 	if next > int(last) {
 		next = int(controlAlias)
 	}
+	m.form.advanced = m.formAdvancedTab()
 	m.form.focus = formControl(next)
+	m.setPasswordRevealed(false)
 	m.form.formError = ""
 	m.form.deleteArmed = false
 	return m, m.focusInputs()
 }
 
-func (m *model) toggleForwardAgent() {
-	if forwardAgentEnabled(m.form.inputs[fieldForwardAgent].Value()) {
-		m.form.inputs[fieldForwardAgent].SetValue("")
+// isFormToggle reports whether control is an on/off switch rather than a
+// text field.
+func isFormToggle(control formControl) bool {
+	return control == controlForwardAgent || control == controlUseSSHConfig || control == controlTransport || control == controlNoLocale
+}
+
+func (m *model) toggleFormControl(control formControl) {
+	if control == controlTransport {
+		m.form.inputs[fieldTransport].SetValue(nextTransport(m.form.inputs[fieldTransport].Value(), 1))
+		return
+	}
+	field, _ := fieldForFormControl(control)
+	if formToggleEnabled(m.form.inputs[field].Value()) {
+		m.form.inputs[field].SetValue("")
 	} else {
-		m.form.inputs[fieldForwardAgent].SetValue("yes")
+		m.form.inputs[field].SetValue("yes")
 	}
 }
 
 func (m *model) focusFormError(err error) {
-	message := strings.ToLower(err.Error())
+	if control, ok := formErrorControl(err.Error()); ok {
+		m.form.focus = control
+	}
+}
+
+// formErrorControl maps a save error to the control it is about, so the form
+// can focus the field and mark it.
+func formErrorControl(message string) (formControl, bool) {
+	message = strings.ToLower(message)
 	switch {
 	case strings.HasPrefix(message, "alias"):
-		m.form.focus = controlAlias
+		return controlAlias, true
+	case strings.HasPrefix(message, "internal subnet"):
+		return controlInternalNets, true
+	case strings.HasPrefix(message, "internal"):
+		return controlInternalHost, true
 	case strings.HasPrefix(message, "hostname"):
-		m.form.focus = controlHostname
+		return controlHostname, true
 	case strings.HasPrefix(message, "port"):
-		m.form.focus = controlPort
+		return controlPort, true
+	case strings.HasPrefix(message, "timeout"):
+		return controlTimeout, true
+	case strings.HasPrefix(message, "hotkey"):
+		return controlHotkey, true
+	case strings.HasPrefix(message, "identity agent"):
+		return controlIdentityAgent, true
+	case strings.HasPrefix(message, "terminal"):
+		return controlTerminal, true
+	case strings.HasPrefix(message, "term"):
+		return controlTerm, true
+	case strings.HasPrefix(message, "user"):
+		return controlUser, true
+	case strings.HasPrefix(message, "proxyjump"):
+		return controlProxyJump, true
+	case strings.HasPrefix(message, "proxycommand"):
+		return controlProxyCommand, true
+	case strings.HasPrefix(message, "tmux session"), strings.HasPrefix(message, "set either a remote command"):
+		return controlTmuxSession, true
+	case strings.HasPrefix(message, "web ui"):
+		return controlWebURLs, true
+	case strings.HasPrefix(message, "expiry"):
+		return controlExpires, true
 	case strings.HasPrefix(message, "new group"):
-		m.form.focus = controlGroup
+		return controlGroup, true
 	}
+	return 0, false
 }
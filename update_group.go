@@ -8,6 +8,9 @@ import (
 )
 
 func (m model) updateGroupPrompt(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.completeGPrefix(msg) {
+		return m, nil
+	}
 	switch msg.String() {
 	case "esc":
 		m.state = stateList
@@ -15,12 +18,55 @@ func (m model) updateGroupPrompt(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.groupPrompt.target = ""
 		m.form.formError = ""
 		return m, nil
+	case "tab", "shift+tab", "up", "down":
+		inputs := m.groupPrompt.inputs()
+		step := 1
+		if msg.String() == "shift+tab" || msg.String() == "up" {
+			step = len(inputs) - 1
+		}
+		current := m.groupPrompt.focused()
+		next := inputs[0]
+		for i, input := range inputs {
+			if input == current {
+				next = inputs[(i+step)%len(inputs)]
+			}
+		}
+		current.Blur()
+		return m, next.Focus()
 	case "enter":
 		name := strings.TrimSpace(m.groupPrompt.input.Value())
 		if name == "" {
 			m.form.formError = "group name is required"
 			return m, nil
 		}
+		query := ""
+		if m.groupPrompt.smart {
+			query = strings.Join(strings.Fields(m.groupPrompt.query.Value()), " ")
+			if _, err := parseHostQuery(query); err != nil {
+				m.form.formError = err.Error()
+				return m, nil
+			}
+		}
+		description := strings.TrimSpace(m.groupPrompt.description.Value())
+		color := strings.TrimSpace(m.groupPrompt.color.Value())
+		if err := validateGroupColor(color); err != nil {
+			m.form.formError = err.Error()
+			return m, nil
+		}
+		var defaults Group
+		if !m.groupPrompt.smart {
+			defaults.DefaultUser = strings.TrimSpace(m.groupPrompt.defaultUser.Value())
+			defaults.DefaultIdentityFile = strings.TrimSpace(m.groupPrompt.defaultKey.Value())
+			defaults.DefaultProxyJump = strings.TrimSpace(m.groupPrompt.defaultJump.Value())
+			err := checkArgValue("default user", defaults.DefaultUser)
+			if err == nil {
+				err = checkArgValue("default proxyjump", defaults.DefaultProxyJump)
+			}
+			if err != nil {
+				m.form.formError = err.Error()
+				return m, nil
+			}
+		}
 		if idx := findGroupByName(m.rawGroups, name); idx != -1 {
 			if m.groupPrompt.action == "rename" && m.rawGroups[idx].ID == m.groupPrompt.target {
 				// no-op rename to same value
@@ -31,8 +77,18 @@ func (m model) updateGroupPrompt(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		if m.groupPrompt.action == "create" {
 			snapshot := m.snapshot()
-			m.rawGroups = append(m.rawGroups, Group{ID: newGroupID(), Name: name, Expanded: true})
-			m.list.SetItems(flattenHosts(m.rawGroups, m.rawHosts))
+			m.rawGroups = append(m.rawGroups, Group{
+				ID:                  newGroupID(),
+				Name:                name,
+				Description:         description,
+				Color:               color,
+				Expanded:            true,
+				Query:               query,
+				DefaultUser:         defaults.DefaultUser,
+				DefaultIdentityFile: defaults.DefaultIdentityFile,
+				DefaultProxyJump:    defaults.DefaultProxyJump,
+			})
+			m.refreshList()
 			if err := m.save(); err != nil {
 				m.restoreSnapshot(snapshot)
 				m.form.formError = fmt.Sprintf("failed to save group changes: %v", err)
@@ -43,10 +99,19 @@ func (m model) updateGroupPrompt(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			for i := range m.rawGroups {
 				if m.rawGroups[i].ID == m.groupPrompt.target {
 					m.rawGroups[i].Name = name
+					m.rawGroups[i].Description = description
+					m.rawGroups[i].Color = color
+					if m.groupPrompt.smart {
+						m.rawGroups[i].Query = query
+					} else {
+						m.rawGroups[i].DefaultUser = defaults.DefaultUser
+						m.rawGroups[i].DefaultIdentityFile = defaults.DefaultIdentityFile
+						m.rawGroups[i].DefaultProxyJump = defaults.DefaultProxyJump
+					}
 					break
 				}
 			}
-			m.list.SetItems(flattenHosts(m.rawGroups, m.rawHosts))
+			m.refreshList()
 			if err := m.save(); err != nil {
 				m.restoreSnapshot(snapshot)
 				m.form.formError = fmt.Sprintf("failed to save group changes: %v", err)
@@ -58,9 +123,9 @@ func (m model) updateGroupPrompt(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.groupPrompt.target = ""
 		m.form.formError = ""
 		return m, nil
-	default:
-		var cmd tea.Cmd
-		m.groupPrompt.input, cmd = m.groupPrompt.input.Update(msg)
-		return m, cmd
 	}
+	var cmd tea.Cmd
+	input := m.groupPrompt.focused()
+	*input, cmd = input.Update(msg)
+	return m, cmd
 }
@@ -9,14 +9,22 @@ import (
 
 func (m model) updateList(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	if m.list.FilterState() == list.Filtering {
+		if msg.String() == "enter" && launcherMode() {
+			if h, ok := launcherMatch(m.list.VisibleItems(), m.list.FilterValue()); ok {
+				return m.connectToHost(h)
+			}
+		}
 		var cmd tea.Cmd
 		m.list, cmd = m.list.Update(msg)
 		// Filter cancelled — restore actual expansion state.
 		if m.list.FilterState() == list.Unfiltered {
-			m.list.SetItems(flattenHosts(m.rawGroups, m.rawHosts))
+			m.refreshList()
 		}
 		return m, cmd
 	}
+	if msg.Paste {
+		return m.openPasteImport(string(msg.Runes))
+	}
 	if m.listDelete.armed && msg.String() != "d" && msg.String() != "x" && msg.String() != "esc" {
 		m.clearListDeleteConfirm()
 	}
@@ -29,6 +37,23 @@ func (m model) updateList(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.clearListDeleteConfirm()
 			return m, nil
 		}
+		// With a filter applied, esc clears it first.
+		if m.list.FilterState() != list.Unfiltered {
+			break
+		}
+		if len(m.marked) > 0 {
+			m.clearMarks()
+			return m, nil
+		}
+		if n := m.cancelRunning(taskScan); n > 0 {
+			m.status.message = "Scan cancelled"
+			if n > 1 {
+				m.status.message = fmt.Sprintf("%d scans cancelled", n)
+			}
+			m.status.isError = false
+			m.status.version++
+			return m, statusClearCmd(m.status.version)
+		}
 	case "q":
 		m.quitting = true
 		return m, tea.Quit
@@ -46,7 +71,7 @@ func (m model) updateList(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			for idx := range m.rawGroups {
 				if m.rawGroups[idx].ID == i.ID {
 					m.rawGroups[idx].Expanded = !m.rawGroups[idx].Expanded
-					m.list.SetItems(flattenHosts(m.rawGroups, m.rawHosts))
+					m.refreshList()
 					return m, nil
 				}
 			}
@@ -57,8 +82,11 @@ func (m model) updateList(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			if msg.String() == "space" {
 				for idx, h := range m.rawHosts {
 					if h.ID == i.ID {
-						m.rawHosts[idx].Expanded = !m.rawHosts[idx].Expanded
-						m.list.SetItems(flattenHosts(m.rawGroups, m.rawHosts))
+						if !h.Expanded {
+							return m.expandHost(idx)
+						}
+						m.rawHosts[idx].Expanded = false
+						m.refreshList()
 						return m, nil
 					}
 				}
@@ -73,7 +101,7 @@ func (m model) updateList(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				if m.rawGroups[idx].ID == g.ID {
 					if !m.rawGroups[idx].Expanded {
 						m.rawGroups[idx].Expanded = true
-						m.list.SetItems(flattenHosts(m.rawGroups, m.rawHosts))
+						m.refreshList()
 					}
 					return m, nil
 				}
@@ -83,13 +111,7 @@ func (m model) updateList(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			for idx, h := range m.rawHosts {
 				if h.ID == i.ID {
 					if !h.Expanded {
-						m.rawHosts[idx].Expanded = true
-						if len(h.Containers) == 0 {
-							m.scanning = true
-							m.list.SetItems(flattenHosts(m.rawGroups, m.rawHosts))
-							return m, scanDockerContainers(m.rawHosts[idx], idx, false)
-						}
-						m.list.SetItems(flattenHosts(m.rawGroups, m.rawHosts))
+						return m.expandHost(idx)
 					}
 					return m, nil
 				}
@@ -101,7 +123,7 @@ func (m model) updateList(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				if m.rawGroups[idx].ID == g.ID {
 					if m.rawGroups[idx].Expanded {
 						m.rawGroups[idx].Expanded = false
-						m.list.SetItems(flattenHosts(m.rawGroups, m.rawHosts))
+						m.refreshList()
 					}
 					return m, nil
 				}
@@ -112,19 +134,18 @@ func (m model) updateList(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				if h.ID == i.ID {
 					if h.Expanded {
 						m.rawHosts[idx].Expanded = false
-						m.list.SetItems(flattenHosts(m.rawGroups, m.rawHosts))
+						m.refreshList()
 					}
 					return m, nil
 				}
 			}
 		}
 	case "ctrl+d":
+		if g, ok := m.list.SelectedItem().(groupItem); ok {
+			return m.startGroupRun(g, groupRunScan)
+		}
 		if i, ok := m.list.SelectedItem().(Host); ok && !i.IsContainer {
-			idx := findHostIndexByID(m.rawHosts, i.ID)
-			if idx != -1 {
-				m.scanning = true
-				return m, scanDockerContainers(m.rawHosts[idx], idx, false)
-			}
+			return m.startScan(i.ID)
 		}
 	case "e":
 		if i, ok := m.list.SelectedItem().(Host); ok && !i.IsContainer {
@@ -148,6 +169,14 @@ func (m model) updateList(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.populateForm(clone)
 			return m, m.focusInputs()
 		}
+	case "C":
+		if i, ok := m.list.SelectedItem().(Host); ok && !i.IsContainer {
+			return m.openBulkClone(i)
+		}
+	case "f":
+		if i, ok := m.list.SelectedItem().(Host); ok && !i.IsContainer {
+			return m.openFirstContact(i)
+		}
 	case "d":
 		if index := m.list.Index(); index >= 0 && len(m.list.Items()) > 0 {
 			if g, ok := m.list.SelectedItem().(groupItem); ok {
@@ -169,22 +198,17 @@ func (m model) updateList(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 					m.listDelete = listDeleteState{armed: true, id: i.ID, kind: "host", label: i.Alias}
 					return m, nil
 				}
-				snapshot := m.snapshot()
-				for idx, h := range m.rawHosts {
-					if h.ID == i.ID {
-						m.rawHosts = append(m.rawHosts[:idx], m.rawHosts[idx+1:]...)
-						break
-					}
-				}
-				m.list.SetItems(flattenHosts(m.rawGroups, m.rawHosts))
-				if err := m.save(); err != nil {
-					m.restoreSnapshot(snapshot)
+				if err := m.trashHost(i.ID); err != nil {
 					m.status.message = fmt.Sprintf("Failed to save host deletion: %v", err)
 					m.status.isError = true
 					m.status.version++
 					return m, statusClearCmd(m.status.version)
 				}
 				m.clearListDeleteConfirm()
+				m.status.message = "Moved " + i.Alias + " to the trash · T to restore"
+				m.status.isError = false
+				m.status.version++
+				return m, statusClearCmd(m.status.version)
 			}
 		}
 	case "p":
@@ -193,7 +217,7 @@ func (m model) updateList(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			if idx != -1 {
 				snapshot := m.snapshot()
 				m.rawHosts[idx].Pinned = !m.rawHosts[idx].Pinned
-				m.list.SetItems(flattenHosts(m.rawGroups, m.rawHosts))
+				m.refreshList()
 				if err := m.save(); err != nil {
 					m.restoreSnapshot(snapshot)
 					m.status.message = fmt.Sprintf("Failed to save: %v", err)
@@ -203,8 +227,50 @@ func (m model) updateList(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				}
 			}
 		}
+	case "A":
+		if i, ok := m.list.SelectedItem().(Host); ok && !i.IsContainer {
+			idx := findHostIndexByID(m.rawHosts, i.ID)
+			if idx != -1 {
+				snapshot := m.snapshot()
+				m.rawHosts[idx].Archived = !m.rawHosts[idx].Archived
+				m.refreshList()
+				if err := m.save(); err != nil {
+					m.restoreSnapshot(snapshot)
+					m.status.message = fmt.Sprintf("Failed to save: %v", err)
+					m.status.isError = true
+					m.status.version++
+					return m, statusClearCmd(m.status.version)
+				}
+				if m.rawHosts[idx].Archived {
+					m.status.message = fmt.Sprintf("Archived %s · press . to show archived hosts", i.Alias)
+				} else {
+					m.status.message = fmt.Sprintf("Restored %s", i.Alias)
+					m.reselectItem(i.ID, false)
+				}
+				m.status.isError = false
+				m.status.version++
+				return m, statusClearCmd(m.status.version)
+			}
+		}
+	case ".":
+		m.clearListDeleteConfirm()
+		m.showArchived = !m.showArchived
+		m.refreshList()
+		return m, nil
+	case "o":
+		m.clearListDeleteConfirm()
+		m.runningOnly = !m.runningOnly
+		m.refreshList()
+		if m.runningOnly {
+			m.status.message = "Showing running containers only · press o to show all"
+		} else {
+			m.status.message = "Showing all containers"
+		}
+		m.status.isError = false
+		m.status.version++
+		return m, statusClearCmd(m.status.version)
 	case "i":
-		imported, skipped, err := importSSHConfig(m.rawHosts)
+		imported, changed, skipped, err := importSSHConfig(m.rawHosts)
 		if err != nil {
 			m.status.message = err.Error()
 			m.status.isError = true
@@ -213,7 +279,7 @@ func (m model) updateList(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		snapshot := m.snapshot()
 		m.rawHosts = append(m.rawHosts, imported...)
-		m.list.SetItems(flattenHosts(m.rawGroups, m.rawHosts))
+		m.refreshList()
 		if err := m.save(); err != nil {
 			m.restoreSnapshot(snapshot)
 			m.status.message = fmt.Sprintf("Imported %d hosts but failed to save: %v", len(imported), err)
@@ -224,13 +290,67 @@ func (m model) updateList(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.status.message = fmt.Sprintf("Imported %d hosts (%d skipped)", len(imported), skipped)
 		m.status.isError = false
 		m.status.version++
+		if len(changed) > 0 {
+			return m.openImportMerge(changed), statusClearCmd(m.status.version)
+		}
 		return m, statusClearCmd(m.status.version)
+	case "I":
+		return m.openHistoryImport()
+	case "ctrl+k":
+		return m.openKnownHostsImport()
+	case "V":
+		return m.openClipboardImport()
+	case "N":
+		return m.openHostGenerator()
+	case "z":
+		return m.toggleCompactList()
+	case "b":
+		return m.openTableView()
+	case "v":
+		if i, ok := m.list.SelectedItem().(Host); ok && !i.IsContainer {
+			return m.openDetail(i)
+		}
+	case "s":
+		if i, ok := m.list.SelectedItem().(Host); ok {
+			return m.openCommandPreview(i)
+		}
+	case "E":
+		if g, ok := m.list.SelectedItem().(groupItem); ok {
+			return m.startGroupRun(g, groupRunExport)
+		}
+	case "ctrl+t":
+		if g, ok := m.list.SelectedItem().(groupItem); ok {
+			return m.startGroupRun(g, groupRunTest)
+		}
+	case "t":
+		if i, ok := m.list.SelectedItem().(Host); ok && !i.IsContainer {
+			return m.openTransfer(i)
+		}
+	case "u":
+		if i, ok := m.list.SelectedItem().(Host); ok && !i.IsContainer {
+			return m.openBookmarks(i)
+		}
+	case "S":
+		m.state = stateStats
+		return m, nil
+	case "l":
+		return m.connectToLastHost()
+	case "1", "2", "3", "4", "5", "6", "7", "8", "9":
+		return m.quickConnect(int(msg.Runes[0] - '0'))
 	case "h":
 		m.rebuildHistoryList()
 		m.state = stateHistory
 		return m, nil
 	case "K":
 		return m.openRotation()
+	case "H":
+		return m.openSecretAudit()
+	case "m":
+		return m.toggleMark()
+	case "M":
+		return m.startSessionRound()
+	case "L":
+		return m.openTunnels()
 	case "?":
 		m.helpOpen = true
 		return m, nil
@@ -239,13 +359,46 @@ func (m model) updateList(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.about.frame = 0
 		return m, aboutTick()
 	case "g":
-		m.openGroupPrompt("create", "", "")
+		return m.startGPrefix()
+	case "ctrl+g":
+		return m.openGroupJump()
+	case "Q":
+		m.openSmartGroupPrompt("", "", "")
 		return m, nil
 	case "r":
 		if g, ok := m.list.SelectedItem().(groupItem); ok {
-			m.openGroupPrompt("rename", g.ID, g.Name)
+			if g.Smart() {
+				m.openSmartGroupPrompt(g.ID, g.Name, g.Query)
+			} else {
+				m.openGroupPrompt("rename", g.ID, g.Name)
+			}
 			return m, nil
 		}
+		if h, ok := m.list.SelectedItem().(Host); ok && !h.IsContainer {
+			return m.openHostRename(h)
+		}
+	case "R":
+		return m.openBatchRename()
+	case "w":
+		if h, ok := m.list.SelectedItem().(Host); ok && !h.IsContainer {
+			return m.toggleMaintenance(h)
+		}
+	case "y":
+		if h, ok := m.list.SelectedItem().(Host); ok && !h.IsContainer {
+			return m.copyPublicKey(h)
+		}
+	case "ctrl+f":
+		return m.fixKeyPermissions()
+	case "T":
+		return m.openTrash()
+	case "W":
+		return m.openWindowsServices()
+	case "F":
+		return m.openPortForward()
+	case "P":
+		return m.openCompose()
+	case "J":
+		return m.openTasks()
 	case "shift+up":
 		if msg := m.moveItem(-1); msg != "" {
 			m.status.message = msg
@@ -268,6 +421,21 @@ func (m model) updateList(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			clearCmd = statusClearCmd(m.status.version)
 		}
 		return m, clearCmd
+	case "shift+left", "shift+right":
+		direction := -1
+		if msg.String() == "shift+right" {
+			direction = +1
+		}
+		if msg := m.moveItemAcross(direction); msg != "" {
+			m.status.message = msg
+			m.status.isError = true
+		}
+		m.status.version++
+		var clearCmd tea.Cmd
+		if m.status.message != "" {
+			clearCmd = statusClearCmd(m.status.version)
+		}
+		return m, clearCmd
 	case "x":
 		if g, ok := m.list.SelectedItem().(groupItem); ok {
 			if !m.listDelete.armed || m.listDelete.id != g.ID || m.listDelete.kind != "group" {
@@ -288,13 +456,13 @@ func (m model) updateList(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	prevFilterState := m.list.FilterState()
 	// Entering filter mode: pre-load all hosts so collapsed groups are searchable.
 	if prevFilterState == list.Unfiltered && msg.String() == "/" {
-		m.list.SetItems(flattenAll(m.rawGroups, m.rawHosts))
+		return m, m.startFiltering()
 	}
 	var cmd tea.Cmd
 	m.list, cmd = m.list.Update(msg)
 	// Filter cleared from FilterApplied state — restore actual expansion.
 	if prevFilterState != list.Unfiltered && m.list.FilterState() == list.Unfiltered {
-		m.list.SetItems(flattenHosts(m.rawGroups, m.rawHosts))
+		m.refreshList()
 	}
 	return m, cmd
 }
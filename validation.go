@@ -0,0 +1,211 @@
+package main
+
+import (
+	"fmt"
+	"net/netip"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// --- Form Field Validation ---
+
+// argMetacharacters break a value once it is pasted into a shell, written to
+// an exported ssh config, or shown as a copyable command line.
+const argMetacharacters = "|&;<>()$`\\\"'*?{}!"
+
+// checkArgValue rejects whitespace, shell metacharacters, and a leading dash
+// that ssh would parse as an option. name prefixes the error so the form can
+// focus the offending field.
+func checkArgValue(name, value string) error {
+	if strings.HasPrefix(value, "-") {
+		return fmt.Errorf("%s must not start with '-'", name)
+	}
+	for _, r := range value {
+		if unicode.IsSpace(r) {
+			return fmt.Errorf("%s must not contain whitespace", name)
+		}
+		if strings.ContainsRune(argMetacharacters, r) {
+			return fmt.Errorf("%s must not contain %q", name, r)
+		}
+	}
+	return nil
+}
+
+// validateHostname accepts DNS names, IPv4 addresses, and IPv6 literals with
+// optional brackets and zone ID.
+func validateHostname(hostname string) error {
+	if err := checkArgValue("hostname", hostname); err != nil {
+		return err
+	}
+	if strings.HasPrefix(hostname, "[") || strings.Contains(hostname, ":") {
+		literal := hostname
+		if strings.HasPrefix(literal, "[") {
+			if !strings.HasSuffix(literal, "]") {
+				return fmt.Errorf("hostname has an unterminated '['")
+			}
+			literal = literal[1 : len(literal)-1]
+		}
+		if addr, err := netip.ParseAddr(literal); err != nil || !addr.Is6() {
+			return fmt.Errorf("hostname %q is not a valid IPv6 address", hostname)
+		}
+		return nil
+	}
+	if _, err := netip.ParseAddr(hostname); err == nil {
+		return nil
+	}
+	if len(hostname) > 253 {
+		return fmt.Errorf("hostname is longer than 253 characters")
+	}
+	for _, label := range strings.Split(strings.TrimSuffix(hostname, "."), ".") {
+		if label == "" || len(label) > 63 {
+			return fmt.Errorf("hostname %q has an empty or overlong label", hostname)
+		}
+		if strings.HasPrefix(label, "-") || strings.HasSuffix(label, "-") {
+			return fmt.Errorf("hostname label %q must not start or end with '-'", label)
+		}
+		for _, r := range label {
+			if r > unicode.MaxASCII || !(r == '-' || r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)) {
+				return fmt.Errorf("hostname must not contain %q", r)
+			}
+		}
+	}
+	return nil
+}
+
+// identityFileWarning reports a key path that cannot be read. It is a warning
+// rather than an error because the key may live on a drive that is not
+// mounted yet.
+func identityFileWarning(path string) string {
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return ""
+	}
+	info, err := os.Stat(expandPath(path))
+	switch {
+	case err != nil:
+		return "key file not found: " + path
+	case info.IsDir():
+		return "key file is a directory: " + path
+	}
+	return ""
+}
+
+// sshConfigAliasWarning reports an alias that no Host block in ~/.ssh/config
+// names, which a host connecting as `ssh <alias>` needs. Blocks that only
+// match through wildcards (Host *) do not count.
+func sshConfigAliasWarning(alias string) string {
+	alias = strings.TrimSpace(alias)
+	if alias == "" {
+		return ""
+	}
+	blocks, err := parseSSHConfigBlocks("~/.ssh/config")
+	if err != nil {
+		return "~/.ssh/config cannot be read"
+	}
+	for _, b := range blocks {
+		for _, p := range b.patterns {
+			if !isWildcard(p) && strings.EqualFold(p, alias) {
+				return ""
+			}
+		}
+	}
+	return "no Host " + alias + " block in ~/.ssh/config"
+}
+
+// duplicateEndpoint returns the alias of another host that logs in as the
+// same user on the same hostname and port as h, or "". Group defaults count,
+// a blank port is 22, and hostnames compare without case.
+func duplicateEndpoint(h Host, hosts []Host, groups []Group) string {
+	endpoint := func(h Host) string {
+		h = applyGroupDefaults(h, groups)
+		port := strings.TrimSpace(h.Port)
+		if port == "" {
+			port = "22"
+		}
+		return strings.TrimSpace(h.User) + "@" + strings.ToLower(bareHostname(h.Hostname)) + ":" + port
+	}
+	if strings.TrimSpace(h.Hostname) == "" {
+		return ""
+	}
+	want := endpoint(h)
+	for _, other := range hosts {
+		if other.ID != h.ID && !other.IsContainer && endpoint(other) == want {
+			return other.Alias
+		}
+	}
+	return ""
+}
+
+// formDuplicateWarning names the host the form's endpoint duplicates.
+func (m model) formDuplicateWarning() string {
+	if alias := duplicateEndpoint(m.formTestHost(), m.rawHosts, m.rawGroups); alias != "" {
+		return "same hostname, port, and user as " + alias
+	}
+	return ""
+}
+
+// formControlIssue returns the inline problem shown beneath a form control,
+// and whether it blocks saving.
+func (m model) formControlIssue(control formControl) (string, bool) {
+	var err error
+	switch control {
+	case controlHostname:
+		if value := strings.TrimSpace(m.form.inputs[fieldHostname].Value()); value != "" {
+			if err = validateHostname(value); err == nil {
+				return m.formDuplicateWarning(), false
+			}
+		}
+	case controlInternalHost:
+		if value := strings.TrimSpace(m.form.inputs[fieldInternalHost].Value()); value != "" {
+			if err = validateHostname(value); err != nil {
+				err = fmt.Errorf("internal %w", err)
+			}
+		}
+	case controlInternalNets:
+		err = validateSubnets(parseSubnets(m.form.inputs[fieldInternalNets].Value()))
+	case controlTimeout:
+		_, err = parseConnectTimeout(m.form.inputs[fieldTimeout].Value())
+	case controlUser:
+		err = checkArgValue("user", strings.TrimSpace(m.form.inputs[fieldUser].Value()))
+	case controlTerm:
+		err = checkArgValue("term", strings.TrimSpace(m.form.inputs[fieldTerm].Value()))
+	case controlHotkey:
+		var digit int
+		if digit, err = parseHotkey(m.form.inputs[fieldHotkey].Value()); err == nil && digit != 0 {
+			return m.hotkeyWarning(digit), false
+		}
+	case controlTerminal:
+		value := m.form.inputs[fieldTerminal].Value()
+		if err = validateTerminal(strings.TrimSpace(value)); err == nil {
+			return terminalWarning(value), false
+		}
+	case controlIdentityAgent:
+		err = validateIdentityAgent(strings.TrimSpace(m.form.inputs[fieldIdentityAgent].Value()))
+	case controlProxyJump:
+		err = checkArgValue("proxyjump", strings.TrimSpace(m.form.inputs[fieldProxyJump].Value()))
+	case controlProxyCommand:
+		command := m.form.inputs[fieldProxyCommand].Value()
+		if err = validateProxyCommand(strings.TrimSpace(command)); err == nil && strings.Contains(command, proxyExampleHost) {
+			return "replace " + proxyExampleHost + " with your proxy", false
+		}
+	case controlPassword:
+		return m.passwordWarning(), false
+	case controlKeyFile:
+		value := m.form.inputs[fieldKeyFile].Value()
+		if warning := identityFileWarning(value); warning != "" {
+			return warning, false
+		}
+		if isSecurityKeyType(identityKeyType(value)) {
+			return "security key: touch it when connecting; tests and scans skip it", false
+		}
+	case controlUseSSHConfig:
+		if formToggleEnabled(m.form.inputs[fieldUseSSHConfig].Value()) {
+			return sshConfigAliasWarning(m.form.inputs[fieldAlias].Value()), false
+		}
+	}
+	if err != nil {
+		return err.Error(), true
+	}
+	return "", false
+}
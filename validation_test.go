@@ -0,0 +1,162 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestValidateHostname(t *testing.T) {
+	valid := []string{"example.com", "db-1.internal.", "my_host", "10.0.0.1", "::1", "[fe80::1%eth0]", "2001:db8::5"}
+	for _, h := range valid {
+		if err := validateHostname(h); err != nil {
+			t.Errorf("validateHostname(%q) = %v, want nil", h, err)
+		}
+	}
+	invalid := []string{"-oProxyCommand=x", "web 1", "web;rm", "host$(id)", "[::1", "1.2.3.4:22", "bad..name", "-web.example.com", "web-.example.com", "héllo.com"}
+	for _, h := range invalid {
+		if err := validateHostname(h); err == nil {
+			t.Errorf("validateHostname(%q) = nil, want error", h)
+		}
+	}
+}
+
+func TestSaveFromFormRejectsUnsafeFields(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("ASSHO_STORE_PASSWORD", "0")
+
+	cases := []struct {
+		field int
+		value string
+		focus formControl
+	}{
+		{fieldHostname, "web 1", controlHostname},
+		{fieldUser, "root;id", controlUser},
+		{fieldProxyJump, "-oProxyCommand=sh", controlProxyJump},
+	}
+	for _, tc := range cases {
+		m := model{form: formState{inputs: newFormInputs()}, historyList: newTestHistoryListModel()}
+		m.list = newTestListModel(nil, nil)
+		m.form.inputs[fieldAlias].SetValue("app")
+		m.form.inputs[fieldHostname].SetValue("10.0.0.1")
+		m.form.inputs[tc.field].SetValue(tc.value)
+		m.buildGroupOptions("")
+
+		err := m.saveFromForm()
+		if err == nil {
+			t.Fatalf("expected %q to be rejected", tc.value)
+		}
+		m.focusFormError(err)
+		if m.form.focus != tc.focus {
+			t.Errorf("%q: expected focus on control %d, got %d", tc.value, tc.focus, m.form.focus)
+		}
+		if len(m.rawHosts) != 0 {
+			t.Errorf("%q: expected nothing saved", tc.value)
+		}
+	}
+}
+
+func TestIdentityFileWarningIsInlineOnly(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("ASSHO_STORE_PASSWORD", "0")
+	if err := os.WriteFile(filepath.Join(home, "id_test"), []byte("key"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if warning := identityFileWarning("~/id_test"); warning != "" {
+		t.Fatalf("expected no warning for an existing key, got %q", warning)
+	}
+
+	m := model{form: formState{inputs: newFormInputs()}, historyList: newTestHistoryListModel()}
+	m.list = newTestListModel(nil, nil)
+	m.form.inputs[fieldAlias].SetValue("app")
+	m.form.inputs[fieldHostname].SetValue("10.0.0.1")
+	m.form.inputs[fieldKeyFile].SetValue("~/missing")
+	m.buildGroupOptions("")
+
+	issue, blocking := m.formControlIssue(controlKeyFile)
+	if blocking || !strings.Contains(issue, "not found") {
+		t.Fatalf("expected non-blocking missing key warning, got %q blocking=%v", issue, blocking)
+	}
+	if !strings.Contains(ansi.Strip(m.renderFormControlBlock(controlKeyFile, 60, false)), "key file not found") {
+		t.Fatal("expected warning rendered beneath the key file field")
+	}
+	if err := m.saveFromForm(); err != nil {
+		t.Fatalf("expected save to succeed despite missing key file: %v", err)
+	}
+}
+
+func TestSSHConfigAliasWarning(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	if warning := sshConfigAliasWarning("bastion"); !strings.Contains(warning, "cannot be read") {
+		t.Fatalf("expected a warning without ~/.ssh/config, got %q", warning)
+	}
+	if err := os.MkdirAll(filepath.Join(home, ".ssh"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	config := "Host *\n    User deploy\n\nHost bastion jump\n    HostName 10.0.0.1\n"
+	if err := os.WriteFile(filepath.Join(home, ".ssh", "config"), []byte(config), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if warning := sshConfigAliasWarning("bastion"); warning != "" {
+		t.Fatalf("expected no warning for a declared alias, got %q", warning)
+	}
+	if warning := sshConfigAliasWarning("web"); !strings.Contains(warning, "no Host web block") {
+		t.Fatalf("expected a wildcard-only match to warn, got %q", warning)
+	}
+}
+
+func TestDuplicateEndpoint(t *testing.T) {
+	groups := []Group{{ID: "g1", Name: "web", DefaultUser: "deploy"}}
+	hosts := []Host{
+		{ID: "a", Alias: "web1", Hostname: "Web1.Example.com", User: "deploy", Port: "22"},
+		{ID: "b", Alias: "web1-admin", Hostname: "web1.example.com", User: "root", Port: "22"},
+		{ID: "c", Alias: "v6", Hostname: "[2001:db8::1]", Port: "2222", GroupID: "g1"},
+	}
+	cases := []struct {
+		h    Host
+		want string
+	}{
+		{Host{Hostname: "web1.example.com", User: "deploy"}, "web1"},
+		{Host{Hostname: "web1.example.com", User: "deploy", Port: "2200"}, ""},
+		{Host{Hostname: "web1.example.com", GroupID: "g1"}, "web1"},
+		{Host{Hostname: "2001:db8::1", User: "deploy", Port: "2222"}, "v6"},
+		{Host{ID: "a", Hostname: "web1.example.com", User: "deploy", Port: "22"}, ""},
+		{Host{User: "deploy"}, ""},
+	}
+	for _, tc := range cases {
+		if got := duplicateEndpoint(tc.h, hosts, groups); got != tc.want {
+			t.Errorf("duplicateEndpoint(%+v) = %q, want %q", tc.h, got, tc.want)
+		}
+	}
+}
+
+func TestFormSavesDuplicateWithWarning(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("ASSHO_STORE_PASSWORD", "0")
+	hosts := []Host{{ID: "a", Alias: "web1", Hostname: "10.0.0.1", User: "root", Port: "22"}}
+	m := model{state: stateForm, rawHosts: hosts, form: newFormState(newFormInputs()), historyList: newTestHistoryListModel()}
+	m.list = newTestListModel(nil, hosts)
+	m.buildGroupOptions("")
+	m.form.inputs[fieldAlias].SetValue("web1-copy")
+	m.form.inputs[fieldHostname].SetValue("10.0.0.1")
+	m.form.inputs[fieldUser].SetValue("root")
+	m.form.inputs[fieldPort].SetValue("22")
+	if issue, blocking := m.formControlIssue(controlHostname); blocking || !strings.Contains(issue, "web1") {
+		t.Fatalf("expected an inline duplicate warning, got %q", issue)
+	}
+
+	result, _ := m.updateForm(tea.KeyMsg{Type: tea.KeyCtrlS})
+	got := result.(model)
+	if len(got.rawHosts) != 2 || got.state != stateList {
+		t.Fatalf("expected the duplicate saved anyway, got %d hosts", len(got.rawHosts))
+	}
+	if got.status.message != "Saved · warning: same hostname, port, and user as web1" {
+		t.Fatalf("unexpected status %q", got.status.message)
+	}
+}
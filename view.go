@@ -60,27 +60,98 @@ func (m model) View() string {
 			view = m.renderKeyInstallView()
 		case stateRotation:
 			view = m.renderRotationView()
+		case stateDetail:
+			view = m.renderDetailView()
+		case stateStats:
+			view = m.renderStatsView()
+		case stateCommandPreview:
+			view = m.renderCommandPreviewView()
+		case stateTransfer:
+			view = m.renderTransferView()
+		case stateBookmarks:
+			view = m.renderBookmarksView()
+		case stateGroupRun:
+			view = m.renderGroupRunView()
+		case stateImportMerge:
+			view = m.renderImportMergeView()
+		case stateHostRename:
+			view = m.renderHostRenameView()
+		case stateBulkClone:
+			view = m.renderBulkCloneView()
+		case stateFirstContact:
+			view = m.renderFirstContactView()
+		case stateDiagnostics:
+			view = m.renderDiagnosticsView()
+		case stateRepair:
+			view = m.renderRepairView()
+		case stateSecretAudit:
+			view = m.renderSecretAuditView()
+		case stateBatchRename:
+			view = m.renderBatchRenameView()
+		case stateTrash:
+			view = m.renderTrashView()
+		case stateServices:
+			view = m.renderWindowsServicesView()
+		case statePortForward:
+			view = m.renderPortForwardView()
+		case stateCompose:
+			view = m.renderComposeView()
+		case stateTunnels:
+			view = m.renderTunnelsView()
+		case stateMaintenance:
+			view = m.renderMaintenanceView()
+		case stateGroupPicker:
+			view = m.renderGroupPickerView()
+		case stateGroupJump:
+			view = m.renderGroupJumpView()
+		case stateHistoryImport:
+			view = m.renderHistoryImportView()
+		case stateHostGenerator:
+			view = m.renderHostGeneratorView()
+		case stateTable:
+			view = m.renderTableView()
+		case stateSessionNote:
+			view = m.renderSessionNoteView()
 		}
 	}
+	if m.tasks.open {
+		view = m.renderTasksOverlay(view)
+	}
+	if m.hostKeyAlert != nil {
+		return m.renderHostKeyAlert(view)
+	}
 	if m.hostTrust.open {
 		return m.renderHostTrustOverlay(view)
 	}
+	if m.round.open {
+		return m.renderSessionRound(view)
+	}
 	return view
 }
 
 func (m model) renderListView() string {
-	header := renderHeader(m.headerFrame, len(m.rawHosts), countContainers(m.rawHosts))
+	header := renderHeader(m.headerFrame, len(m.rawHosts), countContainers(m.rawHosts), m.networkName, m.newRelease)
 
 	var scanStatus string
-	if m.scanning {
-		scanStatus = "\n " + m.spinner.View() + " " +
-			lipgloss.NewStyle().Foreground(colorSecondary).Render("Scanning containers...") + "\n"
+	if n := m.tasks.runningCount(taskScan); n > 0 {
+		// Each scanned host's row carries its own spinner, see rowprogress.go.
+		label := "Scanning 1 host for containers and VMs"
+		if n > 1 {
+			label = fmt.Sprintf("Scanning %d hosts for containers and VMs", n)
+		}
+		scanStatus = "\n " + lipgloss.NewStyle().Foreground(colorSecondary).Render(label) + "  " + helpEntry("esc", "cancel") + "  " + helpEntry("J", "tasks") + "\n"
 	}
 	var deleteStatus string
 	if m.listDelete.armed {
 		deleteStatus = "\n " + testFailStyle.Render("Press again to confirm delete "+m.listDelete.kind+": "+m.listDelete.label+" (Esc to cancel)") + "\n"
 	}
 
+	var markStatus string
+	if len(m.marked) > 0 {
+		markStatus = "\n " + lipgloss.NewStyle().Foreground(colorSecondary).Render(fmt.Sprintf("● %d marked", len(m.marked))) +
+			"  " + helpEntry("M", "connect in turn") + "  " + helpEntry("esc", "clear") + "\n"
+	}
+
 	var importStatus string
 	if m.status.message != "" {
 		style := testSuccessStyle
@@ -92,11 +163,11 @@ func (m model) renderListView() string {
 		importStatus = "\n " + style.Render(marker+" "+m.status.message) + "\n"
 	}
 
-	content := header + m.list.View() + scanStatus + deleteStatus + importStatus
+	content := header + m.renderNoSSHBanner() + m.renderListPosition(max(m.width-4, 20)) + m.list.View() + scanStatus + deleteStatus + markStatus + importStatus
 	if m.err != nil {
 		content += "\n" + testFailStyle.Render(" Config warning: "+m.err.Error())
 	}
-	help := "\n" + renderListHelp(m.list.SelectedItem())
+	help := "\n" + renderListHelp(m.list.SelectedItem(), m.width-4, m.noSSH != nil && m.selectedNeedsSSH())
 	return appStyle.Render(content + help)
 }
 
@@ -144,20 +215,32 @@ func renderHelpModal() string {
 	// Dashboard section
 	b.WriteString(sectionStyle.Render("DASHBOARD") + "\n")
 	b.WriteString(row("enter", "connect") + sep + row("n", "new host") + sep + row("e", "edit") + "\n")
-	b.WriteString(row("c", "duplicate") + sep + row("d/d", "delete") + sep + row("p", "pin/unpin") + "\n")
+	b.WriteString(row("c/C", "duplicate/bulk") + sep + row("d/d", "delete to trash") + sep + row("p", "pin/unpin") + "\n")
 	b.WriteString(row("space/→", "expand") + sep + row("←", "collapse") + sep + row("ctrl+d", "force scan") + "\n")
-	b.WriteString(row("/", "filter") + sep + row("h", "history") + sep + row("i", "import SSH config") + "\n")
-	b.WriteString(row("K", "staged key rotation") + "\n")
-	b.WriteString(row("g", "new group") + sep + row("r", "rename group") + sep + row("⇧↑↓", "reorder") + "\n")
-	b.WriteString(row("a", "about") + sep + row("?", "help") + sep + row("q", "quit") + "\n")
+	b.WriteString(row("/", "filter") + sep + row("h", "history") + sep + row("i/I", "import SSH config/history") + "\n")
+	b.WriteString(row("N", "generate from pattern") + sep + row("ctrl+k", "import known_hosts") + sep + row("V", "import clipboard") + "\n")
+	b.WriteString(row("K", "staged key rotation") + sep + row("v", "host details") + sep + row("S", "statistics") + "\n")
+	b.WriteString(row("f", "first-contact check") + sep + row("H", "secret audit") + sep + row("R", "batch rename") + "\n")
+	b.WriteString(row("s", "show ssh command") + sep + row("t", "scp/rsync transfer") + sep + row("u", "open web UI") + "\n")
+	b.WriteString(row("g", "new group") + sep + row("Q", "smart group") + sep + row("r", "rename") + "\n")
+	b.WriteString(row("⇧↑↓", "reorder") + sep + row("⇧←→", "move to prev/next group") + sep + row("T", "trash") + "\n")
+	b.WriteString(row("A", "archive host") + sep + row(".", "show archived") + sep + row("ctrl+t/ctrl+d/E", "group test/scan/export") + "\n")
+	b.WriteString(row("W", "Windows services") + sep + row("a", "about") + sep + row("?", "help") + "\n")
+	b.WriteString(row("o/z/b", "running only/compact/table") + sep + row("F", "forward container port") + sep + row("P", "compose projects") + "\n")
+	b.WriteString(row("m", "mark host") + sep + row("M", "connect to marked in turn") + sep + row("J", "background tasks") + "\n")
+	b.WriteString(row("L", "tunnel profiles") + sep + row("w", "maintenance on/off") + sep + row("y", "copy public key") + "\n")
+	b.WriteString(row("gg/G", "top/bottom") + sep + row("pgup/pgdn", "page") + sep + row("ctrl+g", "jump to group") + "\n")
+	b.WriteString(row("l", "reconnect to last host") + sep + row("1-9", "quick connect") + sep + row("q", "quit") + "\n")
 	b.WriteString("\n")
 
 	// Form section
 	b.WriteString(sectionStyle.Render("FORM (add / edit)") + "\n")
 	b.WriteString(row("tab/↓", "next field") + entrySep + row("⇧tab/↑", "prev field") + "\n")
-	b.WriteString(row("enter", "advance / activate") + entrySep + row("←→", "cycle group") + "\n")
+	b.WriteString(row("enter", "advance / activate / pick group") + "\n")
 	b.WriteString(row("ctrl+s", "save") + entrySep + row("ctrl+t", "test connection") + entrySep + row("esc", "cancel") + "\n")
-	b.WriteString(row("ctrl+k", "install public key (edit mode)") + "\n")
+	b.WriteString(row("ctrl+g", "network diagnostics") + entrySep + row("ctrl+p", "ProxyCommand preset") + entrySep + row("ctrl+o", "basic / advanced tab") + "\n")
+	b.WriteString(row("ctrl+k", "install public key (edit mode)") + entrySep + row("ctrl+f", "fix key permissions") + "\n")
+	b.WriteString(row("ctrl+r", "show / hide password") + "\n")
 	b.WriteString("\n")
 
 	// History section
@@ -172,8 +255,9 @@ func renderHelpModal() string {
 		{"Password", "Stored in OS keychain, not written to disk"},
 		{"Fwd. Agent", "Toggle forwarding of local SSH keys to the remote (-A)"},
 		{"ProxyJump", "Jump/bastion host: user@host:port — SSH tunnels through it"},
+		{"ProxyCmd", "Command ssh dials through (HTTPS/WebSocket proxy); ctrl+p cycles presets"},
 		{"LocalFwd", "Port tunnel: local_port:remote_host:remote_port"},
-		{"Group", "Collapsible group; use ← → in form to cycle"},
+		{"Group", "Collapsible group; Enter in the form searches them"},
 	}
 	for _, f := range fieldRef {
 		b.WriteString(keyStyle.Render(fmt.Sprintf("%-12s", f.name)) + sp.Render(" ") + descStyle.Render(f.desc) + "\n")
@@ -287,8 +371,34 @@ func (m model) renderGroupPromptView() string {
 	if m.groupPrompt.action == "rename" {
 		title = "Rename Group"
 	}
-	box := formBoxStyle.Render(formTitleStyle.Render(title) + "\n\n" + m.groupPrompt.input.View())
-	help := "\n" + helpBarStyle.Render(helpEntry("enter", "save")+" | "+helpEntry("esc", "cancel"))
+	content := m.groupPrompt.input.View()
+	if m.groupPrompt.smart {
+		title = "New Smart Group"
+		if m.groupPrompt.action == "rename" {
+			title = "Edit Smart Group"
+		}
+		content += "\n" + m.groupPrompt.query.View()
+	}
+	content += "\n" + m.groupPrompt.description.View() + "\n" + m.groupPrompt.color.View()
+	if c, ok := groupColor(m.groupPrompt.color.Value()); ok {
+		content += " " + lipgloss.NewStyle().Foreground(c).Render("●")
+	}
+	if !m.groupPrompt.smart {
+		content += "\n\n" + formSectionStyle.Render("  Member defaults") + "\n" +
+			m.groupPrompt.defaultUser.View() + "\n" + m.groupPrompt.defaultKey.View() + "\n" + m.groupPrompt.defaultJump.View()
+	}
+	content += "\n\n"
+	if m.groupPrompt.smart {
+		content += formHintStyle.Render("key=glob or key!=glob joined by AND · keys: alias host user port group jump notes") + "\n"
+	} else {
+		content += formHintStyle.Render("members with a blank user, key, or jump inherit these") + "\n"
+	}
+	content += formHintStyle.Render("colors: " + strings.Join(groupPaletteNames, " ") + " or #rrggbb")
+	help := "\n" + helpBarStyle.Render(helpEntry("tab", "switch")+" | "+helpEntry("enter", "save")+" | "+helpEntry("esc", "cancel"))
+	if m.form.formError != "" {
+		content += "\n\n" + testFailStyle.Render("✘ "+m.form.formError)
+	}
+	box := formBoxStyle.Render(formTitleStyle.Render(title) + "\n\n" + content)
 	return appStyle.Render(box + help)
 }
 
@@ -339,8 +449,15 @@ func (m model) renderFormWorkspace(width, height int, forceWide bool) string {
 	}
 	contentWidth := max(width-padX*2, 1)
 	contentHeight := max(height-padY, 4)
-	bodyHeight := max(contentHeight-3, 1) // header, status, and footer
 	wide := forceWide || width >= 100
+	// The wide layout's rail lists the keys, so its actions share a row with
+	// the navigation hints.
+	bottom := []string{m.renderFormActions(contentWidth), m.renderFormFooter(contentWidth)}
+	if wide {
+		sep := helpSepStyle.Render("  ·  ")
+		bottom = []string{ansi.Truncate(m.renderFormActions(contentWidth)+"   "+helpEntry("tab", "next")+sep+helpEntry("?", "help"), contentWidth, "")}
+	}
+	bodyHeight := max(contentHeight-3-len(bottom), 1) // header, tabs, status, actions, and footer
 	compact := width < 60
 
 	mainWidth := contentWidth
@@ -364,12 +481,12 @@ func (m model) renderFormWorkspace(width, height int, forceWide bool) string {
 		body = lipgloss.JoinHorizontal(lipgloss.Top, body, "  ", sidebar)
 	}
 
-	content := strings.Join([]string{
+	content := strings.Join(append([]string{
 		m.renderFormHeader(contentWidth),
+		m.renderFormTabs(contentWidth),
 		body,
 		m.renderFormStatusLine(contentWidth),
-		m.renderFormFooter(contentWidth),
-	}, "\n")
+	}, bottom...), "\n")
 
 	return lipgloss.NewStyle().
 		PaddingTop(padY).
@@ -395,17 +512,36 @@ func fitViewToBounds(view string, width, height int) string {
 }
 
 var formFieldHints = [fieldCount]string{
-	fieldAlias:        "Friendly name shown in the host list. Connect directly via `assho connect <alias>`.",
-	fieldHostname:     "IP address or domain name of the server (e.g. 192.168.1.50 or db.example.com).",
-	fieldUser:         "SSH username to log in as (e.g. root, ubuntu, deploy).",
-	fieldPort:         "SSH port. Standard is 22 — only change if the server uses a non-default port.",
-	fieldKeyFile:      "Path to your SSH private key file (e.g. ~/.ssh/id_rsa). Key-based auth is preferred over passwords.",
-	fieldPassword:     "SSH password — stored securely in your OS keychain, not written to the config file.",
-	fieldForwardAgent: "SSH agent forwarding (-A) lets the remote server use your local SSH keys, which is useful when hopping through a bastion.",
-	fieldProxyJump:    "A bastion or jump host used to reach this server. SSH tunnels through it transparently. Format: user@host:port",
-	fieldLocalForward: "Creates a local port tunnel into the remote network. Format: local_port:remote_host:remote_port — e.g. 5432:localhost:5432 to reach a remote database as if it were local.",
-	fieldGroup:        "Assign to a collapsible group (prod, staging, homelab…). Use ← → to cycle through existing groups.",
-	fieldNotes:        "Free-text note shown beneath the alias in the host list.",
+	fieldAlias:         "Friendly name shown in the host list. Connect directly via `assho connect <alias>`.",
+	fieldHostname:      "IP address or domain name of the server (e.g. 192.168.1.50 or db.example.com).",
+	fieldUser:          "SSH username to log in as (e.g. root, ubuntu, deploy).",
+	fieldPort:          "SSH port. Standard is 22 — only change if the server uses a non-default port.",
+	fieldTimeout:       "Seconds ssh waits for this host to answer before giving up; tests and scans get 3 more to finish. Raise it for slow satellite or mobile links. Blank uses ASSHO_CONNECT_TIMEOUT, or 5.",
+	fieldKeyFile:       "Path to your SSH private key file (e.g. ~/.ssh/id_rsa). Key-based auth is preferred over passwords.",
+	fieldPassword:      "SSH password — stored securely in your OS keychain, not written to the config file. Ctrl+R shows it while this field is focused.",
+	fieldForwardAgent:  "SSH agent forwarding (-A) lets the remote server use your local SSH keys, which is useful when hopping through a bastion.",
+	fieldProxyJump:     "A bastion or jump host used to reach this server. SSH tunnels through it transparently. Format: user@host:port",
+	fieldProxyCommand:  "Command ssh runs to reach this server, for hosts behind an HTTPS or WebSocket proxy (corporate proxies, Cloudflare Access). ssh fills in %h, %p and %r. Ctrl+P cycles presets for corkscrew, cloudflared, nc and websocat. Replaces the ProxyJump.",
+	fieldLocalForward:  "Creates a local port tunnel into the remote network. Format: local_port:remote_host:remote_port — e.g. 5432:localhost:5432 to reach a remote database as if it were local.",
+	fieldInternalHost:  "Second address used from the office network or VPN, e.g. a private IP. The hostname above stays the default elsewhere.",
+	fieldInternalNets:  "Use the internal hostname when this machine has an address in one of these CIDR subnets. Leave blank to use it whenever its SSH port answers.",
+	fieldRemoteCommand: "Command run on login instead of a plain shell, e.g. `tmux attach || tmux new` or `cd /srv/app && exec bash`. A TTY is requested automatically.",
+	fieldUseSSHConfig:  "Connect with a plain `ssh <alias>` and let ~/.ssh/config supply the hostname, user, port, key, and jump host. Assho still uses the hostname above for tests and trust review.",
+	fieldTransport:     "PowerShell over ssh starts PowerShell on Windows OpenSSH; PS remoting runs `pwsh` Enter-PSSession against WinRM (port 5985 unless set). W lists a Windows host's services. Local runs this machine's shell and scans its containers without ssh.",
+	fieldTerm:          "Terminal type sent for this host's sessions instead of your local TERM. Old appliances and embedded shells often need vt100 or xterm rather than xterm-256color.",
+	fieldNoLocale:      "Keep LANG and LC_* from being sent, for servers that break on a locale they do not have. ssh only sends them when ssh_config's SendEnv asks for them.",
+	fieldHotkey:        "Digit that connects to this host straight from the dashboard. Digits not bound here go to the pinned hosts, or else to the first visible hosts, in list order.",
+	fieldIdentityAgent: "Agent socket ssh takes this host's keys from instead of SSH_AUTH_SOCK, e.g. 1Password's or Secretive's. `none` uses no agent. Imported from IdentityAgent in ~/.ssh/config.",
+	fieldTerminal:      "Open this host's sessions in a new window of this terminal, e.g. `alacritty -e`, `kitty`, `wezterm start --` or `gnome-terminal --`, and keep the dashboard running. Blank uses ASSHO_TERMINAL; `none` stays in this terminal.",
+	fieldTmuxSession:   "Attach to (or create) this tmux session on connect via `tmux new -As <name>`. Falls back to a login shell when tmux is not installed.",
+	fieldGroup:         "Assign to a collapsible group (prod, staging, homelab…). Press Enter or start typing to search existing groups or create one.",
+	fieldNotes:         "Free-text note shown beneath the alias in the host list.",
+	fieldWebURLs:       "Web UIs opened with `u`, separated by commas. {forwarded_port} expands to the Local forward port, and localhost URLs start that tunnel first.",
+	fieldRole:          "What this host does: db, web, k8s, gpu, mail, or a role from the roles section of hosts.json. Picks the icon and color on the dashboard. Blank infers it from keywords in the alias and hostname.",
+	fieldOwner:         "Person responsible for this host. Shown in the detail pane and written as a comment by `assho export`.",
+	fieldTeam:          "Team that owns this host, so shared inventories record who to ping. Searchable in smart groups as team=<name>.",
+	fieldContact:       "How to reach the owner when the box misbehaves: an email address, chat handle, or pager alias.",
+	fieldExpires:       "Marks a temporary host. After this date it is flagged as expired, and archived on startup when ASSHO_ARCHIVE_EXPIRED=1. Enter a date or a day count such as 7d.",
 }
 
 func renderFormTooSmall(width, height int) string {
@@ -443,6 +579,22 @@ func (m model) renderFormHeader(width int) string {
 	return ansi.Truncate(left+strings.Repeat(" ", gap)+right, width, "")
 }
 
+// renderFormTabs is the Basic / Advanced tab strip under the header.
+func (m model) renderFormTabs(width int) string {
+	active := lipgloss.NewStyle().Foreground(colorPrimary).Bold(true).Underline(true)
+	inactive := lipgloss.NewStyle().Foreground(colorMuted)
+	basic, advanced := active, inactive
+	if m.formAdvancedTab() {
+		basic, advanced = inactive, active
+	}
+	advancedLabel := "Advanced"
+	if n := m.advancedOptionsSet(); n > 0 {
+		advancedLabel = fmt.Sprintf("Advanced · %d set", n)
+	}
+	tabs := basic.Render("Basic") + "   " + advanced.Render(advancedLabel) + "   " + helpEntry("ctrl+o", "switch")
+	return ansi.Truncate(tabs, width, "")
+}
+
 func (m model) formProgressLabel() string {
 	last := controlNotes
 	if m.form.selectedHost != nil {
@@ -461,6 +613,18 @@ func formControlLabel(control formControl) string {
 		return "User"
 	case controlPort:
 		return "Port"
+	case controlTimeout:
+		return "Timeout"
+	case controlTerm:
+		return "TERM"
+	case controlNoLocale:
+		return "Skip locale"
+	case controlTerminal:
+		return "Terminal"
+	case controlHotkey:
+		return "Hotkey"
+	case controlIdentityAgent:
+		return "Identity agent"
 	case controlKeyFile:
 		return "Key file"
 	case controlKeyPicker:
@@ -473,10 +637,36 @@ func formControlLabel(control formControl) string {
 		return "ProxyJump"
 	case controlLocalForward:
 		return "Local forward"
+	case controlProxyCommand:
+		return "ProxyCommand"
+	case controlInternalHost:
+		return "Internal host"
+	case controlInternalNets:
+		return "Internal subnets"
+	case controlRemoteCommand:
+		return "Remote command"
+	case controlTmuxSession:
+		return "Tmux session"
+	case controlUseSSHConfig:
+		return "Use ssh_config"
+	case controlTransport:
+		return "Connection"
 	case controlGroup:
 		return "Group"
 	case controlNotes:
 		return "Notes"
+	case controlWebURLs:
+		return "Web UIs"
+	case controlExpires:
+		return "Expires"
+	case controlRole:
+		return "Role"
+	case controlOwner:
+		return "Owner"
+	case controlTeam:
+		return "Team"
+	case controlContact:
+		return "Contact"
 	case controlDelete:
 		return "Delete host"
 	default:
@@ -492,14 +682,20 @@ func (m model) renderFormDocument(width int, twoColumn, compact bool) string {
 	sections := []section{
 		{title: "Endpoint", rows: [][]formControl{{controlAlias, controlHostname}, {controlUser, controlPort}}},
 		{title: "Authentication", rows: [][]formControl{{controlKeyFile}, {controlPassword, controlForwardAgent}}},
-		{title: "Routing", rows: [][]formControl{{controlProxyJump, controlLocalForward}}},
-		{title: "Details", rows: [][]formControl{{controlGroup, controlNotes}}},
+		{title: "Routing", rows: [][]formControl{{controlProxyJump}}},
+		{title: "Details", rows: [][]formControl{{controlGroup}}},
+	}
+	if m.formAdvancedTab() {
+		sections = []section{
+			{title: "Forwards & proxies", rows: [][]formControl{{controlLocalForward}, {controlProxyCommand}, {controlInternalHost, controlInternalNets}}},
+			{title: "Session", rows: [][]formControl{{controlRemoteCommand, controlTmuxSession}, {controlWebURLs, controlUseSSHConfig}, {controlTransport, controlTimeout}, {controlTerm, controlNoLocale}, {controlTerminal, controlHotkey}, {controlIdentityAgent}}},
+			{title: "Bookkeeping", rows: [][]formControl{{controlExpires, controlRole}, {controlOwner, controlTeam}, {controlContact, controlNotes}}},
+		}
 	}
 	var lines []string
-	for sectionIndex, item := range sections {
-		if sectionIndex > 0 {
-			lines = append(lines, "")
-		}
+	for _, item := range sections {
+		// Every row already ends with a blank line, which doubles as the
+		// section separator.
 		lines = append(lines, renderFormSectionHeading(item.title, width, compact))
 		for _, row := range item.rows {
 			if !twoColumn && len(row) == 2 {
@@ -527,10 +723,6 @@ func (m model) renderFormDocument(width int, twoColumn, compact bool) string {
 			lines = append(lines, "")
 		}
 	}
-	if m.form.selectedHost != nil && !twoColumn {
-		lines = append(lines, renderFormSectionHeading("Danger zone", width, compact))
-		lines = append(lines, strings.Split(m.renderFormControlBlock(controlDelete, width, true), "\n")...)
-	}
 	return strings.TrimRight(strings.Join(lines, "\n"), "\n")
 }
 
@@ -568,8 +760,9 @@ func (m model) renderFormControlBlock(control formControl, width int, inlineHint
 		input := m.form.inputs[fieldKeyFile]
 		input.Width = max(width-lipgloss.Width(button)-1, 1)
 		value = lipgloss.JoinHorizontal(lipgloss.Top, input.View(), " ", button)
-	case controlForwardAgent:
-		enabled := forwardAgentEnabled(m.form.inputs[fieldForwardAgent].Value())
+	case controlForwardAgent, controlUseSSHConfig, controlNoLocale:
+		field, _ := fieldForFormControl(control)
+		enabled := formToggleEnabled(m.form.inputs[field].Value())
 		toggle := "○ OFF"
 		if enabled {
 			toggle = "● ON"
@@ -579,6 +772,13 @@ func (m model) renderFormControlBlock(control formControl, width int, inlineHint
 			toggleStyle = toggleStyle.Foreground(colorText).Background(colorPrimary).Bold(true)
 		}
 		value = toggleStyle.Render(toggle) + " " + formHintStyle.Render("Space or Enter")
+	case controlTransport:
+		selectorStyle := lipgloss.NewStyle().Foreground(colorDimText)
+		if focused {
+			selectorStyle = selectorStyle.Foreground(colorText).Bold(true)
+		}
+		label := ansi.Truncate(transportLabel(m.form.inputs[fieldTransport].Value()), max(width-4, 1), "…")
+		value = selectorStyle.Render("◀ " + label + " ▶")
 	case controlGroup:
 		if m.form.groupCustom {
 			input := m.form.inputs[fieldGroup]
@@ -594,18 +794,8 @@ func (m model) renderFormControlBlock(control formControl, width int, inlineHint
 			if focused {
 				selectorStyle = selectorStyle.Foreground(colorText).Bold(true)
 			}
-			value = selectorStyle.Render("◀ " + groupValue + " ▶")
-		}
-	case controlDelete:
-		text := "Delete host"
-		if m.form.deleteArmed {
-			text = "Press Enter again to delete"
-		}
-		buttonStyle := lipgloss.NewStyle().Foreground(colorDimText).Background(colorSubtle).Padding(0, 1)
-		if focused {
-			buttonStyle = buttonStyle.Foreground(colorText).Background(colorDanger).Bold(true)
+			value = selectorStyle.Render(groupValue + " ▾")
 		}
-		value = buttonStyle.Render(text)
 	default:
 		if field, ok := fieldForFormControl(control); ok {
 			input := m.form.inputs[field]
@@ -615,17 +805,25 @@ func (m model) renderFormControlBlock(control formControl, width int, inlineHint
 	}
 
 	block := labelStyle.Render(label) + "\n" + ansi.Truncate(value, width, "")
+	if issue, blocking := m.formControlIssue(control); issue != "" {
+		style, mark := testPendingStyle, "⚠ "
+		if blocking {
+			style, mark = testFailStyle, "✘ "
+		}
+		block += "\n" + style.Render(ansi.Truncate(mark+issue, width, "…"))
+	} else if errControl, ok := formErrorControl(m.form.formError); ok && errControl == control {
+		// A save error not caught live is marked on its field as well.
+		block += "\n" + testFailStyle.Render(ansi.Truncate("✘ "+m.form.formError, width, "…"))
+	}
 	if inlineHint && focused {
 		if field, ok := fieldForFormControl(control); ok {
 			block += "\n" + lipgloss.NewStyle().Foreground(colorDimText).Italic(true).Width(width).Render(formFieldHints[field])
-		} else if control == controlDelete && m.form.deleteArmed {
-			block += "\n" + formHintStyle.Render("Esc cancels deletion")
 		}
 	}
 	return lipgloss.NewStyle().Width(width).MaxWidth(width).Render(block)
 }
 
-func forwardAgentEnabled(value string) bool {
+func formToggleEnabled(value string) bool {
 	switch strings.ToLower(strings.TrimSpace(value)) {
 	case "yes", "1", "true", "on":
 		return true
@@ -672,17 +870,19 @@ func (m model) renderFormContextRail(width, height int, scrollPercent float64) s
 	b.WriteString(formSectionStyle.Render("Actions") + "\n")
 	b.WriteString(helpEntry("Ctrl+S", "save") + "\n")
 	b.WriteString(helpEntry("Ctrl+T", "test connection") + "\n")
+	b.WriteString(helpEntry("Ctrl+O", "basic / advanced") + "\n")
+	if m.form.focus == controlProxyCommand {
+		b.WriteString(helpEntry("Ctrl+P", "next preset") + "\n")
+	}
+	if m.form.focus == controlPassword {
+		b.WriteString(helpEntry("Ctrl+R", "show / hide password") + "\n")
+	}
 	if m.form.selectedHost != nil {
 		b.WriteString(helpEntry("Ctrl+K", "install public key") + "\n")
 	}
 	b.WriteString(helpEntry("Esc", "cancel") + "\n")
 	b.WriteString("\n")
 	b.WriteString(formHintStyle.Render(fmt.Sprintf("Form position %d%%", int(scrollPercent*100))))
-	if m.form.selectedHost != nil {
-		b.WriteString("\n\n")
-		b.WriteString(formSectionStyle.Render("Danger zone") + "\n")
-		b.WriteString(m.renderFormControlBlock(controlDelete, innerWidth, false))
-	}
 	return lipgloss.NewStyle().
 		Border(lipgloss.NormalBorder(), false, false, false, true).
 		BorderForeground(colorSubtle).
@@ -702,10 +902,33 @@ func (m model) renderFormStatusLine(width int) string {
 	return ansi.Truncate(status, width, "")
 }
 
+// renderFormActions is the action row that stays put below the scrolling
+// fields. Save and Test name their keys; Delete, offered when editing, is
+// the last stop in the tab order.
+func (m model) renderFormActions(width int) string {
+	buttonStyle := lipgloss.NewStyle().Foreground(colorDimText).Background(colorSubtle).Padding(0, 1)
+	buttons := []string{buttonStyle.Render("Save ^S"), buttonStyle.Render("Test ^T")}
+	if m.form.selectedHost != nil {
+		text := "Delete host"
+		if m.form.deleteArmed {
+			text = "Press Enter again to delete"
+		}
+		deleteStyle := buttonStyle
+		if m.form.focus == controlDelete {
+			deleteStyle = deleteStyle.Foreground(colorText).Background(colorDanger).Bold(true)
+		}
+		buttons = append(buttons, deleteStyle.Render(text))
+		if m.form.deleteArmed {
+			buttons = append(buttons, formHintStyle.Render("esc cancels"))
+		}
+	}
+	return ansi.Truncate(strings.Join(buttons, " "), width, "")
+}
+
 func (m model) renderFormFooter(width int) string {
 	var footer string
 	if width < 58 {
-		footer = helpEntry("^S", "save") + "  " + helpEntry("^T", "test")
+		footer = helpEntry("tab", "next")
 		if m.form.selectedHost != nil {
 			footer += "  " + helpEntry("^K", "install key")
 		}
@@ -713,8 +936,6 @@ func (m model) renderFormFooter(width int) string {
 	} else {
 		sep := helpSepStyle.Render("  ·  ")
 		footer = strings.Join([]string{
-			helpEntry("ctrl+s", "save"),
-			helpEntry("ctrl+t", "test"),
 			helpEntry("tab", "next"),
 			helpEntry("esc", "cancel"),
 			helpEntry("?", "help"),
@@ -727,8 +948,8 @@ func (m model) renderFormFooter(width int) string {
 }
 
 func (m model) renderFormStatus() string {
-	if m.form.testing {
-		return " " + m.spinner.View() + " " + testPendingStyle.Render("Testing connection...")
+	if m.formTesting() {
+		return " " + m.spinner.View() + " " + testPendingStyle.Render("Testing connection...") + "  " + helpEntry("esc", "cancel")
 	}
 	if m.form.formError != "" {
 		return "  " + testFailStyle.Render("✘ "+m.form.formError)
@@ -737,7 +958,10 @@ func (m model) renderFormStatus() string {
 		if m.form.testResult {
 			return "  " + testSuccessStyle.Render("✔ "+m.form.testStatus)
 		}
-		return "  " + testFailStyle.Render("✘ "+m.form.testStatus)
+		if m.permFix.path != "" {
+			return "  " + testFailStyle.Render("✘ "+m.form.testStatus) + "  " + helpEntry("ctrl+f", "fix permissions")
+		}
+		return "  " + testFailStyle.Render("✘ "+m.form.testStatus) + "  " + helpEntry("ctrl+g", "diagnose")
 	}
 	return ""
 }
@@ -0,0 +1,264 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// --- Webhooks ---
+
+// hosts.json can list webhooks that hear about events as they happen:
+//
+//	"webhooks": [
+//	  {"name": "homelab", "url": "https://hooks.slack.com/services/T000/B000/XXXX", "format": "slack"},
+//	  {"name": "ops", "url": "https://ops.example.com/hook", "events": ["connection_failed"],
+//	   "template": "{\"host\": {{json .Alias}}, \"why\": {{json .Error}}}"}
+//	]
+//
+// health_changed fires when a scheduled health check finds a host failing
+// or recovered (see healthcheck.go), connection_failed when connecting to or
+// testing a host fails, and group_run_finished when a group test or scan has
+// finished on every member. A webhook without events hears all of them.
+//
+// Without a template the event is posted as JSON. Format "slack" or
+// "discord" posts its one-line summary as the message text. A template is a
+// Go text/template over the event's fields, with json to quote a value.
+// Delivery errors are printed by the CLI and the daemon; the TUI carries on.
+
+const (
+	eventHealthChanged    = "health_changed"
+	eventConnectionFailed = "connection_failed"
+	eventGroupRunFinished = "group_run_finished"
+
+	webhookTimeout = 15 * time.Second
+)
+
+var webhookEvents = []string{eventHealthChanged, eventConnectionFailed, eventGroupRunFinished}
+
+// webhookFormats are the built-in templates.
+var webhookFormats = map[string]string{
+	"slack":   `{"text": {{json .Summary}}}`,
+	"discord": `{"content": {{json .Summary}}}`,
+}
+
+// Webhook is one entry of the "webhooks" section.
+type Webhook struct {
+	Name     string   `json:"name"`
+	URL      string   `json:"url"`
+	Events   []string `json:"events,omitempty"`   // empty for every event
+	Format   string   `json:"format,omitempty"`   // slack or discord
+	Template string   `json:"template,omitempty"` // text/template over webhookEvent
+}
+
+func (w Webhook) wants(event string) bool {
+	return len(w.Events) == 0 || slices.Contains(w.Events, event)
+}
+
+type webhookEvent struct {
+	Event     string    `json:"event"`
+	Time      time.Time `json:"time"`
+	Summary   string    `json:"summary"`
+	Alias     string    `json:"alias,omitempty"`
+	Hostname  string    `json:"hostname,omitempty"`
+	Error     string    `json:"error,omitempty"`
+	Check     string    `json:"check,omitempty"` // health_changed
+	Group     string    `json:"group,omitempty"` // group_run_finished
+	Failing   []string  `json:"failing,omitempty"`
+	Recovered []string  `json:"recovered,omitempty"`
+	OK        int       `json:"ok,omitempty"`
+	Failed    int       `json:"failed,omitempty"`
+}
+
+func connectionFailedEvent(h Host, err error) webhookEvent {
+	status, _ := formatTestStatus(err)
+	return webhookEvent{
+		Event:    eventConnectionFailed,
+		Time:     time.Now(),
+		Summary:  fmt.Sprintf("✘ %s (%s): %s", h.Alias, h.Hostname, status),
+		Alias:    h.Alias,
+		Hostname: h.Hostname,
+		Error:    status,
+	}
+}
+
+func healthChangedEvent(r healthReport) webhookEvent {
+	var parts []string
+	if len(r.NewlyFailing) > 0 {
+		parts = append(parts, "✘ failing: "+strings.Join(r.NewlyFailing, ", "))
+	}
+	if len(r.Recovered) > 0 {
+		parts = append(parts, "✔ recovered: "+strings.Join(r.Recovered, ", "))
+	}
+	return webhookEvent{
+		Event:     eventHealthChanged,
+		Time:      r.Time,
+		Summary:   r.Check + " · " + strings.Join(parts, " · "),
+		Check:     r.Check,
+		Failing:   r.NewlyFailing,
+		Recovered: r.Recovered,
+		OK:        r.Tested - len(r.Failing),
+		Failed:    len(r.Failing),
+	}
+}
+
+func groupRunEvent(run groupRunState) webhookEvent {
+	_, ok, failed := run.summary()
+	var failing []string
+	for _, r := range run.results {
+		if r.done && !r.ok && !r.skipped {
+			failing = append(failing, r.alias)
+		}
+	}
+	action := "Test"
+	if run.kind == groupRunScan {
+		action = "Scan"
+	}
+	summary := fmt.Sprintf("%s of %s finished · %d ok · %d failed", action, run.group, ok, failed)
+	if len(failing) > 0 {
+		summary += " (" + strings.Join(failing, ", ") + ")"
+	}
+	return webhookEvent{
+		Event:   eventGroupRunFinished,
+		Time:    time.Now(),
+		Summary: summary,
+		Group:   run.group,
+		Failing: failing,
+		OK:      ok,
+		Failed:  failed,
+	}
+}
+
+func parseWebhookTemplate(w Webhook) (*template.Template, error) {
+	text := w.Template
+	if text == "" {
+		text = webhookFormats[w.Format]
+	}
+	if text == "" {
+		return nil, nil
+	}
+	funcs := template.FuncMap{"json": func(v any) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	}}
+	return template.New(w.Name).Funcs(funcs).Option("missingkey=error").Parse(text)
+}
+
+func validateWebhooks(hooks []Webhook) error {
+	seen := map[string]bool{}
+	for _, w := range hooks {
+		name := strings.TrimSpace(w.Name)
+		if name == "" {
+			return errors.New("a webhook has no name")
+		}
+		if seen[name] {
+			return fmt.Errorf("webhook %q is defined twice", name)
+		}
+		seen[name] = true
+		if u, err := url.Parse(w.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("webhook %q: url must be an http or https URL", name)
+		}
+		for _, event := range w.Events {
+			if !slices.Contains(webhookEvents, event) {
+				return fmt.Errorf("webhook %q: unknown event %q (use %s)", name, event, strings.Join(webhookEvents, ", "))
+			}
+		}
+		if _, ok := webhookFormats[w.Format]; w.Format != "" && !ok {
+			return fmt.Errorf("webhook %q: format must be slack or discord", name)
+		}
+		if _, err := parseWebhookTemplate(w); err != nil {
+			return fmt.Errorf("webhook %q: template: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// webhookBody renders e for w.
+func webhookBody(w Webhook, e webhookEvent) ([]byte, error) {
+	tmpl, err := parseWebhookTemplate(w)
+	if err != nil {
+		return nil, err
+	}
+	if tmpl == nil {
+		return json.Marshal(e)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, e); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func postWebhook(endpoint string, body []byte) error {
+	client := &http.Client{Timeout: webhookTimeout}
+	resp, err := client.Post(endpoint, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%s returned %s", endpoint, resp.Status)
+	}
+	return nil
+}
+
+// sendWebhooks posts e to every hook that wants it.
+func sendWebhooks(hooks []Webhook, e webhookEvent) []error {
+	var errs []error
+	for _, w := range hooks {
+		if !w.wants(e.Event) {
+			continue
+		}
+		body, err := webhookBody(w, e)
+		if err == nil {
+			err = postWebhook(w.URL, body)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("webhook %s: %w", w.Name, err))
+		}
+	}
+	return errs
+}
+
+// fireWebhooks reads the webhooks from hosts.json and sends e to them.
+func fireWebhooks(e webhookEvent) []error {
+	cfg, err := loadConfigFile()
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return []error{err}
+	}
+	if len(cfg.Webhooks) == 0 {
+		return nil
+	}
+	if err := validateWebhooks(cfg.Webhooks); err != nil {
+		return []error{err}
+	}
+	return sendWebhooks(cfg.Webhooks, e)
+}
+
+// webhookCmd fires e in the background of the TUI.
+func webhookCmd(e webhookEvent) tea.Cmd {
+	return func() tea.Msg {
+		fireWebhooks(e)
+		return nil
+	}
+}
+
+// warnWebhookErrors is how the CLI reports delivery failures.
+func warnWebhookErrors(errs []error) {
+	for _, err := range errs {
+		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+	}
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// webhookRecorder collects the bodies posted to it.
+type webhookRecorder struct {
+	mu     sync.Mutex
+	bodies []string
+}
+
+func (r *webhookRecorder) server(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		r.mu.Lock()
+		r.bodies = append(r.bodies, string(body))
+		r.mu.Unlock()
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestValidateWebhooks(t *testing.T) {
+	if err := validateWebhooks([]Webhook{{Name: "slack", URL: "https://hooks.slack.com/services/x", Format: "slack", Events: []string{eventConnectionFailed}}}); err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []struct {
+		hook Webhook
+		want string
+	}{
+		{Webhook{URL: "https://example.com"}, "no name"},
+		{Webhook{Name: "a", URL: "example.com/hook"}, "http or https"},
+		{Webhook{Name: "a", URL: "https://example.com", Events: []string{"host_down"}}, "unknown event"},
+		{Webhook{Name: "a", URL: "https://example.com", Format: "teams"}, "format"},
+		{Webhook{Name: "a", URL: "https://example.com", Template: "{{.Alias"}, "template"},
+	} {
+		if err := validateWebhooks([]Webhook{c.hook}); err == nil || !strings.Contains(err.Error(), c.want) {
+			t.Errorf("validate %+v = %v, want %q", c.hook, err, c.want)
+		}
+	}
+}
+
+func TestWebhookBodies(t *testing.T) {
+	e := connectionFailedEvent(Host{Alias: "nas", Hostname: "10.0.0.9"}, errors.New("ssh: connect to host 10.0.0.9 port 22: Connection refused"))
+	if e.Event != eventConnectionFailed || !strings.HasPrefix(e.Summary, "✘ nas (10.0.0.9): ") {
+		t.Fatalf("unexpected event %+v", e)
+	}
+
+	raw, err := webhookBody(Webhook{Name: "raw"}, e)
+	var decoded webhookEvent
+	if err != nil || json.Unmarshal(raw, &decoded) != nil || decoded.Alias != "nas" {
+		t.Fatalf("expected the event as JSON, got %s (%v)", raw, err)
+	}
+
+	slack, err := webhookBody(Webhook{Name: "slack", Format: "slack"}, e)
+	var message map[string]string
+	if err != nil || json.Unmarshal(slack, &message) != nil || message["text"] != e.Summary {
+		t.Fatalf("expected a slack message, got %s (%v)", slack, err)
+	}
+
+	custom, err := webhookBody(Webhook{Name: "ops", Template: `{"host": {{json .Alias}}, "down": {{json .Error}}}`}, e)
+	if err != nil || !json.Valid(custom) || !strings.Contains(string(custom), `"host": "nas"`) {
+		t.Fatalf("unexpected template output %s (%v)", custom, err)
+	}
+}
+
+func TestSendWebhooksFiltersEvents(t *testing.T) {
+	var rec webhookRecorder
+	server := rec.server(t)
+	hooks := []Webhook{
+		{Name: "all", URL: server.URL, Format: "discord"},
+		{Name: "health", URL: server.URL, Events: []string{eventHealthChanged}},
+		{Name: "broken", URL: server.URL + "/missing", Template: "{{.Nope}}"},
+	}
+	errs := sendWebhooks(hooks, groupRunEvent(groupRunState{kind: groupRunTest, group: "prod", results: []groupRunResult{
+		{alias: "web", done: true, ok: true},
+		{alias: "db", done: true},
+		{alias: "mail", done: true, skipped: true},
+	}}))
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "webhook broken") {
+		t.Fatalf("expected only the broken template to fail, got %v", errs)
+	}
+	if len(rec.bodies) != 1 || rec.bodies[0] != `{"content": "Test of prod finished · 1 ok · 1 failed (db)"}` {
+		t.Fatalf("unexpected deliveries %q", rec.bodies)
+	}
+}
+
+func TestFireWebhooksReadsConfig(t *testing.T) {
+	var rec webhookRecorder
+	server := rec.server(t)
+	writeTempConfig(t, nil)
+	if errs := fireWebhooks(healthChangedEvent(healthReport{Check: "prod"})); errs != nil {
+		t.Fatalf("no webhooks configured should be quiet, got %v", errs)
+	}
+
+	data, _ := os.ReadFile(getConfigPath())
+	var cfg map[string]any
+	_ = json.Unmarshal(data, &cfg)
+	cfg["webhooks"] = []Webhook{{Name: "homelab", URL: server.URL, Format: "slack"}}
+	data, _ = json.Marshal(cfg)
+	if err := os.WriteFile(getConfigPath(), data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	report := healthReport{Check: "prod", Time: time.Now(), Tested: 3, Failing: []healthFailure{{Alias: "web"}}, NewlyFailing: []string{"web"}, Recovered: []string{"db"}}
+	if errs := fireWebhooks(healthChangedEvent(report)); errs != nil {
+		t.Fatal(errs)
+	}
+	if len(rec.bodies) != 1 || !strings.Contains(rec.bodies[0], "prod · ✘ failing: web · ✔ recovered: db") {
+		t.Fatalf("unexpected deliveries %q", rec.bodies)
+	}
+	if err := saveConfig(nil, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if cfg, _ := loadConfigFile(); len(cfg.Webhooks) != 1 {
+		t.Fatal("expected saving to keep the webhooks section")
+	}
+}
+
+func TestGroupRunFiresWebhookWhenFinished(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("ASSHO_STORE_PASSWORD", "0")
+	hosts := []Host{{ID: "a", Alias: "web"}, {ID: "b", Alias: "db"}}
+	m := model{rawHosts: hosts, list: newTestListModel(nil, hosts), historyList: newTestHistoryListModel()}
+	m.groupRun = groupRunState{id: 1, kind: groupRunTest, results: []groupRunResult{{hostID: "a", alias: "web"}, {hostID: "b", alias: "db"}}}
+
+	next, cmd := m.finishGroupRunResult(groupRunResultMsg{run: 1, index: 0})
+	if cmd != nil {
+		t.Fatal("expected no webhook before every member finished")
+	}
+	if _, cmd = next.(model).finishGroupRunResult(groupRunResultMsg{run: 1, index: 1, err: errors.New("refused")}); cmd == nil {
+		t.Fatal("expected a webhook once the run finished")
+	}
+}
@@ -0,0 +1,298 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"os/exec"
+	"strings"
+	"time"
+	"unicode/utf16"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// --- Windows Hosts ---
+
+// A host's connection type picks how assho reaches it:
+//
+//	powershell  ssh to Windows OpenSSH and start PowerShell instead of cmd.exe
+//	psremoting  run `pwsh -Command Enter-PSSession` against WinRM locally
+//	local       this machine, without ssh (see local.go)
+//
+// W on a Windows host lists its running services, read over the same
+// transport.
+
+const (
+	transportPowerShell = "powershell"
+	transportPSRemoting = "psremoting"
+
+	defaultWinRMPort  = "5985"
+	windowsLogin      = "powershell -NoLogo"
+	windowsTimeout    = 20 * time.Second
+	windowsServiceCmd = `Get-Service | Where-Object { $_.Status -eq 'Running' } | Sort-Object Name | ForEach-Object { $_.Name + [char]9 + $_.DisplayName }`
+)
+
+var transportOptions = []string{"", transportPowerShell, transportPSRemoting, transportLocal}
+
+var errWindowsScan = errors.New("Windows hosts have no containers to scan; press W for services")
+
+type windowsService struct {
+	name    string
+	display string
+}
+
+type windowsServicesState struct {
+	host     Host
+	loading  bool
+	services []windowsService
+	cursor   int
+	err      string
+}
+
+type windowsServicesMsg struct {
+	hostID   string
+	services []windowsService
+	err      error
+}
+
+func (h Host) isWindows() bool {
+	return h.Transport == transportPowerShell || h.Transport == transportPSRemoting
+}
+
+func transportLabel(transport string) string {
+	switch transport {
+	case transportPowerShell:
+		return "PowerShell over ssh"
+	case transportPSRemoting:
+		return "PS remoting (WinRM)"
+	case transportLocal:
+		return "Local (no ssh)"
+	}
+	return "ssh"
+}
+
+// nextTransport cycles the form selector through transportOptions.
+func nextTransport(current string, delta int) string {
+	for i, option := range transportOptions {
+		if option == current {
+			return transportOptions[(i+delta+len(transportOptions))%len(transportOptions)]
+		}
+	}
+	return ""
+}
+
+// psQuote quotes a PowerShell string literal.
+func psQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// winrmPort is the host's port unless it is still ssh's default.
+func winrmPort(h Host) string {
+	if port := strings.TrimSpace(h.Port); port != "" && port != "22" {
+		return port
+	}
+	return defaultWinRMPort
+}
+
+// psRemotingTarget is the -ComputerName/-Port part shared by Enter-PSSession
+// and Invoke-Command.
+func psRemotingTarget(h Host) string {
+	target := "-ComputerName " + psQuote(bareHostname(h.Hostname))
+	if port := winrmPort(h); port != defaultWinRMPort {
+		target += " -Port " + port
+	}
+	return target
+}
+
+// buildPSRemotingCommand opens an interactive WinRM session. PowerShell asks
+// for the password itself, so a stored one is never passed along.
+func buildPSRemotingCommand(h Host) connectCommand {
+	script := "Enter-PSSession " + psRemotingTarget(h)
+	if h.User != "" {
+		script += " -Credential " + psQuote(h.User)
+	}
+	return connectCommand{
+		binary:  "pwsh",
+		args:    []string{"-NoLogo", "-NoExit", "-Command", script},
+		sshHost: h,
+	}
+}
+
+// encodePowerShell is the -EncodedCommand form of script, which survives
+// cmd.exe and ssh quoting untouched.
+func encodePowerShell(script string) string {
+	units := utf16.Encode([]rune(script))
+	buf := make([]byte, 0, len(units)*2)
+	for _, u := range units {
+		buf = append(buf, byte(u), byte(u>>8))
+	}
+	return base64.StdEncoding.EncodeToString(buf)
+}
+
+func parseWindowsServices(out string) []windowsService {
+	var services []windowsService
+	for _, line := range strings.Split(out, "\n") {
+		name, display, _ := strings.Cut(strings.TrimRight(line, "\r"), "\t")
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+		services = append(services, windowsService{name: name, display: strings.TrimSpace(display)})
+	}
+	return services
+}
+
+// testWinRM checks that the WinRM port accepts connections; authenticating
+// would need the password PowerShell prompts for.
+func testWinRM(ctx context.Context, h Host, inv hostInventory) tea.Cmd {
+	return func() tea.Msg {
+		h = resolveEndpoint(h, inv)
+		start := time.Now()
+		err := dialWinRM(ctx, h)
+		return testConnectionMsg{hostID: h.ID, latency: time.Since(start), err: err}
+	}
+}
+
+func dialWinRM(ctx context.Context, h Host) error {
+	if strings.TrimSpace(h.Hostname) == "" {
+		return fmt.Errorf("hostname required")
+	}
+	d := net.Dialer{Timeout: time.Duration(h.connectTimeout()) * time.Second}
+	conn, err := d.DialContext(ctx, "tcp", net.JoinHostPort(bareHostname(h.Hostname), winrmPort(h)))
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return fmt.Errorf("WinRM port unreachable: %v", err)
+	}
+	return conn.Close()
+}
+
+func fetchWindowsServicesTrusted(h Host) tea.Cmd {
+	return func() tea.Msg {
+		var out string
+		var err error
+		if h.Transport == transportPSRemoting {
+			ctx, cancel := context.WithTimeout(context.Background(), windowsTimeout)
+			defer cancel()
+			script := "Invoke-Command " + psRemotingTarget(h) + " -ScriptBlock { " + windowsServiceCmd + " }"
+			var raw []byte
+			raw, err = exec.CommandContext(ctx, "pwsh", "-NoProfile", "-NonInteractive", "-Command", script).CombinedOutput()
+			out = string(raw)
+			if err != nil && strings.TrimSpace(out) != "" {
+				err = fmt.Errorf("%s", strings.TrimSpace(out))
+			}
+		} else {
+			out, err = runSSHCommand(h, "powershell -NoProfile -NonInteractive -EncodedCommand "+encodePowerShell(windowsServiceCmd))
+		}
+		if err != nil {
+			return windowsServicesMsg{hostID: h.ID, err: err}
+		}
+		return windowsServicesMsg{hostID: h.ID, services: parseWindowsServices(out)}
+	}
+}
+
+func fetchWindowsServices(h Host, inv hostInventory) tea.Cmd {
+	if h.Transport == transportPSRemoting {
+		return fetchWindowsServicesTrusted(resolveEndpoint(h, inv))
+	}
+	return checkHostTrustCmd(pendingSSHAction{kind: sshActionServices, host: h, trustHost: h, inv: inv})
+}
+
+func (m model) openWindowsServices() (tea.Model, tea.Cmd) {
+	h, ok := m.list.SelectedItem().(Host)
+	if !ok || h.IsContainer {
+		return m, nil
+	}
+	if !h.isWindows() {
+		m.status.message = "W lists services on Windows hosts; set the connection type in the host form"
+		m.status.isError = true
+		m.status.version++
+		return m, statusClearCmd(m.status.version)
+	}
+	m.clearListDeleteConfirm()
+	m.services = windowsServicesState{host: h, loading: true}
+	m.state = stateServices
+	return m, fetchWindowsServices(h, m.inventory())
+}
+
+func (m model) finishWindowsServices(msg windowsServicesMsg) (tea.Model, tea.Cmd) {
+	if msg.hostID != m.services.host.ID {
+		return m, nil
+	}
+	m.services.loading = false
+	if msg.err != nil {
+		m.services.err, _ = formatTestStatus(msg.err)
+		return m, nil
+	}
+	m.services.err = ""
+	m.services.services = msg.services
+	m.services.cursor = min(m.services.cursor, max(len(msg.services)-1, 0))
+	return m, nil
+}
+
+func (m model) updateWindowsServices(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c":
+		m.quitting = true
+		return m, tea.Quit
+	case "esc", "q", "W":
+		m.state = stateList
+	case "up", "k":
+		if m.services.cursor > 0 {
+			m.services.cursor--
+		}
+	case "down", "j":
+		if m.services.cursor < len(m.services.services)-1 {
+			m.services.cursor++
+		}
+	case "r":
+		if !m.services.loading {
+			m.services.loading = true
+			m.services.err = ""
+			return m, fetchWindowsServices(m.services.host, m.inventory())
+		}
+	}
+	return m, nil
+}
+
+func (m model) renderWindowsServicesView() string {
+	width, height := normalizedSize(m.width, m.height)
+	inner := max(min(width-6, 100)-6, 24)
+	s := m.services
+	var b strings.Builder
+	b.WriteString(lipgloss.NewStyle().Bold(true).Foreground(colorText).Render(ansi.Truncate("SERVICES · "+s.host.Alias, inner, "…")) + "\n")
+	switch {
+	case s.loading:
+		b.WriteString(formHintStyle.Render(ansi.Truncate("Reading running services via "+transportLabel(s.host.Transport)+"…", inner, "…")) + "\n")
+	case s.err != "":
+		b.WriteString(testFailStyle.Render(ansi.Truncate("✘ "+s.err, inner, "…")) + "\n")
+	default:
+		b.WriteString(formHintStyle.Render(fmt.Sprintf("%d running", len(s.services))) + "\n")
+	}
+	b.WriteString("\n")
+
+	maxRows := max(height-12, 2)
+	start := 0
+	if s.cursor >= maxRows {
+		start = s.cursor - maxRows + 1
+	}
+	end := min(start+maxRows, len(s.services))
+	for idx := start; idx < end; idx++ {
+		svc := s.services[idx]
+		label := svc.name
+		if svc.display != "" && svc.display != svc.name {
+			label += " · " + svc.display
+		}
+		b.WriteString(selectionLine(idx == s.cursor, ansi.Truncate(label, inner-2, "…")) + "\n")
+	}
+	if end < len(s.services) {
+		b.WriteString(formHintStyle.Render(fmt.Sprintf("… %d more", len(s.services)-end)) + "\n")
+	}
+	b.WriteString("\n" + helpEntry("r", "refresh") + "  " + helpEntry("esc", "back"))
+	return centeredWorkspace(b.String(), width, height)
+}
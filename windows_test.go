@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestBuildConnectCommandPSRemoting(t *testing.T) {
+	h := Host{ID: "w1", Alias: "dc01", Hostname: "dc01.corp.example", User: `CORP\admin`, Port: "22", Password: "hunter2", Transport: transportPSRemoting}
+
+	cmd, err := buildConnectCommand(h, hostInventory{hosts: []Host{h}}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"-NoLogo", "-NoExit", "-Command", `Enter-PSSession -ComputerName 'dc01.corp.example' -Credential 'CORP\admin'`}
+	if cmd.binary != "pwsh" || !slices.Equal(cmd.args, want) || len(cmd.extraEnv) != 0 {
+		t.Fatalf("got %s %v (env %v), want pwsh %v", cmd.binary, cmd.args, cmd.extraEnv, want)
+	}
+
+	h.Port = "5986"
+	cmd, _ = buildConnectCommand(h, hostInventory{hosts: []Host{h}}, true)
+	if got := cmd.args[3]; got != `Enter-PSSession -ComputerName 'dc01.corp.example' -Port 5986 -Credential 'CORP\admin'` {
+		t.Fatalf("custom WinRM port not passed: %s", got)
+	}
+}
+
+func TestBuildConnectCommandPowerShellOverSSH(t *testing.T) {
+	h := Host{ID: "w2", Alias: "build", Hostname: "10.0.0.20", User: "admin", Transport: transportPowerShell}
+
+	cmd, err := buildConnectCommand(h, hostInventory{hosts: []Host{h}}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"-t", "-l", "admin", "10.0.0.20", "powershell -NoLogo"}
+	if cmd.binary != "ssh" || !slices.Equal(cmd.args, want) {
+		t.Fatalf("got %v, want %v", cmd.args, want)
+	}
+
+	h.RemoteCommand = "pwsh"
+	cmd, _ = buildConnectCommand(h, hostInventory{hosts: []Host{h}}, false)
+	if got := cmd.args[len(cmd.args)-1]; got != "pwsh" {
+		t.Fatalf("remote command should win over the default shell, got %q", got)
+	}
+}
+
+func TestParseWindowsServices(t *testing.T) {
+	services := parseWindowsServices("Dhcp\tDHCP Client\r\nW32Time\tWindows Time\r\n\r\nsshd\n")
+	want := []windowsService{{"Dhcp", "DHCP Client"}, {"W32Time", "Windows Time"}, {"sshd", ""}}
+	if !slices.Equal(services, want) {
+		t.Fatalf("got %+v, want %+v", services, want)
+	}
+}
+
+func TestEncodePowerShell(t *testing.T) {
+	// powershell -EncodedCommand expects base64 of UTF-16LE.
+	if got := encodePowerShell("dir"); got != "ZABpAHIA" {
+		t.Fatalf("got %q", got)
+	}
+}
+
+func TestFormTransportSelectorCycles(t *testing.T) {
+	m := model{state: stateForm, form: newFormState(newFormInputs())}
+	m.form.focus = controlTransport
+
+	for _, want := range []string{transportPowerShell, transportPSRemoting, transportLocal, ""} {
+		result, _ := m.updateForm(tea.KeyMsg{Type: tea.KeyRight})
+		m = result.(model)
+		if got := m.form.inputs[fieldTransport].Value(); got != want {
+			t.Fatalf("got %q, want %q", got, want)
+		}
+	}
+	result, _ := m.updateForm(tea.KeyMsg{Type: tea.KeyLeft})
+	m = result.(model)
+	if got := m.form.inputs[fieldTransport].Value(); got != transportLocal {
+		t.Fatalf("left should wrap to the last option, got %q", got)
+	}
+}
+
+func TestScanSkipsWindowsHosts(t *testing.T) {
+	msg := runDockerScan(context.Background(), Host{ID: "w1", Hostname: "10.0.0.20", Transport: transportPowerShell}, false)
+	if msg.err != errWindowsScan || msg.hostID != "w1" {
+		t.Fatalf("expected the Windows scan error, got %+v", msg)
+	}
+}